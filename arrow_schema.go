@@ -0,0 +1,74 @@
+package goparquet
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// ArrowSchemaKey is the key of the key-value meta data under which Arrow implementations store
+// the serialized Arrow schema of the file, so that the exact Arrow types including extension
+// types can be restored when the file is read into Arrow again.
+const ArrowSchemaKey = "ARROW:schema"
+
+// arrowContinuation is the continuation marker that precedes the length of IPC messages since
+// version 0.15 of the Arrow format.
+const arrowContinuation = 0xFFFFFFFF
+
+// EncodeArrowSchema encodes the flatbuffers Message that contains an Arrow Schema like Arrow
+// implementations do for the ARROW:schema entry, i.e. as IPC message with continuation marker
+// and length prefix, padded to 8 bytes and encoded as base64.
+func EncodeArrowSchema(message []byte) string {
+	padded := (len(message) + 7) &^ 7
+	data := make([]byte, 8+padded)
+	binary.LittleEndian.PutUint32(data, arrowContinuation)
+	binary.LittleEndian.PutUint32(data[4:], uint32(padded))
+	copy(data[8:], message)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// DecodeArrowSchema decodes the value of the ARROW:schema entry and returns the flatbuffers
+// Message that contains the Arrow Schema, which can be parsed with the Arrow module. Messages
+// without continuation marker written by older Arrow versions are supported as well.
+func DecodeArrowSchema(value string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		// some writers omit the padding of the base64 encoding.
+		if data, err = base64.RawStdEncoding.DecodeString(value); err != nil {
+			return nil, errors.Wrap(err, "invalid base64 encoding of Arrow schema")
+		}
+	}
+
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) == arrowContinuation {
+		data = data[4:]
+	}
+	if len(data) < 4 {
+		return nil, errors.New("Arrow schema is too short")
+	}
+	length := binary.LittleEndian.Uint32(data)
+	if uint64(length) > uint64(len(data)-4) {
+		return nil, errors.Errorf("Arrow schema message of %d bytes exceeds the %d available bytes", length, len(data)-4)
+	}
+	return data[4 : 4+length], nil
+}
+
+// ArrowSchema returns the flatbuffers Message that contains the Arrow Schema stored in the
+// ARROW:schema entry of the key-value meta data of the file, and whether there is such an entry.
+func (f *FileReader) ArrowSchema() ([]byte, bool, error) {
+	value, ok := f.MetaDataValue(ArrowSchemaKey)
+	if !ok {
+		return nil, false, nil
+	}
+	message, err := DecodeArrowSchema(value)
+	if err != nil {
+		return nil, true, err
+	}
+	return message, true, nil
+}
+
+// SetArrowSchema stores the flatbuffers Message that contains an Arrow Schema in the
+// ARROW:schema entry of the key-value meta data of the file.
+func (fw *FileWriter) SetArrowSchema(message []byte) {
+	fw.SetMetaData(ArrowSchemaKey, EncodeArrowSchema(message))
+}
@@ -0,0 +1,59 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrowSchema(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	message := []byte{0x10, 0, 0, 0, 0, 0, 0x0a, 0, 0x0c, 0, 0x06, 0, 0x05}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	w.SetArrowSchema(message)
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	read, ok, err := r.ArrowSchema()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, message, read[:len(message)])
+	require.Len(t, read, 16)
+
+	// the entry survives rewriting the file.
+	out := &bytes.Buffer{}
+	require.NoError(t, Rewrite(out, bytes.NewReader(buf.Bytes())))
+	r, err = NewFileReader(bytes.NewReader(out.Bytes()))
+	require.NoError(t, err)
+	read, ok, err = r.ArrowSchema()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, message, read[:len(message)])
+
+	// messages written before the continuation marker was introduced.
+	legacy := append([]byte{4, 0, 0, 0}, 1, 2, 3, 4)
+	read, err = DecodeArrowSchema(base64.RawStdEncoding.EncodeToString(legacy))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3, 4}, read)
+
+	_, err = DecodeArrowSchema(base64.StdEncoding.EncodeToString([]byte{0xff, 0xff, 0xff, 0xff, 9, 0, 0, 0, 1}))
+	require.Error(t, err)
+	_, err = DecodeArrowSchema("!")
+	require.Error(t, err)
+
+	r.meta.KeyValueMetadata = nil
+	_, ok, err = r.ArrowSchema()
+	require.NoError(t, err)
+	require.False(t, ok)
+}
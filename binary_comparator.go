@@ -0,0 +1,272 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// BinaryComparator determines the order of the values of BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY
+// columns that is used to compute their min and max statistics when writing, and to interpret
+// them when reading. Statistics that were computed in another order than they are interpreted
+// in lead to wrong pruning decisions.
+type BinaryComparator int
+
+const (
+	// BinaryComparatorUnsigned compares the values byte-wise as unsigned bytes. This is the
+	// order the parquet format defines for the min_value and max_value statistics fields.
+	BinaryComparatorUnsigned BinaryComparator = iota
+	// BinaryComparatorSigned compares the values byte-wise as signed bytes. This is the order
+	// older writers used for the deprecated min and max statistics fields, so statistics in
+	// this order are written to and read from these fields.
+	BinaryComparatorSigned
+	// BinaryComparatorUTF8 compares the values by their unicode code points. Invalid UTF-8
+	// sequences are compared as U+FFFD. For valid UTF-8, this is the same order as
+	// BinaryComparatorUnsigned.
+	BinaryComparatorUTF8
+)
+
+// firstCustomBinaryComparator is the value of the first comparator registered using
+// RegisterBinaryComparator.
+const firstCustomBinaryComparator BinaryComparator = 256
+
+// customBinaryComparator is a comparator registered using RegisterBinaryComparator.
+type customBinaryComparator struct {
+	name    string
+	compare func(a, b []byte) int
+}
+
+var (
+	customBinaryComparatorsMu sync.RWMutex
+	customBinaryComparators   []customBinaryComparator
+)
+
+// RegisterBinaryComparator registers a custom order of binary values, e.g. of version strings or
+// of case-insensitive text, and returns the BinaryComparator for it. compare needs to return a
+// negative number, 0 or a positive number if a is less than, equal to or greater than b. The
+// returned comparator can be set for single columns using WithColumnStatisticsBinaryComparator
+// and WithColumnPruningBinaryComparator, so that the statistics of these columns are computed
+// and interpreted in the custom order, and FindRowGroups can search them. As the parquet format
+// only defines the byte-wise order for the min_value and max_value statistics, statistics in a
+// custom order are written to the deprecated min and max fields, like the ones of
+// BinaryComparatorSigned, and no column index is written for these columns. Statistics in a
+// custom order are never truncated, as a prefix of a value isn't necessarily smaller than the
+// value itself. Comparators are usually registered during the initialization of a program and
+// can't be unregistered.
+func RegisterBinaryComparator(name string, compare func(a, b []byte) int) BinaryComparator {
+	customBinaryComparatorsMu.Lock()
+	defer customBinaryComparatorsMu.Unlock()
+
+	customBinaryComparators = append(customBinaryComparators, customBinaryComparator{name: name, compare: compare})
+	return firstCustomBinaryComparator + BinaryComparator(len(customBinaryComparators)-1)
+}
+
+// custom returns the registered comparator or false if c isn't a registered comparator.
+func (c BinaryComparator) custom() (customBinaryComparator, bool) {
+	if c < firstCustomBinaryComparator {
+		return customBinaryComparator{}, false
+	}
+
+	customBinaryComparatorsMu.RLock()
+	defer customBinaryComparatorsMu.RUnlock()
+
+	idx := int(c - firstCustomBinaryComparator)
+	if idx >= len(customBinaryComparators) {
+		return customBinaryComparator{}, false
+	}
+	return customBinaryComparators[idx], true
+}
+
+func (c BinaryComparator) String() string {
+	switch c {
+	case BinaryComparatorUnsigned:
+		return "unsigned"
+	case BinaryComparatorSigned:
+		return "signed"
+	case BinaryComparatorUTF8:
+		return "utf8"
+	}
+	if custom, ok := c.custom(); ok {
+		return custom.name
+	}
+	return fmt.Sprintf("BinaryComparator(%d)", int(c))
+}
+
+// Compare returns -1, 0 or 1 if a is less than, equal to or greater than b.
+func (c BinaryComparator) Compare(a, b []byte) int {
+	switch c {
+	case BinaryComparatorUnsigned:
+		return bytes.Compare(a, b)
+	case BinaryComparatorSigned:
+		return compareSignedBytes(a, b)
+	case BinaryComparatorUTF8:
+		return compareUTF8(a, b)
+	}
+	if custom, ok := c.custom(); ok {
+		switch res := custom.compare(a, b); {
+		case res < 0:
+			return -1
+		case res > 0:
+			return 1
+		}
+		return 0
+	}
+	return bytes.Compare(a, b)
+}
+
+// legacy returns true if statistics in the order of the comparator are stored in the
+// deprecated min and max fields. This is the case for all orders that differ from the order the
+// parquet format defines for min_value and max_value, so that other readers don't misinterpret
+// them.
+func (c BinaryComparator) legacy() bool {
+	if _, ok := c.custom(); ok {
+		return true
+	}
+	return c == BinaryComparatorSigned
+}
+
+// truncateMin truncates the min value v to at most length bytes. The result is a prefix of v, so
+// it's never greater than v. Values in the order of a registered comparator aren't truncated.
+func (c BinaryComparator) truncateMin(v []byte, length int) []byte {
+	if _, ok := c.custom(); ok || len(v) <= length {
+		return v
+	}
+	if c == BinaryComparatorUTF8 {
+		length = utf8Boundary(v, length)
+		if length == 0 {
+			return v
+		}
+	}
+	return v[:length:length]
+}
+
+// truncateMax truncates the max value v to at most length bytes and increments the result so
+// that it's still greater than v. v is returned as it is if no such value exists or if c is a
+// registered comparator.
+func (c BinaryComparator) truncateMax(v []byte, length int) []byte {
+	if _, ok := c.custom(); ok || len(v) <= length {
+		return v
+	}
+
+	if c == BinaryComparatorUTF8 {
+		return truncateMaxUTF8(v, length)
+	}
+
+	// the largest byte in the order of the comparator can't be incremented, so the byte before
+	// it is incremented instead.
+	var largest byte = 0xff
+	if c == BinaryComparatorSigned {
+		largest = 0x7f
+	}
+	for i := length - 1; i >= 0; i-- {
+		if v[i] != largest {
+			res := append([]byte{}, v[:i+1]...)
+			res[i]++
+			return res
+		}
+	}
+	return v
+}
+
+// truncateMaxUTF8 truncates v at a rune boundary and increments the last rune that can be
+// incremented, so that the result is greater than v when compared by code points.
+func truncateMaxUTF8(v []byte, length int) []byte {
+	prefix := v[:utf8Boundary(v, length)]
+	for len(prefix) > 0 {
+		r, n := utf8.DecodeLastRune(prefix)
+		prefix = prefix[:len(prefix)-n]
+
+		next := r + 1
+		if next >= 0xd800 && next <= 0xdfff {
+			// surrogates can't be encoded in UTF-8.
+			next = 0xe000
+		}
+		if next > utf8.MaxRune {
+			continue
+		}
+
+		res := append([]byte{}, prefix...)
+		return append(res, string(next)...)
+	}
+	return v
+}
+
+// utf8Boundary returns the largest position up to length at which a rune of v starts.
+func utf8Boundary(v []byte, length int) int {
+	for length > 0 && length < len(v) && !utf8.RuneStart(v[length]) {
+		length--
+	}
+	return length
+}
+
+func compareSignedBytes(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if x, y := int8(a[i]), int8(b[i]); x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return compareInt64(int64(len(a)), int64(len(b)))
+}
+
+func compareUTF8(a, b []byte) int {
+	for len(a) > 0 && len(b) > 0 {
+		x, n := utf8.DecodeRune(a)
+		y, m := utf8.DecodeRune(b)
+		if x != y {
+			return compareInt64(int64(x), int64(y))
+		}
+		a, b = a[n:], b[m:]
+	}
+	return compareInt64(int64(len(a)), int64(len(b)))
+}
+
+func isBinaryType(typ parquet.Type) bool {
+	return typ == parquet.Type_BYTE_ARRAY || typ == parquet.Type_FIXED_LEN_BYTE_ARRAY
+}
+
+// statisticsMinMax returns the min and max values of the statistics of the column described by
+// elem. The statistics of binary columns are taken from the fields that belong to the order of
+// cmp, the statistics of binary decimals are only taken from min_value and max_value. The
+// statistics of other columns are taken from the deprecated min and max fields if min_value and
+// max_value are missing and the deprecated fields are in the order of the column's type.
+func statisticsMinMax(elem *parquet.SchemaElement, stats *parquet.Statistics, cmp BinaryComparator) (min, max []byte) {
+	if stats == nil {
+		return nil, nil
+	}
+
+	if isBinaryType(elem.GetType()) {
+		if isDecimalElement(elem) {
+			return stats.MinValue, stats.MaxValue
+		}
+		if cmp.legacy() {
+			return stats.Min, stats.Max
+		}
+		return stats.MinValue, stats.MaxValue
+	}
+
+	min, max = stats.MinValue, stats.MaxValue
+	if (min == nil || max == nil) && legacyStatisticsOrder(elem) {
+		min, max = stats.Min, stats.Max
+	}
+	return min, max
+}
+
+// legacyStatisticsOrder returns true if the deprecated min and max statistics of the column
+// described by elem are in the order of its type. Older writers computed them by comparing the
+// physical values as signed numbers, which is the wrong order for unsigned integers, and INT96
+// values don't have a defined order at all.
+func legacyStatisticsOrder(elem *parquet.SchemaElement) bool {
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN, parquet.Type_FLOAT, parquet.Type_DOUBLE:
+		return true
+	case parquet.Type_INT32, parquet.Type_INT64:
+		return !isUnsignedElement(elem)
+	}
+	return false
+}
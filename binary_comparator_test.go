@@ -0,0 +1,250 @@
+package goparquet
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryComparator(t *testing.T) {
+	tests := []struct {
+		cmp      BinaryComparator
+		a, b     string
+		expected int
+	}{
+		{BinaryComparatorUnsigned, "a", "\xff", -1},
+		{BinaryComparatorSigned, "a", "\xff", 1},
+		{BinaryComparatorUTF8, "a", "\xff", -1},
+		{BinaryComparatorUnsigned, "ab", "a", 1},
+		{BinaryComparatorSigned, "ab", "a", 1},
+		{BinaryComparatorUTF8, "ab", "a", 1},
+		{BinaryComparatorUTF8, "é", "z", 1},
+		// invalid UTF-8 is compared as U+FFFD, which is smaller than U+10000.
+		{BinaryComparatorUTF8, "\xff", "\U00010000", -1},
+		{BinaryComparatorUnsigned, "\xff", "\U00010000", 1},
+		{BinaryComparatorSigned, "", "", 0},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, tt.cmp.Compare([]byte(tt.a), []byte(tt.b)), "%s: %q <> %q", tt.cmp, tt.a, tt.b)
+	}
+}
+
+func TestBinaryStatistics(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary value;
+	}`)
+	require.NoError(t, err)
+
+	write := func(opts ...FileWriterOption) []byte {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithSchemaDefinition(sd)}, opts...)...)
+		for _, v := range []string{"b", "\xff", "a"} {
+			require.NoError(t, w.AddData(map[string]interface{}{"value": []byte(v)}))
+		}
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	stats := func(data []byte, opts ...FileReaderOption) map[string]interface{} {
+		r, err := NewFileReaderWithOptions(bytes.NewReader(data), opts...)
+		require.NoError(t, err)
+		stats, err := rowGroupStatistics(r.SchemaReader, r.meta.RowGroups[0], []string{"value"}, r.writerVersion, &r.opts)
+		require.NoError(t, err)
+		return stats
+	}
+
+	unsigned := write()
+	require.Equal(t, map[string]interface{}{"min_value": []byte("a"), "max_value": []byte("\xff")}, stats(unsigned))
+	require.Empty(t, stats(unsigned, WithPruningBinaryComparator(BinaryComparatorSigned)))
+
+	signed := write(WithStatisticsBinaryComparator(BinaryComparatorSigned))
+	require.Empty(t, stats(signed))
+	require.Equal(t, map[string]interface{}{"min_value": []byte("\xff"), "max_value": []byte("b")}, stats(signed, WithPruningBinaryComparator(BinaryComparatorSigned)))
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(signed), WithPruningBinaryComparator(BinaryComparatorSigned))
+	require.NoError(t, err)
+	rollup, err := r.RollupStatistics()
+	require.NoError(t, err)
+	require.Equal(t, []byte("\xff"), rollup["value"].MinValue)
+	require.Equal(t, []byte("b"), rollup["value"].MaxValue)
+}
+
+func TestBinaryComparatorTruncate(t *testing.T) {
+	tests := []struct {
+		cmp      BinaryComparator
+		value    string
+		length   int
+		min, max string
+	}{
+		{BinaryComparatorUnsigned, "abc", 3, "abc", "abc"},
+		{BinaryComparatorUnsigned, "abcd", 2, "ab", "ac"},
+		{BinaryComparatorUnsigned, "a\xff\xffd", 3, "a\xff\xff", "b"},
+		{BinaryComparatorUnsigned, "\xff\xffd", 2, "\xff\xff", "\xff\xffd"},
+		{BinaryComparatorSigned, "a\x7fd", 2, "a\x7f", "b"},
+		{BinaryComparatorSigned, "a\xffd", 2, "a\xff", "a\x00"},
+		{BinaryComparatorUTF8, "aéb", 2, "a", "b"},
+		{BinaryComparatorUTF8, "éab", 2, "é", "ê"},
+		{BinaryComparatorUTF8, "é", 1, "é", "é"},
+		{BinaryComparatorUTF8, "a\uD7FFb", 4, "a\uD7FF", "a\uE000"},
+		{BinaryComparatorUTF8, "a\U0010ffffb", 5, "a\U0010ffff", "b"},
+	}
+
+	for _, tt := range tests {
+		min := tt.cmp.truncateMin([]byte(tt.value), tt.length)
+		max := tt.cmp.truncateMax([]byte(tt.value), tt.length)
+		require.Equal(t, tt.min, string(min), "%s: min of %q", tt.cmp, tt.value)
+		require.Equal(t, tt.max, string(max), "%s: max of %q", tt.cmp, tt.value)
+		require.True(t, tt.cmp.Compare(min, []byte(tt.value)) <= 0, "%s: min of %q", tt.cmp, tt.value)
+		require.True(t, tt.cmp.Compare(max, []byte(tt.value)) >= 0, "%s: max of %q", tt.cmp, tt.value)
+	}
+}
+
+func TestStatisticsTruncateLength(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary value (STRING);
+		required binary dec (DECIMAL(40, 0));
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithStatisticsTruncateLength(4))
+	for _, v := range []string{"banana", "apple", "cherry"} {
+		require.NoError(t, w.AddData(map[string]interface{}{"value": []byte(v), "dec": []byte(v)}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	stats := r.meta.RowGroups[0].Columns[0].MetaData.Statistics
+	require.Equal(t, []byte("appl"), stats.MinValue)
+	require.Equal(t, []byte("ches"), stats.MaxValue)
+
+	// decimals are never truncated.
+	stats = r.meta.RowGroups[0].Columns[1].MetaData.Statistics
+	require.Equal(t, []byte("apple"), stats.MinValue)
+	require.Equal(t, []byte("cherry"), stats.MaxValue)
+}
+
+func TestRegisterBinaryComparator(t *testing.T) {
+	caseInsensitive := RegisterBinaryComparator("case-insensitive", func(a, b []byte) int {
+		return 2 * bytes.Compare(bytes.ToLower(a), bytes.ToLower(b))
+	})
+	require.Equal(t, "case-insensitive", caseInsensitive.String())
+	require.Equal(t, 1, caseInsensitive.Compare([]byte("b"), []byte("A")))
+	require.Equal(t, 0, caseInsensitive.Compare([]byte("a"), []byte("A")))
+	require.Equal(t, []byte("abcd"), caseInsensitive.truncateMin([]byte("abcd"), 2))
+	require.Equal(t, []byte("abcd"), caseInsensitive.truncateMax([]byte("abcd"), 2))
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary name (STRING);
+		required binary other (STRING);
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithStatisticsTruncateLength(2), WithColumnStatisticsBinaryComparator("name", caseInsensitive))
+	for _, v := range []string{"bb", "Ccc", "aaa"} {
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte(v), "other": []byte(v)}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithColumnPruningBinaryComparator("name", caseInsensitive))
+	require.NoError(t, err)
+	require.Equal(t, caseInsensitive, r.ColumnBinaryComparator("name"))
+	require.Equal(t, BinaryComparatorUnsigned, r.ColumnBinaryComparator("other"))
+
+	stats, err := rowGroupStatistics(r.SchemaReader, r.meta.RowGroups[0], []string{"name", "other"}, r.writerVersion, &r.opts)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"min_name":  []byte("aaa"),
+		"max_name":  []byte("Ccc"),
+		"min_other": []byte("Cc"),
+		"max_other": []byte("bb"),
+	}, stats)
+
+	rollup, err := r.RollupStatistics()
+	require.NoError(t, err)
+	require.Equal(t, []byte("aaa"), rollup["name"].MinValue)
+	require.Equal(t, []byte("Ccc"), rollup["name"].MaxValue)
+
+	// statistics in a custom order are only written to the deprecated fields, so that readers
+	// that interpret min_value and max_value in the byte-wise order don't misread them.
+	chunkStats := r.meta.RowGroups[0].Columns[0].MetaData.Statistics
+	require.Nil(t, chunkStats.MinValue)
+	require.Nil(t, chunkStats.MaxValue)
+	require.Equal(t, []byte("aaa"), chunkStats.Min)
+	require.Nil(t, r.meta.ColumnOrders)
+
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	stats, err = rowGroupStatistics(r.SchemaReader, r.meta.RowGroups[0], []string{"name"}, r.writerVersion, &r.opts)
+	require.NoError(t, err)
+	require.Empty(t, stats)
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithColumnPruningBinaryComparator("unknown", caseInsensitive))
+	require.Error(t, err)
+
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithColumnStatisticsBinaryComparator("unknown", caseInsensitive))
+	require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("a"), "other": []byte("b")}))
+	require.Error(t, w.FlushRowGroup())
+}
+
+func TestFindRowGroups(t *testing.T) {
+	version := RegisterBinaryComparator("version", func(a, b []byte) int {
+		x, _ := strconv.Atoi(string(a))
+		y, _ := strconv.Atoi(string(b))
+		return x - y
+	})
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary version (STRING);
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithColumnStatisticsBinaryComparator("version", version))
+	for rg, values := range [][]int{{1, 2}, {9, 10}, {10, 10}, {10, 12}, {100, 200}} {
+		for _, v := range values {
+			require.NoError(t, w.AddData(map[string]interface{}{"version": []byte(strconv.Itoa(v)), "id": int64(rg*10 + v)}))
+		}
+		require.NoError(t, w.FlushRowGroup())
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithColumnPruningBinaryComparator("version", version))
+	require.NoError(t, err)
+
+	for value, expected := range map[string][2]int{
+		"0":   {0, 0},
+		"2":   {0, 1},
+		"5":   {1, 1},
+		"10":  {1, 4},
+		"11":  {3, 4},
+		"150": {4, 5},
+		"300": {5, 5},
+	} {
+		first, end, err := r.FindRowGroups("version", value)
+		require.NoError(t, err)
+		require.Equal(t, expected, [2]int{first, end}, value)
+	}
+
+	first, end, err := r.FindRowGroups("id", int64(2))
+	require.NoError(t, err)
+	require.Equal(t, [2]int{0, 1}, [2]int{first, end})
+
+	_, _, err = r.FindRowGroups("id", "foo")
+	require.Error(t, err)
+	_, _, err = r.FindRowGroups("unknown", int64(1))
+	require.Error(t, err)
+
+	// without the comparator, the statistics of the column can't be used.
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	_, _, err = r.FindRowGroups("version", "10")
+	require.Error(t, err)
+}
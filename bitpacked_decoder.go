@@ -0,0 +1,75 @@
+package goparquet
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// bitPackedDecoder decodes levels encoded with the deprecated BIT_PACKED encoding. Other than
+// the bit-packed runs of the RLE/bit-packing hybrid encoding, the values are packed from the
+// most significant bit to the least significant bit of each byte, and the data has no length
+// prefix, so the number of values has to be known in advance.
+type bitPackedDecoder struct {
+	bitWidth  int
+	numValues int
+
+	data []byte
+	pos  int
+}
+
+func newBitPackedDecoder(bitWidth int, numValues int) *bitPackedDecoder {
+	return &bitPackedDecoder{
+		bitWidth:  bitWidth,
+		numValues: numValues,
+	}
+}
+
+func (d *bitPackedDecoder) initSize(r io.Reader) error {
+	if d.bitWidth == 0 {
+		return nil
+	}
+
+	size := (int64(d.bitWidth)*int64(d.numValues) + 7) / 8
+	data, err := ioutil.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return errors.Errorf("bit-packed levels must be %d byte but its %d byte", size, len(data))
+	}
+
+	d.data = data
+	d.pos = 0
+	return nil
+}
+
+func (d *bitPackedDecoder) init(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	d.data = data
+	d.pos = 0
+	return nil
+}
+
+func (d *bitPackedDecoder) next() (int32, error) {
+	if d.bitWidth == 0 {
+		return 0, nil
+	}
+	if d.pos+d.bitWidth > len(d.data)*8 {
+		return 0, io.EOF
+	}
+
+	var v int32
+	for i := 0; i < d.bitWidth; i++ {
+		bit := d.data[d.pos/8] >> (7 - uint(d.pos%8)) & 1
+		v = v<<1 | int32(bit)
+		d.pos++
+	}
+
+	return v, nil
+}
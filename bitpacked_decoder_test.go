@@ -0,0 +1,94 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitPackedDecoder(t *testing.T) {
+	// the example from the description of the BIT_PACKED encoding in the parquet format.
+	d := newBitPackedDecoder(3, 8)
+	require.NoError(t, d.initSize(bytes.NewReader([]byte{0x05, 0x39, 0x77, 0xff})))
+	for i := int32(0); i < 8; i++ {
+		v, err := d.next()
+		require.NoError(t, err)
+		require.Equal(t, i, v)
+	}
+	_, err := d.next()
+	require.Equal(t, io.EOF, err)
+
+	d = newBitPackedDecoder(3, 8)
+	require.Error(t, d.initSize(bytes.NewReader([]byte{0x05, 0x39})))
+}
+
+func TestReadBitPackedLevels(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional group a {
+			repeated int64 b;
+		}
+	}`)
+	require.NoError(t, err)
+
+	encodeBitPacked := func(bitWidth int, levels ...int32) []byte {
+		data := make([]byte, (bitWidth*len(levels)+7)/8)
+		pos := 0
+		for _, l := range levels {
+			for i := bitWidth - 1; i >= 0; i-- {
+				if l>>uint(i)&1 == 1 {
+					data[pos/8] |= 1 << uint(7-pos%8)
+				}
+				pos++
+			}
+		}
+		return data
+	}
+
+	// rows: {a: {b: [1, 2]}}, {}, {a: {b: [3]}}, {a: {}}
+	data := &bytes.Buffer{}
+	data.Write(encodeBitPacked(1, 0, 1, 0, 0, 0))
+	data.Write(encodeBitPacked(2, 2, 2, 0, 2, 1))
+	require.NoError(t, binary.Write(data, binary.LittleEndian, []int64{1, 2, 3}))
+
+	page := &EncodedPage{
+		Header: &parquet.PageHeader{
+			Type:                 parquet.PageType_DATA_PAGE,
+			UncompressedPageSize: int32(data.Len()),
+			CompressedPageSize:   int32(data.Len()),
+			DataPageHeader: &parquet.DataPageHeader{
+				NumValues:               5,
+				Encoding:                parquet.Encoding_PLAIN,
+				DefinitionLevelEncoding: parquet.Encoding_BIT_PACKED,
+				RepetitionLevelEncoding: parquet.Encoding_BIT_PACKED,
+			},
+		},
+		Data: data.Bytes(),
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.WriteEncodedRowGroup(4, []*EncodedColumnChunk{{Column: "a.b", Pages: []*EncodedPage{page}}}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithStrictValidation())
+	require.NoError(t, err)
+
+	expected := []map[string]interface{}{
+		{"a": map[string]interface{}{"b": []int64{1, 2}}},
+		{},
+		{"a": map[string]interface{}{"b": []int64{3}}},
+		{"a": map[string]interface{}{}},
+	}
+	for _, row := range expected {
+		actual, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, row, actual)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,357 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// BloomFilter is the Bloom filter of a column chunk as it is stored in the file.
+type BloomFilter struct {
+	// Header describes the algorithm, hash function and compression of the filter.
+	Header *parquet.BloomFilterHeader
+	// Bitset contains the Header.NumBytes bytes of the filter.
+	Bitset []byte
+}
+
+// ReadBloomFilter reads the Bloom filter of the column colName in the row group with index
+// rowGroup. The column name has to be provided in its dotted notation. nil is returned if the
+// column chunk has no Bloom filter. If the column meta data contains the length of the filter,
+// exactly that many bytes are read and the size of the filter header and bitset has to match
+// it. Otherwise, the length is taken from the filter header. In both cases, the filter must not
+// overlap with the meta data footer of the file. It doesn't change the position of the reader
+// for NextRow.
+func (f *FileReader) ReadBloomFilter(rowGroup int, colName string) (*BloomFilter, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return nil, errors.Errorf("row group index %d is out of bounds", rowGroup)
+	}
+
+	col := f.GetColumnByName(colName)
+	if col == nil {
+		return nil, errors.Errorf("column %q not found", colName)
+	}
+
+	rg := f.meta.RowGroups[rowGroup]
+	if len(rg.Columns) <= col.Index() {
+		return nil, errors.Errorf("column index %d is out of bounds", col.Index())
+	}
+
+	chunk := rg.Columns[col.Index()]
+	dec, err := f.opts.decryptor.chunk(col, chunk)
+	if err != nil {
+		return nil, err
+	}
+	if chunk.MetaData == nil {
+		return nil, errors.Errorf("missing meta data for column %s", colName)
+	}
+	if chunk.MetaData.BloomFilterOffset == nil {
+		return nil, nil
+	}
+
+	r := f.reader
+	if chunk.FilePath != nil {
+		file, closeFile, err := openChunkFile(chunk, &f.opts)
+		if err != nil {
+			return nil, err
+		}
+		defer closeFile()
+		r = file
+	}
+
+	end, err := dataEnd(r, chunk.FilePath == nil)
+	if err != nil {
+		return nil, err
+	}
+
+	bf, err := readBloomFilter(r, chunk.MetaData.GetBloomFilterOffset(), chunk.MetaData.BloomFilterLength, end, dec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading bloom filter of column %s failed", colName)
+	}
+
+	return bf, nil
+}
+
+// dataEnd returns the offset at which the data of r ends. For parquet files, this is where the
+// meta data footer starts.
+func dataEnd(r io.ReadSeeker, parquetFile bool) (int64, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if !parquetFile {
+		return size, nil
+	}
+
+	if size < 8 {
+		return 0, errors.New("file is too small")
+	}
+	if _, err := r.Seek(-8, io.SeekEnd); err != nil {
+		return 0, err
+	}
+
+	var footerLen int32
+	if err := binary.Read(r, binary.LittleEndian, &footerLen); err != nil {
+		return 0, err
+	}
+
+	end := size - 8 - int64(footerLen)
+	if footerLen < 0 || end < 0 {
+		return 0, errors.Errorf("invalid footer length %d", footerLen)
+	}
+
+	return end, nil
+}
+
+// readBloomFilter reads the Bloom filter at offset. If dec is set, the header and the bitset of
+// the filter are encrypted modules.
+func readBloomFilter(r io.ReadSeeker, offset int64, length *int32, end int64, dec *chunkDecryptor) (*BloomFilter, error) {
+	if offset < 0 || offset >= end {
+		return nil, errors.Errorf("offset %d is out of bounds", offset)
+	}
+
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if dec != nil {
+		return readEncryptedBloomFilter(r, offset, length, end, dec)
+	}
+
+	src := r
+	if length != nil {
+		if *length <= 0 || int64(*length) > end-offset {
+			return nil, errors.Errorf("length %d at offset %d is out of bounds", *length, offset)
+		}
+
+		data := make([]byte, *length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		src = bytes.NewReader(data)
+	}
+
+	pos := &offsetReader{inner: src}
+	header := &parquet.BloomFilterHeader{}
+	if err := readThrift(header, pos); err != nil {
+		return nil, errors.Wrap(err, "reading header failed")
+	}
+	if offset+pos.Count() > end {
+		return nil, errors.New("header exceeds the data of the file")
+	}
+
+	if header.NumBytes <= 0 {
+		return nil, errors.Errorf("invalid size of %d bytes", header.NumBytes)
+	}
+	if header.Algorithm.IsSetBLOCK() && header.NumBytes%32 != 0 {
+		return nil, errors.Errorf("size of %d bytes is not a multiple of the block size", header.NumBytes)
+	}
+
+	size := pos.Count() + int64(header.NumBytes)
+	if length != nil && size != int64(*length) {
+		return nil, errors.Errorf("header and bitset have a size of %d bytes but the length is %d bytes", size, *length)
+	}
+	if offset+size > end {
+		return nil, errors.Errorf("bitset of %d bytes exceeds the data of the file", header.NumBytes)
+	}
+
+	bitset := make([]byte, header.NumBytes)
+	if _, err := io.ReadFull(pos, bitset); err != nil {
+		return nil, errors.Wrap(err, "reading bitset failed")
+	}
+
+	return &BloomFilter{Header: header, Bitset: bitset}, nil
+}
+
+// readEncryptedBloomFilter reads the Bloom filter at the current position of r, which is offset,
+// whose header and bitset are encrypted modules.
+func readEncryptedBloomFilter(r io.ReadSeeker, offset int64, length *int32, end int64, dec *chunkDecryptor) (*BloomFilter, error) {
+	max := end - offset
+	if length != nil {
+		if *length <= 0 || int64(*length) > max {
+			return nil, errors.Errorf("length %d at offset %d is out of bounds", *length, offset)
+		}
+		max = int64(*length)
+	}
+
+	pos := &offsetReader{inner: r}
+	data, err := dec.readModule(pos, max, bloomFilterHeaderModule, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading header failed")
+	}
+	header := &parquet.BloomFilterHeader{}
+	if err := readThrift(header, bytes.NewReader(data)); err != nil {
+		return nil, errors.Wrap(err, "reading header failed")
+	}
+
+	if header.NumBytes <= 0 {
+		return nil, errors.Errorf("invalid size of %d bytes", header.NumBytes)
+	}
+	if header.Algorithm.IsSetBLOCK() && header.NumBytes%32 != 0 {
+		return nil, errors.Errorf("size of %d bytes is not a multiple of the block size", header.NumBytes)
+	}
+
+	bitset, err := dec.readModule(pos, max-pos.Count(), bloomFilterBitsetModule, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading bitset failed")
+	}
+	if len(bitset) != int(header.NumBytes) {
+		return nil, errors.Errorf("bitset has %d bytes but the header declares %d bytes", len(bitset), header.NumBytes)
+	}
+	if length != nil && pos.Count() != int64(*length) {
+		return nil, errors.Errorf("header and bitset have a size of %d bytes but the length is %d bytes", pos.Count(), *length)
+	}
+
+	return &BloomFilter{Header: header, Bitset: bitset}, nil
+}
+
+const (
+	// bloomFilterBlockSize is the size of a block of a split block Bloom filter in bytes.
+	bloomFilterBlockSize = 32
+	// maxBloomFilterSize is the maximum size of the bitset of a Bloom filter that is written.
+	maxBloomFilterSize = 128 * 1024 * 1024
+)
+
+// bloomFilterSalt contains the salt values of the split block Bloom filter algorithm.
+var bloomFilterSalt = [8]uint32{
+	0x47b6137b, 0x44974d91, 0x8824ad5b, 0xa2b7289d,
+	0x705495c7, 0x2df1424b, 0x9efc4947, 0x5c6bfb31,
+}
+
+// newBloomFilter creates an empty split block Bloom filter that is large enough to contain
+// numValues distinct values with a false positive probability of fpp.
+func newBloomFilter(numValues int64, fpp float64) *BloomFilter {
+	// number of bits according to the parquet format specification, rounded up to a power of 2.
+	numBits := -8 * float64(numValues) / math.Log(1-math.Pow(fpp, 1.0/8))
+	size := int64(bloomFilterBlockSize)
+	for size < maxBloomFilterSize && float64(8*size) < numBits {
+		size *= 2
+	}
+
+	return &BloomFilter{
+		Header: &parquet.BloomFilterHeader{
+			NumBytes:    int32(size),
+			Algorithm:   &parquet.BloomFilterAlgorithm{BLOCK: &parquet.SplitBlockAlgorithm{}},
+			Hash:        &parquet.BloomFilterHash{XXHASH: &parquet.XxHash{}},
+			Compression: &parquet.BloomFilterCompression{UNCOMPRESSED: &parquet.Uncompressed{}},
+		},
+		Bitset: make([]byte, size),
+	}
+}
+
+// block returns the offset of the block within the bitset that contains hash.
+func (b *BloomFilter) block(hash uint64) int {
+	numBlocks := uint64(len(b.Bitset) / bloomFilterBlockSize)
+	return int(((hash>>32)*numBlocks)>>32) * bloomFilterBlockSize
+}
+
+func (b *BloomFilter) insert(hash uint64) {
+	block := b.Bitset[b.block(hash):]
+	for i, salt := range bloomFilterSalt {
+		word := block[4*i : 4*i+4]
+		binary.LittleEndian.PutUint32(word, binary.LittleEndian.Uint32(word)|1<<((uint32(hash)*salt)>>27))
+	}
+}
+
+func (b *BloomFilter) check(hash uint64) bool {
+	block := b.Bitset[b.block(hash):]
+	for i, salt := range bloomFilterSalt {
+		if binary.LittleEndian.Uint32(block[4*i:])&(1<<((uint32(hash)*salt)>>27)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns false if value is definitely not contained in the column chunk of the Bloom
+// filter, and true if it might be contained. The value needs to have the type that the column
+// store returns for the column, e.g. []byte for BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY columns
+// including UUIDs and decimals, or int32 for INT32 columns. Only split block Bloom filters with
+// the xxHash hash function that aren't compressed are supported.
+func (b *BloomFilter) Check(value interface{}) (bool, error) {
+	h := b.Header
+	if h == nil || !h.Algorithm.IsSetBLOCK() || !h.Hash.IsSetXXHASH() || !h.Compression.IsSetUNCOMPRESSED() {
+		return false, errors.New("unsupported bloom filter algorithm, hash or compression")
+	}
+	if len(b.Bitset) == 0 || len(b.Bitset)%bloomFilterBlockSize != 0 {
+		return false, errors.Errorf("invalid bitset size of %d bytes", len(b.Bitset))
+	}
+
+	hash, err := bloomFilterHash(value)
+	if err != nil {
+		return false, err
+	}
+
+	return b.check(hash), nil
+}
+
+// bloomFilterHash returns the hash of the plain encoding of value as it's defined by the parquet
+// format specification. Other than in PLAIN encoded data pages, byte arrays aren't prefixed by
+// their length.
+func bloomFilterHash(value interface{}) (uint64, error) {
+	var buf [8]byte
+	switch v := value.(type) {
+	case int32:
+		binary.LittleEndian.PutUint32(buf[:], uint32(v))
+		return xxHash64(buf[:4]), nil
+	case int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		return xxHash64(buf[:]), nil
+	case float32:
+		binary.LittleEndian.PutUint32(buf[:], math.Float32bits(v))
+		return xxHash64(buf[:4]), nil
+	case float64:
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		return xxHash64(buf[:]), nil
+	case [12]byte:
+		return xxHash64(v[:]), nil
+	case []byte:
+		return xxHash64(v), nil
+	}
+	return 0, errors.Errorf("unsupported type %T for bloom filter", value)
+}
+
+// chunkBloomFilter is the Bloom filter of a column chunk. Like the page index, it's written after
+// all row groups, right before the meta data footer.
+type chunkBloomFilter struct {
+	chunk  *parquet.ColumnChunk
+	filter *BloomFilter
+}
+
+// buildBloomFilter creates a Bloom filter that contains all values of the column store of col.
+func buildBloomFilter(col *Column, fpp float64) (*BloomFilter, error) {
+	if typ := col.data.parquetType(); typ == parquet.Type_BOOLEAN {
+		return nil, errors.Errorf("bloom filters are not supported on type %s", typ)
+	}
+
+	values := col.data.values.values
+	filter := newBloomFilter(int64(len(values)), fpp)
+	for _, v := range values {
+		hash, err := bloomFilterHash(v)
+		if err != nil {
+			return nil, err
+		}
+		filter.insert(hash)
+	}
+	return filter, nil
+}
+
+// writeBloomFilters writes the Bloom filters and stores their locations in the column chunks.
+func writeBloomFilters(w writePos, filters []*chunkBloomFilter) error {
+	for _, bf := range filters {
+		pos := w.Pos()
+		if err := writeThrift(bf.filter.Header, w); err != nil {
+			return errors.Wrap(err, "writing bloom filter header failed")
+		}
+		if err := writeFull(w, bf.filter.Bitset); err != nil {
+			return errors.Wrap(err, "writing bloom filter bitset failed")
+		}
+		length := int32(w.Pos() - pos)
+		bf.chunk.MetaData.BloomFilterOffset = &pos
+		bf.chunk.MetaData.BloomFilterLength = &length
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBloomFilter(t *testing.T) {
+	data := writeValidateTestFile(t)
+
+	meta, _, err := readFileMetaData(bytes.NewReader(data), FooterLimits{}, nil)
+	require.NoError(t, err)
+
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	columns := data[:len(data)-8-footerLen]
+
+	encodeHeader := func(numBytes int32) []byte {
+		buf := &bytes.Buffer{}
+		require.NoError(t, writeThrift(&parquet.BloomFilterHeader{
+			NumBytes:    numBytes,
+			Algorithm:   &parquet.BloomFilterAlgorithm{BLOCK: &parquet.SplitBlockAlgorithm{}},
+			Hash:        &parquet.BloomFilterHash{XXHASH: &parquet.XxHash{}},
+			Compression: &parquet.BloomFilterCompression{UNCOMPRESSED: &parquet.Uncompressed{}},
+		}, buf))
+		return buf.Bytes()
+	}
+	header := encodeHeader(32)
+	bitset := bytes.Repeat([]byte{0xa5}, 32)
+
+	// writeFileWithHeader appends a bloom filter for the column id of the first row group to the
+	// column chunks of the file and sets its offset and length in the meta data.
+	writeFileWithHeader := func(header []byte, offset int64, length *int32) []byte {
+		buf := &bytes.Buffer{}
+		buf.Write(columns)
+		buf.Write(header)
+		buf.Write(bitset)
+
+		chunk := meta.RowGroups[0].Columns[0].MetaData
+		chunk.BloomFilterOffset = &offset
+		chunk.BloomFilterLength = length
+
+		footer := &bytes.Buffer{}
+		require.NoError(t, writeThrift(meta, footer))
+		buf.Write(footer.Bytes())
+		require.NoError(t, binary.Write(buf, binary.LittleEndian, int32(footer.Len())))
+		buf.Write(magic)
+
+		chunk.BloomFilterOffset = nil
+		chunk.BloomFilterLength = nil
+		return buf.Bytes()
+	}
+	writeFile := func(offset int64, length *int32) []byte {
+		return writeFileWithHeader(header, offset, length)
+	}
+
+	readFilter := func(data []byte) (*BloomFilter, error) {
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		return r.ReadBloomFilter(0, "id")
+	}
+
+	offset := int64(len(columns))
+	length := int32(len(header) + len(bitset))
+
+	bf, err := readFilter(data)
+	require.NoError(t, err)
+	require.Nil(t, bf)
+
+	for name, l := range map[string]*int32{"with length": &length, "without length": nil} {
+		bf, err = readFilter(writeFile(offset, l))
+		require.NoError(t, err, name)
+		require.Equal(t, int32(32), bf.Header.NumBytes, name)
+		require.True(t, bf.Header.Algorithm.IsSetBLOCK(), name)
+		require.Equal(t, bitset, bf.Bitset, name)
+	}
+
+	longer, shorter := length+1, length-1
+	_, err = readFilter(writeFile(offset, &longer))
+	require.Error(t, err)
+	_, err = readFilter(writeFile(offset, &shorter))
+	require.Error(t, err)
+
+	// the bitset would extend into the footer.
+	_, err = readFilter(writeFileWithHeader(encodeHeader(64), offset, nil))
+	require.Error(t, err)
+	// the size of split block filters must be a multiple of 32 bytes.
+	_, err = readFilter(writeFileWithHeader(encodeHeader(33), offset, nil))
+	require.Error(t, err)
+
+	huge := int32(1 << 30)
+	_, err = readFilter(writeFile(offset, &huge))
+	require.Error(t, err)
+	_, err = readFilter(writeFile(int64(len(data)), nil))
+	require.Error(t, err)
+
+	r, err := NewFileReader(bytes.NewReader(writeFile(offset, &length)))
+	require.NoError(t, err)
+	_, err = r.ReadBloomFilter(3, "id")
+	require.Error(t, err)
+	bf, err = r.ReadBloomFilter(1, "id")
+	require.NoError(t, err)
+	require.Nil(t, bf)
+}
+
+func TestXxHash64(t *testing.T) {
+	tests := map[string]uint64{
+		"":    0xef46db3751d8e999,
+		"a":   0xd24ec4f1a98c6e5b,
+		"abc": 0x44bc2cf5ad770999,
+		"Nobody inspects the spammish repetition": 0xfbcea83c8a378bf1,
+	}
+	for input, expected := range tests {
+		require.Equal(t, expected, xxHash64([]byte(input)), "%q", input)
+	}
+}
+
+func TestWriteBloomFilter(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required fixed_len_byte_array(16) uuid (UUID);
+		optional fixed_len_byte_array(5) amount (DECIMAL(10, 2));
+		required binary name (STRING);
+	}`)
+	require.NoError(t, err)
+
+	uuid := func(i int) []byte {
+		v := make([]byte, 16)
+		binary.BigEndian.PutUint64(v[8:], uint64(i)*0x9e3779b97f4a7c15)
+		return v
+	}
+	amount := func(i int) []byte {
+		v := make([]byte, 5)
+		binary.BigEndian.PutUint32(v[1:], uint32(i*100))
+		return v
+	}
+
+	var buf bytes.Buffer
+	w := NewFileWriter(&buf, WithSchemaDefinition(sd),
+		WithBloomFilter("uuid", 0.01),
+		WithBloomFilter("amount", 0.01),
+		WithBloomFilter("id", 0.01),
+	)
+	for i := 0; i < 2000; i++ {
+		data := map[string]interface{}{
+			"id":   int64(i),
+			"uuid": uuid(i),
+			"name": []byte("name"),
+		}
+		if i%2 == 0 {
+			data["amount"] = amount(i)
+		}
+		require.NoError(t, w.AddData(data))
+		if i%1000 == 999 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	filter, err := r.ReadBloomFilter(0, "name")
+	require.NoError(t, err)
+	require.Nil(t, filter)
+
+	for rg := 0; rg < 2; rg++ {
+		for _, col := range []string{"id", "uuid", "amount"} {
+			filter, err := r.ReadBloomFilter(rg, col)
+			require.NoError(t, err)
+			require.NotNil(t, filter, col)
+
+			value := func(i int) interface{} {
+				switch col {
+				case "id":
+					return int64(i)
+				case "uuid":
+					return uuid(i)
+				}
+				return amount(i)
+			}
+
+			falsePositives := 0
+			for i := 0; i < 2000; i++ {
+				ok, err := filter.Check(value(i))
+				require.NoError(t, err)
+				contained := i/1000 == rg && (col != "amount" || i%2 == 0)
+				if contained {
+					require.True(t, ok, "%s/%d: %d", col, rg, i)
+				} else if ok {
+					falsePositives++
+				}
+			}
+			require.True(t, falsePositives < 50, "%s/%d: %d false positives", col, rg, falsePositives)
+		}
+	}
+
+	filter, err = r.ReadBloomFilter(0, "id")
+	require.NoError(t, err)
+	_, err = filter.Check(true)
+	require.Error(t, err)
+
+	// reading the bloom filters must not change the position for NextRow.
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), row["id"])
+
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithBloomFilter("unknown", 0.01))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1), "uuid": uuid(1), "name": []byte("name")}))
+	require.Error(t, w.FlushRowGroup())
+
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithBloomFilter("id", 1))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1), "uuid": uuid(1), "name": []byte("name")}))
+	require.Error(t, w.FlushRowGroup())
+}
@@ -0,0 +1,120 @@
+package goparquet
+
+import (
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// ByteRange is a range of bytes within a file.
+type ByteRange struct {
+	// Offset is the position of the first byte of the range.
+	Offset int64
+	// Length is the number of bytes of the range.
+	Length int64
+}
+
+// ChunkByteRanges describes where the data of a column chunk is stored.
+type ChunkByteRanges struct {
+	// Column is the flat name of the column in dotted notation.
+	Column string
+	// FilePath is the path of the file that contains the column chunk, or empty if the chunk is
+	// stored in the file itself.
+	FilePath string
+	// Chunk is the range of all pages of the chunk, including their headers.
+	Chunk ByteRange
+	// DictionaryPage is the range of the dictionary page, or nil if the chunk has no dictionary
+	// page.
+	DictionaryPage *ByteRange
+	// DataPages contains the ranges of the data pages, including their headers. It is taken from
+	// the offset index, so it's nil if the file has no page index for the chunk.
+	DataPages []ByteRange
+}
+
+// ByteRanges returns the byte ranges of the chunks of the columns in projection of the row group
+// with index rowGroup, so that caching layers can prefetch exactly the data that is needed to
+// read them. The names of the columns need to be provided in dotted notation, a group includes
+// all its columns. If projection is empty, the columns selected with WithColumns are used. Only
+// the offset indexes of the chunks are read to determine the ranges of their pages. It doesn't
+// change the position of the reader for NextRow.
+func (f *FileReader) ByteRanges(projection []string, rowGroup int) ([]*ChunkByteRanges, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return nil, errors.Errorf("row group index %d is out of bounds", rowGroup)
+	}
+	rg := f.meta.RowGroups[rowGroup]
+
+	for _, name := range projection {
+		if f.GetColumnByName(name) == nil && !isGroupPrefix(f.Columns(), name) {
+			return nil, errors.Errorf("column %q not found", name)
+		}
+	}
+
+	var result []*ChunkByteRanges
+	for _, col := range f.Columns() {
+		if len(projection) > 0 && !inProjection(projection, col.FlatName()) {
+			continue
+		}
+		if len(projection) == 0 && !f.SchemaReader.isSelected(col.FlatName()) {
+			continue
+		}
+
+		if len(rg.Columns) <= col.Index() || rg.Columns[col.Index()].MetaData == nil {
+			return nil, errors.Errorf("missing meta data for column %s", col.FlatName())
+		}
+
+		ranges, err := f.chunkByteRanges(col, rg.Columns[col.Index()])
+		if err != nil {
+			return nil, errors.Wrapf(err, "column %s", col.FlatName())
+		}
+		result = append(result, ranges)
+	}
+
+	return result, nil
+}
+
+func (f *FileReader) chunkByteRanges(col *Column, chunk *parquet.ColumnChunk) (*ChunkByteRanges, error) {
+	meta := chunk.MetaData
+	ranges := &ChunkByteRanges{
+		Column:   col.FlatName(),
+		FilePath: chunk.GetFilePath(),
+		Chunk:    ByteRange{Offset: meta.DataPageOffset, Length: meta.TotalCompressedSize},
+	}
+
+	if meta.DictionaryPageOffset != nil && *meta.DictionaryPageOffset < meta.DataPageOffset {
+		offset := *meta.DictionaryPageOffset
+		ranges.Chunk.Offset = offset
+		ranges.DictionaryPage = &ByteRange{Offset: offset, Length: meta.DataPageOffset - offset}
+	}
+
+	if chunk.OffsetIndexOffset == nil || chunk.OffsetIndexLength == nil {
+		return ranges, nil
+	}
+
+	var r io.ReadSeeker = f.reader
+	if chunk.FilePath != nil {
+		file, closeFile, err := openChunkFile(chunk, &f.opts)
+		if err != nil {
+			return nil, err
+		}
+		defer closeFile()
+		r = file
+	}
+
+	dec, err := f.opts.decryptor.chunk(col, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	offsetIndex := &parquet.OffsetIndex{}
+	if err := readPageIndexStruct(r, offsetIndex, *chunk.OffsetIndexOffset, *chunk.OffsetIndexLength, dec, offsetIndexModule); err != nil {
+		return nil, errors.Wrap(err, "reading offset index failed")
+	}
+
+	ranges.DataPages = make([]ByteRange, 0, len(offsetIndex.PageLocations))
+	for _, loc := range offsetIndex.PageLocations {
+		ranges.DataPages = append(ranges.DataPages, ByteRange{Offset: loc.Offset, Length: int64(loc.CompressedPageSize)})
+	}
+
+	return ranges, nil
+}
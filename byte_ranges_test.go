@@ -0,0 +1,62 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteRanges(t *testing.T) {
+	data := writeValidateTestFile(t, WithPageIndex(), WithPageValueLimits(1, 30))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	ranges, err := r.ByteRanges([]string{"name", "tags"}, 1)
+	require.NoError(t, err)
+	require.Len(t, ranges, 2)
+	require.Equal(t, "name", ranges[0].Column)
+	require.Equal(t, "tags", ranges[1].Column)
+
+	for i, ranges := range ranges {
+		meta := r.meta.RowGroups[1].Columns[i+1].MetaData
+		require.Equal(t, meta.TotalCompressedSize, ranges.Chunk.Length)
+		end := ranges.Chunk.Offset
+		if meta.DictionaryPageOffset != nil {
+			require.Equal(t, *meta.DictionaryPageOffset, end)
+			require.Equal(t, end, ranges.DictionaryPage.Offset)
+			end += ranges.DictionaryPage.Length
+		} else {
+			require.Nil(t, ranges.DictionaryPage)
+		}
+
+		// the pages cover the whole chunk without gaps.
+		require.Equal(t, meta.DataPageOffset, end)
+		require.NotEmpty(t, ranges.DataPages)
+		for _, page := range ranges.DataPages {
+			require.Equal(t, end, page.Offset)
+			end += page.Length
+		}
+		require.Equal(t, ranges.Chunk.Offset+ranges.Chunk.Length, end)
+	}
+
+	ranges, err = r.ByteRanges(nil, 0)
+	require.NoError(t, err)
+	require.Len(t, ranges, 3)
+
+	_, err = r.ByteRanges([]string{"unknown"}, 0)
+	require.Error(t, err)
+	_, err = r.ByteRanges(nil, 3)
+	require.Error(t, err)
+
+	// the ranges of the pages aren't known without page index.
+	r, err = NewFileReader(bytes.NewReader(writeValidateTestFile(t)), "id")
+	require.NoError(t, err)
+	ranges, err = r.ByteRanges(nil, 2)
+	require.NoError(t, err)
+	require.Len(t, ranges, 1)
+	require.Equal(t, "id", ranges[0].Column)
+	require.Nil(t, ranges[0].DataPages)
+	require.Equal(t, r.meta.RowGroups[2].Columns[0].MetaData.TotalCompressedSize, ranges[0].Chunk.Length)
+}
@@ -3,6 +3,7 @@ package goparquet
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/bits"
 
 	"github.com/pkg/errors"
@@ -11,43 +12,51 @@ import (
 )
 
 type getValueDecoderFn func(parquet.Encoding) (valuesDecoder, error)
-type getLevelDecoder func(parquet.Encoding) (levelDecoder, error)
+type getLevelDecoder func(enc parquet.Encoding, numValues int32) (levelDecoder, error)
+
+// newLevelDecoder returns the decoder for levels up to max in the encoding enc. The number of
+// values is required for the deprecated BIT_PACKED encoding, which has no length prefix.
+func newLevelDecoder(enc parquet.Encoding, max uint16, numValues int32) (decoder, error) {
+	switch enc {
+	case parquet.Encoding_RLE:
+		dec := newHybridDecoder(bits.Len16(max))
+		dec.buffered = true
+		return dec, nil
+	case parquet.Encoding_BIT_PACKED:
+		if numValues < 0 {
+			return nil, errors.Errorf("negative number of values %d", numValues)
+		}
+		return newBitPackedDecoder(bits.Len16(max), int(numValues)), nil
+	default:
+		return nil, errors.Errorf("%q is not supported for definition and repetition level", enc)
+	}
+}
 
 func getDictValuesDecoder(typ *parquet.SchemaElement) (valuesDecoder, error) {
-	switch *typ.Type {
+	vt, err := resolveValueType(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	switch vt.typ {
 	case parquet.Type_BYTE_ARRAY:
 		return &byteArrayPlainDecoder{}, nil
 	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
 		if typ.TypeLength == nil {
 			return nil, errors.Errorf("type %s with nil type len", typ)
 		}
+		if *typ.TypeLength <= 0 {
+			return nil, errors.Errorf("type %s with invalid type len %d", typ, *typ.TypeLength)
+		}
 		return &byteArrayPlainDecoder{length: int(*typ.TypeLength)}, nil
 	case parquet.Type_FLOAT:
 		return &floatPlainDecoder{}, nil
 	case parquet.Type_DOUBLE:
 		return &doublePlainDecoder{}, nil
 	case parquet.Type_INT32:
-		var unSigned bool
-		if typ.ConvertedType != nil {
-			if *typ.ConvertedType == parquet.ConvertedType_UINT_8 || *typ.ConvertedType == parquet.ConvertedType_UINT_16 || *typ.ConvertedType == parquet.ConvertedType_UINT_32 {
-				unSigned = true
-			}
-		}
-		if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-			unSigned = true
-		}
-		return &int32PlainDecoder{unSigned: unSigned}, nil
+		return &int32PlainDecoder{unSigned: vt.unsigned}, nil
 	case parquet.Type_INT64:
-		var unSigned bool
-		if typ.ConvertedType != nil {
-			if *typ.ConvertedType == parquet.ConvertedType_UINT_64 {
-				unSigned = true
-			}
-		}
-		if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-			unSigned = true
-		}
-		return &int64PlainDecoder{unSigned: unSigned}, nil
+		return &int64PlainDecoder{unSigned: vt.unsigned}, nil
 	case parquet.Type_INT96:
 		return &int96PlainDecoder{}, nil
 	}
@@ -84,11 +93,15 @@ func getByteArrayValuesDecoder(pageEncoding parquet.Encoding, dictValues []inter
 }
 
 func getFixedLenByteArrayValuesDecoder(pageEncoding parquet.Encoding, len int, dictValues []interface{}) (valuesDecoder, error) {
+	// a length of zero would turn the plain decoder into a decoder for variable length byte arrays.
+	if len <= 0 {
+		return nil, errors.Errorf("invalid length %d for fixed_len_byte_array", len)
+	}
 	switch pageEncoding {
 	case parquet.Encoding_PLAIN:
 		return &byteArrayPlainDecoder{length: len}, nil
 	case parquet.Encoding_DELTA_BYTE_ARRAY:
-		return &byteArrayDeltaDecoder{}, nil
+		return &byteArrayDeltaDecoder{length: len}, nil
 	case parquet.Encoding_RLE_DICTIONARY:
 		return &dictDecoder{values: dictValues}, nil
 	default:
@@ -96,16 +109,7 @@ func getFixedLenByteArrayValuesDecoder(pageEncoding parquet.Encoding, len int, d
 	}
 }
 
-func getInt32ValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement, dictValues []interface{}) (valuesDecoder, error) {
-	var unSigned bool
-	if typ.ConvertedType != nil {
-		if *typ.ConvertedType == parquet.ConvertedType_UINT_8 || *typ.ConvertedType == parquet.ConvertedType_UINT_16 || *typ.ConvertedType == parquet.ConvertedType_UINT_32 {
-			unSigned = true
-		}
-	}
-	if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-		unSigned = true
-	}
+func getInt32ValuesDecoder(pageEncoding parquet.Encoding, unSigned bool, dictValues []interface{}) (valuesDecoder, error) {
 	switch pageEncoding {
 	case parquet.Encoding_PLAIN:
 		return &int32PlainDecoder{unSigned: unSigned}, nil
@@ -118,16 +122,7 @@ func getInt32ValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaEle
 	}
 }
 
-func getInt64ValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement, dictValues []interface{}) (valuesDecoder, error) {
-	var unSigned bool
-	if typ.ConvertedType != nil {
-		if *typ.ConvertedType == parquet.ConvertedType_UINT_64 {
-			unSigned = true
-		}
-	}
-	if typ.LogicalType != nil && typ.LogicalType.INTEGER != nil && !typ.LogicalType.INTEGER.IsSigned {
-		unSigned = true
-	}
+func getInt64ValuesDecoder(pageEncoding parquet.Encoding, unSigned bool, dictValues []interface{}) (valuesDecoder, error) {
 	switch pageEncoding {
 	case parquet.Encoding_PLAIN:
 		return &int64PlainDecoder{unSigned: unSigned}, nil
@@ -146,7 +141,12 @@ func getValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement,
 		pageEncoding = parquet.Encoding_RLE_DICTIONARY
 	}
 
-	switch *typ.Type {
+	vt, err := resolveValueType(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	switch vt.typ {
 	case parquet.Type_BOOLEAN:
 		return getBooleanValuesDecoder(pageEncoding, dictValues)
 
@@ -175,10 +175,10 @@ func getValuesDecoder(pageEncoding parquet.Encoding, typ *parquet.SchemaElement,
 		}
 
 	case parquet.Type_INT32:
-		return getInt32ValuesDecoder(pageEncoding, typ, dictValues)
+		return getInt32ValuesDecoder(pageEncoding, vt.unsigned, dictValues)
 
 	case parquet.Type_INT64:
-		return getInt64ValuesDecoder(pageEncoding, typ, dictValues)
+		return getInt64ValuesDecoder(pageEncoding, vt.unsigned, dictValues)
 
 	case parquet.Type_INT96:
 		switch pageEncoding {
@@ -203,21 +203,95 @@ func createDataReader(r io.Reader, codec parquet.CompressionCodec, compressedSiz
 	return newBlockReader(r, codec, compressedSize, uncompressedSize)
 }
 
-func readPages(r *offsetReader, col *Column, chunkMeta *parquet.ColumnMetaData, dDecoder, rDecoder getLevelDecoder) ([]pageReader, error) {
+// pageReadError adds the position of the page to err if the page couldn't be decompressed.
+func pageReadError(err error, col *Column, page int, offset int64) error {
+	var de *DecompressionError
+	if errors.As(err, &de) {
+		de.Column = col.FlatName()
+		de.Page = page
+		de.Offset = offset
+	}
+	return err
+}
+
+// positionedPage adds the position of the page to the errors of readValues, which are returned
+// by pages whose data is decompressed while the values are decoded.
+type positionedPage struct {
+	pageReader
+
+	col    *Column
+	page   int
+	offset int64
+}
+
+func (p *positionedPage) readValues(val []interface{}) (int, int, *packedArray, *packedArray, error) {
+	n, notNull, dLevel, rLevel, err := p.pageReader.readValues(val)
+	return n, notNull, dLevel, rLevel, pageReadError(err, p.col, p.page, p.offset)
+}
+
+func readPages(r *offsetReader, col *Column, chunkMeta *parquet.ColumnMetaData, pd *pageDecryptor, dDecoder, rDecoder getLevelDecoder, opts *fileReaderOptions, mem *memoryTracker, indices *dictIndexCollector) ([]pageReader, error) {
 	var (
 		dictPage *dictPageReader
 		pages    []pageReader
 	)
 
-	for {
+	for page := 0; ; page++ {
 		if chunkMeta.TotalCompressedSize-r.Count() <= 0 {
 			break
 		}
+		pageOffset := r.offset
 		ph := &parquet.PageHeader{}
-		if err := readThrift(ph, r); err != nil {
+		if pd != nil {
+			var err error
+			if ph, err = pd.readPageHeader(r, chunkMeta.TotalCompressedSize-r.Count()); err != nil {
+				return nil, err
+			}
+		} else if err := readThrift(ph, r); err != nil {
+			return nil, err
+		}
+
+		if opts.strict && r.Count()+int64(ph.CompressedPageSize) > chunkMeta.TotalCompressedSize {
+			return nil, &ValidationError{Column: col.FlatName(), Reason: fmt.Sprintf("page of %d bytes exceeds the column chunk size of %d bytes", ph.CompressedPageSize, chunkMeta.TotalCompressedSize)}
+		}
+
+		if err := mem.reservePage(ph.CompressedPageSize, ph.UncompressedPageSize, pageNumValues(ph)); err != nil {
 			return nil, err
 		}
 
+		// the page readers read the page data from pageData, which is replaced by the already
+		// validated page data if CRC validation is enabled.
+		var pageData io.Reader = r
+		if opts.validateCRC {
+			data, err := readPageWithCRC(r, ph, col, opts.strictCRC)
+			if err != nil {
+				return nil, err
+			}
+			pageData = data
+		}
+
+		if pd != nil {
+			data, header, err := decryptPageData(pageData, ph, pd)
+			if err != nil {
+				return nil, errors.Wrapf(err, "column %s page %d", col.FlatName(), page)
+			}
+			pageData, ph = data, header
+		}
+
+		codec := opts.codec(col, chunkMeta.Codec)
+		if len(opts.middleware) > 0 {
+			data, header, c, err := applyPageMiddleware(pageData, ph, codec, col, opts.middleware)
+			if err != nil {
+				return nil, err
+			}
+			pageData, ph, codec = data, header, c
+		}
+
+		if opts.strict && ph.Type == parquet.PageType_DATA_PAGE_V2 && ph.DataPageHeaderV2 != nil {
+			if err := validatePageV2Sizes(ph, col); err != nil {
+				return nil, &ValidationError{Column: col.FlatName(), Reason: err.Error()}
+			}
+		}
+
 		if ph.Type == parquet.PageType_DICTIONARY_PAGE {
 			if dictPage != nil {
 				return nil, errors.New("there should be only one dictionary")
@@ -231,13 +305,17 @@ func readPages(r *offsetReader, col *Column, chunkMeta *parquet.ColumnMetaData,
 				return nil, err
 			}
 
-			// re-use the value dictionary store
-			p.values = col.getColumnStore().values.values
-			if err := p.read(r, ph, chunkMeta.Codec); err != nil {
-				return nil, err
+			// the dictionary values must not share their memory with the column store, as the
+			// column store is filled page by page while the dictionary is still in use by the
+			// following pages.
+			if err := p.read(pageData, ph, codec); err != nil {
+				return nil, pageReadError(err, col, page, pageOffset)
 			}
 
 			dictPage = p
+			if indices != nil {
+				indices.dictionary = append(indices.dictionary[:0], p.values...)
+			}
 			// Go to the next data Page
 			// if we have a DictionaryPageOffset we should return to DataPageOffset
 			if chunkMeta.DictionaryPageOffset != nil {
@@ -261,31 +339,84 @@ func readPages(r *offsetReader, col *Column, chunkMeta *parquet.ColumnMetaData,
 				ph: ph,
 			}
 		default:
-			return nil, errors.Errorf("DATA_PAGE or DATA_PAGE_V2 type supported, but was %s", ph.Type)
+			// index pages and page types added in later versions of the format don't contain
+			// any data that is needed to read the values, so they are skipped.
+			if opts.strict {
+				return nil, &ValidationError{Column: col.FlatName(), Reason: fmt.Sprintf("unsupported page type %s", ph.Type)}
+			}
+			if ph.CompressedPageSize < 0 {
+				return nil, errors.Errorf("invalid size %d of %s page", ph.CompressedPageSize, ph.Type)
+			}
+			if n, err := io.CopyN(ioutil.Discard, pageData, int64(ph.CompressedPageSize)); err != nil {
+				return nil, errors.Wrapf(err, "need to skip %d byte of %s page but there was only %d byte", ph.CompressedPageSize, ph.Type, n)
+			}
+			continue
 		}
 		var dictValue []interface{}
 		if dictPage != nil {
 			dictValue = dictPage.values
 		}
+		// the encoding is decided per page, as writers fall back from dictionary encoding to
+		// PLAIN within a chunk once the dictionary grows too large.
 		var fn = func(typ parquet.Encoding) (valuesDecoder, error) {
-			return getValuesDecoder(typ, col.Element(), dictValue)
+			if dictPage == nil && (typ == parquet.Encoding_RLE_DICTIONARY || typ == parquet.Encoding_PLAIN_DICTIONARY) {
+				return nil, errors.Errorf("page of column %s is %s encoded but the chunk has no dictionary page", col.FlatName(), typ)
+			}
+			dec, err := getValuesDecoder(typ, col.Element(), dictValue)
+			if err != nil {
+				return nil, err
+			}
+			if bd, ok := dec.(*byteArrayPlainDecoder); ok {
+				bd.zeroCopy = opts.zeroCopy
+			}
+			if indices != nil {
+				return indices.wrap(dec), nil
+			}
+			return dec, nil
 		}
 		if err := p.init(dDecoder, rDecoder, fn); err != nil {
 			return nil, err
 		}
 
-		if err := p.read(r, ph, chunkMeta.Codec); err != nil {
-			return nil, err
+		if err := p.read(pageData, ph, codec); err != nil {
+			return nil, pageReadError(err, col, page, pageOffset)
 		}
-		pages = append(pages, p)
+
+		pages = append(pages, &positionedPage{pageReader: p, col: col, page: page, offset: pageOffset})
 	}
 
 	return pages, nil
 }
 
+// openChunkFile opens the file that contains the data of the column chunk if it is not stored in
+// the file itself. The returned function closes the file again.
+func openChunkFile(chunk *parquet.ColumnChunk, opts *fileReaderOptions) (io.ReadSeeker, func(), error) {
+	if opts.openFile == nil {
+		return nil, nil, fmt.Errorf("data is in another file: '%s', but no file opener is set", *chunk.FilePath)
+	}
+
+	r, err := opts.openFile(*chunk.FilePath)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "opening file '%s' failed", *chunk.FilePath)
+	}
+
+	return r, func() {
+		if c, ok := r.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}, nil
+}
+
 func skipChunk(r io.Seeker, col *Column, chunk *parquet.ColumnChunk) error {
 	if chunk.FilePath != nil {
-		return fmt.Errorf("nyi: data is in another file: '%s'", *chunk.FilePath)
+		// the position in r doesn't change, as the data is in another file.
+		return nil
+	}
+
+	if chunk.MetaData == nil && chunk.CryptoMetadata != nil {
+		// the meta data of encrypted columns whose key isn't available is unknown, but the
+		// position isn't needed, as every chunk is read from its own offset.
+		return nil
 	}
 
 	c := col.Index()
@@ -311,9 +442,20 @@ func skipChunk(r io.Seeker, col *Column, chunk *parquet.ColumnChunk) error {
 	return err
 }
 
-func readChunk(r io.ReadSeeker, col *Column, chunk *parquet.ColumnChunk) ([]pageReader, error) {
+func readChunk(r io.ReadSeeker, col *Column, chunk *parquet.ColumnChunk, opts *fileReaderOptions, mem *memoryTracker, indices *dictIndexCollector) ([]pageReader, error) {
 	if chunk.FilePath != nil {
-		return nil, fmt.Errorf("nyi: data is in another file: '%s'", *chunk.FilePath)
+		// all page data is read into memory by readPages, so the file can be closed afterwards.
+		f, closeFile, err := openChunkFile(chunk, opts)
+		if err != nil {
+			return nil, err
+		}
+		defer closeFile()
+		r = f
+	}
+
+	dec, err := opts.decryptor.chunk(col, chunk)
+	if err != nil {
+		return nil, err
 	}
 
 	c := col.Index()
@@ -334,8 +476,7 @@ func readChunk(r io.ReadSeeker, col *Column, chunk *parquet.ColumnChunk) ([]page
 		offset = *chunk.MetaData.DictionaryPageOffset
 	}
 	// Seek to the beginning of the first Page
-	_, err := r.Seek(offset, io.SeekStart)
-	if err != nil {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
 		return nil, err
 	}
 
@@ -345,43 +486,57 @@ func readChunk(r io.ReadSeeker, col *Column, chunk *parquet.ColumnChunk) ([]page
 		count:  0,
 	}
 
-	rDecoder := func(enc parquet.Encoding) (levelDecoder, error) {
-		if enc != parquet.Encoding_RLE {
-			return nil, errors.Errorf("%q is not supported for definition and repetition level", enc)
+	rDecoder := func(enc parquet.Encoding, numValues int32) (levelDecoder, error) {
+		dec, err := newLevelDecoder(enc, col.MaxRepetitionLevel(), numValues)
+		if err != nil {
+			return nil, err
 		}
-		dec := newHybridDecoder(bits.Len16(col.MaxRepetitionLevel()))
-		dec.buffered = true
-		return &levelDecoderWrapper{decoder: dec, max: col.MaxRepetitionLevel()}, nil
+		return &levelDecoderWrapper{decoder: dec, max: col.MaxRepetitionLevel(), validate: opts.strict, column: col.FlatName()}, nil
 	}
 
-	dDecoder := func(enc parquet.Encoding) (levelDecoder, error) {
-		if enc != parquet.Encoding_RLE {
-			return nil, errors.Errorf("%q is not supported for definition and repetition level", enc)
+	dDecoder := func(enc parquet.Encoding, numValues int32) (levelDecoder, error) {
+		dec, err := newLevelDecoder(enc, col.MaxDefinitionLevel(), numValues)
+		if err != nil {
+			return nil, err
 		}
-		dec := newHybridDecoder(bits.Len16(col.MaxDefinitionLevel()))
-		dec.buffered = true
-		return &levelDecoderWrapper{decoder: dec, max: col.MaxDefinitionLevel()}, nil
+		return &levelDecoderWrapper{decoder: dec, max: col.MaxDefinitionLevel(), validate: opts.strict, column: col.FlatName()}, nil
 	}
 
 	if col.MaxRepetitionLevel() == 0 {
-		rDecoder = func(parquet.Encoding) (levelDecoder, error) {
+		rDecoder = func(parquet.Encoding, int32) (levelDecoder, error) {
 			return &levelDecoderWrapper{decoder: constDecoder(0), max: col.MaxRepetitionLevel()}, nil
 		}
 	}
 
 	if col.MaxDefinitionLevel() == 0 {
-		dDecoder = func(parquet.Encoding) (levelDecoder, error) {
+		dDecoder = func(parquet.Encoding, int32) (levelDecoder, error) {
 			return &levelDecoderWrapper{decoder: constDecoder(0), max: col.MaxDefinitionLevel()}, nil
 		}
 	}
-	return readPages(reader, col, chunk.MetaData, dDecoder, rDecoder)
+	var pd *pageDecryptor
+	if dec != nil {
+		pd = dec.pages(chunk.MetaData)
+	}
+	return readPages(reader, col, chunk.MetaData, pd, dDecoder, rDecoder, opts, mem, indices)
 }
 
 func readPageData(col *Column, pages []pageReader) error {
 	s := col.getColumnStore()
+
+	// the number of nulls is only known for some pages, so the buffer may be larger than needed.
+	var maxValues int
+	for i := range pages {
+		maxValues += int(pages[i].numValues() - pages[i].numNulls())
+	}
+	if free := cap(s.values.values) - len(s.values.values); free < maxValues {
+		values := make([]interface{}, len(s.values.values), len(s.values.values)+maxValues)
+		copy(values, s.values.values)
+		s.values.values = values
+	}
+
 	for i := range pages {
 		data := make([]interface{}, pages[i].numValues())
-		n, dl, rl, err := pages[i].readValues(data)
+		n, notNull, dl, rl, err := pages[i].readValues(data)
 		if err != nil {
 			return err
 		}
@@ -394,38 +549,67 @@ func readPageData(col *Column, pages []pageReader) error {
 		s.rLevels.appendArray(rl)
 		s.dLevels.appendArray(dl)
 
-		s.values.values = append(s.values.values, data...)
+		s.values.values = append(s.values.values, data[:notNull]...)
 		s.values.noDictMode = true
 	}
 
 	return nil
 }
 
-func readRowGroup(r io.ReadSeeker, schema SchemaReader, rowGroups *parquet.RowGroup) error {
+// readRowGroup reads the selected columns of a row group. With lenient value counts, the number
+// of records of the row group is the smallest number of rows of the selected columns, and the
+// column chunks whose value counts don't match their meta data are returned.
+func readRowGroup(r io.ReadSeeker, schema SchemaReader, rowGroup int, rowGroups *parquet.RowGroup, opts *fileReaderOptions) ([]ValueCountMismatch, error) {
 	dataCols := schema.Columns()
 	schema.resetData()
 	schema.setNumRecords(rowGroups.NumRows)
+	// the data of the previous row group was released by resetting the schema, so only the
+	// memory of this row group is accounted for.
+	mem := newMemoryTracker(opts.maxMemorySize)
+	var (
+		mismatches  []ValueCountMismatch
+		lenientRows bool
+	)
 	for _, c := range dataCols {
 		idx := c.Index()
 		if len(rowGroups.Columns) <= idx {
-			return fmt.Errorf("column index %d is out of bounds", idx)
+			return nil, fmt.Errorf("column index %d is out of bounds", idx)
 		}
 		chunk := rowGroups.Columns[c.Index()]
 		if !schema.isSelected(c.flatName) {
 			if err := skipChunk(r, c, chunk); err != nil {
-				return err
+				return nil, err
 			}
 			c.data.skipped = true
 			continue
 		}
-		pages, err := readChunk(r, c, chunk)
+		pages, err := readChunk(r, c, chunk, opts, mem, nil)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if err := readPageData(c, pages); err != nil {
-			return err
+			return nil, err
+		}
+		if opts.observer != nil {
+			opts.observer.ChunkRead(chunkEncodings(rowGroup, c, chunk.MetaData, pages))
+		}
+		numValues, numRows := chunk.MetaData.NumValues, rowGroups.NumRows
+		if opts.lenientValueCounts {
+			if m := checkValueCounts(rowGroup, c, chunk.MetaData, rowGroups.NumRows); m != nil {
+				mismatches = append(mismatches, *m)
+				numValues, numRows = m.PageValues, m.PageRows
+			}
+			if !lenientRows || numRows < schema.rowGroupNumRecords() {
+				schema.setNumRecords(numRows)
+				lenientRows = true
+			}
+		}
+		if opts.strict {
+			if err := validateColumnData(c, numValues, numRows); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	return nil
+	return mismatches, nil
 }
@@ -1,6 +1,16 @@
 package goparquet
 
-import "testing"
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
 
 func TestFuzzCrashReadRowGroup(t *testing.T) {
 	data := []byte("PAR1\x150\x19,H\f0000000000" +
@@ -20,3 +30,231 @@ func TestFuzzCrashReadRowGroup(t *testing.T) {
 
 	readAllData(t, data)
 }
+
+func testDictPage(t *testing.T, values ...int64) *EncodedPage {
+	buf := &bytes.Buffer{}
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, values))
+	return &EncodedPage{
+		Header: &parquet.PageHeader{
+			Type:                 parquet.PageType_DICTIONARY_PAGE,
+			UncompressedPageSize: int32(buf.Len()),
+			CompressedPageSize:   int32(buf.Len()),
+			DictionaryPageHeader: &parquet.DictionaryPageHeader{
+				NumValues: int32(len(values)),
+				Encoding:  parquet.Encoding_PLAIN,
+			},
+		},
+		Data: buf.Bytes(),
+	}
+}
+
+func testDataPage(t *testing.T, enc parquet.Encoding, values ...int64) *EncodedPage {
+	buf := &bytes.Buffer{}
+	if enc == parquet.Encoding_RLE_DICTIONARY {
+		const bitWidth = 2
+		indices := make([]int32, len(values))
+		for i := range values {
+			indices[i] = int32(values[i])
+		}
+		buf.WriteByte(bitWidth)
+		he := newHybridEncoder(bitWidth)
+		require.NoError(t, he.init(buf))
+		require.NoError(t, he.encode(indices))
+		require.NoError(t, he.Close())
+	} else {
+		require.NoError(t, binary.Write(buf, binary.LittleEndian, values))
+	}
+	return &EncodedPage{
+		Header: &parquet.PageHeader{
+			Type:                 parquet.PageType_DATA_PAGE,
+			UncompressedPageSize: int32(buf.Len()),
+			CompressedPageSize:   int32(buf.Len()),
+			DataPageHeader: &parquet.DataPageHeader{
+				NumValues:               int32(len(values)),
+				Encoding:                enc,
+				DefinitionLevelEncoding: parquet.Encoding_RLE,
+				RepetitionLevelEncoding: parquet.Encoding_RLE,
+			},
+		},
+		Data: buf.Bytes(),
+	}
+}
+
+func TestReadDictionaryFallbackToPlain(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+
+	// two row groups, so that buffers of the first row group get reused in the second one.
+	for i := 0; i < 2; i++ {
+		require.NoError(t, w.WriteEncodedRowGroup(10, []*EncodedColumnChunk{
+			{
+				Column: "foo",
+				Pages: []*EncodedPage{
+					testDictPage(t, 100, 200, 300),
+					testDataPage(t, parquet.Encoding_RLE_DICTIONARY, 0, 1, 2, 1),
+					testDataPage(t, parquet.Encoding_RLE_DICTIONARY, 2, 2, 0),
+					testDataPage(t, parquet.Encoding_PLAIN, 400, 500, 600),
+				},
+			},
+		}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	expected := []int64{100, 200, 300, 200, 300, 300, 100, 400, 500, 600}
+	for i := 0; i < 2; i++ {
+		for _, v := range expected {
+			data, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, map[string]interface{}{"foo": v}, data)
+		}
+	}
+
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestReadDictionaryPageMissing(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.WriteEncodedRowGroup(2, []*EncodedColumnChunk{
+		{
+			Column: "foo",
+			Pages:  []*EncodedPage{testDataPage(t, parquet.Encoding_RLE_DICTIONARY, 0, 1)},
+		},
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	_, err = r.NextRow()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no dictionary page")
+}
+
+func TestReadSkipsUnknownPages(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"foo": int64(i)}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// append an index page and a page of a type unknown to this version of the format to the
+	// column chunk, and write a new footer behind them.
+	meta := r.meta.RowGroups[0].Columns[0].MetaData
+	end := meta.DataPageOffset + meta.TotalCompressedSize
+	if meta.DictionaryPageOffset != nil {
+		end = *meta.DictionaryPageOffset + meta.TotalCompressedSize
+	}
+	out := &writePosStruct{w: &bytes.Buffer{}}
+	_, err = out.Write(buf.Bytes()[:end])
+	require.NoError(t, err)
+	for _, typ := range []parquet.PageType{parquet.PageType_INDEX_PAGE, parquet.PageType(42)} {
+		require.NoError(t, writeThrift(&parquet.PageHeader{Type: typ, CompressedPageSize: 3, UncompressedPageSize: 3}, out))
+		_, err = out.Write([]byte{1, 2, 3})
+		require.NoError(t, err)
+	}
+	meta.TotalCompressedSize += out.Pos() - end
+	require.NoError(t, writeFileMetaData(out, r.meta))
+	data := out.w.(*bytes.Buffer).Bytes()
+
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["foo"])
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithStrictValidation())
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr), "unexpected error %v", err)
+	require.Contains(t, validationErr.Reason, "INDEX_PAGE")
+}
+
+func TestReadManyPagesWithNulls(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	// every page contains a single value between nulls, i.e. null, value, null.
+	var (
+		pages    []*EncodedPage
+		expected []map[string]interface{}
+	)
+	for i := 0; i < 50; i++ {
+		levels := &packedArray{}
+		levels.reset(1)
+		for _, l := range []int32{0, 1, 0} {
+			levels.appendSingle(l)
+		}
+		levels.flush()
+
+		data := &bytes.Buffer{}
+		require.NoError(t, encodeLevelsV1(data, 1, levels))
+		require.NoError(t, binary.Write(data, binary.LittleEndian, int64(i)))
+
+		pages = append(pages, &EncodedPage{
+			Header: &parquet.PageHeader{
+				Type:                 parquet.PageType_DATA_PAGE,
+				UncompressedPageSize: int32(data.Len()),
+				CompressedPageSize:   int32(data.Len()),
+				DataPageHeader: &parquet.DataPageHeader{
+					NumValues:               3,
+					Encoding:                parquet.Encoding_PLAIN,
+					DefinitionLevelEncoding: parquet.Encoding_RLE,
+					RepetitionLevelEncoding: parquet.Encoding_RLE,
+				},
+			},
+			Data: data.Bytes(),
+		})
+		expected = append(expected, map[string]interface{}{}, map[string]interface{}{"foo": int64(i)}, map[string]interface{}{})
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.WriteEncodedRowGroup(int64(len(expected)), []*EncodedColumnChunk{{Column: "foo", Pages: pages}}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithStrictValidation())
+	require.NoError(t, err)
+
+	for i, row := range expected {
+		actual, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, row, actual)
+		if i == 0 {
+			// only the non-null values of the pages are stored.
+			require.Len(t, r.GetColumnByName("foo").data.values.values, 50)
+		}
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
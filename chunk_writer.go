@@ -1,6 +1,8 @@
 package goparquet
 
 import (
+	"encoding/binary"
+	"math"
 	"sort"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -200,7 +202,7 @@ func getDictValuesEncoder(typ *parquet.SchemaElement) (valuesEncoder, error) {
 	return nil, errors.Errorf("type %s is not supported for dict value encoder", typ)
 }
 
-func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.CompressionCodec, pageFn newDataPageFunc, kvMetaData map[string]string) (*parquet.ColumnChunk, error) {
+func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.CompressionCodec, pageFn newDataPageFunc, bounds pageBounds, statsOpts statisticsOptions, writeCRC, withPageIndex bool, kvMetaData map[string]string) (*parquet.ColumnChunk, *chunkPageIndex, error) {
 	pos := w.Pos() // Save the position before writing data
 	chunkOffset := pos
 	var (
@@ -215,17 +217,18 @@ func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.Comp
 		totalComp   int64
 		totalUnComp int64
 	)
-	if col.data.useDictionary() {
+	pages, dictSize := splitDataPages(col, bounds, col.data.useDictionary())
+	if dictSize > 0 {
 		useDict = true
 		tmp := pos // make a copy, do not use the pos here
 		dictPageOffset = &tmp
-		dict := &dictPageWriter{}
+		dict := &dictPageWriter{numValues: dictSize, writeCRC: writeCRC}
 		if err := dict.init(schema, col, codec); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		compSize, unCompSize, err := dict.write(w)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		totalComp = w.Pos() - pos
 		// Header size plus the rLevel and dLevel size
@@ -234,23 +237,39 @@ func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.Comp
 		pos = w.Pos() // Move position for data pos
 	}
 
-	page := pageFn(useDict)
-
-	if err := page.init(schema, col, codec); err != nil {
-		return nil, err
+	var pageIndex *pageIndexBuilder
+	if withPageIndex {
+		pageIndex = newPageIndexBuilder(col, statsOpts)
 	}
 
-	compSize, unCompSize, err := page.write(w)
-	if err != nil {
-		return nil, err
-	}
+	dataPageOffset := pos
+	fallback := false
+	for _, dp := range pages {
+		page := pageFn(dp.dictSize > 0, writeCRC)
+		fallback = fallback || dp.dictSize == 0
 
-	totalComp += w.Pos() - pos
-	// Header size plus the rLevel and dLevel size
-	headerSize := totalComp - int64(compSize)
-	totalUnComp += int64(unCompSize) + headerSize
+		if err := page.init(schema, col, codec); err != nil {
+			return nil, nil, err
+		}
+		page.setPage(dp)
 
-	encodings := make([]parquet.Encoding, 0, 3)
+		compSize, unCompSize, err := page.write(w)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pageSize := w.Pos() - pos
+		totalComp += pageSize
+		// Header size plus the rLevel and dLevel size
+		headerSize := pageSize - int64(compSize)
+		totalUnComp += int64(unCompSize) + headerSize
+		if pageIndex != nil {
+			pageIndex.addPage(dp, pos, pageSize)
+		}
+		pos = w.Pos()
+	}
+
+	encodings := make([]parquet.Encoding, 0, 4)
 	encodings = append(encodings,
 		parquet.Encoding_RLE,
 		col.data.encoding(),
@@ -258,6 +277,10 @@ func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.Comp
 	if useDict {
 		encodings[1] = parquet.Encoding_PLAIN // In dictionary we use PLAIN for the data, not the column encoding
 		encodings = append(encodings, parquet.Encoding_RLE_DICTIONARY)
+		if fallback && col.data.encoding() != parquet.Encoding_PLAIN {
+			// pages after the fallback use the column encoding.
+			encodings = append(encodings, col.data.encoding())
+		}
 	}
 
 	keyValueMetaData := make([]*parquet.KeyValue, 0, len(kvMetaData))
@@ -273,11 +296,20 @@ func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.Comp
 	distinctCount := int64(col.data.values.numDistinctValues())
 
 	stats := &parquet.Statistics{
-		MinValue:      col.data.minValue(),
-		MaxValue:      col.data.maxValue(),
 		NullCount:     &nullCount,
 		DistinctCount: &distinctCount,
 	}
+	min, max := statsOpts.minMax(col, col.data.values.values)
+	if statsOpts.comparator(col).legacy() && isBinaryType(col.data.parquetType()) && !isDecimalElement(col.Element()) {
+		stats.Min, stats.Max = min, max
+	} else {
+		stats.MinValue, stats.MaxValue = min, max
+		// older readers only know the deprecated fields, which can be written as well for the
+		// columns whose order they interpret correctly.
+		if legacyStatisticsOrder(col.Element()) {
+			stats.Min, stats.Max = min, max
+		}
+	}
 
 	ch := &parquet.ColumnChunk{
 		FilePath:   nil, // No support for external
@@ -291,7 +323,7 @@ func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.Comp
 			TotalUncompressedSize: totalUnComp,
 			TotalCompressedSize:   totalComp,
 			KeyValueMetadata:      keyValueMetaData,
-			DataPageOffset:        pos,
+			DataPageOffset:        dataPageOffset,
 			IndexPageOffset:       nil,
 			DictionaryPageOffset:  dictPageOffset,
 			Statistics:            stats,
@@ -303,20 +335,338 @@ func writeChunk(w writePos, schema SchemaWriter, col *Column, codec parquet.Comp
 		ColumnIndexLength: nil,
 	}
 
-	return ch, nil
+	if pageIndex != nil {
+		return ch, pageIndex.finish(ch), nil
+	}
+	return ch, nil, nil
+}
+
+// statisticsOptions contains the settings that are used to compute the statistics of column chunks.
+type statisticsOptions struct {
+	binaryCmp      BinaryComparator
+	columnCmp      map[string]BinaryComparator
+	truncateLength int
+}
+
+// comparator returns the binary comparator of the column, which is binaryCmp unless it was
+// overridden for the column.
+func (o statisticsOptions) comparator(col *Column) BinaryComparator {
+	if cmp, ok := o.columnCmp[col.FlatName()]; ok {
+		return cmp
+	}
+	return o.binaryCmp
+}
+
+// minMax returns the min and max of the values of the column like valuesMinMax. The results of
+// BYTE_ARRAY columns that aren't decimals are truncated to truncateLength bytes if it's set.
+func (o statisticsOptions) minMax(col *Column, values []interface{}) (min, max []byte) {
+	cmp := o.comparator(col)
+	min, max = valuesMinMax(col.Element(), values, cmp)
+	if o.truncateLength > 0 && col.data.parquetType() == parquet.Type_BYTE_ARRAY && !isDecimalElement(col.Element()) {
+		min = cmp.truncateMin(min, o.truncateLength)
+		max = cmp.truncateMax(max, o.truncateLength)
+	}
+	return min, max
+}
+
+// valuesMinMax returns the smallest and largest of the values of the column described by elem,
+// plain encoded like the min_value and max_value statistics, in the sort order of the logical type
+// of the column. Binary values that aren't decimals are ordered by cmp, NaN values are ignored. It
+// returns nil if there are no values or if the sort order of the column's type is undefined.
+func valuesMinMax(elem *parquet.SchemaElement, values []interface{}, cmp BinaryComparator) (min, max []byte) {
+	if elem.GetType() == parquet.Type_INT96 {
+		return nil, nil
+	}
+
+	// the values are compared as they are and only the results are encoded, so that no value
+	// needs to be encoded for the comparison.
+	var minValue, maxValue interface{}
+	for _, v := range values {
+		switch v.(type) {
+		case bool, int32, int64, []byte:
+		case float32, float64:
+			if isNaN(v) {
+				continue
+			}
+		default:
+			continue
+		}
+		if minValue == nil || compareValues(elem, v, minValue, cmp) < 0 {
+			minValue = v
+		}
+		if maxValue == nil || compareValues(elem, v, maxValue, cmp) > 0 {
+			maxValue = v
+		}
+	}
+	min, max = encodeStatValue(minValue), encodeStatValue(maxValue)
+
+	// -0 and +0 are equal, so a zero is written as -0 if it's the min value and as +0 if it's
+	// the max value to be safe for readers that compare them differently.
+	switch elem.GetType() {
+	case parquet.Type_FLOAT:
+		if min != nil && math.Float32frombits(binary.LittleEndian.Uint32(min)) == 0 {
+			min = encodeStatValue(float32(math.Copysign(0, -1)))
+		}
+		if max != nil && math.Float32frombits(binary.LittleEndian.Uint32(max)) == 0 {
+			max = encodeStatValue(float32(0))
+		}
+	case parquet.Type_DOUBLE:
+		if min != nil && math.Float64frombits(binary.LittleEndian.Uint64(min)) == 0 {
+			min = encodeStatValue(math.Copysign(0, -1))
+		}
+		if max != nil && math.Float64frombits(binary.LittleEndian.Uint64(max)) == 0 {
+			max = encodeStatValue(float64(0))
+		}
+	}
+
+	return min, max
 }
 
-func writeRowGroup(w writePos, schema SchemaWriter, codec parquet.CompressionCodec, pageFn newDataPageFunc, h *flushRowGroupOptionHandle) ([]*parquet.ColumnChunk, error) {
+// encodeStatValue plain encodes the value v like the min_value and max_value statistics. It
+// returns nil for NaN values and values of unsupported types.
+func encodeStatValue(v interface{}) []byte {
+	switch typed := v.(type) {
+	case bool:
+		if typed {
+			return []byte{1}
+		}
+		return []byte{0}
+	case int32:
+		ret := make([]byte, 4)
+		binary.LittleEndian.PutUint32(ret, uint32(typed))
+		return ret
+	case int64:
+		ret := make([]byte, 8)
+		binary.LittleEndian.PutUint64(ret, uint64(typed))
+		return ret
+	case float32:
+		if math.IsNaN(float64(typed)) {
+			return nil
+		}
+		ret := make([]byte, 4)
+		binary.LittleEndian.PutUint32(ret, math.Float32bits(typed))
+		return ret
+	case float64:
+		if math.IsNaN(typed) {
+			return nil
+		}
+		ret := make([]byte, 8)
+		binary.LittleEndian.PutUint64(ret, math.Float64bits(typed))
+		return ret
+	case []byte:
+		return typed
+	}
+	return nil
+}
+
+func writeRowGroup(w writePos, schema SchemaWriter, codec parquet.CompressionCodec, columnCodec map[string]parquet.CompressionCodec, pageFn newDataPageFunc, columnPageFn map[string]newDataPageFunc, bounds pageBounds, statsOpts statisticsOptions, writeCRC, withPageIndex bool, h *flushRowGroupOptionHandle) ([]*parquet.ColumnChunk, []*chunkPageIndex, error) {
 	dataCols := schema.Columns()
 	var res = make([]*parquet.ColumnChunk, 0, len(dataCols))
+	var indexes []*chunkPageIndex
 	for _, ci := range dataCols {
-		ch, err := writeChunk(w, schema, ci, codec, pageFn, h.getMetaData(ci.FlatName()))
+		fn := pageFn
+		if colFn, ok := columnPageFn[ci.FlatName()]; ok {
+			fn = colFn
+		}
+		c := codec
+		if colCodec, ok := columnCodec[ci.FlatName()]; ok {
+			c = colCodec
+		}
+		ch, index, err := writeChunk(w, schema, ci, c, fn, bounds, statsOpts, writeCRC, withPageIndex, h.getMetaData(ci.FlatName()))
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		res = append(res, ch)
+		if index != nil {
+			indexes = append(indexes, index)
+		}
+	}
+
+	return res, indexes, nil
+}
+
+// EncodedPage is a single page that was already encoded (and optionally compressed)
+// outside of this package. Header is written as-is before Data, so the sizes, value
+// counts and encodings in the header need to be consistent with Data.
+type EncodedPage struct {
+	Header *parquet.PageHeader
+	Data   []byte
+}
+
+// EncodedColumnChunk contains all pages of a single column chunk that are written
+// using WriteEncodedRowGroup. If the chunk contains a dictionary page, it needs to be
+// the first page of the chunk.
+type EncodedColumnChunk struct {
+	// Column is the flat name of the column in dotted notation.
+	Column string
+	// Codec is the compression codec that was used to compress the data of all pages.
+	Codec parquet.CompressionCodec
+	Pages []*EncodedPage
+	// Statistics is optional and stored as-is in the column chunk meta data.
+	Statistics *parquet.Statistics
+}
+
+func pageEncodings(ph *parquet.PageHeader) ([]parquet.Encoding, int64, error) {
+	switch ph.Type {
+	case parquet.PageType_DICTIONARY_PAGE:
+		if ph.DictionaryPageHeader == nil {
+			return nil, 0, errors.New("dictionary page without dictionary page header")
+		}
+		return []parquet.Encoding{ph.DictionaryPageHeader.Encoding}, 0, nil
+	case parquet.PageType_DATA_PAGE:
+		if ph.DataPageHeader == nil {
+			return nil, 0, errors.New("data page without data page header")
+		}
+		h := ph.DataPageHeader
+		return []parquet.Encoding{h.Encoding, h.DefinitionLevelEncoding, h.RepetitionLevelEncoding}, int64(h.NumValues), nil
+	case parquet.PageType_DATA_PAGE_V2:
+		if ph.DataPageHeaderV2 == nil {
+			return nil, 0, errors.New("data page v2 without data page header v2")
+		}
+		h := ph.DataPageHeaderV2
+		return []parquet.Encoding{h.Encoding, parquet.Encoding_RLE}, int64(h.NumValues), nil
+	default:
+		return nil, 0, errors.Errorf("unsupported page type %s", ph.Type)
+	}
+}
+
+// validateEncodedChunk checks that chunk can be written as the column chunk of col in a row
+// group of numRows rows, so that all chunks of a row group are validated before any of them
+// is written.
+func validateEncodedChunk(col *Column, chunk *EncodedColumnChunk, numRows int64) error {
+	if chunk == nil {
+		return errors.Errorf("column %s: no column chunk to write", col.FlatName())
+	}
+
+	if chunk.Column != col.FlatName() {
+		return errors.Errorf("column chunk of column %s provided for column %s", chunk.Column, col.FlatName())
+	}
+
+	if _, err := parquet.CompressionCodecFromString(chunk.Codec.String()); err != nil {
+		return errors.Errorf("column %s: invalid compression codec %d", chunk.Column, chunk.Codec)
+	}
+
+	if len(chunk.Pages) == 0 {
+		return errors.Errorf("column %s: no pages to write", chunk.Column)
+	}
+
+	var (
+		numValues     int64
+		haveDataPages bool
+	)
+	for idx, page := range chunk.Pages {
+		if page == nil || page.Header == nil {
+			return errors.Errorf("column %s: page %d has no header", chunk.Column, idx)
+		}
+
+		if int(page.Header.CompressedPageSize) != len(page.Data) {
+			return errors.Errorf("column %s: page %d has compressed size %d in header but %d bytes of data", chunk.Column, idx, page.Header.CompressedPageSize, len(page.Data))
+		}
+
+		_, n, err := pageEncodings(page.Header)
+		if err != nil {
+			return errors.Wrapf(err, "column %s: page %d", chunk.Column, idx)
+		}
+
+		if page.Header.Type == parquet.PageType_DICTIONARY_PAGE {
+			if idx != 0 {
+				return errors.Errorf("column %s: dictionary page needs to be the first page but is page %d", chunk.Column, idx)
+			}
+		} else {
+			haveDataPages = true
+		}
+		numValues += n
+	}
+
+	if !haveDataPages {
+		return errors.Errorf("column %s: no data page found", chunk.Column)
+	}
+
+	// every row has exactly one value (or null) in columns that aren't repeated, and at least
+	// one in repeated columns.
+	if col.MaxRepetitionLevel() == 0 && numValues != numRows {
+		return errors.Errorf("column %s: pages contain %d values but the row group has %d rows", chunk.Column, numValues, numRows)
+	}
+	if numValues < numRows {
+		return errors.Errorf("column %s: pages contain %d values but the row group has %d rows", chunk.Column, numValues, numRows)
+	}
+
+	return nil
+}
+
+// writeEncodedChunk writes the pages of chunk, which needs to be validated with
+// validateEncodedChunk first.
+func writeEncodedChunk(w writePos, col *Column, chunk *EncodedColumnChunk, kvMetaData map[string]string) (*parquet.ColumnChunk, error) {
+	var (
+		chunkOffset    = w.Pos()
+		dataPageOffset = int64(-1)
+		dictPageOffset *int64
+		numValues      int64
+		totalComp      int64
+		totalUnComp    int64
+		encodings      []parquet.Encoding
+		seen           = make(map[parquet.Encoding]bool)
+	)
+
+	for _, page := range chunk.Pages {
+		encs, n, err := pageEncodings(page.Header)
+		if err != nil {
+			return nil, err
+		}
+
+		pos := w.Pos()
+		if page.Header.Type == parquet.PageType_DICTIONARY_PAGE {
+			tmp := pos
+			dictPageOffset = &tmp
+		} else if dataPageOffset < 0 {
+			dataPageOffset = pos
+		}
+
+		if err := writeThrift(page.Header, w); err != nil {
+			return nil, err
+		}
+		headerSize := w.Pos() - pos
+
+		if err := writeFull(w, page.Data); err != nil {
+			return nil, err
+		}
+
+		totalComp += headerSize + int64(page.Header.CompressedPageSize)
+		totalUnComp += headerSize + int64(page.Header.UncompressedPageSize)
+		numValues += n
+
+		for _, e := range encs {
+			if !seen[e] {
+				seen[e] = true
+				encodings = append(encodings, e)
+			}
+		}
 	}
 
-	return res, nil
+	keyValueMetaData := make([]*parquet.KeyValue, 0, len(kvMetaData))
+	for k, v := range kvMetaData {
+		value := v
+		keyValueMetaData = append(keyValueMetaData, &parquet.KeyValue{Key: k, Value: &value})
+	}
+	sort.Slice(keyValueMetaData, func(i, j int) bool {
+		return keyValueMetaData[i].Key < keyValueMetaData[j].Key
+	})
+
+	return &parquet.ColumnChunk{
+		FileOffset: chunkOffset,
+		MetaData: &parquet.ColumnMetaData{
+			Type:                  col.Element().GetType(),
+			Encodings:             encodings,
+			PathInSchema:          col.pathArray(),
+			Codec:                 chunk.Codec,
+			NumValues:             numValues,
+			TotalUncompressedSize: totalUnComp,
+			TotalCompressedSize:   totalComp,
+			KeyValueMetadata:      keyValueMetaData,
+			DataPageOffset:        dataPageOffset,
+			DictionaryPageOffset:  dictPageOffset,
+			Statistics:            chunk.Statistics,
+		},
+	}, nil
 }
@@ -199,6 +199,12 @@ func createColumn(field, typ string) (col *parquetschema.ColumnDefinition, field
 		col.SchemaElement.LogicalType.STRING = &parquet.StringType{}
 		col.SchemaElement.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
 		fieldHandler = byteArrayHandler
+	case "enum":
+		col.SchemaElement.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		col.SchemaElement.LogicalType = parquet.NewLogicalType()
+		col.SchemaElement.LogicalType.ENUM = &parquet.EnumType{}
+		col.SchemaElement.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM)
+		fieldHandler = byteArrayHandler
 	case "byte_array":
 		col.SchemaElement.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
 		fieldHandler = byteArrayHandler
@@ -323,6 +329,7 @@ var validTypes = map[string]bool{
 	"double":     true,
 	"byte_array": true,
 	"string":     true,
+	"enum":       true,
 	"int":        true,
 	"json":       true,
 	// TODO: support more data types
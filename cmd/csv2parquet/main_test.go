@@ -138,6 +138,14 @@ func TestCreateColumn(t *testing.T) {
 			ExpectedLogicalType:   &parquet.LogicalType{STRING: &parquet.StringType{}},
 			ExpectedConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
 		},
+		"enum": {
+			Field:                 "foo",
+			Type:                  "enum",
+			ExpectErr:             false,
+			ExpectedType:          parquet.Type_BYTE_ARRAY,
+			ExpectedLogicalType:   &parquet.LogicalType{ENUM: &parquet.EnumType{}},
+			ExpectedConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM),
+		},
 	}
 
 	for testName, tt := range tests {
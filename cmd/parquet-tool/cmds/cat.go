@@ -3,11 +3,23 @@ package cmds
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	catColumns *[]string
+	catFilters *[]string
+	catLimit   *int
+	catFormat  *string
+)
+
 func init() {
+	catColumns = catCmd.PersistentFlags().StringSliceP("columns", "c", nil, "The columns to print in dotted notation, all columns are printed if none are provided")
+	catFilters = catCmd.PersistentFlags().StringArrayP("filter", "f", nil, "Only print the rows that match the filter <column><operator><value>, e.g. age>=18. The operators are == (or =), !=, <, <=, > and >=. Row groups whose statistics don't match all filters are skipped")
+	catLimit = catCmd.PersistentFlags().IntP("limit", "n", -1, "The maximum number of records to print, all records are printed if it's negative")
+	catFormat = catCmd.PersistentFlags().StringP("format", "o", "text", "The output format, valid values are "+strings.Join(outputFormats, ", "))
 	rootCmd.AddCommand(catCmd)
 }
 
@@ -20,7 +32,13 @@ var catCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if err := catFile(os.Stdout, args[0], -1); err != nil {
+		opts := catOptions{
+			columns: *catColumns,
+			filters: *catFilters,
+			limit:   *catLimit,
+			format:  *catFormat,
+		}
+		if err := catFile(os.Stdout, args[0], opts); err != nil {
 			log.Fatal(err)
 		}
 	},
@@ -0,0 +1,109 @@
+package cmds
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCatTestFile writes a test file with 9 rows in 3 row groups to a temporary directory, which
+// needs to be removed by the caller.
+func writeCatTestFile(t *testing.T) (dir, file string) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		required group info {
+			optional double score;
+		}
+	}`)
+	require.NoError(t, err)
+
+	dir, err = ioutil.TempDir("", "parquet-tool")
+	require.NoError(t, err)
+
+	file = filepath.Join(dir, "test.parquet")
+	fl, err := os.Create(file)
+	require.NoError(t, err)
+	defer fl.Close()
+
+	w := goparquet.NewFileWriter(fl, goparquet.WithSchemaDefinition(sd))
+	for i := 0; i < 9; i++ {
+		row := map[string]interface{}{
+			"id":   int64(i),
+			"info": map[string]interface{}{"score": float64(i) / 2},
+		}
+		if i != 4 {
+			row["name"] = []byte{byte('a' + i)}
+		}
+		require.NoError(t, w.AddData(row))
+		if i%3 == 2 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+
+	return dir, file
+}
+
+func TestCatFile(t *testing.T) {
+	dir, file := writeCatTestFile(t)
+	defer os.RemoveAll(dir)
+
+	tests := map[string]struct {
+		opts     catOptions
+		expected string
+		logged   string
+	}{
+		"json": {
+			opts:     catOptions{limit: 2, format: "json"},
+			expected: "{\"id\":0,\"info\":{\"score\":0},\"name\":\"a\"}\n{\"id\":1,\"info\":{\"score\":0.5},\"name\":\"b\"}\n",
+		},
+		"csv": {
+			opts:     catOptions{columns: []string{"name", "info"}, limit: -1, filters: []string{"id>=3", "id<6"}, format: "csv"},
+			expected: "name,info.score\nd,1.5\n,2\nf,2.5\n",
+			logged:   "Skipped 2 of 3 inspected row groups",
+		},
+		"table": {
+			opts:     catOptions{columns: []string{"id", "name"}, limit: -1, filters: []string{"name=h"}, format: "table"},
+			expected: "id  name\n7   h\n",
+			logged:   "Skipped 2 of 3 inspected row groups",
+		},
+		"text": {
+			opts:     catOptions{columns: []string{"name"}, limit: -1, filters: []string{"info.score > 3.5"}, format: "text"},
+			expected: "name = i\n\n",
+			logged:   "Skipped 2 of 3 inspected row groups",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var logged bytes.Buffer
+			log.SetOutput(&logged)
+			defer log.SetOutput(os.Stderr)
+
+			var buf bytes.Buffer
+			require.NoError(t, catFile(&buf, file, tt.opts))
+			require.Equal(t, tt.expected, buf.String())
+			require.Contains(t, logged.String(), tt.logged)
+		})
+	}
+
+	invalid := []catOptions{
+		{columns: []string{"unknown"}, format: "json"},
+		{filters: []string{"unknown=1"}, format: "json"},
+		{filters: []string{"id"}, format: "json"},
+		{filters: []string{"id=abc"}, format: "json"},
+		{filters: []string{"info=1"}, format: "json"},
+		{format: "xml"},
+	}
+	for _, opts := range invalid {
+		require.Error(t, catFile(&bytes.Buffer{}, file, opts), "%+v", opts)
+	}
+}
@@ -0,0 +1,47 @@
+package cmds
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/internal/rowfilter"
+)
+
+// operators contains the supported filter operators. Longer operators come first, so that e.g.
+// <= isn't parsed as <.
+var operators = []struct {
+	token string
+	op    rowfilter.Op
+}{
+	{"==", rowfilter.Eq},
+	{"!=", rowfilter.Ne},
+	{"<=", rowfilter.Le},
+	{">=", rowfilter.Ge},
+	{"=", rowfilter.Eq},
+	{"<", rowfilter.Lt},
+	{">", rowfilter.Gt},
+}
+
+// parseFilter parses an expression of the form <column><operator><value>, e.g. age>=18.
+func parseFilter(expr string) (*rowfilter.Filter, error) {
+	pos, token := -1, ""
+	var op rowfilter.Op
+	for _, o := range operators {
+		if i := strings.Index(expr, o.token); i >= 0 && (pos < 0 || i < pos) {
+			pos, token, op = i, o.token, o.op
+		}
+	}
+	if pos <= 0 {
+		tokens := make([]string, len(operators))
+		for i, o := range operators {
+			tokens[i] = o.token
+		}
+		return nil, fmt.Errorf("invalid filter %q, expected <column><operator><value> with one of the operators %s", expr, strings.Join(tokens, " "))
+	}
+
+	return &rowfilter.Filter{
+		Column: strings.TrimSpace(expr[:pos]),
+		Op:     op,
+		Value:  strings.TrimSpace(expr[pos+len(token):]),
+	}, nil
+}
@@ -0,0 +1,164 @@
+package cmds
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/internal/rowfilter"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// outputFormats contains the supported values of the --format flag.
+var outputFormats = []string{"text", "json", "csv", "table"}
+
+// rowWriter writes the rows of a parquet file in a certain output format.
+type rowWriter interface {
+	write(row map[string]interface{}) error
+	flush() error
+}
+
+// newRowWriter creates a rowWriter for the format. columns are the flat names of the data columns
+// that are written by the csv and table formats.
+func newRowWriter(w io.Writer, format string, sd *parquetschema.SchemaDefinition, columns []string) (rowWriter, error) {
+	switch format {
+	case "text":
+		return &textWriter{w: w}, nil
+	case "json":
+		return &jsonWriter{enc: json.NewEncoder(w), sd: sd}, nil
+	case "csv":
+		cw := &csvWriter{w: csv.NewWriter(w), columns: columns}
+		return cw, cw.w.Write(columns)
+	case "table":
+		tw := &tableWriter{w: tabwriter.NewWriter(w, 0, 8, 2, ' ', 0), columns: columns}
+		_, err := fmt.Fprintln(tw.w, strings.Join(columns, "\t"))
+		return tw, err
+	}
+	return nil, fmt.Errorf("invalid format %q, valid formats are %s", format, strings.Join(outputFormats, ", "))
+}
+
+type textWriter struct {
+	w io.Writer
+}
+
+func (t *textWriter) write(row map[string]interface{}) error {
+	printData(t.w, row, "")
+	_, err := fmt.Fprintln(t.w)
+	return err
+}
+
+func (t *textWriter) flush() error {
+	return nil
+}
+
+// jsonWriter writes every row as a JSON object on a separate line.
+type jsonWriter struct {
+	enc *json.Encoder
+	sd  *parquetschema.SchemaDefinition
+}
+
+func (j *jsonWriter) write(row map[string]interface{}) error {
+	return j.enc.Encode(&goparquet.Row{Values: row, Schema: j.sd})
+}
+
+func (j *jsonWriter) flush() error {
+	return nil
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func (c *csvWriter) write(row map[string]interface{}) error {
+	return c.w.Write(cells(row, c.columns))
+}
+
+func (c *csvWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type tableWriter struct {
+	w       *tabwriter.Writer
+	columns []string
+}
+
+func (t *tableWriter) write(row map[string]interface{}) error {
+	values := cells(row, t.columns)
+	for i := range values {
+		// tabs and newlines would break the layout of the table.
+		values[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(values[i])
+	}
+	_, err := fmt.Fprintln(t.w, strings.Join(values, "\t"))
+	return err
+}
+
+func (t *tableWriter) flush() error {
+	return t.w.Flush()
+}
+
+// cells returns the values of the columns in row formatted as text.
+func cells(row map[string]interface{}, columns []string) []string {
+	res := make([]string, len(columns))
+	for i, col := range columns {
+		res[i] = formatCell(rowfilter.Lookup(row, strings.Split(col, ".")))
+	}
+	return res
+}
+
+// formatCell formats v as text. Binary values are printed as strings, values that aren't
+// primitive are printed as JSON.
+func formatCell(v interface{}) string {
+	switch x := textValue(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case bool, int32, int64, float32, float64:
+		return fmt.Sprint(x)
+	default:
+		data, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Sprint(x)
+		}
+		return string(data)
+	}
+}
+
+// textValue converts all binary values within v to strings.
+func textValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case []byte:
+		return string(x)
+	case [][]byte:
+		res := make([]string, len(x))
+		for i := range x {
+			res[i] = string(x[i])
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(x))
+		for i := range x {
+			res[i] = textValue(x[i])
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(x))
+		for k := range x {
+			res[k] = textValue(x[k])
+		}
+		return res
+	case []map[string]interface{}:
+		res := make([]interface{}, len(x))
+		for i := range x {
+			res[i] = textValue(x[i])
+		}
+		return res
+	}
+	return v
+}
@@ -23,7 +23,7 @@ var headCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if err := catFile(os.Stdout, args[0], *recordCount); err != nil {
+		if err := catFile(os.Stdout, args[0], catOptions{limit: *recordCount, format: "text"}); err != nil {
 			log.Fatal(err)
 		}
 	},
@@ -9,9 +9,23 @@ import (
 	"text/tabwriter"
 
 	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/internal/rowfilter"
 )
 
-func catFile(w io.Writer, address string, n int) error {
+// catOptions contains the options of the cat and head commands.
+type catOptions struct {
+	// columns are the names of the columns to print in dotted notation, all columns are printed
+	// if it's empty.
+	columns []string
+	// filters are expressions of the form <column><operator><value> that all need to match for
+	// a row to be printed.
+	filters []string
+	// limit is the maximum number of rows to print, or -1 to print all rows.
+	limit  int
+	format string
+}
+
+func catFile(w io.Writer, address string, opts catOptions) error {
 	fl, err := os.Open(address)
 	if err != nil {
 		return fmt.Errorf("can not open the file: %q", err)
@@ -23,18 +37,57 @@ func catFile(w io.Writer, address string, n int) error {
 		return fmt.Errorf("failed to read the parquet header: %q", err)
 	}
 
-	for i := 0; (n == -1) || i < n; i++ {
+	q := &rowfilter.Query{Columns: opts.columns}
+	for _, expr := range opts.filters {
+		f, err := parseFilter(expr)
+		if err != nil {
+			return err
+		}
+		q.Filters = append(q.Filters, f)
+	}
+	if err := q.Prepare(reader); err != nil {
+		return err
+	}
+
+	var columns []string
+	for _, col := range reader.Columns() {
+		if q.Projected(col.FlatName()) {
+			columns = append(columns, col.FlatName())
+		}
+	}
+
+	rw, err := newRowWriter(w, opts.format, reader.GetSchemaDefinition(), columns)
+	if err != nil {
+		return err
+	}
+
+	for written := 0; opts.limit < 0 || written < opts.limit; {
 		data, err := reader.NextRow()
 		if err == io.EOF {
-			return nil
+			break
 		}
 		if err != nil {
 			log.Printf("Reading data failed with error, skip current row group: %q", err)
 			continue
 		}
 
-		printData(w, data, "")
-		fmt.Println()
+		if !q.Match(data) {
+			continue
+		}
+		q.Project(data)
+
+		if err := rw.write(data); err != nil {
+			return err
+		}
+		written++
+	}
+
+	if err := rw.flush(); err != nil {
+		return err
+	}
+
+	if len(q.Filters) > 0 {
+		log.Printf("Skipped %d of %d inspected row groups based on their statistics", q.Skipped, q.RowGroups)
 	}
 
 	return nil
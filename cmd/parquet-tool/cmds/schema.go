@@ -32,6 +32,6 @@ var schemaCmd = &cobra.Command{
 			log.Fatalf("Failed to read the parquet header: %q", err)
 		}
 
-		fmt.Print(reader.GetSchemaDefinition())
+		fmt.Print(reader.String())
 	},
 }
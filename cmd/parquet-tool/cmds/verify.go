@@ -0,0 +1,195 @@
+package cmds
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sync"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyDeep     *bool
+	verifyParallel *int
+)
+
+func init() {
+	verifyDeep = verifyCmd.PersistentFlags().Bool("deep", false, "Decode all values and check them against the statistics of the column chunks")
+	verifyParallel = verifyCmd.PersistentFlags().IntP("parallel", "p", runtime.NumCPU(), "The number of column chunks that are verified in parallel")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify file-name.parquet",
+	Short: "Verify the integrity of all column chunks and print a JSON report",
+	Long: `Verify the integrity of all column chunks of the parquet file and print a JSON report.
+The page headers, the value counts and the CRC32 checksums of all pages are checked,
+and every page is decompressed. With --deep, all values are decoded as well and checked
+against the statistics of the column chunks. The command exits with status 2 if problems
+were found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			_ = cmd.Usage()
+			os.Exit(1)
+		}
+
+		report, err := verifyFile(args[0], *verifyDeep, *verifyParallel)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := writeVerifyReport(os.Stdout, report); err != nil {
+			log.Fatal(err)
+		}
+
+		if !report.Valid {
+			os.Exit(2)
+		}
+	},
+}
+
+type verifyReport struct {
+	File      string               `json:"file"`
+	Deep      bool                 `json:"deep"`
+	Valid     bool                 `json:"valid"`
+	NumRows   int64                `json:"num_rows"`
+	RowGroups int                  `json:"row_groups"`
+	Chunks    []*verifyChunkReport `json:"chunks"`
+}
+
+type verifyChunkReport struct {
+	RowGroup        int      `json:"row_group"`
+	Column          string   `json:"column"`
+	Valid           bool     `json:"valid"`
+	DataPages       int      `json:"data_pages"`
+	DictionaryPages int      `json:"dictionary_pages"`
+	NumValues       int64    `json:"num_values"`
+	ValuesChecked   bool     `json:"values_checked"`
+	Problems        []string `json:"problems,omitempty"`
+}
+
+func writeVerifyReport(w io.Writer, report *verifyReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// verifyFile validates all column chunks of the file with parallel workers. Every worker opens
+// the file on its own, as a FileReader can't be used concurrently.
+func verifyFile(file string, deep bool, parallel int) (*verifyReport, error) {
+	reader, closeFile, err := openVerifyReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile()
+
+	report := &verifyReport{
+		File:      file,
+		Deep:      deep,
+		Valid:     true,
+		NumRows:   reader.NumRows(),
+		RowGroups: reader.RowGroupCount(),
+	}
+
+	for rg := 0; rg < reader.RowGroupCount(); rg++ {
+		for _, col := range reader.Columns() {
+			report.Chunks = append(report.Chunks, &verifyChunkReport{RowGroup: rg, Column: col.FlatName()})
+		}
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	chunks := make(chan *verifyChunkReport)
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := verifyChunks(file, deep, chunks); err != nil {
+				errOnce.Do(func() { firstErr = err })
+			}
+		}()
+	}
+	for _, chunk := range report.Chunks {
+		chunks <- chunk
+	}
+	close(chunks)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	for _, chunk := range report.Chunks {
+		if !chunk.Valid {
+			report.Valid = false
+		}
+	}
+
+	return report, nil
+}
+
+// verifyChunks validates the chunks it receives until the channel is closed. The chunks are
+// drained even if an error occurred so that the sender isn't blocked.
+func verifyChunks(file string, deep bool, chunks <-chan *verifyChunkReport) error {
+	reader, closeFile, err := openVerifyReader(file)
+	if err != nil {
+		for range chunks {
+		}
+		return err
+	}
+	defer closeFile()
+
+	var firstErr error
+	for chunk := range chunks {
+		if firstErr != nil {
+			continue
+		}
+
+		var res *goparquet.ChunkValidationReport
+		if deep {
+			res, err = reader.ValidateValues(chunk.RowGroup, chunk.Column)
+		} else {
+			res, err = reader.Validate(chunk.RowGroup, chunk.Column)
+		}
+		if err != nil {
+			firstErr = fmt.Errorf("validating column %s in row group %d failed: %w", chunk.Column, chunk.RowGroup, err)
+			continue
+		}
+
+		chunk.Valid = res.Valid()
+		chunk.DataPages = res.DataPages
+		chunk.DictionaryPages = res.DictionaryPages
+		chunk.NumValues = res.NumValues
+		chunk.ValuesChecked = res.ValuesChecked
+		chunk.Problems = res.Problems
+	}
+
+	return firstErr
+}
+
+func openVerifyReader(file string) (*goparquet.FileReader, func(), error) {
+	fl, err := os.Open(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can not open the file: %w", err)
+	}
+
+	reader, err := goparquet.NewFileReader(fl)
+	if err != nil {
+		fl.Close()
+		return nil, nil, fmt.Errorf("failed to read the parquet header: %w", err)
+	}
+
+	return reader, func() { fl.Close() }, nil
+}
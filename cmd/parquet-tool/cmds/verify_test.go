@@ -0,0 +1,67 @@
+package cmds
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyFile(t *testing.T) {
+	dir, file := writeCatTestFile(t)
+	defer os.RemoveAll(dir)
+
+	for _, deep := range []bool{false, true} {
+		report, err := verifyFile(file, deep, 2)
+		require.NoError(t, err)
+		require.True(t, report.Valid, "%+v", report.Chunks)
+		require.Equal(t, int64(9), report.NumRows)
+		require.Equal(t, 3, report.RowGroups)
+		require.Len(t, report.Chunks, 9)
+		for _, chunk := range report.Chunks {
+			require.True(t, chunk.Valid)
+			require.Equal(t, int64(3), chunk.NumValues)
+			require.Equal(t, deep, chunk.ValuesChecked)
+		}
+		require.Equal(t, 1, report.Chunks[3].RowGroup)
+		require.Equal(t, "id", report.Chunks[3].Column)
+
+		var buf bytes.Buffer
+		require.NoError(t, writeVerifyReport(&buf, report))
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		require.Equal(t, true, decoded["valid"])
+		require.Equal(t, deep, decoded["deep"])
+	}
+
+	// corrupt the last byte of the first id chunk, which isn't compressed, so that only decoding
+	// the values reveals the problem.
+	data, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	reader, err := goparquet.NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	ranges, err := reader.ByteRanges([]string{"id"}, 0)
+	require.NoError(t, err)
+	data[ranges[0].Chunk.Offset+ranges[0].Chunk.Length-1] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(file, data, 0644))
+
+	report, err := verifyFile(file, false, 2)
+	require.NoError(t, err)
+	require.True(t, report.Valid, "%+v", report.Chunks)
+
+	report, err = verifyFile(file, true, 2)
+	require.NoError(t, err)
+	require.False(t, report.Valid)
+	require.False(t, report.Chunks[0].Valid)
+	require.NotEmpty(t, report.Chunks[0].Problems)
+	for _, chunk := range report.Chunks[1:] {
+		require.True(t, chunk.Valid, "%+v", chunk)
+	}
+
+	_, err = verifyFile(dir+"/unknown.parquet", false, 1)
+	require.Error(t, err)
+}
@@ -0,0 +1,199 @@
+package goparquet
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// WriteColumnBatch adds the values and levels of a single column to the current row group, so
+// that data that is already organized in columns can be written without assembling rows first.
+// path is the flat name of the data column in dotted notation. values contains the non-null
+// values of the batch, either as a typed slice like []int64 or [][]byte, or as []interface{}.
+// Values of logical types can be provided in the same Go types as for AddData, e.g. Decimal
+// values or decimal strings for DECIMAL columns, time.Time for DATE and TIMESTAMP columns and
+// UUID strings for UUID columns.
+// dLevels and rLevels contain the definition and repetition level of every value including
+// nulls, a value of values is used for every definition level that equals the maximum
+// definition level of the column. dLevels can be nil if the column has no definition levels,
+// and rLevels can be nil if the column has no repetition levels.
+//
+// Batches have to consist of whole records, so the first repetition level of a batch must be 0.
+// The columns can be written in any order and in multiple batches, but all data columns need to
+// contain the same number of records when the row group is flushed. Batches can't be mixed with
+// records added using AddData in the same row group, and the row group isn't flushed
+// automatically.
+func (fw *FileWriter) WriteColumnBatch(path string, values interface{}, dLevels, rLevels []uint16) error {
+	if fw.tx != nil {
+		return errors.New("can't write column batch while a transaction is in progress")
+	}
+	if len(fw.copiedChunks) > 0 {
+		return errors.New("can't write column batch while column chunks of the current row group are copied")
+	}
+	if fw.rowGroupNumRecords() > 0 {
+		return errors.New("can't write column batch while the current row group contains records added using AddData")
+	}
+	if fw.int96Timestamps {
+		return errors.New("can't write column batch with INT96 timestamps")
+	}
+	if fw.sorting != nil && fw.sorting.mode != SortUnverified {
+		return errors.New("can't write column batch while the order of the sorting columns is verified or established by the writer")
+	}
+	if err := fw.checkNewRowGroupLimit(); err != nil {
+		return err
+	}
+
+	col := fw.GetColumnByName(path)
+	if col == nil || !col.DataColumn() {
+		return errors.Errorf("column %q not found", path)
+	}
+
+	vals, err := batchValues(values)
+	if err != nil {
+		return errors.Wrapf(err, "column %s", path)
+	}
+
+	maxD, maxR := col.MaxDefinitionLevel(), col.MaxRepetitionLevel()
+	if dLevels == nil {
+		if maxD > 0 {
+			return errors.Errorf("column %s requires definition levels", path)
+		}
+		dLevels = make([]uint16, len(vals))
+	}
+	if rLevels == nil {
+		if maxR > 0 {
+			return errors.Errorf("column %s requires repetition levels", path)
+		}
+		rLevels = make([]uint16, len(dLevels))
+	}
+	if len(dLevels) != len(rLevels) {
+		return errors.Errorf("column %s: got %d definition levels but %d repetition levels", path, len(dLevels), len(rLevels))
+	}
+	if len(rLevels) > 0 && rLevels[0] != 0 {
+		return errors.Errorf("column %s: batch doesn't start with a new record", path)
+	}
+
+	// repeatedDefs contains the definition level of the element that is repeated at every
+	// repetition level, and depth the number of repeated elements that the previous value is
+	// nested in. A value can only repeat elements that exist for the previous value, and all
+	// elements up to the repeated one exist for the value itself.
+	repeatedDefs := fw.repeatedDefinitionLevels(path)
+	var (
+		defined int
+		records int64
+		depth   uint16
+	)
+	for i := range dLevels {
+		if dLevels[i] > maxD {
+			return errors.Errorf("column %s: definition level %d exceeds the maximum of %d", path, dLevels[i], maxD)
+		}
+		if rLevels[i] > maxR {
+			return errors.Errorf("column %s: repetition level %d exceeds the maximum of %d", path, rLevels[i], maxR)
+		}
+		if rLevels[i] > depth {
+			return errors.Errorf("column %s: repetition level %d of level %d exceeds the %d repeated elements of the previous value", path, rLevels[i], i, depth)
+		}
+		if rLevels[i] > 0 && dLevels[i] < repeatedDefs[rLevels[i]-1] {
+			return errors.Errorf("column %s: definition level %d of level %d doesn't define the element repeated at repetition level %d", path, dLevels[i], i, rLevels[i])
+		}
+		if dLevels[i] == maxD {
+			defined++
+		}
+		if rLevels[i] == 0 {
+			records++
+		}
+
+		depth = 0
+		for depth < uint16(len(repeatedDefs)) && repeatedDefs[depth] <= dLevels[i] {
+			depth++
+		}
+	}
+	if defined != len(vals) {
+		return errors.Errorf("column %s: levels define %d values but %d values were provided", path, defined, len(vals))
+	}
+
+	cs := col.getColumnStore()
+	if len(vals) > 0 && cs.alwaysNull() {
+		return errors.Errorf("column %s is annotated as UNKNOWN and can only contain null values", path)
+	}
+
+	fw.prepareColumns()
+
+	// all values are checked before they are added, so that a failed batch doesn't leave
+	// partial data behind. Values of logical types are converted like in AddData.
+	for i := range vals {
+		converted, err := cs.convertValue(vals[i])
+		if err != nil {
+			return errors.Wrapf(err, "column %s", path)
+		}
+		v, err := cs.getValues(converted)
+		if err != nil {
+			return errors.Wrapf(err, "column %s", path)
+		}
+		if len(v) != 1 {
+			return errors.Errorf("column %s: value %d is not a single value", path, i)
+		}
+		vals[i] = v[0]
+	}
+
+	var next int
+	for i := range dLevels {
+		cs.appendRDLevel(rLevels[i], dLevels[i])
+		if dLevels[i] < maxD {
+			cs.values.addValue(nil, 0)
+			continue
+		}
+		cs.values.addValue(vals[next], cs.sizeOf(vals[next]))
+		next++
+	}
+
+	if fw.columnBatchRecords == nil {
+		fw.columnBatchRecords = make(map[string]int64)
+	}
+	fw.columnBatchRecords[path] += records
+
+	return nil
+}
+
+// finishColumnBatches checks that all data columns of the current row group contain the same
+// number of records written using WriteColumnBatch, and sets the number of records of the row
+// group.
+func (fw *FileWriter) finishColumnBatches() error {
+	var records int64
+	for i, col := range fw.Columns() {
+		n, ok := fw.columnBatchRecords[col.FlatName()]
+		if !ok {
+			return errors.Errorf("no column batch was written for column %s in the current row group", col.FlatName())
+		}
+		if i > 0 && n != records {
+			return errors.Errorf("column %s contains %d records but column %s contains %d records", col.FlatName(), n, fw.Columns()[0].FlatName(), records)
+		}
+		records = n
+	}
+
+	fw.SchemaWriter.setNumRecords(records)
+	fw.columnBatchRecords = nil
+
+	return nil
+}
+
+// batchValues converts a slice of values to []interface{}.
+func batchValues(values interface{}) ([]interface{}, error) {
+	if values == nil {
+		return nil, nil
+	}
+	if vals, ok := values.([]interface{}); ok {
+		return append([]interface{}(nil), vals...), nil
+	}
+
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice {
+		return nil, errors.Errorf("values must be a slice, got %T", values)
+	}
+
+	vals := make([]interface{}, v.Len())
+	for i := range vals {
+		vals[i] = v.Index(i).Interface()
+	}
+	return vals, nil
+}
@@ -0,0 +1,144 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteColumnBatch(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		repeated int32 tags;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+
+	require.NoError(t, w.WriteColumnBatch("id", []int64{1, 2}, nil, nil))
+	require.NoError(t, w.WriteColumnBatch("id", []interface{}{int64(3)}, nil, nil))
+	require.NoError(t, w.WriteColumnBatch("name", [][]byte{[]byte("a"), []byte("c")}, []uint16{1, 0, 1}, nil))
+	require.NoError(t, w.WriteColumnBatch("tags", []int32{1, 2, 3}, []uint16{1, 1, 0, 1}, []uint16{0, 1, 0, 0}))
+
+	// a failed batch doesn't change the column.
+	require.Error(t, w.WriteColumnBatch("unknown", []int64{1}, nil, nil))
+	require.Error(t, w.WriteColumnBatch("id", 42, nil, nil))
+	require.Error(t, w.WriteColumnBatch("id", []int32{1}, nil, nil))
+	require.Error(t, w.WriteColumnBatch("name", [][]byte{[]byte("a")}, nil, nil))
+	require.Error(t, w.WriteColumnBatch("name", [][]byte{[]byte("a")}, []uint16{2}, nil))
+	require.Error(t, w.WriteColumnBatch("name", [][]byte{[]byte("a")}, []uint16{0}, nil))
+	require.Error(t, w.WriteColumnBatch("tags", []int32{1}, []uint16{1}, []uint16{1}))
+	require.Error(t, w.WriteColumnBatch("tags", []int32{1}, []uint16{1}, []uint16{0, 1}))
+	require.Error(t, w.AddData(map[string]interface{}{"id": int64(4)}))
+
+	require.NoError(t, w.FlushRowGroup())
+
+	// all columns need the same number of records.
+	require.NoError(t, w.WriteColumnBatch("id", []int64{4}, nil, nil))
+	require.Error(t, w.FlushRowGroup())
+	require.NoError(t, w.WriteColumnBatch("name", nil, []uint16{0}, nil))
+	require.NoError(t, w.WriteColumnBatch("tags", []int32{4}, []uint16{1, 0}, []uint16{0, 0}))
+	require.Error(t, w.FlushRowGroup())
+	require.NoError(t, w.WriteColumnBatch("id", []int64{5}, nil, nil))
+	require.NoError(t, w.WriteColumnBatch("name", [][]byte{[]byte("e")}, []uint16{1}, nil))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 2, r.RowGroupCount())
+
+	expected := []map[string]interface{}{
+		{"id": int64(1), "name": []byte("a"), "tags": []int32{1, 2}},
+		{"id": int64(2)},
+		{"id": int64(3), "name": []byte("c"), "tags": []int32{3}},
+		{"id": int64(4), "tags": []int32{4}},
+		{"id": int64(5), "name": []byte("e")},
+	}
+	for _, row := range expected {
+		actual, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, row, actual)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestWriteColumnBatchLevelStructure(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		repeated group a {
+			repeated int64 b;
+		}
+	}`)
+	require.NoError(t, err)
+
+	w := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+
+	// b can't be repeated in a record without any a.
+	err = w.WriteColumnBatch("a.b", []int64{7}, []uint16{0, 2}, []uint16{0, 2})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "repetition level 2 of level 1 exceeds")
+	// a value that repeats a has to define a.
+	err = w.WriteColumnBatch("a.b", []int64{7}, []uint16{2, 0}, []uint16{0, 1})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "definition level 0 of level 1 doesn't define")
+	// a value that repeats b has to define b.
+	err = w.WriteColumnBatch("a.b", []int64{7}, []uint16{2, 1}, []uint16{0, 2})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "definition level 1 of level 1 doesn't define")
+
+	require.NoError(t, w.WriteColumnBatch("a.b", []int64{1, 2, 3}, []uint16{2, 2, 1, 0, 2}, []uint16{0, 2, 1, 0, 0}))
+	require.Equal(t, int64(3), w.columnBatchRecords["a.b"])
+}
+
+func TestWriteColumnBatchLogicalTypes(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 amount (DECIMAL(18, 2));
+		optional fixed_len_byte_array(8) price (DECIMAL(10, 3));
+		required int32 day (DATE);
+		required int64 ts (TIMESTAMP(MILLIS, true));
+		required fixed_len_byte_array(16) id (UUID);
+	}`)
+	require.NoError(t, err)
+
+	day := time.Date(2020, 9, 13, 0, 0, 0, 0, time.UTC)
+	ts := time.Date(2020, 9, 13, 14, 26, 40, 123000000, time.UTC)
+	id := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.WriteColumnBatch("amount", []interface{}{NewDecimal(12345, 2), "-1.50"}, nil, nil))
+	require.NoError(t, w.WriteColumnBatch("price", []string{"1.234"}, []uint16{1, 0}, nil))
+	require.NoError(t, w.WriteColumnBatch("day", []time.Time{day, day.AddDate(0, 0, -1)}, nil, nil))
+	require.NoError(t, w.WriteColumnBatch("ts", []time.Time{ts, ts}, nil, nil))
+	require.NoError(t, w.WriteColumnBatch("id", []string{id, id}, nil, nil))
+
+	// the values are converted before any of them is added.
+	require.Error(t, w.WriteColumnBatch("amount", []string{"1.00", "foo"}, nil, nil))
+	require.Error(t, w.WriteColumnBatch("id", []string{"foo"}, nil, nil))
+
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithUUIDFormat(UUIDString))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.NumRows())
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int64(12345), row["amount"])
+	require.Equal(t, TimeToDate(day), row["day"])
+	require.Equal(t, ts.UnixNano()/int64(time.Millisecond), row["ts"])
+	require.Equal(t, id, row["id"])
+	require.NotNil(t, row["price"])
+
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int64(-150), row["amount"])
+	require.Equal(t, TimeToDate(day)-1, row["day"])
+	require.NotContains(t, row, "price")
+}
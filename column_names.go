@@ -0,0 +1,108 @@
+package goparquet
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// DuplicateColumnNames determines how a FileReader handles columns and groups with the same flat
+// name, e.g. two fields with the same name in a group, which some writers produce.
+type DuplicateColumnNames int
+
+const (
+	// DuplicateColumnNamesAllow keeps the duplicate names. Columns with a duplicate flat name
+	// can't be looked up by their name and have to be accessed by their index, e.g. using
+	// GetColumnByIndex. In the rows returned by NextRow, the value of the last of the fields
+	// with the same name is kept. This is the default.
+	DuplicateColumnNamesAllow DuplicateColumnNames = iota
+	// DuplicateColumnNamesError rejects files that contain duplicate flat names.
+	DuplicateColumnNamesError
+	// DuplicateColumnNamesRename renames all but the first field with the same flat name by
+	// appending the suffix _2, _3 and so on to its name.
+	DuplicateColumnNamesRename
+)
+
+func (d DuplicateColumnNames) String() string {
+	switch d {
+	case DuplicateColumnNamesAllow:
+		return "allow"
+	case DuplicateColumnNamesError:
+		return "error"
+	case DuplicateColumnNamesRename:
+		return "rename"
+	}
+	return fmt.Sprintf("DuplicateColumnNames(%d)", int(d))
+}
+
+// WithDuplicateColumnNames sets how columns with the same flat name in the schema of the file are
+// handled. By default, the duplicate names are kept, see DuplicateColumnNamesAllow.
+func WithDuplicateColumnNames(mode DuplicateColumnNames) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.duplicateNames = mode
+	}
+}
+
+// resolveDuplicateNames checks the flat names of all columns and groups of the schema and handles
+// duplicates according to mode.
+func (r *schema) resolveDuplicateNames(mode DuplicateColumnNames) error {
+	seen := make(map[string]bool)
+
+	var fn func(c *Column) error
+	fn = func(c *Column) error {
+		for _, child := range c.children {
+			if seen[child.flatName] {
+				switch mode {
+				case DuplicateColumnNamesError:
+					return errors.Errorf("duplicate column name %q", child.flatName)
+				case DuplicateColumnNamesRename:
+					renameDuplicateColumn(child, c.flatName, seen)
+				}
+			}
+			seen[child.flatName] = true
+
+			if err := fn(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	r.ensureRoot()
+	return fn(r.root)
+}
+
+// renameDuplicateColumn renames the column by appending the first suffix that results in an
+// unused flat name, and updates the flat names of its children.
+func renameDuplicateColumn(col *Column, path string, seen map[string]bool) {
+	for i := 2; ; i++ {
+		name := fmt.Sprintf("%s_%d", col.name, i)
+		flatName := name
+		if path != "" {
+			flatName = path + "." + name
+		}
+		if seen[flatName] {
+			continue
+		}
+
+		col.name = name
+		if col.element != nil {
+			// the element is copied as it belongs to the meta data of the file.
+			elem := *col.element
+			elem.Name = name
+			col.element = &elem
+		}
+		setFlatNames(col, path)
+		return
+	}
+}
+
+func setFlatNames(col *Column, path string) {
+	col.flatName = col.name
+	if path != "" {
+		col.flatName = path + "." + col.name
+	}
+	for _, child := range col.children {
+		setFlatNames(child, col.flatName)
+	}
+}
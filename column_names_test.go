@@ -0,0 +1,91 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateColumnNames(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+		required group grp {
+			required int64 fop;
+			required int64 fop_2;
+		}
+		optional int64 fop;
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w := NewFileWriter(&buf, WithSchemaDefinition(sd))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{
+			"foo": int64(i),
+			"grp": map[string]interface{}{"fop": int64(10 + i), "fop_2": int64(20 + i)},
+			"fop": int64(30 + i),
+		}))
+	}
+	require.NoError(t, w.Close())
+
+	// the name fop is only unique in the original file because it's in different groups.
+	data := bytes.Replace(buf.Bytes(), []byte("fop"), []byte("foo"), -1)
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(data), WithDuplicateColumnNames(DuplicateColumnNamesError))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `duplicate column name "foo"`)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithDuplicateColumnNames(DuplicateColumnNamesRename))
+	require.NoError(t, err)
+	var names []string
+	for _, col := range r.Columns() {
+		names = append(names, col.FlatName())
+	}
+	require.Equal(t, []string{"foo", "grp.foo", "grp.foo_2", "foo_2"}, names)
+	require.Equal(t, "foo_2", r.GetColumnByName("foo_2").Element().Name)
+	require.NotNil(t, r.GetSchemaDefinition().SubSchema("foo_2"))
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"foo":   int64(0),
+		"grp":   map[string]interface{}{"foo": int64(10), "foo_2": int64(20)},
+		"foo_2": int64(30),
+	}, row)
+
+	// the duplicate names are kept by default.
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Nil(t, r.GetColumnByName("foo"))
+	require.NotNil(t, r.GetColumnByName("grp.foo"))
+	require.Equal(t, "foo", r.GetColumnByIndex(3).FlatName())
+	require.Equal(t, 3, r.GetColumnByIndex(3).Index())
+	require.Nil(t, r.GetColumnByIndex(4))
+
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int64(30), row["foo"])
+}
+
+func TestWriteDuplicateColumnNames(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+		required int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	s := &schema{}
+	require.Error(t, s.SetSchemaDefinition(sd))
+
+	fooStore, err := NewInt64Store(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+	require.NoError(t, err)
+	barStore, err := NewInt64Store(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+	require.NoError(t, err)
+
+	w := NewFileWriter(&bytes.Buffer{})
+	require.NoError(t, w.AddColumn("foo", NewDataColumn(fooStore, parquet.FieldRepetitionType_REQUIRED)))
+	require.Error(t, w.AddColumn("foo", NewDataColumn(barStore, parquet.FieldRepetitionType_REQUIRED)))
+}
@@ -0,0 +1,314 @@
+package goparquet
+
+import (
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/pkg/errors"
+)
+
+// columnReaderBatchSize is the number of values and levels a ColumnReader decodes at once.
+const columnReaderBatchSize = 1024
+
+// ColumnRecords contains the values and levels of whole records of a single column.
+type ColumnRecords struct {
+	// NumRecords is the number of records.
+	NumRecords int
+	// Values contains the non-null values of the records.
+	Values []interface{}
+	// DefinitionLevels and RepetitionLevels contain the definition and repetition level of every
+	// value including nulls. A repetition level of 0 marks the first value of a record.
+	DefinitionLevels []int32
+	RepetitionLevels []int32
+}
+
+// ColumnReader reads the values and levels of a single column chunk record by record, so that
+// nested columns can be consumed without splitting records across batches.
+type ColumnReader struct {
+	col   *Column
+	pages []pageReader
+
+	// values, dLevels and rLevels contain the decoded data that wasn't returned yet.
+	values  []interface{}
+	dLevels []int32
+	rLevels []int32
+}
+
+// NewColumnReader creates a ColumnReader for the chunk of the column colName in the row group
+// with index rowGroup. The column name has to be provided in its dotted notation. It doesn't
+// change the position of the reader for NextRow.
+func (f *FileReader) NewColumnReader(rowGroup int, colName string) (*ColumnReader, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return nil, errors.Errorf("row group index %d is out of bounds", rowGroup)
+	}
+
+	col := f.GetColumnByName(colName)
+	if col == nil {
+		return nil, errors.Errorf("column %q not found", colName)
+	}
+
+	rg := f.meta.RowGroups[rowGroup]
+	if len(rg.Columns) <= col.Index() {
+		return nil, errors.Errorf("column index %d is out of bounds", col.Index())
+	}
+
+	pages, err := readChunk(f.reader, col, rg.Columns[col.Index()], &f.opts, newMemoryTracker(f.opts.maxMemorySize), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ColumnReader{
+		col:   col,
+		pages: pages,
+	}, nil
+}
+
+// ReadRecords reads up to maxRecords records. Records are never split, so all values of a
+// record are returned by the same call. It returns io.EOF if no records are left.
+func (c *ColumnReader) ReadRecords(maxRecords int) (*ColumnRecords, error) {
+	if maxRecords <= 0 {
+		return nil, errors.Errorf("invalid number of records %d", maxRecords)
+	}
+
+	maxD := int32(c.col.MaxDefinitionLevel())
+	result := &ColumnRecords{}
+
+	for {
+		var (
+			levels int
+			values int
+		)
+		for ; levels < len(c.rLevels); levels++ {
+			if c.rLevels[levels] == 0 {
+				if result.NumRecords == maxRecords {
+					break
+				}
+				result.NumRecords++
+			}
+			if c.dLevels[levels] == maxD {
+				values++
+			}
+		}
+
+		result.Values = append(result.Values, c.values[:values]...)
+		result.DefinitionLevels = append(result.DefinitionLevels, c.dLevels[:levels]...)
+		result.RepetitionLevels = append(result.RepetitionLevels, c.rLevels[:levels]...)
+		c.values, c.dLevels, c.rLevels = c.values[values:], c.dLevels[levels:], c.rLevels[levels:]
+
+		if len(c.rLevels) > 0 {
+			// the next record doesn't fit anymore.
+			return result, nil
+		}
+
+		err := c.fill()
+		if err == io.EOF {
+			if result.NumRecords == 0 {
+				return nil, io.EOF
+			}
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Next returns the next value of the column chunk together with its definition and repetition
+// level. The value is nil if the definition level is lower than the maximum definition level of
+// the column. It returns io.EOF if no values are left. Next and ReadRecords can be mixed, they
+// continue where the other one stopped.
+func (c *ColumnReader) Next() (value interface{}, dLevel int32, rLevel int32, err error) {
+	for len(c.dLevels) == 0 {
+		if err := c.fill(); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	dLevel, rLevel = c.dLevels[0], c.rLevels[0]
+	c.dLevels, c.rLevels = c.dLevels[1:], c.rLevels[1:]
+	if dLevel == int32(c.col.MaxDefinitionLevel()) {
+		value = c.values[0]
+		c.values = c.values[1:]
+	}
+
+	return value, dLevel, rLevel, nil
+}
+
+// ColumnStream reads the values and levels of a single column across all row groups of a file
+// one by one, without assembling them into rows.
+type ColumnStream struct {
+	f        *FileReader
+	colName  string
+	rowGroup int
+	current  *ColumnReader
+}
+
+// NewColumnStream creates a ColumnStream for the column colName. The column name has to be
+// provided in its dotted notation. The chunks of the column are read one row group at a time
+// when they are needed. A stream can be used alongside NextRow and other streams of the same
+// reader, as long as they aren't used concurrently.
+func (f *FileReader) NewColumnStream(colName string) (*ColumnStream, error) {
+	if f.GetColumnByName(colName) == nil {
+		return nil, errors.Errorf("column %q not found", colName)
+	}
+
+	return &ColumnStream{f: f, colName: colName}, nil
+}
+
+// Next returns the next value of the column together with its definition and repetition level
+// like ColumnReader.Next. It returns io.EOF if all row groups were read.
+func (s *ColumnStream) Next() (value interface{}, dLevel int32, rLevel int32, err error) {
+	for {
+		if s.current == nil {
+			if s.rowGroup >= len(s.f.meta.RowGroups) {
+				return nil, 0, 0, io.EOF
+			}
+			s.current, err = s.f.NewColumnReader(s.rowGroup, s.colName)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+		}
+
+		value, dLevel, rLevel, err = s.current.Next()
+		if err != io.EOF {
+			return value, dLevel, rLevel, err
+		}
+		s.current = nil
+		s.rowGroup++
+	}
+}
+
+// RowGroup returns the index of the row group that is currently read.
+func (s *ColumnStream) RowGroup() int {
+	return s.rowGroup
+}
+
+// ReadSlices reads up to maxRecords records of a repeated primitive column, i.e. a repeated
+// column without repeated parents, and returns the values of every record as a typed slice
+// without assembling the records, e.g. []int32 for a repeated int32 column. The values of STRING,
+// ENUM and JSON columns are returned as []string instead of [][]byte. Records without values are
+// returned as nil slices of the same type. It returns io.EOF if no records are left.
+func (c *ColumnReader) ReadSlices(maxRecords int) ([]interface{}, error) {
+	if c.col.MaxRepetitionLevel() != 1 || c.col.rep != parquet.FieldRepetitionType_REPEATED {
+		return nil, errors.Errorf("column %s is not a repeated primitive column", c.col.FlatName())
+	}
+
+	records, err := c.ReadRecords(maxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	asString := parquetschema.IsUTF8String(c.col.Element())
+	maxD := int32(c.col.MaxDefinitionLevel())
+	result := make([]interface{}, 0, records.NumRecords)
+
+	var values int
+	for i := 0; i < len(records.RepetitionLevels); {
+		end := i + 1
+		for end < len(records.RepetitionLevels) && records.RepetitionLevels[end] != 0 {
+			end++
+		}
+
+		n := 0
+		if records.DefinitionLevels[i] == maxD {
+			// a repeated leaf can't contain null values, so all values of the record are defined.
+			n = end - i
+		}
+		result = append(result, typedSlice(c.col.data.parquetType(), asString, records.Values[values:values+n]))
+
+		values += n
+		i = end
+	}
+
+	return result, nil
+}
+
+// typedSlice converts the values of a column of type typ to a slice of their type.
+func typedSlice(typ parquet.Type, asString bool, values []interface{}) interface{} {
+	switch typ {
+	case parquet.Type_BOOLEAN:
+		var res []bool
+		for _, v := range values {
+			res = append(res, v.(bool))
+		}
+		return res
+	case parquet.Type_INT32:
+		var res []int32
+		for _, v := range values {
+			res = append(res, v.(int32))
+		}
+		return res
+	case parquet.Type_INT64:
+		var res []int64
+		for _, v := range values {
+			res = append(res, v.(int64))
+		}
+		return res
+	case parquet.Type_INT96:
+		var res [][12]byte
+		for _, v := range values {
+			res = append(res, v.([12]byte))
+		}
+		return res
+	case parquet.Type_FLOAT:
+		var res []float32
+		for _, v := range values {
+			res = append(res, v.(float32))
+		}
+		return res
+	case parquet.Type_DOUBLE:
+		var res []float64
+		for _, v := range values {
+			res = append(res, v.(float64))
+		}
+		return res
+	}
+
+	if asString {
+		var res []string
+		for _, v := range values {
+			res = append(res, string(v.([]byte)))
+		}
+		return res
+	}
+
+	var res [][]byte
+	for _, v := range values {
+		res = append(res, v.([]byte))
+	}
+	return res
+}
+
+// fill decodes the next batch of values and levels. It returns io.EOF if all pages were read.
+func (c *ColumnReader) fill() error {
+	for len(c.pages) > 0 {
+		data := make([]interface{}, columnReaderBatchSize)
+		n, notNull, dl, rl, err := c.pages[0].readValues(data)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			c.pages = c.pages[1:]
+			continue
+		}
+
+		for i := 0; i < n; i++ {
+			d, r := int32(0), int32(0)
+			if dl != nil {
+				d, _ = dl.at(i)
+			}
+			if rl != nil {
+				r, _ = rl.at(i)
+			}
+			c.dLevels = append(c.dLevels, d)
+			c.rLevels = append(c.rLevels, r)
+		}
+		// the non-null values are at the beginning of data.
+		c.values = append(c.values, data[:notNull]...)
+
+		return nil
+	}
+
+	return io.EOF
+}
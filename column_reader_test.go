@@ -0,0 +1,217 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnReaderReadRecords(t *testing.T) {
+	data := writeValidateTestFile(t, WithPageValueLimits(1, 30))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	_, err = r.NewColumnReader(3, "tags")
+	require.Error(t, err)
+	_, err = r.NewColumnReader(0, "unknown")
+	require.Error(t, err)
+
+	cr, err := r.NewColumnReader(1, "tags")
+	require.NoError(t, err)
+
+	_, err = cr.ReadRecords(0)
+	require.Error(t, err)
+
+	var records int
+	for {
+		batch, err := cr.ReadRecords(7)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		expected := 7
+		if records == 98 {
+			expected = 2
+		}
+		require.Equal(t, expected, batch.NumRecords)
+		require.Len(t, batch.Values, 2*expected)
+		require.Len(t, batch.DefinitionLevels, 2*expected)
+		require.Len(t, batch.RepetitionLevels, 2*expected)
+
+		for i := 0; i < expected; i++ {
+			id := 100 + records + i
+			require.Equal(t, []interface{}{int32(id), int32(id % 3)}, batch.Values[2*i:2*i+2])
+			require.Equal(t, []int32{0, 1}, batch.RepetitionLevels[2*i:2*i+2])
+			require.Equal(t, []int32{1, 1}, batch.DefinitionLevels[2*i:2*i+2])
+		}
+		records += batch.NumRecords
+	}
+	require.Equal(t, 100, records)
+
+	cr, err = r.NewColumnReader(0, "name")
+	require.NoError(t, err)
+	batch, err := cr.ReadRecords(1000)
+	require.NoError(t, err)
+	require.Equal(t, 100, batch.NumRecords)
+	require.Len(t, batch.Values, 75)
+	require.Equal(t, int32(0), batch.DefinitionLevels[0])
+	require.Equal(t, int32(1), batch.DefinitionLevels[1])
+	_, err = cr.ReadRecords(1000)
+	require.Equal(t, io.EOF, err)
+
+	// reading a column chunk doesn't change the position for NextRow.
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), row["id"])
+}
+
+func TestColumnReaderReadSlices(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		repeated binary tags (STRING);
+		repeated binary raw;
+		repeated double scores;
+		repeated binary states (ENUM);
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w := NewFileWriter(&buf, WithSchemaDefinition(sd))
+	for i := 0; i < 50; i++ {
+		record := map[string]interface{}{"id": int64(i)}
+		if i%3 != 0 {
+			record["tags"] = [][]byte{[]byte("a"), []byte(string(rune('a' + i%26)))}
+			record["raw"] = [][]byte{{byte(i)}}
+			record["scores"] = []float64{float64(i)}
+			record["states"] = [][]byte{[]byte("ACTIVE")}
+		}
+		require.NoError(t, w.AddData(record))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	cr, err := r.NewColumnReader(0, "id")
+	require.NoError(t, err)
+	_, err = cr.ReadSlices(10)
+	require.Error(t, err)
+
+	cr, err = r.NewColumnReader(0, "tags")
+	require.NoError(t, err)
+	var records int
+	for {
+		batch, err := cr.ReadSlices(16)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		for _, v := range batch {
+			tags, ok := v.([]string)
+			require.True(t, ok, "unexpected type %T", v)
+			if records%3 == 0 {
+				require.Nil(t, tags)
+			} else {
+				require.Equal(t, []string{"a", string(rune('a' + records%26))}, tags)
+			}
+			records++
+		}
+	}
+	require.Equal(t, 50, records)
+
+	cr, err = r.NewColumnReader(0, "raw")
+	require.NoError(t, err)
+	batch, err := cr.ReadSlices(3)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{[][]byte(nil), [][]byte{{1}}, [][]byte{{2}}}, batch)
+
+	cr, err = r.NewColumnReader(0, "scores")
+	require.NoError(t, err)
+	batch, err = cr.ReadSlices(3)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{[]float64(nil), []float64{1}, []float64{2}}, batch)
+
+	cr, err = r.NewColumnReader(0, "states")
+	require.NoError(t, err)
+	batch, err = cr.ReadSlices(3)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{[]string(nil), []string{"ACTIVE"}, []string{"ACTIVE"}}, batch)
+}
+
+func TestColumnReaderNext(t *testing.T) {
+	data := writeValidateTestFile(t, WithPageValueLimits(1, 30))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	cr, err := r.NewColumnReader(0, "name")
+	require.NoError(t, err)
+
+	batch, err := cr.ReadRecords(2)
+	require.NoError(t, err)
+	require.Equal(t, 2, batch.NumRecords)
+
+	for i := 2; i < 100; i++ {
+		value, dLevel, rLevel, err := cr.Next()
+		require.NoError(t, err)
+		require.Equal(t, int32(0), rLevel)
+		if i%4 == 0 {
+			require.Nil(t, value)
+			require.Equal(t, int32(0), dLevel)
+		} else {
+			require.Equal(t, []byte{byte('a' + i%5)}, value)
+			require.Equal(t, int32(1), dLevel)
+		}
+	}
+	_, _, _, err = cr.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestColumnStream(t *testing.T) {
+	data := writeValidateTestFile(t, WithPageValueLimits(1, 30))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	_, err = r.NewColumnStream("unknown")
+	require.Error(t, err)
+
+	ids, err := r.NewColumnStream("id")
+	require.NoError(t, err)
+	tags, err := r.NewColumnStream("tags")
+	require.NoError(t, err)
+
+	for i := 0; i < 300; i++ {
+		value, dLevel, rLevel, err := ids.Next()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), value)
+		require.Equal(t, int32(0), dLevel)
+		require.Equal(t, int32(0), rLevel)
+		require.Equal(t, i/100, ids.RowGroup())
+
+		for j, expected := range []int32{int32(i), int32(i % 3)} {
+			value, dLevel, rLevel, err := tags.Next()
+			require.NoError(t, err)
+			require.Equal(t, expected, value)
+			require.Equal(t, int32(1), dLevel)
+			require.Equal(t, int32(j), rLevel)
+		}
+
+		// streams can be interleaved with NextRow.
+		if i < 10 {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, int64(i), row["id"])
+		}
+	}
+
+	_, _, _, err = ids.Next()
+	require.Equal(t, io.EOF, err)
+	_, _, _, err = tags.Next()
+	require.Equal(t, io.EOF, err)
+}
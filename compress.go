@@ -3,6 +3,7 @@ package goparquet
 import (
 	"bytes"
 	"compress/gzip"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"sync"
@@ -26,6 +27,13 @@ type (
 		DecompressBlock([]byte) ([]byte, error)
 	}
 
+	// StreamDecompressor can be implemented by a BlockCompressor to decompress blocks as a
+	// stream. Large pages of codecs whose compressor implements it are decompressed while
+	// their values are decoded, so the uncompressed page is never held in memory as a whole.
+	StreamDecompressor interface {
+		DecompressStream(io.Reader) (io.Reader, error)
+	}
+
 	plainCompressor  struct{}
 	snappyCompressor struct{}
 	gzipCompressor   struct{}
@@ -75,6 +83,10 @@ func (gzipCompressor) DecompressBlock(block []byte) ([]byte, error) {
 	return ret, r.Close()
 }
 
+func (gzipCompressor) DecompressStream(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
 func compressBlock(block []byte, method parquet.CompressionCodec) ([]byte, error) {
 	compressorLock.RLock()
 	defer compressorLock.RUnlock()
@@ -99,6 +111,58 @@ func decompressBlock(block []byte, method parquet.CompressionCodec) ([]byte, err
 	return c.DecompressBlock(block)
 }
 
+// streamDecompressor returns the StreamDecompressor of the codec, or nil if its compressor
+// doesn't implement it.
+func streamDecompressor(method parquet.CompressionCodec) StreamDecompressor {
+	compressorLock.RLock()
+	defer compressorLock.RUnlock()
+
+	sd, _ := compressors[method].(StreamDecompressor)
+	return sd
+}
+
+// streamingPageSize is the minimum uncompressed size of a page that is decompressed as a stream.
+var streamingPageSize int32 = 4 * 1024 * 1024
+
+// decompressionPreviewSize is the maximum number of bytes of the page data in a DecompressionError.
+const decompressionPreviewSize = 16
+
+// DecompressionError is returned when the data of a page can't be decompressed to its declared
+// size. This often means that the codec in the column chunk meta data is wrong, which can be
+// worked around using WithCodecOverride.
+type DecompressionError struct {
+	// Column is the flat name of the column the page belongs to.
+	Column string
+	// Page is the index of the page within the column chunk, including the dictionary page.
+	Page int
+	// Offset is the offset of the page header in the file.
+	Offset int64
+	// Codec is the codec that was used to decompress the page.
+	Codec parquet.CompressionCodec
+	// CompressedSize and UncompressedSize are the sizes declared in the page header.
+	CompressedSize   int32
+	UncompressedSize int32
+	// Preview contains the first bytes of the compressed page data.
+	Preview []byte
+	// Err is the error returned by the codec, or nil if the data was decompressed to the
+	// wrong size.
+	Err error
+}
+
+func (e *DecompressionError) Error() string {
+	reason := "wrong uncompressed size"
+	if e.Err != nil {
+		reason = e.Err.Error()
+	}
+	return fmt.Sprintf("decompressing page %d of column %s at offset %d with codec %s failed: %s (compressed size %d, uncompressed size %d, data % x)",
+		e.Page, e.Column, e.Offset, e.Codec, reason, e.CompressedSize, e.UncompressedSize, e.Preview)
+}
+
+// Unwrap returns the error returned by the codec.
+func (e *DecompressionError) Unwrap() error {
+	return e.Err
+}
+
 func newBlockReader(in io.Reader, codec parquet.CompressionCodec, compressedSize int32, uncompressedSize int32) (io.Reader, error) {
 	buf, err := ioutil.ReadAll(io.LimitReader(in, int64(compressedSize)))
 	if err != nil {
@@ -109,16 +173,107 @@ func newBlockReader(in io.Reader, codec parquet.CompressionCodec, compressedSize
 		return nil, errors.Errorf("compressed data must be %d byte but its %d byte", compressedSize, len(buf))
 	}
 
+	decompressionErr := func(err error) error {
+		preview := buf
+		if len(preview) > decompressionPreviewSize {
+			preview = preview[:decompressionPreviewSize]
+		}
+		return &DecompressionError{
+			Codec:            codec,
+			CompressedSize:   compressedSize,
+			UncompressedSize: uncompressedSize,
+			Preview:          append([]byte(nil), preview...),
+			Err:              err,
+		}
+	}
+
+	if sd := streamDecompressor(codec); sd != nil && uncompressedSize >= streamingPageSize {
+		r, err := sd.DecompressStream(bytes.NewReader(buf))
+		if err != nil {
+			return nil, decompressionErr(err)
+		}
+		return &decompressionStream{r: r, remaining: int64(uncompressedSize), err: decompressionErr}, nil
+	}
+
 	res, err := decompressBlock(buf, codec)
 	if err != nil {
-		return nil, errors.Wrap(err, "decompression failed")
+		return nil, decompressionErr(err)
 	}
 
 	if len(res) != int(uncompressedSize) {
-		return nil, errors.Errorf("decompressed data must be %d byte but its %d byte", uncompressedSize, len(res))
+		return nil, decompressionErr(nil)
+	}
+
+	// a bytes.Buffer allows decoders to reference the page data instead of copying it.
+	return bytes.NewBuffer(res), nil
+}
+
+// decompressionStream returns the data of a page that is decompressed while it is read, and
+// checks that the decompressed data has the declared size. This trades the zero-copy reading of
+// byte arrays for memory: the values of a streamed page are always copied out of the stream, even
+// with WithZeroCopyByteArrays, as there is no page buffer they could reference. Errors of the
+// stream are only returned while the values are decoded, so the caller needs to add the position
+// of the page to them using pageReadError like it does for pages that are decompressed at once.
+type decompressionStream struct {
+	r         io.Reader
+	remaining int64
+	err       func(error) error
+}
+
+func (s *decompressionStream) Read(p []byte) (int, error) {
+	if s.remaining == 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > s.remaining {
+		p = p[:s.remaining]
+	}
+
+	n, err := s.r.Read(p)
+	s.remaining -= int64(n)
+	switch {
+	case err == io.EOF && n > 0 && s.remaining > 0:
+		// decoders don't necessarily handle data returned along with an error, so the end of
+		// the data is reported by the next call.
+		return n, nil
+	case err == io.EOF && n == 0:
+		return 0, s.err(nil)
+	case err != nil && err != io.EOF:
+		// the data is corrupt, and callers like io.ReadFull ignore errors returned along with
+		// the data they asked for.
+		return 0, s.err(err)
 	}
 
-	return bytes.NewReader(res), nil
+	if s.remaining == 0 && err != io.EOF {
+		// the codec checks the integrity of the data, e.g. the checksum of gzip, only when the
+		// end of the compressed data is reached, which decoders that read exactly the declared
+		// size never do.
+		if err := s.drain(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// maxConsecutiveEmptyReads is the number of reads without data and without error after which a
+// codec is considered to be stuck, like in bufio.
+const maxConsecutiveEmptyReads = 100
+
+// drain reads the remaining compressed data, which must not contain any more decompressed data.
+func (s *decompressionStream) drain() error {
+	var buf [1]byte
+	for i := 0; i < maxConsecutiveEmptyReads; i++ {
+		n, err := s.r.Read(buf[:])
+		if n > 0 {
+			return s.err(nil)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return s.err(err)
+		}
+	}
+	return s.err(io.ErrNoProgress)
 }
 
 // RegisterBlockCompressor is a function to to register additional block compressors to the package. By default,
@@ -1,6 +1,10 @@
 package goparquet
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
 	"testing"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -30,3 +34,145 @@ occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim
 		assert.Equal(t, block, b2)
 	}
 }
+
+func TestCodecOverride(t *testing.T) {
+	data := writeValidateTestFile(t, WithCompressionCodec(parquet.CompressionCodec_GZIP))
+
+	openWrongCodec := func(opts ...FileReaderOption) *FileReader {
+		r, err := NewFileReaderWithOptions(bytes.NewReader(data), opts...)
+		require.NoError(t, err)
+		for _, rg := range r.meta.RowGroups {
+			rg.Columns[0].MetaData.Codec = parquet.CompressionCodec_SNAPPY
+		}
+		return r
+	}
+
+	r := openWrongCodec()
+	_, err := r.NextRow()
+	var decompressionErr *DecompressionError
+	require.True(t, errors.As(err, &decompressionErr), "unexpected error %v", err)
+	require.Equal(t, "id", decompressionErr.Column)
+	require.Equal(t, parquet.CompressionCodec_SNAPPY, decompressionErr.Codec)
+	require.Equal(t, 0, decompressionErr.Page)
+	require.Equal(t, r.meta.RowGroups[0].Columns[0].MetaData.DataPageOffset, decompressionErr.Offset)
+	require.Equal(t, []byte{0x1f, 0x8b}, decompressionErr.Preview[:2])
+	require.Contains(t, err.Error(), "1f 8b")
+
+	for _, opts := range [][]FileReaderOption{
+		{WithCodecOverride(parquet.CompressionCodec_GZIP)},
+		{WithCodecOverride(parquet.CompressionCodec_GZIP, "id")},
+	} {
+		r := openWrongCodec(opts...)
+		report, err := r.Validate(0, "id")
+		require.NoError(t, err)
+		require.True(t, report.Valid(), "%v", report.Problems)
+
+		for i := 0; ; i++ {
+			row, err := r.NextRow()
+			if err == io.EOF {
+				require.Equal(t, 300, i)
+				break
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(i), row["id"])
+		}
+	}
+
+	r = openWrongCodec(WithCodecOverride(parquet.CompressionCodec_GZIP, "name"))
+	_, err = r.NextRow()
+	require.True(t, errors.As(err, &decompressionErr), "unexpected error %v", err)
+}
+
+// testDecompression runs test with pages that are decompressed at once and with pages that are
+// decompressed as a stream.
+func testDecompression(t *testing.T, test func(t *testing.T)) {
+	t.Run("block", test)
+	t.Run("streaming", func(t *testing.T) {
+		defer func(size int32) { streamingPageSize = size }(streamingPageSize)
+		streamingPageSize = 1
+		test(t)
+	})
+}
+
+func TestStreamingDecompression(t *testing.T) {
+	defer func(size int32) { streamingPageSize = size }(streamingPageSize)
+	streamingPageSize = 1
+
+	block := bytes.Repeat([]byte("streaming decompression "), 100)
+	compressed, err := compressBlock(block, parquet.CompressionCodec_GZIP)
+	require.NoError(t, err)
+
+	r, err := newBlockReader(bytes.NewReader(compressed), parquet.CompressionCodec_GZIP, int32(len(compressed)), int32(len(block)))
+	require.NoError(t, err)
+	require.IsType(t, &decompressionStream{}, r)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, block, data)
+
+	var decompressionErr *DecompressionError
+	for _, size := range []int{len(block) + 1, len(block) - 1} {
+		r, err = newBlockReader(bytes.NewReader(compressed), parquet.CompressionCodec_GZIP, int32(len(compressed)), int32(size))
+		require.NoError(t, err)
+		_, err = ioutil.ReadAll(r)
+		require.True(t, errors.As(err, &decompressionErr), "unexpected error %v", err)
+	}
+
+	// the checksum in the gzip trailer is checked even if only the declared size is read.
+	corrupted := append([]byte{}, compressed...)
+	corrupted[len(corrupted)-8] ^= 0xff
+	r, err = newBlockReader(bytes.NewReader(corrupted), parquet.CompressionCodec_GZIP, int32(len(corrupted)), int32(len(block)))
+	require.NoError(t, err)
+	_, err = io.ReadFull(r, make([]byte, len(block)))
+	require.True(t, errors.As(err, &decompressionErr), "unexpected error %v", err)
+
+	// snappy doesn't support streaming, so the block is decompressed right away.
+	compressed, err = compressBlock(block, parquet.CompressionCodec_SNAPPY)
+	require.NoError(t, err)
+	r, err = newBlockReader(bytes.NewReader(compressed), parquet.CompressionCodec_SNAPPY, int32(len(compressed)), int32(len(block)))
+	require.NoError(t, err)
+	require.IsType(t, &bytes.Buffer{}, r)
+
+	fileData := writeValidateTestFile(t, WithCompressionCodec(parquet.CompressionCodec_GZIP), WithDataPageV2())
+	fr, err := NewFileReader(bytes.NewReader(fileData))
+	require.NoError(t, err)
+	for i := 0; i < 300; i++ {
+		row, err := fr.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["id"])
+		require.Equal(t, []int32{int32(i), int32(i % 3)}, row["tags"])
+	}
+	_, err = fr.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	// errors of streamed pages are only returned while the values are decoded, but they contain
+	// the position of the page like the errors of pages that are decompressed at once.
+	meta := fr.meta.RowGroups[0].Columns[0].MetaData
+	end := meta.DataPageOffset + meta.TotalCompressedSize
+	corrupted = append([]byte{}, fileData...)
+	corrupted[end-8] ^= 0xff
+	fr, err = NewFileReader(bytes.NewReader(corrupted))
+	require.NoError(t, err)
+	_, err = fr.NextRow()
+	require.True(t, errors.As(err, &decompressionErr), "unexpected error %v", err)
+	require.Equal(t, "id", decompressionErr.Column)
+	require.True(t, decompressionErr.Offset >= meta.DataPageOffset && decompressionErr.Offset < end, "offset %d", decompressionErr.Offset)
+}
+
+// stuckReader neither returns data nor an error.
+type stuckReader struct{}
+
+func (stuckReader) Read([]byte) (int, error) {
+	return 0, nil
+}
+
+func TestDecompressionStreamNoProgress(t *testing.T) {
+	block := []byte("no progress")
+	stream := &decompressionStream{
+		r:         io.MultiReader(bytes.NewReader(block), stuckReader{}),
+		remaining: int64(len(block)),
+		err:       func(err error) error { return &DecompressionError{Err: err} },
+	}
+
+	_, err := io.ReadFull(stream, make([]byte, len(block)))
+	require.True(t, errors.Is(err, io.ErrNoProgress), "unexpected error %v", err)
+}
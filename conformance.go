@@ -0,0 +1,93 @@
+package goparquet
+
+import (
+	"fmt"
+	"io"
+)
+
+// ConformanceReport is the result of checking a whole file using CheckConformance.
+type ConformanceReport struct {
+	// NumRows is the number of rows according to the file meta data.
+	NumRows int64
+	// RowsRead is the number of rows that were read successfully.
+	RowsRead int64
+	// Chunks contains the validation reports of all column chunks, ordered by row group and
+	// column.
+	Chunks []*ChunkValidationReport
+	// Problems contains a description of every inconsistency that was found outside of a single
+	// column chunk.
+	Problems []string
+}
+
+// Valid returns true if no problems were found in the file.
+func (r *ConformanceReport) Valid() bool {
+	if len(r.Problems) > 0 {
+		return false
+	}
+	for _, chunk := range r.Chunks {
+		if !chunk.Valid() {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *ConformanceReport) addProblem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// CheckConformance checks whether the file can be read completely by this package and whether
+// its content is consistent with its meta data. It validates every column chunk like
+// FileReader.Validate does, checks that the row counts of the row groups and column chunks add
+// up, and reads all rows. This allows to check files written by other implementations before
+// relying on them. Inconsistencies are reported in the returned report, an error is only
+// returned if the file can't be opened, e.g. because its footer is corrupt or it is encrypted
+// and the keys weren't set using WithDecryption, in which case the error is ErrEncryptedFile. The
+// options are used to open the file, so that the same options as for reading the file can be
+// checked.
+func CheckConformance(r io.ReadSeeker, opts ...FileReaderOption) (*ConformanceReport, error) {
+	reader, err := NewFileReaderWithOptions(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ConformanceReport{NumRows: reader.NumRows()}
+
+	var numRows int64
+	for rgIdx, rg := range reader.meta.RowGroups {
+		numRows += rg.NumRows
+		for _, col := range reader.Columns() {
+			chunk, err := reader.Validate(rgIdx, col.FlatName())
+			if err != nil {
+				report.addProblem("row group %d: validating column %s failed: %v", rgIdx, col.FlatName(), err)
+				continue
+			}
+			report.Chunks = append(report.Chunks, chunk)
+
+			// every row contains exactly one value of columns that aren't repeated.
+			if col.MaxRepetitionLevel() == 0 && chunk.NumValues != rg.NumRows {
+				report.addProblem("row group %d: column %s contains %d values but the row group contains %d rows", rgIdx, col.FlatName(), chunk.NumValues, rg.NumRows)
+			}
+		}
+	}
+	if numRows != report.NumRows {
+		report.addProblem("the row groups contain %d rows but the file meta data declares %d rows", numRows, report.NumRows)
+	}
+
+	for {
+		_, err := reader.NextRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report.addProblem("reading row %d failed: %v", report.RowsRead, err)
+			return report, nil
+		}
+		report.RowsRead++
+	}
+	if report.RowsRead != report.NumRows {
+		report.addProblem("read %d rows but the file meta data declares %d rows", report.RowsRead, report.NumRows)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,140 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConformance(t *testing.T) {
+	testDecompression(t, func(t *testing.T) {
+		data := writeValidateTestFile(t, WithCompressionCodec(parquet.CompressionCodec_GZIP), WithPageValueLimits(1, 30))
+
+		report, err := CheckConformance(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.True(t, report.Valid(), "%v", report.Problems)
+		require.Equal(t, int64(300), report.NumRows)
+		require.Equal(t, int64(300), report.RowsRead)
+		require.Len(t, report.Chunks, 9)
+
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		// corrupt the compressed data of the last page of the first column.
+		meta := r.meta.RowGroups[0].Columns[0].MetaData
+		offset := meta.DataPageOffset + meta.TotalCompressedSize - 8
+		corrupted := append([]byte{}, data...)
+		for i := offset; i < offset+8; i++ {
+			corrupted[i] ^= 0xff
+		}
+
+		report, err = CheckConformance(bytes.NewReader(corrupted))
+		require.NoError(t, err)
+		require.False(t, report.Valid())
+		require.False(t, report.Chunks[0].Valid())
+		require.True(t, report.Chunks[1].Valid(), "%v", report.Chunks[1].Problems)
+		require.Len(t, report.Problems, 1)
+		require.Contains(t, report.Problems[0], "reading row 0 failed")
+		require.Equal(t, int64(0), report.RowsRead)
+	})
+}
+
+func TestEncryptedFile(t *testing.T) {
+	data := writeValidateTestFile(t)
+
+	encrypted := append([]byte{}, data...)
+	copy(encrypted, encryptedMagic)
+	copy(encrypted[len(encrypted)-4:], encryptedMagic)
+
+	_, err := NewFileReader(bytes.NewReader(encrypted))
+	require.Error(t, err)
+	require.Equal(t, ErrEncryptedFile, errors.Cause(err))
+
+	_, err = CheckConformance(bytes.NewReader(encrypted))
+	require.Equal(t, ErrEncryptedFile, errors.Cause(err))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	r.meta.RowGroups[0].Columns[1].CryptoMetadata = &parquet.ColumnCryptoMetaData{}
+
+	_, err = r.NextRow()
+	require.Error(t, err)
+	require.Equal(t, ErrEncryptedFile, errors.Cause(err))
+}
+
+// parquetTestingKeys returns the keys of the encrypted sample files of apache/parquet-testing.
+// Files whose keys are stored in external key material are skipped.
+func parquetTestingKeys(file string) DecryptionProperties {
+	props := DecryptionProperties{
+		KeyRetriever: func(keyMetadata []byte) ([]byte, error) {
+			switch string(keyMetadata) {
+			case "kf":
+				return []byte("0123456789012345"), nil
+			case "kc1":
+				return []byte("1234567890123450"), nil
+			case "kc2":
+				return []byte("1234567890123451"), nil
+			}
+			return nil, ErrEncryptedFile
+		},
+	}
+	if strings.Contains(filepath.Base(file), "disable_aad_storage") {
+		props.AADPrefix = []byte("tester")
+	}
+	return props
+}
+
+// TestParquetTestingCorpus checks the sample files of a checkout of the apache/parquet-testing
+// repository using CheckConformance. Encrypted files are decrypted with the keys of the
+// repository. Files whose keys are unknown and files that use compression codecs that aren't
+// registered are skipped.
+func TestParquetTestingCorpus(t *testing.T) {
+	root := os.Getenv("PARQUET_TESTING_ROOT")
+	if root == "" {
+		t.Skip("The PARQUET_TESTING_ROOT is missing, skip the tests")
+	}
+
+	files, err := filepath.Glob(filepath.Join(root, "data", "*.parquet*"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	codecs := GetRegisteredBlockCompressors()
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			f, err := os.Open(file)
+			require.NoError(t, err)
+			defer f.Close()
+
+			decryption := WithDecryption(parquetTestingKeys(file))
+			r, err := NewFileReaderWithOptions(f, decryption)
+			if errors.Cause(err) == ErrEncryptedFile {
+				t.Skip("the keys of the file are unknown")
+			}
+			require.NoError(t, err)
+			for _, rg := range r.meta.RowGroups {
+				for _, chunk := range rg.Columns {
+					if chunk.MetaData == nil {
+						continue
+					}
+					if _, ok := codecs[chunk.MetaData.Codec]; !ok {
+						t.Skipf("compression codec %s is not registered", chunk.MetaData.Codec)
+					}
+				}
+			}
+
+			_, err = f.Seek(0, io.SeekStart)
+			require.NoError(t, err)
+			report, err := CheckConformance(f, decryption)
+			require.NoError(t, err)
+			require.True(t, report.Valid(), "%v", report.Problems)
+		})
+	}
+}
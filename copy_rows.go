@@ -0,0 +1,193 @@
+package goparquet
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// CopyRowsOption describes an option function that is applied to CopyRows.
+type CopyRowsOption func(opts *copyRowsOptions)
+
+type copyRowsOptions struct {
+	columns  []string
+	filter   func(row map[string]interface{}) bool
+	mapping  func(row map[string]interface{}) (map[string]interface{}, error)
+	rawChunk bool
+}
+
+// WithCopyColumns restricts the columns that are read from the source file to the provided
+// columns or groups, so that the other columns aren't decoded at all. The names need to be
+// provided in dotted notation. Without this option, only the columns of the destination schema
+// are read, unless a mapping is set using WithCopyMapping, in which case all columns are read.
+func WithCopyColumns(columns ...string) CopyRowsOption {
+	return func(opts *copyRowsOptions) {
+		opts.columns = columns
+	}
+}
+
+// WithCopyFilter only copies the rows for which filter returns true. The filter is applied before
+// the mapping set using WithCopyMapping.
+func WithCopyFilter(filter func(row map[string]interface{}) bool) CopyRowsOption {
+	return func(opts *copyRowsOptions) {
+		opts.filter = filter
+	}
+}
+
+// WithCopyMapping maps every row of the source file to a row of the schema of the destination
+// file, e.g. to rename columns or to convert values during a schema migration. If the mapping
+// returns an error, the copy is aborted.
+func WithCopyMapping(mapping func(row map[string]interface{}) (map[string]interface{}, error)) CopyRowsOption {
+	return func(opts *copyRowsOptions) {
+		opts.mapping = mapping
+	}
+}
+
+// WithCopyRawChunks copies whole row groups using CopyChunk instead of decoding and encoding their
+// rows if possible, which keeps the encodings, compression and statistics of the source file. This
+// is possible if neither a filter nor a mapping nor columns are set, no rows of the source file
+// were read yet, the source file has no row group filter and isn't read in salvage mode, the
+// destination file has no unflushed data, and every column of the destination schema has the same
+// type and levels in the source file. Otherwise, the rows are copied one by one.
+func WithCopyRawChunks() CopyRowsOption {
+	return func(opts *copyRowsOptions) {
+		opts.rawChunk = true
+	}
+}
+
+// CopyRows copies all remaining rows of src to dst and returns the number of copied rows. This is
+// the common core of tools that transcode, rewrite or migrate files. The rows are added to dst
+// like using AddData, so the row groups are flushed according to the options of dst, and dst
+// still needs to be closed by the caller. Columns of src that aren't part of the destination
+// schema are dropped. Columns that aren't read are evicted from src, so src can't be used for
+// anything else afterwards.
+func CopyRows(dst *FileWriter, src *FileReader, opts ...CopyRowsOption) (int64, error) {
+	o := &copyRowsOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// the columns of dst are prepared first, so that raw chunks aren't copied to columns whose
+	// type is changed by the options of dst.
+	dst.prepareColumns()
+
+	if o.rawChunk && canCopyRawChunks(dst, src, o) {
+		return copyRawChunks(dst, src)
+	}
+
+	for _, name := range o.columns {
+		if src.GetColumnByName(name) == nil && !isGroupPrefix(src.Columns(), name) {
+			return 0, errors.Errorf("copy column %q not found", name)
+		}
+	}
+
+	columns := o.columns
+	if len(columns) == 0 && o.mapping == nil {
+		for _, col := range dst.Columns() {
+			columns = append(columns, col.FlatName())
+		}
+	}
+	if err := evictUnselectedColumns(src, columns); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for {
+		row, err := src.NextRow()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, errors.Wrap(err, "reading row failed")
+		}
+		delete(row, StatisticsKey)
+
+		if o.filter != nil && !o.filter(row) {
+			continue
+		}
+
+		if o.mapping != nil {
+			if row, err = o.mapping(row); err != nil {
+				return count, errors.Wrap(err, "mapping row failed")
+			}
+		}
+
+		if err := dst.AddData(row); err != nil {
+			return count, errors.Wrap(err, "adding row failed")
+		}
+		count++
+	}
+}
+
+// evictUnselectedColumns evicts the data columns of src that aren't part of the columns or groups
+// in columns. Nothing is evicted if columns is empty.
+func evictUnselectedColumns(src *FileReader, columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+
+	var evict []string
+	for _, col := range src.Columns() {
+		if !col.DataColumn() {
+			continue
+		}
+		if !inProjection(columns, col.FlatName()) {
+			evict = append(evict, col.FlatName())
+		}
+	}
+	if len(evict) == 0 {
+		return nil
+	}
+	return src.EvictColumns(evict...)
+}
+
+// canCopyRawChunks returns true if the row groups of src can be copied to dst using CopyChunk.
+func canCopyRawChunks(dst *FileWriter, src *FileReader, o *copyRowsOptions) bool {
+	if o.filter != nil || o.mapping != nil || len(o.columns) > 0 {
+		return false
+	}
+	if src.rowGroupPosition > 0 || src.opts.rowGroupFilter != nil || src.opts.salvage {
+		return false
+	}
+	if dst.tx != nil || dst.rowGroupNumRecords() > 0 || len(dst.copiedChunks) > 0 {
+		return false
+	}
+
+	for _, col := range dst.Columns() {
+		srcCol := src.GetColumnByName(col.FlatName())
+		if srcCol == nil || !srcCol.DataColumn() || !src.SchemaReader.isSelected(col.FlatName()) {
+			return false
+		}
+		if columnFingerprint(srcCol) != columnFingerprint(col) {
+			return false
+		}
+	}
+
+	for _, rg := range src.meta.RowGroups {
+		for _, chunk := range rg.Columns {
+			if chunk.FilePath != nil {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func copyRawChunks(dst *FileWriter, src *FileReader) (int64, error) {
+	var count int64
+	for rg := range src.meta.RowGroups {
+		for _, col := range dst.Columns() {
+			if err := dst.CopyChunk(src, rg, col.FlatName()); err != nil {
+				return count, err
+			}
+		}
+		count += src.meta.RowGroups[rg].NumRows
+	}
+
+	// all rows of src were consumed.
+	src.rowGroupPosition = len(src.meta.RowGroups)
+	src.skipRowGroup = true
+
+	return count, nil
+}
@@ -0,0 +1,123 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyRows(t *testing.T) {
+	data := writeValidateTestFile(t, WithCompressionCodec(parquet.CompressionCodec_GZIP))
+
+	readRows := func(data []byte) []map[string]interface{} {
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		var rows []map[string]interface{}
+		for {
+			row, err := r.NextRow()
+			if err == io.EOF {
+				return rows
+			}
+			require.NoError(t, err)
+			rows = append(rows, row)
+		}
+	}
+	expected := readRows(data)
+
+	for name, opts := range map[string][]CopyRowsOption{"rows": nil, "raw": {WithCopyRawChunks()}} {
+		t.Run(name, func(t *testing.T) {
+			src, err := NewFileReader(bytes.NewReader(data))
+			require.NoError(t, err)
+
+			buf := &bytes.Buffer{}
+			w := NewFileWriter(buf, WithSchemaDefinition(src.GetSchemaDefinition()), WithCompressionCodec(parquet.CompressionCodec_SNAPPY))
+			n, err := CopyRows(w, src, opts...)
+			require.NoError(t, err)
+			require.Equal(t, int64(300), n)
+			require.NoError(t, w.Close())
+
+			_, err = src.NextRow()
+			require.Equal(t, io.EOF, err)
+
+			require.Equal(t, expected, readRows(buf.Bytes()))
+
+			r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			codec := r.meta.RowGroups[0].Columns[0].MetaData.Codec
+			if name == "raw" {
+				require.Equal(t, 3, r.RowGroupCount())
+				require.Equal(t, parquet.CompressionCodec_GZIP, codec)
+			} else {
+				require.Equal(t, parquet.CompressionCodec_SNAPPY, codec)
+			}
+		})
+	}
+
+	t.Run("mapping", func(t *testing.T) {
+		sd, err := parquetschema.ParseSchemaDefinition(`message test {
+			required int64 key;
+			optional binary label (STRING);
+		}`)
+		require.NoError(t, err)
+
+		src, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		n, err := CopyRows(w, src,
+			WithCopyColumns("id", "name"),
+			WithCopyFilter(func(row map[string]interface{}) bool {
+				return row["id"].(int64)%2 == 0
+			}),
+			WithCopyMapping(func(row map[string]interface{}) (map[string]interface{}, error) {
+				_, ok := row["tags"]
+				require.False(t, ok)
+				return map[string]interface{}{"key": row["id"], "label": row["name"]}, nil
+			}),
+			WithCopyRawChunks(),
+		)
+		require.NoError(t, err)
+		require.Equal(t, int64(150), n)
+		require.NoError(t, w.Close())
+
+		rows := readRows(buf.Bytes())
+		require.Len(t, rows, 150)
+		require.Equal(t, map[string]interface{}{"key": int64(2), "label": []byte("c")}, rows[1])
+		require.Equal(t, map[string]interface{}{"key": int64(4)}, rows[2])
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		sd, err := parquetschema.ParseSchemaDefinition(`message test {
+			required int64 id;
+		}`)
+		require.NoError(t, err)
+
+		src, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		n, err := CopyRows(w, src, WithCopyRawChunks())
+		require.NoError(t, err)
+		require.Equal(t, int64(300), n)
+		require.NoError(t, w.Close())
+
+		rows := readRows(buf.Bytes())
+		require.Len(t, rows, 300)
+		require.Equal(t, map[string]interface{}{"id": int64(299)}, rows[299])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		src, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		w := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(src.GetSchemaDefinition()))
+		_, err = CopyRows(w, src, WithCopyColumns("unknown"))
+		require.Error(t, err)
+	})
+}
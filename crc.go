@@ -0,0 +1,70 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// CRCError is returned when the CRC32 checksum of a page doesn't match its data, or when a page
+// without checksum is read while strict CRC validation is enabled.
+type CRCError struct {
+	// Column is the flat name of the column the page belongs to.
+	Column string
+	// Missing is true if the page has no checksum at all.
+	Missing bool
+	// Expected is the checksum from the page header.
+	Expected uint32
+	// Actual is the checksum of the page data.
+	Actual uint32
+}
+
+func (e *CRCError) Error() string {
+	if e.Missing {
+		return fmt.Sprintf("page of column %s has no CRC32 checksum", e.Column)
+	}
+	return fmt.Sprintf("CRC32 checksum mismatch in page of column %s: expected %08x, got %08x", e.Column, e.Expected, e.Actual)
+}
+
+// pageCRC returns the CRC32 checksum of the page data as it is stored in the file, for the crc
+// field of the page header.
+func pageCRC(data ...[]byte) *int32 {
+	h := crc32.NewIEEE()
+	for _, d := range data {
+		_, _ = h.Write(d)
+	}
+	crc := int32(h.Sum32())
+	return &crc
+}
+
+// readPageWithCRC reads the data of the page with the provided header and validates it against
+// the CRC32 checksum of the header, which covers the page data exactly as it is stored in the
+// file, i.e. after compression. If strict is set, pages without checksum are rejected.
+func readPageWithCRC(r io.Reader, ph *parquet.PageHeader, col *Column, strict bool) (io.Reader, error) {
+	if ph.Crc == nil && !strict {
+		return r, nil
+	}
+
+	if ph.CompressedPageSize < 0 {
+		return nil, errors.New("invalid page data size")
+	}
+
+	data := make([]byte, ph.CompressedPageSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errors.Wrap(err, "reading page data failed")
+	}
+
+	if ph.Crc == nil {
+		return nil, &CRCError{Column: col.FlatName(), Missing: true}
+	}
+
+	if expected, actual := uint32(*ph.Crc), crc32.ChecksumIEEE(data); expected != actual {
+		return nil, &CRCError{Column: col.FlatName(), Expected: expected, Actual: actual}
+	}
+
+	return bytes.NewReader(data), nil
+}
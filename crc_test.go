@@ -0,0 +1,124 @@
+package goparquet
+
+import (
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCRC32Validation(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	withCRC := func(p *EncodedPage, crc uint32) *EncodedPage {
+		c := int32(crc)
+		p.Header.Crc = &c
+		return p
+	}
+
+	valid := testDataPage(t, parquet.Encoding_PLAIN, 1, 2, 3)
+	valid = withCRC(valid, crc32.ChecksumIEEE(valid.Data))
+	corrupted := testDataPage(t, parquet.Encoding_PLAIN, 4, 5, 6)
+	corrupted = withCRC(corrupted, crc32.ChecksumIEEE(corrupted.Data)+1)
+	missing := testDataPage(t, parquet.Encoding_PLAIN, 7, 8, 9)
+
+	tests := map[string]struct {
+		page    *EncodedPage
+		opts    []FileReaderOption
+		missing bool
+		fail    bool
+	}{
+		"valid":                {page: valid, opts: []FileReaderOption{WithCRC32Validation(true)}},
+		"corrupted":            {page: corrupted, opts: []FileReaderOption{WithCRC32Validation(false)}, fail: true},
+		"corrupted unverified": {page: corrupted},
+		"missing lenient":      {page: missing, opts: []FileReaderOption{WithCRC32Validation(false)}},
+		"missing strict":       {page: missing, opts: []FileReaderOption{WithCRC32Validation(true)}, missing: true, fail: true},
+	}
+
+	for name, tt := range tests {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		require.NoError(t, w.WriteEncodedRowGroup(3, []*EncodedColumnChunk{{Column: "foo", Pages: []*EncodedPage{tt.page}}}))
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), tt.opts...)
+		require.NoError(t, err, name)
+
+		_, err = r.NextRow()
+		if !tt.fail {
+			require.NoError(t, err, name)
+			continue
+		}
+
+		var crcErr *CRCError
+		require.True(t, errors.As(err, &crcErr), "%s: unexpected error %v", name, err)
+		require.Equal(t, "foo", crcErr.Column, name)
+		require.Equal(t, tt.missing, crcErr.Missing, name)
+	}
+}
+
+func TestWriteCRC32Checksums(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		repeated int32 tags;
+	}`)
+	require.NoError(t, err)
+
+	tests := map[string][]FileWriterOption{
+		"v1":        {WithCRC32Checksums()},
+		"v1_gzip":   {WithCRC32Checksums(), WithCompressionCodec(parquet.CompressionCodec_GZIP)},
+		"v2":        {WithCRC32Checksums(), WithDataPageV2()},
+		"v2_snappy": {WithCRC32Checksums(), WithDataPageV2(), WithCompressionCodec(parquet.CompressionCodec_SNAPPY)},
+	}
+
+	for name, opts := range tests {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithSchemaDefinition(sd)}, opts...)...)
+		for i := 0; i < 100; i++ {
+			data := map[string]interface{}{
+				"id":   int64(i),
+				"tags": []int32{int32(i), int32(i % 3)},
+			}
+			if i%4 != 0 {
+				data["name"] = []byte{byte('a' + i%5)}
+			}
+			require.NoError(t, w.AddData(data), name)
+		}
+		require.NoError(t, w.Close(), name)
+
+		r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithCRC32Validation(true))
+		require.NoError(t, err, name)
+
+		report, err := r.Validate(0, "name")
+		require.NoError(t, err, name)
+		require.True(t, report.Valid(), "%s: %v", name, report.Problems)
+		require.Equal(t, 1, report.DictionaryPages, name)
+
+		for i := 0; i < 100; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err, name)
+			require.Equal(t, int64(i), row["id"], name)
+		}
+
+		// corrupt the last byte of the page data of the first column.
+		meta := r.meta.RowGroups[0].Columns[0].MetaData
+		corrupted := append([]byte{}, buf.Bytes()...)
+		corrupted[meta.DataPageOffset+meta.TotalCompressedSize-1] ^= 0xff
+
+		r, err = NewFileReaderWithOptions(bytes.NewReader(corrupted), WithCRC32Validation(true))
+		require.NoError(t, err, name)
+
+		_, err = r.NextRow()
+		var crcErr *CRCError
+		require.True(t, errors.As(err, &crcErr), "%s: unexpected error %v", name, err)
+		require.Equal(t, "id", crcErr.Column, name)
+	}
+}
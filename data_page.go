@@ -0,0 +1,217 @@
+package goparquet
+
+import (
+	"io"
+	"math/bits"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+const (
+	// DefaultPageSize is the default target size of the encoded values and levels of a data page.
+	DefaultPageSize = 1024 * 1024
+	// DefaultPageMinValues is the default minimum number of values in a data page.
+	DefaultPageMinValues = 100
+	// DefaultPageMaxValues is the default maximum number of values in a data page.
+	DefaultPageMaxValues = 20000
+	// DefaultMaxDictionarySize is the default maximum plain encoded size of the dictionary of a
+	// column chunk.
+	DefaultMaxDictionarySize = 1024 * 1024
+)
+
+// pageBounds contains the limits that are used to split a column chunk into data pages.
+type pageBounds struct {
+	size      int64
+	minValues int
+	maxValues int
+
+	// dictionarySize is the maximum size of the dictionary, after which the pages of the column
+	// chunk fall back to the encoding of the column.
+	dictionarySize int64
+}
+
+// full returns true if a page with numValues values and an estimated size of sizeBits bits
+// reached one of the limits.
+func (b pageBounds) full(numValues int, sizeBits int64) bool {
+	if b.maxValues > 0 && numValues >= b.maxValues {
+		return true
+	}
+	return b.size > 0 && numValues >= b.minValues && sizeBits >= 8*b.size
+}
+
+func defaultPageBounds() pageBounds {
+	return pageBounds{
+		size:           DefaultPageSize,
+		minValues:      DefaultPageMinValues,
+		maxValues:      DefaultPageMaxValues,
+		dictionarySize: DefaultMaxDictionarySize,
+	}
+}
+
+// dataPage describes the part of a column chunk that is written to a single data page.
+type dataPage struct {
+	rLevels *packedArray
+	dLevels *packedArray
+
+	// values contains the non-null values of the page if the page isn't dictionary encoded,
+	// indices contains their indices into the dictionary of the column chunk otherwise.
+	values  []interface{}
+	indices []int32
+	// dictSize is the number of values in the dictionary of the column chunk if the page is
+	// dictionary encoded, and 0 otherwise.
+	dictSize int
+
+	numValues int32 // including the null values
+	numNulls  int32
+	numRows   int32
+}
+
+// splitDataPages splits the data of the column into data pages. A page is completed as soon as
+// it reaches one of the limits of bounds. Pages only end at record boundaries, so a single record
+// is never split across pages.
+//
+// If useDict is true, the pages are dictionary encoded until the plain encoded size of the
+// dictionary would exceed bounds.dictionarySize. The page that exceeds it and all following pages
+// fall back to the encoding of the column, and the dictionary only contains the values of the
+// pages before. As the values of the dictionary are stored in the order in which they were
+// added, this is always the first dictSize values of the column store. dictSize is 0 if no page
+// is dictionary encoded.
+func splitDataPages(col *Column, bounds pageBounds, useDict bool) (pages []*dataPage, dictSize int) {
+	data := col.data
+	numLevels := data.dLevels.count
+
+	var values []interface{}
+	if !useDict {
+		values = data.values.assemble()
+	}
+
+	if data.alwaysNull() {
+		// the levels of a column without values compress well, so a single page is written
+		// regardless of the number of values.
+		bounds = pageBounds{}
+	}
+
+	maxD := int32(col.MaxDefinitionLevel())
+	levelBits := int64(bits.Len16(col.MaxRepetitionLevel()) + bits.Len16(col.MaxDefinitionLevel()))
+	indexBits := int64(bits.Len(uint(data.values.numDistinctValues())))
+
+	var (
+		levelStart int
+		valueStart int
+		valueIdx   int
+		numNulls   int32
+		numRows    int32
+		sizeBits   int64
+
+		// dictValues is the number of dictionary values used by the pages so far, including the
+		// current page, and dictBytes is their plain encoded size.
+		dictValues int
+		dictBytes  int64
+	)
+
+	addPage := func(levelEnd int) {
+		page := &dataPage{
+			rLevels:   data.rLevels.slice(levelStart, levelEnd),
+			dLevels:   data.dLevels.slice(levelStart, levelEnd),
+			numValues: int32(levelEnd - levelStart),
+			numNulls:  numNulls,
+			numRows:   numRows,
+		}
+		if useDict {
+			page.indices = data.values.data[valueStart:valueIdx]
+			dictSize = dictValues
+		} else {
+			page.values = values[valueStart:valueIdx]
+		}
+		pages = append(pages, page)
+
+		levelStart, valueStart = levelEnd, valueIdx
+		numNulls, numRows, sizeBits = 0, 0, 0
+	}
+
+	// fallback switches to the encoding of the column, starting with the current page, which
+	// contains the levels up to level.
+	fallback := func(level int) {
+		useDict = false
+		values = data.values.assemble()
+		sizeBits = int64(level-levelStart+1) * levelBits
+		for _, v := range values[valueStart:valueIdx] {
+			sizeBits += 8 * int64(data.sizeOf(v))
+		}
+	}
+
+	for i := 0; i < numLevels; i++ {
+		rl, dl, _ := data.getRDLevelAt(i)
+		if rl == 0 {
+			if n := i - levelStart; n > 0 && bounds.full(n, sizeBits) {
+				addPage(i)
+			}
+			numRows++
+		}
+
+		sizeBits += levelBits
+		if dl < maxD {
+			numNulls++
+			continue
+		}
+
+		if useDict {
+			if idx := int(data.values.data[valueIdx]); idx >= dictValues {
+				// values are added to the dictionary in the order in which they first appear.
+				dictValues = idx + 1
+				dictBytes += int64(data.sizeOf(data.values.values[idx]))
+			}
+			if bounds.dictionarySize > 0 && dictBytes > bounds.dictionarySize {
+				fallback(i)
+			}
+		}
+
+		if useDict {
+			sizeBits += indexBits
+		} else {
+			sizeBits += 8 * int64(data.sizeOf(values[valueIdx]))
+		}
+		valueIdx++
+	}
+
+	if numLevels > levelStart || len(pages) == 0 {
+		addPage(numLevels)
+	}
+
+	for _, page := range pages {
+		if page.indices == nil {
+			continue
+		}
+		if dictSize == 0 {
+			// the dictionary encoded pages only contain null values.
+			page.indices, page.values = nil, []interface{}{}
+			continue
+		}
+		page.dictSize = dictSize
+	}
+
+	return pages, dictSize
+}
+
+// encodePageValues encodes the values of the data page using the encoding of the column, or
+// their indices into the dictionary of the column chunk if the page is dictionary encoded.
+func encodePageValues(w io.Writer, col *Column, dictionary bool, page *dataPage) error {
+	if dictionary {
+		return encodeDictIndices(w, page.dictSize, page.indices)
+	}
+
+	encoder, err := getValuesEncoder(col.data.encoding(), col.Element(), col.data.values)
+	if err != nil {
+		return err
+	}
+
+	return encodeValue(w, encoder, page.values)
+}
+
+// dataPageEncoding returns the encoding of the values in the data pages of the column.
+func dataPageEncoding(col *Column, dictionary bool) parquet.Encoding {
+	if dictionary {
+		return parquet.Encoding_RLE_DICTIONARY
+	}
+	return col.data.encoding()
+}
@@ -0,0 +1,202 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitDataPages(t *testing.T) {
+	tests := map[string]struct {
+		opts  []FileWriterOption
+		pages map[string]int
+	}{
+		"max_values": {
+			opts:  []FileWriterOption{WithPageValueLimits(1, 30)},
+			pages: map[string]int{"id": 4, "name": 4, "tags": 7},
+		},
+		"max_values_v2": {
+			opts:  []FileWriterOption{WithDataPageV2(), WithPageValueLimits(1, 30)},
+			pages: map[string]int{"id": 4, "name": 4, "tags": 7},
+		},
+		"size": {
+			opts:  []FileWriterOption{WithPageSize(64), WithPageValueLimits(1, 1000)},
+			pages: map[string]int{"id": 13},
+		},
+		"min_values": {
+			opts:  []FileWriterOption{WithPageSize(1), WithPageValueLimits(50, 1000)},
+			pages: map[string]int{"id": 2, "name": 2, "tags": 4},
+		},
+		"no_size_limit": {
+			opts:  []FileWriterOption{WithPageSize(0), WithPageValueLimits(1, 40)},
+			pages: map[string]int{"id": 3, "name": 3, "tags": 5},
+		},
+		"no_value_limit": {
+			opts:  []FileWriterOption{WithPageSize(400), WithPageValueLimits(1, 0)},
+			pages: map[string]int{"id": 2},
+		},
+		"no_limits": {
+			opts:  []FileWriterOption{WithPageSize(0), WithPageValueLimits(1, 0)},
+			pages: map[string]int{"id": 1, "name": 1, "tags": 1},
+		},
+		"defaults": {
+			pages: map[string]int{"id": 1, "name": 1, "tags": 1},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := writeValidateTestFile(t, tt.opts...)
+			r, err := NewFileReader(bytes.NewReader(data))
+			require.NoError(t, err)
+
+			for rg := 0; rg < r.RowGroupCount(); rg++ {
+				for col, pages := range tt.pages {
+					report, err := r.Validate(rg, col)
+					require.NoError(t, err)
+					require.True(t, report.Valid(), "%s/%d: %v", col, rg, report.Problems)
+					require.Equal(t, pages, report.DataPages, "%s/%d", col, rg)
+				}
+			}
+
+			for i := 0; i < 300; i++ {
+				row, err := r.NextRow()
+				require.NoError(t, err)
+				require.Equal(t, int64(i), row["id"])
+				require.Equal(t, []int32{int32(i), int32(i % 3)}, row["tags"])
+				if i%4 != 0 {
+					require.Equal(t, []byte{byte('a' + i%5)}, row["name"])
+				} else {
+					require.NotContains(t, row, "name")
+				}
+			}
+			_, err = r.NextRow()
+			require.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestDictionaryFallback(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary name (STRING);
+	}`)
+	require.NoError(t, err)
+
+	// the first 500 rows contain 10 different values of 2 bytes, the other rows contain distinct
+	// values of 5 bytes.
+	value := func(i int) []byte {
+		if i < 500 {
+			return []byte(fmt.Sprintf("v%d", i%10))
+		}
+		return []byte(fmt.Sprintf("x%04d", i))
+	}
+
+	tests := map[string]struct {
+		numRows   int
+		dictSize  int64
+		dictPage  int32
+		encodings []parquet.Encoding
+	}{
+		"fallback": {
+			numRows:  1000,
+			dictSize: 100,
+			dictPage: 10,
+			encodings: []parquet.Encoding{
+				parquet.Encoding_RLE_DICTIONARY, parquet.Encoding_RLE_DICTIONARY, parquet.Encoding_RLE_DICTIONARY,
+				parquet.Encoding_RLE_DICTIONARY, parquet.Encoding_RLE_DICTIONARY, parquet.Encoding_PLAIN,
+				parquet.Encoding_PLAIN, parquet.Encoding_PLAIN, parquet.Encoding_PLAIN, parquet.Encoding_PLAIN,
+			},
+		},
+		"fallback_first_page": {
+			numRows:  200,
+			dictSize: 10,
+			encodings: []parquet.Encoding{
+				parquet.Encoding_PLAIN, parquet.Encoding_PLAIN,
+			},
+		},
+		"no_limit": {
+			numRows:  600,
+			dictSize: 0,
+			dictPage: 110,
+			encodings: []parquet.Encoding{
+				parquet.Encoding_RLE_DICTIONARY, parquet.Encoding_RLE_DICTIONARY, parquet.Encoding_RLE_DICTIONARY,
+				parquet.Encoding_RLE_DICTIONARY, parquet.Encoding_RLE_DICTIONARY, parquet.Encoding_RLE_DICTIONARY,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewFileWriter(&buf, WithSchemaDefinition(sd), WithPageValueLimits(1, 100), WithMaxDictionarySize(tt.dictSize))
+			for i := 0; i < tt.numRows; i++ {
+				require.NoError(t, w.AddData(map[string]interface{}{"name": value(i)}))
+			}
+			require.NoError(t, w.Close())
+
+			var (
+				dictPage  int32
+				encodings []parquet.Encoding
+			)
+			r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithPageMiddleware(func(page *PageData) error {
+				if h := page.Header.DictionaryPageHeader; h != nil {
+					dictPage = h.NumValues
+				}
+				if h := page.Header.DataPageHeader; h != nil {
+					encodings = append(encodings, h.Encoding)
+				}
+				return nil
+			}))
+			require.NoError(t, err)
+
+			for i := 0; i < tt.numRows; i++ {
+				row, err := r.NextRow()
+				require.NoError(t, err)
+				require.Equal(t, value(i), row["name"])
+			}
+			_, err = r.NextRow()
+			require.Equal(t, io.EOF, err)
+
+			require.Equal(t, tt.dictPage, dictPage)
+			require.Equal(t, tt.encodings, encodings)
+
+			meta := r.meta.RowGroups[0].Columns[0].MetaData
+			require.Equal(t, tt.dictPage > 0, meta.DictionaryPageOffset != nil)
+			if tt.dictPage > 0 {
+				require.Contains(t, meta.Encodings, parquet.Encoding_RLE_DICTIONARY)
+			}
+		})
+	}
+}
+
+func TestDictionaryLargeColumnChunk(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	// chunks with many values use a dictionary as long as it's small enough.
+	var buf bytes.Buffer
+	w := NewFileWriter(&buf, WithSchemaDefinition(sd))
+	for i := 0; i < 50000; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i % 7)}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	meta := r.meta.RowGroups[0].Columns[0].MetaData
+	require.NotNil(t, meta.DictionaryPageOffset)
+	require.Equal(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN, parquet.Encoding_RLE_DICTIONARY}, meta.Encodings)
+
+	for i := 0; i < 50000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i%7), row["id"])
+	}
+}
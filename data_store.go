@@ -1,7 +1,6 @@
 package goparquet
 
 import (
-	"math"
 	"math/bits"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -28,6 +27,12 @@ type ColumnStore struct {
 	allowDict bool
 
 	skipped bool
+
+	// timestamps contains the options to convert time.Time values into TIMESTAMP values, nil for
+	// the defaults.
+	timestamps *timestampOptions
+	// int96 is set if the column is a TIMESTAMP column that is stored as INT96.
+	int96 *int96Timestamp
 }
 
 // useDictionary is simply a function to decide to use dictionary or not,
@@ -35,9 +40,6 @@ func (cs *ColumnStore) useDictionary() bool {
 	if !cs.allowDict {
 		return false
 	}
-	if len(cs.values.data) > math.MaxInt16 {
-		return false
-	}
 
 	// There is no point for using dictionary if all values are nil
 	if len(cs.values.data) == 0 || len(cs.values.values) == 0 {
@@ -48,10 +50,44 @@ func (cs *ColumnStore) useDictionary() bool {
 	return dictLen < noDictLen
 }
 
+// alwaysNull returns true if the column is annotated with the UNKNOWN logical type, which is
+// used for columns that only contain null values.
+func (cs *ColumnStore) alwaysNull() bool {
+	params := cs.params()
+	return params != nil && params.LogicalType != nil && params.LogicalType.IsSetUNKNOWN()
+}
+
 func (cs *ColumnStore) encoding() parquet.Encoding {
 	return cs.enc
 }
 
+// writerEncodings contains the encodings that can be used to write the values of each
+// physical type, apart from dictionary encoding.
+var writerEncodings = map[parquet.Type][]parquet.Encoding{
+	parquet.Type_BOOLEAN:              {parquet.Encoding_PLAIN, parquet.Encoding_RLE},
+	parquet.Type_INT32:                {parquet.Encoding_PLAIN, parquet.Encoding_DELTA_BINARY_PACKED},
+	parquet.Type_INT64:                {parquet.Encoding_PLAIN, parquet.Encoding_DELTA_BINARY_PACKED},
+	parquet.Type_INT96:                {parquet.Encoding_PLAIN},
+	parquet.Type_FLOAT:                {parquet.Encoding_PLAIN},
+	parquet.Type_DOUBLE:               {parquet.Encoding_PLAIN},
+	parquet.Type_BYTE_ARRAY:           {parquet.Encoding_PLAIN, parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY, parquet.Encoding_DELTA_BYTE_ARRAY},
+	parquet.Type_FIXED_LEN_BYTE_ARRAY: {parquet.Encoding_PLAIN, parquet.Encoding_DELTA_BYTE_ARRAY},
+}
+
+// setEncoding sets the encoding that is used to write the values and disables the dictionary.
+func (cs *ColumnStore) setEncoding(enc parquet.Encoding) error {
+	typ := cs.parquetType()
+	for _, e := range writerEncodings[typ] {
+		if e == enc {
+			cs.enc = enc
+			cs.allowDict = false
+			return nil
+		}
+	}
+
+	return errors.Errorf("encoding %q is not supported on type %s", enc, typ)
+}
+
 func (cs *ColumnStore) repetitionType() parquet.FieldRepetitionType {
 	return cs.repTyp
 }
@@ -75,6 +111,69 @@ func (cs *ColumnStore) reset(rep parquet.FieldRepetitionType, maxR, maxD uint16)
 	cs.typedColumnStore.reset(rep)
 }
 
+// release frees the data of the store. Like the store of a column that isn't selected, it
+// doesn't return any values afterwards until it is reset.
+func (cs *ColumnStore) release() {
+	if cs.values == nil {
+		// nothing was read yet.
+		return
+	}
+	rbw, dbw := cs.rLevels.bw, cs.dLevels.bw
+	cs.values = &dictStore{}
+	cs.values.init()
+	cs.rLevels = &packedArray{}
+	cs.rLevels.reset(rbw)
+	cs.dLevels = &packedArray{}
+	cs.dLevels.reset(dbw)
+	cs.readPos = 0
+	cs.skipped = true
+}
+
+// columnStoreMark is the state of a ColumnStore at a certain point while writing.
+type columnStoreMark struct {
+	levels    int
+	values    int
+	data      int
+	nullCount int32
+	size      int64
+	valueSize int64
+}
+
+func (cs *ColumnStore) mark() columnStoreMark {
+	return columnStoreMark{
+		levels:    cs.dLevels.count,
+		values:    len(cs.values.values),
+		data:      len(cs.values.data),
+		nullCount: cs.values.nullCount,
+		size:      cs.values.size,
+		valueSize: cs.values.valueSize,
+	}
+}
+
+// truncate removes all data that was added after the mark m was taken. The min and max values
+// are recalculated from the remaining values.
+func (cs *ColumnStore) truncate(m columnStoreMark) error {
+	if m.levels > cs.dLevels.count || m.values > len(cs.values.values) || m.data > len(cs.values.data) {
+		return errors.New("mark is beyond the current data")
+	}
+
+	cs.rLevels.truncate(m.levels)
+	cs.dLevels.truncate(m.levels)
+	cs.values.truncate(m.values, m.data)
+	cs.values.nullCount = m.nullCount
+	cs.values.size = m.size
+	cs.values.valueSize = m.valueSize
+
+	cs.typedColumnStore.reset(cs.repTyp)
+	for _, v := range cs.values.values {
+		if _, err := cs.getValues(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (cs *ColumnStore) appendRDLevel(rl, dl uint16) {
 	cs.rLevels.appendSingle(int32(rl))
 	cs.dLevels.appendSingle(int32(dl))
@@ -91,6 +190,11 @@ func (cs *ColumnStore) add(v interface{}, dL uint16, maxRL, rL uint16) error {
 	if rL > maxRL {
 		rL = maxRL
 	}
+	v, err := cs.convertValue(v)
+	if err != nil {
+		return err
+	}
+
 	// the dL is a little tricky. there is some case if the REQUIRED field here are nil (since there is something above
 	// them is nil) they can not be the first level, but if they are in the next levels, is actually ok, but the
 	// level is one less
@@ -103,6 +207,9 @@ func (cs *ColumnStore) add(v interface{}, dL uint16, maxRL, rL uint16) error {
 	if err != nil {
 		return err
 	}
+	if len(vals) > 0 && cs.alwaysNull() {
+		return errors.New("the column is annotated as UNKNOWN and can only contain null values")
+	}
 	if len(vals) == 0 {
 		// the MaxRl might be increased in the beginning and increased again in the next call but for nil its not important
 		return cs.add(nil, dL, maxRL, rL)
@@ -125,6 +232,20 @@ func (cs *ColumnStore) add(v interface{}, dL uint16, maxRL, rL uint16) error {
 	return nil
 }
 
+// convertValue converts values of the Go types that are accepted for logical types, like Decimal
+// for DECIMAL, time.Time for DATE and TIMESTAMP and strings for UUID columns, into the physical
+// type of the column.
+func (cs *ColumnStore) convertValue(v interface{}) (interface{}, error) {
+	v, err := cs.convertTimestamps(cs.convertDates(v))
+	if err != nil {
+		return nil, err
+	}
+	if v, err = cs.convertUUIDs(v); err != nil {
+		return nil, err
+	}
+	return cs.convertDecimals(v)
+}
+
 // getRDLevelAt return the next rLevel in the read position, if there is no value left, it returns true
 // if the position is less than zero, then it returns the current position
 // NOTE: make sure always r is before d, in any function
@@ -0,0 +1,154 @@
+package goparquet
+
+import (
+	"time"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// WithDateConversion makes NextRow return the values of columns annotated as DATE as time.Time
+// values at midnight UTC instead of the number of days since the Unix epoch as int32. Repeated
+// DATE columns are returned as []time.Time.
+func WithDateConversion() FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.dateConversion = true
+	}
+}
+
+// DateToTime returns the time at midnight UTC of a DATE value, which is the number of days since
+// the Unix epoch.
+func DateToTime(days int32) time.Time {
+	return time.Unix(int64(days)*secondsPerDay, 0).UTC()
+}
+
+// TimeToDate returns the DATE value of the date of t in its location, which is the number of days
+// since the Unix epoch.
+func TimeToDate(t time.Time) int32 {
+	y, m, d := t.Date()
+	return int32(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / secondsPerDay)
+}
+
+// isDateElement returns true if the column is annotated as DATE.
+func isDateElement(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil && elem.LogicalType.IsSetDATE() {
+		return true
+	}
+	return elem.ConvertedType != nil && elem.GetConvertedType() == parquet.ConvertedType_DATE
+}
+
+// convertDates converts time.Time values, which may be a single value or a slice of values, into
+// DATE values if the column is annotated as DATE. Other values are returned unchanged.
+func (cs *ColumnStore) convertDates(v interface{}) interface{} {
+	switch typed := v.(type) {
+	case time.Time:
+		if cs.isDate() {
+			return TimeToDate(typed)
+		}
+	case []time.Time:
+		if cs.isDate() {
+			dates := make([]int32, len(typed))
+			for i := range typed {
+				dates[i] = TimeToDate(typed[i])
+			}
+			return dates
+		}
+	}
+	return v
+}
+
+func (cs *ColumnStore) isDate() bool {
+	params := cs.params()
+	if params == nil || cs.parquetType() != parquet.Type_INT32 {
+		return false
+	}
+	return isDateElement(&parquet.SchemaElement{LogicalType: params.LogicalType, ConvertedType: params.ConvertedType})
+}
+
+// convertRow converts the values of a row returned by NextRow into the Go types requested by
+// WithDateConversion, WithTimestampConversion, WithInt96Conversion and WithUUIDFormat.
+func (f *FileReader) convertRow(row map[string]interface{}) {
+	if f.rowSchema == nil {
+		f.rowSchema = f.RowSchemaDefinition()
+	}
+	f.opts.convertGroup(f.rowSchema.RootColumn.Children, row)
+}
+
+func (opts *fileReaderOptions) convertGroup(cols []*parquetschema.ColumnDefinition, data map[string]interface{}) {
+	for name, v := range data {
+		col := findColumnDefinition(cols, name)
+		if col == nil {
+			continue
+		}
+
+		switch typed := v.(type) {
+		case map[string]interface{}:
+			opts.convertGroup(col.Children, typed)
+		case []map[string]interface{}:
+			for i := range typed {
+				opts.convertGroup(col.Children, typed[i])
+			}
+		default:
+			data[name] = opts.convertValue(col.SchemaElement, v)
+		}
+	}
+}
+
+// convertsValues returns true if the values of the rows need to be converted.
+func (opts *fileReaderOptions) convertsValues() bool {
+	return opts.dateConversion || opts.timestampConversion || opts.int96Conversion || opts.uuidFormat != UUIDBytes
+}
+
+// convertValue converts the value v of the column described by elem, which may be a slice of
+// values if the column is repeated.
+func (opts *fileReaderOptions) convertValue(elem *parquet.SchemaElement, v interface{}) interface{} {
+	switch typed := v.(type) {
+	case int32:
+		if opts.dateConversion && isDateElement(elem) {
+			return DateToTime(typed)
+		}
+	case []int32:
+		if opts.dateConversion && isDateElement(elem) {
+			times := make([]time.Time, len(typed))
+			for i := range typed {
+				times[i] = DateToTime(typed[i])
+			}
+			return times
+		}
+	case int64:
+		if unit, utc, ok := timestampUnit(elem); ok && opts.timestampConversion {
+			return opts.timestampToTime(typed, unit, utc)
+		}
+	case []int64:
+		if unit, utc, ok := timestampUnit(elem); ok && opts.timestampConversion {
+			times := make([]time.Time, len(typed))
+			for i := range typed {
+				times[i] = opts.timestampToTime(typed[i], unit, utc)
+			}
+			return times
+		}
+	case [12]byte:
+		if opts.int96Conversion {
+			return Int96ToTime(typed).UTC()
+		}
+	case [][12]byte:
+		if opts.int96Conversion {
+			times := make([]time.Time, len(typed))
+			for i := range typed {
+				times[i] = Int96ToTime(typed[i]).UTC()
+			}
+			return times
+		}
+	case []byte:
+		if opts.uuidFormat != UUIDBytes && isUUIDElement(elem) {
+			return opts.uuidFormat.value(typed)
+		}
+	case [][]byte:
+		if opts.uuidFormat != UUIDBytes && isUUIDElement(elem) {
+			return opts.uuidFormat.values(typed)
+		}
+	}
+	return v
+}
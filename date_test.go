@@ -0,0 +1,62 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateConversion(t *testing.T) {
+	require.Equal(t, int32(18518), TimeToDate(time.Date(2020, 9, 13, 23, 59, 0, 0, time.FixedZone("CEST", 2*60*60))))
+	require.Equal(t, int32(-1), TimeToDate(time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), DateToTime(-1))
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 day (DATE);
+		optional int32 other (DATE);
+		repeated int32 days (DATE);
+		required int32 count;
+		optional group nested {
+			required int32 day (DATE);
+		}
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"day":    time.Date(2020, 9, 13, 12, 0, 0, 0, time.UTC),
+		"days":   []time.Time{time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+		"count":  int32(3),
+		"nested": map[string]interface{}{"day": int32(1)},
+	}))
+	require.NoError(t, w.Close())
+
+	// time.Time values are only accepted for DATE columns.
+	require.Error(t, NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd)).AddData(map[string]interface{}{"day": int32(1), "count": time.Now()}))
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"day":    int32(18518),
+		"days":   []int32{1, -1},
+		"count":  int32(3),
+		"nested": map[string]interface{}{"day": int32(1)},
+	}, row)
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithDateConversion())
+	require.NoError(t, err)
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"day":    time.Date(2020, 9, 13, 0, 0, 0, 0, time.UTC),
+		"days":   []time.Time{time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+		"count":  int32(3),
+		"nested": map[string]interface{}{"day": time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}, row)
+}
@@ -0,0 +1,219 @@
+package goparquet
+
+import (
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// Decimal is a decimal number with the value Unscaled * 10^-Scale. Decimals can be written to
+// columns annotated as DECIMAL of any physical type, and are converted to the scale of the column
+// and encoded in its physical type when they're added. Decimal strings like "-12.30" can be
+// written to these columns as well.
+type Decimal struct {
+	// Unscaled is the unscaled value. A nil value is 0.
+	Unscaled *big.Int
+	// Scale is the number of digits after the decimal point.
+	Scale int32
+}
+
+// NewDecimal returns the decimal unscaled * 10^-scale.
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	return Decimal{Unscaled: big.NewInt(unscaled), Scale: scale}
+}
+
+// ParseDecimal parses a decimal string like "-12.30". The scale of the decimal is the number of
+// digits after the decimal point.
+func ParseDecimal(s string) (Decimal, error) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 {
+		return Decimal{}, errors.Errorf("invalid decimal %q", s)
+	}
+
+	var scale int32
+	if idx := strings.IndexByte(digits, '.'); idx >= 0 {
+		scale = int32(len(digits) - idx - 1)
+		digits = digits[:idx] + digits[idx+1:]
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, errors.Errorf("invalid decimal %q", s)
+	}
+
+	unscaled, _ := new(big.Int).SetString(digits, 10)
+	if strings.HasPrefix(s, "-") {
+		unscaled.Neg(unscaled)
+	}
+	return Decimal{Unscaled: unscaled, Scale: scale}, nil
+}
+
+func (d Decimal) String() string {
+	return formatDecimal(d.unscaled(), d.Scale)
+}
+
+func (d Decimal) unscaled() *big.Int {
+	if d.Unscaled == nil {
+		return new(big.Int)
+	}
+	return d.Unscaled
+}
+
+// rescale returns the unscaled value of d with the scale scale. It fails if the value can't be
+// represented with the scale without rounding.
+func (d Decimal) rescale(scale int32) (*big.Int, error) {
+	unscaled := d.unscaled()
+	if d.Scale == scale {
+		return unscaled, nil
+	}
+
+	if d.Scale < scale {
+		factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-d.Scale)), nil)
+		return new(big.Int).Mul(unscaled, factor), nil
+	}
+
+	factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.Scale-scale)), nil)
+	q, r := new(big.Int).QuoRem(unscaled, factor, new(big.Int))
+	if r.Sign() != 0 {
+		return nil, errors.Errorf("decimal %s can't be represented with scale %d", d, scale)
+	}
+	return q, nil
+}
+
+// decimalParams returns the scale and precision of a column annotated as DECIMAL. ok is false if
+// the column isn't annotated as DECIMAL.
+func decimalParams(params *ColumnParameters) (scale, precision int32, ok bool) {
+	if params == nil {
+		return 0, 0, false
+	}
+	if params.LogicalType != nil && params.LogicalType.DECIMAL != nil {
+		return params.LogicalType.DECIMAL.Scale, params.LogicalType.DECIMAL.Precision, true
+	}
+	if params.ConvertedType != nil && *params.ConvertedType == parquet.ConvertedType_DECIMAL {
+		if params.Scale != nil {
+			scale = *params.Scale
+		}
+		if params.Precision != nil {
+			precision = *params.Precision
+		}
+		return scale, precision, true
+	}
+	return 0, 0, false
+}
+
+// convertDecimals converts the Decimal values and decimal strings in v, which may be a single
+// value or a slice of values, into the physical type of the column if it is annotated as DECIMAL.
+// Other values are returned unchanged.
+func (cs *ColumnStore) convertDecimals(v interface{}) (interface{}, error) {
+	scale, precision, ok := decimalParams(cs.params())
+	if !ok {
+		return v, nil
+	}
+
+	var arr interface{}
+	switch typed := v.(type) {
+	case Decimal:
+		return cs.encodeDecimal(typed, scale, precision)
+	case *Decimal:
+		if typed == nil {
+			return nil, nil
+		}
+		return cs.encodeDecimal(*typed, scale, precision)
+	case string:
+		d, err := ParseDecimal(typed)
+		if err != nil {
+			return nil, err
+		}
+		return cs.encodeDecimal(d, scale, precision)
+	case []Decimal:
+		for i := range typed {
+			encoded, err := cs.encodeDecimal(typed[i], scale, precision)
+			if err != nil {
+				return nil, err
+			}
+			arr = cs.append(arr, encoded)
+		}
+	case []string:
+		for i := range typed {
+			d, err := ParseDecimal(typed[i])
+			if err != nil {
+				return nil, err
+			}
+			encoded, err := cs.encodeDecimal(d, scale, precision)
+			if err != nil {
+				return nil, err
+			}
+			arr = cs.append(arr, encoded)
+		}
+	default:
+		return v, nil
+	}
+	return arr, nil
+}
+
+// encodeDecimal converts d to the scale of the column, checks that it fits into the precision of
+// the column and encodes it in the physical type of the column.
+func (cs *ColumnStore) encodeDecimal(d Decimal, scale, precision int32) (interface{}, error) {
+	unscaled, err := d.rescale(scale)
+	if err != nil {
+		return nil, err
+	}
+
+	if precision > 0 {
+		limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+		if new(big.Int).Abs(unscaled).Cmp(limit) >= 0 {
+			return nil, errors.Errorf("decimal %s exceeds the precision %d", d, precision)
+		}
+	}
+
+	switch typ := cs.parquetType(); typ {
+	case parquet.Type_INT32:
+		if !unscaled.IsInt64() || unscaled.Int64() < math.MinInt32 || unscaled.Int64() > math.MaxInt32 {
+			return nil, errors.Errorf("decimal %s doesn't fit into %s", d, typ)
+		}
+		return int32(unscaled.Int64()), nil
+	case parquet.Type_INT64:
+		if !unscaled.IsInt64() {
+			return nil, errors.Errorf("decimal %s doesn't fit into %s", d, typ)
+		}
+		return unscaled.Int64(), nil
+	case parquet.Type_BYTE_ARRAY:
+		return decimalToBytes(unscaled, 0)
+	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		var size int
+		if length := cs.params().TypeLength; length != nil {
+			size = int(*length)
+		}
+		b, err := decimalToBytes(unscaled, size)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decimal %s doesn't fit into %s", d, typ)
+		}
+		return b, nil
+	default:
+		return nil, errors.Errorf("DECIMAL isn't supported for type %s", typ)
+	}
+}
+
+// decimalToBytes encodes the unscaled value of a decimal as big-endian two's complement. If size is
+// 0, the minimal number of bytes is used, otherwise the value is sign-extended to size bytes.
+func decimalToBytes(unscaled *big.Int, size int) ([]byte, error) {
+	// the number of bytes that are needed including the sign bit.
+	n := unscaled.BitLen()/8 + 1
+	if unscaled.Sign() < 0 {
+		n = new(big.Int).Not(unscaled).BitLen()/8 + 1
+	}
+	if size > 0 {
+		if n > size {
+			return nil, errors.Errorf("%d bytes are needed but the size is %d bytes", n, size)
+		}
+		n = size
+	}
+
+	v := unscaled
+	if unscaled.Sign() < 0 {
+		v = new(big.Int).Add(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(n)*8))
+	}
+	b := v.Bytes()
+	return append(make([]byte, n-len(b), n), b...), nil
+}
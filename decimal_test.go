@@ -0,0 +1,112 @@
+package goparquet
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDecimal(t *testing.T) {
+	for s, expected := range map[string]string{
+		"0":       "0",
+		"-12.30":  "-12.30",
+		"+1.5":    "1.5",
+		".25":     "0.25",
+		"-0.0001": "-0.0001",
+		"42.":     "42",
+	} {
+		d, err := ParseDecimal(s)
+		require.NoError(t, err, s)
+		require.Equal(t, expected, d.String(), s)
+	}
+
+	for _, s := range []string{"", "-", ".", "1.2.3", "1e5", "--1", "abc"} {
+		_, err := ParseDecimal(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestDecimalToBytes(t *testing.T) {
+	tests := []struct {
+		unscaled int64
+		size     int
+		expected []byte
+	}{
+		{0, 0, []byte{0x00}},
+		{127, 0, []byte{0x7f}},
+		{128, 0, []byte{0x00, 0x80}},
+		{-1, 0, []byte{0xff}},
+		{-128, 0, []byte{0x80}},
+		{-129, 0, []byte{0xff, 0x7f}},
+		{-5, 4, []byte{0xff, 0xff, 0xff, 0xfb}},
+		{300, 4, []byte{0x00, 0x00, 0x01, 0x2c}},
+	}
+	for _, tt := range tests {
+		b, err := decimalToBytes(big.NewInt(tt.unscaled), tt.size)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, b, "%d", tt.unscaled)
+		require.Equal(t, tt.unscaled, decimalFromBytes(b).Int64())
+	}
+
+	_, err := decimalToBytes(big.NewInt(128), 1)
+	require.Error(t, err)
+}
+
+func TestWriteDecimals(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 a (DECIMAL(9, 2));
+		required int64 b (DECIMAL(18, 3));
+		optional binary c (DECIMAL(30, 2));
+		optional fixed_len_byte_array(4) d (DECIMAL(8, 2));
+		repeated int32 e (DECIMAL(5, 1));
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	huge, _ := new(big.Int).SetString("-1234567890123456789012", 10)
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"a": "-12.3",
+		"b": NewDecimal(5, 0),
+		"c": Decimal{Unscaled: huge, Scale: 2},
+		"d": &Decimal{Unscaled: big.NewInt(-5), Scale: 2},
+		"e": []string{"1.5", "-2"},
+	}))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"a": int32(7),
+		"b": int64(8),
+		"d": (*Decimal)(nil),
+		"e": []Decimal{NewDecimal(100, 2)},
+	}))
+
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int32(-1230), row["a"])
+	require.Equal(t, int64(5000), row["b"])
+	require.Equal(t, huge.String(), decimalFromBytes(row["c"].([]byte)).String())
+	require.Equal(t, []byte{0xff, 0xff, 0xff, 0xfb}, row["d"])
+	require.Equal(t, []int32{15, -20}, row["e"])
+
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"a": int32(7), "b": int64(8), "e": []int32{10}}, row)
+
+	for _, row := range []map[string]interface{}{
+		{"a": "1.234", "b": int64(0)},
+		{"a": "10000000.00", "b": int64(0)},
+		{"a": "1", "b": int64(0), "d": "1000000.00"},
+		{"a": "1.x", "b": int64(0)},
+		{"a": "1", "b": Decimal{Unscaled: huge, Scale: 3}},
+	} {
+		w := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+		require.Error(t, w.AddData(row), "%v", row)
+	}
+}
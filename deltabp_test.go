@@ -5,6 +5,9 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetencoding"
+	"github.com/fraugster/parquet-go/parquetschema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -21,10 +24,7 @@ func buildDataDelta(l int) []int32 {
 func TestDelta(t *testing.T) {
 	for i := 1; i < 32; i++ {
 		data := &bytes.Buffer{}
-		enc := &deltaBitPackEncoder32{
-			blockSize:      128,
-			miniBlockCount: 4,
-		}
+		enc := &deltaBitPackEncoder32{}
 		assert.NoError(t, enc.init(data))
 		to1 := buildDataDelta(8*1024 + 5)
 		for _, i := range to1 {
@@ -33,10 +33,7 @@ func TestDelta(t *testing.T) {
 		assert.NoError(t, enc.Close())
 
 		buf2 := bytes.NewReader(data.Bytes())
-		dec := &deltaBitPackDecoder32{
-			blockSize:      128,
-			miniBlockCount: 4,
-		}
+		dec := &deltaBitPackDecoder32{}
 		assert.NoError(t, dec.init(buf2))
 		var toR []int32
 		total := len(to1)
@@ -50,3 +47,54 @@ func TestDelta(t *testing.T) {
 		assert.Equal(t, toR, to1)
 	}
 }
+
+func TestDeltaCompatibility(t *testing.T) {
+	values := buildDataDelta(1005)
+
+	data := &bytes.Buffer{}
+	enc := &deltaBitPackEncoder32{}
+	require.NoError(t, enc.init(data))
+	for _, v := range values {
+		require.NoError(t, enc.addInt32(v))
+	}
+	require.NoError(t, enc.Close())
+
+	pdec, err := parquetencoding.NewDeltaDecoder(data)
+	require.NoError(t, err)
+	decoded := make([]int32, len(values))
+	_, err = pdec.DecodeInt32(decoded)
+	require.NoError(t, err)
+	require.Equal(t, values, decoded)
+
+	data.Reset()
+	require.NoError(t, parquetencoding.EncodeDeltaInt32(data, values))
+	dec := &deltaBitPackDecoder32{}
+	require.NoError(t, dec.init(data))
+	for i := range decoded {
+		decoded[i], err = dec.next()
+		require.NoError(t, err)
+	}
+	require.Equal(t, values, decoded)
+}
+
+func TestDeltaSingleValue(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 a;
+		required int64 b;
+	}`)
+	require.NoError(t, err)
+
+	// a single value is stored in the header only, without any block.
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd),
+		WithEncodingForColumn("a", parquet.Encoding_DELTA_BINARY_PACKED),
+		WithEncodingForColumn("b", parquet.Encoding_DELTA_BINARY_PACKED))
+	require.NoError(t, w.AddData(map[string]interface{}{"a": int32(42), "b": int64(-42)}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"a": int32(42), "b": int64(-42)}, row)
+}
@@ -0,0 +1,145 @@
+package goparquet
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DictionaryChunk contains the data of a single column chunk in dictionary form. Instead of
+// the values themselves, every non-null value is represented by its index in Dictionary.
+// This allows callers to e.g. group or join on small integer keys, and to only look up
+// the actual values when they are required.
+type DictionaryChunk struct {
+	// Dictionary contains the distinct values of the dictionary page, followed by the values
+	// of all pages that were not dictionary encoded, i.e. after the writer fell back to another
+	// encoding. The latter are not deduplicated.
+	Dictionary []interface{}
+	// Indices contains the dictionary index of every non-null value of the column chunk.
+	Indices []int32
+	// DefinitionLevels and RepetitionLevels contain the definition and repetition level of every
+	// value including nulls.
+	DefinitionLevels []int32
+	RepetitionLevels []int32
+}
+
+// Value returns the i-th non-null value of the column chunk.
+func (dc *DictionaryChunk) Value(i int) interface{} {
+	return dc.Dictionary[dc.Indices[i]]
+}
+
+// ReadDictionaryChunk reads the chunk of the column colName in the row group with index rowGroup
+// and returns its values as indices into the dictionary of the chunk, without materializing the
+// values of dictionary encoded pages. The column name has to be provided in its dotted notation.
+// It doesn't change the position of the reader for NextRow.
+func (f *FileReader) ReadDictionaryChunk(rowGroup int, colName string) (*DictionaryChunk, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return nil, errors.Errorf("row group index %d is out of bounds", rowGroup)
+	}
+
+	col := f.GetColumnByName(colName)
+	if col == nil {
+		return nil, errors.Errorf("column %q not found", colName)
+	}
+
+	rg := f.meta.RowGroups[rowGroup]
+	if len(rg.Columns) <= col.Index() {
+		return nil, errors.Errorf("column index %d is out of bounds", col.Index())
+	}
+
+	c := &dictIndexCollector{}
+	pages, err := readChunk(f.reader, col, rg.Columns[col.Index()], &f.opts, newMemoryTracker(f.opts.maxMemorySize), c)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DictionaryChunk{}
+	for _, p := range pages {
+		data := make([]interface{}, p.numValues())
+		n, _, dl, rl, err := p.readValues(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if int32(n) != p.numValues() {
+			return nil, errors.Errorf("expect %d value but read %d", p.numValues(), n)
+		}
+
+		result.DefinitionLevels = appendLevels(result.DefinitionLevels, dl)
+		result.RepetitionLevels = appendLevels(result.RepetitionLevels, rl)
+	}
+
+	result.Dictionary = c.dictionary
+	result.Indices = c.indices
+
+	return result, nil
+}
+
+func appendLevels(dst []int32, levels *packedArray) []int32 {
+	if levels == nil {
+		return dst
+	}
+	for i := 0; i < levels.count; i++ {
+		v, _ := levels.at(i)
+		dst = append(dst, v)
+	}
+	return dst
+}
+
+// dictIndexCollector collects the dictionary indices of all pages of a column chunk.
+type dictIndexCollector struct {
+	dictionary []interface{}
+	indices    []int32
+}
+
+func (c *dictIndexCollector) wrap(dec valuesDecoder) valuesDecoder {
+	if dd, ok := dec.(*dictDecoder); ok {
+		return &dictIndexDecoder{dictDecoder: dd, c: c}
+	}
+	return &fallbackIndexDecoder{valuesDecoder: dec, c: c}
+}
+
+// dictIndexDecoder only decodes the keys of a dictionary encoded page and doesn't look up the values.
+// The values in dst are left untouched.
+type dictIndexDecoder struct {
+	*dictDecoder
+
+	c *dictIndexCollector
+}
+
+func (d *dictIndexDecoder) decodeValues(dst []interface{}) (int, error) {
+	if d.keys == nil {
+		return 0, errors.New("no value is inside dictionary")
+	}
+	size := int32(len(d.values))
+
+	for i := range dst {
+		key, err := d.keys.next()
+		if err != nil {
+			return i, err
+		}
+
+		if key < 0 || key >= size {
+			return i, errors.Errorf("dict: invalid index %d, values count are %d", key, size)
+		}
+
+		d.c.indices = append(d.c.indices, key)
+	}
+
+	return len(dst), nil
+}
+
+// fallbackIndexDecoder appends the values of a page that is not dictionary encoded to the
+// dictionary, so that they can be referenced by index as well.
+type fallbackIndexDecoder struct {
+	valuesDecoder
+
+	c *dictIndexCollector
+}
+
+func (d *fallbackIndexDecoder) decodeValues(dst []interface{}) (int, error) {
+	n, err := d.valuesDecoder.decodeValues(dst)
+	for _, v := range dst[:n] {
+		d.c.indices = append(d.c.indices, int32(len(d.c.dictionary)))
+		d.c.dictionary = append(d.c.dictionary, v)
+	}
+	return n, err
+}
@@ -0,0 +1,98 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDictionaryChunk(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.WriteEncodedRowGroup(7, []*EncodedColumnChunk{
+		{
+			Column: "foo",
+			Pages: []*EncodedPage{
+				testDictPage(t, 100, 200, 300),
+				testDataPage(t, parquet.Encoding_RLE_DICTIONARY, 2, 0, 2, 1),
+				testDataPage(t, parquet.Encoding_PLAIN, 400, 500, 600),
+			},
+		},
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	dc, err := r.ReadDictionaryChunk(0, "foo")
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(100), int64(200), int64(300), int64(400), int64(500), int64(600)}, dc.Dictionary)
+	require.Equal(t, []int32{2, 0, 2, 1, 3, 4, 5}, dc.Indices)
+	require.Equal(t, []int32{0, 0, 0, 0, 0, 0, 0}, dc.DefinitionLevels)
+	require.Equal(t, int64(300), dc.Value(2))
+
+	_, err = r.ReadDictionaryChunk(1, "foo")
+	require.Error(t, err)
+
+	_, err = r.ReadDictionaryChunk(0, "bar")
+	require.Error(t, err)
+}
+
+func TestReadDictionaryChunkOptional(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional binary name (STRING);
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, name := range []string{"a", "b", "", "a", "a"} {
+		data := map[string]interface{}{}
+		if name != "" {
+			data["name"] = []byte(name)
+		}
+		require.NoError(t, w.AddData(data))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	dc, err := r.ReadDictionaryChunk(0, "name")
+	require.NoError(t, err)
+	require.Equal(t, []int32{1, 1, 0, 1, 1}, dc.DefinitionLevels)
+	require.Len(t, dc.Indices, 4)
+
+	var values []string
+	for i := range dc.Indices {
+		values = append(values, string(dc.Value(i).([]byte)))
+	}
+	require.Equal(t, []string{"a", "b", "a", "a"}, values)
+
+	// reading the dictionary chunk doesn't affect reading rows.
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"name": []byte("a")}, row)
+}
+
+func TestDictIndexDecoderInvalidIndex(t *testing.T) {
+	page := testDataPage(t, parquet.Encoding_RLE_DICTIONARY, 1, 0, 3, 2)
+
+	c := &dictIndexCollector{}
+	dec := c.wrap(&dictDecoder{values: []interface{}{int64(100), int64(200), int64(300)}})
+	require.NoError(t, dec.init(bytes.NewReader(page.Data)))
+
+	// the indices before the invalid one are reported as decoded.
+	n, err := dec.decodeValues(make([]interface{}, 4))
+	require.Error(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []int32{1, 0}, c.indices)
+}
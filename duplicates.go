@@ -0,0 +1,178 @@
+package goparquet
+
+import (
+	"math/bits"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// DuplicateReport describes how many duplicate values a column contains and estimates how
+// large its values would be with different encodings, to help choose the layout of a file
+// before rewriting it. The sizes are estimates of the encoded values without the levels, page
+// headers and compression.
+type DuplicateReport struct {
+	// Column is the flat name of the column.
+	Column string
+	// Values is the number of non-null values.
+	Values int64
+	// Nulls is the number of null values.
+	Nulls int64
+	// DistinctValues is the number of distinct non-null values in the whole file.
+	DistinctValues int64
+	// PlainSize is the size of the values with PLAIN encoding.
+	PlainSize int64
+	// DictionarySize is the size of the values with dictionary encoding, i.e. a dictionary of the
+	// distinct values per row group and a bit-packed index per value.
+	DictionarySize int64
+	// SortedDictionarySize is the size of the values with dictionary encoding if the rows of each
+	// row group were sorted by the column, so that the indices could be stored as one run per
+	// distinct value.
+	SortedDictionarySize int64
+}
+
+// DuplicateRatio returns the share of the non-null values that are duplicates of another value.
+func (r *DuplicateReport) DuplicateRatio() float64 {
+	if r.Values == 0 {
+		return 0
+	}
+	return float64(r.Values-r.DistinctValues) / float64(r.Values)
+}
+
+// DictionarySavings returns the share of PlainSize that dictionary encoding would save. It is
+// negative if dictionary encoding would increase the size.
+func (r *DuplicateReport) DictionarySavings() float64 {
+	return savings(r.PlainSize, r.DictionarySize)
+}
+
+// SortedDictionarySavings returns the share of PlainSize that dictionary encoding would save if
+// the rows were sorted by the column.
+func (r *DuplicateReport) SortedDictionarySavings() float64 {
+	return savings(r.PlainSize, r.SortedDictionarySize)
+}
+
+func savings(plain, size int64) float64 {
+	if plain == 0 {
+		return 0
+	}
+	return 1 - float64(size)/float64(plain)
+}
+
+// AnalyzeDuplicates decodes all values of the columns in all row groups and reports their
+// duplicates. The names of the columns need to be provided in dotted notation. If no columns are
+// provided, all selected columns are analyzed. The current position of NextRow isn't changed.
+func (f *FileReader) AnalyzeDuplicates(columns ...string) ([]*DuplicateReport, error) {
+	var cols []*Column
+	if len(columns) == 0 {
+		for _, col := range f.Columns() {
+			if f.isSelected(col.FlatName()) {
+				cols = append(cols, col)
+			}
+		}
+	}
+	for _, name := range columns {
+		col := f.GetColumnByName(name)
+		if col == nil || !col.DataColumn() {
+			return nil, errors.Errorf("column %q not found", name)
+		}
+		cols = append(cols, col)
+	}
+
+	reports := make([]*DuplicateReport, 0, len(cols))
+	for _, col := range cols {
+		report, err := f.analyzeDuplicates(col)
+		if err != nil {
+			return nil, errors.Wrapf(err, "analyzing column %s failed", col.FlatName())
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func (f *FileReader) analyzeDuplicates(col *Column) (*DuplicateReport, error) {
+	report := &DuplicateReport{Column: col.FlatName()}
+	distinct := make(map[interface{}]bool)
+
+	for rg := range f.meta.RowGroups {
+		chunk := f.meta.RowGroups[rg].Columns[col.Index()]
+		pages, err := readChunk(f.reader, col, chunk, &f.opts, newMemoryTracker(f.opts.maxMemorySize), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			values        int64
+			dictSize      int64
+			chunkDistinct = make(map[interface{}]bool)
+		)
+		for i := range pages {
+			data := make([]interface{}, pages[i].numValues())
+			n, notNull, _, _, err := pages[i].readValues(data)
+			if err != nil {
+				return nil, err
+			}
+			report.Nulls += int64(n - notNull)
+			for _, v := range data[:notNull] {
+				size := plainValueSize(col.Element(), v)
+				report.PlainSize += size
+				values++
+
+				key := duplicateKey(v)
+				if !chunkDistinct[key] {
+					chunkDistinct[key] = true
+					dictSize += size
+				}
+				distinct[key] = true
+			}
+		}
+
+		if col.Element().GetType() == parquet.Type_BOOLEAN {
+			report.PlainSize += (values + 7) / 8
+			dictSize = (int64(len(chunkDistinct)) + 7) / 8
+		}
+
+		// the indices are bit-packed with the bit width of the largest index, or stored as
+		// RLE runs of the bit width rounded up to whole bytes with a one byte header.
+		runs := int64(len(chunkDistinct))
+		var bitWidth int64
+		if runs > 0 {
+			bitWidth = int64(bits.Len64(uint64(runs - 1)))
+		}
+		report.DictionarySize += dictSize + 1 + (values*bitWidth+7)/8
+		report.SortedDictionarySize += dictSize + 1 + runs*(1+(bitWidth+7)/8)
+		report.Values += values
+	}
+
+	report.DistinctValues = int64(len(distinct))
+	return report, nil
+}
+
+// duplicateKey returns a comparable key of the value v.
+func duplicateKey(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+// plainValueSize returns the size of the value v of the column with PLAIN encoding.
+func plainValueSize(elem *parquet.SchemaElement, v interface{}) int64 {
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN:
+		// booleans are bit-packed, so their size is calculated per column chunk.
+		return 0
+	case parquet.Type_INT32, parquet.Type_FLOAT:
+		return 4
+	case parquet.Type_INT64, parquet.Type_DOUBLE:
+		return 8
+	case parquet.Type_INT96:
+		return 12
+	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		return int64(elem.GetTypeLength())
+	}
+	if b, ok := v.([]byte); ok {
+		return 4 + int64(len(b))
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeDuplicates(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary country (STRING);
+		required boolean flag;
+	}`)
+	require.NoError(t, err)
+
+	countries := []string{"de", "fr", "de", "it"}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMaxRowGroupRows(50))
+	for i := 0; i < 100; i++ {
+		data := map[string]interface{}{"id": int64(i), "flag": i%2 == 0}
+		if i%10 != 0 {
+			data["country"] = []byte(countries[i%4])
+		}
+		require.NoError(t, w.AddData(data))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	reports, err := r.AnalyzeDuplicates()
+	require.NoError(t, err)
+	require.Len(t, reports, 3)
+
+	id := reports[0]
+	require.Equal(t, "id", id.Column)
+	require.Equal(t, int64(100), id.Values)
+	require.Equal(t, int64(100), id.DistinctValues)
+	require.Equal(t, float64(0), id.DuplicateRatio())
+	require.Equal(t, int64(800), id.PlainSize)
+	// dictionaries of 50 values and 6 bit indices in each row group.
+	require.Equal(t, int64(2*(400+1+38)), id.DictionarySize)
+	require.True(t, id.DictionarySavings() < 0)
+
+	country := reports[1]
+	require.Equal(t, int64(90), country.Values)
+	require.Equal(t, int64(10), country.Nulls)
+	require.Equal(t, int64(3), country.DistinctValues)
+	require.InDelta(t, 87.0/90, country.DuplicateRatio(), 1e-9)
+	require.Equal(t, int64(90*6), country.PlainSize)
+	// dictionaries of 3 values and 2 bit indices in each row group.
+	require.Equal(t, int64(2*(18+1+12)), country.DictionarySize)
+	require.Equal(t, int64(2*(18+1+6)), country.SortedDictionarySize)
+	require.True(t, country.SortedDictionarySavings() > country.DictionarySavings())
+
+	flag := reports[2]
+	require.Equal(t, int64(2), flag.DistinctValues)
+	require.Equal(t, int64(2*7), flag.PlainSize)
+
+	reports, err = r.AnalyzeDuplicates("country")
+	require.NoError(t, err)
+	require.Equal(t, []*DuplicateReport{country}, reports)
+
+	_, err = r.AnalyzeDuplicates("unknown")
+	require.Error(t, err)
+
+	// the position of NextRow isn't changed.
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), row["id"])
+}
@@ -0,0 +1,461 @@
+package goparquet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// DecryptionProperties contains the keys that are needed to read files that use parquet modular
+// encryption. Columns whose key isn't available can't be read, but the other columns of the file
+// can still be read.
+type DecryptionProperties struct {
+	// FooterKey is the key of the footer and of all columns that are encrypted with the footer
+	// key. Files with a plaintext footer can be read without it, but the signature of their
+	// footer is only verified if it's available.
+	FooterKey []byte
+	// ColumnKeys contains the keys of the columns that are encrypted with their own key, by the
+	// flat names of the columns.
+	ColumnKeys map[string][]byte
+	// KeyRetriever returns the key for the key meta data that the writer stored in the file. It's
+	// called for the footer and the columns whose keys weren't set explicitly.
+	KeyRetriever func(keyMetadata []byte) ([]byte, error)
+	// AADPrefix is the prefix of the additional authenticated data of the file. It needs to be
+	// set if the writer didn't store it in the file. If it's set, the file has to be encrypted
+	// with the same prefix.
+	AADPrefix []byte
+}
+
+// WithDecryption sets the keys that are used to read files that use parquet modular encryption.
+// Encrypted files are decrypted using AES-GCM, or AES-CTR for the pages of files that use the
+// AES_GCM_CTR_V1 algorithm, and the integrity of all modules that are encrypted with AES-GCM is
+// verified. Without the keys, reading an encrypted file or column fails with ErrEncryptedFile.
+func WithDecryption(props DecryptionProperties) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.decryption = &props
+	}
+}
+
+// The module types that are part of the additional authenticated data of the encrypted modules.
+const (
+	footerModule byte = iota
+	columnMetaDataModule
+	dataPageModule
+	dictionaryPageModule
+	dataPageHeaderModule
+	dictionaryPageHeaderModule
+	columnIndexModule
+	offsetIndexModule
+	bloomFilterHeaderModule
+	bloomFilterBitsetModule
+)
+
+const (
+	// moduleLengthSize is the size of the length that precedes every encrypted module.
+	moduleLengthSize = 4
+	gcmNonceSize     = 12
+	gcmTagSize       = 16
+	// footerSignatureSize is the size of the nonce and the tag that follow plaintext footers.
+	footerSignatureSize = gcmNonceSize + gcmTagSize
+)
+
+// fileDecryptor decrypts the modules of an encrypted file.
+type fileDecryptor struct {
+	props   *DecryptionProperties
+	fileAAD []byte
+	// ctr is true if the pages are encrypted with AES-CTR instead of AES-GCM.
+	ctr bool
+	// footerKey is nil if the footer key isn't available.
+	footerKey []byte
+	chunks    map[*parquet.ColumnChunk]*chunkDecryptor
+}
+
+func newFileDecryptor(algorithm *parquet.EncryptionAlgorithm, props *DecryptionProperties) (*fileDecryptor, error) {
+	if props == nil {
+		return nil, ErrEncryptedFile
+	}
+
+	var (
+		prefix, unique []byte
+		supplyPrefix   bool
+		ctr            bool
+	)
+	switch {
+	case algorithm.AES_GCM_V1 != nil:
+		prefix, unique, supplyPrefix = algorithm.AES_GCM_V1.AadPrefix, algorithm.AES_GCM_V1.AadFileUnique, algorithm.AES_GCM_V1.GetSupplyAadPrefix()
+	case algorithm.AES_GCM_CTR_V1 != nil:
+		prefix, unique, supplyPrefix = algorithm.AES_GCM_CTR_V1.AadPrefix, algorithm.AES_GCM_CTR_V1.AadFileUnique, algorithm.AES_GCM_CTR_V1.GetSupplyAadPrefix()
+		ctr = true
+	default:
+		return nil, errors.New("unsupported encryption algorithm")
+	}
+
+	if props.AADPrefix != nil {
+		if prefix != nil && !bytes.Equal(prefix, props.AADPrefix) {
+			return nil, errors.New("the AAD prefix doesn't match the AAD prefix stored in the file")
+		}
+		if prefix == nil && !supplyPrefix {
+			return nil, errors.New("an AAD prefix was set but the file was encrypted without one")
+		}
+		prefix = props.AADPrefix
+	} else if supplyPrefix {
+		return nil, errors.New("the file requires an AAD prefix but none was set")
+	}
+
+	return &fileDecryptor{
+		props:   props,
+		fileAAD: append(append([]byte{}, prefix...), unique...),
+		ctr:     ctr,
+		chunks:  make(map[*parquet.ColumnChunk]*chunkDecryptor),
+	}, nil
+}
+
+func (d *fileDecryptor) key(explicit, keyMetadata []byte) ([]byte, error) {
+	if explicit != nil {
+		return explicit, nil
+	}
+	if d.props.KeyRetriever == nil {
+		return nil, ErrEncryptedFile
+	}
+	key, err := d.props.KeyRetriever(keyMetadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving key failed")
+	}
+	return key, nil
+}
+
+// setFooterKey retrieves the footer key for the key meta data of the footer.
+func (d *fileDecryptor) setFooterKey(keyMetadata []byte) error {
+	key, err := d.key(d.props.FooterKey, keyMetadata)
+	if err != nil {
+		return err
+	}
+	if _, err := aes.NewCipher(key); err != nil {
+		return errors.Wrap(err, "invalid footer key")
+	}
+	d.footerKey = key
+	return nil
+}
+
+func (d *fileDecryptor) aad(module byte, rowGroup, column, page int16) []byte {
+	aad := append(append(make([]byte, 0, len(d.fileAAD)+7), d.fileAAD...), module)
+	if module == footerModule {
+		return aad
+	}
+	aad = appendInt16(aad, rowGroup)
+	aad = appendInt16(aad, column)
+	if module == dataPageModule || module == dataPageHeaderModule {
+		aad = appendInt16(aad, page)
+	}
+	return aad
+}
+
+func appendInt16(b []byte, v int16) []byte {
+	return append(b, byte(v), byte(uint16(v)>>8))
+}
+
+// decryptFooter decrypts the footer of a file with an encrypted footer, which consists of the
+// encrypted module of the footer.
+func (d *fileDecryptor) decryptFooter(data []byte) ([]byte, error) {
+	if d.footerKey == nil {
+		return nil, ErrEncryptedFile
+	}
+	return decryptGCM(d.footerKey, data, d.aad(footerModule, 0, 0, 0))
+}
+
+// verifyFooterSignature verifies the signature that follows the plaintext footer.
+func (d *fileDecryptor) verifyFooterSignature(footer, signature []byte) error {
+	gcm, err := newGCM(d.footerKey)
+	if err != nil {
+		return err
+	}
+	sealed := gcm.Seal(nil, signature[:gcmNonceSize], footer, d.aad(footerModule, 0, 0, 0))
+	if subtle.ConstantTimeCompare(sealed[len(sealed)-gcmTagSize:], signature[gcmNonceSize:]) != 1 {
+		return errors.New("the signature of the footer doesn't match")
+	}
+	return nil
+}
+
+// decryptColumns prepares the decryption of the encrypted column chunks of meta, and replaces
+// the meta data of the chunks whose meta data is encrypted by the decrypted meta data. The meta
+// data of chunks whose key isn't available is left as it is.
+func (d *fileDecryptor) decryptColumns(meta *parquet.FileMetaData) error {
+	for rgIdx, rg := range meta.RowGroups {
+		// writers store the ordinal of the row group in encrypted files.
+		rowGroup := int16(rgIdx)
+		if rg.Ordinal != nil {
+			rowGroup = *rg.Ordinal
+		}
+
+		for colIdx, chunk := range rg.Columns {
+			if chunk.CryptoMetadata == nil {
+				continue
+			}
+			if (rg.Ordinal == nil && rgIdx > math.MaxInt16) || colIdx > math.MaxInt16 {
+				return errors.Errorf("row group %d column %d exceeds the maximum number of encrypted row groups or columns", rgIdx, colIdx)
+			}
+
+			cd := &chunkDecryptor{file: d, rowGroup: rowGroup, column: int16(colIdx)}
+			d.chunks[chunk] = cd
+
+			var key []byte
+			switch crypto := chunk.CryptoMetadata; {
+			case crypto.ENCRYPTION_WITH_FOOTER_KEY != nil:
+				if key = d.footerKey; key == nil {
+					cd.err = ErrEncryptedFile
+				}
+			case crypto.ENCRYPTION_WITH_COLUMN_KEY != nil:
+				path := strings.Join(crypto.ENCRYPTION_WITH_COLUMN_KEY.PathInSchema, ".")
+				key, cd.err = d.key(d.props.ColumnKeys[path], crypto.ENCRYPTION_WITH_COLUMN_KEY.KeyMetadata)
+			default:
+				return errors.Errorf("row group %d column %d: unsupported column encryption", rgIdx, colIdx)
+			}
+			if cd.err != nil {
+				continue
+			}
+
+			if cd.gcm, cd.err = newGCM(key); cd.err != nil {
+				return errors.Wrapf(cd.err, "row group %d column %d: invalid key", rgIdx, colIdx)
+			}
+			cd.block, _ = aes.NewCipher(key)
+
+			if chunk.EncryptedColumnMetadata == nil {
+				continue
+			}
+			data, err := cd.decryptModule(chunk.EncryptedColumnMetadata, columnMetaDataModule, 0)
+			if err != nil {
+				return errors.Wrapf(err, "row group %d column %d: decrypting column meta data failed", rgIdx, colIdx)
+			}
+			chunk.MetaData = &parquet.ColumnMetaData{}
+			if err := readThrift(chunk.MetaData, bytes.NewReader(data)); err != nil {
+				return errors.Wrapf(err, "row group %d column %d: reading column meta data failed", rgIdx, colIdx)
+			}
+		}
+	}
+
+	return nil
+}
+
+// chunk returns the decryptor of the column chunk, or nil if the chunk isn't encrypted. It
+// returns an error wrapping ErrEncryptedFile if the chunk is encrypted but can't be decrypted.
+func (d *fileDecryptor) chunk(col *Column, chunk *parquet.ColumnChunk) (*chunkDecryptor, error) {
+	if chunk.CryptoMetadata == nil {
+		return nil, nil
+	}
+	var cd *chunkDecryptor
+	if d != nil {
+		cd = d.chunks[chunk]
+	}
+	if cd == nil {
+		return nil, errors.Wrapf(ErrEncryptedFile, "column %s", col.FlatName())
+	}
+	if cd.err != nil {
+		return nil, errors.Wrapf(cd.err, "column %s", col.FlatName())
+	}
+	return cd, nil
+}
+
+// chunkDecryptor decrypts the modules of a column chunk.
+type chunkDecryptor struct {
+	file     *fileDecryptor
+	gcm      cipher.AEAD
+	block    cipher.Block
+	rowGroup int16
+	column   int16
+	// err is set if the chunk can't be decrypted, e.g. because its key isn't available.
+	err error
+}
+
+func (cd *chunkDecryptor) decryptModule(data []byte, module byte, page int16) ([]byte, error) {
+	return openGCM(cd.gcm, data, cd.file.aad(module, cd.rowGroup, cd.column, page))
+}
+
+// readModule reads the encrypted module of the type module from r and decrypts it. max is the
+// maximum size of the module.
+func (cd *chunkDecryptor) readModule(r io.Reader, max int64, module byte, page int16) ([]byte, error) {
+	data, err := readModule(r, max)
+	if err != nil {
+		return nil, err
+	}
+	return cd.decryptModule(data, module, page)
+}
+
+// pages returns a decryptor for the pages of the chunk.
+func (cd *chunkDecryptor) pages(meta *parquet.ColumnMetaData) *pageDecryptor {
+	return &pageDecryptor{chunk: cd, dictionary: meta.DictionaryPageOffset != nil}
+}
+
+// pageDecryptor decrypts the page headers and pages of a column chunk in the order in which
+// they are stored.
+type pageDecryptor struct {
+	chunk *chunkDecryptor
+	// dictionary is true as long as the next page is the dictionary page.
+	dictionary bool
+	// page is the ordinal of the next data page.
+	page int
+}
+
+// readPageHeader reads and decrypts the header of the next page from r, whose size is at most
+// max.
+func (pd *pageDecryptor) readPageHeader(r io.Reader, max int64) (*parquet.PageHeader, error) {
+	if pd.page > math.MaxInt16 {
+		return nil, errors.Errorf("page %d exceeds the maximum number of encrypted pages", pd.page)
+	}
+
+	module := dataPageHeaderModule
+	if pd.dictionary {
+		module = dictionaryPageHeaderModule
+	}
+	data, err := pd.chunk.readModule(r, max, module, int16(pd.page))
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting page header failed")
+	}
+
+	ph := &parquet.PageHeader{}
+	if err := readThrift(ph, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ph, nil
+}
+
+// decryptPage decrypts the data of the page with the header ph and returns the decrypted data
+// and a copy of the header whose compressed size is the size of the decrypted data. Pages that
+// are neither dictionary nor data pages are returned as they are.
+func (pd *pageDecryptor) decryptPage(data []byte, ph *parquet.PageHeader) ([]byte, *parquet.PageHeader, error) {
+	var module byte
+	switch ph.Type {
+	case parquet.PageType_DICTIONARY_PAGE:
+		module = dictionaryPageModule
+	case parquet.PageType_DATA_PAGE, parquet.PageType_DATA_PAGE_V2:
+		module = dataPageModule
+	default:
+		pd.dictionary = false
+		return data, ph, nil
+	}
+
+	// the ordinal is advanced even if the page can't be decrypted, so that the following pages
+	// can still be decrypted.
+	page := int16(pd.page)
+	if module == dataPageModule {
+		pd.page++
+	}
+	pd.dictionary = false
+
+	var (
+		plain []byte
+		err   error
+	)
+	if pd.chunk.file.ctr {
+		plain, err = decryptCTR(pd.chunk.block, data)
+	} else {
+		plain, err = pd.chunk.decryptModule(data, module, page)
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "decrypting page failed")
+	}
+
+	header := *ph
+	header.CompressedPageSize = int32(len(plain))
+	return plain, &header, nil
+}
+
+// decryptPageData reads the encrypted data of the page with the header ph from r and decrypts it.
+// It returns a reader for the decrypted data and the header that describes it.
+func decryptPageData(r io.Reader, ph *parquet.PageHeader, pd *pageDecryptor) (io.Reader, *parquet.PageHeader, error) {
+	if ph.CompressedPageSize < 0 {
+		return nil, nil, errors.New("invalid page data size")
+	}
+
+	data := make([]byte, ph.CompressedPageSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, nil, errors.Wrap(err, "reading page data failed")
+	}
+
+	plain, header, err := pd.decryptPage(data, ph)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bytes.NewReader(plain), header, nil
+}
+
+// readModule reads an encrypted module, which is preceded by its length, from r.
+func readModule(r io.Reader, max int64) ([]byte, error) {
+	var buf [moduleLengthSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, errors.Wrap(err, "reading module length failed")
+	}
+	length := int64(binary.LittleEndian.Uint32(buf[:]))
+	if length+moduleLengthSize > max {
+		return nil, errors.Errorf("module of %d bytes exceeds the remaining %d bytes", length, max-moduleLengthSize)
+	}
+
+	data := make([]byte, moduleLengthSize+length)
+	copy(data, buf[:])
+	if _, err := io.ReadFull(r, data[moduleLengthSize:]); err != nil {
+		return nil, errors.Wrap(err, "reading module failed")
+	}
+	return data, nil
+}
+
+// moduleData returns the data of the encrypted module, i.e. the nonce followed by the ciphertext
+// and the tag, after checking its length.
+func moduleData(module []byte, min int) ([]byte, error) {
+	if len(module) < moduleLengthSize+min {
+		return nil, errors.Errorf("encrypted module of %d bytes is too short", len(module))
+	}
+	if length := binary.LittleEndian.Uint32(module); int64(length) != int64(len(module)-moduleLengthSize) {
+		return nil, errors.Errorf("encrypted module has a length of %d bytes but contains %d bytes", length, len(module)-moduleLengthSize)
+	}
+	return module[moduleLengthSize:], nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func decryptGCM(key, module, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return openGCM(gcm, module, aad)
+}
+
+func openGCM(gcm cipher.AEAD, module, aad []byte) ([]byte, error) {
+	data, err := moduleData(module, gcmNonceSize+gcmTagSize)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, data[:gcmNonceSize], data[gcmNonceSize:], aad)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting module failed")
+	}
+	return plain, nil
+}
+
+func decryptCTR(block cipher.Block, module []byte) ([]byte, error) {
+	data, err := moduleData(module, gcmNonceSize)
+	if err != nil {
+		return nil, err
+	}
+	// the counter starts at 1 and follows the nonce.
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, data[:gcmNonceSize])
+	iv[aes.BlockSize-1] = 1
+
+	plain := make([]byte, len(data)-gcmNonceSize)
+	cipher.NewCTR(block, iv).XORKeyStream(plain, data[gcmNonceSize:])
+	return plain, nil
+}
@@ -0,0 +1,477 @@
+package goparquet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testFooterKey = []byte("0123456789012345")
+	testColumnKey = []byte("1234567890123450")
+)
+
+// testEncryption describes how encryptTestFile encrypts a file.
+type testEncryption struct {
+	// columnKeys contains the columns that are encrypted with their own key. All other columns
+	// except the plaintext columns are encrypted with the footer key.
+	columnKeys map[string][]byte
+	plaintext  map[string]bool
+
+	plaintextFooter bool
+	ctr             bool
+	aadPrefix       []byte
+	storeAADPrefix  bool
+}
+
+type testEncryptor struct {
+	enc     testEncryption
+	fileAAD []byte
+	nonce   byte
+}
+
+func (e *testEncryptor) aad(module byte, rowGroup, column, page int) []byte {
+	d := &fileDecryptor{fileAAD: e.fileAAD}
+	return d.aad(module, int16(rowGroup), int16(column), int16(page))
+}
+
+func (e *testEncryptor) nextNonce() []byte {
+	e.nonce++
+	nonce := make([]byte, gcmNonceSize)
+	nonce[0] = e.nonce
+	return nonce
+}
+
+func (e *testEncryptor) gcm(t *testing.T, key, data, aad []byte) []byte {
+	gcm, err := newGCM(key)
+	require.NoError(t, err)
+	nonce := e.nextNonce()
+	sealed := gcm.Seal(nil, nonce, data, aad)
+	return e.module(append(nonce, sealed...))
+}
+
+func (e *testEncryptor) ctr(t *testing.T, key, data []byte) []byte {
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	nonce := e.nextNonce()
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, nonce)
+	iv[aes.BlockSize-1] = 1
+	ciphertext := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(ciphertext, data)
+	return e.module(append(nonce, ciphertext...))
+}
+
+func (e *testEncryptor) module(data []byte) []byte {
+	module := make([]byte, moduleLengthSize, moduleLengthSize+len(data))
+	binary.LittleEndian.PutUint32(module, uint32(len(data)))
+	return append(module, data...)
+}
+
+func serializeThrift(t *testing.T, tr thriftWriter) []byte {
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeThrift(tr, buf))
+	return buf.Bytes()
+}
+
+// encryptTestFile encrypts the file data that was written without encryption the way writers of
+// parquet modular encryption do. The meta data of the key of a column is the column name, the
+// meta data of the footer key is "footer".
+func encryptTestFile(t *testing.T, data []byte, enc testEncryption) []byte {
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	// the page indexes and Bloom filters are read from another reader, as the meta data of r is
+	// changed.
+	src, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	e := &testEncryptor{enc: enc, fileAAD: append(append([]byte{}, enc.aadPrefix...), "unique"...)}
+	algorithm := &parquet.EncryptionAlgorithm{}
+	supplyPrefix := enc.aadPrefix != nil && !enc.storeAADPrefix
+	var storedPrefix []byte
+	if enc.storeAADPrefix {
+		storedPrefix = enc.aadPrefix
+	}
+	if enc.ctr {
+		algorithm.AES_GCM_CTR_V1 = &parquet.AesGcmCtrV1{AadPrefix: storedPrefix, AadFileUnique: []byte("unique"), SupplyAadPrefix: &supplyPrefix}
+	} else {
+		algorithm.AES_GCM_V1 = &parquet.AesGcmV1{AadPrefix: storedPrefix, AadFileUnique: []byte("unique"), SupplyAadPrefix: &supplyPrefix}
+	}
+
+	out := &bytes.Buffer{}
+	if enc.plaintextFooter {
+		out.Write(magic)
+	} else {
+		out.Write(encryptedMagic)
+	}
+
+	meta := r.meta
+	keys := make(map[*parquet.ColumnChunk][]byte)
+	// pageLocations maps the offsets of the pages to their new offsets and sizes.
+	pageLocations := make(map[int64]*parquet.PageLocation)
+	for rgIdx, rg := range meta.RowGroups {
+		ordinal := int16(rgIdx)
+		rg.Ordinal = &ordinal
+
+		for colIdx, chunk := range rg.Columns {
+			path := strings.Join(chunk.MetaData.PathInSchema, ".")
+			cm := chunk.MetaData
+			start := cm.DataPageOffset
+			if cm.DictionaryPageOffset != nil {
+				start = *cm.DictionaryPageOffset
+			}
+			raw := data[start : start+cm.TotalCompressedSize]
+			newStart := int64(out.Len())
+
+			if enc.plaintext[path] {
+				out.Write(raw)
+				delta := newStart - start
+				cm.DataPageOffset += delta
+				if cm.DictionaryPageOffset != nil {
+					*cm.DictionaryPageOffset += delta
+				}
+				continue
+			}
+
+			key := testFooterKey
+			chunk.CryptoMetadata = &parquet.ColumnCryptoMetaData{ENCRYPTION_WITH_FOOTER_KEY: &parquet.EncryptionWithFooterKey{}}
+			if columnKey, ok := enc.columnKeys[path]; ok {
+				key = columnKey
+				chunk.CryptoMetadata = &parquet.ColumnCryptoMetaData{ENCRYPTION_WITH_COLUMN_KEY: &parquet.EncryptionWithColumnKey{
+					PathInSchema: chunk.MetaData.PathInSchema,
+					KeyMetadata:  []byte(path),
+				}}
+			}
+			keys[chunk] = key
+
+			pages := &offsetReader{inner: bytes.NewReader(raw)}
+			for page := 0; pages.Count() < int64(len(raw)); {
+				offset := start + pages.Count()
+				ph := &parquet.PageHeader{}
+				require.NoError(t, readThrift(ph, pages))
+				pageData := make([]byte, ph.CompressedPageSize)
+				_, err := io.ReadFull(pages, pageData)
+				require.NoError(t, err)
+
+				dict := ph.Type == parquet.PageType_DICTIONARY_PAGE
+				var encrypted []byte
+				switch {
+				case enc.ctr:
+					encrypted = e.ctr(t, key, pageData)
+				case dict:
+					encrypted = e.gcm(t, key, pageData, e.aad(dictionaryPageModule, rgIdx, colIdx, 0))
+				default:
+					encrypted = e.gcm(t, key, pageData, e.aad(dataPageModule, rgIdx, colIdx, page))
+				}
+
+				ph.CompressedPageSize = int32(len(encrypted))
+				ph.Crc = nil
+				headerAAD := e.aad(dataPageHeaderModule, rgIdx, colIdx, page)
+				if dict {
+					headerAAD = e.aad(dictionaryPageHeaderModule, rgIdx, colIdx, 0)
+				}
+				header := e.gcm(t, key, serializeThrift(t, ph), headerAAD)
+
+				newOffset := int64(out.Len())
+				if dict {
+					cm.DictionaryPageOffset = &newOffset
+				} else {
+					if page == 0 {
+						cm.DataPageOffset = newOffset
+					}
+					page++
+				}
+				out.Write(header)
+				out.Write(encrypted)
+				pageLocations[offset] = &parquet.PageLocation{Offset: newOffset, CompressedPageSize: int32(len(header) + len(encrypted))}
+			}
+			cm.TotalCompressedSize = int64(out.Len()) - newStart
+		}
+	}
+
+	// the page indexes and Bloom filters are written after all row groups.
+	for rgIdx, rg := range meta.RowGroups {
+		for colIdx, chunk := range rg.Columns {
+			key, ok := keys[chunk]
+			if !ok {
+				chunk.ColumnIndexOffset, chunk.ColumnIndexLength = nil, nil
+				chunk.OffsetIndexOffset, chunk.OffsetIndexLength = nil, nil
+				chunk.MetaData.BloomFilterOffset, chunk.MetaData.BloomFilterLength = nil, nil
+				continue
+			}
+			path := strings.Join(chunk.MetaData.PathInSchema, ".")
+
+			columnIndex, offsetIndex, err := src.PageIndex(rgIdx, path)
+			require.NoError(t, err)
+			if columnIndex != nil {
+				module := e.gcm(t, key, serializeThrift(t, columnIndex), e.aad(columnIndexModule, rgIdx, colIdx, 0))
+				offset, length := int64(out.Len()), int32(len(module))
+				chunk.ColumnIndexOffset, chunk.ColumnIndexLength = &offset, &length
+				out.Write(module)
+			}
+			if offsetIndex != nil {
+				for i, loc := range offsetIndex.PageLocations {
+					offsetIndex.PageLocations[i] = &parquet.PageLocation{
+						Offset:             pageLocations[loc.Offset].Offset,
+						CompressedPageSize: pageLocations[loc.Offset].CompressedPageSize,
+						FirstRowIndex:      loc.FirstRowIndex,
+					}
+				}
+				module := e.gcm(t, key, serializeThrift(t, offsetIndex), e.aad(offsetIndexModule, rgIdx, colIdx, 0))
+				offset, length := int64(out.Len()), int32(len(module))
+				chunk.OffsetIndexOffset, chunk.OffsetIndexLength = &offset, &length
+				out.Write(module)
+			}
+
+			bf, err := src.ReadBloomFilter(rgIdx, path)
+			require.NoError(t, err)
+			if bf != nil {
+				header := e.gcm(t, key, serializeThrift(t, bf.Header), e.aad(bloomFilterHeaderModule, rgIdx, colIdx, 0))
+				bitset := e.gcm(t, key, bf.Bitset, e.aad(bloomFilterBitsetModule, rgIdx, colIdx, 0))
+				offset, length := int64(out.Len()), int32(len(header)+len(bitset))
+				chunk.MetaData.BloomFilterOffset, chunk.MetaData.BloomFilterLength = &offset, &length
+				out.Write(header)
+				out.Write(bitset)
+			}
+		}
+	}
+
+	// the column meta data is encrypted once all offsets are known.
+	for rgIdx, rg := range meta.RowGroups {
+		for colIdx, chunk := range rg.Columns {
+			key, ok := keys[chunk]
+			if !ok {
+				continue
+			}
+			_, columnKey := enc.columnKeys[strings.Join(chunk.MetaData.PathInSchema, ".")]
+			if !columnKey && !enc.plaintextFooter {
+				continue
+			}
+			chunk.EncryptedColumnMetadata = e.gcm(t, key, serializeThrift(t, chunk.MetaData), e.aad(columnMetaDataModule, rgIdx, colIdx, 0))
+			if enc.plaintextFooter {
+				stripped := *chunk.MetaData
+				stripped.Statistics = nil
+				chunk.MetaData = &stripped
+			} else {
+				chunk.MetaData = nil
+			}
+		}
+	}
+
+	footerStart := out.Len()
+	if enc.plaintextFooter {
+		meta.EncryptionAlgorithm = algorithm
+		meta.FooterSigningKeyMetadata = []byte("footer")
+		footer := serializeThrift(t, meta)
+		gcm, err := newGCM(testFooterKey)
+		require.NoError(t, err)
+		nonce := e.nextNonce()
+		sealed := gcm.Seal(nil, nonce, footer, e.aad(footerModule, 0, 0, 0))
+		out.Write(footer)
+		out.Write(nonce)
+		out.Write(sealed[len(sealed)-gcmTagSize:])
+	} else {
+		out.Write(serializeThrift(t, &parquet.FileCryptoMetaData{EncryptionAlgorithm: algorithm, KeyMetadata: []byte("footer")}))
+		out.Write(e.gcm(t, testFooterKey, serializeThrift(t, meta), e.aad(footerModule, 0, 0, 0)))
+	}
+	require.NoError(t, binary.Write(out, binary.LittleEndian, int32(out.Len()-footerStart)))
+	if enc.plaintextFooter {
+		out.Write(magic)
+	} else {
+		out.Write(encryptedMagic)
+	}
+
+	return out.Bytes()
+}
+
+func testKeyRetriever(keyMetadata []byte) ([]byte, error) {
+	switch string(keyMetadata) {
+	case "footer":
+		return testFooterKey, nil
+	case "name":
+		return testColumnKey, nil
+	}
+	return nil, errors.Errorf("unknown key %q", keyMetadata)
+}
+
+func readTestRows(t *testing.T, data []byte, opts ...FileReaderOption) []map[string]interface{} {
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), opts...)
+	require.NoError(t, err)
+
+	var rows []map[string]interface{}
+	for {
+		row, err := r.NextRow()
+		if err == io.EOF {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestDecryption(t *testing.T) {
+	plain := writeValidateTestFile(t,
+		WithPageValueLimits(1, 30),
+		WithPageIndex(),
+		WithBloomFilter("id", 0.01),
+	)
+	expected := readTestRows(t, plain)
+
+	src, err := NewFileReader(bytes.NewReader(plain))
+	require.NoError(t, err)
+	require.NotNil(t, src.meta.RowGroups[0].Columns[1].MetaData.DictionaryPageOffset)
+
+	tests := map[string]testEncryption{
+		"gcm_encrypted_footer": {
+			columnKeys: map[string][]byte{"name": testColumnKey},
+		},
+		"ctr_encrypted_footer": {
+			columnKeys: map[string][]byte{"name": testColumnKey},
+			ctr:        true,
+		},
+		"gcm_plaintext_footer": {
+			columnKeys:      map[string][]byte{"name": testColumnKey},
+			plaintext:       map[string]bool{"tags": true},
+			plaintextFooter: true,
+		},
+		"ctr_plaintext_footer_aad_prefix": {
+			columnKeys:      map[string][]byte{"name": testColumnKey},
+			plaintextFooter: true,
+			ctr:             true,
+			aadPrefix:       []byte("tester"),
+		},
+		"gcm_stored_aad_prefix": {
+			aadPrefix:      []byte("tester"),
+			storeAADPrefix: true,
+		},
+	}
+
+	for name, enc := range tests {
+		enc := enc
+		t.Run(name, func(t *testing.T) {
+			data := encryptTestFile(t, plain, enc)
+
+			explicit := DecryptionProperties{FooterKey: testFooterKey, ColumnKeys: map[string][]byte{"name": testColumnKey}}
+			retrieved := DecryptionProperties{KeyRetriever: testKeyRetriever}
+			if !enc.storeAADPrefix {
+				explicit.AADPrefix, retrieved.AADPrefix = enc.aadPrefix, enc.aadPrefix
+			}
+
+			require.Equal(t, expected, readTestRows(t, data, WithDecryption(explicit)))
+			require.Equal(t, expected, readTestRows(t, data, WithDecryption(retrieved)))
+
+			report, err := CheckConformance(bytes.NewReader(data), WithDecryption(explicit))
+			require.NoError(t, err)
+			require.True(t, report.Valid(), "%v", report.Problems)
+
+			r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(explicit))
+			require.NoError(t, err)
+			for rg := 0; rg < r.RowGroupCount(); rg++ {
+				ci, oi, err := r.PageIndex(rg, "id")
+				require.NoError(t, err)
+				srcCI, srcOI, err := src.PageIndex(rg, "id")
+				require.NoError(t, err)
+				require.Equal(t, srcCI, ci)
+				require.Equal(t, len(srcOI.PageLocations), len(oi.PageLocations))
+
+				bf, err := r.ReadBloomFilter(rg, "id")
+				require.NoError(t, err)
+				srcBF, err := src.ReadBloomFilter(rg, "id")
+				require.NoError(t, err)
+				require.Equal(t, srcBF, bf)
+			}
+
+			_, err = NewFileReader(bytes.NewReader(data))
+			if !enc.plaintextFooter {
+				require.Equal(t, ErrEncryptedFile, errors.Cause(err))
+				return
+			}
+			// without keys, only the columns that aren't encrypted can be read.
+			require.NoError(t, err)
+			if enc.plaintext["tags"] {
+				rows := readTestRows(t, data, WithColumns("tags"))
+				require.Len(t, rows, len(expected))
+				require.Equal(t, expected[1]["tags"], rows[1]["tags"])
+			}
+			r, err = NewFileReader(bytes.NewReader(data))
+			require.NoError(t, err)
+			_, err = r.NextRow()
+			require.Equal(t, ErrEncryptedFile, errors.Cause(err))
+		})
+	}
+}
+
+func TestDecryptionErrors(t *testing.T) {
+	plain := writeValidateTestFile(t)
+	enc := testEncryption{columnKeys: map[string][]byte{"name": testColumnKey}, aadPrefix: []byte("tester")}
+	data := encryptTestFile(t, plain, enc)
+	keys := DecryptionProperties{FooterKey: testFooterKey, ColumnKeys: map[string][]byte{"name": testColumnKey}, AADPrefix: []byte("tester")}
+
+	// the columns whose key is available can be read without the other keys.
+	withoutColumnKey := keys
+	withoutColumnKey.ColumnKeys = nil
+	rows := readTestRows(t, data, WithDecryption(withoutColumnKey), WithColumns("id", "tags"))
+	require.Len(t, rows, 300)
+	require.NotContains(t, rows[1], "name")
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(withoutColumnKey))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Equal(t, ErrEncryptedFile, errors.Cause(err))
+
+	wrongKey := keys
+	wrongKey.ColumnKeys = map[string][]byte{"name": testFooterKey}
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(wrongKey))
+	require.Error(t, err)
+	require.Nil(t, r)
+
+	wrongPrefix := keys
+	wrongPrefix.AADPrefix = []byte("other")
+	_, err = NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(wrongPrefix))
+	require.Error(t, err)
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(encryptTestFile(t, plain, testEncryption{})), WithDecryption(keys))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "encrypted without one")
+
+	noPrefix := keys
+	noPrefix.AADPrefix = nil
+	_, err = NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(noPrefix))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "requires an AAD prefix")
+
+	// modified data fails the authentication of its module.
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(keys))
+	require.NoError(t, err)
+	modified := append([]byte{}, data...)
+	modified[r.meta.RowGroups[0].Columns[0].MetaData.DataPageOffset+10] ^= 0xff
+	r, err = NewFileReaderWithOptions(bytes.NewReader(modified), WithDecryption(keys))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "message authentication failed")
+
+	// the signature of plaintext footers is verified if the footer key is available.
+	enc.plaintextFooter = true
+	data = encryptTestFile(t, plain, enc)
+	_, err = NewFileReaderWithOptions(bytes.NewReader(data), WithDecryption(keys))
+	require.NoError(t, err)
+
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	modified = append([]byte{}, data...)
+	created := bytes.Index(modified, []byte(r.meta.GetCreatedBy()))
+	require.True(t, created > 0)
+	modified[created] ^= 0x01
+	_, err = NewFileReaderWithOptions(bytes.NewReader(modified), WithDecryption(keys))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signature of the footer doesn't match")
+}
@@ -3,60 +3,284 @@ package goparquet
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 
+	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/fraugster/parquet-go/parquet"
 	"github.com/pkg/errors"
 )
 
 var magic = []byte{'P', 'A', 'R', '1'}
 
-func readFileMetaData(r io.ReadSeeker) (*parquet.FileMetaData, error) {
+// encryptedMagic is the magic of files with an encrypted footer.
+var encryptedMagic = []byte{'P', 'A', 'R', 'E'}
+
+// ErrEncryptedFile is returned when a file with an encrypted footer or an encrypted column chunk
+// is read without the key that is needed to decrypt it, see WithDecryption.
+var ErrEncryptedFile = errors.New("missing key to decrypt the encrypted parquet file")
+
+// readFileMetaData reads the meta data footer of the file. For encrypted files, it returns the
+// decryptor of the file, whose column chunks are prepared for decryption.
+func readFileMetaData(r io.ReadSeeker, limits FooterLimits, decryption *DecryptionProperties) (*parquet.FileMetaData, *fileDecryptor, error) {
 	if _, err := r.Seek(0, io.SeekStart); err != nil {
-		return nil, errors.Wrap(err, "seek for the file magic header failed")
+		return nil, nil, errors.Wrap(err, "seek for the file magic header failed")
 	}
 
-	buf := make([]byte, 4)
+	header := make([]byte, 4)
 	// read and validate header
-	if _, err := io.ReadFull(r, buf); err != nil {
-		return nil, errors.Wrap(err, "read the file magic header failed")
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, errors.Wrap(err, "read the file magic header failed")
 	}
-	if !bytes.Equal(buf, magic) {
-		return nil, errors.Errorf("invalid parquet file header")
+	if !bytes.Equal(header, magic) && !bytes.Equal(header, encryptedMagic) {
+		return nil, nil, errors.Errorf("invalid parquet file header")
 	}
 
 	// read and validate footer
-	if _, err := r.Seek(-4, io.SeekEnd); err != nil {
-		return nil, errors.Wrap(err, "seek for the file magic footer failed")
+	size, err := r.Seek(-4, io.SeekEnd)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "seek for the file magic footer failed")
 	}
 
-	if _, err := io.ReadFull(r, buf); err != nil {
-		return nil, errors.Wrap(err, "read the file magic header failed")
+	footer := make([]byte, 4)
+	if _, err := io.ReadFull(r, footer); err != nil {
+		return nil, nil, errors.Wrap(err, "read the file magic header failed")
 	}
-	if !bytes.Equal(buf, magic) {
-		return nil, errors.Errorf("invalid parquet file footer")
+	if !bytes.Equal(footer, header) {
+		return nil, nil, errors.Errorf("invalid parquet file footer")
 	}
 
 	// read footer length
 	if _, err := r.Seek(-8, io.SeekEnd); err != nil {
-		return nil, errors.Wrap(err, "seek for the footer len failed")
+		return nil, nil, errors.Wrap(err, "seek for the footer len failed")
 	}
 	var fl int32
 	if err := binary.Read(r, binary.LittleEndian, &fl); err != nil {
-		return nil, errors.Wrap(err, "read the footer len failed")
+		return nil, nil, errors.Wrap(err, "read the footer len failed")
 	}
 	if fl <= 0 {
-		return nil, errors.Errorf("invalid footer len %d", fl)
+		return nil, nil, errors.Errorf("invalid footer len %d", fl)
+	}
+	if limits.MaxFooterSize > 0 && int64(fl) > limits.MaxFooterSize {
+		return nil, nil, &FooterLimitExceededError{Limit: "footer size", Max: limits.MaxFooterSize, Actual: int64(fl)}
 	}
 
 	// read file metadata
 	if _, err := r.Seek(-8-int64(fl), io.SeekEnd); err != nil {
-		return nil, errors.Wrap(err, "seek file meta data failed")
+		return nil, nil, errors.Wrap(err, "seek file meta data failed")
+	}
+
+	if bytes.Equal(footer, encryptedMagic) {
+		// the footer consists of the crypto meta data followed by the encrypted meta data.
+		if int64(fl) > size-8 {
+			return nil, nil, errors.Errorf("invalid footer len %d", fl)
+		}
+		data := make([]byte, fl)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, nil, errors.Wrap(err, "read file meta data failed")
+		}
+		return readEncryptedFooter(data, limits, decryption)
+	}
+
+	meta := &parquet.FileMetaData{}
+	if err := readFooterStruct(meta, r, int64(fl), limits); err != nil {
+		return nil, nil, errors.Wrap(err, "read file meta failed")
+	}
+
+	if meta.EncryptionAlgorithm == nil {
+		return meta, nil, nil
+	}
+
+	// files with a plaintext footer may contain encrypted columns. Without decryption
+	// properties, only the columns that aren't encrypted can be read.
+	if decryption == nil {
+		return meta, nil, nil
+	}
+	dec, err := newFileDecryptor(meta.EncryptionAlgorithm, decryption)
+	if err != nil {
+		return nil, nil, err
+	}
+	if decryption.FooterKey != nil || decryption.KeyRetriever != nil {
+		if err := dec.setFooterKey(meta.FooterSigningKeyMetadata); err != nil {
+			return nil, nil, errors.Wrap(err, "retrieving the footer key failed")
+		}
+		if err := verifyPlaintextFooter(r, dec, fl); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := dec.decryptColumns(meta); err != nil {
+		return nil, nil, err
+	}
+
+	return meta, dec, nil
+}
+
+// readEncryptedFooter reads the footer of a file with an encrypted footer.
+func readEncryptedFooter(data []byte, limits FooterLimits, decryption *DecryptionProperties) (*parquet.FileMetaData, *fileDecryptor, error) {
+	if decryption == nil {
+		return nil, nil, ErrEncryptedFile
 	}
+
+	r := bytes.NewReader(data)
+	cryptoMeta := &parquet.FileCryptoMetaData{}
+	if err := readFooterStruct(cryptoMeta, r, int64(len(data)), limits); err != nil {
+		return nil, nil, errors.Wrap(err, "read file crypto meta data failed")
+	}
+
+	dec, err := newFileDecryptor(cryptoMeta.EncryptionAlgorithm, decryption)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := dec.setFooterKey(cryptoMeta.KeyMetadata); err != nil {
+		return nil, nil, errors.Wrap(err, "retrieving the footer key failed")
+	}
+	footer, err := dec.decryptFooter(data[len(data)-r.Len():])
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "decrypting file meta data failed")
+	}
+
 	meta := &parquet.FileMetaData{}
-	if err := readThrift(meta, io.LimitReader(r, int64(fl))); err != nil {
-		return nil, errors.Wrap(err, "read file meta failed")
+	if err := readFooterStruct(meta, bytes.NewReader(footer), int64(len(footer)), limits); err != nil {
+		return nil, nil, errors.Wrap(err, "read file meta failed")
 	}
+	if err := dec.decryptColumns(meta); err != nil {
+		return nil, nil, err
+	}
+
+	return meta, dec, nil
+}
 
-	return meta, nil
+// verifyPlaintextFooter verifies the signature of the plaintext footer of fl bytes, which
+// consists of the meta data followed by the nonce and the tag of the signature.
+func verifyPlaintextFooter(r io.ReadSeeker, dec *fileDecryptor, fl int32) error {
+	if fl < footerSignatureSize {
+		return errors.Errorf("footer of %d bytes is too short for the signature", fl)
+	}
+	if _, err := r.Seek(-8-int64(fl), io.SeekEnd); err != nil {
+		return errors.Wrap(err, "seek file meta data failed")
+	}
+	data := make([]byte, fl)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return errors.Wrap(err, "read file meta data failed")
+	}
+	split := len(data) - footerSignatureSize
+	return dec.verifyFooterSignature(data[:split], data[split:])
+}
+
+// readFooterStruct reads the thrift struct tr of at most size bytes from r while enforcing the
+// limits.
+func readFooterStruct(tr thriftReader, r io.Reader, size int64, limits FooterLimits) error {
+	lr := &io.LimitedReader{R: r, N: size}
+	proto := &limitedProtocol{
+		TCompactProtocol: thrift.NewTCompactProtocol(&thrift.StreamTransport{Reader: lr}),
+		r:                lr,
+		limits:           limits,
+	}
+	if err := tr.Read(proto); err != nil {
+		// the thrift code replaces errors by new errors with additional context, so the
+		// typed error of an exceeded limit needs to be taken from the protocol.
+		if proto.err != nil {
+			return proto.err
+		}
+		return err
+	}
+	return nil
+}
+
+// FooterLimits contains limits that are enforced when the meta data footer of a file is parsed,
+// to reject files with huge or hostile footers before the memory for them is allocated. A limit
+// of 0 means no limit. Independent of these limits, strings and containers that are larger than
+// the remaining footer data are always rejected, so that footers of any size can be parsed safely.
+type FooterLimits struct {
+	// MaxFooterSize is the maximum size of the encoded footer in bytes.
+	MaxFooterSize int64
+	// MaxStringSize is the maximum size of a single string or binary value in bytes,
+	// e.g. of statistics or key-value meta data.
+	MaxStringSize int64
+	// MaxContainerSize is the maximum number of elements of a single list, set or map,
+	// e.g. of the schema elements or row groups.
+	MaxContainerSize int64
+}
+
+// FooterLimitExceededError is returned when the meta data footer of a file exceeds one of the
+// limits configured using WithFooterLimits.
+type FooterLimitExceededError struct {
+	// Limit is the name of the limit that was exceeded.
+	Limit string
+	// Max is the configured limit.
+	Max int64
+	// Actual is the size found in the footer.
+	Actual int64
+}
+
+func (e *FooterLimitExceededError) Error() string {
+	return fmt.Sprintf("file meta data exceeds the %s limit of %d with %d", e.Limit, e.Max, e.Actual)
+}
+
+// limitedProtocol is a thrift protocol that checks the size of strings, binaries and containers
+// before they are allocated.
+type limitedProtocol struct {
+	*thrift.TCompactProtocol
+
+	// r is the reader the protocol reads from, and provides the number of remaining bytes.
+	r      *io.LimitedReader
+	limits FooterLimits
+	err    error
+}
+
+func (p *limitedProtocol) check(limit string, max int64, size int) error {
+	if int64(size) > p.r.N {
+		return errors.Errorf("%s %d exceeds the remaining %d bytes of the footer", limit, size, p.r.N)
+	}
+	if max > 0 && int64(size) > max {
+		p.err = &FooterLimitExceededError{Limit: limit, Max: max, Actual: int64(size)}
+		return p.err
+	}
+	return nil
+}
+
+func (p *limitedProtocol) ReadString() (string, error) {
+	buf, err := p.ReadBinary()
+	return string(buf), err
+}
+
+func (p *limitedProtocol) ReadBinary() ([]byte, error) {
+	// the compact protocol writes the length of strings and binaries as unsigned varint.
+	l, err := binary.ReadUvarint(&byteReader{Reader: p.r})
+	if err != nil {
+		return nil, err
+	}
+	length := int(int32(l))
+	if length < 0 {
+		return nil, errors.Errorf("invalid negative length %d", length)
+	}
+	if err := p.check("string size", p.limits.MaxStringSize, length); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(p.r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (p *limitedProtocol) ReadListBegin() (thrift.TType, int, error) {
+	elemType, size, err := p.TCompactProtocol.ReadListBegin()
+	if err != nil {
+		return elemType, size, err
+	}
+	return elemType, size, p.check("container size", p.limits.MaxContainerSize, size)
+}
+
+func (p *limitedProtocol) ReadSetBegin() (thrift.TType, int, error) {
+	return p.ReadListBegin()
+}
+
+func (p *limitedProtocol) ReadMapBegin() (thrift.TType, thrift.TType, int, error) {
+	keyType, valueType, size, err := p.TCompactProtocol.ReadMapBegin()
+	if err != nil {
+		return keyType, valueType, size, err
+	}
+	return keyType, valueType, size, p.check("container size", p.limits.MaxContainerSize, size)
 }
@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
 	"github.com/pkg/errors"
 )
 
-// FileReader is used to read data from a parquet file. Always use NewFileReader to create
-// such an object.
+// FileReader is used to read data from a parquet file. Always use NewFileReader or
+// NewFileReaderWithOptions to create such an object.
 type FileReader struct {
 	meta *parquet.FileMetaData
 	SchemaReader
@@ -19,41 +21,508 @@ type FileReader struct {
 	rowGroupPosition int
 	currentRecord    int64
 	skipRowGroup     bool
+	rowGroupStats    map[string]interface{}
+	skippedRows      []SkippedRows
+
+	valueCountMismatches []ValueCountMismatch
+
+	// writerVersion is the parsed created_by field of the file meta data.
+	writerVersion *WriterVersion
+
+	// rowSchema is the schema definition of the rows, which is needed to convert their values.
+	rowSchema *parquetschema.SchemaDefinition
+
+	// fileSchema is the schema definition of the file, which is needed to convert the rows to
+	// the target schema.
+	fileSchema *parquetschema.SchemaDefinition
+
+	opts fileReaderOptions
+}
+
+// FileReaderOption describes an option function that is applied to a FileReader when it is created.
+type FileReaderOption func(opts *fileReaderOptions)
+
+type fileReaderOptions struct {
+	columns       []string
+	fieldIDs      []int32
+	zeroCopy      bool
+	maxMemorySize int64
+	footerLimits  FooterLimits
+	validateCRC   bool
+	strictCRC     bool
+	statsColumns  []string
+	strict        bool
+	salvage       bool
+	openFile      FileOpener
+	middleware    []PageMiddleware
+	maxLevel      uint16
+
+	lenientValueCounts bool
+	dateConversion     bool
+
+	timestampConversion bool
+	timestampLocation   *time.Location
+	int96Conversion     bool
+	uuidFormat          UUIDFormat
+
+	codecOverride        *parquet.CompressionCodec
+	codecOverrideColumns []string
+
+	rowGroupFilter        RowGroupFilter
+	rowGroupFilterColumns []string
+
+	binaryComparator  BinaryComparator
+	columnComparators map[string]BinaryComparator
+	duplicateNames    DuplicateColumnNames
+
+	observer ReaderObserver
+
+	targetSchema *parquetschema.SchemaDefinition
+
+	decryption *DecryptionProperties
+	// decryptor is set when an encrypted file is opened.
+	decryptor *fileDecryptor
+}
+
+// comparator returns the order in which the statistics of the column are interpreted, which is
+// binaryComparator unless it was overridden for the column.
+func (opts *fileReaderOptions) comparator(col *Column) BinaryComparator {
+	if cmp, ok := opts.columnComparators[col.FlatName()]; ok {
+		return cmp
+	}
+	return opts.binaryComparator
+}
+
+// codec returns the codec to decompress the pages of the column with, which is the codec from
+// the column chunk meta data unless it was overridden using WithCodecOverride.
+func (opts *fileReaderOptions) codec(col *Column, codec parquet.CompressionCodec) parquet.CompressionCodec {
+	if opts.codecOverride == nil {
+		return codec
+	}
+	if len(opts.codecOverrideColumns) == 0 {
+		return *opts.codecOverride
+	}
+	for _, name := range opts.codecOverrideColumns {
+		if name == col.FlatName() {
+			return *opts.codecOverride
+		}
+	}
+	return codec
+}
+
+// WithColumns limits the columns that are read to the provided columns. The names of the columns
+// need to be provided in dotted notation. If no columns are provided, then all columns are read.
+func WithColumns(columns ...string) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.columns = columns
+	}
+}
+
+// WithColumnsByFieldID limits the columns that are read to the columns and groups with the
+// provided field IDs, in addition to the columns provided with WithColumns, so that columns can
+// be resolved independently of their names. NewFileReaderWithOptions fails if a field ID isn't
+// unique in the schema of the file.
+func WithColumnsByFieldID(ids ...int32) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.fieldIDs = ids
+	}
+}
+
+// WithZeroCopyByteArrays enables reading BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY values of pages that are not
+// dictionary encoded without copying them. Instead, the values reference the memory of the page they
+// were read from. This reduces allocations for pipelines that process values and discard them right away.
+// The values must not be modified, and they must not be used anymore after the next row group is read.
+// Retaining any value would also keep the whole page in memory. Values of columns annotated as STRING
+// or UTF8 are returned as []byte as well, so this applies to them without any conversion to string.
+func WithZeroCopyByteArrays() FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.zeroCopy = true
+	}
+}
+
+// WithMaximumMemorySize limits the memory that may be used to read a single row group to maxSize
+// bytes. The memory required for compressed and uncompressed page data, dictionaries, levels and
+// decoded values is estimated from the page headers before it is allocated. If the limit is
+// exceeded, reading the row group fails with a *MemoryLimitExceededError. This protects the process
+// from running out of memory when reading huge or hostile files. A maxSize of 0 means no limit.
+func WithMaximumMemorySize(maxSize int64) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.maxMemorySize = maxSize
+	}
+}
+
+// WithFooterLimits sets limits that are enforced when the meta data footer of the file is parsed.
+// If a limit is exceeded, creating the FileReader fails with a *FooterLimitExceededError.
+func WithFooterLimits(limits FooterLimits) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.footerLimits = limits
+	}
+}
+
+// WithCRC32Validation enables the validation of the CRC32 checksums of pages, so that corrupted
+// data is detected instead of silently producing wrong values. Pages without checksum are skipped
+// by the validation, unless strict is set, in which case reading them fails as well. If the
+// validation fails, a *CRCError is returned.
+func WithCRC32Validation(strict bool) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.validateCRC = true
+		opts.strictCRC = strict
+	}
+}
+
+// WithStatisticsColumns adds the min and max values of the provided columns in the current row
+// group to every row returned by NextRow, so that consumers can reason about the bounds of the
+// data without reading the meta data separately. The values are added as a map under the key
+// StatisticsKey, named min_<column> and max_<column>, e.g. min_ts and max_ts for the column ts.
+// Columns without statistics in the current row group are omitted. The names of the columns need
+// to be provided in dotted notation.
+func WithStatisticsColumns(columns ...string) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.statsColumns = columns
+	}
+}
+
+// RowGroupFilter decides whether the row group with index rowGroup needs to be read. stats contains
+// the min and max values of the columns provided to WithRowGroupFilter, named like the values
+// added by WithStatisticsColumns. Row groups for which the filter returns false are skipped
+// without being read.
+type RowGroupFilter func(rowGroup int, stats map[string]interface{}) bool
+
+// WithRowGroupFilter skips all row groups for which filter returns false, so that predicates can
+// be pushed down to the statistics of the row groups. The names of the columns whose statistics
+// are passed to the filter need to be provided in dotted notation.
+func WithRowGroupFilter(filter RowGroupFilter, columns ...string) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.rowGroupFilter = filter
+		opts.rowGroupFilterColumns = columns
+	}
+}
+
+// WithPruningBinaryComparator sets the order in which the statistics of binary columns are
+// interpreted by WithRowGroupFilter, WithStatisticsColumns and RollupStatistics. Statistics that
+// were written in another order are ignored: with BinaryComparatorSigned, only the deprecated min
+// and max fields are used, otherwise only the min_value and max_value fields are used. The
+// default is BinaryComparatorUnsigned, the order defined by the parquet format.
+func WithPruningBinaryComparator(cmp BinaryComparator) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.binaryComparator = cmp
+	}
+}
+
+// WithColumnPruningBinaryComparator sets the order in which the statistics of a single binary
+// column are interpreted, overriding the comparator set with WithPruningBinaryComparator. This
+// allows to prune by statistics that were written in an order registered with
+// RegisterBinaryComparator, using WithColumnStatisticsBinaryComparator. The name of the column
+// needs to be provided in dotted notation.
+func WithColumnPruningBinaryComparator(col string, cmp BinaryComparator) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		if opts.columnComparators == nil {
+			opts.columnComparators = make(map[string]BinaryComparator)
+		}
+		opts.columnComparators[col] = cmp
+	}
+}
+
+// WithStrictValidation enables additional validation of the data that is read, for files from
+// untrusted producers. Definition and repetition levels must not exceed the maximum levels of
+// their column, and the number of values must match the value count of the column chunk and the
+// number of rows of the row group. Pages must not exceed the size of their column chunk, the
+// level sizes of V2 data pages must fit into the page sizes of their header, and the values of
+// STRING columns must be valid UTF-8. Pages other than data and dictionary pages, which are
+// skipped otherwise, are rejected, as are schema elements without repetition type and roots that
+// are optional or repeated. If the validation fails, a *ValidationError is returned.
+// Dictionary indices are always checked against the size of the dictionary.
+func WithStrictValidation() FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.strict = true
+	}
+}
+
+// WithSalvageMode enables a best-effort recovery mode for partially corrupted files. If a row group
+// can't be read, e.g. because a page fails to decompress or decode, the row group is skipped and
+// reading continues with the next row group instead of failing. As the pages of the columns of a
+// row group don't line up, rows can only be skipped by whole row groups. The rows that were
+// skipped so far are returned by SkippedRows.
+func WithSalvageMode() FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.salvage = true
+	}
+}
+
+// SkippedRows describes a range of rows that was skipped in salvage mode.
+type SkippedRows struct {
+	// RowGroup is the index of the row group the rows belong to.
+	RowGroup int
+	// FirstRow is the index of the first skipped row in the file.
+	FirstRow int64
+	// NumRows is the number of skipped rows.
+	NumRows int64
+	// Err is the error that caused the rows to be skipped.
+	Err error
+}
+
+// FileOpener opens the file at path for reading. If the returned reader implements io.Closer, it
+// is closed once the data that was needed has been read from it.
+type FileOpener func(path string) (io.ReadSeeker, error)
+
+// WithFileOpener sets the function that is used to open the files that contain the data of
+// column chunks which are not stored in the file itself, e.g. in summary files written by older
+// versions of parquet-mr. The file path of the column chunk is passed to the opener as-is.
+// Without a FileOpener, reading such column chunks fails.
+func WithFileOpener(open FileOpener) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.openFile = open
+	}
+}
+
+// WithPageMiddleware adds middleware that processes the raw data of every page that is read before
+// it is decompressed and decoded, including the pages that are checked by Validate. The
+// middleware is applied in the order in which it is added, after the CRC32 checksum of the page
+// was validated.
+func WithPageMiddleware(middleware ...PageMiddleware) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.middleware = append(opts.middleware, middleware...)
+	}
+}
+
+// WithMaxLevel sets the highest definition and repetition level that is accepted in the schema of
+// the file. Opening a file with a schema that is nested deeper fails with a
+// *LevelLimitExceededError. By default, MaxLevel is used, which is also the highest level that can
+// be set.
+func WithMaxLevel(max uint16) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.maxLevel = max
+	}
+}
+
+// WithCodecOverride forces the pages of the provided columns to be decompressed with codec,
+// ignoring the codec in the column chunk meta data. This allows to read files from buggy writers
+// that declared the wrong codec. If no columns are provided, the codec is used for all columns.
+// The names of the columns need to be provided in dotted notation.
+func WithCodecOverride(codec parquet.CompressionCodec, columns ...string) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.codecOverride = &codec
+		opts.codecOverrideColumns = columns
+	}
 }
 
 // NewFileReader creates a new FileReader. You can limit the columns that are read by providing
 // the names of the specific columns to read using dotted notation. If no columns are provided,
 // then all columns are read.
 func NewFileReader(r io.ReadSeeker, columns ...string) (*FileReader, error) {
-	meta, err := readFileMetaData(r)
+	return NewFileReaderWithOptions(r, WithColumns(columns...))
+}
+
+// NewFileReaderWithOptions creates a new FileReader. You can provide FileReaderOptions to
+// influence the file reader's behaviour.
+func NewFileReaderWithOptions(r io.ReadSeeker, options ...FileReaderOption) (*FileReader, error) {
+	opts := fileReaderOptions{
+		maxLevel: MaxLevel,
+	}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	meta, decryptor, err := readFileMetaData(r, opts.footerLimits, opts.decryption)
 	if err != nil {
 		return nil, errors.Wrap(err, "reading file meta data failed")
 	}
+	opts.decryptor = decryptor
+
+	if err := normalizeRepetitionTypes(meta.Schema, opts.strict); err != nil {
+		return nil, errors.Wrap(err, "creating schema failed")
+	}
 
-	schema, err := makeSchema(meta)
+	schema, err := makeSchema(meta, opts.maxLevel, opts.duplicateNames)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating schema failed")
 	}
 
+	columns := opts.columns
+	for _, id := range opts.fieldIDs {
+		col := schema.GetColumnByFieldID(id)
+		if col == nil {
+			return nil, errors.Errorf("no unique column with field ID %d found", id)
+		}
+		columns = append(columns[:len(columns):len(columns)], col.FlatName())
+	}
 	schema.setSelectedColumns(columns...)
+
+	var fileSchema *parquetschema.SchemaDefinition
+	if opts.targetSchema != nil {
+		fileSchema = schema.GetSchemaDefinition()
+		if err := checkTargetSchema(opts.targetSchema, fileSchema); err != nil {
+			return nil, err
+		}
+		if len(columns) == 0 {
+			if cols := targetColumns(opts.targetSchema, schema.Columns()); len(cols) > 0 {
+				schema.setSelectedColumns(cols...)
+			}
+		}
+	}
+
+	if len(opts.statsColumns) > 0 {
+		if schema.GetColumnByName(StatisticsKey) != nil {
+			return nil, errors.Errorf("column %s conflicts with the statistics columns", StatisticsKey)
+		}
+		for _, name := range opts.statsColumns {
+			if schema.GetColumnByName(name) == nil {
+				return nil, errors.Errorf("statistics column %q not found", name)
+			}
+		}
+	}
+
+	for _, name := range opts.rowGroupFilterColumns {
+		if schema.GetColumnByName(name) == nil {
+			return nil, errors.Errorf("row group filter column %q not found", name)
+		}
+	}
+
+	for name := range opts.columnComparators {
+		if schema.GetColumnByName(name) == nil {
+			return nil, errors.Errorf("binary comparator column %q not found", name)
+		}
+	}
+
 	// Reset the reader to the beginning of the file
 	if _, err := r.Seek(4, io.SeekStart); err != nil {
 		return nil, err
 	}
 	return &FileReader{
-		meta:         meta,
-		SchemaReader: schema,
-		reader:       r,
+		meta:          meta,
+		SchemaReader:  schema,
+		reader:        r,
+		fileSchema:    fileSchema,
+		writerVersion: ParseCreatedBy(meta.GetCreatedBy()),
+		opts:          opts,
 	}, nil
 }
 
-// readRowGroup read the next row group into memory
+// readRowGroup read the next row group into memory. In salvage mode, row groups that can't be read
+// are skipped.
 func (f *FileReader) readRowGroup() error {
+	for {
+		err := f.readNextRowGroup()
+		if err == nil || err == io.EOF || !f.opts.salvage {
+			return err
+		}
+		f.skipRows(f.rowGroupPosition-1, 0, err)
+	}
+}
+
+// skipRows records that the rows of the row group with index rowGroup starting at the row
+// with index firstRow in the row group were skipped because of err.
+func (f *FileReader) skipRows(rowGroup int, firstRow int64, err error) {
+	skipped := SkippedRows{
+		RowGroup: rowGroup,
+		FirstRow: firstRow,
+		NumRows:  f.meta.RowGroups[rowGroup].NumRows - firstRow,
+		Err:      err,
+	}
+	for _, rg := range f.meta.RowGroups[:rowGroup] {
+		skipped.FirstRow += rg.NumRows
+	}
+	f.skippedRows = append(f.skippedRows, skipped)
+}
+
+// BinaryComparator returns the order in which the statistics of binary columns are interpreted,
+// so that row group filters can compare values in the same order.
+func (f *FileReader) BinaryComparator() BinaryComparator {
+	return f.opts.binaryComparator
+}
+
+// ColumnBinaryComparator returns the order in which the statistics of the column colName are
+// interpreted, which is BinaryComparator unless it was overridden for the column using
+// WithColumnPruningBinaryComparator. The column name has to be provided in its dotted notation.
+func (f *FileReader) ColumnBinaryComparator(colName string) BinaryComparator {
+	if cmp, ok := f.opts.columnComparators[colName]; ok {
+		return cmp
+	}
+	return f.opts.binaryComparator
+}
+
+// SkippedRows returns the rows that were skipped so far in salvage mode because they couldn't be read.
+func (f *FileReader) SkippedRows() []SkippedRows {
+	return f.skippedRows
+}
+
+// EvictColumns releases the data of the provided columns and stops reading them, e.g. because
+// they are no longer needed after a filter stage of a pipeline. The data of the current row
+// group is released right away, so the remaining rows of the current row group and all rows of
+// the following row groups returned by NextRow don't contain the columns anymore. The names of
+// the columns or groups need to be provided in dotted notation. Evicted columns can't be read
+// again using the same FileReader.
+func (f *FileReader) EvictColumns(columns ...string) error {
+	for _, name := range columns {
+		found := false
+		for _, col := range f.Columns() {
+			if col.FlatName() == name || strings.HasPrefix(col.FlatName(), name+".") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("column %q not found", name)
+		}
+	}
+
+	f.SchemaReader.evictColumns(columns...)
+	for _, col := range f.Columns() {
+		if !f.SchemaReader.isSelected(col.FlatName()) && !col.data.skipped {
+			col.data.release()
+		}
+	}
+
+	return nil
+}
+
+// SetRowGroupFilter sets the filter for all row groups that weren't read yet, like
+// WithRowGroupFilter. This allows to derive the filter from the schema of the file after the
+// FileReader was created. A nil filter reads all remaining row groups.
+func (f *FileReader) SetRowGroupFilter(filter RowGroupFilter, columns ...string) error {
+	for _, name := range columns {
+		if f.GetColumnByName(name) == nil {
+			return errors.Errorf("row group filter column %q not found", name)
+		}
+	}
+
+	f.opts.rowGroupFilter = filter
+	f.opts.rowGroupFilterColumns = columns
+	return nil
+}
+
+func (f *FileReader) readNextRowGroup() error {
+	for f.opts.rowGroupFilter != nil && f.rowGroupPosition < len(f.meta.RowGroups) {
+		stats, err := rowGroupStatistics(f.SchemaReader, f.meta.RowGroups[f.rowGroupPosition], f.opts.rowGroupFilterColumns, f.writerVersion, &f.opts)
+		if err != nil {
+			// the row group is consumed, so that it's recorded as skipped in salvage mode and not
+			// filtered again.
+			f.rowGroupPosition++
+			return err
+		}
+		if f.opts.rowGroupFilter(f.rowGroupPosition, stats) {
+			break
+		}
+		f.rowGroupPosition++
+	}
+
 	if len(f.meta.RowGroups) <= f.rowGroupPosition {
 		return io.EOF
 	}
 	f.rowGroupPosition++
-	return readRowGroup(f.reader, f.SchemaReader, f.meta.RowGroups[f.rowGroupPosition-1])
+	rg := f.meta.RowGroups[f.rowGroupPosition-1]
+
+	if len(f.opts.statsColumns) > 0 {
+		stats, err := rowGroupStatistics(f.SchemaReader, rg, f.opts.statsColumns, f.writerVersion, &f.opts)
+		if err != nil {
+			return err
+		}
+		f.rowGroupStats = stats
+	}
+
+	mismatches, err := readRowGroup(f.reader, f.SchemaReader, f.rowGroupPosition-1, rg, &f.opts)
+	f.valueCountMismatches = append(f.valueCountMismatches, mismatches...)
+	return err
 }
 
 // CurrentRowGroup returns information about the current row group.
@@ -75,6 +544,26 @@ func (f *FileReader) NumRows() int64 {
 	return f.meta.NumRows
 }
 
+// FirstRowIndex returns the index of the first row of the row group with index rowGroup within
+// the file, i.e. the number of rows in all row groups before it. Together with the position of a
+// row within its row group, this allows to address every row of the file. The number of rows is
+// taken from the meta data of the row groups.
+func (f *FileReader) FirstRowIndex(rowGroup int) (int64, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return 0, errors.Errorf("row group index %d is out of bounds", rowGroup)
+	}
+
+	var idx int64
+	for i, rg := range f.meta.RowGroups[:rowGroup] {
+		if rg.NumRows < 0 {
+			return 0, errors.Errorf("row group %d has a negative number of rows", i)
+		}
+		idx += rg.NumRows
+	}
+
+	return idx, nil
+}
+
 func (f *FileReader) advanceIfNeeded() error {
 	if f.rowGroupPosition == 0 || f.currentRecord >= f.SchemaReader.rowGroupNumRecords() || f.skipRowGroup {
 		if err := f.readRowGroup(); err != nil {
@@ -104,7 +593,33 @@ func (f *FileReader) NextRow() (map[string]interface{}, error) {
 	}
 
 	f.currentRecord++
-	return f.SchemaReader.getData()
+	row, err := f.SchemaReader.getData()
+	if err != nil && f.opts.salvage {
+		// the rows of the row group can't be trusted anymore once the levels are out of sync.
+		f.skipRows(f.rowGroupPosition-1, f.currentRecord-1, err)
+		f.skipRowGroup = true
+		return f.NextRow()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f.opts.targetSchema != nil {
+		row = adaptGroup(f.opts.targetSchema.RootColumn.Children, f.fileSchema.RootColumn.Children, row)
+	}
+	if f.opts.convertsValues() {
+		f.convertRow(row)
+	}
+	if len(f.opts.statsColumns) == 0 {
+		return row, nil
+	}
+
+	stats := make(map[string]interface{}, len(f.rowGroupStats))
+	for k, v := range f.rowGroupStats {
+		stats[k] = v
+	}
+	row[StatisticsKey] = stats
+
+	return row, nil
 }
 
 // SkipRowGroup skips the currently loaded row group and advances to the next row group.
@@ -122,6 +637,32 @@ func (f *FileReader) MetaData() map[string]string {
 	return keyValueMetaDataToMap(f.meta.KeyValueMetadata)
 }
 
+// MetaDataValue returns the value of the metadata entry key stored in the parquet file, and whether
+// such an entry with a value exists. If the key occurs multiple times, the last value is returned
+// like in MetaData.
+func (f *FileReader) MetaDataValue(key string) (string, bool) {
+	for i := len(f.meta.KeyValueMetadata) - 1; i >= 0; i-- {
+		if kv := f.meta.KeyValueMetadata[i]; kv.Key == key && kv.Value != nil {
+			return *kv.Value, true
+		}
+	}
+	return "", false
+}
+
+// MetaDataKeys returns the keys of all metadata entries stored in the parquet file in the order of
+// the file, including the keys of entries without a value, which MetaData omits.
+func (f *FileReader) MetaDataKeys() []string {
+	keys := make([]string, 0, len(f.meta.KeyValueMetadata))
+	seen := make(map[string]bool, len(f.meta.KeyValueMetadata))
+	for _, kv := range f.meta.KeyValueMetadata {
+		if !seen[kv.Key] {
+			seen[kv.Key] = true
+			keys = append(keys, kv.Key)
+		}
+	}
+	return keys
+}
+
 // ColumnMetaData returns a map of metadata key-value pairs for the provided column in the current
 // row group. The column name has to be provided in its dotted notation.
 func (f *FileReader) ColumnMetaData(colName string) (map[string]string, error) {
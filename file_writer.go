@@ -3,7 +3,9 @@ package goparquet
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"sort"
 
 	"github.com/fraugster/parquet-go/parquet"
 	"github.com/fraugster/parquet-go/parquetschema"
@@ -22,12 +24,68 @@ type FileWriter struct {
 	createdBy       string
 
 	rowGroupFlushSize int64
+	rowGroupFlushRows int64
+
+	rowGroupTargetSize int64
+	rowGroupMaxPadding int64
+	// estimatedSize and writtenSize are the estimated data sizes and the actually written sizes of
+	// the row groups that were flushed so far, which are used to estimate the compression ratio.
+	estimatedSize int64
+	writtenSize   int64
 
 	rowGroups []*parquet.RowGroup
 
-	codec parquet.CompressionCodec
+	codec       parquet.CompressionCodec
+	columnCodec map[string]parquet.CompressionCodec
+
+	newPage       newDataPageFunc
+	columnNewPage map[string]newDataPageFunc
+	columnEnc     map[string]parquet.Encoding
+	writeCRC      bool
+	pageBounds    pageBounds
+	statsOpts     statisticsOptions
+
+	writePageIndex bool
+	pageIndexes    []*chunkPageIndex
+
+	bloomFilterFPP map[string]float64
+	bloomFilters   []*chunkBloomFilter
+
+	// columnOptionsApplied is set once the per-column options were checked against the schema.
+	columnOptionsApplied bool
+
+	// copiedChunks contains the column chunks of the current row group that were copied using
+	// CopyChunk, which all have copiedRows rows.
+	copiedChunks map[string]*parquet.ColumnChunk
+	copiedRows   int64
+
+	// columnBatchRecords contains the number of records of the columns of the current row group
+	// that were written using WriteColumnBatch.
+	columnBatchRecords map[string]int64
+
+	sorting *rowGroupSorting
+
+	timestampOpts   *timestampOptions
+	int96Timestamps bool
+	columnsPrepared bool
+
+	plugins        []WriterPlugin
+	sidecarStorage SidecarStorage
 
-	newPage newDataPageFunc
+	writeFingerprint bool
+
+	maxFileSize  int64
+	maxRowGroups int
+	onLimit      func(limit WriterLimit) error
+
+	tx *writerTransaction
+}
+
+// writerTransaction holds the state of the current row group when a transaction was begun.
+type writerTransaction struct {
+	numRecords int64
+	marks      map[string]columnStoreMark
+	sorting    sortingMark
 }
 
 // FileWriterOption describes an option function that is applied to a FileWriter when it is created.
@@ -47,6 +105,7 @@ func NewFileWriter(w io.Writer, options ...FileWriterOption) *FileWriter {
 		rowGroups:    []*parquet.RowGroup{},
 		createdBy:    "parquet-go",
 		newPage:      newDataPageV1Writer,
+		pageBounds:   defaultPageBounds(),
 	}
 
 	for _, opt := range options {
@@ -77,14 +136,28 @@ func WithCompressionCodec(codec parquet.CompressionCodec) FileWriterOption {
 	}
 }
 
-// WithMetaData sets the key-value meta data on the file.
+// WithColumnCodec sets the compression codec of a single column, overriding the codec set with
+// WithCompressionCodec, e.g. to compress large binary columns more heavily than numeric columns.
+// The codec needs to be registered using RegisterBlockCompressor unless it's supported out of
+// the box. The name of the column needs to be provided in dotted notation.
+func WithColumnCodec(col string, codec parquet.CompressionCodec) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.columnCodec == nil {
+			fw.columnCodec = make(map[string]parquet.CompressionCodec)
+		}
+		fw.columnCodec[col] = codec
+	}
+}
+
+// WithMetaData sets the key-value meta data on the file. The entries are copied, so changes to
+// data don't affect the file, and can be changed until the file is closed using SetMetaData and
+// DeleteMetaData.
 func WithMetaData(data map[string]string) FileWriterOption {
 	return func(fw *FileWriter) {
-		if data != nil {
-			fw.kvStore = data
-			return
+		fw.kvStore = make(map[string]string, len(data))
+		for k, v := range data {
+			fw.kvStore[k] = v
 		}
-		fw.kvStore = make(map[string]string)
 	}
 }
 
@@ -98,6 +171,33 @@ func WithMaxRowGroupSize(size int64) FileWriterOption {
 	}
 }
 
+// WithMaxRowGroupRows sets the maximum number of records of a row group before it shall be
+// flushed automatically. It can be combined with WithMaxRowGroupSize, in which case the row
+// group is flushed as soon as one of the limits is reached. The same restrictions as for
+// WithMaxRowGroupSize apply.
+func WithMaxRowGroupRows(n int64) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.rowGroupFlushRows = n
+	}
+}
+
+// WithRowGroupTargetSize sets the size that the row groups shall have in the file, i.e. after
+// encoding and compression, e.g. the part size of an object store like 128 MiB, so that scanners
+// can fetch a row group with a single ranged read. The row group is flushed automatically once its
+// estimated size gets close to the target. The size is estimated from the size of its data and the
+// compression ratio of the row groups that were flushed before, which is assumed to be 1 for the
+// first row group. The row groups are also aligned to multiples of size within the file: a row
+// group that would start less than maxPadding bytes before the next multiple of size is preceded by
+// zero bytes up to it, otherwise its target is reduced to end at that multiple. A maxPadding of 0
+// disables the alignment, so that all row groups target size. The same restrictions as for
+// WithMaxRowGroupSize apply, which can be combined with this option.
+func WithRowGroupTargetSize(size, maxPadding int64) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.rowGroupTargetSize = size
+		fw.rowGroupMaxPadding = maxPadding
+	}
+}
+
 // WithSchemaDefinition sets the schema definition to use for this parquet file.
 func WithSchemaDefinition(sd *parquetschema.SchemaDefinition) FileWriterOption {
 	return func(fw *FileWriter) {
@@ -107,6 +207,15 @@ func WithSchemaDefinition(sd *parquetschema.SchemaDefinition) FileWriterOption {
 	}
 }
 
+// WithSchemaFingerprint enables storing the fingerprint of the schema in the key-value meta data
+// of the file under the key SchemaFingerprintKey when the file is closed, so that readers can
+// compare schemas of multiple files without parsing them.
+func WithSchemaFingerprint() FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.writeFingerprint = true
+	}
+}
+
 // WithDataPageV2 enables the writer to write pages in the new V2 format. By default,
 // the library is using the V1 format. Please be aware that this may cause compatibility
 // issues with older implementations of parquet.
@@ -116,6 +225,246 @@ func WithDataPageV2() FileWriterOption {
 	}
 }
 
+// DataPageVersion is the version of the format of data pages.
+type DataPageVersion int
+
+const (
+	// DataPageV1 is the original format of data pages.
+	DataPageV1 DataPageVersion = iota + 1
+	// DataPageV2 is the new format of data pages, which stores the repetition and definition
+	// levels uncompressed in front of the values.
+	DataPageV2
+)
+
+// WithDataPageVersionForColumn sets the version of the data pages of a single column, overriding
+// the version that is used for all other columns. This allows to mix V1 and V2 data pages in the
+// same file, e.g. to write V2 pages only for columns that readers prune by their levels. The name
+// of the column needs to be provided in dotted notation.
+func WithDataPageVersionForColumn(col string, version DataPageVersion) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.columnNewPage == nil {
+			fw.columnNewPage = make(map[string]newDataPageFunc)
+		}
+		switch version {
+		case DataPageV2:
+			fw.columnNewPage[col] = newDataPageV2Writer
+		default:
+			fw.columnNewPage[col] = newDataPageV1Writer
+		}
+	}
+}
+
+// WithEncodingForColumn sets the encoding of the values of a single column, e.g.
+// DELTA_BINARY_PACKED for INT32 and INT64 columns with monotonically increasing values like IDs
+// or timestamps, which are stored far more compactly than with PLAIN encoding. Dictionary
+// encoding is not considered for the column anymore. The supported encodings depend on the
+// type of the column. The name of the column needs to be provided in dotted notation.
+func WithEncodingForColumn(col string, enc parquet.Encoding) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.columnEnc == nil {
+			fw.columnEnc = make(map[string]parquet.Encoding)
+		}
+		fw.columnEnc[col] = enc
+	}
+}
+
+// WithCRC32Checksums enables the writer to store the CRC32 checksum of every data and
+// dictionary page in its page header, so that readers can detect corrupted pages. By default,
+// no checksums are written.
+func WithCRC32Checksums() FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.writeCRC = true
+	}
+}
+
+// WithPageIndex enables the writer to store the column index and the offset index of every
+// column chunk, which contain the min and max values, null counts and locations of its data
+// pages, so that readers can skip individual pages. The indexes are written right before the
+// meta data footer when the file is closed. By default, no page indexes are written.
+func WithPageIndex() FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.writePageIndex = true
+	}
+}
+
+// WithBloomFilter enables the writer to store a split block Bloom filter for every column chunk
+// of the column col, which allows readers to skip row groups that definitely don't contain a
+// value, e.g. when looking up IDs. The filter is sized for the number of distinct values of the
+// column chunk, so that values are falsely reported as contained with a probability of fpp, which
+// needs to be between 0 and 1. Bloom filters are supported on columns of all types except
+// BOOLEAN, including FIXED_LEN_BYTE_ARRAY columns like UUIDs and decimals. The filters are
+// written right before the meta data footer when the file is closed. The name of the column
+// needs to be provided in dotted notation.
+func WithBloomFilter(col string, fpp float64) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.bloomFilterFPP == nil {
+			fw.bloomFilterFPP = make(map[string]float64)
+		}
+		fw.bloomFilterFPP[col] = fpp
+	}
+}
+
+// WithPageSize sets the target size of the encoded values and levels of a data page. The
+// values of a column chunk are split into multiple data pages, each of which is completed once
+// the estimated size of its data reaches the target size. Pages are never split within a
+// record, so pages with large records can exceed the target size. A size of 0 disables the size
+// limit, so that pages are only completed by the maximum number of values. The default is
+// DefaultPageSize.
+func WithPageSize(size int64) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.pageBounds.size = size
+	}
+}
+
+// WithPageValueLimits sets the minimum and maximum number of values, including null values, in
+// a data page. A page is only completed because of its size if it contains at least min values,
+// and it is always completed once it contains max values, so that columns with very large
+// values don't result in lots of tiny pages and columns with very small values don't result
+// in huge pages. Pages are never split within a record, so pages with large records can
+// contain more than max values. A max of 0 disables the limit, so that pages are only completed
+// by their size. The defaults are DefaultPageMinValues and DefaultPageMaxValues.
+func WithPageValueLimits(min, max int) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.pageBounds.minValues = min
+		fw.pageBounds.maxValues = max
+	}
+}
+
+// WithMaxDictionarySize sets the maximum plain encoded size of the dictionary of a column chunk.
+// Columns that benefit from dictionary encoding are written with RLE_DICTIONARY encoded data
+// pages until the values that were added to the dictionary exceed the size. The data page that
+// exceeds it and all following data pages of the column chunk fall back to the encoding of the
+// column, which is PLAIN unless set otherwise. A size of 0 disables the limit. The default is
+// DefaultMaxDictionarySize.
+func WithMaxDictionarySize(size int64) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.pageBounds.dictionarySize = size
+	}
+}
+
+// WithStatisticsBinaryComparator sets the order in which the min and max statistics of binary
+// columns are computed. With BinaryComparatorSigned, the statistics are written to the deprecated
+// min and max fields for older readers, otherwise they are written to the min_value and max_value
+// fields. The default is BinaryComparatorUnsigned, the order defined by the parquet format.
+func WithStatisticsBinaryComparator(cmp BinaryComparator) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.statsOpts.binaryCmp = cmp
+	}
+}
+
+// WithColumnStatisticsBinaryComparator sets the order in which the min and max statistics and the
+// column index of a single binary column are computed, overriding the comparator set with
+// WithStatisticsBinaryComparator. This allows to use an order registered with
+// RegisterBinaryComparator for columns with domain-specific orderings, whose statistics are
+// written to the deprecated min and max fields without a column index. The name of the column
+// needs to be provided in dotted notation.
+func WithColumnStatisticsBinaryComparator(col string, cmp BinaryComparator) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.statsOpts.columnCmp == nil {
+			fw.statsOpts.columnCmp = make(map[string]BinaryComparator)
+		}
+		fw.statsOpts.columnCmp[col] = cmp
+	}
+}
+
+// WithStatisticsTruncateLength sets the maximum length of the min and max statistics of BYTE_ARRAY
+// columns to keep the meta data of wide columns small. Longer min values are truncated. Longer
+// max values are truncated and incremented in the order of the binary comparator so that they
+// are still an upper bound, max values that can't be incremented are kept as they are. Values of
+// decimal columns are never truncated. A length of 0 disables the truncation, which is the
+// default.
+func WithStatisticsTruncateLength(length int) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.statsOpts.truncateLength = length
+	}
+}
+
+// WriterLimit identifies a hard limit of a FileWriter.
+type WriterLimit int
+
+const (
+	// LimitMaxFileSize is hit when the data written to the file plus the data of the current
+	// row group reaches the maximum file size.
+	LimitMaxFileSize WriterLimit = iota + 1
+	// LimitMaxRowGroups is hit when a row group is to be written but the file already contains
+	// the maximum number of row groups.
+	LimitMaxRowGroups
+)
+
+func (l WriterLimit) String() string {
+	switch l {
+	case LimitMaxFileSize:
+		return "max file size"
+	case LimitMaxRowGroups:
+		return "max row groups"
+	}
+	return fmt.Sprintf("WriterLimit(%d)", int(l))
+}
+
+// ErrLimitExceeded is returned when a hard limit of the FileWriter is hit and no limit
+// callback was configured using WithLimitCallback.
+var ErrLimitExceeded = errors.New("writer limit exceeded")
+
+// WithMaxFileSize sets a hard limit on the size of the file. Before data is added, the writer
+// checks whether the data written so far plus the estimated size of the current row group
+// reached the limit. As the row group size is an estimation of the uncompressed size, the
+// final file size may differ.
+func WithMaxFileSize(size int64) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.maxFileSize = size
+	}
+}
+
+// WithMaxRowGroups sets a hard limit on the number of row groups in the file. Once the file
+// contains n row groups, the limit is hit when data for another row group is added, so that the
+// file can still be closed.
+func WithMaxRowGroups(n int) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.maxRowGroups = n
+	}
+}
+
+// WithLimitCallback sets a function that is called whenever a hard limit is hit. If the function
+// returns an error, the operation that hit the limit is aborted and the error is returned to the
+// caller, e.g. so that the caller can close the file and rotate to a new one. If the function
+// returns nil, the operation continues, which allows the function to apply backpressure by
+// blocking. Without a limit callback, ErrLimitExceeded is returned when a limit is hit.
+func WithLimitCallback(fn func(limit WriterLimit) error) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.onLimit = fn
+	}
+}
+
+func (fw *FileWriter) limitHit(limit WriterLimit) error {
+	if fw.onLimit == nil {
+		return fmt.Errorf("%w: %s", ErrLimitExceeded, limit)
+	}
+	return fw.onLimit(limit)
+}
+
+func (fw *FileWriter) checkRowGroupLimit() error {
+	if fw.maxRowGroups > 0 && len(fw.rowGroups) >= fw.maxRowGroups {
+		return fw.limitHit(LimitMaxRowGroups)
+	}
+	return nil
+}
+
+// checkNewRowGroupLimit checks the row group limit before the first data of a row group is
+// added, as the row group couldn't be flushed otherwise.
+func (fw *FileWriter) checkNewRowGroupLimit() error {
+	if fw.rowGroupNumRecords() > 0 || len(fw.columnBatchRecords) > 0 || len(fw.copiedChunks) > 0 {
+		return nil
+	}
+	return fw.checkRowGroupLimit()
+}
+
+func (fw *FileWriter) checkFileSizeLimit() error {
+	if fw.maxFileSize > 0 && fw.CurrentFileSize()+fw.CurrentRowGroupSize() >= fw.maxFileSize {
+		return fw.limitHit(LimitMaxFileSize)
+	}
+	return nil
+}
+
 type flushRowGroupOptionHandle struct {
 	cols   map[string]map[string]string
 	global map[string]string
@@ -176,11 +525,50 @@ func WithRowGroupMetaData(kv map[string]string) FlushRowGroupOption {
 
 // FlushRowGroup writes the current row group to the parquet file.
 func (fw *FileWriter) FlushRowGroup(opts ...FlushRowGroupOption) error {
+	if fw.tx != nil {
+		return errors.New("can't flush row group while a transaction is in progress")
+	}
+
+	if len(fw.copiedChunks) > 0 {
+		return errors.New("can't flush row group while column chunks of the current row group are copied")
+	}
+
+	// the options are checked before the row group is changed in any way, e.g. sorted, so that
+	// it's left as it is if they're invalid.
+	if err := fw.applyColumnOptions(); err != nil {
+		return err
+	}
+
+	if len(fw.columnBatchRecords) > 0 {
+		if err := fw.finishColumnBatches(); err != nil {
+			return err
+		}
+	}
+
 	// Write the entire row group
 	if fw.rowGroupNumRecords() == 0 {
 		return errors.New("nothing to write")
 	}
 
+	if err := fw.checkRowGroupLimit(); err != nil {
+		return err
+	}
+
+	var sortingColumns []*parquet.SortingColumn
+	if fw.sorting != nil {
+		if err := fw.sorting.resolve(fw); err != nil {
+			return err
+		}
+		if err := fw.sorting.sortRowGroup(fw); err != nil {
+			return err
+		}
+		sortingColumns = fw.sorting.metaData()
+	}
+
+	if err := fw.observeRowGroup(); err != nil {
+		return err
+	}
+
 	if fw.w.Pos() == 0 {
 		if err := writeFull(fw.w, magic); err != nil {
 			return err
@@ -193,52 +581,416 @@ func (fw *FileWriter) FlushRowGroup(opts ...FlushRowGroupOption) error {
 		o(h)
 	}
 
-	cc, err := writeRowGroup(fw.w, fw.SchemaWriter, fw.codec, fw.newPage, h)
+	// the bloom filters are built before the row group is written, as they need the values of
+	// the column stores.
+	bloomFilters := make(map[int]*BloomFilter, len(fw.bloomFilterFPP))
+	for col, fpp := range fw.bloomFilterFPP {
+		c := fw.GetColumnByName(col)
+		filter, err := buildBloomFilter(c, fpp)
+		if err != nil {
+			return fmt.Errorf("building bloom filter of column %s failed: %w", col, err)
+		}
+		bloomFilters[c.Index()] = filter
+	}
+
+	if err := fw.alignRowGroup(); err != nil {
+		return err
+	}
+	startPos, dataSize := fw.w.Pos(), fw.SchemaWriter.DataSize()
+
+	cc, indexes, err := writeRowGroup(fw.w, fw.SchemaWriter, fw.codec, fw.columnCodec, fw.newPage, fw.columnNewPage, fw.pageBounds, fw.statsOpts, fw.writeCRC, fw.writePageIndex, h)
 	if err != nil {
 		return err
 	}
+	fw.pageIndexes = append(fw.pageIndexes, indexes...)
+	fw.estimatedSize += dataSize
+	fw.writtenSize += fw.w.Pos() - startPos
+	for i, ch := range cc {
+		if filter, ok := bloomFilters[i]; ok {
+			fw.bloomFilters = append(fw.bloomFilters, &chunkBloomFilter{chunk: ch, filter: filter})
+		}
+	}
 
 	fw.rowGroups = append(fw.rowGroups, &parquet.RowGroup{
 		Columns:        cc,
 		TotalByteSize:  0,
 		NumRows:        fw.rowGroupNumRecords(),
-		SortingColumns: nil,
+		SortingColumns: sortingColumns,
 	})
 	fw.totalNumRecords += fw.rowGroupNumRecords()
 	// flush the schema
 	fw.SchemaWriter.resetData()
+	if fw.sorting != nil {
+		fw.sorting.reset()
+	}
+
+	return nil
+}
+
+// applyColumnOptions checks that the per-column options refer to columns of the schema and sets
+// the encodings of the column stores. It's only done for the first row group, as the schema
+// can't change once data was added.
+func (fw *FileWriter) applyColumnOptions() error {
+	if fw.columnOptionsApplied {
+		return nil
+	}
+
+	for col := range fw.columnNewPage {
+		if fw.GetColumnByName(col) == nil {
+			return fmt.Errorf("data page version set for unknown column %q", col)
+		}
+	}
+
+	for col := range fw.columnCodec {
+		if fw.GetColumnByName(col) == nil {
+			return fmt.Errorf("compression codec set for unknown column %q", col)
+		}
+	}
+
+	for col := range fw.statsOpts.columnCmp {
+		if fw.GetColumnByName(col) == nil {
+			return fmt.Errorf("binary comparator set for unknown column %q", col)
+		}
+	}
+
+	for col, fpp := range fw.bloomFilterFPP {
+		if fw.GetColumnByName(col) == nil {
+			return fmt.Errorf("bloom filter set for unknown column %q", col)
+		}
+		if fpp <= 0 || fpp >= 1 {
+			return fmt.Errorf("invalid false positive probability %v of bloom filter for column %s", fpp, col)
+		}
+	}
+
+	for col, enc := range fw.columnEnc {
+		c := fw.GetColumnByName(col)
+		if c == nil {
+			return fmt.Errorf("encoding set for unknown column %q", col)
+		}
+		if err := c.getColumnStore().setEncoding(enc); err != nil {
+			return fmt.Errorf("setting encoding of column %s failed: %w", col, err)
+		}
+	}
+
+	fw.columnOptionsApplied = true
+	return nil
+}
+
+// WriteEncodedRowGroup writes a complete row group of numRows rows that consists of pages
+// that were already encoded (and optionally compressed) by the caller. This allows other
+// encoder implementations to use the FileWriter solely for the file layout and the meta data
+// footer. A column chunk has to be provided for every data column of the schema, in any order.
+// The pages of TIMESTAMP columns have to be encoded as INT96 if WithInt96Timestamps is used.
+// It is not possible to write an encoded row group while there is data added through AddData
+// that has not been flushed yet. All column chunks are validated before the first one is
+// written, so nothing is written if any of them is invalid, e.g. if the number of values of a
+// column that isn't repeated differs from numRows.
+func (fw *FileWriter) WriteEncodedRowGroup(numRows int64, chunks []*EncodedColumnChunk, opts ...FlushRowGroupOption) error {
+	if fw.tx != nil {
+		return errors.New("can't write encoded row group while a transaction is in progress")
+	}
+
+	if fw.rowGroupNumRecords() > 0 {
+		return errors.New("can't write encoded row group while the current row group contains unflushed data")
+	}
+
+	if err := fw.checkRowGroupLimit(); err != nil {
+		return err
+	}
+
+	fw.prepareColumns()
+
+	byName := make(map[string]*EncodedColumnChunk, len(chunks))
+	for i, c := range chunks {
+		if c == nil {
+			return fmt.Errorf("column chunk %d is nil", i)
+		}
+		if _, ok := byName[c.Column]; ok {
+			return fmt.Errorf("duplicate column chunk for column %s", c.Column)
+		}
+		byName[c.Column] = c
+	}
+
+	cols := fw.Columns()
+	if len(cols) != len(byName) {
+		return fmt.Errorf("schema has %d columns but %d column chunks were provided", len(cols), len(byName))
+	}
+
+	// all chunks are validated before the first one is written, so that an invalid chunk
+	// doesn't leave the pages of the chunks before it in the file.
+	for _, col := range cols {
+		chunk, ok := byName[col.FlatName()]
+		if !ok {
+			return fmt.Errorf("no column chunk provided for column %s", col.FlatName())
+		}
+		if err := validateEncodedChunk(col, chunk, numRows); err != nil {
+			return err
+		}
+	}
+
+	if fw.w.Pos() == 0 {
+		if err := writeFull(fw.w, magic); err != nil {
+			return err
+		}
+	}
+
+	h := newFlushRowGroupOptionHandle()
+	for _, o := range opts {
+		o(h)
+	}
+
+	cc := make([]*parquet.ColumnChunk, 0, len(cols))
+	var totalSize int64
+	for _, col := range cols {
+		ch, err := writeEncodedChunk(fw.w, col, byName[col.FlatName()], h.getMetaData(col.FlatName()))
+		if err != nil {
+			return err
+		}
+		totalSize += ch.MetaData.TotalUncompressedSize
+		cc = append(cc, ch)
+	}
+
+	fw.rowGroups = append(fw.rowGroups, &parquet.RowGroup{
+		Columns:       cc,
+		TotalByteSize: totalSize,
+		NumRows:       numRows,
+	})
+	fw.totalNumRecords += numRows
 
 	return nil
 }
 
 // AddData adds a new record to the current row group and flushes it if auto-flush is enabled and the size
-// is equal to or greater than the configured maximum row group size.
+// or the number of records is equal to or greater than the configured maximum of the row group. If a maximum
+// file size was configured and is reached, or the record would start a row group beyond the maximum number
+// of row groups, the limit callback is invoked before the record is added. While a
+// transaction is in progress, the row group is not flushed before the transaction is committed.
 func (fw *FileWriter) AddData(m map[string]interface{}) error {
-	if err := fw.SchemaWriter.AddData(m); err != nil {
+	if err := fw.checkFileSizeLimit(); err != nil {
+		return err
+	}
+
+	if len(fw.columnBatchRecords) > 0 {
+		return errors.New("can't add data while the current row group contains column batches")
+	}
+
+	if err := fw.checkNewRowGroupLimit(); err != nil {
+		return err
+	}
+
+	fw.prepareColumns()
+
+	if fw.sorting != nil {
+		if err := fw.sorting.addData(fw, m); err != nil {
+			return err
+		}
+	} else if err := fw.SchemaWriter.AddData(m); err != nil {
 		return err
 	}
 
+	if fw.tx != nil {
+		return nil
+	}
+
+	return fw.autoFlush()
+}
+
+func (fw *FileWriter) autoFlush() error {
 	if fw.rowGroupFlushSize > 0 && fw.SchemaWriter.DataSize() >= fw.rowGroupFlushSize {
 		return fw.FlushRowGroup()
 	}
 
+	if fw.rowGroupFlushRows > 0 && fw.rowGroupNumRecords() >= fw.rowGroupFlushRows {
+		return fw.FlushRowGroup()
+	}
+
+	if fw.rowGroupTargetSize > 0 && fw.rowGroupNumRecords() > 0 {
+		// like parquet-mr, the row group is flushed once there's no room for another two records
+		// of the average size, so that it doesn't exceed the target.
+		size := fw.estimatedRowGroupSize()
+		if size >= fw.rowGroupTarget()-2*size/fw.rowGroupNumRecords() {
+			return fw.FlushRowGroup()
+		}
+	}
+
 	return nil
 }
 
+// estimatedRowGroupSize estimates the size that the current row group will have in the file,
+// based on the compression ratio of the row groups that were flushed so far.
+func (fw *FileWriter) estimatedRowGroupSize() int64 {
+	size := fw.SchemaWriter.DataSize()
+	if fw.estimatedSize == 0 {
+		return size
+	}
+	return int64(float64(size) * float64(fw.writtenSize) / float64(fw.estimatedSize))
+}
+
+// partRemaining returns the number of bytes from the current position to the next multiple of
+// the row group target size.
+func (fw *FileWriter) partRemaining() int64 {
+	pos := fw.w.Pos()
+	if pos == 0 {
+		pos = int64(len(magic))
+	}
+	return fw.rowGroupTargetSize - pos%fw.rowGroupTargetSize
+}
+
+// rowGroupTarget returns the target size of the current row group, which ends at the next
+// multiple of the row group target size unless the row group gets padded to it.
+func (fw *FileWriter) rowGroupTarget() int64 {
+	if fw.rowGroupMaxPadding <= 0 {
+		return fw.rowGroupTargetSize
+	}
+	if remaining := fw.partRemaining(); remaining > fw.rowGroupMaxPadding {
+		return remaining
+	}
+	return fw.rowGroupTargetSize
+}
+
+// alignRowGroup pads the file to the next multiple of the row group target size if it's less
+// than the maximum padding away.
+func (fw *FileWriter) alignRowGroup() error {
+	if fw.rowGroupTargetSize <= 0 || fw.rowGroupMaxPadding <= 0 {
+		return nil
+	}
+	remaining := fw.partRemaining()
+	if remaining == fw.rowGroupTargetSize || remaining > fw.rowGroupMaxPadding {
+		return nil
+	}
+	return writeFull(fw.w, make([]byte, remaining))
+}
+
+// BeginRowGroup begins a transaction for the records that are added to the current row group
+// until Commit or Rollback is called. Rollback discards these records, e.g. if a batch of
+// records fails a validation partway through, or if AddData returned an error after a record
+// was only added partially. The row group isn't flushed while the transaction is in progress.
+func (fw *FileWriter) BeginRowGroup() error {
+	if fw.tx != nil {
+		return errors.New("transaction is already in progress")
+	}
+	fw.prepareColumns()
+
+	tx := &writerTransaction{
+		numRecords: fw.rowGroupNumRecords(),
+		marks:      make(map[string]columnStoreMark),
+	}
+	for _, col := range fw.Columns() {
+		tx.marks[col.FlatName()] = col.getColumnStore().mark()
+	}
+	if fw.sorting != nil {
+		tx.sorting = fw.sorting.mark()
+	}
+	fw.tx = tx
+
+	return nil
+}
+
+// Commit ends the current transaction and keeps all records that were added since BeginRowGroup
+// was called. The row group is flushed if auto-flush is enabled and the maximum row group size
+// is reached.
+func (fw *FileWriter) Commit() error {
+	if fw.tx == nil {
+		return errors.New("no transaction in progress")
+	}
+	fw.tx = nil
+
+	return fw.autoFlush()
+}
+
+// Rollback ends the current transaction and discards all records that were added since
+// BeginRowGroup was called.
+func (fw *FileWriter) Rollback() error {
+	if fw.tx == nil {
+		return errors.New("no transaction in progress")
+	}
+	tx := fw.tx
+	fw.tx = nil
+
+	for _, col := range fw.Columns() {
+		m, ok := tx.marks[col.FlatName()]
+		if !ok {
+			return fmt.Errorf("column %s was added during the transaction", col.FlatName())
+		}
+		if err := col.getColumnStore().truncate(m); err != nil {
+			return fmt.Errorf("rolling back column %s failed: %w", col.FlatName(), err)
+		}
+	}
+	fw.setNumRecords(tx.numRecords)
+	if fw.sorting != nil {
+		fw.sorting.truncate(tx.sorting)
+	}
+
+	return nil
+}
+
+// columnOrders returns the orders of the statistics of all columns, which are the orders defined
+// by their types. Without them, readers can't rely on the min_value and max_value statistics of
+// columns whose order depends on their logical type, like TIMESTAMP(NANOS). The orders are either
+// set for all columns or for none, so nil is returned if the statistics of any column aren't in
+// the order defined by its type.
+func (fw *FileWriter) columnOrders() []*parquet.ColumnOrder {
+	cols := fw.Columns()
+	orders := make([]*parquet.ColumnOrder, len(cols))
+	for i, col := range cols {
+		if !fw.typeDefinedOrder(col) {
+			return nil
+		}
+		orders[i] = &parquet.ColumnOrder{TYPE_ORDER: parquet.NewTypeDefinedOrder()}
+	}
+	return orders
+}
+
+// typeDefinedOrder returns true if the statistics of the column are in the order defined by its
+// type. This isn't the case for types without a defined order, like INT96 and INTERVAL, and for
+// binary columns whose statistics are computed with another comparator than
+// BinaryComparatorUnsigned.
+func (fw *FileWriter) typeDefinedOrder(col *Column) bool {
+	elem := col.Element()
+	switch elem.GetType() {
+	case parquet.Type_INT96:
+		return false
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		if elem.ConvertedType != nil && elem.GetConvertedType() == parquet.ConvertedType_INTERVAL {
+			return false
+		}
+		return isDecimalElement(elem) || fw.statsOpts.comparator(col) == BinaryComparatorUnsigned
+	}
+	return true
+}
+
 // Close flushes the current row group if necessary, taking the provided
-// options into account, and writes the meta data footer to the file.
+// options into account, writes the meta data footer to the file and lets
+// the writer plugins write their sidecar files.
 // Please be aware that this only finalizes the writing process. If you
 // provided a file as io.Writer when creating the FileWriter, you still need
 // to Close that file handle separately.
 func (fw *FileWriter) Close(opts ...FlushRowGroupOption) error {
-	if len(fw.rowGroups) == 0 || fw.rowGroupNumRecords() > 0 {
+	if fw.tx != nil {
+		return errors.New("can't close file while a transaction is in progress")
+	}
+
+	if len(fw.copiedChunks) > 0 {
+		return fmt.Errorf("only %d of %d column chunks of the current row group were copied", len(fw.copiedChunks), len(fw.Columns()))
+	}
+
+	if len(fw.rowGroups) == 0 || fw.rowGroupNumRecords() > 0 || len(fw.columnBatchRecords) > 0 {
 		if err := fw.FlushRowGroup(opts...); err != nil {
 			return err
 		}
 	}
 
-	kv := make([]*parquet.KeyValue, 0, len(fw.kvStore))
+	keys := make([]string, 0, len(fw.kvStore))
 	for i := range fw.kvStore {
+		keys = append(keys, i)
+	}
+	sort.Strings(keys)
+
+	kv := make([]*parquet.KeyValue, 0, len(fw.kvStore))
+	for _, i := range keys {
+		if fw.writeFingerprint && i == SchemaFingerprintKey {
+			continue
+		}
 		v := fw.kvStore[i]
 		addr := &v
 		if v == "" {
@@ -249,6 +1001,21 @@ func (fw *FileWriter) Close(opts ...FlushRowGroupOption) error {
 			Value: addr,
 		})
 	}
+	if fw.writeFingerprint {
+		fingerprint := fw.SchemaFingerprint()
+		kv = append(kv, &parquet.KeyValue{
+			Key:   SchemaFingerprintKey,
+			Value: &fingerprint,
+		})
+	}
+	if err := writePageIndexes(fw.w, fw.pageIndexes); err != nil {
+		return err
+	}
+
+	if err := writeBloomFilters(fw.w, fw.bloomFilters); err != nil {
+		return err
+	}
+
 	meta := &parquet.FileMetaData{
 		Version:          fw.version,
 		Schema:           fw.getSchemaArray(),
@@ -256,20 +1023,29 @@ func (fw *FileWriter) Close(opts ...FlushRowGroupOption) error {
 		RowGroups:        fw.rowGroups,
 		KeyValueMetadata: kv,
 		CreatedBy:        &fw.createdBy,
-		ColumnOrders:     nil,
+		ColumnOrders:     fw.columnOrders(),
 	}
 
-	pos := fw.w.Pos()
-	if err := writeThrift(meta, fw.w); err != nil {
+	if err := writeFileMetaData(fw.w, meta); err != nil {
 		return err
 	}
 
-	ln := int32(fw.w.Pos() - pos)
-	if err := binary.Write(fw.w, binary.LittleEndian, &ln); err != nil {
+	return fw.writeSidecars(meta)
+}
+
+// writeFileMetaData writes the meta data footer, followed by its length and the file magic.
+func writeFileMetaData(w writePos, meta *parquet.FileMetaData) error {
+	pos := w.Pos()
+	if err := writeThrift(meta, w); err != nil {
 		return err
 	}
 
-	return writeFull(fw.w, magic)
+	ln := int32(w.Pos() - pos)
+	if err := binary.Write(w, binary.LittleEndian, &ln); err != nil {
+		return err
+	}
+
+	return writeFull(w, magic)
 }
 
 // CurrentRowGroupSize returns a rough estimation of the uncompressed size of the current row group data. If you selected
@@ -285,3 +1061,25 @@ func (fw *FileWriter) CurrentRowGroupSize() int64 {
 func (fw *FileWriter) CurrentFileSize() int64 {
 	return fw.w.Pos()
 }
+
+// SetMetaData sets the entry key of the key-value meta data of the file to value, e.g. to embed
+// lineage information, schema registry IDs or an Arrow schema. Entries with an empty value are
+// written without a value. The meta data is written when the file is closed, so entries can be
+// set or updated at any time before.
+func (fw *FileWriter) SetMetaData(key, value string) {
+	fw.kvStore[key] = value
+}
+
+// DeleteMetaData removes the entry key from the key-value meta data of the file.
+func (fw *FileWriter) DeleteMetaData(key string) {
+	delete(fw.kvStore, key)
+}
+
+// MetaData returns a copy of the key-value meta data that will be written to the file.
+func (fw *FileWriter) MetaData() map[string]string {
+	ret := make(map[string]string, len(fw.kvStore))
+	for k, v := range fw.kvStore {
+		ret[k] = v
+	}
+	return ret
+}
@@ -2,10 +2,14 @@ package goparquet
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"math/rand"
 	"testing"
 
+	"github.com/fraugster/parquet-go/parquet"
 	"github.com/fraugster/parquet-go/parquetschema"
 	"github.com/stretchr/testify/require"
 )
@@ -108,3 +112,402 @@ func TestByteReaderSelectedInnerFull(t *testing.T) {
 		require.Empty(t, y)
 	}
 }
+
+func TestFooterLimits(t *testing.T) {
+	data := buildTestStream(t)
+
+	_, err := NewFileReaderWithOptions(bytes.NewReader(data), WithFooterLimits(FooterLimits{MaxFooterSize: 1 << 20, MaxStringSize: 1024, MaxContainerSize: 1024}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		limits FooterLimits
+		limit  string
+	}{
+		{FooterLimits{MaxFooterSize: 100}, "footer size"},
+		{FooterLimits{MaxStringSize: 3}, "string size"},
+		{FooterLimits{MaxContainerSize: 50}, "container size"},
+	}
+
+	for _, tt := range tests {
+		_, err := NewFileReaderWithOptions(bytes.NewReader(data), WithFooterLimits(tt.limits))
+		var limitErr *FooterLimitExceededError
+		require.True(t, errors.As(err, &limitErr), "%s: unexpected error %v", tt.limit, err)
+		require.Equal(t, tt.limit, limitErr.Limit)
+		require.True(t, limitErr.Actual > limitErr.Max)
+	}
+}
+
+func TestFooterWithHugeContainer(t *testing.T) {
+	// version 1, followed by a schema list of 2^31-1 elements.
+	footer := []byte{0x15, 0x02, 0x19, 0xfc, 0xff, 0xff, 0xff, 0xff, 0x07}
+
+	buf := &bytes.Buffer{}
+	buf.Write(magic)
+	buf.Write(footer)
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, int32(len(footer))))
+	buf.Write(magic)
+
+	_, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds the remaining")
+}
+
+func TestSalvageMode(t *testing.T) {
+	testDecompression(t, func(t *testing.T) {
+		sd, err := parquetschema.ParseSchemaDefinition(`message test {
+			required int64 id;
+			optional int64 value;
+		}`)
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd), WithCompressionCodec(parquet.CompressionCodec_GZIP))
+		for i := 0; i < 300; i++ {
+			require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i), "value": int64(i * 2)}))
+			if i%100 == 99 {
+				require.NoError(t, w.FlushRowGroup())
+			}
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+
+		// corrupt the compressed data of the column value in the second row group.
+		meta := r.meta.RowGroups[1].Columns[1].MetaData
+		data := append([]byte{}, buf.Bytes()...)
+		for i := meta.DataPageOffset + meta.TotalCompressedSize - 8; i < meta.DataPageOffset+meta.TotalCompressedSize; i++ {
+			data[i] ^= 0xff
+		}
+
+		r, err = NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		var readErr error
+		for readErr == nil {
+			_, readErr = r.NextRow()
+		}
+		require.NotEqual(t, io.EOF, readErr)
+
+		r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithSalvageMode())
+		require.NoError(t, err)
+
+		var ids []int64
+		for {
+			row, err := r.NextRow()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			ids = append(ids, row["id"].(int64))
+		}
+
+		require.Len(t, ids, 200)
+		require.Equal(t, int64(99), ids[99])
+		require.Equal(t, int64(200), ids[100])
+
+		skipped := r.SkippedRows()
+		require.Len(t, skipped, 1)
+		require.Equal(t, 1, skipped[0].RowGroup)
+		require.Equal(t, int64(100), skipped[0].FirstRow)
+		require.Equal(t, int64(100), skipped[0].NumRows)
+		require.Error(t, skipped[0].Err)
+	})
+}
+
+func TestSalvageModeWithCorruptStatistics(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for i := 0; i < 300; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i)}))
+		if i%100 == 99 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// a min value of the first and the last row group that can't be decoded as int64.
+	meta := r.meta
+	meta.RowGroups[0].Columns[0].MetaData.Statistics.MinValue = []byte{1, 2}
+	meta.RowGroups[2].Columns[0].MetaData.Statistics.MinValue = []byte{1, 2}
+	footer := &bytes.Buffer{}
+	require.NoError(t, writeThrift(meta, footer))
+	footerLen := binary.LittleEndian.Uint32(buf.Bytes()[buf.Len()-8:])
+	corrupt := bytes.NewBuffer(append([]byte{}, buf.Bytes()[:buf.Len()-8-int(footerLen)]...))
+	corrupt.Write(footer.Bytes())
+	require.NoError(t, binary.Write(corrupt, binary.LittleEndian, int32(footer.Len())))
+	corrupt.Write(magic)
+	data := corrupt.Bytes()
+
+	var filtered []int
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithSalvageMode(), WithRowGroupFilter(func(rowGroup int, stats map[string]interface{}) bool {
+		filtered = append(filtered, rowGroup)
+		return true
+	}, "id"))
+	require.NoError(t, err)
+
+	var ids []int64
+	for {
+		row, err := r.NextRow()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		ids = append(ids, row["id"].(int64))
+	}
+
+	require.Len(t, ids, 100)
+	require.Equal(t, int64(100), ids[0])
+	require.Equal(t, []int{1}, filtered)
+
+	skipped := r.SkippedRows()
+	require.Len(t, skipped, 2)
+	for i, rowGroup := range []int{0, 2} {
+		require.Equal(t, rowGroup, skipped[i].RowGroup)
+		require.Equal(t, int64(rowGroup*100), skipped[i].FirstRow)
+		require.Equal(t, int64(100), skipped[i].NumRows)
+		require.Contains(t, skipped[i].Err.Error(), "decoding min value of column id failed")
+	}
+}
+
+func TestExternalColumnChunks(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for i := 0; i < 20; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i), "name": []byte(fmt.Sprint(i % 3))}))
+		if i%10 == 9 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+	dataFile := buf.Bytes()
+
+	r, err := NewFileReader(bytes.NewReader(dataFile))
+	require.NoError(t, err)
+
+	// a summary file that only contains the meta data, which references the data file.
+	meta := r.meta
+	for _, rg := range meta.RowGroups {
+		for _, chunk := range rg.Columns {
+			chunk.FilePath = &[]string{"part-0.parquet"}[0]
+		}
+	}
+	summary := &bytes.Buffer{}
+	summary.Write(magic)
+	footer := &bytes.Buffer{}
+	require.NoError(t, writeThrift(meta, footer))
+	summary.Write(footer.Bytes())
+	require.NoError(t, binary.Write(summary, binary.LittleEndian, int32(footer.Len())))
+	summary.Write(magic)
+
+	r, err = NewFileReader(bytes.NewReader(summary.Bytes()))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+
+	var opened []string
+	r, err = NewFileReaderWithOptions(bytes.NewReader(summary.Bytes()), WithFileOpener(func(path string) (io.ReadSeeker, error) {
+		opened = append(opened, path)
+		if path != "part-0.parquet" {
+			return nil, errors.New("file not found")
+		}
+		return bytes.NewReader(dataFile), nil
+	}))
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"id": int64(i), "name": []byte(fmt.Sprint(i % 3))}, row)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+	require.Len(t, opened, 4)
+
+	report, err := r.Validate(1, "name")
+	require.NoError(t, err)
+	require.True(t, report.Valid(), "%v", report.Problems)
+}
+
+func TestFirstRowIndex(t *testing.T) {
+	r, err := NewFileReader(bytes.NewReader(writeValidateTestFile(t)))
+	require.NoError(t, err)
+
+	for rg, expected := range []int64{0, 100, 200} {
+		idx, err := r.FirstRowIndex(rg)
+		require.NoError(t, err)
+		require.Equal(t, expected, idx)
+	}
+
+	_, err = r.FirstRowIndex(3)
+	require.Error(t, err)
+	_, err = r.FirstRowIndex(-1)
+	require.Error(t, err)
+
+	r.meta.RowGroups[0].NumRows = -1
+	_, err = r.FirstRowIndex(1)
+	require.Error(t, err)
+}
+
+func TestRowGroupFilter(t *testing.T) {
+	data := writeValidateTestFile(t)
+
+	var rowGroups []int
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithRowGroupFilter(func(rowGroup int, stats map[string]interface{}) bool {
+		rowGroups = append(rowGroups, rowGroup)
+		return stats["max_id"].(int64) >= 150 && rowGroup != 2
+	}, "id"))
+	require.NoError(t, err)
+
+	for i := 100; i < 200; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["id"])
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, []int{0, 1, 2}, rowGroups)
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(data), WithRowGroupFilter(func(int, map[string]interface{}) bool { return true }, "unknown"))
+	require.Error(t, err)
+
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Error(t, r.SetRowGroupFilter(func(int, map[string]interface{}) bool { return true }, "unknown"))
+	require.NoError(t, r.SetRowGroupFilter(func(rowGroup int, stats map[string]interface{}) bool {
+		return stats["min_id"].(int64) >= 200
+	}, "id"))
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, int64(200), row["id"])
+}
+
+func TestEvictColumns(t *testing.T) {
+	data := writeValidateTestFile(t)
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	require.Error(t, r.EvictColumns("unknown"))
+
+	for i := 0; i < 50; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Contains(t, row, "tags")
+	}
+
+	require.NoError(t, r.EvictColumns("tags"))
+	col := r.GetColumnByName("tags")
+	require.Equal(t, 0, col.data.dLevels.count)
+	require.Equal(t, int32(0), col.data.values.numValues())
+
+	for i := 50; i < 300; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["id"])
+		require.NotContains(t, row, "tags")
+		if i%4 != 0 {
+			require.Equal(t, []byte{byte('a' + i%5)}, row["name"])
+		}
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestMetaDataAccessors(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	r := &FileReader{meta: &parquet.FileMetaData{KeyValueMetadata: []*parquet.KeyValue{
+		{Key: "a", Value: str("1")},
+		{Key: "b"},
+		{Key: "c", Value: str("")},
+		{Key: "a", Value: str("2")},
+	}}}
+
+	v, ok := r.MetaDataValue("a")
+	require.True(t, ok)
+	require.Equal(t, "2", v)
+
+	_, ok = r.MetaDataValue("b")
+	require.False(t, ok)
+
+	v, ok = r.MetaDataValue("c")
+	require.True(t, ok)
+	require.Equal(t, "", v)
+
+	_, ok = r.MetaDataValue("d")
+	require.False(t, ok)
+
+	require.Equal(t, []string{"a", "b", "c"}, r.MetaDataKeys())
+	require.Equal(t, map[string]string{"a": "2", "c": ""}, r.MetaData())
+}
+
+func TestImplicitRepetitionTypes(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+		required group bar {
+			required binary baz (STRING);
+		}
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"foo": int64(1), "bar": map[string]interface{}{"baz": []byte("a")}}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// write a new footer in which the root is repeated and the other elements have no
+	// repetition type.
+	repeated := parquet.FieldRepetitionType_REPEATED
+	r.meta.Schema[0].RepetitionType = &repeated
+	for _, elem := range r.meta.Schema[1:] {
+		elem.RepetitionType = nil
+	}
+	footerLen := binary.LittleEndian.Uint32(buf.Bytes()[buf.Len()-8:])
+	out := &writePosStruct{w: &bytes.Buffer{}}
+	_, err = out.Write(buf.Bytes()[:buf.Len()-8-int(footerLen)])
+	require.NoError(t, err)
+	require.NoError(t, writeFileMetaData(out, r.meta))
+	data := out.w.(*bytes.Buffer).Bytes()
+
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Nil(t, r.GetSchemaDefinition().RootColumn.SchemaElement.RepetitionType)
+	require.Equal(t, parquet.FieldRepetitionType_REQUIRED, *r.GetColumnByName("bar.baz").RepetitionType())
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"foo": int64(1), "bar": map[string]interface{}{"baz": []byte("a")}}, row)
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(data), WithStrictValidation())
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Equal(t, "test", verr.Column)
+
+	r.meta.Schema[0].RepetitionType = nil
+	r.meta.Schema[3].RepetitionType = nil
+	out = &writePosStruct{w: &bytes.Buffer{}}
+	_, err = out.Write(buf.Bytes()[:buf.Len()-8-int(footerLen)])
+	require.NoError(t, err)
+	require.NoError(t, writeFileMetaData(out, r.meta))
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(out.w.(*bytes.Buffer).Bytes()), WithStrictValidation())
+	require.True(t, errors.As(err, &verr))
+	require.Equal(t, "bar.baz", verr.Column)
+}
@@ -0,0 +1,110 @@
+package goparquet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// SchemaFingerprintKey is the key of the key-value meta data in which the schema fingerprint
+// is stored if the file was written with WithSchemaFingerprint.
+const SchemaFingerprintKey = "parquet-go.schema.fingerprint"
+
+// SchemaFingerprint returns a fingerprint of the schema as hex-encoded SHA-256 hash. Two schemas
+// have the same fingerprint if they contain the same columns in the same order with the same
+// names, types, repetition types and annotations. The name and the repetition type of the root
+// element are ignored, and elements without repetition type are treated as required. Converted
+// types are hashed as their equivalent logical types, as newer writers set both while older
+// writers only set the converted type, so that e.g. UTF8 and STRING columns are the same.
+func (r *schema) SchemaFingerprint() string {
+	return schemaElementsFingerprint(r.getSchemaArray())
+}
+
+// schemaElementsFingerprint returns the fingerprint of the flattened schema elements of a file.
+func schemaElementsFingerprint(elems []*parquet.SchemaElement) string {
+	h := sha256.New()
+	for idx, elem := range elems {
+		writeElementFingerprint(h, elem, idx == 0)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeElementFingerprint(w io.Writer, elem *parquet.SchemaElement, root bool) {
+	if root {
+		fmt.Fprintf(w, "%q", "")
+	} else {
+		// elements without repetition type are read as required, see normalizeRepetitionTypes.
+		fmt.Fprintf(w, "%q rep=%s", elem.Name, elem.GetRepetitionType())
+	}
+	if elem.Type != nil {
+		fmt.Fprintf(w, " type=%s", *elem.Type)
+	}
+	if elem.TypeLength != nil {
+		fmt.Fprintf(w, " length=%d", *elem.TypeLength)
+	}
+	if elem.NumChildren != nil {
+		fmt.Fprintf(w, " children=%d", *elem.NumChildren)
+	}
+
+	if annotation := annotationFingerprint(elem); annotation != "" {
+		fmt.Fprintf(w, " %s", annotation)
+	}
+
+	if elem.FieldID != nil {
+		fmt.Fprintf(w, " id=%d", *elem.FieldID)
+	}
+
+	fmt.Fprintln(w)
+}
+
+// annotationFingerprint returns the logical or converted type of elem in a canonical form, or
+// an empty string if it has neither. Converted types are replaced by their equivalent logical
+// types.
+func annotationFingerprint(elem *parquet.SchemaElement) string {
+	switch lt := parquetschema.LogicalTypeOf(elem); {
+	case lt != nil:
+		return "logical=" + logicalTypeFingerprint(lt)
+	case elem.ConvertedType != nil:
+		if *elem.ConvertedType == parquet.ConvertedType_DECIMAL {
+			return fmt.Sprintf("converted=%s(%d,%d)", *elem.ConvertedType, elem.GetPrecision(), elem.GetScale())
+		}
+		return fmt.Sprintf("converted=%s", *elem.ConvertedType)
+	}
+	return ""
+}
+
+func logicalTypeFingerprint(lt *parquet.LogicalType) string {
+	timeUnit := func(u *parquet.TimeUnit) string {
+		switch {
+		case u == nil:
+			return ""
+		case u.IsSetMILLIS():
+			return "MILLIS"
+		case u.IsSetMICROS():
+			return "MICROS"
+		case u.IsSetNANOS():
+			return "NANOS"
+		}
+		return ""
+	}
+
+	switch {
+	case lt.IsSetDECIMAL():
+		return fmt.Sprintf("DECIMAL(%d,%d)", lt.DECIMAL.Precision, lt.DECIMAL.Scale)
+	case lt.IsSetTIME():
+		return fmt.Sprintf("TIME(%s,%t)", timeUnit(lt.TIME.Unit), lt.TIME.IsAdjustedToUTC)
+	case lt.IsSetTIMESTAMP():
+		return fmt.Sprintf("TIMESTAMP(%s,%t)", timeUnit(lt.TIMESTAMP.Unit), lt.TIMESTAMP.IsAdjustedToUTC)
+	case lt.IsSetINTEGER():
+		return fmt.Sprintf("INTEGER(%d,%t)", lt.INTEGER.BitWidth, lt.INTEGER.IsSigned)
+	case lt.IsSetUNKNOWN():
+		return "UNKNOWN"
+	}
+
+	name, _ := logicalTypePhysicalTypes(lt)
+	return name
+}
@@ -0,0 +1,130 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFingerprint(t *testing.T) {
+	fingerprint := func(schema string) string {
+		sd, err := parquetschema.ParseSchemaDefinition(schema)
+		require.NoError(t, err)
+		return NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd)).SchemaFingerprint()
+	}
+
+	base := fingerprint(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		optional group tags (LIST) {
+			repeated group list {
+				required int32 element;
+			}
+		}
+	}`)
+
+	require.Len(t, base, 64)
+	require.Equal(t, base, fingerprint(`message other {
+		required int64 id;
+		optional binary name (STRING);
+		optional group tags (LIST) {
+			repeated group list {
+				required int32 element;
+			}
+		}
+	}`))
+
+	for name, schema := range map[string]string{
+		"order": `message test {
+			optional binary name (STRING);
+			required int64 id;
+			optional group tags (LIST) {
+				repeated group list {
+					required int32 element;
+				}
+			}
+		}`,
+		"repetition": `message test {
+			optional int64 id;
+			optional binary name (STRING);
+			optional group tags (LIST) {
+				repeated group list {
+					required int32 element;
+				}
+			}
+		}`,
+		"annotation": `message test {
+			required int64 id;
+			optional binary name (JSON);
+			optional group tags (LIST) {
+				repeated group list {
+					required int32 element;
+				}
+			}
+		}`,
+		"nested type": `message test {
+			required int64 id;
+			optional binary name (STRING);
+			optional group tags (LIST) {
+				repeated group list {
+					required int64 element;
+				}
+			}
+		}`,
+	} {
+		require.NotEqual(t, base, fingerprint(schema), name)
+	}
+
+	require.NotEqual(t,
+		fingerprint(`message test { required int64 ts (TIMESTAMP(MILLIS, true)); }`),
+		fingerprint(`message test { required int64 ts (TIMESTAMP(MICROS, true)); }`),
+	)
+
+	// converted types are equivalent to their logical types.
+	require.Equal(t,
+		fingerprint(`message test { optional binary name (STRING); required int32 count (INT(32, true)); }`),
+		fingerprint(`message test { optional binary name (UTF8); required int32 count (INT_32); }`),
+	)
+	require.NotEqual(t,
+		fingerprint(`message test { required int32 count (INT(32, false)); }`),
+		fingerprint(`message test { required int32 count (INT_32); }`),
+	)
+}
+
+func TestSchemaFingerprintRepetitionTypes(t *testing.T) {
+	one := int32(1)
+	elems := func(rootRep, colRep *parquet.FieldRepetitionType) []*parquet.SchemaElement {
+		return []*parquet.SchemaElement{
+			{Name: "test", RepetitionType: rootRep, NumChildren: &one},
+			{Name: "id", RepetitionType: colRep, Type: parquet.TypePtr(parquet.Type_INT64)},
+		}
+	}
+	required := parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
+	optional := parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+
+	base := schemaElementsFingerprint(elems(nil, required))
+	require.Equal(t, base, schemaElementsFingerprint(elems(required, required)))
+	require.Equal(t, base, schemaElementsFingerprint(elems(nil, nil)))
+	require.NotEqual(t, base, schemaElementsFingerprint(elems(nil, optional)))
+}
+
+func TestWriteSchemaFingerprint(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithSchemaFingerprint(), WithMetaData(map[string]string{"foo": "bar"}))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, w.SchemaFingerprint(), r.SchemaFingerprint())
+	require.Equal(t, map[string]string{"foo": "bar", SchemaFingerprintKey: w.SchemaFingerprint()}, r.MetaData())
+}
@@ -36,6 +36,14 @@ function.
 		// ...
 	}
 
+Instead of writing the schema definition by hand, you can also derive it from your Go data structure
+using the SchemaOf function. The names, optionality and logical types of the columns can be controlled
+with parquet struct tags.
+
+	sd, err := floor.SchemaOf(yourRecord{})
+	// ...
+	w, err := floor.NewFileWriter("your-file.parquet", goparquet.WithSchemaDefinition(sd))
+
 By default, floor will use reflection to map your data structure to a parquet schema. Alternatively,
 you can choose to bypass the use of reflection by implementing the floor.Marshaller interface. This is
 especially useful if the structure of your parquet schema doesn't exactly match the structure of your
@@ -7,6 +7,8 @@ import (
 
 var fieldNameFunc = fieldNameToLower
 
+// fieldNameToLower returns the name set in the parquet struct tag of the field, or the lowercase
+// field name if the tag doesn't set a name, e.g. `parquet:",optional"`.
 func fieldNameToLower(field reflect.StructField) string {
 	parquetStructTag, ok := field.Tag.Lookup("parquet")
 	if !ok {
@@ -14,6 +16,9 @@ func fieldNameToLower(field reflect.StructField) string {
 	}
 
 	parquetStructTagFields := strings.Split(parquetStructTag, ",")
+	if name := strings.TrimSpace(parquetStructTagFields[0]); name != "" {
+		return name
+	}
 
-	return strings.TrimSpace(parquetStructTagFields[0])
+	return strings.ToLower(field.Name)
 }
@@ -87,7 +87,7 @@ func (r *Reader) Scan(obj interface{}) error {
 	}
 	um, ok := obj.(interfaces.Unmarshaller)
 	if !ok {
-		um = &reflectUnmarshaller{obj: obj, schemaDef: r.r.GetSchemaDefinition()}
+		um = &reflectUnmarshaller{obj: obj, schemaDef: r.r.RowSchemaDefinition()}
 	}
 
 	return um.UnmarshalParquet(interfaces.NewUnmarshallObject(r.data))
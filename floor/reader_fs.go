@@ -0,0 +1,31 @@
+//go:build go1.16
+// +build go1.16
+
+package floor
+
+import (
+	"io/fs"
+
+	goparquet "github.com/fraugster/parquet-go"
+)
+
+// NewFileReaderFS returns a new high-level parquet file reader that reads from the file name in
+// fsys, e.g. an embedded file system or a zip archive. See goparquet.OpenFS for how the file is
+// read.
+func NewFileReaderFS(fsys fs.FS, name string) (*Reader, error) {
+	f, err := goparquet.OpenFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := goparquet.NewFileReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &Reader{
+		r: r,
+		f: f,
+	}, nil
+}
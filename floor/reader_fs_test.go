@@ -0,0 +1,50 @@
+//go:build go1.16
+// +build go1.16
+
+package floor
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileReaderFS(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test_msg {
+		required int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w := NewWriter(goparquet.NewFileWriter(&buf, goparquet.WithSchemaDefinition(sd)))
+	type testMsg struct {
+		Foo int64
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, w.Write(testMsg{Foo: int64(i)}))
+	}
+	require.NoError(t, w.Close())
+
+	fsys := fstest.MapFS{"test.parquet": &fstest.MapFile{Data: buf.Bytes()}}
+
+	_, err = NewFileReaderFS(fsys, "unknown.parquet")
+	require.Error(t, err)
+
+	r, err := NewFileReaderFS(fsys, "test.parquet")
+	require.NoError(t, err)
+	defer r.Close()
+
+	count := 0
+	for r.Next() {
+		var msg testMsg
+		require.NoError(t, r.Scan(&msg))
+		require.Equal(t, int64(count), msg.Foo)
+		count++
+	}
+	require.NoError(t, r.Err())
+	require.Equal(t, 3, count)
+}
@@ -0,0 +1,409 @@
+package floor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// SchemaOf derives a parquet schema definition from the type of obj, which needs to be a struct
+// or a *struct, so that a writer for objects of this type can be created without writing the
+// schema definition by hand:
+//
+//	sd, err := floor.SchemaOf(yourRecord{})
+//	// ...
+//	w, err := floor.NewFileWriter("your-file.parquet", goparquet.WithSchemaDefinition(sd))
+//
+// The schema matches the mapping of Go types to parquet types that is used by (*Writer).Write and
+// (*Reader).Scan. Every struct field becomes a column whose name is the name set in the parquet
+// struct tag, or the lowercase field name if the tag doesn't set a name. Pointers, maps and slices
+// other than byte slices are optional, all other fields are required. Strings are annotated as
+// STRING, time.Time values are stored as TIMESTAMP(NANOS, true) and Time values as
+// TIME(NANOS, false).
+//
+// The name in the struct tag can be followed by a comma-separated list of options:
+//
+//	optional              the field is optional
+//	required              the field is required
+//	string, json, bson    the string or byte slice is annotated as STRING, JSON or BSON
+//	enum                  the string or byte slice is annotated as ENUM
+//	uuid                  the [16]byte array is annotated as UUID
+//	date                  the time.Time value is stored as DATE
+//	timestamp=<unit>      the time.Time value is stored as TIMESTAMP with the unit millis, micros or nanos
+//	time=<unit>           the Time value is stored as TIME with the unit millis, micros or nanos
+//	id=<field ID>         the column has the field ID, e.g. to resolve it by ID in table formats like Iceberg
+//
+// The options that annotate a type are applied to the elements of slices and to the values of
+// maps, e.g. a []time.Time field with the option date is stored as a LIST of DATE values.
+func SchemaOf(obj interface{}) (*parquetschema.SchemaDefinition, error) {
+	typ := reflect.TypeOf(obj)
+	if typ == nil {
+		return nil, errors.New("object is nil")
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("object needs to be a struct or a *struct, it's a %v instead", typ)
+	}
+
+	b := &schemaBuilder{inProgress: make(map[reflect.Type]bool)}
+	children, err := b.structFields(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(typ.Name())
+	if name == "" {
+		name = "msg"
+	}
+
+	sd := parquetschema.SchemaDefinitionFromColumnDefinition(&parquetschema.ColumnDefinition{
+		Children:      children,
+		SchemaElement: &parquet.SchemaElement{Name: name},
+	})
+	setNumChildren(sd.RootColumn)
+
+	if err := sd.Validate(); err != nil {
+		return nil, fmt.Errorf("derived schema definition is invalid: %w", err)
+	}
+
+	return sd, nil
+}
+
+// schemaBuilder builds the column definitions of struct types.
+type schemaBuilder struct {
+	// inProgress contains the struct types whose fields are currently being built, to detect
+	// recursive types, which can't be represented in a parquet schema.
+	inProgress map[reflect.Type]bool
+}
+
+// fieldOptions are the options of a struct field that are set in its parquet struct tag.
+type fieldOptions struct {
+	repetition *parquet.FieldRepetitionType
+	annotation string
+	unit       string
+	fieldID    *int32
+}
+
+func parseFieldOptions(field reflect.StructField) (*fieldOptions, error) {
+	opts := &fieldOptions{}
+
+	tag, ok := field.Tag.Lookup("parquet")
+	if !ok {
+		return opts, nil
+	}
+
+	tagFields := strings.Split(tag, ",")
+	for _, opt := range tagFields[1:] {
+		opt = strings.TrimSpace(opt)
+		key, value := opt, ""
+		if idx := strings.Index(opt, "="); idx >= 0 {
+			key, value = opt[:idx], opt[idx+1:]
+		}
+
+		switch key {
+		case "":
+		case "optional":
+			opts.repetition = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+		case "required":
+			opts.repetition = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
+		case "string", "json", "bson", "enum", "uuid", "date", "timestamp", "time":
+			if opts.annotation != "" {
+				return nil, fmt.Errorf("field %s has the conflicting options %s and %s", field.Name, opts.annotation, key)
+			}
+			opts.annotation = key
+			if key == "timestamp" || key == "time" {
+				switch value {
+				case "", "nanos", "micros", "millis":
+					opts.unit = value
+				default:
+					return nil, fmt.Errorf("field %s has the invalid unit %q", field.Name, value)
+				}
+			} else if value != "" {
+				return nil, fmt.Errorf("field %s has the invalid option %q", field.Name, opt)
+			}
+		case "id":
+			id, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("field %s has the invalid field ID %q", field.Name, value)
+			}
+			fieldID := int32(id)
+			opts.fieldID = &fieldID
+		default:
+			return nil, fmt.Errorf("field %s has the unknown option %q", field.Name, opt)
+		}
+	}
+
+	return opts, nil
+}
+
+func (b *schemaBuilder) structFields(typ reflect.Type) ([]*parquetschema.ColumnDefinition, error) {
+	if b.inProgress[typ] {
+		return nil, fmt.Errorf("recursive type %s is not supported", typ)
+	}
+	b.inProgress[typ] = true
+	defer delete(b.inProgress, typ)
+
+	var children []*parquetschema.ColumnDefinition
+	names := make(map[string]bool)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		name := fieldNameFunc(field)
+		if names[name] {
+			return nil, fmt.Errorf("field %s of %s uses the column name %s more than once", field.Name, typ, name)
+		}
+		names[name] = true
+
+		opts, err := parseFieldOptions(field)
+		if err != nil {
+			return nil, err
+		}
+
+		col, err := b.column(name, field.Type, opts)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %s: %w", field.Name, typ, err)
+		}
+		if opts.repetition != nil {
+			col.SchemaElement.RepetitionType = opts.repetition
+		}
+		col.SchemaElement.FieldID = opts.fieldID
+
+		children = append(children, col)
+	}
+
+	return children, nil
+}
+
+// column returns the column definition of a value of type typ with the name name.
+func (b *schemaBuilder) column(name string, typ reflect.Type, opts *fieldOptions) (*parquetschema.ColumnDefinition, error) {
+	repetition := parquet.FieldRepetitionType_REQUIRED
+	if typ.Kind() == reflect.Ptr {
+		repetition = parquet.FieldRepetitionType_OPTIONAL
+		typ = typ.Elem()
+	}
+
+	col := &parquetschema.ColumnDefinition{
+		SchemaElement: &parquet.SchemaElement{
+			Name:           name,
+			RepetitionType: parquet.FieldRepetitionTypePtr(repetition),
+		},
+	}
+	elem := col.SchemaElement
+
+	switch {
+	case typ.ConvertibleTo(reflect.TypeOf(Time{})):
+		return col, setTimeType(elem, opts)
+	case typ.ConvertibleTo(reflect.TypeOf(time.Time{})):
+		return col, setTimestampType(elem, opts)
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		elem.Type = parquet.TypePtr(parquet.Type_BOOLEAN)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		elem.Type = parquet.TypePtr(parquet.Type_INT32)
+	case reflect.Int64, reflect.Uint32, reflect.Uint64:
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+	case reflect.Float32:
+		elem.Type = parquet.TypePtr(parquet.Type_FLOAT)
+	case reflect.Float64:
+		elem.Type = parquet.TypePtr(parquet.Type_DOUBLE)
+	case reflect.String:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		if opts.annotation == "" {
+			setLogicalType(elem, "string")
+			return col, nil
+		}
+		return col, setByteArrayAnnotation(elem, opts)
+	case reflect.Array, reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+			if typ.Kind() == reflect.Array {
+				length := int32(typ.Len())
+				elem.Type = parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY)
+				elem.TypeLength = &length
+			}
+			return col, setByteArrayAnnotation(elem, opts)
+		}
+		return col, b.setListType(col, typ, opts)
+	case reflect.Map:
+		return col, b.setMapType(col, typ, opts)
+	case reflect.Struct:
+		if opts.annotation != "" {
+			return nil, fmt.Errorf("option %s is not supported for type %s", opts.annotation, typ)
+		}
+		children, err := b.structFields(typ)
+		if err != nil {
+			return nil, err
+		}
+		col.Children = children
+		return col, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+
+	if opts.annotation != "" {
+		return nil, fmt.Errorf("option %s is not supported for type %s", opts.annotation, typ)
+	}
+
+	return col, nil
+}
+
+func (b *schemaBuilder) setListType(col *parquetschema.ColumnDefinition, typ reflect.Type, opts *fieldOptions) error {
+	element, err := b.column("element", typ.Elem(), opts)
+	if err != nil {
+		return err
+	}
+
+	col.SchemaElement.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+	col.SchemaElement.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_LIST)
+	col.Children = []*parquetschema.ColumnDefinition{
+		{
+			SchemaElement: &parquet.SchemaElement{
+				Name:           "list",
+				RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+			},
+			Children: []*parquetschema.ColumnDefinition{element},
+		},
+	}
+
+	return nil
+}
+
+func (b *schemaBuilder) setMapType(col *parquetschema.ColumnDefinition, typ reflect.Type, opts *fieldOptions) error {
+	key, err := b.column("key", typ.Key(), &fieldOptions{})
+	if err != nil {
+		return err
+	}
+	key.SchemaElement.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
+
+	value, err := b.column("value", typ.Elem(), opts)
+	if err != nil {
+		return err
+	}
+
+	col.SchemaElement.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+	col.SchemaElement.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_MAP)
+	col.Children = []*parquetschema.ColumnDefinition{
+		{
+			SchemaElement: &parquet.SchemaElement{
+				Name:           "key_value",
+				RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+			},
+			Children: []*parquetschema.ColumnDefinition{key, value},
+		},
+	}
+
+	return nil
+}
+
+func setByteArrayAnnotation(elem *parquet.SchemaElement, opts *fieldOptions) error {
+	switch opts.annotation {
+	case "":
+		return nil
+	case "string", "json", "bson", "enum":
+		if elem.GetType() != parquet.Type_BYTE_ARRAY {
+			return fmt.Errorf("option %s is not supported for fixed length byte arrays", opts.annotation)
+		}
+	case "uuid":
+		if elem.GetType() != parquet.Type_FIXED_LEN_BYTE_ARRAY || elem.GetTypeLength() != 16 {
+			return errors.New("option uuid is only supported for [16]byte")
+		}
+	default:
+		return fmt.Errorf("option %s is not supported for strings and byte arrays", opts.annotation)
+	}
+
+	setLogicalType(elem, opts.annotation)
+	return nil
+}
+
+// setLogicalType sets the logical type and the converted type of elem to the annotation.
+func setLogicalType(elem *parquet.SchemaElement, annotation string) {
+	elem.LogicalType = parquet.NewLogicalType()
+	switch annotation {
+	case "string":
+		elem.LogicalType.STRING = parquet.NewStringType()
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+	case "json":
+		elem.LogicalType.JSON = parquet.NewJsonType()
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_JSON)
+	case "bson":
+		elem.LogicalType.BSON = parquet.NewBsonType()
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_BSON)
+	case "enum":
+		elem.LogicalType.ENUM = parquet.NewEnumType()
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM)
+	case "uuid":
+		elem.LogicalType.UUID = parquet.NewUUIDType()
+	}
+}
+
+func setTimestampType(elem *parquet.SchemaElement, opts *fieldOptions) error {
+	elem.LogicalType = parquet.NewLogicalType()
+
+	switch opts.annotation {
+	case "date":
+		elem.Type = parquet.TypePtr(parquet.Type_INT32)
+		elem.LogicalType.DATE = parquet.NewDateType()
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)
+		return nil
+	case "", "timestamp":
+	default:
+		return fmt.Errorf("option %s is not supported for time.Time", opts.annotation)
+	}
+
+	elem.Type = parquet.TypePtr(parquet.Type_INT64)
+	elem.LogicalType.TIMESTAMP = &parquet.TimestampType{IsAdjustedToUTC: true, Unit: parquet.NewTimeUnit()}
+	switch opts.unit {
+	case "millis":
+		elem.LogicalType.TIMESTAMP.Unit.MILLIS = parquet.NewMilliSeconds()
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS)
+	case "micros":
+		elem.LogicalType.TIMESTAMP.Unit.MICROS = parquet.NewMicroSeconds()
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)
+	default:
+		elem.LogicalType.TIMESTAMP.Unit.NANOS = parquet.NewNanoSeconds()
+	}
+
+	return nil
+}
+
+func setTimeType(elem *parquet.SchemaElement, opts *fieldOptions) error {
+	if opts.annotation != "" && opts.annotation != "time" {
+		return fmt.Errorf("option %s is not supported for floor.Time", opts.annotation)
+	}
+
+	elem.Type = parquet.TypePtr(parquet.Type_INT64)
+	elem.LogicalType = parquet.NewLogicalType()
+	elem.LogicalType.TIME = &parquet.TimeType{Unit: parquet.NewTimeUnit()}
+	switch opts.unit {
+	case "millis":
+		elem.Type = parquet.TypePtr(parquet.Type_INT32)
+		elem.LogicalType.TIME.Unit.MILLIS = parquet.NewMilliSeconds()
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MILLIS)
+	case "micros":
+		elem.LogicalType.TIME.Unit.MICROS = parquet.NewMicroSeconds()
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MICROS)
+	default:
+		elem.LogicalType.TIME.Unit.NANOS = parquet.NewNanoSeconds()
+	}
+
+	return nil
+}
+
+func setNumChildren(col *parquetschema.ColumnDefinition) {
+	if nc := int32(len(col.Children)); nc > 0 {
+		col.SchemaElement.NumChildren = &nc
+	}
+	for _, child := range col.Children {
+		setNumChildren(child)
+	}
+}
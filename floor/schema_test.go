@@ -0,0 +1,206 @@
+package floor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaOfNested struct {
+	A int16
+	B *string `parquet:"bee,json"`
+}
+
+type schemaOfRecord struct {
+	ID      int64       `parquet:"id"`
+	Name    string      `parquet:"name,optional"`
+	Data    []byte      `parquet:"data,required"`
+	UUID    [16]byte    `parquet:"uuid,uuid"`
+	Count   *uint64     `parquet:"count"`
+	Created time.Time   `parquet:"created,timestamp=millis"`
+	Days    []time.Time `parquet:"days,date"`
+	Start   Time
+	Attrs   map[string]int32
+	Nested  schemaOfNested
+	Items   []*schemaOfNested
+}
+
+func TestSchemaOf(t *testing.T) {
+	expected, err := parquetschema.ParseSchemaDefinition(`message schemaofrecord {
+		required int64 id;
+		optional binary name (STRING);
+		required binary data;
+		required fixed_len_byte_array(16) uuid (UUID);
+		optional int64 count;
+		required int64 created (TIMESTAMP(MILLIS, true));
+		optional group days (LIST) {
+			repeated group list {
+				required int32 element (DATE);
+			}
+		}
+		required int64 start (TIME(NANOS, false));
+		optional group attrs (MAP) {
+			repeated group key_value {
+				required binary key (STRING);
+				required int32 value;
+			}
+		}
+		required group nested {
+			required int32 a;
+			optional binary bee (JSON);
+		}
+		optional group items (LIST) {
+			repeated group list {
+				optional group element {
+					required int32 a;
+					optional binary bee (JSON);
+				}
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	sd, err := SchemaOf(&schemaOfRecord{})
+	require.NoError(t, err)
+	require.Equal(t, expected.String(), sd.String())
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(goparquet.NewFileWriter(buf, goparquet.WithSchemaDefinition(sd)))
+
+	count := uint64(42)
+	bee := `{"b":1}`
+	records := []schemaOfRecord{
+		{
+			ID:      1,
+			Name:    "foo",
+			Data:    []byte{1, 2, 3},
+			UUID:    [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			Count:   &count,
+			Created: time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC),
+			Days:    []time.Time{time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+			Start:   MustTime(NewTime(1, 2, 3, 4)),
+			Attrs:   map[string]int32{"a": 1},
+			Nested:  schemaOfNested{A: -5, B: &bee},
+			Items:   []*schemaOfNested{{A: 7}},
+		},
+		{
+			ID:      2,
+			Data:    []byte{},
+			Created: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+			Start:   MustTime(NewTime(5, 6, 7, 8)),
+		},
+	}
+	for _, record := range records {
+		require.NoError(t, w.Write(record))
+	}
+	require.NoError(t, w.Close())
+
+	fr, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r := NewReader(fr)
+
+	var read []schemaOfRecord
+	for r.Next() {
+		var record schemaOfRecord
+		require.NoError(t, r.Scan(&record))
+		read = append(read, record)
+	}
+	require.NoError(t, r.Err())
+	require.Equal(t, len(records), len(read))
+	for i := range records {
+		require.True(t, records[i].Created.Equal(read[i].Created))
+		read[i].Created = records[i].Created
+		require.Equal(t, records[i].Start.Nanoseconds(), read[i].Start.Nanoseconds())
+		read[i].Start = records[i].Start
+		for j := range records[i].Days {
+			require.True(t, records[i].Days[j].Equal(read[i].Days[j]))
+			read[i].Days[j] = records[i].Days[j]
+		}
+	}
+	require.Equal(t, records, read)
+}
+
+func TestSchemaOfInvalid(t *testing.T) {
+	type recursive struct {
+		Next *recursive
+	}
+
+	tests := []interface{}{
+		nil,
+		42,
+		struct{ A chan int }{},
+		struct {
+			A int `parquet:"a"`
+			B int `parquet:"a"`
+		}{},
+		struct {
+			A int `parquet:"a,unknown"`
+		}{},
+		struct {
+			A int `parquet:"a,json"`
+		}{},
+		struct {
+			A string `parquet:"a,json,enum"`
+		}{},
+		struct {
+			A [8]byte `parquet:"a,uuid"`
+		}{},
+		struct {
+			A time.Time `parquet:"a,timestamp=seconds"`
+		}{},
+		struct {
+			A Time `parquet:"a,date"`
+		}{},
+		recursive{},
+	}
+
+	for idx, obj := range tests {
+		_, err := SchemaOf(obj)
+		require.Error(t, err, "%d. SchemaOf didn't fail", idx)
+	}
+}
+
+func TestSchemaOfFieldIDs(t *testing.T) {
+	type record struct {
+		ID   int64  `parquet:"id,id=1"`
+		Name string `parquet:"name,optional,id=2"`
+		Tags []string
+	}
+
+	sd, err := SchemaOf(record{})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), sd.SubSchema("id").SchemaElement().GetFieldID())
+	require.Equal(t, int32(2), sd.SubSchema("name").SchemaElement().GetFieldID())
+	require.Nil(t, sd.SubSchema("tags").SchemaElement().FieldID)
+
+	_, err = SchemaOf(struct {
+		A int `parquet:"a,id=x"`
+	}{})
+	require.Error(t, err)
+}
+
+func TestSchemaOfTagWithoutName(t *testing.T) {
+	type record struct {
+		Score int64 `parquet:",optional"`
+		Name  string
+	}
+
+	sd, err := SchemaOf(record{})
+	require.NoError(t, err)
+	require.Equal(t, "message record {\n  optional int64 score;\n  required binary name (STRING);\n}\n", sd.String())
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(goparquet.NewFileWriter(buf, goparquet.WithSchemaDefinition(sd)))
+	require.NoError(t, w.Write(record{Score: 42, Name: "foo"}))
+	require.NoError(t, w.Close())
+
+	r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"score": int64(42), "name": []byte("foo")}, row)
+}
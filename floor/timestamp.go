@@ -0,0 +1,144 @@
+package floor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// TimestampOverflowMode defines how the Writer handles time.Time values that can't be stored
+// exactly in a TIMESTAMP or DATE column, either because they are out of the range that the unit
+// of the column can represent, or because they are more precise than the unit of the column.
+type TimestampOverflowMode int
+
+const (
+	// TimestampOverflowRaw stores the result of the conversion without any checks, so values out
+	// of range wrap around and additional precision is truncated. This is the default.
+	TimestampOverflowRaw TimestampOverflowMode = iota
+	// TimestampOverflowError makes Write return an error for values that are out of range or that
+	// would lose precision, e.g. a time with microseconds in a TIMESTAMP(MILLIS, true) column or a
+	// time that isn't midnight UTC in a DATE column.
+	TimestampOverflowError
+	// TimestampOverflowClamp stores the smallest or largest value that the column can represent
+	// for values out of range. Additional precision is truncated.
+	TimestampOverflowClamp
+)
+
+// WriterOption describes an option function that is applied to a Writer when it is created.
+type WriterOption func(w *Writer)
+
+// WithTimestampOverflowMode sets how time.Time values are handled that can't be stored exactly in
+// the TIMESTAMP or DATE columns. The names of the columns need to be provided in dotted notation.
+// If no columns are provided, the mode is used for all columns that don't have their own mode.
+func WithTimestampOverflowMode(mode TimestampOverflowMode, columns ...string) WriterOption {
+	return func(w *Writer) {
+		if len(columns) == 0 {
+			w.timestampOverflow = mode
+			return
+		}
+		if w.columnTimestampOverflow == nil {
+			w.columnTimestampOverflow = make(map[string]TimestampOverflowMode)
+		}
+		for _, col := range columns {
+			w.columnTimestampOverflow[col] = mode
+		}
+	}
+}
+
+// timestampOverflowModes contains the timestamp overflow modes of a writer for the schema
+// elements of a schema definition.
+type timestampOverflowModes struct {
+	defaultMode TimestampOverflowMode
+	elemModes   map[*parquet.SchemaElement]TimestampOverflowMode
+}
+
+func newTimestampOverflowModes(defaultMode TimestampOverflowMode, columnModes map[string]TimestampOverflowMode, schemaDef *parquetschema.SchemaDefinition) (*timestampOverflowModes, error) {
+	modes := &timestampOverflowModes{defaultMode: defaultMode}
+	if len(columnModes) == 0 {
+		return modes, nil
+	}
+
+	modes.elemModes = make(map[*parquet.SchemaElement]TimestampOverflowMode)
+	for col, mode := range columnModes {
+		sd := schemaDef
+		for _, name := range strings.Split(col, ".") {
+			sd = sd.SubSchema(name)
+			if sd == nil {
+				return nil, fmt.Errorf("timestamp overflow mode set for unknown column %q", col)
+			}
+		}
+		modes.elemModes[sd.SchemaElement()] = mode
+	}
+
+	return modes, nil
+}
+
+func (m *timestampOverflowModes) mode(elem *parquet.SchemaElement) TimestampOverflowMode {
+	if m == nil {
+		return TimestampOverflowRaw
+	}
+	if mode, ok := m.elemModes[elem]; ok {
+		return mode
+	}
+	return m.defaultMode
+}
+
+// timestampValue returns t as the number of units since the Unix epoch.
+func timestampValue(t time.Time, unit time.Duration, mode TimestampOverflowMode) (int64, error) {
+	v, exact, overflow := goparquet.TimeToTimestamp(t, unit)
+
+	switch mode {
+	case TimestampOverflowError:
+		if !exact {
+			return 0, fmt.Errorf("time %s can't be stored without losing precision", t)
+		}
+		if overflow != 0 {
+			return 0, fmt.Errorf("time %s is out of range", t)
+		}
+	case TimestampOverflowClamp:
+		if overflow > 0 {
+			return math.MaxInt64, nil
+		}
+		if overflow < 0 {
+			return math.MinInt64, nil
+		}
+	}
+
+	return v, nil
+}
+
+// dateValue returns t as the number of days since the Unix epoch. Times before the Unix epoch
+// that aren't at midnight UTC are rounded down to the day they're in.
+func dateValue(t time.Time, mode TimestampOverflowMode) (int32, error) {
+	const secondsPerDay = 24 * 60 * 60
+
+	sec := t.Unix()
+	days, rem := sec/secondsPerDay, sec%secondsPerDay
+	if rem < 0 {
+		days--
+	}
+
+	switch mode {
+	case TimestampOverflowError:
+		if rem != 0 || t.Nanosecond() != 0 {
+			return 0, fmt.Errorf("time %s can't be stored as date without losing precision", t)
+		}
+		if days > math.MaxInt32 || days < math.MinInt32 {
+			return 0, fmt.Errorf("time %s is out of range", t)
+		}
+	case TimestampOverflowClamp:
+		if days > math.MaxInt32 {
+			return math.MaxInt32, nil
+		}
+		if days < math.MinInt32 {
+			return math.MinInt32, nil
+		}
+	}
+
+	return int32(days), nil
+}
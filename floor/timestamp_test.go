@@ -0,0 +1,105 @@
+package floor
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampValue(t *testing.T) {
+	inRange := time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	farFuture := time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)
+	farPast := time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		t        time.Time
+		unit     time.Duration
+		mode     TimestampOverflowMode
+		expected int64
+		err      bool
+	}{
+		{inRange, time.Nanosecond, TimestampOverflowError, inRange.UnixNano(), false},
+		{inRange, time.Millisecond, TimestampOverflowError, inRange.UnixNano() / 1000000, false},
+		{inRange, time.Second, TimestampOverflowError, 0, true},
+		{inRange, time.Second, TimestampOverflowClamp, inRange.Unix(), false},
+		{farFuture, time.Nanosecond, TimestampOverflowRaw, farFuture.UnixNano(), false},
+		{farFuture, time.Nanosecond, TimestampOverflowError, 0, true},
+		{farFuture, time.Nanosecond, TimestampOverflowClamp, math.MaxInt64, false},
+		{farFuture, time.Microsecond, TimestampOverflowError, farFuture.Unix() * 1000000, false},
+		{farPast, time.Nanosecond, TimestampOverflowError, 0, true},
+		{farPast, time.Nanosecond, TimestampOverflowClamp, math.MinInt64, false},
+		{farPast, time.Millisecond, TimestampOverflowRaw, farPast.Unix() * 1000, false},
+	}
+
+	for idx, tt := range tests {
+		v, err := timestampValue(tt.t, tt.unit, tt.mode)
+		if tt.err {
+			require.Error(t, err, "%d. timestampValue didn't fail", idx)
+			continue
+		}
+		require.NoError(t, err, "%d. timestampValue failed", idx)
+		require.Equal(t, tt.expected, v, "%d. timestampValue returned wrong value", idx)
+	}
+}
+
+func TestDateValue(t *testing.T) {
+	days, err := dateValue(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), TimestampOverflowError)
+	require.NoError(t, err)
+	require.Equal(t, int32(18263), days)
+
+	days, err = dateValue(time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC), TimestampOverflowRaw)
+	require.NoError(t, err)
+	require.Equal(t, int32(18263), days)
+
+	_, err = dateValue(time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC), TimestampOverflowError)
+	require.Error(t, err)
+
+	// times before the Unix epoch are rounded down to the day they're in.
+	days, err = dateValue(time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC), TimestampOverflowRaw)
+	require.NoError(t, err)
+	require.Equal(t, int32(-1), days)
+
+	days, err = dateValue(time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), TimestampOverflowError)
+	require.NoError(t, err)
+	require.Equal(t, int32(-1), days)
+
+	farFuture := time.Unix(math.MaxInt32*86400*2, 0)
+	_, err = dateValue(farFuture, TimestampOverflowError)
+	require.Error(t, err)
+
+	days, err = dateValue(farFuture, TimestampOverflowClamp)
+	require.NoError(t, err)
+	require.Equal(t, int32(math.MaxInt32), days)
+}
+
+func TestWriteTimestampOverflow(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 ts (TIMESTAMP(NANOS, true));
+		required int64 clamped (TIMESTAMP(NANOS, true));
+	}`)
+	require.NoError(t, err)
+
+	type record struct {
+		TS      time.Time
+		Clamped time.Time
+	}
+
+	farFuture := time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	w := NewWriter(goparquet.NewFileWriter(&bytes.Buffer{}, goparquet.WithSchemaDefinition(sd)),
+		WithTimestampOverflowMode(TimestampOverflowError),
+		WithTimestampOverflowMode(TimestampOverflowClamp, "clamped"),
+	)
+	require.NoError(t, w.Write(record{TS: time.Unix(1, 0), Clamped: farFuture}))
+	require.Error(t, w.Write(record{TS: farFuture, Clamped: farFuture}))
+
+	w = NewWriter(goparquet.NewFileWriter(&bytes.Buffer{}, goparquet.WithSchemaDefinition(sd)),
+		WithTimestampOverflowMode(TimestampOverflowClamp, "unknown"),
+	)
+	require.Error(t, w.Write(record{}))
+}
@@ -16,10 +16,14 @@ import (
 )
 
 // NewWriter creates a new high-level writer for parquet.
-func NewWriter(w *goparquet.FileWriter) *Writer {
-	return &Writer{
+func NewWriter(w *goparquet.FileWriter, opts ...WriterOption) *Writer {
+	hlw := &Writer{
 		w: w,
 	}
+	for _, opt := range opts {
+		opt(hlw)
+	}
+	return hlw
 }
 
 // NewFileWriter creates a nigh high-level writer for parquet
@@ -42,6 +46,12 @@ func NewFileWriter(file string, opts ...goparquet.FileWriterOption) (*Writer, er
 type Writer struct {
 	w *goparquet.FileWriter
 	f io.Closer
+
+	timestampOverflow       TimestampOverflowMode
+	columnTimestampOverflow map[string]TimestampOverflowMode
+	// timestampModes is built from the timestamp overflow options when the first object is
+	// written.
+	timestampModes *timestampOverflowModes
 }
 
 // Write adds a new object to be written to the parquet file. If
@@ -51,7 +61,15 @@ type Writer struct {
 func (w *Writer) Write(obj interface{}) error {
 	m, ok := obj.(interfaces.Marshaller)
 	if !ok {
-		m = &reflectMarshaller{obj: obj, schemaDef: w.w.GetSchemaDefinition()}
+		schemaDef := w.w.GetSchemaDefinition()
+		if w.timestampModes == nil {
+			timestampModes, err := newTimestampOverflowModes(w.timestampOverflow, w.columnTimestampOverflow, schemaDef)
+			if err != nil {
+				return err
+			}
+			w.timestampModes = timestampModes
+		}
+		m = &reflectMarshaller{obj: obj, schemaDef: schemaDef, timestampModes: w.timestampModes}
 	}
 
 	data := interfaces.NewMarshallObjectWithSchema(nil, w.w.GetSchemaDefinition())
@@ -67,8 +85,9 @@ func (w *Writer) Write(obj interface{}) error {
 }
 
 type reflectMarshaller struct {
-	obj       interface{}
-	schemaDef *parquetschema.SchemaDefinition
+	obj            interface{}
+	schemaDef      *parquetschema.SchemaDefinition
+	timestampModes *timestampOverflowModes
 }
 
 func (m *reflectMarshaller) MarshalParquet(record interfaces.MarshalObject) error {
@@ -131,19 +150,21 @@ func (m *reflectMarshaller) decodeTimeValue(elem *parquet.SchemaElement, field i
 }
 
 func (m *reflectMarshaller) decodeTimestampValue(elem *parquet.SchemaElement, field interfaces.MarshalElement, value reflect.Value) error {
-	var factor int64
+	var unit time.Duration
 	switch {
 	case elem.GetLogicalType().TIMESTAMP.Unit.IsSetNANOS():
-		factor = 1
+		unit = time.Nanosecond
 	case elem.GetLogicalType().TIMESTAMP.Unit.IsSetMICROS():
-		factor = 1000
+		unit = time.Microsecond
 	case elem.GetLogicalType().TIMESTAMP.Unit.IsSetMILLIS():
-		factor = 1000000
+		unit = time.Millisecond
 	default:
 		return errors.New("invalid TIMESTAMP unit")
 	}
-	ts := value.Interface().(time.Time).UnixNano()
-	ts /= factor
+	ts, err := timestampValue(value.Interface().(time.Time), unit, m.timestampModes.mode(elem))
+	if err != nil {
+		return fmt.Errorf("field %s: %w", elem.GetName(), err)
+	}
 	field.SetInt64(ts)
 	return nil
 }
@@ -166,7 +187,10 @@ func (m *reflectMarshaller) decodeValue(field interfaces.MarshalElement, value r
 		if elem := schemaDef.SchemaElement(); elem.LogicalType != nil {
 			switch {
 			case elem.GetLogicalType().IsSetDATE():
-				days := int32(value.Interface().(time.Time).Sub(time.Unix(0, 0).UTC()).Hours() / 24)
+				days, err := dateValue(value.Interface().(time.Time), m.timestampModes.mode(elem))
+				if err != nil {
+					return fmt.Errorf("field %s: %w", elem.GetName(), err)
+				}
 				field.SetInt32(days)
 				return nil
 			case elem.GetLogicalType().IsSetTIMESTAMP():
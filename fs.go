@@ -0,0 +1,64 @@
+//go:build go1.16
+// +build go1.16
+
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// OpenFS opens the file name in fsys so that it can be read using a FileReader. Files that
+// implement io.Seeker are used as-is, files that implement io.ReaderAt are read through an
+// io.SectionReader. Other files, e.g. the files of a zip archive, can only be read sequentially,
+// so they're read into memory completely. The returned file needs to be closed by the caller.
+func OpenFS(fsys fs.FS, name string) (io.ReadSeekCloser, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if rs, ok := f.(io.ReadSeekCloser); ok {
+		return rs, nil
+	}
+
+	if ra, ok := f.(io.ReaderAt); ok {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, errors.Wrapf(err, "stat of %s failed", name)
+		}
+		return &fsFile{ReadSeeker: io.NewSectionReader(ra, 0, info.Size()), closer: f}, nil
+	}
+
+	data, err := ioutil.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s failed", name)
+	}
+	return &fsFile{ReadSeeker: bytes.NewReader(data)}, nil
+}
+
+// FSFileOpener returns a FileOpener that opens the files of column chunks in fsys using OpenFS.
+func FSFileOpener(fsys fs.FS) FileOpener {
+	return func(path string) (io.ReadSeeker, error) {
+		return OpenFS(fsys, path)
+	}
+}
+
+// fsFile is a file of an fs.FS that doesn't implement io.Seeker itself.
+type fsFile struct {
+	io.ReadSeeker
+	closer io.Closer
+}
+
+func (f *fsFile) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+	return f.closer.Close()
+}
@@ -0,0 +1,76 @@
+//go:build go1.16
+// +build go1.16
+
+package goparquet
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readerAtFS only exposes the io.ReaderAt capability of the files of the underlying file system.
+type readerAtFS struct {
+	fs.FS
+}
+
+type readerAtFile struct {
+	fs.File
+	io.ReaderAt
+}
+
+func (r readerAtFS) Open(name string) (fs.File, error) {
+	f, err := r.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return readerAtFile{File: f, ReaderAt: f.(io.ReaderAt)}, nil
+}
+
+func TestOpenFS(t *testing.T) {
+	data := writeValidateTestFile(t)
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	fw, err := zw.Create("dir/test.parquet")
+	require.NoError(t, err)
+	_, err = fw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	zr, err := zip.NewReader(bytes.NewReader(zipped.Bytes()), int64(zipped.Len()))
+	require.NoError(t, err)
+
+	mapFS := fstest.MapFS{"dir/test.parquet": &fstest.MapFile{Data: data}}
+
+	tests := map[string]fs.FS{
+		"seeker":    mapFS,
+		"reader_at": readerAtFS{mapFS},
+		"zip":       zr,
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := OpenFS(fsys, "dir/test.parquet")
+			require.NoError(t, err)
+			defer f.Close()
+
+			r, err := NewFileReader(f)
+			require.NoError(t, err)
+			for i := 0; i < 300; i++ {
+				row, err := r.NextRow()
+				require.NoError(t, err)
+				require.Equal(t, int64(i), row["id"])
+			}
+			_, err = r.NextRow()
+			require.Equal(t, io.EOF, err)
+		})
+	}
+
+	_, err = OpenFS(mapFS, "dir/unknown.parquet")
+	require.Error(t, err)
+}
@@ -1,10 +1,9 @@
 package goparquet
 
 import (
-	"encoding/binary"
+	"fmt"
 	"hash/fnv"
 	"io"
-	"math"
 	"math/bits"
 
 	"github.com/apache/thrift/lib/go/thrift"
@@ -34,6 +33,18 @@ func (br *byteReader) ReadByte() (byte, error) {
 	return buf[0], nil
 }
 
+// countingReader counts the bytes that are read from r.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
 type offsetReader struct {
 	inner  io.ReadSeeker
 	offset int64
@@ -61,23 +72,6 @@ func (o *offsetReader) Count() int64 {
 	return o.count
 }
 
-func decodeRLEValue(bytes []byte) int32 {
-	switch len(bytes) {
-	case 0:
-		return 0
-	case 1:
-		return int32(bytes[0])
-	case 2:
-		return int32(bytes[0]) + int32(bytes[1])<<8
-	case 3:
-		return int32(bytes[0]) + int32(bytes[1])<<8 + int32(bytes[2])<<16
-	case 4:
-		return int32(bytes[0]) + int32(bytes[1])<<8 + int32(bytes[2])<<16 + int32(bytes[3])<<24
-	default:
-		panic("invalid argument")
-	}
-}
-
 func writeFull(w io.Writer, buf []byte) error {
 	if len(buf) == 0 {
 		return nil
@@ -146,65 +140,6 @@ func decodePackedArray(d levelDecoder, count int) (*packedArray, int, error) {
 	return ret, nn, nil
 }
 
-func readUVariant32(r io.Reader) (int32, error) {
-	b, ok := r.(io.ByteReader)
-	if !ok {
-		b = &byteReader{Reader: r}
-	}
-
-	i, err := binary.ReadUvarint(b)
-	if err != nil {
-		return 0, err
-	}
-
-	if i > math.MaxInt32 {
-		return 0, errors.New("int32 out of range")
-	}
-
-	return int32(i), nil
-}
-
-func readVariant32(r io.Reader) (int32, error) {
-	b, ok := r.(io.ByteReader)
-	if !ok {
-		b = &byteReader{Reader: r}
-	}
-
-	i, err := binary.ReadVarint(b)
-	if err != nil {
-		return 0, err
-	}
-
-	if i > math.MaxInt32 || i < math.MinInt32 {
-		return 0, errors.New("int32 out of range")
-	}
-
-	return int32(i), nil
-}
-
-func writeVariant(w io.Writer, in int64) error {
-	buf := make([]byte, 12)
-	n := binary.PutVarint(buf, in)
-
-	return writeFull(w, buf[:n])
-}
-
-func writeUVariant(w io.Writer, in uint64) error {
-	buf := make([]byte, 12)
-	n := binary.PutUvarint(buf, in)
-
-	return writeFull(w, buf[:n])
-}
-
-func readVariant64(r io.Reader) (int64, error) {
-	b, ok := r.(io.ByteReader)
-	if !ok {
-		b = &byteReader{Reader: r}
-	}
-
-	return binary.ReadVarint(b)
-}
-
 type constDecoder int32
 
 func (cd constDecoder) initSize(io.Reader) error {
@@ -222,6 +157,18 @@ func (cd constDecoder) next() (int32, error) {
 type levelDecoderWrapper struct {
 	decoder
 	max uint16
+
+	// if validate is set, levels larger than max are rejected.
+	validate bool
+	column   string
+}
+
+func (l *levelDecoderWrapper) next() (int32, error) {
+	v, err := l.decoder.next()
+	if err == nil && l.validate && (v < 0 || v > int32(l.max)) {
+		return 0, &ValidationError{Column: l.column, Reason: fmt.Sprintf("level %d exceeds the maximum level %d", v, l.max)}
+	}
+	return v, err
 }
 
 func (l *levelDecoderWrapper) maxLevel() uint16 {
@@ -229,19 +176,6 @@ func (l *levelDecoderWrapper) maxLevel() uint16 {
 }
 
 // check the b2 into b1 to find the max prefix len
-func prefix(b1, b2 []byte) int {
-	l := len(b1)
-	if l2 := len(b2); l > l2 {
-		l = l2
-	}
-	for i := 0; i < l; i++ {
-		if b1[i] != b2[i] {
-			return i
-		}
-	}
-
-	return l
-}
 
 func encodeValue(w io.Writer, enc valuesEncoder, all []interface{}) error {
 	if err := enc.init(w); err != nil {
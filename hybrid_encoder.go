@@ -4,15 +4,16 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+
+	"github.com/fraugster/parquet-go/parquetencoding"
 )
 
 type hybridEncoder struct {
 	w io.Writer
 
-	left       []int32
-	original   io.Writer
-	bitWidth   int
-	unpackerFn pack8int32Func
+	left     []int32
+	original io.Writer
+	bitWidth int
 
 	data *packedArray
 }
@@ -20,9 +21,8 @@ type hybridEncoder struct {
 func newHybridEncoder(bitWidth int) *hybridEncoder {
 	p := &packedArray{}
 	return &hybridEncoder{
-		bitWidth:   bitWidth,
-		unpackerFn: pack8Int32FuncByWidth[bitWidth],
-		data:       p,
+		bitWidth: bitWidth,
+		data:     p,
 	}
 }
 
@@ -52,23 +52,6 @@ func (he *hybridEncoder) write(items ...[]byte) error {
 	return nil
 }
 
-func (he *hybridEncoder) bpEncode() error {
-	// If the bit width is zero, no need to write any
-	if he.bitWidth == 0 {
-		return nil
-	}
-	l := he.data.count
-	if x := l % 8; x != 0 {
-		l += 8 - x
-	}
-
-	header := ((l / 8) << 1) | 1
-	buf := make([]byte, 4) // big enough for int
-	cnt := binary.PutUvarint(buf, uint64(header))
-
-	return he.write(buf[:cnt], he.data.data)
-}
-
 func (he *hybridEncoder) encode(data []int32) error {
 	for i := range data {
 		he.data.appendSingle(data[i])
@@ -83,9 +66,22 @@ func (he *hybridEncoder) encodePacked(data *packedArray) error {
 	return nil
 }
 
+// flush writes all values using the RLE/bit-packing hybrid encoding of the parquetencoding
+// package.
 func (he *hybridEncoder) flush() error {
+	// If the bit width is zero, no need to write any
+	if he.bitWidth == 0 {
+		return nil
+	}
 	he.data.flush()
-	return he.bpEncode()
+
+	values := make([]int32, 0, he.data.count+8)
+	for block := 0; len(values) < he.data.count; block += he.bitWidth {
+		unpacked := he.data.reader(he.data.data[block : block+he.bitWidth])
+		values = append(values, unpacked[:]...)
+	}
+
+	return parquetencoding.EncodeHybrid(he.w, he.bitWidth, values[:he.data.count])
 }
 
 func (he *hybridEncoder) Close() error {
@@ -2,10 +2,12 @@ package goparquet
 
 import (
 	"bytes"
+	"io"
 	"math"
 	"math/rand"
 	"testing"
 
+	"github.com/fraugster/parquet-go/parquetencoding"
 	"github.com/stretchr/testify/require"
 
 	"github.com/stretchr/testify/assert"
@@ -76,3 +78,73 @@ func TestOnlyOne(t *testing.T) {
 	require.NoError(t, decodeInt32(dec, read))
 	require.Equal(t, data.toArray(), read)
 }
+
+func TestHybridCompatibility(t *testing.T) {
+	for bw := 0; bw <= 32; bw++ {
+		values := buildData(bw, 1005)
+
+		data := &bytes.Buffer{}
+		enc := newHybridEncoder(bw)
+		require.NoError(t, enc.initSize(data))
+		require.NoError(t, enc.encode(values))
+		require.NoError(t, enc.Close())
+
+		pdec, err := parquetencoding.NewHybridDecoderWithLength(data, bw)
+		require.NoError(t, err)
+		decoded := make([]int32, len(values))
+		_, err = pdec.Decode(decoded)
+		require.NoError(t, err)
+		require.Equal(t, values, decoded, "bit width %d", bw)
+
+		data.Reset()
+		require.NoError(t, parquetencoding.EncodeHybridWithLength(data, bw, values))
+		dec := newHybridDecoder(bw)
+		require.NoError(t, dec.initSize(data))
+		require.NoError(t, decodeInt32(dec, decoded))
+		require.Equal(t, values, decoded, "bit width %d", bw)
+	}
+}
+
+func TestHybridEncoderRuns(t *testing.T) {
+	var values []int32
+	for i := 0; i < 1000; i++ {
+		values = append(values, 1)
+	}
+
+	data := &bytes.Buffer{}
+	enc := newHybridEncoder(1)
+	require.NoError(t, enc.init(data))
+	require.NoError(t, enc.encode(values))
+	require.NoError(t, enc.Close())
+	// a single RLE run with a header of 2 bytes and a value of 1 byte.
+	require.Equal(t, []byte{0xd0, 0x0f, 0x01}, data.Bytes())
+
+	values = values[:0]
+	for i := 0; i < 1005; i++ {
+		switch {
+		case i < 13:
+			values = append(values, int32(i%4))
+		case i < 500:
+			values = append(values, 2)
+		default:
+			values = append(values, int32(i%3))
+		}
+	}
+
+	data.Reset()
+	enc = newHybridEncoder(2)
+	require.NoError(t, enc.init(data))
+	require.NoError(t, enc.encode(values))
+	require.NoError(t, enc.Close())
+	require.Less(t, data.Len(), 150)
+
+	dec := newHybridDecoder(2)
+	require.NoError(t, dec.init(bytes.NewReader(data.Bytes())))
+	for i := range values {
+		v, err := dec.next()
+		require.NoError(t, err)
+		require.Equal(t, values[i], v, "value %d", i)
+	}
+	_, err := dec.next()
+	require.Equal(t, io.EOF, err)
+}
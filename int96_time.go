@@ -12,6 +12,10 @@ const (
 
 func timeToJD(t time.Time) (uint32, uint64) {
 	days := t.Unix() / secPerDay
+	if t.Unix() < 0 && t.Unix()%secPerDay != 0 {
+		// round towards the beginning of the day for times before the Unix epoch.
+		days--
+	}
 	nSecs := t.UnixNano() - (days * secPerDay * int64(time.Second))
 
 	// unix time starts from Jan 1, 1970 AC, this day is 2440588 day after the Jan 1, 4713 BC
@@ -19,13 +23,12 @@ func timeToJD(t time.Time) (uint32, uint64) {
 }
 
 func jdToTime(jd uint32, nsec uint64) time.Time {
-	sec := int64(jd-jan011970) * secPerDay
+	sec := (int64(jd) - jan011970) * secPerDay
 	return time.Unix(sec, int64(nsec))
 }
 
 // Int96ToTime is a utility function to convert a Int96 Julian Date timestamp (https://en.wikipedia.org/wiki/Julian_day) to a time.Time.
-// Please be aware that this function is limited to timestamps after the Unix epoch (Jan 01 1970 00:00:00 UTC) and cannot
-// convert timestamps before that. The returned time does not contain a monotonic clock reading and is in the machine's current time zone.
+// The returned time does not contain a monotonic clock reading and is in the machine's current time zone.
 func Int96ToTime(parquetDate [12]byte) time.Time {
 	nano := binary.LittleEndian.Uint64(parquetDate[:8])
 	dt := binary.LittleEndian.Uint32(parquetDate[8:])
@@ -34,8 +37,6 @@ func Int96ToTime(parquetDate [12]byte) time.Time {
 }
 
 // TimeToInt96 is a utility function to convert a time.Time to an Int96 Julian Date timestamp (https://en.wikipedia.org/wiki/Julian_day).
-// Please be aware that this function is limited to timestamps after the Unix epoch (Jan 01 1970 00:00:00 UTC) and cannot convert
-// timestamps before that.
 func TimeToInt96(t time.Time) [12]byte {
 	var parquetDate [12]byte
 	days, nSecs := timeToJD(t)
@@ -44,3 +45,12 @@ func TimeToInt96(t time.Time) [12]byte {
 
 	return parquetDate
 }
+
+// WithInt96Conversion makes NextRow return the values of INT96 columns as time.Time values in UTC
+// instead of [12]byte, as INT96 is used by Impala, Hive and Spark to store timestamps as the
+// nanoseconds of the day and the Julian day. Repeated INT96 columns are returned as []time.Time.
+func WithInt96Conversion() FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.int96Conversion = true
+	}
+}
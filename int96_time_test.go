@@ -1,9 +1,11 @@
 package goparquet
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
+	"github.com/fraugster/parquet-go/parquetschema"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,6 +19,8 @@ func TestConvert(t *testing.T) {
 		now.Add(-240 * time.Hour),
 		now.Add(-2400 * time.Hour),
 		now.Add(-24000 * time.Hour),
+		time.Date(1969, 12, 31, 23, 59, 59, 999999999, time.Local),
+		time.Date(1900, 1, 1, 12, 0, 0, 0, time.Local),
 	}
 
 	for i := range arr {
@@ -32,3 +36,25 @@ func TestConvert(t *testing.T) {
 	expected := time.Date(2000, 1, 1, 12, 34, 56, 0, time.UTC)
 	require.Equal(t, expected, ts.UTC())
 }
+
+func TestInt96Conversion(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int96 ts;
+		repeated int96 history;
+	}`)
+	require.NoError(t, err)
+
+	ts := time.Date(2000, 1, 1, 12, 34, 56, 789, time.UTC)
+	old := time.Date(1955, 2, 24, 19, 15, 0, 0, time.UTC)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"ts": TimeToInt96(ts), "history": [][12]byte{TimeToInt96(old), TimeToInt96(ts)}}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithInt96Conversion())
+	require.NoError(t, err)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"ts": ts, "history": []time.Time{old, ts}}, row)
+}
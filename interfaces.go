@@ -11,9 +11,17 @@ type pageReader interface {
 	init(dDecoder, rDecoder getLevelDecoder, values getValueDecoderFn) error
 	read(r io.Reader, ph *parquet.PageHeader, codec parquet.CompressionCodec) error
 
-	readValues([]interface{}) (n int, dLevel *packedArray, rLevel *packedArray, err error)
+	// readValues decodes the levels of up to len(values) values and the non-null values among
+	// them. It returns the number of decoded levels n, and the number of non-null values notNull,
+	// which are stored at the beginning of values.
+	readValues(values []interface{}) (n, notNull int, dLevel *packedArray, rLevel *packedArray, err error)
 
 	numValues() int32
+	// numNulls returns the number of null values in the page if the page header provides it, and
+	// 0 otherwise.
+	numNulls() int32
+	// valuesEncoding returns the encoding of the values of the page.
+	valuesEncoding() parquet.Encoding
 }
 
 // pageReader is an internal interface used only internally to read the pages
@@ -23,7 +31,14 @@ type pageWriter interface {
 	write(w io.Writer) (int, int, error)
 }
 
-type newDataPageFunc func(useDict bool) pageWriter
+// dataPageWriter is a pageWriter that writes a part of the column chunk to a data page
+type dataPageWriter interface {
+	pageWriter
+
+	setPage(page *dataPage)
+}
+
+type newDataPageFunc func(useDict bool, writeCRC bool) dataPageWriter
 
 type valuesDecoder interface {
 	init(io.Reader) error
@@ -60,9 +75,6 @@ type parquetColumn interface {
 type typedColumnStore interface {
 	parquetColumn
 	reset(repetitionType parquet.FieldRepetitionType)
-	// Min and Max in parquet byte
-	maxValue() []byte
-	minValue() []byte
 
 	// Should extract the value, turn it into an array and check for min and max on all values in this
 	getValues(v interface{}) ([]interface{}, error)
@@ -0,0 +1,427 @@
+package rowfilter
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// Op is the comparison operator of a filter.
+type Op int
+
+const (
+	// Eq matches values that are equal to the value of the filter.
+	Eq Op = iota
+	// Ne matches values that are not equal to the value of the filter.
+	Ne
+	// Lt matches values that are less than the value of the filter.
+	Lt
+	// Le matches values that are less than or equal to the value of the filter.
+	Le
+	// Gt matches values that are greater than the value of the filter.
+	Gt
+	// Ge matches values that are greater than or equal to the value of the filter.
+	Ge
+)
+
+// Filter is a predicate on the value of a single non-repeated column. Rows in which the column is
+// null never match.
+//
+// The value of the filter is parsed according to the type of the column. DECIMAL values are
+// compared by their decimal value, e.g. 10.5, DATE values are provided as 2006-01-02 and TIMESTAMP
+// values in RFC 3339 format, or without time zone for timestamps that aren't adjusted to UTC.
+// UUID values are provided in their canonical form and the values of other FIXED_LEN_BYTE_ARRAY
+// columns as hexadecimal strings. Binary values are compared in the order of the column's binary
+// comparator, see goparquet.WithPruningBinaryComparator.
+type Filter struct {
+	// Column is the name of the column in dotted notation.
+	Column string
+	// Op is the comparison operator.
+	Op Op
+	// Value is the value the column is compared to, as text.
+	Value string
+
+	path  []string
+	value interface{}
+	// normalize converts the values of the column and its statistics to the type of value. It
+	// returns nil if a value can't be converted.
+	normalize func(v interface{}) interface{}
+	// cmp is the order in which binary values are compared, which is also the order of the
+	// statistics that are used to skip row groups.
+	cmp goparquet.BinaryComparator
+}
+
+// init checks that the filter can be applied to col and parses the value of the filter. Binary
+// values are compared using cmp.
+func (f *Filter) init(col *goparquet.Column, cmp goparquet.BinaryComparator) error {
+	if col == nil {
+		return f.errorf("filter column %q not found", f.Column)
+	}
+	if !col.DataColumn() {
+		return f.errorf("filter column %q is a group", f.Column)
+	}
+	if col.MaxRepetitionLevel() > 0 {
+		return f.errorf("filter column %q is repeated", f.Column)
+	}
+
+	elem := col.Element()
+	lt := parquetschema.LogicalTypeOf(elem)
+	if lt != nil && lt.IsSetINTEGER() && !lt.INTEGER.IsSigned {
+		return f.errorf("filter column %q is unsigned", f.Column)
+	}
+
+	f.normalize = func(v interface{}) interface{} { return v }
+
+	var err error
+	switch {
+	case lt != nil && lt.IsSetDECIMAL():
+		err = f.initDecimal(elem.GetType(), lt.DECIMAL.Scale)
+	case lt != nil && lt.IsSetDATE() && elem.GetType() == parquet.Type_INT32:
+		err = f.initDate()
+	case lt != nil && lt.IsSetTIMESTAMP() && elem.GetType() == parquet.Type_INT64:
+		err = f.initTimestamp(lt.TIMESTAMP)
+	case lt != nil && lt.IsSetUUID() && elem.GetType() == parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		err = f.initUUID()
+	default:
+		err = f.initPhysical(elem)
+	}
+	if err != nil {
+		return err
+	}
+
+	f.path = strings.Split(f.Column, ".")
+	f.cmp = cmp
+
+	return nil
+}
+
+// initPhysical parses the value of the filter according to the physical type of the column.
+func (f *Filter) initPhysical(elem *parquet.SchemaElement) error {
+	var err error
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN:
+		f.value, err = strconv.ParseBool(f.Value)
+	case parquet.Type_INT32:
+		var v int64
+		v, err = strconv.ParseInt(f.Value, 10, 32)
+		f.value = int32(v)
+	case parquet.Type_INT64:
+		f.value, err = strconv.ParseInt(f.Value, 10, 64)
+	case parquet.Type_FLOAT:
+		var v float64
+		v, err = strconv.ParseFloat(f.Value, 32)
+		f.value = float32(v)
+	case parquet.Type_DOUBLE:
+		f.value, err = strconv.ParseFloat(f.Value, 64)
+	case parquet.Type_BYTE_ARRAY:
+		f.value = []byte(f.Value)
+	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		var v []byte
+		v, err = hex.DecodeString(f.Value)
+		if err == nil && len(v) != int(elem.GetTypeLength()) {
+			return f.errorf("value %q for filter column %q needs to have %d bytes", f.Value, f.Column, elem.GetTypeLength())
+		}
+		f.value = v
+	default:
+		return f.errorf("filters on columns of type %s are not supported", elem.GetType())
+	}
+	if err != nil {
+		return f.invalidValue()
+	}
+	return nil
+}
+
+// initDecimal parses the value of the filter as a decimal. The values of the column, which are
+// unscaled integers of the physical type typ, are converted to their decimal value.
+func (f *Filter) initDecimal(typ parquet.Type, scale int32) error {
+	d, err := goparquet.ParseDecimal(f.Value)
+	if err != nil {
+		return f.invalidValue()
+	}
+	f.value = decimalRat(d.Unscaled, d.Scale)
+
+	f.normalize = func(v interface{}) interface{} {
+		var unscaled *big.Int
+		switch x := v.(type) {
+		case int32:
+			unscaled = big.NewInt(int64(x))
+		case int64:
+			unscaled = big.NewInt(x)
+		case []byte:
+			if typ != parquet.Type_BYTE_ARRAY && typ != parquet.Type_FIXED_LEN_BYTE_ARRAY {
+				return nil
+			}
+			unscaled = bytesToBigInt(x)
+		default:
+			return nil
+		}
+		return decimalRat(unscaled, scale)
+	}
+
+	return nil
+}
+
+// decimalRat returns the rational number unscaled * 10^-scale.
+func decimalRat(unscaled *big.Int, scale int32) *big.Rat {
+	r := new(big.Rat).SetInt(unscaled)
+	factor := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(scale))), nil))
+	if scale >= 0 {
+		return r.Quo(r, factor)
+	}
+	return r.Mul(r, factor)
+}
+
+func abs(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// bytesToBigInt converts a big-endian two's complement integer to a big.Int.
+func bytesToBigInt(b []byte) *big.Int {
+	v := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+	}
+	return v
+}
+
+// initDate parses the value of the filter as a date. The values of the column are accepted as
+// number of days since the Unix epoch and as time.Time values.
+func (f *Filter) initDate() error {
+	t, err := time.Parse("2006-01-02", f.Value)
+	if err != nil {
+		return f.invalidValue()
+	}
+	f.value = t
+
+	f.normalize = func(v interface{}) interface{} {
+		switch x := v.(type) {
+		case int32:
+			return goparquet.DateToTime(x)
+		case time.Time:
+			y, m, d := x.Date()
+			return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// initTimestamp parses the value of the filter as a timestamp. The values of the column are
+// accepted as number of units since the Unix epoch and as time.Time values. Timestamps that
+// aren't adjusted to UTC are compared by their wall clock time.
+func (f *Filter) initTimestamp(ts *parquet.TimestampType) error {
+	unit := time.Millisecond
+	switch {
+	case ts.Unit.IsSetMICROS():
+		unit = time.Microsecond
+	case ts.Unit.IsSetNANOS():
+		unit = time.Nanosecond
+	}
+
+	local := func(t time.Time) time.Time {
+		if ts.IsAdjustedToUTC {
+			return t
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, f.Value)
+	if err != nil && !ts.IsAdjustedToUTC {
+		t, err = time.Parse("2006-01-02T15:04:05.999999999", f.Value)
+	}
+	if err != nil {
+		return f.invalidValue()
+	}
+	f.value = local(t)
+
+	perSecond := int64(time.Second / unit)
+	f.normalize = func(v interface{}) interface{} {
+		switch x := v.(type) {
+		case int64:
+			return time.Unix(x/perSecond, (x%perSecond)*int64(unit)).UTC()
+		case time.Time:
+			return local(x)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// initUUID parses the value of the filter as a UUID. The values of the column are accepted in
+// all formats supported by goparquet.WithUUIDFormat.
+func (f *Filter) initUUID() error {
+	u, ok := parseUUID(f.Value)
+	if !ok {
+		return f.invalidValue()
+	}
+	f.value = u
+
+	f.normalize = func(v interface{}) interface{} {
+		switch x := v.(type) {
+		case []byte:
+			return x
+		case [16]byte:
+			return x[:]
+		case string:
+			if u, ok := parseUUID(x); ok {
+				return u
+			}
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// parseUUID parses a UUID in the canonical form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func parseUUID(s string) ([]byte, bool) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return nil, false
+	}
+	u, err := hex.DecodeString(s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	return u, err == nil
+}
+
+func (f *Filter) invalidValue() error {
+	return f.errorf("invalid value %q for filter column %q", f.Value, f.Column)
+}
+
+func (f *Filter) errorf(format string, args ...interface{}) error {
+	return &QueryError{fmt.Sprintf(format, args...)}
+}
+
+// match returns true if the value of the column in row matches the filter.
+func (f *Filter) match(row map[string]interface{}) bool {
+	c, ok := f.compare(Lookup(row, f.path))
+	if !ok {
+		return false
+	}
+
+	switch f.Op {
+	case Eq:
+		return c == 0
+	case Ne:
+		return c != 0
+	case Lt:
+		return c < 0
+	case Le:
+		return c <= 0
+	case Gt:
+		return c > 0
+	case Ge:
+		return c >= 0
+	}
+	return false
+}
+
+// matchStatistics returns false if no value between min and max can match the filter.
+func (f *Filter) matchStatistics(min, max interface{}) bool {
+	cMin, ok := f.compare(min)
+	if !ok {
+		return true
+	}
+	cMax, ok := f.compare(max)
+	if !ok {
+		return true
+	}
+
+	switch f.Op {
+	case Eq:
+		return cMin <= 0 && cMax >= 0
+	case Ne:
+		return cMin != 0 || cMax != 0
+	case Lt:
+		return cMin < 0
+	case Le:
+		return cMin <= 0
+	case Gt:
+		return cMax > 0
+	case Ge:
+		return cMax >= 0
+	}
+	return true
+}
+
+// compare compares the value v of the column with the value of the filter. It returns false if
+// they can't be compared, e.g. because v is nil.
+func (f *Filter) compare(v interface{}) (int, bool) {
+	switch x := f.normalize(v).(type) {
+	case bool:
+		y, ok := f.value.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x == y:
+			return 0, true
+		case !x:
+			return -1, true
+		}
+		return 1, true
+	case int32:
+		y, ok := f.value.(int32)
+		return compareInt64(int64(x), int64(y)), ok
+	case int64:
+		y, ok := f.value.(int64)
+		return compareInt64(x, y), ok
+	case float32:
+		y, ok := f.value.(float32)
+		return compareFloat64(float64(x), float64(y)), ok
+	case float64:
+		y, ok := f.value.(float64)
+		return compareFloat64(x, y), ok
+	case []byte:
+		y, ok := f.value.([]byte)
+		return f.cmp.Compare(x, y), ok
+	case *big.Rat:
+		y, ok := f.value.(*big.Rat)
+		if !ok {
+			return 0, false
+		}
+		return x.Cmp(y), true
+	case time.Time:
+		y, ok := f.value.(time.Time)
+		switch {
+		case !ok:
+			return 0, false
+		case x.Before(y):
+			return -1, true
+		case x.After(y):
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
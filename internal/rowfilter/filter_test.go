@@ -0,0 +1,141 @@
+package rowfilter
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterLogicalTypes(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required fixed_len_byte_array(4) price (DECIMAL(8, 2));
+		required int64 amount (DECIMAL(18, 3));
+		required int32 day (DATE);
+		required int64 ts (TIMESTAMP(MICROS, true));
+		required fixed_len_byte_array(16) uid (UUID);
+		required fixed_len_byte_array(2) code;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := goparquet.NewFileWriter(buf, goparquet.WithSchemaDefinition(sd))
+	base := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{
+			"id":     int64(i),
+			"price":  goparquet.NewDecimal(int64(i*500-1000), 2),
+			"amount": goparquet.NewDecimal(int64(i*1500), 3),
+			"day":    goparquet.TimeToDate(base.AddDate(0, 0, i)),
+			"ts":     base.Add(time.Duration(i)*time.Hour).UnixNano() / 1000,
+			"uid":    []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, byte(i)},
+			"code":   []byte{0xab, byte(i)},
+		}))
+		if i%2 == 1 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+
+	tests := []struct {
+		filter    Filter
+		ids       []int64
+		rowGroups int
+	}{
+		// the prices are -10.00, -5.00, 0.00, 5.00, 10.00 and 15.00.
+		{Filter{Column: "price", Op: Gt, Value: "10.5"}, []int64{5}, 1},
+		{Filter{Column: "price", Op: Lt, Value: "-7"}, []int64{0}, 1},
+		{Filter{Column: "price", Op: Eq, Value: "5"}, []int64{3}, 1},
+		// the amounts are 0, 1.5, 3, 4.5, 6 and 7.5.
+		{Filter{Column: "amount", Op: Ge, Value: "4.50"}, []int64{3, 4, 5}, 2},
+		{Filter{Column: "day", Op: Le, Value: "2021-03-02"}, []int64{0, 1}, 1},
+		{Filter{Column: "ts", Op: Gt, Value: "2021-03-01T15:30:00+01:00"}, []int64{3, 4, 5}, 2},
+		{Filter{Column: "uid", Op: Eq, Value: "00000000-0000-0000-0000-000000000004"}, []int64{4}, 1},
+		{Filter{Column: "code", Op: Ne, Value: "ab02"}, []int64{0, 1, 3, 4, 5}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter.Column, func(t *testing.T) {
+			r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()), "id", tt.filter.Column)
+			require.NoError(t, err)
+
+			filter := tt.filter
+			q := &Query{Columns: []string{"id"}, Filters: []*Filter{&filter}}
+			require.NoError(t, q.Prepare(r))
+
+			var ids []int64
+			for {
+				row, err := r.NextRow()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				if q.Match(row) {
+					q.Project(row)
+					require.Len(t, row, 1)
+					ids = append(ids, row["id"].(int64))
+				}
+			}
+			require.Equal(t, tt.ids, ids)
+			require.Equal(t, 3-tt.rowGroups, q.Skipped)
+		})
+	}
+
+	invalid := []Filter{
+		{Column: "price", Value: "abc"},
+		{Column: "day", Value: "2021-03-01T00:00:00Z"},
+		{Column: "ts", Value: "2021-03-01"},
+		{Column: "uid", Value: "0000"},
+		{Column: "code", Value: "abc"},
+		{Column: "code", Value: "ab0102"},
+	}
+	for _, f := range invalid {
+		r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		f := f
+		err = (&Query{Filters: []*Filter{&f}}).Prepare(r)
+		require.IsType(t, &QueryError{}, err, "%+v", f)
+	}
+}
+
+func TestFilterConvertedValues(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 day (DATE);
+		required int64 ts (TIMESTAMP(MILLIS, false));
+		required fixed_len_byte_array(16) uid (UUID);
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := goparquet.NewFileWriter(buf, goparquet.WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"day": goparquet.TimeToDate(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)),
+		"ts":  time.Date(2021, 3, 1, 12, 30, 0, 0, time.UTC).UnixNano() / 1e6,
+		"uid": "01234567-89ab-cdef-0123-456789abcdef",
+	}))
+	require.NoError(t, w.Close())
+
+	// the values are compared the same way if NextRow converts them.
+	loc := time.FixedZone("test", 3600)
+	r, err := goparquet.NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()),
+		goparquet.WithDateConversion(), goparquet.WithTimestampConversion(loc), goparquet.WithUUIDFormat(goparquet.UUIDString))
+	require.NoError(t, err)
+
+	q := &Query{Filters: []*Filter{
+		{Column: "day", Op: Eq, Value: "2021-03-01"},
+		{Column: "ts", Op: Eq, Value: "2021-03-01T12:30:00"},
+		{Column: "uid", Op: Eq, Value: "01234567-89AB-CDEF-0123-456789ABCDEF"},
+	}}
+	require.NoError(t, q.Prepare(r))
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.IsType(t, time.Time{}, row["ts"])
+	require.True(t, q.Match(row))
+	require.Equal(t, 0, q.Skipped)
+}
@@ -0,0 +1,170 @@
+// Package rowfilter implements the projection and the filters on column values that are shared
+// by parquet-tool and the parquethttp package.
+package rowfilter
+
+import (
+	"fmt"
+	"strings"
+
+	goparquet "github.com/fraugster/parquet-go"
+)
+
+// Query selects the columns and the rows of a file that are returned.
+type Query struct {
+	// Columns are the names of the columns to return in dotted notation, all columns are returned
+	// if it's empty.
+	Columns []string
+	// Filters need to match for a row to be returned.
+	Filters []*Filter
+
+	// RowGroups is the number of row groups whose statistics were inspected, and Skipped the
+	// number of row groups that were skipped based on their statistics.
+	RowGroups, Skipped int
+}
+
+// Prepare checks that the columns and filters of the query are valid for the schema of the file,
+// parses the values of the filters and restricts r to the columns and row groups that are needed
+// for the query. The returned error is a *QueryError if the query is invalid for the file.
+func (q *Query) Prepare(r *goparquet.FileReader) error {
+	sd := r.GetSchemaDefinition()
+	for _, name := range q.Columns {
+		sub := sd
+		for _, elem := range strings.Split(name, ".") {
+			sub = sub.SubSchema(elem)
+		}
+		if sub == nil {
+			return &QueryError{fmt.Sprintf("column %q not found", name)}
+		}
+	}
+
+	for _, f := range q.Filters {
+		if err := f.init(r.GetColumnByName(f.Column), r.ColumnBinaryComparator(f.Column)); err != nil {
+			return err
+		}
+	}
+
+	if len(q.Columns) > 0 {
+		// the filter columns need to be read to evaluate the filters, even if they are not
+		// part of the projection.
+		var evicted []string
+		for _, col := range r.Columns() {
+			if !q.Projected(col.FlatName()) && !q.filtered(col.FlatName()) {
+				evicted = append(evicted, col.FlatName())
+			}
+		}
+		if len(evicted) > 0 {
+			if err := r.EvictColumns(evicted...); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(q.Filters) > 0 {
+		filterColumns := make([]string, len(q.Filters))
+		for i, f := range q.Filters {
+			filterColumns[i] = f.Column
+		}
+		return r.SetRowGroupFilter(q.keepRowGroup, filterColumns...)
+	}
+
+	return nil
+}
+
+// Projected returns true if the column is part of the projection of the query.
+func (q *Query) Projected(column string) bool {
+	if len(q.Columns) == 0 {
+		return true
+	}
+	for _, name := range q.Columns {
+		if column == name || strings.HasPrefix(column, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// filtered returns true if the column is used by a filter of the query.
+func (q *Query) filtered(column string) bool {
+	for _, f := range q.Filters {
+		if f.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *Query) keepRowGroup(_ int, stats map[string]interface{}) bool {
+	q.RowGroups++
+	for _, f := range q.Filters {
+		if !f.matchStatistics(stats["min_"+f.Column], stats["max_"+f.Column]) {
+			q.Skipped++
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns true if all filters of the query match the row.
+func (q *Query) Match(row map[string]interface{}) bool {
+	for _, f := range q.Filters {
+		if !f.match(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// Project removes the values of the filter columns that aren't part of the projection from row.
+func (q *Query) Project(row map[string]interface{}) {
+	for _, f := range q.Filters {
+		if !q.Projected(f.Column) {
+			removeValue(row, f.path)
+		}
+	}
+}
+
+// QueryError is returned by Prepare if the query is invalid for the file.
+type QueryError struct {
+	Reason string
+}
+
+func (e *QueryError) Error() string {
+	return e.Reason
+}
+
+// Lookup returns the value at path within v. The values of repeated groups are returned as a
+// slice.
+func Lookup(v interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return v
+	}
+
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return Lookup(x[path[0]], path[1:])
+	case []map[string]interface{}:
+		res := make([]interface{}, 0, len(x))
+		for _, m := range x {
+			res = append(res, Lookup(m, path))
+		}
+		return res
+	}
+	return nil
+}
+
+// removeValue removes the value at path from row, as well as the groups that become empty.
+func removeValue(row map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(row, path[0])
+		return
+	}
+
+	child, ok := row[path[0]].(map[string]interface{})
+	if !ok {
+		return
+	}
+	removeValue(child, path[1:])
+	if len(child) == 0 {
+		delete(row, path[0])
+	}
+}
@@ -0,0 +1,182 @@
+package goparquet
+
+import (
+	"fmt"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// UnsupportedTypeError is returned when the values of a column can't be read because its physical
+// type is missing or can't be combined with its converted type or logical type.
+type UnsupportedTypeError struct {
+	// Column is the name of the schema element of the column.
+	Column string
+	// Type is the physical type of the column, or nil if it has none.
+	Type *parquet.Type
+	// ConvertedType and LogicalType are the annotations of the column, or nil if they're not set.
+	ConvertedType *parquet.ConvertedType
+	LogicalType   *parquet.LogicalType
+	// Reason describes why the combination is not supported.
+	Reason string
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported type of column %s: %s", e.Column, e.Reason)
+}
+
+// convertedTypePhysicalTypes maps the converted types that can annotate a column with values to
+// the physical types they can annotate.
+var convertedTypePhysicalTypes = map[parquet.ConvertedType][]parquet.Type{
+	parquet.ConvertedType_UTF8:             {parquet.Type_BYTE_ARRAY},
+	parquet.ConvertedType_ENUM:             {parquet.Type_BYTE_ARRAY},
+	parquet.ConvertedType_JSON:             {parquet.Type_BYTE_ARRAY},
+	parquet.ConvertedType_BSON:             {parquet.Type_BYTE_ARRAY},
+	parquet.ConvertedType_DECIMAL:          {parquet.Type_INT32, parquet.Type_INT64, parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY},
+	parquet.ConvertedType_DATE:             {parquet.Type_INT32},
+	parquet.ConvertedType_TIME_MILLIS:      {parquet.Type_INT32},
+	parquet.ConvertedType_TIME_MICROS:      {parquet.Type_INT64},
+	parquet.ConvertedType_TIMESTAMP_MILLIS: {parquet.Type_INT64},
+	parquet.ConvertedType_TIMESTAMP_MICROS: {parquet.Type_INT64},
+	parquet.ConvertedType_UINT_8:           {parquet.Type_INT32},
+	parquet.ConvertedType_UINT_16:          {parquet.Type_INT32},
+	parquet.ConvertedType_UINT_32:          {parquet.Type_INT32},
+	parquet.ConvertedType_UINT_64:          {parquet.Type_INT64},
+	parquet.ConvertedType_INT_8:            {parquet.Type_INT32},
+	parquet.ConvertedType_INT_16:           {parquet.Type_INT32},
+	parquet.ConvertedType_INT_32:           {parquet.Type_INT32},
+	parquet.ConvertedType_INT_64:           {parquet.Type_INT64},
+	parquet.ConvertedType_INTERVAL:         {parquet.Type_FIXED_LEN_BYTE_ARRAY},
+}
+
+// integerConvertedTypes maps the integer converted types to their bit width and signedness.
+var integerConvertedTypes = map[parquet.ConvertedType]parquet.IntType{
+	parquet.ConvertedType_UINT_8:  {BitWidth: 8, IsSigned: false},
+	parquet.ConvertedType_UINT_16: {BitWidth: 16, IsSigned: false},
+	parquet.ConvertedType_UINT_32: {BitWidth: 32, IsSigned: false},
+	parquet.ConvertedType_UINT_64: {BitWidth: 64, IsSigned: false},
+	parquet.ConvertedType_INT_8:   {BitWidth: 8, IsSigned: true},
+	parquet.ConvertedType_INT_16:  {BitWidth: 16, IsSigned: true},
+	parquet.ConvertedType_INT_32:  {BitWidth: 32, IsSigned: true},
+	parquet.ConvertedType_INT_64:  {BitWidth: 64, IsSigned: true},
+}
+
+// logicalTypePhysicalTypes returns the name of the logical type and the physical types it can
+// annotate. No physical types are returned for logical types that can't annotate a column with
+// values. An empty name is returned if no logical type is set, or if it is UNKNOWN, which can
+// annotate any physical type.
+func logicalTypePhysicalTypes(lt *parquet.LogicalType) (string, []parquet.Type) {
+	switch {
+	case lt.STRING != nil:
+		return "STRING", []parquet.Type{parquet.Type_BYTE_ARRAY}
+	case lt.ENUM != nil:
+		return "ENUM", []parquet.Type{parquet.Type_BYTE_ARRAY}
+	case lt.JSON != nil:
+		return "JSON", []parquet.Type{parquet.Type_BYTE_ARRAY}
+	case lt.BSON != nil:
+		return "BSON", []parquet.Type{parquet.Type_BYTE_ARRAY}
+	case lt.UUID != nil:
+		return "UUID", []parquet.Type{parquet.Type_FIXED_LEN_BYTE_ARRAY}
+	case lt.DECIMAL != nil:
+		return "DECIMAL", []parquet.Type{parquet.Type_INT32, parquet.Type_INT64, parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY}
+	case lt.DATE != nil:
+		return "DATE", []parquet.Type{parquet.Type_INT32}
+	case lt.TIME != nil:
+		if lt.TIME.Unit != nil && lt.TIME.Unit.MILLIS != nil {
+			return "TIME(MILLIS)", []parquet.Type{parquet.Type_INT32}
+		}
+		return "TIME", []parquet.Type{parquet.Type_INT64}
+	case lt.TIMESTAMP != nil:
+		return "TIMESTAMP", []parquet.Type{parquet.Type_INT64}
+	case lt.INTEGER != nil:
+		name := fmt.Sprintf("INTEGER(%d,%t)", lt.INTEGER.BitWidth, lt.INTEGER.IsSigned)
+		switch lt.INTEGER.BitWidth {
+		case 8, 16, 32:
+			return name, []parquet.Type{parquet.Type_INT32}
+		case 64:
+			return name, []parquet.Type{parquet.Type_INT64}
+		}
+		return name, nil
+	case lt.MAP != nil:
+		return "MAP", nil
+	case lt.LIST != nil:
+		return "LIST", nil
+	}
+	return "", nil
+}
+
+func containsType(types []parquet.Type, typ parquet.Type) bool {
+	for _, t := range types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// valueType is the resolved type of the values of a column.
+type valueType struct {
+	typ parquet.Type
+	// unsigned is set for integer columns that are annotated as unsigned.
+	unsigned bool
+}
+
+// resolveValueType resolves the type of the values of the column described by elem from its
+// physical type, converted type and logical type. An *UnsupportedTypeError is returned if the
+// physical type is missing or the annotations contradict each other or the physical type.
+func resolveValueType(elem *parquet.SchemaElement) (valueType, error) {
+	unsupported := func(format string, args ...interface{}) (valueType, error) {
+		return valueType{}, &UnsupportedTypeError{
+			Column:        elem.Name,
+			Type:          elem.Type,
+			ConvertedType: elem.ConvertedType,
+			LogicalType:   elem.LogicalType,
+			Reason:        fmt.Sprintf(format, args...),
+		}
+	}
+
+	if elem.Type == nil {
+		return unsupported("column has no physical type")
+	}
+	vt := valueType{typ: *elem.Type}
+
+	if ct := elem.ConvertedType; ct != nil {
+		types, ok := convertedTypePhysicalTypes[*ct]
+		if !ok {
+			return unsupported("converted type %s can't annotate a column with values", *ct)
+		}
+		if !containsType(types, vt.typ) {
+			return unsupported("converted type %s can't annotate type %s", *ct, vt.typ)
+		}
+		if *ct == parquet.ConvertedType_INTERVAL && elem.GetTypeLength() != 12 {
+			return unsupported("converted type INTERVAL requires a type length of 12, not %d", elem.GetTypeLength())
+		}
+		if it, ok := integerConvertedTypes[*ct]; ok {
+			vt.unsigned = !it.IsSigned
+		}
+	}
+
+	if lt := elem.LogicalType; lt != nil {
+		name, types := logicalTypePhysicalTypes(lt)
+		if name != "" && types == nil {
+			return unsupported("logical type %s can't annotate a column with values", name)
+		}
+		if name != "" && !containsType(types, vt.typ) {
+			return unsupported("logical type %s can't annotate type %s", name, vt.typ)
+		}
+		if lt.UUID != nil && elem.GetTypeLength() != 16 {
+			return unsupported("logical type UUID requires a type length of 16, not %d", elem.GetTypeLength())
+		}
+
+		if it := lt.INTEGER; it != nil {
+			if ct := elem.ConvertedType; ct != nil {
+				cit, ok := integerConvertedTypes[*ct]
+				if !ok || cit.BitWidth != it.BitWidth || cit.IsSigned != it.IsSigned {
+					return unsupported("converted type %s contradicts logical type %s", *ct, name)
+				}
+			}
+			vt.unsigned = !it.IsSigned
+		}
+	}
+
+	return vt, nil
+}
@@ -0,0 +1,64 @@
+package goparquet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveValueType(t *testing.T) {
+	intType := func(bitWidth int8, signed bool) *parquet.LogicalType {
+		return &parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: bitWidth, IsSigned: signed}}
+	}
+	ct := parquet.ConvertedTypePtr
+	typeLength := func(l int32) *int32 { return &l }
+
+	tests := map[string]struct {
+		elem     *parquet.SchemaElement
+		unsigned bool
+		fail     bool
+	}{
+		"plain int32":        {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32)}},
+		"uint8":              {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32), ConvertedType: ct(parquet.ConvertedType_UINT_8)}, unsigned: true},
+		"uint64 logical":     {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT64), LogicalType: intType(64, false)}, unsigned: true},
+		"uint32 both":        {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32), ConvertedType: ct(parquet.ConvertedType_UINT_32), LogicalType: intType(32, false)}, unsigned: true},
+		"string":             {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BYTE_ARRAY), ConvertedType: ct(parquet.ConvertedType_UTF8), LogicalType: &parquet.LogicalType{STRING: &parquet.StringType{}}}},
+		"decimal flba":       {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY), TypeLength: typeLength(8), ConvertedType: ct(parquet.ConvertedType_DECIMAL)}},
+		"uuid":               {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY), TypeLength: typeLength(16), LogicalType: &parquet.LogicalType{UUID: &parquet.UUIDType{}}}},
+		"unknown":            {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32), LogicalType: &parquet.LogicalType{UNKNOWN: &parquet.NullType{}}}},
+		"interval":           {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY), TypeLength: typeLength(12), ConvertedType: ct(parquet.ConvertedType_INTERVAL)}},
+		"time millis":        {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32), LogicalType: &parquet.LogicalType{TIME: &parquet.TimeType{Unit: &parquet.TimeUnit{MILLIS: &parquet.MilliSeconds{}}}}}},
+		"no type":            {elem: &parquet.SchemaElement{}, fail: true},
+		"uint8 on binary":    {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BYTE_ARRAY), ConvertedType: ct(parquet.ConvertedType_UINT_8)}, fail: true},
+		"list on int32":      {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32), ConvertedType: ct(parquet.ConvertedType_LIST)}, fail: true},
+		"string on int64":    {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT64), LogicalType: &parquet.LogicalType{STRING: &parquet.StringType{}}}, fail: true},
+		"int64 on int32":     {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32), LogicalType: intType(64, true)}, fail: true},
+		"invalid bit width":  {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32), LogicalType: intType(12, true)}, fail: true},
+		"signedness differs": {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32), ConvertedType: ct(parquet.ConvertedType_INT_32), LogicalType: intType(32, false)}, fail: true},
+		"time micros int32":  {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32), LogicalType: &parquet.LogicalType{TIME: &parquet.TimeType{Unit: &parquet.TimeUnit{MICROS: &parquet.MicroSeconds{}}}}}, fail: true},
+		"interval length":    {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY), TypeLength: typeLength(8), ConvertedType: ct(parquet.ConvertedType_INTERVAL)}, fail: true},
+		"uuid length":        {elem: &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY), TypeLength: typeLength(8), LogicalType: &parquet.LogicalType{UUID: &parquet.UUIDType{}}}, fail: true},
+	}
+
+	for name, tt := range tests {
+		tt.elem.Name = "col"
+		vt, err := resolveValueType(tt.elem)
+		if !tt.fail {
+			require.NoError(t, err, name)
+			require.Equal(t, tt.unsigned, vt.unsigned, name)
+			continue
+		}
+
+		var typeErr *UnsupportedTypeError
+		require.True(t, errors.As(err, &typeErr), "%s: unexpected error %v", name, err)
+		require.Equal(t, "col", typeErr.Column, name)
+
+		// the decoders must report the same error instead of panicking.
+		_, err = getValuesDecoder(parquet.Encoding_PLAIN, tt.elem, nil)
+		require.True(t, errors.As(err, &typeErr), "%s: unexpected error %v", name, err)
+		_, err = getDictValuesDecoder(tt.elem)
+		require.True(t, errors.As(err, &typeErr), "%s: unexpected error %v", name, err)
+	}
+}
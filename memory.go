@@ -0,0 +1,187 @@
+package goparquet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+const (
+	// valueMemorySize is a rough estimation of the memory required for every value of a page
+	// once it is decoded, i.e. the interface value and its repetition and definition level.
+	valueMemorySize = 24
+	// pageMemorySize is a rough estimation of the memory required for the header, reader and
+	// decoders of every page.
+	pageMemorySize = 512
+)
+
+// MemoryLimitExceededError is returned by the FileReader if reading a row group requires more
+// memory than configured using WithMaximumMemorySize.
+type MemoryLimitExceededError struct {
+	// Limit is the configured memory limit in bytes.
+	Limit int64
+	// Required is the amount of memory in bytes that would have been required to read the row
+	// group up to the point where the limit was exceeded.
+	Required int64
+}
+
+func (e *MemoryLimitExceededError) Error() string {
+	return fmt.Sprintf("reading row group requires at least %d bytes of memory, which exceeds the limit of %d bytes", e.Required, e.Limit)
+}
+
+// memoryTracker accounts for the memory that is required to read a single row group. It
+// is based on the sizes provided in page headers before any memory is allocated, so that
+// huge or hostile files are rejected early.
+type memoryTracker struct {
+	limit int64
+	used  int64
+}
+
+func newMemoryTracker(limit int64) *memoryTracker {
+	return &memoryTracker{limit: limit}
+}
+
+// reserve adds size bytes to the required memory and returns a *MemoryLimitExceededError
+// if the limit is exceeded. A tracker without limit never returns an error.
+func (m *memoryTracker) reserve(size int64) error {
+	if m == nil || m.limit <= 0 {
+		return nil
+	}
+
+	m.used += size
+	if m.used > m.limit {
+		return &MemoryLimitExceededError{Limit: m.limit, Required: m.used}
+	}
+	return nil
+}
+
+// reservePage reserves the memory for the compressed and uncompressed page data and the decoded
+// values of a page.
+func (m *memoryTracker) reservePage(compressedSize, uncompressedSize, numValues int32) error {
+	var size int64
+	// invalid negative sizes are rejected when the page is read.
+	if compressedSize > 0 {
+		size += int64(compressedSize)
+	}
+	if uncompressedSize > 0 {
+		size += int64(uncompressedSize)
+	}
+	if numValues > 0 {
+		size += int64(numValues) * valueMemorySize
+	}
+	return m.reserve(size)
+}
+
+// pageNumValues returns the number of values of a page according to its header.
+func pageNumValues(ph *parquet.PageHeader) int32 {
+	switch {
+	case ph.DataPageHeader != nil:
+		return ph.DataPageHeader.NumValues
+	case ph.DataPageHeaderV2 != nil:
+		return ph.DataPageHeaderV2.NumValues
+	case ph.DictionaryPageHeader != nil:
+		return ph.DictionaryPageHeader.NumValues
+	}
+	return 0
+}
+
+// EstimateReadMemory estimates the memory in bytes that is required to read the columns in
+// projection of the row group with index rowGroup, without reading any data. The names of the
+// columns need to be provided in dotted notation, a group includes all its columns. If
+// projection is empty, the columns selected with WithColumns are used. The estimate is based
+// on the sizes and value counts in the column chunk meta data and the page counts in the page
+// encoding stats, and accounts for the same memory as WithMaximumMemorySize. As the number of
+// dictionary entries is not part of the meta data, every value of a dictionary encoded chunk is
+// assumed to have its own dictionary entry, so the estimate errs on the side of caution.
+func (f *FileReader) EstimateReadMemory(projection []string, rowGroup int) (int64, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return 0, errors.Errorf("row group index %d is out of bounds", rowGroup)
+	}
+	rg := f.meta.RowGroups[rowGroup]
+
+	for _, name := range projection {
+		if f.GetColumnByName(name) == nil && !isGroupPrefix(f.Columns(), name) {
+			return 0, errors.Errorf("column %q not found", name)
+		}
+	}
+
+	var total int64
+	for _, col := range f.Columns() {
+		if len(projection) > 0 && !inProjection(projection, col.FlatName()) {
+			continue
+		}
+		if len(projection) == 0 && !f.SchemaReader.isSelected(col.FlatName()) {
+			continue
+		}
+
+		if len(rg.Columns) <= col.Index() || rg.Columns[col.Index()].MetaData == nil {
+			return 0, errors.Errorf("missing meta data for column %s", col.FlatName())
+		}
+
+		total += estimateChunkMemory(rg.Columns[col.Index()].MetaData)
+	}
+
+	return total, nil
+}
+
+func estimateChunkMemory(meta *parquet.ColumnMetaData) int64 {
+	var size int64
+	// invalid negative sizes are rejected when the chunk is read.
+	if meta.TotalCompressedSize > 0 {
+		size += meta.TotalCompressedSize
+	}
+	if meta.TotalUncompressedSize > 0 {
+		size += meta.TotalUncompressedSize
+	}
+
+	numValues := meta.NumValues
+	if numValues < 0 {
+		numValues = 0
+	}
+	size += numValues * valueMemorySize
+
+	pages := int64(0)
+	dictionary := meta.DictionaryPageOffset != nil
+	for _, stats := range meta.EncodingStats {
+		if stats.Count > 0 {
+			pages += int64(stats.Count)
+		}
+		if stats.PageType == parquet.PageType_DICTIONARY_PAGE {
+			dictionary = true
+		}
+	}
+	if pages == 0 {
+		// without encoding stats, the chunk is assumed to consist of a single data page.
+		pages = 1
+		if dictionary {
+			pages++
+		}
+	}
+	size += pages * pageMemorySize
+
+	if dictionary {
+		size += numValues * valueMemorySize
+	}
+
+	return size
+}
+
+func inProjection(projection []string, flatName string) bool {
+	for _, name := range projection {
+		if flatName == name || strings.HasPrefix(flatName, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func isGroupPrefix(cols []*Column, name string) bool {
+	for _, col := range cols {
+		if strings.HasPrefix(col.FlatName(), name+".") {
+			return true
+		}
+	}
+	return false
+}
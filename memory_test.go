@@ -0,0 +1,90 @@
+package goparquet
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaximumMemorySize(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required binary data;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i), "data": bytes.Repeat([]byte{byte(i)}, 100)}))
+		if i == 499 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithMaximumMemorySize(1024))
+	require.NoError(t, err)
+
+	_, err = r.NextRow()
+	var memErr *MemoryLimitExceededError
+	require.True(t, errors.As(err, &memErr), "unexpected error %v", err)
+	require.Equal(t, int64(1024), memErr.Limit)
+	require.True(t, memErr.Required > memErr.Limit)
+
+	// the limit applies to every row group on its own.
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithMaximumMemorySize(200*1024))
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithMaximumMemorySize(1024), WithColumns("id"))
+	require.NoError(t, err)
+	_, err = r.ReadDictionaryChunk(0, "data")
+	require.True(t, errors.As(err, &memErr), "unexpected error %v", err)
+}
+
+func TestEstimateReadMemory(t *testing.T) {
+	data := writeValidateTestFile(t)
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	all, err := r.EstimateReadMemory(nil, 0)
+	require.NoError(t, err)
+	id, err := r.EstimateReadMemory([]string{"id"}, 0)
+	require.NoError(t, err)
+	require.True(t, id > 0 && id < all, "id %d, all %d", id, all)
+
+	both, err := r.EstimateReadMemory([]string{"id", "name", "tags"}, 0)
+	require.NoError(t, err)
+	require.Equal(t, all, both)
+
+	_, err = r.EstimateReadMemory(nil, 3)
+	require.Error(t, err)
+	_, err = r.EstimateReadMemory([]string{"unknown"}, 0)
+	require.Error(t, err)
+
+	// the estimate is enough to read the row group with the same memory limit.
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithMaximumMemorySize(all))
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithColumns("id"), WithMaximumMemorySize(id))
+	require.NoError(t, err)
+	selected, err := r.EstimateReadMemory(nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, id, selected)
+	for i := 0; i < 100; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,337 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// MergeFiles writes a parquet file to w that contains the row groups of all srcs in the given
+// order. All files need to have the same schema. The column chunks are copied as they are,
+// without decoding and encoding their pages, and only the meta data footers are combined into a
+// new footer. Page indexes and Bloom filters of the column chunks are copied as well. The
+// key-value meta data of the merged file contains the entries that are the same in all files,
+// and the created_by field is only kept if it is the same in all files. Min and max statistics
+// that the writer of a file is known to have written wrong are dropped, see WriterVersion. Files
+// whose column chunks are stored in separate files or are encrypted can't be merged.
+func MergeFiles(w io.Writer, srcs ...io.ReadSeeker) error {
+	if len(srcs) == 0 {
+		return errors.New("no files to merge")
+	}
+
+	metas := make([]*parquet.FileMetaData, len(srcs))
+	writers := make([]*WriterVersion, len(srcs))
+	for i, r := range srcs {
+		meta, _, err := readFileMetaData(r, FooterLimits{}, nil)
+		if err != nil {
+			return errors.Wrapf(err, "reading meta data of file %d failed", i)
+		}
+		if i > 0 && schemaElementsFingerprint(meta.Schema) != schemaElementsFingerprint(metas[0].Schema) {
+			return errors.Errorf("schema of file %d differs from the schema of file 0", i)
+		}
+		metas[i] = meta
+		writers[i] = ParseCreatedBy(meta.GetCreatedBy())
+	}
+
+	out := &writePosStruct{w: w}
+	if err := writeFull(out, magic); err != nil {
+		return err
+	}
+
+	merged := &parquet.FileMetaData{
+		Version:          metas[0].Version,
+		Schema:           metas[0].Schema,
+		RowGroups:        []*parquet.RowGroup{},
+		KeyValueMetadata: metas[0].KeyValueMetadata,
+		CreatedBy:        metas[0].CreatedBy,
+		ColumnOrders:     metas[0].ColumnOrders,
+	}
+
+	var (
+		pageIndexes  []*chunkPageIndex
+		bloomFilters []*chunkBloomFilter
+	)
+	for i, r := range srcs {
+		meta := metas[i]
+		end, err := dataEnd(r, true)
+		if err != nil {
+			return errors.Wrapf(err, "file %d", i)
+		}
+
+		for rgIdx, rg := range meta.RowGroups {
+			mergedRG := *rg
+			mergedRG.Columns = make([]*parquet.ColumnChunk, 0, len(rg.Columns))
+			for _, chunk := range rg.Columns {
+				m, err := mergeColumnChunk(out, r, chunk, end, writers[i])
+				if err != nil {
+					return errors.Wrapf(err, "merging row group %d of file %d failed", rgIdx, i)
+				}
+				mergedRG.Columns = append(mergedRG.Columns, m.chunk)
+				if m.pageIndex != nil {
+					pageIndexes = append(pageIndexes, m.pageIndex)
+				}
+				if m.bloomFilter != nil {
+					bloomFilters = append(bloomFilters, m.bloomFilter)
+				}
+			}
+
+			if rg.FileOffset != nil && len(mergedRG.Columns) > 0 {
+				offset := mergedRG.Columns[0].FileOffset
+				mergedRG.FileOffset = &offset
+			}
+			if rg.Ordinal != nil {
+				ordinal := int16(len(merged.RowGroups))
+				mergedRG.Ordinal = &ordinal
+			}
+			merged.RowGroups = append(merged.RowGroups, &mergedRG)
+		}
+
+		merged.NumRows += meta.NumRows
+		merged.KeyValueMetadata = commonKeyValueMetaData(merged.KeyValueMetadata, meta.KeyValueMetadata)
+		if merged.CreatedBy != nil && (meta.CreatedBy == nil || *meta.CreatedBy != *merged.CreatedBy) {
+			merged.CreatedBy = nil
+		}
+		if !reflect.DeepEqual(merged.ColumnOrders, meta.ColumnOrders) {
+			merged.ColumnOrders = nil
+		}
+	}
+
+	if err := writePageIndexes(out, pageIndexes); err != nil {
+		return err
+	}
+
+	if err := writeBloomFilters(out, bloomFilters); err != nil {
+		return err
+	}
+
+	return writeFileMetaData(out, merged)
+}
+
+// mergedChunk is a column chunk that was copied to the merged file, together with its page
+// index and Bloom filter, which are written after all row groups.
+type mergedChunk struct {
+	chunk       *parquet.ColumnChunk
+	pageIndex   *chunkPageIndex
+	bloomFilter *chunkBloomFilter
+}
+
+// mergeColumnChunk copies the pages of chunk from r, whose data ends at end, to w, and returns
+// the column chunk with its offsets moved to the new position. Statistics that writer is known to
+// have written wrong are dropped, as the created_by field of the new file may not identify the
+// writer anymore.
+func mergeColumnChunk(w writePos, r io.ReadSeeker, chunk *parquet.ColumnChunk, end int64, writer *WriterVersion) (*mergedChunk, error) {
+	if chunk.FilePath != nil {
+		return nil, errors.Errorf("column chunk is stored in the separate file %s", *chunk.FilePath)
+	}
+	if chunk.CryptoMetadata != nil || chunk.EncryptedColumnMetadata != nil {
+		return nil, ErrEncryptedFile
+	}
+
+	meta := chunk.MetaData
+	if meta == nil {
+		return nil, errors.New("missing meta data")
+	}
+
+	start := meta.DataPageOffset
+	if meta.DictionaryPageOffset != nil && *meta.DictionaryPageOffset < start {
+		start = *meta.DictionaryPageOffset
+	}
+	if start < 0 || meta.TotalCompressedSize < 0 || start+meta.TotalCompressedSize > end {
+		return nil, errors.Errorf("column chunk of %d bytes at offset %d is out of bounds", meta.TotalCompressedSize, start)
+	}
+
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	newStart := w.Pos()
+	if _, err := io.CopyN(w, r, meta.TotalCompressedSize); err != nil {
+		return nil, errors.Wrap(err, "copying column chunk failed")
+	}
+	delta := newStart - start
+
+	newMeta := *meta
+	newMeta.DataPageOffset += delta
+	if meta.DictionaryPageOffset != nil {
+		offset := *meta.DictionaryPageOffset + delta
+		newMeta.DictionaryPageOffset = &offset
+	}
+	if meta.IndexPageOffset != nil {
+		offset := *meta.IndexPageOffset + delta
+		newMeta.IndexPageOffset = &offset
+	}
+	newMeta.BloomFilterOffset = nil
+	newMeta.BloomFilterLength = nil
+	newMeta.Statistics = writer.trustedStatistics(meta.Type, meta.Statistics)
+
+	newChunk := *chunk
+	newChunk.FileOffset = newStart
+	newChunk.MetaData = &newMeta
+	newChunk.ColumnIndexOffset, newChunk.ColumnIndexLength = nil, nil
+	newChunk.OffsetIndexOffset, newChunk.OffsetIndexLength = nil, nil
+
+	res := &mergedChunk{chunk: &newChunk}
+
+	if chunk.OffsetIndexOffset != nil && chunk.OffsetIndexLength != nil {
+		index := &chunkPageIndex{chunk: &newChunk, offsetIndex: &parquet.OffsetIndex{}}
+		if err := readPageIndexStruct(r, index.offsetIndex, *chunk.OffsetIndexOffset, *chunk.OffsetIndexLength, nil, offsetIndexModule); err != nil {
+			return nil, errors.Wrap(err, "reading offset index failed")
+		}
+		for _, loc := range index.offsetIndex.PageLocations {
+			loc.Offset += delta
+		}
+
+		if chunk.ColumnIndexOffset != nil && chunk.ColumnIndexLength != nil {
+			index.columnIndex = &parquet.ColumnIndex{}
+			if err := readPageIndexStruct(r, index.columnIndex, *chunk.ColumnIndexOffset, *chunk.ColumnIndexLength, nil, columnIndexModule); err != nil {
+				return nil, errors.Wrap(err, "reading column index failed")
+			}
+		}
+		res.pageIndex = index
+	}
+
+	if meta.BloomFilterOffset != nil {
+		filter, err := readBloomFilter(r, *meta.BloomFilterOffset, meta.BloomFilterLength, end, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading bloom filter failed")
+		}
+		res.bloomFilter = &chunkBloomFilter{chunk: &newChunk, filter: filter}
+	}
+
+	return res, nil
+}
+
+// commonKeyValueMetaData returns the entries of a that have the same value in b.
+func commonKeyValueMetaData(a, b []*parquet.KeyValue) []*parquet.KeyValue {
+	res := make([]*parquet.KeyValue, 0, len(a))
+	for _, kv := range a {
+		for _, other := range b {
+			if kv.Key == other.Key && reflect.DeepEqual(kv.Value, other.Value) {
+				res = append(res, kv)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// CopyChunk copies the chunk of the column colName in the row group with index rowGroup of r to
+// the current row group of the FileWriter as it is, without decoding and encoding its pages, so
+// that compaction tools can be built on top of this package. The page index and the Bloom filter
+// of the chunk are copied as well, the statistics unless the writer of r is known to have written
+// them wrong. The column needs to have the same type and levels in both
+// schemas, and its name has to be provided in dotted notation. Once a chunk was copied for every
+// column of the schema, the row group is complete and a new row group begins. All chunks of a
+// row group need to have the same number of rows. It is not possible to copy chunks while there
+// is data added through AddData that has not been flushed yet.
+func (fw *FileWriter) CopyChunk(r *FileReader, rowGroup int, colName string) error {
+	if fw.tx != nil {
+		return errors.New("can't copy column chunk while a transaction is in progress")
+	}
+
+	if fw.rowGroupNumRecords() > 0 {
+		return errors.New("can't copy column chunk while the current row group contains unflushed data")
+	}
+
+	if rowGroup < 0 || rowGroup >= len(r.meta.RowGroups) {
+		return errors.Errorf("row group index %d is out of bounds", rowGroup)
+	}
+
+	// the columns are prepared before comparing them, so that TIMESTAMP chunks can't be copied
+	// to columns that are written as INT96.
+	fw.prepareColumns()
+
+	src, dst := r.GetColumnByName(colName), fw.GetColumnByName(colName)
+	if src == nil || !src.DataColumn() {
+		return errors.Errorf("column %q not found in source file", colName)
+	}
+	if dst == nil || !dst.DataColumn() {
+		return errors.Errorf("column %q not found", colName)
+	}
+	if columnFingerprint(src) != columnFingerprint(dst) {
+		return errors.Errorf("column %s has a different type or different levels in the source file", colName)
+	}
+
+	if _, ok := fw.copiedChunks[colName]; ok {
+		return errors.Errorf("column chunk of column %s was already copied to the current row group", colName)
+	}
+
+	if err := fw.checkNewRowGroupLimit(); err != nil {
+		return err
+	}
+
+	rg := r.meta.RowGroups[rowGroup]
+	if len(rg.Columns) <= src.Index() {
+		return errors.Errorf("column index %d is out of bounds", src.Index())
+	}
+
+	if len(fw.copiedChunks) == 0 {
+		if err := fw.checkRowGroupLimit(); err != nil {
+			return err
+		}
+	} else if rg.NumRows != fw.copiedRows {
+		return errors.Errorf("row group %d has %d rows but the current row group has %d rows", rowGroup, rg.NumRows, fw.copiedRows)
+	}
+
+	if fw.w.Pos() == 0 {
+		if err := writeFull(fw.w, magic); err != nil {
+			return err
+		}
+	}
+
+	end, err := dataEnd(r.reader, true)
+	if err != nil {
+		return err
+	}
+
+	m, err := mergeColumnChunk(fw.w, r.reader, rg.Columns[src.Index()], end, r.writerVersion)
+	if err != nil {
+		return errors.Wrapf(err, "copying column chunk of column %s failed", colName)
+	}
+
+	if fw.copiedChunks == nil {
+		fw.copiedChunks = make(map[string]*parquet.ColumnChunk)
+	}
+	fw.copiedChunks[colName] = m.chunk
+	fw.copiedRows = rg.NumRows
+	if m.pageIndex != nil {
+		fw.pageIndexes = append(fw.pageIndexes, m.pageIndex)
+	}
+	if m.bloomFilter != nil {
+		fw.bloomFilters = append(fw.bloomFilters, m.bloomFilter)
+	}
+
+	cols := fw.Columns()
+	if len(fw.copiedChunks) < len(cols) {
+		return nil
+	}
+
+	cc := make([]*parquet.ColumnChunk, 0, len(cols))
+	var totalSize int64
+	for _, col := range cols {
+		ch := fw.copiedChunks[col.FlatName()]
+		totalSize += ch.MetaData.TotalUncompressedSize
+		cc = append(cc, ch)
+	}
+
+	fw.rowGroups = append(fw.rowGroups, &parquet.RowGroup{
+		Columns:       cc,
+		TotalByteSize: totalSize,
+		NumRows:       fw.copiedRows,
+	})
+	fw.totalNumRecords += fw.copiedRows
+	fw.copiedChunks = nil
+
+	return nil
+}
+
+// columnFingerprint returns a fingerprint of the type and the levels of the data column col.
+func columnFingerprint(col *Column) string {
+	buf := &bytes.Buffer{}
+	writeElementFingerprint(buf, col.Element(), false)
+	fmt.Fprintf(buf, "r=%d d=%d", col.MaxRepetitionLevel(), col.MaxDefinitionLevel())
+	return buf.String()
+}
@@ -0,0 +1,185 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeFiles(t *testing.T) {
+	first := writeValidateTestFile(t, WithPageIndex(), WithBloomFilter("id", 0.01), WithMetaData(map[string]string{"a": "1", "b": "2"}))
+	second := writeValidateTestFile(t, WithDataPageV2(), WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithMetaData(map[string]string{"a": "1", "b": "3"}))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, MergeFiles(buf, bytes.NewReader(first), bytes.NewReader(second)))
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(600), r.NumRows())
+	require.Equal(t, 6, r.RowGroupCount())
+	require.Equal(t, map[string]string{"a": "1"}, r.MetaData())
+
+	for rg := 0; rg < r.RowGroupCount(); rg++ {
+		for _, col := range []string{"id", "name", "tags"} {
+			report, err := r.Validate(rg, col)
+			require.NoError(t, err)
+			require.True(t, report.Valid(), "%s/%d: %v", col, rg, report.Problems)
+		}
+	}
+
+	_, oi, err := r.PageIndex(1, "id")
+	require.NoError(t, err)
+	require.NotNil(t, oi)
+	require.Equal(t, r.meta.RowGroups[1].Columns[0].MetaData.DataPageOffset, oi.PageLocations[0].Offset)
+	_, oi, err = r.PageIndex(4, "id")
+	require.NoError(t, err)
+	require.Nil(t, oi)
+
+	bf, err := r.ReadBloomFilter(2, "id")
+	require.NoError(t, err)
+	ok, err := bf.Check(int64(250))
+	require.NoError(t, err)
+	require.True(t, ok)
+	bf, err = r.ReadBloomFilter(3, "id")
+	require.NoError(t, err)
+	require.Nil(t, bf)
+
+	for i := 0; i < 600; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i%300), row["id"])
+		require.Equal(t, []int32{int32(i % 300), int32(i % 300 % 3)}, row["tags"])
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestMergeFilesInvalid(t *testing.T) {
+	require.Error(t, MergeFiles(&bytes.Buffer{}))
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.NoError(t, w.Close())
+
+	data := writeValidateTestFile(t)
+	err = MergeFiles(&bytes.Buffer{}, bytes.NewReader(data), bytes.NewReader(buf.Bytes()))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "schema of file 1")
+
+	require.Error(t, MergeFiles(&bytes.Buffer{}, bytes.NewReader(data), bytes.NewReader([]byte("PAR1"))))
+}
+
+func TestMergeFilesConvertedTypes(t *testing.T) {
+	var files []io.ReadSeeker
+	for _, schema := range []string{
+		`message test { required binary name (UTF8); required int32 count (INT_32); }`,
+		`message test { required binary name (STRING); required int32 count (INT(32, true)); }`,
+	} {
+		sd, err := parquetschema.ParseSchemaDefinition(schema)
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("foo"), "count": int32(1)}))
+		require.NoError(t, w.Close())
+		files = append(files, bytes.NewReader(buf.Bytes()))
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, MergeFiles(buf, files...))
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.NumRows())
+}
+
+func TestCopyChunk(t *testing.T) {
+	src, err := NewFileReader(bytes.NewReader(writeValidateTestFile(t, WithPageIndex(), WithBloomFilter("id", 0.01))))
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(src.GetSchemaDefinition()))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(-1), "tags": []int32{}}))
+	require.NoError(t, w.FlushRowGroup())
+
+	for _, rg := range []int{2, 0} {
+		// the chunks don't need to be copied in the order of the schema.
+		for _, col := range []string{"tags", "id", "name"} {
+			require.NoError(t, w.CopyChunk(src, rg, col))
+		}
+	}
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(-2), "tags": []int32{}}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(202), r.NumRows())
+	require.Equal(t, 4, r.RowGroupCount())
+
+	bf, err := r.ReadBloomFilter(1, "id")
+	require.NoError(t, err)
+	ok, err := bf.Check(int64(250))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, oi, err := r.PageIndex(2, "name")
+	require.NoError(t, err)
+	require.Equal(t, r.meta.RowGroups[2].Columns[1].MetaData.DataPageOffset, oi.PageLocations[0].Offset)
+
+	ids := []int64{-1}
+	for i := 200; i < 300; i++ {
+		ids = append(ids, int64(i))
+	}
+	for i := 0; i < 100; i++ {
+		ids = append(ids, int64(i))
+	}
+	ids = append(ids, -2)
+	for _, id := range ids {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, id, row["id"])
+		if id >= 0 {
+			require.Equal(t, []int32{int32(id), int32(id % 3)}, row["tags"])
+		}
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestCopyChunkInvalid(t *testing.T) {
+	src, err := NewFileReader(bytes.NewReader(writeValidateTestFile(t)))
+	require.NoError(t, err)
+
+	w := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(src.GetSchemaDefinition()))
+	require.Error(t, w.CopyChunk(src, 3, "id"))
+	require.Error(t, w.CopyChunk(src, 0, "unknown"))
+
+	require.NoError(t, w.CopyChunk(src, 0, "id"))
+	require.Error(t, w.CopyChunk(src, 1, "id"))
+	require.Error(t, w.FlushRowGroup())
+	require.Error(t, w.Close())
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 id;
+		optional binary name (STRING);
+		repeated int32 tags;
+	}`)
+	require.NoError(t, err)
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+	require.Error(t, w.CopyChunk(src, 0, "id"))
+	require.NoError(t, w.CopyChunk(src, 0, "tags"))
+
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(src.GetSchemaDefinition()))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.Error(t, w.CopyChunk(src, 0, "id"))
+}
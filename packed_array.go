@@ -99,3 +99,47 @@ func (pa *packedArray) appendArray(other *packedArray) {
 		pa.appendSingle(v)
 	}
 }
+
+// truncate removes all values from position n onwards.
+func (pa *packedArray) truncate(n int) {
+	if n < 0 || n > pa.count {
+		panic("truncate out of range")
+	}
+	if n == pa.count {
+		return
+	}
+
+	var buf [8]int32
+	for i := 0; i < n%8; i++ {
+		buf[i], _ = pa.at(n - n%8 + i)
+	}
+
+	pa.data = pa.data[:(n/8)*pa.bw]
+	pa.buf = buf
+	pa.bufPos = n % 8
+	pa.count = n
+}
+
+// slice returns the values from position from up to but not including position to. The array
+// itself is returned if it contains exactly these values.
+func (pa *packedArray) slice(from, to int) *packedArray {
+	if from < 0 || to > pa.count || from > to {
+		panic("slice out of range")
+	}
+	if from == 0 && to == pa.count {
+		return pa
+	}
+
+	res := &packedArray{}
+	res.reset(pa.bw)
+	for i := from; i < to; i++ {
+		v, _ := pa.at(i)
+		res.appendSingle(v)
+	}
+	return res
+}
+
+// size returns the number of bytes the values take up when they are bit-packed.
+func (pa *packedArray) size() int64 {
+	return (int64(pa.count)*int64(pa.bw) + 7) / 8
+}
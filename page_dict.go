@@ -65,8 +65,11 @@ func (dp *dictPageReader) read(r io.Reader, ph *parquet.PageHeader, codec parque
 
 type dictPageWriter struct {
 	col *Column
+	// numValues is the number of values of the column store that are written to the dictionary.
+	numValues int
 
-	codec parquet.CompressionCodec
+	codec    parquet.CompressionCodec
+	writeCRC bool
 }
 
 func (dp *dictPageWriter) init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec) error {
@@ -82,7 +85,7 @@ func (dp *dictPageWriter) getHeader(comp, unComp int) *parquet.PageHeader {
 		CompressedPageSize:   int32(comp),
 		Crc:                  nil,
 		DictionaryPageHeader: &parquet.DictionaryPageHeader{
-			NumValues: dp.col.data.values.numDistinctValues(),
+			NumValues: int32(dp.numValues),
 			Encoding:  parquet.Encoding_PLAIN, // PLAIN_DICTIONARY is deprecated in the Parquet 2.0 specification
 			IsSorted:  nil,
 		},
@@ -99,7 +102,7 @@ func (dp *dictPageWriter) write(w io.Writer) (int, int, error) {
 		return 0, 0, err
 	}
 
-	err = encodeValue(dataBuf, encoder, dp.col.data.values.values)
+	err = encodeValue(dataBuf, encoder, dp.col.data.values.values[:dp.numValues])
 	if err != nil {
 		return 0, 0, err
 	}
@@ -111,6 +114,9 @@ func (dp *dictPageWriter) write(w io.Writer) (int, int, error) {
 	compSize, unCompSize := len(comp), len(dataBuf.Bytes())
 
 	header := dp.getHeader(compSize, unCompSize)
+	if dp.writeCRC {
+		header.Crc = pageCRC(comp)
+	}
 	if err := writeThrift(header, w); err != nil {
 		return 0, 0, err
 	}
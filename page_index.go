@@ -0,0 +1,227 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// chunkPageIndex contains the page index of a column chunk. It's written after all row groups,
+// right before the meta data footer, and its location is stored in the column chunk.
+type chunkPageIndex struct {
+	chunk *parquet.ColumnChunk
+	// columnIndex is nil if no column index can be written for the column chunk, e.g. because
+	// the sort order of its type is undefined.
+	columnIndex *parquet.ColumnIndex
+	offsetIndex *parquet.OffsetIndex
+}
+
+// pageIndexBuilder builds the page index of a column chunk while its data pages are written.
+type pageIndexBuilder struct {
+	col       *Column
+	statsOpts statisticsOptions
+	index     *chunkPageIndex
+	firstRow  int64
+}
+
+func newPageIndexBuilder(col *Column, statsOpts statisticsOptions) *pageIndexBuilder {
+	index := &chunkPageIndex{
+		offsetIndex: &parquet.OffsetIndex{PageLocations: []*parquet.PageLocation{}},
+	}
+
+	// binary statistics in the legacy order can't be stored in the column index, as it has
+	// no fields for them.
+	legacy := statsOpts.comparator(col).legacy() && isBinaryType(col.data.parquetType()) && !isDecimalElement(col.Element())
+	if col.data.parquetType() != parquet.Type_INT96 && !legacy {
+		index.columnIndex = &parquet.ColumnIndex{
+			NullPages:  []bool{},
+			MinValues:  [][]byte{},
+			MaxValues:  [][]byte{},
+			NullCounts: []int64{},
+		}
+	}
+
+	return &pageIndexBuilder{
+		col:       col,
+		statsOpts: statsOpts,
+		index:     index,
+	}
+}
+
+// addPage adds the data page that was written at offset and whose header and data take up size
+// bytes.
+func (b *pageIndexBuilder) addPage(page *dataPage, offset int64, size int64) {
+	b.index.offsetIndex.PageLocations = append(b.index.offsetIndex.PageLocations, &parquet.PageLocation{
+		Offset:             offset,
+		CompressedPageSize: int32(size),
+		FirstRowIndex:      b.firstRow,
+	})
+	b.firstRow += int64(page.numRows)
+
+	ci := b.index.columnIndex
+	if ci == nil {
+		return
+	}
+
+	values := page.values
+	if page.dictSize > 0 {
+		values = make([]interface{}, len(page.indices))
+		for i, idx := range page.indices {
+			values[i] = b.col.data.values.values[idx]
+		}
+	}
+
+	nullPage := page.numNulls == page.numValues
+	min, max := b.statsOpts.minMax(b.col, values)
+	if !nullPage && (min == nil || max == nil) {
+		// the page only contains values that are ignored in the statistics, e.g. NaN, so the
+		// column index would claim that it contains no values at all.
+		b.index.columnIndex = nil
+		return
+	}
+	if nullPage {
+		min, max = []byte{}, []byte{}
+	}
+
+	ci.NullPages = append(ci.NullPages, nullPage)
+	ci.MinValues = append(ci.MinValues, min)
+	ci.MaxValues = append(ci.MaxValues, max)
+	ci.NullCounts = append(ci.NullCounts, int64(page.numNulls))
+}
+
+// finish completes the page index of the column chunk.
+func (b *pageIndexBuilder) finish(chunk *parquet.ColumnChunk) *chunkPageIndex {
+	b.index.chunk = chunk
+	if ci := b.index.columnIndex; ci != nil {
+		ci.BoundaryOrder = boundaryOrder(b.col.Element(), ci, b.statsOpts.comparator(b.col))
+	}
+	return b.index
+}
+
+// boundaryOrder returns the order of the min and max values of the pages that aren't null pages.
+func boundaryOrder(elem *parquet.SchemaElement, ci *parquet.ColumnIndex, cmp BinaryComparator) parquet.BoundaryOrder {
+	ascending, descending := true, true
+	prev := -1
+	for i := range ci.NullPages {
+		if ci.NullPages[i] {
+			continue
+		}
+		if prev >= 0 {
+			cMin := compareStatValues(elem, ci.MinValues[prev], ci.MinValues[i], cmp)
+			cMax := compareStatValues(elem, ci.MaxValues[prev], ci.MaxValues[i], cmp)
+			if cMin > 0 || cMax > 0 {
+				ascending = false
+			}
+			if cMin < 0 || cMax < 0 {
+				descending = false
+			}
+		}
+		prev = i
+	}
+
+	switch {
+	case ascending:
+		return parquet.BoundaryOrder_ASCENDING
+	case descending:
+		return parquet.BoundaryOrder_DESCENDING
+	}
+	return parquet.BoundaryOrder_UNORDERED
+}
+
+// writePageIndexes writes the column indexes and then the offset indexes of the column chunks and
+// stores their locations in the column chunks.
+func writePageIndexes(w writePos, indexes []*chunkPageIndex) error {
+	for _, idx := range indexes {
+		if idx.columnIndex == nil {
+			continue
+		}
+		pos := w.Pos()
+		if err := writeThrift(idx.columnIndex, w); err != nil {
+			return errors.Wrap(err, "writing column index failed")
+		}
+		length := int32(w.Pos() - pos)
+		idx.chunk.ColumnIndexOffset = &pos
+		idx.chunk.ColumnIndexLength = &length
+	}
+
+	for _, idx := range indexes {
+		if idx.offsetIndex == nil {
+			continue
+		}
+		pos := w.Pos()
+		if err := writeThrift(idx.offsetIndex, w); err != nil {
+			return errors.Wrap(err, "writing offset index failed")
+		}
+		length := int32(w.Pos() - pos)
+		idx.chunk.OffsetIndexOffset = &pos
+		idx.chunk.OffsetIndexLength = &length
+	}
+
+	return nil
+}
+
+// PageIndex returns the column index and the offset index of the chunk of the column colName in
+// the row group with index rowGroup. The column name has to be provided in its dotted notation.
+// Both are nil if the file doesn't contain them. It doesn't change the position of the reader
+// for NextRow.
+func (f *FileReader) PageIndex(rowGroup int, colName string) (*parquet.ColumnIndex, *parquet.OffsetIndex, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return nil, nil, errors.Errorf("row group index %d is out of bounds", rowGroup)
+	}
+
+	col := f.GetColumnByName(colName)
+	if col == nil {
+		return nil, nil, errors.Errorf("column %q not found", colName)
+	}
+
+	rg := f.meta.RowGroups[rowGroup]
+	if len(rg.Columns) <= col.Index() {
+		return nil, nil, errors.Errorf("column index %d is out of bounds", col.Index())
+	}
+	chunk := rg.Columns[col.Index()]
+
+	dec, err := f.opts.decryptor.chunk(col, chunk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var columnIndex *parquet.ColumnIndex
+	if chunk.ColumnIndexOffset != nil && chunk.ColumnIndexLength != nil {
+		columnIndex = &parquet.ColumnIndex{}
+		if err := readPageIndexStruct(f.reader, columnIndex, *chunk.ColumnIndexOffset, *chunk.ColumnIndexLength, dec, columnIndexModule); err != nil {
+			return nil, nil, errors.Wrapf(err, "reading column index of column %s failed", colName)
+		}
+	}
+
+	var offsetIndex *parquet.OffsetIndex
+	if chunk.OffsetIndexOffset != nil && chunk.OffsetIndexLength != nil {
+		offsetIndex = &parquet.OffsetIndex{}
+		if err := readPageIndexStruct(f.reader, offsetIndex, *chunk.OffsetIndexOffset, *chunk.OffsetIndexLength, dec, offsetIndexModule); err != nil {
+			return nil, nil, errors.Wrapf(err, "reading offset index of column %s failed", colName)
+		}
+	}
+
+	return columnIndex, offsetIndex, nil
+}
+
+// readPageIndexStruct reads the column or offset index at offset. If dec is set, the index is
+// an encrypted module of the type module.
+func readPageIndexStruct(r io.ReadSeeker, tr thriftReader, offset int64, length int32, dec *chunkDecryptor, module byte) error {
+	if offset < 0 || length < 0 {
+		return errors.Errorf("invalid location %d with length %d", offset, length)
+	}
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if dec == nil {
+		return readThrift(tr, io.LimitReader(r, int64(length)))
+	}
+
+	data, err := dec.readModule(r, int64(length), module, 0)
+	if err != nil {
+		return err
+	}
+	return readThrift(tr, bytes.NewReader(data))
+}
@@ -0,0 +1,87 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageIndex(t *testing.T) {
+	data := writeValidateTestFile(t, WithPageIndex(), WithPageValueLimits(1, 30))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	for rg := 0; rg < 3; rg++ {
+		ci, oi, err := r.PageIndex(rg, "id")
+		require.NoError(t, err)
+		require.NotNil(t, ci)
+		require.NotNil(t, oi)
+
+		require.Len(t, oi.PageLocations, 4)
+		require.Equal(t, r.meta.RowGroups[rg].Columns[0].MetaData.DataPageOffset, oi.PageLocations[0].Offset)
+		for i, loc := range oi.PageLocations {
+			require.Equal(t, int64(i*30), loc.FirstRowIndex)
+			if i > 0 {
+				prev := oi.PageLocations[i-1]
+				require.Equal(t, prev.Offset+int64(prev.CompressedPageSize), loc.Offset)
+			}
+		}
+
+		require.Equal(t, parquet.BoundaryOrder_ASCENDING, ci.BoundaryOrder)
+		require.Equal(t, []bool{false, false, false, false}, ci.NullPages)
+		require.Equal(t, []int64{0, 0, 0, 0}, ci.NullCounts)
+		for i := range ci.MinValues {
+			require.Equal(t, int64(rg*100+i*30), int64(binary.LittleEndian.Uint64(ci.MinValues[i])))
+		}
+		require.Equal(t, int64(rg*100+99), int64(binary.LittleEndian.Uint64(ci.MaxValues[3])))
+	}
+
+	ci, oi, err := r.PageIndex(0, "name")
+	require.NoError(t, err)
+	require.Len(t, oi.PageLocations, 4)
+	require.Equal(t, []int64{8, 7, 8, 2}, ci.NullCounts)
+	require.Equal(t, []byte("a"), ci.MinValues[0])
+	require.Equal(t, []byte("e"), ci.MaxValues[0])
+	require.Equal(t, parquet.BoundaryOrder_ASCENDING, ci.BoundaryOrder)
+
+	_, oi, err = r.PageIndex(0, "tags")
+	require.NoError(t, err)
+	require.Len(t, oi.PageLocations, 7)
+	require.Equal(t, int64(15), oi.PageLocations[1].FirstRowIndex)
+
+	_, _, err = r.PageIndex(3, "id")
+	require.Error(t, err)
+	_, _, err = r.PageIndex(0, "unknown")
+	require.Error(t, err)
+
+	// reading the page index must not change the position for NextRow.
+	for i := 0; i < 300; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["id"])
+	}
+
+	r, err = NewFileReader(bytes.NewReader(writeValidateTestFile(t)))
+	require.NoError(t, err)
+	ci, oi, err = r.PageIndex(0, "id")
+	require.NoError(t, err)
+	require.Nil(t, ci)
+	require.Nil(t, oi)
+}
+
+func TestPageIndexNullPages(t *testing.T) {
+	ci := &parquet.ColumnIndex{
+		NullPages: []bool{false, true, false},
+		MinValues: [][]byte{{3}, {}, {1}},
+		MaxValues: [][]byte{{5}, {}, {2}},
+	}
+	elem := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BYTE_ARRAY)}
+	require.Equal(t, parquet.BoundaryOrder_DESCENDING, boundaryOrder(elem, ci, BinaryComparatorUnsigned))
+
+	ci.MinValues[2] = []byte{4}
+	require.Equal(t, parquet.BoundaryOrder_UNORDERED, boundaryOrder(elem, ci, BinaryComparatorUnsigned))
+}
@@ -0,0 +1,93 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// PageData is the raw data of a single page as it is passed through the page middleware.
+type PageData struct {
+	// Column is the flat name of the column the page belongs to.
+	Column string
+	// Header is the header of the page. UncompressedPageSize needs to be updated by middleware
+	// that changes the size of the data after decompression. CompressedPageSize is updated to the
+	// size of Data after all middleware was applied.
+	Header *parquet.PageHeader
+	// Codec is the compression codec that is used to decompress Data. Middleware that decompresses
+	// the data itself needs to set it to UNCOMPRESSED.
+	Codec parquet.CompressionCodec
+	// Data is the page data as it is stored in the file, i.e. before decompression. For
+	// DATA_PAGE_V2 pages, it starts with the uncompressed repetition and definition levels.
+	Data []byte
+}
+
+// PageMiddleware processes the raw data of a page after its header was read and before it is
+// decompressed and decoded, e.g. to decrypt data that was encrypted at rest, to check its
+// integrity or to cache it. It may replace the data of the page.
+type PageMiddleware func(page *PageData) error
+
+// applyPageMiddleware reads the data of the page with the provided header from r and passes it
+// through the middleware. It returns a reader for the resulting data, along with the header and
+// codec that need to be used to read it.
+func applyPageMiddleware(r io.Reader, ph *parquet.PageHeader, codec parquet.CompressionCodec, col *Column, middleware []PageMiddleware) (io.Reader, *parquet.PageHeader, parquet.CompressionCodec, error) {
+	if ph.CompressedPageSize < 0 {
+		return nil, nil, 0, errors.New("invalid page data size")
+	}
+
+	data := make([]byte, ph.CompressedPageSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, nil, 0, errors.Wrap(err, "reading page data failed")
+	}
+
+	page, err := runPageMiddleware(data, ph, codec, col, middleware)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return bytes.NewReader(page.Data), page.Header, page.Codec, nil
+}
+
+// runPageMiddleware passes the page data and a copy of its header through the middleware.
+func runPageMiddleware(data []byte, ph *parquet.PageHeader, codec parquet.CompressionCodec, col *Column, middleware []PageMiddleware) (*PageData, error) {
+	// the middleware works on a deep copy, so that changes to the nested headers don't affect the
+	// original header.
+	header, err := copyPageHeader(ph)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &PageData{
+		Column: col.FlatName(),
+		Header: header,
+		Codec:  codec,
+		Data:   data,
+	}
+
+	for _, mw := range middleware {
+		if err := mw(page); err != nil {
+			return nil, errors.Wrapf(err, "page middleware of column %s failed", page.Column)
+		}
+	}
+
+	page.Header.CompressedPageSize = int32(len(page.Data))
+
+	return page, nil
+}
+
+// copyPageHeader returns a deep copy of ph.
+func copyPageHeader(ph *parquet.PageHeader) (*parquet.PageHeader, error) {
+	buf := &bytes.Buffer{}
+	if err := writeThrift(ph, buf); err != nil {
+		return nil, errors.Wrap(err, "copying page header failed")
+	}
+
+	header := &parquet.PageHeader{}
+	if err := readThrift(header, buf); err != nil {
+		return nil, errors.Wrap(err, "copying page header failed")
+	}
+
+	return header, nil
+}
@@ -0,0 +1,152 @@
+package goparquet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageMiddleware(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	xor := func(data []byte) []byte {
+		res := make([]byte, len(data))
+		for i := range data {
+			res[i] = data[i] ^ 0x5a
+		}
+		return res
+	}
+
+	// the pages are "encrypted" and prefixed with a marker byte that needs to be removed.
+	encrypt := func(p *EncodedPage) *EncodedPage {
+		p.Data = append([]byte{0xff}, xor(p.Data)...)
+		p.Header.CompressedPageSize = int32(len(p.Data))
+		return p
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.WriteEncodedRowGroup(5, []*EncodedColumnChunk{{
+		Column: "foo",
+		Pages: []*EncodedPage{
+			encrypt(testDictPage(t, 10, 20, 30)),
+			encrypt(testDataPage(t, parquet.Encoding_RLE_DICTIONARY, 2, 1, 0)),
+			encrypt(testDataPage(t, parquet.Encoding_PLAIN, 40, 50)),
+		},
+	}}))
+	require.NoError(t, w.Close())
+
+	var pageTypes []parquet.PageType
+	decrypt := func(page *PageData) error {
+		if page.Data[0] != 0xff {
+			return errors.New("invalid marker")
+		}
+		page.Data = xor(page.Data[1:])
+		return nil
+	}
+	record := func(page *PageData) error {
+		require.Equal(t, "foo", page.Column)
+		require.Equal(t, parquet.CompressionCodec_UNCOMPRESSED, page.Codec)
+		pageTypes = append(pageTypes, page.Header.Type)
+		return nil
+	}
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithPageMiddleware(decrypt), WithPageMiddleware(record))
+	require.NoError(t, err)
+
+	for _, v := range []int64{30, 20, 10, 40, 50} {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"foo": v}, row)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, []parquet.PageType{parquet.PageType_DICTIONARY_PAGE, parquet.PageType_DATA_PAGE, parquet.PageType_DATA_PAGE}, pageTypes)
+
+	// without decryption, the data can't be read.
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+
+	// the middleware is applied in order, so the data is decrypted twice.
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithPageMiddleware(decrypt, decrypt))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid marker")
+
+	// Validate applies the middleware as well.
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	report, err := r.Validate(0, "foo")
+	require.NoError(t, err)
+	require.False(t, report.Valid())
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithPageMiddleware(decrypt))
+	require.NoError(t, err)
+	report, err = r.Validate(0, "foo")
+	require.NoError(t, err)
+	require.True(t, report.Valid(), "%v", report.Problems)
+	require.Len(t, report.Pages, 2)
+	report, err = r.ValidateValues(0, "foo")
+	require.NoError(t, err)
+	require.True(t, report.Valid(), "%v", report.Problems)
+}
+
+func TestPageMiddlewareHeaderCopy(t *testing.T) {
+	ph := &parquet.PageHeader{
+		Type:           parquet.PageType_DATA_PAGE,
+		DataPageHeader: &parquet.DataPageHeader{NumValues: 2, Encoding: parquet.Encoding_PLAIN},
+	}
+	col := &Column{name: "foo", flatName: "foo"}
+
+	page, err := runPageMiddleware([]byte{1, 2}, ph, parquet.CompressionCodec_UNCOMPRESSED, col, []PageMiddleware{func(page *PageData) error {
+		page.Header.DataPageHeader.NumValues = 1
+		page.Data = page.Data[:1]
+		return nil
+	}})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), page.Header.DataPageHeader.NumValues)
+	require.Equal(t, int32(1), page.Header.CompressedPageSize)
+	require.Equal(t, int32(2), ph.DataPageHeader.NumValues)
+	require.Equal(t, int32(0), ph.CompressedPageSize)
+}
+
+func TestPageMiddlewareDecompression(t *testing.T) {
+	data := writeValidateTestFile(t, WithCompressionCodec(parquet.CompressionCodec_GZIP), WithDataPageV2())
+
+	var decompressed int
+	decompress := func(page *PageData) error {
+		levelsSize := 0
+		if h := page.Header.DataPageHeaderV2; h != nil {
+			levelsSize = int(h.RepetitionLevelsByteLength + h.DefinitionLevelsByteLength)
+		}
+		values, err := decompressBlock(page.Data[levelsSize:], page.Codec)
+		if err != nil {
+			return err
+		}
+		page.Data = append(page.Data[:levelsSize:levelsSize], values...)
+		page.Codec = parquet.CompressionCodec_UNCOMPRESSED
+		decompressed++
+		return nil
+	}
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithPageMiddleware(decompress))
+	require.NoError(t, err)
+
+	for i := 0; i < 300; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["id"])
+	}
+	require.True(t, decompressed >= 9)
+}
@@ -24,34 +24,41 @@ func (dp *dataPageReaderV1) numValues() int32 {
 	return dp.valuesCount
 }
 
-func (dp *dataPageReaderV1) readValues(val []interface{}) (n int, dLevel *packedArray, rLevel *packedArray, err error) {
+func (dp *dataPageReaderV1) numNulls() int32 {
+	return 0
+}
+
+func (dp *dataPageReaderV1) valuesEncoding() parquet.Encoding {
+	return dp.encoding
+}
+
+func (dp *dataPageReaderV1) readValues(val []interface{}) (n, notNull int, dLevel *packedArray, rLevel *packedArray, err error) {
 	size := len(val)
 	if rem := int(dp.valuesCount) - dp.position; rem < size {
 		size = rem
 	}
 
 	if size == 0 {
-		return 0, nil, nil, nil
+		return 0, 0, nil, nil, nil
 	}
 
 	rLevel, _, err = decodePackedArray(dp.rDecoder, size)
 	if err != nil {
-		return 0, nil, nil, errors.Wrap(err, "read repetition levels failed")
+		return 0, 0, nil, nil, errors.Wrap(err, "read repetition levels failed")
 	}
 
-	var notNull int
 	dLevel, notNull, err = decodePackedArray(dp.dDecoder, size)
 	if err != nil {
-		return 0, nil, nil, errors.Wrap(err, "read definition levels failed")
+		return 0, 0, nil, nil, errors.Wrap(err, "read definition levels failed")
 	}
 
 	if notNull != 0 {
 		if n, err := dp.valuesDecoder.decodeValues(val[:notNull]); err != nil {
-			return 0, nil, nil, errors.Wrapf(err, "read values from page failed, need %d value read %d", notNull, n)
+			return 0, 0, nil, nil, errors.Wrapf(err, "read values from page failed, need %d value read %d", notNull, n)
 		}
 	}
 	dp.position += size
-	return size, dLevel, rLevel, nil
+	return size, notNull, dLevel, rLevel, nil
 }
 
 func (dp *dataPageReaderV1) init(dDecoder, rDecoder getLevelDecoder, values getValueDecoderFn) error {
@@ -60,12 +67,12 @@ func (dp *dataPageReaderV1) init(dDecoder, rDecoder getLevelDecoder, values getV
 	}
 
 	var err error
-	dp.rDecoder, err = rDecoder(dp.ph.DataPageHeader.RepetitionLevelEncoding)
+	dp.rDecoder, err = rDecoder(dp.ph.DataPageHeader.RepetitionLevelEncoding, dp.ph.DataPageHeader.NumValues)
 	if err != nil {
 		return err
 	}
 
-	dp.dDecoder, err = dDecoder(dp.ph.DataPageHeader.DefinitionLevelEncoding)
+	dp.dDecoder, err = dDecoder(dp.ph.DataPageHeader.DefinitionLevelEncoding, dp.ph.DataPageHeader.NumValues)
 	if err != nil {
 		return err
 	}
@@ -108,10 +115,12 @@ func (dp *dataPageReaderV1) read(r io.Reader, ph *parquet.PageHeader, codec parq
 }
 
 type dataPageWriterV1 struct {
-	col *Column
+	col  *Column
+	page *dataPage
 
 	codec      parquet.CompressionCodec
 	dictionary bool
+	writeCRC   bool
 }
 
 func (dp *dataPageWriterV1) init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec) error {
@@ -120,18 +129,19 @@ func (dp *dataPageWriterV1) init(schema SchemaWriter, col *Column, codec parquet
 	return nil
 }
 
+func (dp *dataPageWriterV1) setPage(page *dataPage) {
+	dp.page = page
+}
+
 func (dp *dataPageWriterV1) getHeader(comp, unComp int) *parquet.PageHeader {
-	enc := dp.col.data.encoding()
-	if dp.dictionary {
-		enc = parquet.Encoding_RLE_DICTIONARY
-	}
+	enc := dataPageEncoding(dp.col, dp.dictionary)
 	ph := &parquet.PageHeader{
 		Type:                 parquet.PageType_DATA_PAGE,
 		UncompressedPageSize: int32(unComp),
 		CompressedPageSize:   int32(comp),
 		Crc:                  nil,
 		DataPageHeader: &parquet.DataPageHeader{
-			NumValues: dp.col.data.values.numValues() + dp.col.data.values.nullValueCount(),
+			NumValues: dp.page.numValues,
 			Encoding:  enc,
 			// Only RLE supported for now, not sure if we need support for more encoding
 			DefinitionLevelEncoding: parquet.Encoding_RLE,
@@ -146,30 +156,19 @@ func (dp *dataPageWriterV1) write(w io.Writer) (int, int, error) {
 	dataBuf := &bytes.Buffer{}
 	// Only write repetition value higher than zero
 	if dp.col.MaxRepetitionLevel() > 0 {
-		if err := encodeLevelsV1(dataBuf, dp.col.MaxRepetitionLevel(), dp.col.data.rLevels); err != nil {
+		if err := encodeLevelsV1(dataBuf, dp.col.MaxRepetitionLevel(), dp.page.rLevels); err != nil {
 			return 0, 0, err
 		}
 	}
 
 	// Only write definition value higher than zero
 	if dp.col.MaxDefinitionLevel() > 0 {
-		if err := encodeLevelsV1(dataBuf, dp.col.MaxDefinitionLevel(), dp.col.data.dLevels); err != nil {
+		if err := encodeLevelsV1(dataBuf, dp.col.MaxDefinitionLevel(), dp.page.dLevels); err != nil {
 			return 0, 0, err
 		}
 	}
 
-	enc := dp.col.data.encoding()
-	if dp.dictionary {
-		enc = parquet.Encoding_RLE_DICTIONARY
-	}
-
-	encoder, err := getValuesEncoder(enc, dp.col.Element(), dp.col.data.values)
-	if err != nil {
-		return 0, 0, err
-	}
-
-	err = encodeValue(dataBuf, encoder, dp.col.data.values.assemble())
-	if err != nil {
+	if err := encodePageValues(dataBuf, dp.col, dp.dictionary, dp.page); err != nil {
 		return 0, 0, err
 	}
 
@@ -180,6 +179,9 @@ func (dp *dataPageWriterV1) write(w io.Writer) (int, int, error) {
 	compSize, unCompSize := len(comp), len(dataBuf.Bytes())
 
 	header := dp.getHeader(compSize, unCompSize)
+	if dp.writeCRC {
+		header.Crc = pageCRC(comp)
+	}
 	if err := writeThrift(header, w); err != nil {
 		return 0, 0, err
 	}
@@ -187,8 +189,9 @@ func (dp *dataPageWriterV1) write(w io.Writer) (int, int, error) {
 	return compSize, unCompSize, writeFull(w, comp)
 }
 
-func newDataPageV1Writer(useDict bool) pageWriter {
+func newDataPageV1Writer(useDict bool, writeCRC bool) dataPageWriter {
 	return &dataPageWriterV1{
 		dictionary: useDict,
+		writeCRC:   writeCRC,
 	}
 }
@@ -3,6 +3,7 @@ package goparquet
 import (
 	"bytes"
 	"io"
+	"io/ioutil"
 
 	"github.com/fraugster/parquet-go/parquet"
 	"github.com/pkg/errors"
@@ -12,6 +13,7 @@ type dataPageReaderV2 struct {
 	ph *parquet.PageHeader
 
 	valuesCount        int32
+	nullsCount         int32
 	encoding           parquet.Encoding
 	valuesDecoder      valuesDecoder
 	dDecoder, rDecoder levelDecoder
@@ -23,44 +25,58 @@ func (dp *dataPageReaderV2) numValues() int32 {
 	return dp.valuesCount
 }
 
-func (dp *dataPageReaderV2) readValues(val []interface{}) (n int, dLevel *packedArray, rLevel *packedArray, err error) {
+func (dp *dataPageReaderV2) numNulls() int32 {
+	return dp.nullsCount
+}
+
+func (dp *dataPageReaderV2) valuesEncoding() parquet.Encoding {
+	return dp.encoding
+}
+
+func (dp *dataPageReaderV2) readValues(val []interface{}) (n, notNull int, dLevel *packedArray, rLevel *packedArray, err error) {
 	size := len(val)
 	if rem := int(dp.valuesCount) - dp.position; rem < size {
 		size = rem
 	}
 
 	if size == 0 {
-		return 0, nil, nil, nil
+		return 0, 0, nil, nil, nil
 	}
 
 	rLevel, _, err = decodePackedArray(dp.rDecoder, size)
 	if err != nil {
-		return 0, nil, nil, errors.Wrap(err, "read repetition levels failed")
+		return 0, 0, nil, nil, errors.Wrap(err, "read repetition levels failed")
 	}
 
-	var notNull int
 	dLevel, notNull, err = decodePackedArray(dp.dDecoder, size)
 	if err != nil {
-		return 0, nil, nil, errors.Wrap(err, "read definition levels failed")
+		return 0, 0, nil, nil, errors.Wrap(err, "read definition levels failed")
 	}
 
 	if notNull != 0 {
+		if dp.valuesDecoder == nil {
+			return 0, 0, nil, nil, errors.Errorf("page contains %d values but its header declares only null values", notNull)
+		}
 		if n, err := dp.valuesDecoder.decodeValues(val[:notNull]); err != nil {
-			return 0, nil, nil, errors.Wrapf(err, "read values from page failed, need %d values but read %d", notNull, n)
+			return 0, 0, nil, nil, errors.Wrapf(err, "read values from page failed, need %d values but read %d", notNull, n)
 		}
 	}
 	dp.position += size
-	return size, dLevel, rLevel, nil
+	return size, notNull, dLevel, rLevel, nil
 }
 
 func (dp *dataPageReaderV2) init(dDecoder, rDecoder getLevelDecoder, values getValueDecoderFn) error {
+	if dp.ph.DataPageHeaderV2 == nil {
+		return errors.New("page header is missing data page header V2")
+	}
+
 	var err error
 	// Page v2 dose not have any encoding for the levels
-	dp.dDecoder, err = dDecoder(parquet.Encoding_RLE)
+	dp.dDecoder, err = dDecoder(parquet.Encoding_RLE, dp.ph.DataPageHeaderV2.GetNumValues())
 	if err != nil {
 		return err
 	}
-	dp.rDecoder, err = rDecoder(parquet.Encoding_RLE)
+	dp.rDecoder, err = rDecoder(parquet.Encoding_RLE, dp.ph.DataPageHeaderV2.GetNumValues())
 	if err != nil {
 		return err
 	}
@@ -80,6 +96,9 @@ func (dp *dataPageReaderV2) read(r io.Reader, ph *parquet.PageHeader, codec parq
 	if dp.valuesCount = ph.DataPageHeaderV2.NumValues; dp.valuesCount < 0 {
 		return errors.Errorf("negative NumValues in DATA_PAGE_V2: %d", dp.valuesCount)
 	}
+	if dp.nullsCount = ph.DataPageHeaderV2.NumNulls; dp.nullsCount < 0 || dp.nullsCount > dp.valuesCount {
+		return errors.Errorf("invalid NumNulls in DATA_PAGE_V2: %d", dp.nullsCount)
+	}
 
 	if ph.DataPageHeaderV2.RepetitionLevelsByteLength < 0 {
 		return errors.Errorf("invalid RepetitionLevelsByteLength")
@@ -90,7 +109,11 @@ func (dp *dataPageReaderV2) read(r io.Reader, ph *parquet.PageHeader, codec parq
 	dp.encoding = ph.DataPageHeaderV2.Encoding
 	dp.ph = ph
 
-	{ // to hide the govet shadow error
+	// pages that only contain null values don't need a values decoder, so their values aren't
+	// decompressed at all.
+	nullsOnly := dp.valuesCount > 0 && dp.nullsCount == dp.valuesCount
+	dp.valuesDecoder = nil
+	if !nullsOnly {
 		var err error
 		if dp.valuesDecoder, err = dp.fn(dp.encoding); err != nil {
 			return err
@@ -120,6 +143,19 @@ func (dp *dataPageReaderV2) read(r io.Reader, ph *parquet.PageHeader, codec parq
 		}
 	}
 
+	// the levels are never compressed, and the values are only compressed if is_compressed is set.
+	if !ph.DataPageHeaderV2.GetIsCompressed() {
+		codec = parquet.CompressionCodec_UNCOMPRESSED
+	}
+
+	if nullsOnly {
+		size := int64(ph.GetCompressedPageSize() - levelsSize)
+		if n, err := io.CopyN(ioutil.Discard, r, size); err != nil {
+			return errors.Wrapf(err, "need to skip %d byte but there was only %d byte", size, n)
+		}
+		return nil
+	}
+
 	reader, err := createDataReader(r, codec, ph.GetCompressedPageSize()-levelsSize, ph.GetUncompressedPageSize()-levelsSize)
 	if err != nil {
 		return err
@@ -129,34 +165,35 @@ func (dp *dataPageReaderV2) read(r io.Reader, ph *parquet.PageHeader, codec parq
 }
 
 type dataPageWriterV2 struct {
-	col    *Column
-	schema SchemaWriter
+	col  *Column
+	page *dataPage
 
 	codec      parquet.CompressionCodec
 	dictionary bool
+	writeCRC   bool
 }
 
 func (dp *dataPageWriterV2) init(schema SchemaWriter, col *Column, codec parquet.CompressionCodec) error {
 	dp.col = col
 	dp.codec = codec
-	dp.schema = schema
 	return nil
 }
 
+func (dp *dataPageWriterV2) setPage(page *dataPage) {
+	dp.page = page
+}
+
 func (dp *dataPageWriterV2) getHeader(comp, unComp, defSize, repSize int, isCompressed bool) *parquet.PageHeader {
-	enc := dp.col.data.encoding()
-	if dp.dictionary {
-		enc = parquet.Encoding_RLE_DICTIONARY
-	}
+	enc := dataPageEncoding(dp.col, dp.dictionary)
 	ph := &parquet.PageHeader{
 		Type:                 parquet.PageType_DATA_PAGE_V2,
 		UncompressedPageSize: int32(unComp + defSize + repSize),
 		CompressedPageSize:   int32(comp + defSize + repSize),
 		Crc:                  nil,
 		DataPageHeaderV2: &parquet.DataPageHeaderV2{
-			NumValues:                  dp.col.data.values.numValues() + dp.col.data.values.nullValueCount(),
-			NumNulls:                   dp.col.data.values.nullValueCount(),
-			NumRows:                    int32(dp.schema.rowGroupNumRecords()),
+			NumValues:                  dp.page.numValues,
+			NumNulls:                   dp.page.numNulls,
+			NumRows:                    dp.page.numRows,
 			Encoding:                   enc,
 			DefinitionLevelsByteLength: int32(defSize),
 			RepetitionLevelsByteLength: int32(repSize),
@@ -172,7 +209,7 @@ func (dp *dataPageWriterV2) write(w io.Writer) (int, int, error) {
 
 	// Only write repetition value higher than zero
 	if dp.col.MaxRepetitionLevel() > 0 {
-		if err := encodeLevelsV2(rep, dp.col.MaxRepetitionLevel(), dp.col.data.rLevels); err != nil {
+		if err := encodeLevelsV2(rep, dp.col.MaxRepetitionLevel(), dp.page.rLevels); err != nil {
 			return 0, 0, err
 		}
 	}
@@ -181,23 +218,13 @@ func (dp *dataPageWriterV2) write(w io.Writer) (int, int, error) {
 
 	// Only write definition level higher than zero
 	if dp.col.MaxDefinitionLevel() > 0 {
-		if err := encodeLevelsV2(def, dp.col.MaxDefinitionLevel(), dp.col.data.dLevels); err != nil {
+		if err := encodeLevelsV2(def, dp.col.MaxDefinitionLevel(), dp.page.dLevels); err != nil {
 			return 0, 0, err
 		}
 	}
 
 	dataBuf := &bytes.Buffer{}
-	enc := dp.col.data.encoding()
-	if dp.dictionary {
-		enc = parquet.Encoding_RLE_DICTIONARY
-	}
-
-	encoder, err := getValuesEncoder(enc, dp.col.Element(), dp.col.data.values)
-	if err != nil {
-		return 0, 0, err
-	}
-
-	if err = encodeValue(dataBuf, encoder, dp.col.data.values.assemble()); err != nil {
+	if err := encodePageValues(dataBuf, dp.col, dp.dictionary, dp.page); err != nil {
 		return 0, 0, err
 	}
 
@@ -208,6 +235,9 @@ func (dp *dataPageWriterV2) write(w io.Writer) (int, int, error) {
 	compSize, unCompSize := len(comp), len(dataBuf.Bytes())
 	defLen, repLen := def.Len(), rep.Len()
 	header := dp.getHeader(compSize, unCompSize, defLen, repLen, dp.codec != parquet.CompressionCodec_UNCOMPRESSED)
+	if dp.writeCRC {
+		header.Crc = pageCRC(rep.Bytes(), def.Bytes(), comp)
+	}
 	if err := writeThrift(header, w); err != nil {
 		return 0, 0, err
 	}
@@ -223,8 +253,9 @@ func (dp *dataPageWriterV2) write(w io.Writer) (int, int, error) {
 	return compSize + defLen + repLen, unCompSize + defLen + repLen, writeFull(w, comp)
 }
 
-func newDataPageV2Writer(useDict bool) pageWriter {
+func newDataPageV2Writer(useDict bool, writeCRC bool) dataPageWriter {
 	return &dataPageWriterV2{
 		dictionary: useDict,
+		writeCRC:   writeCRC,
 	}
 }
@@ -0,0 +1,163 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDataPageV2IsCompressed(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	encodePage := func(compressed bool, values ...int64) *EncodedPage {
+		levels := &packedArray{}
+		levels.reset(1)
+		for _, v := range values {
+			if v < 0 {
+				levels.appendSingle(0)
+			} else {
+				levels.appendSingle(1)
+			}
+		}
+		levels.flush()
+		def := &bytes.Buffer{}
+		require.NoError(t, encodeLevelsV2(def, 1, levels))
+
+		data := &bytes.Buffer{}
+		for _, v := range values {
+			if v >= 0 {
+				require.NoError(t, binary.Write(data, binary.LittleEndian, v))
+			}
+		}
+		uncompressedSize := data.Len()
+		block := data.Bytes()
+		if compressed {
+			block, err = compressBlock(block, parquet.CompressionCodec_GZIP)
+			require.NoError(t, err)
+		}
+
+		return &EncodedPage{
+			Header: &parquet.PageHeader{
+				Type:                 parquet.PageType_DATA_PAGE_V2,
+				UncompressedPageSize: int32(def.Len() + uncompressedSize),
+				CompressedPageSize:   int32(def.Len() + len(block)),
+				DataPageHeaderV2: &parquet.DataPageHeaderV2{
+					NumValues:                  int32(len(values)),
+					NumRows:                    int32(len(values)),
+					Encoding:                   parquet.Encoding_PLAIN,
+					DefinitionLevelsByteLength: int32(def.Len()),
+					IsCompressed:               compressed,
+				},
+			},
+			Data: append(def.Bytes(), block...),
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.WriteEncodedRowGroup(5, []*EncodedColumnChunk{
+		{
+			Column: "foo",
+			Codec:  parquet.CompressionCodec_GZIP,
+			Pages: []*EncodedPage{
+				encodePage(false, 1, 2),
+				encodePage(true, -1, 3, 4),
+			},
+		},
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	for _, v := range []int64{1, 2, -1, 3, 4} {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		if v < 0 {
+			require.Equal(t, map[string]interface{}{}, row)
+		} else {
+			require.Equal(t, map[string]interface{}{"foo": v}, row)
+		}
+	}
+
+	report, err := r.Validate(0, "foo")
+	require.NoError(t, err)
+	require.True(t, report.Valid(), "%v", report.Problems)
+}
+
+func TestDataPageV2NullsAndRows(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional int64 value;
+		repeated int32 list;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf,
+		WithSchemaDefinition(sd),
+		WithDataPageV2(),
+		WithCompressionCodec(parquet.CompressionCodec_GZIP),
+		WithPageValueLimits(1, 10),
+	)
+	for i := 0; i < 30; i++ {
+		data := map[string]interface{}{"list": []int32{int32(i), int32(i)}}
+		if i < 10 || i >= 20 {
+			data["value"] = int64(i)
+		}
+		require.NoError(t, w.AddData(data))
+	}
+	require.NoError(t, w.Close())
+
+	var headers []*parquet.DataPageHeaderV2
+	corrupt := func(page *PageData) error {
+		h := page.Header.DataPageHeaderV2
+		if h == nil {
+			return nil
+		}
+		headers = append(headers, h)
+		if page.Column == "value" && h.NumNulls == h.NumValues {
+			// the values of pages that only contain nulls must not be decompressed.
+			levels := int(h.DefinitionLevelsByteLength + h.RepetitionLevelsByteLength)
+			for i := levels; i < len(page.Data); i++ {
+				page.Data[i] ^= 0xff
+			}
+		}
+		return nil
+	}
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithPageMiddleware(corrupt))
+	require.NoError(t, err)
+	for i := 0; i < 30; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		if i < 10 || i >= 20 {
+			require.Equal(t, int64(i), row["value"])
+		} else {
+			require.NotContains(t, row, "value")
+		}
+		require.Equal(t, []int32{int32(i), int32(i)}, row["list"])
+	}
+
+	require.Len(t, headers, 3+6)
+	for i, h := range headers[:3] {
+		require.Equal(t, int32(10), h.NumValues)
+		require.Equal(t, int32(10), h.NumRows)
+		if i == 1 {
+			require.Equal(t, int32(10), h.NumNulls)
+		} else {
+			require.Equal(t, int32(0), h.NumNulls)
+		}
+	}
+	for _, h := range headers[3:] {
+		require.Equal(t, int32(10), h.NumValues)
+		require.Equal(t, int32(5), h.NumRows)
+		require.Equal(t, int32(0), h.NumNulls)
+	}
+}
@@ -0,0 +1,288 @@
+package goparquet
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/pkg/errors"
+)
+
+// PandasMetadataKey is the key of the key-value meta data under which pandas stores the
+// information that is needed to restore the index and the types of a DataFrame.
+const PandasMetadataKey = "pandas"
+
+// PandasMetadata is the content of the pandas key-value meta data.
+type PandasMetadata struct {
+	// IndexColumns describes the index of the DataFrame, either by the field names of the
+	// columns that contain the index or by a range index.
+	IndexColumns []PandasIndex `json:"index_columns"`
+	// ColumnIndexes describes the levels of the column labels of the DataFrame.
+	ColumnIndexes []PandasColumn `json:"column_indexes"`
+	// Columns describes the columns of the file, including the columns of the index.
+	Columns []PandasColumn `json:"columns"`
+	// Creator is the library that wrote the meta data.
+	Creator *PandasCreator `json:"creator,omitempty"`
+	// PandasVersion is the version of pandas that wrote the meta data.
+	PandasVersion string `json:"pandas_version,omitempty"`
+}
+
+// PandasColumn describes the pandas type of a column.
+type PandasColumn struct {
+	// Name is the name of the column in the DataFrame, nil for unnamed index columns.
+	Name *string `json:"name"`
+	// FieldName is the name of the column in the parquet file. It is empty for the levels of the
+	// column labels, which is stored as null.
+	FieldName string `json:"field_name"`
+	// PandasType is the logical pandas type, e.g. "int64", "unicode" or "datetimetz".
+	PandasType string `json:"pandas_type"`
+	// NumpyType is the type of the numpy array, e.g. "int64", "object" or "datetime64[ns]".
+	NumpyType string `json:"numpy_type"`
+	// Metadata contains type-specific details like the time zone or the precision and scale of
+	// decimals.
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// MarshalJSON encodes the column like pandas, with an empty field name as null.
+func (c PandasColumn) MarshalJSON() ([]byte, error) {
+	type column PandasColumn
+	var fieldName *string
+	if c.FieldName != "" {
+		fieldName = &c.FieldName
+	}
+	return json.Marshal(struct {
+		column
+		FieldName *string `json:"field_name"`
+	}{column: column(c), FieldName: fieldName})
+}
+
+// PandasCreator describes the library that wrote the pandas meta data.
+type PandasCreator struct {
+	Library string `json:"library"`
+	Version string `json:"version"`
+}
+
+// PandasIndex describes an index of a DataFrame. It is either stored in the column Column of the
+// file, or it is a range index described by Range.
+type PandasIndex struct {
+	Column string
+	Range  *PandasRangeIndex
+}
+
+// PandasRangeIndex describes a range index that isn't stored in the file.
+type PandasRangeIndex struct {
+	Name  *string `json:"name"`
+	Start int64   `json:"start"`
+	Stop  int64   `json:"stop"`
+	Step  int64   `json:"step"`
+}
+
+// MarshalJSON encodes the index as the field name of its column or as a range index object.
+func (idx PandasIndex) MarshalJSON() ([]byte, error) {
+	if idx.Range != nil {
+		return json.Marshal(struct {
+			Kind string `json:"kind"`
+			*PandasRangeIndex
+		}{Kind: "range", PandasRangeIndex: idx.Range})
+	}
+	return json.Marshal(idx.Column)
+}
+
+// UnmarshalJSON decodes the index from the field name of its column or a range index object.
+func (idx *PandasIndex) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &idx.Column); err == nil {
+		idx.Range = nil
+		return nil
+	}
+
+	var obj struct {
+		Kind string `json:"kind"`
+		PandasRangeIndex
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	if obj.Kind != "range" {
+		return errors.Errorf("unsupported index kind %q", obj.Kind)
+	}
+	idx.Column, idx.Range = "", &obj.PandasRangeIndex
+	return nil
+}
+
+// ParsePandasMetadata parses the pandas key-value meta data.
+func ParsePandasMetadata(data []byte) (*PandasMetadata, error) {
+	m := &PandasMetadata{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrap(err, "invalid pandas meta data")
+	}
+	return m, nil
+}
+
+// Marshal encodes the pandas meta data.
+func (m *PandasMetadata) Marshal() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// Column returns the description of the column with the field name fieldName, or nil if there is
+// none.
+func (m *PandasMetadata) Column(fieldName string) *PandasColumn {
+	for i := range m.Columns {
+		if m.Columns[i].FieldName == fieldName {
+			return &m.Columns[i]
+		}
+	}
+	return nil
+}
+
+// IndexColumnNames returns the field names of the columns that contain the index, in the order of
+// the index levels.
+func (m *PandasMetadata) IndexColumnNames() []string {
+	var names []string
+	for _, idx := range m.IndexColumns {
+		if idx.Range == nil {
+			names = append(names, idx.Column)
+		}
+	}
+	return names
+}
+
+// NewPandasMetadata creates pandas meta data for a file with the schema definition sd, in which
+// the top-level columns indexColumns contain the index of the DataFrame. Without index columns,
+// pandas uses a default range index. The pandas types are derived from the types of the columns.
+func NewPandasMetadata(sd *parquetschema.SchemaDefinition, indexColumns ...string) (*PandasMetadata, error) {
+	if sd == nil || sd.RootColumn == nil {
+		return nil, errors.New("schema definition has no root column")
+	}
+
+	m := &PandasMetadata{
+		IndexColumns: []PandasIndex{},
+		ColumnIndexes: []PandasColumn{
+			{PandasType: "unicode", NumpyType: "object", Metadata: map[string]interface{}{"encoding": "UTF-8"}},
+		},
+		Creator: &PandasCreator{Library: "parquet-go"},
+	}
+
+	for _, name := range indexColumns {
+		if findColumnDefinition(sd.RootColumn.Children, name) == nil {
+			return nil, errors.Errorf("index column %q not found", name)
+		}
+		m.IndexColumns = append(m.IndexColumns, PandasIndex{Column: name})
+	}
+
+	for _, col := range sd.RootColumn.Children {
+		fieldName := col.SchemaElement.GetName()
+		name := &fieldName
+		// pyarrow stores unnamed index levels in columns named like __index_level_0__.
+		if strings.HasPrefix(fieldName, "__index_level_") && strings.HasSuffix(fieldName, "__") {
+			name = nil
+		}
+		pandasType, numpyType, metadata := pandasColumnType(col)
+		m.Columns = append(m.Columns, PandasColumn{
+			Name:       name,
+			FieldName:  fieldName,
+			PandasType: pandasType,
+			NumpyType:  numpyType,
+			Metadata:   metadata,
+		})
+	}
+
+	return m, nil
+}
+
+// pandasColumnType returns the pandas type, the numpy type and the type-specific meta data of
+// a column.
+func pandasColumnType(col *parquetschema.ColumnDefinition) (string, string, map[string]interface{}) {
+	elem := col.SchemaElement
+	if elem.Type == nil {
+		if isListElement(elem) {
+			for len(col.Children) == 1 && col.SchemaElement.Type == nil {
+				col = col.Children[0]
+			}
+			if col.SchemaElement.Type != nil {
+				typ, _, _ := pandasColumnType(col)
+				return "list[" + typ + "]", "object", nil
+			}
+		}
+		return "object", "object", nil
+	}
+
+	lt := elem.LogicalType
+	switch {
+	case parquetschema.IsUTF8String(elem):
+		return "unicode", "object", nil
+	case isDecimalElement(elem):
+		return "decimal", "object", map[string]interface{}{"precision": elem.GetPrecision(), "scale": elem.GetScale()}
+	case lt != nil && lt.IsSetDATE(), elem.ConvertedType != nil && elem.GetConvertedType() == parquet.ConvertedType_DATE:
+		return "date", "object", nil
+	case lt != nil && lt.IsSetTIMESTAMP():
+		if lt.TIMESTAMP.IsAdjustedToUTC {
+			return "datetimetz", "datetime64[ns]", map[string]interface{}{"timezone": "UTC"}
+		}
+		return "datetime", "datetime64[ns]", nil
+	case elem.ConvertedType != nil && (elem.GetConvertedType() == parquet.ConvertedType_TIMESTAMP_MILLIS || elem.GetConvertedType() == parquet.ConvertedType_TIMESTAMP_MICROS):
+		return "datetimetz", "datetime64[ns]", map[string]interface{}{"timezone": "UTC"}
+	case elem.GetType() == parquet.Type_INT96:
+		return "datetime", "datetime64[ns]", nil
+	}
+
+	switch elem.GetType() {
+	case parquet.Type_BOOLEAN:
+		return "bool", "bool", nil
+	case parquet.Type_INT32, parquet.Type_INT64:
+		typ := "int"
+		bitWidth := int8(32)
+		if elem.GetType() == parquet.Type_INT64 {
+			bitWidth = 64
+		}
+		if lt != nil && lt.IsSetINTEGER() {
+			bitWidth = lt.INTEGER.BitWidth
+			if !lt.INTEGER.IsSigned {
+				typ = "uint"
+			}
+		} else if elem.ConvertedType != nil {
+			if it, ok := integerConvertedTypes[elem.GetConvertedType()]; ok {
+				bitWidth = it.BitWidth
+				if !it.IsSigned {
+					typ = "uint"
+				}
+			}
+		}
+		typ = fmt.Sprintf("%s%d", typ, bitWidth)
+		return typ, typ, nil
+	case parquet.Type_FLOAT:
+		return "float32", "float32", nil
+	case parquet.Type_DOUBLE:
+		return "float64", "float64", nil
+	}
+	return "bytes", "object", nil
+}
+
+// isListElement returns true if the group is annotated as LIST.
+func isListElement(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil && elem.LogicalType.IsSetLIST() {
+		return true
+	}
+	return elem.ConvertedType != nil && elem.GetConvertedType() == parquet.ConvertedType_LIST
+}
+
+// PandasMetadata returns the pandas key-value meta data of the file, or nil if there is none.
+func (f *FileReader) PandasMetadata() (*PandasMetadata, error) {
+	data, ok := f.MetaDataValue(PandasMetadataKey)
+	if !ok {
+		return nil, nil
+	}
+	return ParsePandasMetadata([]byte(data))
+}
+
+// SetPandasMetadata stores m as the pandas key-value meta data of the file, so that pandas can
+// restore the index and the types of a DataFrame when it reads the file.
+func (fw *FileWriter) SetPandasMetadata(m *PandasMetadata) error {
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	fw.SetMetaData(PandasMetadataKey, string(data))
+	return nil
+}
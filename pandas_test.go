@@ -0,0 +1,98 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+// pyarrowPandasMetadata was written by pyarrow 11.0.0 for a DataFrame with a named index.
+const pyarrowPandasMetadata = `{"index_columns": ["id"], "column_indexes": [{"name": null, "field_name": null, "pandas_type": "unicode", "numpy_type": "object", "metadata": {"encoding": "UTF-8"}}], "columns": [{"name": "name", "field_name": "name", "pandas_type": "unicode", "numpy_type": "object", "metadata": null}, {"name": "score", "field_name": "score", "pandas_type": "float64", "numpy_type": "float64", "metadata": null}, {"name": "id", "field_name": "id", "pandas_type": "int64", "numpy_type": "int64", "metadata": null}], "creator": {"library": "pyarrow", "version": "11.0.0"}, "pandas_version": "1.5.3"}`
+
+func TestParsePandasMetadata(t *testing.T) {
+	m, err := ParsePandasMetadata([]byte(pyarrowPandasMetadata))
+	require.NoError(t, err)
+	require.Equal(t, []string{"id"}, m.IndexColumnNames())
+	require.Equal(t, "float64", m.Column("score").PandasType)
+	require.Nil(t, m.Column("unknown"))
+	require.Nil(t, m.ColumnIndexes[0].Name)
+	require.Equal(t, &PandasCreator{Library: "pyarrow", Version: "11.0.0"}, m.Creator)
+
+	data, err := m.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, pyarrowPandasMetadata, string(data))
+
+	rangeIndex := `{"index_columns": [{"kind": "range", "name": null, "start": 0, "stop": 3, "step": 1}], "column_indexes": [], "columns": []}`
+	m, err = ParsePandasMetadata([]byte(rangeIndex))
+	require.NoError(t, err)
+	require.Empty(t, m.IndexColumnNames())
+	require.Equal(t, &PandasRangeIndex{Start: 0, Stop: 3, Step: 1}, m.IndexColumns[0].Range)
+
+	data, err = m.Marshal()
+	require.NoError(t, err)
+	require.JSONEq(t, rangeIndex, string(data))
+
+	_, err = ParsePandasMetadata([]byte(`{"index_columns": [{"kind": "multi"}]}`))
+	require.Error(t, err)
+}
+
+func TestPandasMetadataRoundTrip(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional binary name (STRING);
+		required int32 small (INT(16, true));
+		optional int64 count (INT(64, false));
+		optional int64 ts (TIMESTAMP(MICROS, true));
+		optional fixed_len_byte_array(8) price (DECIMAL(16, 2));
+		optional group tags (LIST) {
+			repeated group list {
+				optional double element;
+			}
+		}
+		required int64 __index_level_0__;
+	}`)
+	require.NoError(t, err)
+
+	m, err := NewPandasMetadata(sd, "__index_level_0__")
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.SetPandasMetadata(m))
+	require.NoError(t, w.AddData(map[string]interface{}{"small": int32(1), "__index_level_0__": int64(0)}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	read, err := r.PandasMetadata()
+	require.NoError(t, err)
+	require.Equal(t, []string{"__index_level_0__"}, read.IndexColumnNames())
+
+	types := make(map[string]string)
+	for _, col := range read.Columns {
+		types[col.FieldName] = col.PandasType + " " + col.NumpyType
+	}
+	require.Equal(t, map[string]string{
+		"name":              "unicode object",
+		"small":             "int16 int16",
+		"count":             "uint64 uint64",
+		"ts":                "datetimetz datetime64[ns]",
+		"price":             "decimal object",
+		"tags":              "list[float64] object",
+		"__index_level_0__": "int64 int64",
+	}, types)
+	require.Nil(t, read.Column("__index_level_0__").Name)
+	require.Equal(t, "UTC", read.Column("ts").Metadata["timezone"])
+	require.Equal(t, float64(16), read.Column("price").Metadata["precision"])
+
+	_, err = NewPandasMetadata(sd, "unknown")
+	require.Error(t, err)
+
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r.meta.KeyValueMetadata = nil
+	read, err = r.PandasMetadata()
+	require.NoError(t, err)
+	require.Nil(t, read)
+}
@@ -6819,6 +6819,11 @@ func (p *PageEncodingStats) String() string {
 // This information can be used to determine if all data pages are
 // dictionary encoded for example *
 //  - BloomFilterOffset: Byte offset from beginning of file to Bloom filter data. *
+//  - BloomFilterLength: Size of Bloom filter data including the serialized header, in bytes.
+// Added in 2.10 so readers may not read this field from old files and
+// it can be obtained after the BloomFilterHeader has been deserialized.
+// Writers should write this field so readers can read the bloom filter
+// in a single I/O.
 type ColumnMetaData struct {
 	Type                  Type                 `thrift:"type,1,required" db:"type" json:"type"`
 	Encodings             []Encoding           `thrift:"encodings,2,required" db:"encodings" json:"encodings"`
@@ -6834,6 +6839,7 @@ type ColumnMetaData struct {
 	Statistics            *Statistics          `thrift:"statistics,12" db:"statistics" json:"statistics,omitempty"`
 	EncodingStats         []*PageEncodingStats `thrift:"encoding_stats,13" db:"encoding_stats" json:"encoding_stats,omitempty"`
 	BloomFilterOffset     *int64               `thrift:"bloom_filter_offset,14" db:"bloom_filter_offset" json:"bloom_filter_offset,omitempty"`
+	BloomFilterLength     *int32               `thrift:"bloom_filter_length,15" db:"bloom_filter_length" json:"bloom_filter_length,omitempty"`
 }
 
 func NewColumnMetaData() *ColumnMetaData {
@@ -6919,6 +6925,15 @@ func (p *ColumnMetaData) GetBloomFilterOffset() int64 {
 	}
 	return *p.BloomFilterOffset
 }
+
+var ColumnMetaData_BloomFilterLength_DEFAULT int32
+
+func (p *ColumnMetaData) GetBloomFilterLength() int32 {
+	if !p.IsSetBloomFilterLength() {
+		return ColumnMetaData_BloomFilterLength_DEFAULT
+	}
+	return *p.BloomFilterLength
+}
 func (p *ColumnMetaData) IsSetKeyValueMetadata() bool {
 	return p.KeyValueMetadata != nil
 }
@@ -6943,6 +6958,10 @@ func (p *ColumnMetaData) IsSetBloomFilterOffset() bool {
 	return p.BloomFilterOffset != nil
 }
 
+func (p *ColumnMetaData) IsSetBloomFilterLength() bool {
+	return p.BloomFilterLength != nil
+}
+
 func (p *ColumnMetaData) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -7114,6 +7133,16 @@ func (p *ColumnMetaData) Read(iprot thrift.TProtocol) error {
 					return err
 				}
 			}
+		case 15:
+			if fieldTypeId == thrift.I32 {
+				if err := p.ReadField15(iprot); err != nil {
+					return err
+				}
+			} else {
+				if err := iprot.Skip(fieldTypeId); err != nil {
+					return err
+				}
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -7329,6 +7358,15 @@ func (p *ColumnMetaData) ReadField14(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *ColumnMetaData) ReadField15(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 15: ", err)
+	} else {
+		p.BloomFilterLength = &v
+	}
+	return nil
+}
+
 func (p *ColumnMetaData) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("ColumnMetaData"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -7376,6 +7414,9 @@ func (p *ColumnMetaData) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField14(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField15(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -7612,6 +7653,21 @@ func (p *ColumnMetaData) writeField14(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *ColumnMetaData) writeField15(oprot thrift.TProtocol) (err error) {
+	if p.IsSetBloomFilterLength() {
+		if err := oprot.WriteFieldBegin("bloom_filter_length", thrift.I32, 15); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 15:bloom_filter_length: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(*p.BloomFilterLength)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.bloom_filter_length (15) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 15:bloom_filter_length: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *ColumnMetaData) String() string {
 	if p == nil {
 		return "<nil>"
@@ -0,0 +1,25 @@
+/*
+Package parquetarrow writes Apache Arrow records to parquet files. It works in conjunction with
+the goparquet package, and is a separate module so that the goparquet package doesn't depend on
+the Arrow module.
+
+The Writer writes every record as a row group, and every column of a record as a column chunk,
+without assembling rows:
+
+	w, err := parquetarrow.NewWriter(file, schema, goparquet.WithCompressionCodec(parquet.CompressionCodec_SNAPPY))
+	if err != nil {
+		// ...
+	}
+	for reader.Next() {
+		if err := w.Write(reader.Record()); err != nil {
+			// ...
+		}
+	}
+	if err := w.Close(); err != nil {
+		// ...
+	}
+
+The parquet schema is derived from the Arrow schema as described for SchemaDefinition, and the
+Arrow schema itself is stored in the ARROW:schema entry of the key-value meta data of the file.
+*/
+package parquetarrow
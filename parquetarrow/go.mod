@@ -0,0 +1,11 @@
+module github.com/fraugster/parquet-go/parquetarrow
+
+go 1.15
+
+require (
+	github.com/apache/arrow/go/arrow v0.0.0-20211112161151-bc219186db40
+	github.com/fraugster/parquet-go v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.7.0
+)
+
+replace github.com/fraugster/parquet-go => ../
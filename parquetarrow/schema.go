@@ -0,0 +1,266 @@
+package parquetarrow
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// SchemaDefinition derives the parquet schema definition that a Writer uses to write records
+// with the Arrow schema. Every field of the Arrow schema becomes a column, and struct fields
+// become groups. Nullable fields are optional, all other fields are required. The Arrow types
+// are mapped to parquet types as follows:
+//
+//	bool                        boolean
+//	int8, int16, int32          int32 (INT(8, true), INT(16, true), INT(32, true))
+//	uint8, uint16, uint32       int32 (INT(8, false), INT(16, false), INT(32, false))
+//	int64                       int64 (INT(64, true))
+//	uint64                      int64 (INT(64, false))
+//	float32, float64            float, double
+//	utf8                        binary (STRING)
+//	binary                      binary
+//	fixed_size_binary(n)        fixed_len_byte_array(n)
+//	decimal(p, s)               fixed_len_byte_array (DECIMAL(p, s)) of the smallest length for p
+//	date32, date64              int32 (DATE)
+//	time32                      int32 (TIME(MILLIS, true)), seconds are converted to milliseconds
+//	time64                      int64 (TIME(MICROS, true) or TIME(NANOS, true))
+//	timestamp                   int64 (TIMESTAMP), seconds are converted to milliseconds
+//	null                        optional int32 (UNKNOWN)
+//	list                        group (LIST) with a repeated group list and the field element
+//	map                         group (MAP) with a repeated group key_value and the fields key and value
+//
+// Timestamps with a time zone are adjusted to UTC, timestamps without a time zone are not. The
+// keys of maps are required. All other Arrow types, e.g. float16, fixed size lists, intervals,
+// durations and extension types, are not supported.
+func SchemaDefinition(schema *arrow.Schema) (*parquetschema.SchemaDefinition, error) {
+	if schema == nil {
+		return nil, errors.New("schema is nil")
+	}
+
+	children, err := columns(schema.Fields())
+	if err != nil {
+		return nil, err
+	}
+	if len(children) == 0 {
+		return nil, errors.New("schema has no fields")
+	}
+
+	sd := parquetschema.SchemaDefinitionFromColumnDefinition(&parquetschema.ColumnDefinition{
+		Children:      children,
+		SchemaElement: &parquet.SchemaElement{Name: "schema"},
+	})
+	setNumChildren(sd.RootColumn)
+
+	if err := sd.Validate(); err != nil {
+		return nil, fmt.Errorf("derived schema definition is invalid: %w", err)
+	}
+
+	return sd, nil
+}
+
+func columns(fields []arrow.Field) ([]*parquetschema.ColumnDefinition, error) {
+	children := make([]*parquetschema.ColumnDefinition, 0, len(fields))
+	names := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if names[field.Name] {
+			return nil, fmt.Errorf("duplicate field name %q", field.Name)
+		}
+		names[field.Name] = true
+
+		col, err := column(field)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		children = append(children, col)
+	}
+	return children, nil
+}
+
+// column returns the column definition of the Arrow field.
+func column(field arrow.Field) (*parquetschema.ColumnDefinition, error) {
+	if field.Name == "" || strings.Contains(field.Name, ".") {
+		return nil, fmt.Errorf("invalid column name %q", field.Name)
+	}
+
+	repetition := parquet.FieldRepetitionType_REQUIRED
+	if nullable(field) {
+		repetition = parquet.FieldRepetitionType_OPTIONAL
+	}
+
+	col := &parquetschema.ColumnDefinition{
+		SchemaElement: &parquet.SchemaElement{
+			Name:           field.Name,
+			RepetitionType: parquet.FieldRepetitionTypePtr(repetition),
+		},
+	}
+	elem := col.SchemaElement
+
+	switch typ := field.Type.(type) {
+	case *arrow.BooleanType:
+		elem.Type = parquet.TypePtr(parquet.Type_BOOLEAN)
+	case *arrow.Int8Type:
+		setIntType(elem, 8, true, parquet.ConvertedType_INT_8)
+	case *arrow.Int16Type:
+		setIntType(elem, 16, true, parquet.ConvertedType_INT_16)
+	case *arrow.Int32Type:
+		setIntType(elem, 32, true, parquet.ConvertedType_INT_32)
+	case *arrow.Int64Type:
+		setIntType(elem, 64, true, parquet.ConvertedType_INT_64)
+	case *arrow.Uint8Type:
+		setIntType(elem, 8, false, parquet.ConvertedType_UINT_8)
+	case *arrow.Uint16Type:
+		setIntType(elem, 16, false, parquet.ConvertedType_UINT_16)
+	case *arrow.Uint32Type:
+		setIntType(elem, 32, false, parquet.ConvertedType_UINT_32)
+	case *arrow.Uint64Type:
+		setIntType(elem, 64, false, parquet.ConvertedType_UINT_64)
+	case *arrow.Float32Type:
+		elem.Type = parquet.TypePtr(parquet.Type_FLOAT)
+	case *arrow.Float64Type:
+		elem.Type = parquet.TypePtr(parquet.Type_DOUBLE)
+	case *arrow.StringType:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+		elem.LogicalType = &parquet.LogicalType{STRING: parquet.NewStringType()}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+	case *arrow.BinaryType:
+		elem.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+	case *arrow.FixedSizeBinaryType:
+		length := int32(typ.ByteWidth)
+		elem.Type = parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY)
+		elem.TypeLength = &length
+	case *arrow.Decimal128Type:
+		if typ.Precision < 1 || typ.Precision > 38 {
+			return nil, fmt.Errorf("invalid decimal precision %d", typ.Precision)
+		}
+		length := decimalLength(typ.Precision)
+		precision, scale := typ.Precision, typ.Scale
+		elem.Type = parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY)
+		elem.TypeLength = &length
+		elem.Precision, elem.Scale = &precision, &scale
+		elem.LogicalType = &parquet.LogicalType{DECIMAL: &parquet.DecimalType{Precision: precision, Scale: scale}}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL)
+	case *arrow.Date32Type, *arrow.Date64Type:
+		elem.Type = parquet.TypePtr(parquet.Type_INT32)
+		elem.LogicalType = &parquet.LogicalType{DATE: parquet.NewDateType()}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)
+	case *arrow.Time32Type:
+		elem.Type = parquet.TypePtr(parquet.Type_INT32)
+		elem.LogicalType = &parquet.LogicalType{TIME: &parquet.TimeType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{MILLIS: parquet.NewMilliSeconds()}}}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MILLIS)
+	case *arrow.Time64Type:
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+		elem.LogicalType = &parquet.LogicalType{TIME: &parquet.TimeType{IsAdjustedToUTC: true, Unit: parquet.NewTimeUnit()}}
+		if typ.Unit == arrow.Microsecond {
+			elem.LogicalType.TIME.Unit.MICROS = parquet.NewMicroSeconds()
+			elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MICROS)
+		} else {
+			elem.LogicalType.TIME.Unit.NANOS = parquet.NewNanoSeconds()
+		}
+	case *arrow.TimestampType:
+		utc := typ.TimeZone != ""
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+		elem.LogicalType = &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{IsAdjustedToUTC: utc, Unit: parquet.NewTimeUnit()}}
+		switch typ.Unit {
+		case arrow.Second, arrow.Millisecond:
+			elem.LogicalType.TIMESTAMP.Unit.MILLIS = parquet.NewMilliSeconds()
+			if utc {
+				elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS)
+			}
+		case arrow.Microsecond:
+			elem.LogicalType.TIMESTAMP.Unit.MICROS = parquet.NewMicroSeconds()
+			if utc {
+				elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)
+			}
+		default:
+			elem.LogicalType.TIMESTAMP.Unit.NANOS = parquet.NewNanoSeconds()
+		}
+	case *arrow.NullType:
+		elem.Type = parquet.TypePtr(parquet.Type_INT32)
+		elem.LogicalType = &parquet.LogicalType{UNKNOWN: parquet.NewNullType()}
+	case *arrow.StructType:
+		if len(typ.Fields()) == 0 {
+			return nil, errors.New("struct has no fields")
+		}
+		children, err := columns(typ.Fields())
+		if err != nil {
+			return nil, err
+		}
+		col.Children = children
+	case *arrow.ListType:
+		elemField := typ.ElemField()
+		element, err := column(arrow.Field{Name: "element", Type: elemField.Type, Nullable: elemField.Nullable})
+		if err != nil {
+			return nil, fmt.Errorf("list element: %w", err)
+		}
+		elem.LogicalType = &parquet.LogicalType{LIST: parquet.NewListType()}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_LIST)
+		col.Children = []*parquetschema.ColumnDefinition{repeatedGroup("list", element)}
+	case *arrow.MapType:
+		// the keys of Arrow maps are never null.
+		key, err := column(arrow.Field{Name: "key", Type: typ.KeyType()})
+		if err != nil {
+			return nil, fmt.Errorf("map key: %w", err)
+		}
+		value, err := column(arrow.Field{Name: "value", Type: typ.ItemType(), Nullable: typ.ItemField().Nullable})
+		if err != nil {
+			return nil, fmt.Errorf("map value: %w", err)
+		}
+		elem.LogicalType = &parquet.LogicalType{MAP: parquet.NewMapType()}
+		elem.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_MAP)
+		col.Children = []*parquetschema.ColumnDefinition{repeatedGroup("key_value", key, value)}
+	default:
+		return nil, fmt.Errorf("unsupported Arrow type %s", field.Type)
+	}
+
+	return col, nil
+}
+
+// nullable returns true if the field is written as an optional column. Fields of the null type
+// are always optional, as they only contain null values.
+func nullable(field arrow.Field) bool {
+	return field.Nullable || field.Type.ID() == arrow.NULL
+}
+
+// repeatedGroup returns the repeated group of a LIST or MAP with the children.
+func repeatedGroup(name string, children ...*parquetschema.ColumnDefinition) *parquetschema.ColumnDefinition {
+	return &parquetschema.ColumnDefinition{
+		Children: children,
+		SchemaElement: &parquet.SchemaElement{
+			Name:           name,
+			RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+		},
+	}
+}
+
+func setIntType(elem *parquet.SchemaElement, bitWidth int8, signed bool, convertedType parquet.ConvertedType) {
+	elem.Type = parquet.TypePtr(parquet.Type_INT32)
+	if bitWidth == 64 {
+		elem.Type = parquet.TypePtr(parquet.Type_INT64)
+	}
+	elem.LogicalType = &parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: bitWidth, IsSigned: signed}}
+	elem.ConvertedType = parquet.ConvertedTypePtr(convertedType)
+}
+
+// decimalLength returns the smallest length of a fixed_len_byte_array that can hold the unscaled
+// values of the precision, with the same bound as the validation of parquetschema.
+func decimalLength(precision int32) int32 {
+	length := int32(1)
+	for float64(precision) > math.Floor(math.Log10(math.Exp2(8*float64(length)-1))-1) {
+		length++
+	}
+	return length
+}
+
+func setNumChildren(col *parquetschema.ColumnDefinition) {
+	if nc := int32(len(col.Children)); nc > 0 {
+		col.SchemaElement.NumChildren = &nc
+	}
+	for _, child := range col.Children {
+		setNumChildren(child)
+	}
+}
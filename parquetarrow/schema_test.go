@@ -0,0 +1,115 @@
+package parquetarrow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaDefinition(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "flag", Type: arrow.FixedWidthTypes.Boolean},
+		{Name: "i8", Type: arrow.PrimitiveTypes.Int8},
+		{Name: "i64", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "u32", Type: arrow.PrimitiveTypes.Uint32},
+		{Name: "u64", Type: arrow.PrimitiveTypes.Uint64},
+		{Name: "f32", Type: arrow.PrimitiveTypes.Float32},
+		{Name: "f64", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "data", Type: arrow.BinaryTypes.Binary},
+		{Name: "code", Type: &arrow.FixedSizeBinaryType{ByteWidth: 3}},
+		{Name: "price", Type: &arrow.Decimal128Type{Precision: 9, Scale: 2}},
+		{Name: "big", Type: &arrow.Decimal128Type{Precision: 38, Scale: 0}},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32},
+		{Name: "day64", Type: arrow.FixedWidthTypes.Date64},
+		{Name: "t32", Type: arrow.FixedWidthTypes.Time32s},
+		{Name: "t64", Type: arrow.FixedWidthTypes.Time64us},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Second, TimeZone: "UTC"}},
+		{Name: "local", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}},
+		{Name: "nothing", Type: arrow.Null},
+		{Name: "address", Type: arrow.StructOf(
+			arrow.Field{Name: "city", Type: arrow.BinaryTypes.String},
+			arrow.Field{Name: "zip", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		), Nullable: true},
+		{Name: "ids", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64), Nullable: true},
+		{Name: "matrix", Type: arrow.ListOfNonNullable(arrow.ListOfNonNullable(arrow.PrimitiveTypes.Int32))},
+		{Name: "tags", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int32)},
+	}, nil)
+
+	sd, err := SchemaDefinition(schema)
+	require.NoError(t, err)
+	require.Equal(t, `message schema {
+  required boolean flag;
+  required int32 i8 (INT(8, true));
+  optional int64 i64 (INT(64, true));
+  required int32 u32 (INT(32, false));
+  required int64 u64 (INT(64, false));
+  required float f32;
+  required double f64;
+  optional binary name (STRING);
+  required binary data;
+  required fixed_len_byte_array(3) code;
+  required fixed_len_byte_array(5) price (DECIMAL(9, 2));
+  required fixed_len_byte_array(17) big (DECIMAL(38, 0));
+  required int32 day (DATE);
+  required int32 day64 (DATE);
+  required int32 t32 (TIME(MILLIS, true));
+  required int64 t64 (TIME(MICROS, true));
+  required int64 ts (TIMESTAMP(MILLIS, true));
+  required int64 local (TIMESTAMP(NANOS, false));
+  optional int32 nothing (UNKNOWN);
+  optional group address {
+    required binary city (STRING);
+    optional int32 zip (INT(32, true));
+  }
+  optional group ids (LIST) {
+    repeated group list {
+      optional int64 element (INT(64, true));
+    }
+  }
+  required group matrix (LIST) {
+    repeated group list {
+      required group element (LIST) {
+        repeated group list {
+          required int32 element (INT(32, true));
+        }
+      }
+    }
+  }
+  required group tags (MAP) {
+    repeated group key_value {
+      required binary key (STRING);
+      optional int32 value (INT(32, true));
+    }
+  }
+}
+`, sd.String())
+	require.NoError(t, sd.ValidateStrict())
+
+	invalid := []arrow.Field{
+		{Name: "half", Type: arrow.FixedWidthTypes.Float16},
+		{Name: "a.b", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "empty", Type: arrow.StructOf()},
+		{Name: "list", Type: arrow.ListOf(arrow.FixedWidthTypes.Float16)},
+		{Name: "fixed", Type: arrow.FixedSizeListOf(2, arrow.PrimitiveTypes.Int64)},
+		{Name: "map", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.FixedWidthTypes.Float16)},
+	}
+	for _, field := range invalid {
+		_, err := SchemaDefinition(arrow.NewSchema([]arrow.Field{field}, nil))
+		require.Error(t, err, field.Name)
+	}
+
+	_, err = SchemaDefinition(arrow.NewSchema(nil, nil))
+	require.Error(t, err)
+
+	duplicate := arrow.NewSchema([]arrow.Field{
+		{Name: "a", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "a", Type: arrow.BinaryTypes.String},
+	}, nil)
+	_, err = SchemaDefinition(duplicate)
+	require.Error(t, err)
+	_, err = NewWriter(&bytes.Buffer{}, duplicate)
+	require.Error(t, err)
+}
@@ -0,0 +1,397 @@
+package parquetarrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/decimal128"
+	"github.com/apache/arrow/go/arrow/ipc"
+	goparquet "github.com/fraugster/parquet-go"
+)
+
+// Writer writes Arrow records to a parquet file. Every record is written as a row group, and
+// every column of a record as a column chunk.
+type Writer struct {
+	fw     *goparquet.FileWriter
+	schema *arrow.Schema
+}
+
+// NewWriter creates a Writer that writes records with the Arrow schema to w. The parquet schema
+// definition is derived from the Arrow schema using SchemaDefinition, and the Arrow schema is
+// stored in the ARROW:schema entry of the key-value meta data, so that Arrow implementations can
+// restore the exact Arrow types when reading the file. The options are passed to
+// goparquet.NewFileWriter, except for options that set the schema definition.
+func NewWriter(w io.Writer, schema *arrow.Schema, opts ...goparquet.FileWriterOption) (*Writer, error) {
+	sd, err := SchemaDefinition(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := schemaMessage(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	fw := goparquet.NewFileWriter(w, opts...)
+	if err := fw.SetSchemaDefinition(sd); err != nil {
+		return nil, err
+	}
+	fw.SetArrowSchema(message)
+
+	return &Writer{fw: fw, schema: schema}, nil
+}
+
+// Write writes the record as a row group. The schema of the record needs to be equal to the
+// schema the Writer was created with. Records without rows are skipped.
+func (w *Writer) Write(rec array.Record) error {
+	if !rec.Schema().Equal(w.schema) {
+		return errors.New("record schema differs from the schema of the writer")
+	}
+	if rec.NumRows() == 0 {
+		return nil
+	}
+
+	// all batches are built before they are written, so that invalid values don't leave a
+	// partial row group behind.
+	var (
+		batches []*columnBatch
+		err     error
+	)
+	for i, field := range w.schema.Fields() {
+		batches, err = appendBatches(batches, field.Name, field, rec.Column(i), rowLevels(int(rec.NumRows())))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, b := range batches {
+		if err := w.fw.WriteColumnBatch(b.path, b.values, b.dLevels, b.rLevels); err != nil {
+			return err
+		}
+	}
+
+	return w.fw.FlushRowGroup()
+}
+
+// Close writes the footer of the file. It doesn't close the underlying writer.
+func (w *Writer) Close(opts ...goparquet.FlushRowGroupOption) error {
+	return w.fw.Close(opts...)
+}
+
+// schemaMessage returns the flatbuffers Message that contains the Arrow schema, as written at the
+// beginning of an IPC stream.
+func schemaMessage(schema *arrow.Schema) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := ipc.NewWriter(buf, ipc.WithSchema(schema)).Close(); err != nil {
+		return nil, fmt.Errorf("serializing Arrow schema failed: %w", err)
+	}
+
+	data := buf.Bytes()
+	if len(data) < 8 || binary.LittleEndian.Uint32(data) != 0xFFFFFFFF {
+		return nil, errors.New("serialized Arrow schema has no continuation marker")
+	}
+	length := binary.LittleEndian.Uint32(data[4:])
+	if uint64(length) > uint64(len(data)-8) {
+		return nil, errors.New("serialized Arrow schema is truncated")
+	}
+	return data[8 : 8+length], nil
+}
+
+// columnBatch contains the values and levels of a data column of a record.
+type columnBatch struct {
+	path    string
+	values  interface{}
+	dLevels []uint16
+	rLevels []uint16
+}
+
+// levels contains the definition and repetition level of every entry of the data columns of a
+// field, and the index of the value of the entry in the array of the field, which is -1 if the
+// entry is null or an empty list at the level of the field or one of its parents.
+type levels struct {
+	defs, reps     []uint16
+	indices        []int
+	maxDef, maxRep uint16
+}
+
+// rowLevels returns the levels of the fields of the schema, which have an entry for every row.
+func rowLevels(numRows int) *levels {
+	lv := &levels{
+		defs:    make([]uint16, numRows),
+		reps:    make([]uint16, numRows),
+		indices: make([]int, numRows),
+	}
+	for i := range lv.indices {
+		lv.indices[i] = i
+	}
+	return lv
+}
+
+// appendBatches appends the batches of the data columns of the field to batches. lv contains the
+// levels of the entries that were reached by the parent groups of the field.
+func appendBatches(batches []*columnBatch, path string, field arrow.Field, arr array.Interface, lv *levels) ([]*columnBatch, error) {
+	if nullable(field) {
+		optional := &levels{
+			defs:    make([]uint16, len(lv.defs)),
+			reps:    lv.reps,
+			indices: make([]int, len(lv.indices)),
+			maxDef:  lv.maxDef + 1,
+			maxRep:  lv.maxRep,
+		}
+		for i, idx := range lv.indices {
+			optional.defs[i], optional.indices[i] = lv.defs[i], idx
+			if idx >= 0 && valid(arr, idx) {
+				optional.defs[i]++
+			} else {
+				optional.indices[i] = -1
+			}
+		}
+		lv = optional
+	} else if arr.NullN() > 0 {
+		for _, idx := range lv.indices {
+			if idx >= 0 && !valid(arr, idx) {
+				return nil, fmt.Errorf("column %s is not nullable but value %d is null", path, idx)
+			}
+		}
+	}
+
+	switch a := arr.(type) {
+	case *array.Struct:
+		var err error
+		for i, child := range field.Type.(*arrow.StructType).Fields() {
+			batches, err = appendBatches(batches, path+"."+child.Name, child, a.Field(i), lv)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return batches, nil
+	case *array.Map:
+		// the entries of a map are a list of structs with the fields key and value.
+		typ := field.Type.(*arrow.MapType)
+		entries := a.ListValues().(*array.Struct)
+		lv = listLevels(a.List, lv)
+		batches, err := appendBatches(batches, path+".key_value.key", arrow.Field{Name: "key", Type: typ.KeyType()}, entries.Field(0), lv)
+		if err != nil {
+			return nil, err
+		}
+		return appendBatches(batches, path+".key_value.value", arrow.Field{Name: "value", Type: typ.ItemType(), Nullable: typ.ItemField().Nullable}, entries.Field(1), lv)
+	case *array.List:
+		elemField := field.Type.(*arrow.ListType).ElemField()
+		return appendBatches(batches, path+".list.element", arrow.Field{Name: "element", Type: elemField.Type, Nullable: elemField.Nullable}, a.ListValues(), listLevels(a, lv))
+	}
+
+	values, err := columnValues(arr, lv.indices)
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %w", path, err)
+	}
+
+	b := &columnBatch{path: path, values: values}
+	if lv.maxDef > 0 {
+		b.dLevels = lv.defs
+	}
+	if lv.maxRep > 0 {
+		b.rLevels = lv.reps
+	}
+	return append(batches, b), nil
+}
+
+// listLevels returns the levels of the elements of the lists of arr, whose entries have the levels
+// lv. Every element becomes an entry, all but the first element of a list repeat the list. Null
+// and empty lists keep their single entry.
+func listLevels(arr *array.List, lv *levels) *levels {
+	offsets := arr.Offsets()[arr.Data().Offset():]
+	elements := &levels{maxDef: lv.maxDef + 1, maxRep: lv.maxRep + 1}
+	for i, idx := range lv.indices {
+		if idx < 0 || offsets[idx] == offsets[idx+1] {
+			elements.defs = append(elements.defs, lv.defs[i])
+			elements.reps = append(elements.reps, lv.reps[i])
+			elements.indices = append(elements.indices, -1)
+			continue
+		}
+		for j := offsets[idx]; j < offsets[idx+1]; j++ {
+			rep := elements.maxRep
+			if j == offsets[idx] {
+				rep = lv.reps[i]
+			}
+			elements.defs = append(elements.defs, elements.maxDef)
+			elements.reps = append(elements.reps, rep)
+			elements.indices = append(elements.indices, int(j))
+		}
+	}
+	return elements
+}
+
+// valid returns true if the value of row i of arr is not null.
+func valid(arr array.Interface, i int) bool {
+	// arrays of the null type have no validity bitmap.
+	return arr.DataType().ID() != arrow.NULL && arr.IsValid(i)
+}
+
+// columnValues returns the values of arr that are referenced by indices, in the Go type of the
+// physical type of the column. Negative indices are skipped.
+func columnValues(arr array.Interface, indices []int) (interface{}, error) {
+	var rows []int
+	for _, i := range indices {
+		if i >= 0 {
+			rows = append(rows, i)
+		}
+	}
+
+	switch a := arr.(type) {
+	case *array.Boolean:
+		values := make([]bool, len(rows))
+		for j, i := range rows {
+			values[j] = a.Value(i)
+		}
+		return values, nil
+	case *array.Int8:
+		return int32Values(rows, func(i int) int32 { return int32(a.Value(i)) }), nil
+	case *array.Int16:
+		return int32Values(rows, func(i int) int32 { return int32(a.Value(i)) }), nil
+	case *array.Int32:
+		return int32Values(rows, func(i int) int32 { return a.Value(i) }), nil
+	case *array.Uint8:
+		return int32Values(rows, func(i int) int32 { return int32(a.Value(i)) }), nil
+	case *array.Uint16:
+		return int32Values(rows, func(i int) int32 { return int32(a.Value(i)) }), nil
+	case *array.Uint32:
+		return int32Values(rows, func(i int) int32 { return int32(a.Value(i)) }), nil
+	case *array.Int64:
+		return int64Values(rows, func(i int) int64 { return a.Value(i) }), nil
+	case *array.Uint64:
+		return int64Values(rows, func(i int) int64 { return int64(a.Value(i)) }), nil
+	case *array.Float32:
+		values := make([]float32, len(rows))
+		for j, i := range rows {
+			values[j] = a.Value(i)
+		}
+		return values, nil
+	case *array.Float64:
+		values := make([]float64, len(rows))
+		for j, i := range rows {
+			values[j] = a.Value(i)
+		}
+		return values, nil
+	case *array.String:
+		return byteArrayValues(rows, func(i int) []byte { return []byte(a.Value(i)) }), nil
+	case *array.Binary:
+		return byteArrayValues(rows, func(i int) []byte { return append([]byte(nil), a.Value(i)...) }), nil
+	case *array.FixedSizeBinary:
+		return byteArrayValues(rows, func(i int) []byte { return append([]byte(nil), a.Value(i)...) }), nil
+	case *array.Decimal128:
+		length := int(decimalLength(a.DataType().(*arrow.Decimal128Type).Precision))
+		values := make([][]byte, len(rows))
+		for j, i := range rows {
+			v, ok := decimalBytes(a.Value(i), length)
+			if !ok {
+				return nil, fmt.Errorf("decimal value in row %d doesn't fit into %d bytes", i, length)
+			}
+			values[j] = v
+		}
+		return values, nil
+	case *array.Date32:
+		return int32Values(rows, func(i int) int32 { return int32(a.Value(i)) }), nil
+	case *array.Date64:
+		values := make([]int32, len(rows))
+		for j, i := range rows {
+			days := int64(a.Value(i)) / 86400000
+			if int64(a.Value(i))%86400000 < 0 {
+				days--
+			}
+			if days < math.MinInt32 || days > math.MaxInt32 {
+				return nil, fmt.Errorf("date64 value %d in row %d is out of range", a.Value(i), i)
+			}
+			values[j] = int32(days)
+		}
+		return values, nil
+	case *array.Time32:
+		values := make([]int32, len(rows))
+		for j, i := range rows {
+			v := int64(a.Value(i))
+			if a.DataType().(*arrow.Time32Type).Unit == arrow.Second {
+				v *= 1000
+			}
+			if v < math.MinInt32 || v > math.MaxInt32 {
+				return nil, fmt.Errorf("time32 value %d in row %d is out of range", a.Value(i), i)
+			}
+			values[j] = int32(v)
+		}
+		return values, nil
+	case *array.Time64:
+		return int64Values(rows, func(i int) int64 { return int64(a.Value(i)) }), nil
+	case *array.Timestamp:
+		values := make([]int64, len(rows))
+		seconds := a.DataType().(*arrow.TimestampType).Unit == arrow.Second
+		for j, i := range rows {
+			v := int64(a.Value(i))
+			if seconds {
+				if v < math.MinInt64/1000 || v > math.MaxInt64/1000 {
+					return nil, fmt.Errorf("timestamp value %d in row %d is out of range", v, i)
+				}
+				v *= 1000
+			}
+			values[j] = v
+		}
+		return values, nil
+	case *array.Null:
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unsupported Arrow type %s", arr.DataType())
+}
+
+// decimalBytes returns the big-endian two's complement of the unscaled value n in length bytes,
+// and false if it doesn't fit.
+func decimalBytes(n decimal128.Num, length int) ([]byte, bool) {
+	v := make([]byte, 16)
+	binary.BigEndian.PutUint64(v, uint64(n.HighBits()))
+	binary.BigEndian.PutUint64(v[8:], n.LowBits())
+
+	sign := byte(0)
+	if n.Sign() < 0 {
+		sign = 0xFF
+	}
+	for len(v) < length {
+		v = append([]byte{sign}, v...)
+	}
+
+	// the value only fits if the omitted bytes just extend the sign.
+	omitted := v[:len(v)-length]
+	for _, b := range omitted {
+		if b != sign {
+			return nil, false
+		}
+	}
+	v = v[len(omitted):]
+	return v, v[0]&0x80 == sign&0x80
+}
+
+func int32Values(rows []int, value func(int) int32) []int32 {
+	values := make([]int32, len(rows))
+	for j, i := range rows {
+		values[j] = value(i)
+	}
+	return values
+}
+
+func int64Values(rows []int, value func(int) int64) []int64 {
+	values := make([]int64, len(rows))
+	for j, i := range rows {
+		values[j] = value(i)
+	}
+	return values
+}
+
+func byteArrayValues(rows []int, value func(int) []byte) [][]byte {
+	values := make([][]byte, len(rows))
+	for j, i := range rows {
+		values[j] = value(i)
+	}
+	return values
+}
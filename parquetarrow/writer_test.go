@@ -0,0 +1,245 @@
+package parquetarrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/decimal128"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "small", Type: arrow.PrimitiveTypes.Uint8},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "price", Type: &arrow.Decimal128Type{Precision: 9, Scale: 2}, Nullable: true},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Second, TimeZone: "UTC"}},
+		{Name: "nothing", Type: arrow.Null},
+		{Name: "address", Type: arrow.StructOf(
+			arrow.Field{Name: "city", Type: arrow.BinaryTypes.String},
+			arrow.Field{Name: "zip", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		), Nullable: true},
+	}, nil)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, schema)
+	require.NoError(t, err)
+
+	for _, ids := range [][]int64{{1, 2, 3}, {4}} {
+		for _, id := range ids {
+			b.Field(0).(*array.Int64Builder).Append(id)
+			b.Field(1).(*array.Uint8Builder).Append(uint8(200 + id))
+			b.Field(4).(*array.TimestampBuilder).Append(arrow.Timestamp(1600000000 + id))
+			b.Field(5).(*array.NullBuilder).AppendNull()
+
+			address := b.Field(6).(*array.StructBuilder)
+			if id%2 == 0 {
+				b.Field(2).(*array.StringBuilder).Append("name")
+				b.Field(3).(*array.Decimal128Builder).Append(decimal128.FromI64(-id * 150))
+				address.Append(true)
+				address.FieldBuilder(0).(*array.StringBuilder).Append("city")
+				address.FieldBuilder(1).(*array.Int32Builder).AppendNull()
+			} else {
+				b.Field(2).(*array.StringBuilder).AppendNull()
+				b.Field(3).(*array.Decimal128Builder).AppendNull()
+				// the values of a null struct are ignored.
+				address.AppendValues([]bool{false})
+				address.FieldBuilder(0).(*array.StringBuilder).Append("ignored")
+				address.FieldBuilder(1).(*array.Int32Builder).Append(int32(id))
+			}
+		}
+
+		rec := b.NewRecord()
+		require.NoError(t, w.Write(rec))
+		rec.Release()
+	}
+	require.NoError(t, w.Close())
+
+	r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 2, r.RowGroupCount())
+
+	decimal := func(v int64) []byte {
+		data := make([]byte, 8)
+		binary.BigEndian.PutUint64(data, uint64(v))
+		return data[3:]
+	}
+	expected := []map[string]interface{}{
+		{"id": int64(1), "small": uint32(201), "ts": int64(1600000001000)},
+		{"id": int64(2), "small": uint32(202), "ts": int64(1600000002000), "name": []byte("name"), "price": decimal(-300), "address": map[string]interface{}{"city": []byte("city")}},
+		{"id": int64(3), "small": uint32(203), "ts": int64(1600000003000)},
+		{"id": int64(4), "small": uint32(204), "ts": int64(1600000004000), "name": []byte("name"), "price": decimal(-600), "address": map[string]interface{}{"city": []byte("city")}},
+	}
+	for _, row := range expected {
+		data, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, row, data)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	// the stored Arrow schema can be read as the schema message of an IPC stream.
+	message, ok, err := r.ArrowSchema()
+	require.NoError(t, err)
+	require.True(t, ok)
+	stream := &bytes.Buffer{}
+	require.NoError(t, binary.Write(stream, binary.LittleEndian, []uint32{0xFFFFFFFF, uint32(len(message))}))
+	stream.Write(message)
+	require.NoError(t, binary.Write(stream, binary.LittleEndian, []uint32{0xFFFFFFFF, 0}))
+	ipcReader, err := ipc.NewReader(stream)
+	require.NoError(t, err)
+	require.True(t, schema.Equal(ipcReader.Schema()))
+}
+
+func TestWriterNested(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "ids", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64), Nullable: true},
+		{Name: "matrix", Type: arrow.ListOf(arrow.ListOf(arrow.PrimitiveTypes.Int32))},
+		{Name: "tags", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int32), Nullable: true},
+		{Name: "points", Type: arrow.ListOf(arrow.StructOf(
+			arrow.Field{Name: "x", Type: arrow.PrimitiveTypes.Int32},
+			arrow.Field{Name: "label", Type: arrow.BinaryTypes.String, Nullable: true},
+		)), Nullable: true},
+	}, nil)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+
+	ids := b.Field(0).(*array.ListBuilder)
+	ids.Append(true)
+	ids.ValueBuilder().(*array.Int64Builder).AppendValues([]int64{1, 0, 3}, []bool{true, false, true})
+	ids.AppendNull()
+	ids.Append(true)
+
+	matrix := b.Field(1).(*array.ListBuilder)
+	rows := matrix.ValueBuilder().(*array.ListBuilder)
+	cells := rows.ValueBuilder().(*array.Int32Builder)
+	matrix.Append(true)
+	rows.Append(true)
+	cells.AppendValues([]int32{1, 2}, nil)
+	rows.Append(true)
+	rows.Append(true)
+	cells.Append(3)
+	matrix.Append(true)
+	matrix.Append(true)
+	rows.Append(true)
+	cells.Append(4)
+
+	tags := b.Field(2).(*array.MapBuilder)
+	tags.Append(true)
+	tags.KeyBuilder().(*array.StringBuilder).AppendValues([]string{"a", "b"}, nil)
+	tags.ItemBuilder().(*array.Int32Builder).AppendValues([]int32{1, 0}, []bool{true, false})
+	tags.Append(true)
+	tags.AppendNull()
+
+	points := b.Field(3).(*array.ListBuilder)
+	point := points.ValueBuilder().(*array.StructBuilder)
+	points.Append(true)
+	point.Append(true)
+	point.FieldBuilder(0).(*array.Int32Builder).Append(5)
+	point.FieldBuilder(1).(*array.StringBuilder).Append("p")
+	point.Append(true)
+	point.FieldBuilder(0).(*array.Int32Builder).Append(6)
+	point.FieldBuilder(1).(*array.StringBuilder).AppendNull()
+	points.Append(true)
+	points.AppendNull()
+
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, schema)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(rec))
+	// the slice starts in the middle of the lists.
+	slice := rec.NewSlice(1, 3)
+	require.NoError(t, w.Write(slice))
+	slice.Release()
+	require.NoError(t, w.Close())
+
+	r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(5), r.NumRows())
+
+	list := func(elements ...map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"list": elements}
+	}
+	element := func(v interface{}) map[string]interface{} {
+		if v == nil {
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{"element": v}
+	}
+	expected := []map[string]interface{}{
+		{
+			"ids":    list(element(int64(1)), element(nil), element(int64(3))),
+			"matrix": list(element(list(element(int32(1)), element(int32(2)))), element(map[string]interface{}{}), element(list(element(int32(3))))),
+			"tags": map[string]interface{}{"key_value": []map[string]interface{}{
+				{"key": []byte("a"), "value": int32(1)},
+				{"key": []byte("b")},
+			}},
+			"points": list(
+				element(map[string]interface{}{"x": int32(5), "label": []byte("p")}),
+				element(map[string]interface{}{"x": int32(6)}),
+			),
+		},
+		{"matrix": map[string]interface{}{}, "tags": map[string]interface{}{}, "points": map[string]interface{}{}},
+		{"ids": map[string]interface{}{}, "matrix": list(element(list(element(int32(4)))))},
+	}
+	for _, row := range append(expected, expected[1:]...) {
+		data, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, row, data)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestWriterInvalidRecords(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "price", Type: &arrow.Decimal128Type{Precision: 3, Scale: 2}},
+	}, nil)
+
+	newRecord := func(price int64, idValid bool) array.Record {
+		b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+		defer b.Release()
+		b.Field(0).(*array.Int64Builder).AppendValues([]int64{1}, []bool{idValid})
+		b.Field(1).(*array.Decimal128Builder).Append(decimal128.FromI64(price))
+		return b.NewRecord()
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, schema)
+	require.NoError(t, err)
+
+	require.Error(t, w.Write(newRecord(100, false)), "null value in non-nullable column")
+	require.Error(t, w.Write(newRecord(40000, true)), "decimal doesn't fit into 2 bytes")
+
+	ob := array.NewRecordBuilder(memory.DefaultAllocator, arrow.NewSchema([]arrow.Field{{Name: "id", Type: arrow.PrimitiveTypes.Int64}}, nil))
+	defer ob.Release()
+	ob.Field(0).(*array.Int64Builder).Append(1)
+	require.Error(t, w.Write(ob.NewRecord()), "schema differs")
+
+	// failed records don't leave partial row groups behind.
+	require.NoError(t, w.Write(newRecord(-999, true)))
+	require.NoError(t, w.Close())
+
+	r, err := goparquet.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r.NumRows())
+	data, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"id": int64(1), "price": []byte{0xfc, 0x19}}, data)
+}
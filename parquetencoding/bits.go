@@ -0,0 +1,122 @@
+package parquetencoding
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// bitWriter writes values of arbitrary bit widths, starting with the least significant bit.
+type bitWriter struct {
+	data []byte
+	acc  uint64
+	n    uint
+}
+
+func (w *bitWriter) write(v uint64, bitWidth int) {
+	// the accumulator holds less than 8 bits between iterations, so it has room for 32 more bits.
+	for bitWidth > 0 {
+		take := uint(bitWidth)
+		if take > 32 {
+			take = 32
+		}
+		w.acc |= (v & (1<<take - 1)) << w.n
+		w.n += take
+		for w.n >= 8 {
+			w.data = append(w.data, byte(w.acc))
+			w.acc >>= 8
+			w.n -= 8
+		}
+		v >>= take
+		bitWidth -= int(take)
+	}
+}
+
+// bytes returns the written data, including a partially written last byte.
+func (w *bitWriter) bytes() []byte {
+	if w.n > 0 {
+		w.data = append(w.data, byte(w.acc))
+		w.acc, w.n = 0, 0
+	}
+	return w.data
+}
+
+// bitReader reads values of arbitrary bit widths from a byte slice, starting with the least significant bit.
+type bitReader struct {
+	data []byte
+	pos  uint
+}
+
+func (r *bitReader) read(bitWidth int) uint64 {
+	var (
+		v     uint64
+		shift uint
+	)
+	for bw := uint(bitWidth); bw > 0; {
+		b := r.data[r.pos/8]
+		off := r.pos % 8
+		take := 8 - off
+		if take > bw {
+			take = bw
+		}
+		v |= uint64(b>>off&(1<<take-1)) << shift
+		shift += take
+		bw -= take
+		r.pos += take
+	}
+	return v
+}
+
+// packedSize returns the number of bytes required for count values of the provided bit width.
+func packedSize(count int, bitWidth int) int {
+	return (count*bitWidth + 7) / 8
+}
+
+// byteReader reads single bytes without reading ahead, to not consume data that
+// belongs to the next encoded section.
+type byteReader struct {
+	r   io.Reader
+	buf [1]byte
+}
+
+func (br *byteReader) ReadByte() (byte, error) {
+	if rb, ok := br.r.(io.ByteReader); ok {
+		return rb.ReadByte()
+	}
+	if _, err := io.ReadFull(br.r, br.buf[:]); err != nil {
+		return 0, err
+	}
+	return br.buf[0], nil
+}
+
+func readUvarint(br *byteReader) (uint64, error) {
+	v, err := binary.ReadUvarint(br)
+	if err == io.EOF {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+func readVarint(br *byteReader) (int64, error) {
+	v, err := binary.ReadVarint(br)
+	if err == io.EOF {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+func writeUvarint(w io.Writer, v uint64) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, v)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+func writeVarint(w io.Writer, v int64) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutVarint(buf, v)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+var errInvalidBitWidth = errors.New("bit width must be between 0 and 32")
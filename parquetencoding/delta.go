@@ -0,0 +1,410 @@
+package parquetencoding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+)
+
+const (
+	deltaBlockSize      = 128
+	deltaMiniBlockCount = 4
+	deltaMiniBlockSize  = deltaBlockSize / deltaMiniBlockCount
+)
+
+// EncodeDeltaInt32 writes the values using the DELTA_BINARY_PACKED encoding.
+func EncodeDeltaInt32(w io.Writer, values []int32) error {
+	v := make([]int64, len(values))
+	for i := range values {
+		v[i] = int64(values[i])
+	}
+	return encodeDelta(w, v, 32)
+}
+
+// EncodeDeltaInt64 writes the values using the DELTA_BINARY_PACKED encoding.
+func EncodeDeltaInt64(w io.Writer, values []int64) error {
+	return encodeDelta(w, values, 64)
+}
+
+func encodeDelta(w io.Writer, values []int64, width int) error {
+	if err := writeUvarint(w, deltaBlockSize); err != nil {
+		return err
+	}
+	if err := writeUvarint(w, deltaMiniBlockCount); err != nil {
+		return err
+	}
+	if err := writeUvarint(w, uint64(len(values))); err != nil {
+		return err
+	}
+
+	var first int64
+	if len(values) > 0 {
+		first = values[0]
+	}
+	if err := writeVarint(w, first); err != nil {
+		return err
+	}
+
+	// the deltas are calculated with the overflow behaviour of the type, so that the bit width
+	// of the deltas of int32 values never exceeds 32.
+	deltas := make([]int64, 0, deltaBlockSize)
+	for i := 1; i < len(values); i++ {
+		delta := values[i] - values[i-1]
+		if width == 32 {
+			delta = int64(int32(delta))
+		}
+		deltas = append(deltas, delta)
+		if len(deltas) == deltaBlockSize || i == len(values)-1 {
+			if err := writeDeltaBlock(w, deltas, width); err != nil {
+				return err
+			}
+			deltas = deltas[:0]
+		}
+	}
+
+	return nil
+}
+
+func writeDeltaBlock(w io.Writer, deltas []int64, width int) error {
+	minDelta := int64(math.MaxInt64)
+	for _, d := range deltas {
+		if d < minDelta {
+			minDelta = d
+		}
+	}
+
+	if err := writeVarint(w, minDelta); err != nil {
+		return err
+	}
+
+	bitWidths := make([]byte, deltaMiniBlockCount)
+	packed := &bitWriter{}
+	for mb := 0; mb*deltaMiniBlockSize < len(deltas); mb++ {
+		end := (mb + 1) * deltaMiniBlockSize
+		if end > len(deltas) {
+			end = len(deltas)
+		}
+
+		miniBlock := make([]uint64, deltaMiniBlockSize)
+		var max uint64
+		for i, d := range deltas[mb*deltaMiniBlockSize : end] {
+			v := uint64(d - minDelta)
+			if width == 32 {
+				v = uint64(uint32(v))
+			}
+			miniBlock[i] = v
+			if v > max {
+				max = v
+			}
+		}
+
+		bitWidths[mb] = byte(bits.Len64(max))
+		for _, v := range miniBlock {
+			packed.write(v, int(bitWidths[mb]))
+		}
+	}
+
+	if _, err := w.Write(bitWidths); err != nil {
+		return err
+	}
+	_, err := w.Write(packed.bytes())
+	return err
+}
+
+// DeltaDecoder decodes values that are encoded using the DELTA_BINARY_PACKED encoding.
+// Always use NewDeltaDecoder to create such an object.
+type DeltaDecoder struct {
+	r   *byteReader
+	err error
+
+	miniBlockCount int
+	miniBlockSize  int
+	total          uint64
+	read           uint64
+
+	value     int64
+	minDelta  int64
+	bitWidths []byte
+	miniBlock int
+	values    []uint64
+	pos       int
+}
+
+// NewDeltaDecoder creates a new decoder that reads DELTA_BINARY_PACKED encoded values from r. It
+// reads the header of the encoded data right away.
+func NewDeltaDecoder(r io.Reader) (*DeltaDecoder, error) {
+	d := &DeltaDecoder{r: &byteReader{r: r}}
+
+	blockSize, err := readUvarint(d.r)
+	if err != nil {
+		return nil, err
+	}
+	miniBlockCount, err := readUvarint(d.r)
+	if err != nil {
+		return nil, err
+	}
+	if blockSize == 0 || blockSize%128 != 0 || blockSize > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid block size %d", blockSize)
+	}
+	if miniBlockCount == 0 || blockSize%miniBlockCount != 0 || (blockSize/miniBlockCount)%32 != 0 {
+		return nil, fmt.Errorf("invalid mini block count %d", miniBlockCount)
+	}
+
+	if d.total, err = readUvarint(d.r); err != nil {
+		return nil, err
+	}
+	if d.value, err = readVarint(d.r); err != nil {
+		return nil, err
+	}
+
+	d.miniBlockCount = int(miniBlockCount)
+	d.miniBlockSize = int(blockSize / miniBlockCount)
+	d.bitWidths = make([]byte, d.miniBlockCount)
+	d.miniBlock = d.miniBlockCount
+	d.values = make([]uint64, d.miniBlockSize)
+	d.pos = d.miniBlockSize
+
+	return d, nil
+}
+
+// Len returns the total number of values according to the header of the encoded data.
+func (d *DeltaDecoder) Len() int {
+	return int(d.total)
+}
+
+// NextInt64 returns the next value. It returns io.EOF if there are no more values.
+func (d *DeltaDecoder) NextInt64() (int64, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	if d.read >= d.total {
+		return 0, io.EOF
+	}
+
+	if d.read > 0 {
+		if d.pos == d.miniBlockSize {
+			if err := d.readMiniBlock(); err != nil {
+				d.err = err
+				return 0, err
+			}
+		}
+		d.value += d.minDelta + int64(d.values[d.pos])
+		d.pos++
+	}
+
+	d.read++
+	return d.value, nil
+}
+
+// NextInt32 returns the next value of data that was encoded from int32 values. It returns io.EOF
+// if there are no more values.
+func (d *DeltaDecoder) NextInt32() (int32, error) {
+	v, err := d.NextInt64()
+	return int32(v), err
+}
+
+// DecodeInt32 fills dst with the next values and returns the number of decoded values. If there
+// are less values available than requested, it returns the number of values and io.EOF.
+func (d *DeltaDecoder) DecodeInt32(dst []int32) (int, error) {
+	for i := range dst {
+		v, err := d.NextInt32()
+		if err != nil {
+			return i, err
+		}
+		dst[i] = v
+	}
+	return len(dst), nil
+}
+
+// DecodeInt64 fills dst with the next values and returns the number of decoded values. If there
+// are less values available than requested, it returns the number of values and io.EOF.
+func (d *DeltaDecoder) DecodeInt64(dst []int64) (int, error) {
+	for i := range dst {
+		v, err := d.NextInt64()
+		if err != nil {
+			return i, err
+		}
+		dst[i] = v
+	}
+	return len(dst), nil
+}
+
+func (d *DeltaDecoder) readMiniBlock() error {
+	if d.miniBlock == d.miniBlockCount {
+		var err error
+		if d.minDelta, err = readVarint(d.r); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(d.r.r, d.bitWidths); err != nil {
+			return unexpectedEOF(err)
+		}
+		d.miniBlock = 0
+	}
+
+	bw := int(d.bitWidths[d.miniBlock])
+	if bw > 64 {
+		return fmt.Errorf("invalid bit width %d", bw)
+	}
+
+	buf := make([]byte, packedSize(d.miniBlockSize, bw))
+	if _, err := io.ReadFull(d.r.r, buf); err != nil {
+		return unexpectedEOF(err)
+	}
+
+	br := &bitReader{data: buf}
+	for i := range d.values {
+		d.values[i] = br.read(bw)
+	}
+
+	d.miniBlock++
+	d.pos = 0
+	return nil
+}
+
+// EncodeDeltaLengthByteArray writes the values using the DELTA_LENGTH_BYTE_ARRAY encoding.
+func EncodeDeltaLengthByteArray(w io.Writer, values [][]byte) error {
+	lengths := make([]int32, len(values))
+	for i := range values {
+		if len(values[i]) > math.MaxInt32 {
+			return errors.New("byte array is too large")
+		}
+		lengths[i] = int32(len(values[i]))
+	}
+
+	if err := EncodeDeltaInt32(w, lengths); err != nil {
+		return err
+	}
+
+	for i := range values {
+		if _, err := w.Write(values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeDeltaLengthByteArray reads all values that are encoded using the DELTA_LENGTH_BYTE_ARRAY encoding.
+func DecodeDeltaLengthByteArray(r io.Reader) ([][]byte, error) {
+	lengths, err := decodeDeltaInt32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, l := range lengths {
+		if l < 0 {
+			return nil, fmt.Errorf("negative length %d", l)
+		}
+		total += int64(l)
+	}
+
+	// the lengths are taken from the data, so they are checked against the remaining input if
+	// its size is known. Otherwise, the values grow while reading instead of trusting the lengths
+	// blindly.
+	values := make([][]byte, len(lengths))
+	if lr, ok := r.(interface{ Len() int }); ok {
+		if remaining := lr.Len(); total > int64(remaining) {
+			return nil, fmt.Errorf("values of %d bytes exceed the remaining %d bytes", total, remaining)
+		}
+		data := make([]byte, total)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		for i, l := range lengths {
+			// the capacity is limited so that appending to a value can't overwrite the next value.
+			values[i], data = data[:l:l], data[l:]
+		}
+		return values, nil
+	}
+
+	for i, l := range lengths {
+		if values[i], err = readBytes(r, int(l)); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// EncodeDeltaByteArray writes the values using the DELTA_BYTE_ARRAY encoding, i.e. the length of the
+// prefix that is shared with the previous value followed by the remaining suffix.
+func EncodeDeltaByteArray(w io.Writer, values [][]byte) error {
+	prefixes := make([]int32, len(values))
+	suffixes := make([][]byte, len(values))
+
+	var prev []byte
+	for i, v := range values {
+		p := 0
+		for p < len(prev) && p < len(v) && prev[p] == v[p] {
+			p++
+		}
+		prefixes[i] = int32(p)
+		suffixes[i] = v[p:]
+		prev = v
+	}
+
+	if err := EncodeDeltaInt32(w, prefixes); err != nil {
+		return err
+	}
+	return EncodeDeltaLengthByteArray(w, suffixes)
+}
+
+// DecodeDeltaByteArray reads all values that are encoded using the DELTA_BYTE_ARRAY encoding.
+func DecodeDeltaByteArray(r io.Reader) ([][]byte, error) {
+	prefixes, err := decodeDeltaInt32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	suffixes, err := DecodeDeltaLengthByteArray(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(prefixes) != len(suffixes) {
+		return nil, fmt.Errorf("got %d prefix lengths but %d suffixes", len(prefixes), len(suffixes))
+	}
+
+	values := make([][]byte, len(suffixes))
+	var prev []byte
+	for i := range suffixes {
+		p := int(prefixes[i])
+		if p < 0 || p > len(prev) {
+			return nil, fmt.Errorf("invalid prefix length %d", p)
+		}
+		v := make([]byte, 0, p+len(suffixes[i]))
+		v = append(v, prev[:p]...)
+		values[i] = append(v, suffixes[i]...)
+		prev = values[i]
+	}
+
+	return values, nil
+}
+
+func decodeDeltaInt32(r io.Reader) ([]int32, error) {
+	d, err := NewDeltaDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// the number of values is taken from the data, so the allocation is capped
+	// to not trust it blindly.
+	capacity := 1024
+	if d.total < uint64(capacity) {
+		capacity = int(d.total)
+	}
+	values := make([]int32, 0, capacity)
+	for {
+		v, err := d.NextInt32()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+}
@@ -0,0 +1,94 @@
+package parquetencoding
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeltaInt32(t *testing.T) {
+	tests := [][]int32{
+		{},
+		{42},
+		{1, 2, 3, 4, 5},
+		{math.MaxInt32, math.MinInt32, math.MaxInt32, 0, -1},
+	}
+
+	random := make([]int32, 1000)
+	for i := range random {
+		random[i] = int32(rand.Uint32())
+	}
+	tests = append(tests, random)
+
+	for idx, values := range tests {
+		buf := &bytes.Buffer{}
+		require.NoError(t, EncodeDeltaInt32(buf, values))
+
+		dec, err := NewDeltaDecoder(buf)
+		require.NoError(t, err)
+		require.Equal(t, len(values), dec.Len())
+
+		decoded := make([]int32, len(values))
+		n, err := dec.DecodeInt32(decoded)
+		require.NoError(t, err, "%d. decoding failed", idx)
+		require.Equal(t, len(values), n)
+		require.Equal(t, values, decoded, "%d. values don't match", idx)
+
+		_, err = dec.NextInt32()
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, 0, buf.Len(), "%d. unread data", idx)
+	}
+}
+
+func TestDeltaInt64(t *testing.T) {
+	values := []int64{math.MaxInt64, math.MinInt64, 0, -1, 1}
+	for i := 0; i < 500; i++ {
+		values = append(values, rand.Int63()-rand.Int63())
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, EncodeDeltaInt64(buf, values))
+
+	dec, err := NewDeltaDecoder(buf)
+	require.NoError(t, err)
+	decoded := make([]int64, len(values))
+	_, err = dec.DecodeInt64(decoded)
+	require.NoError(t, err)
+	require.Equal(t, values, decoded)
+}
+
+func TestDeltaByteArray(t *testing.T) {
+	values := [][]byte{[]byte("hello"), []byte("help"), []byte("helping"), {}, []byte("world")}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, EncodeDeltaLengthByteArray(buf, values))
+	decoded, err := DecodeDeltaLengthByteArray(buf)
+	require.NoError(t, err)
+	require.Equal(t, values, decoded)
+
+	buf.Reset()
+	require.NoError(t, EncodeDeltaByteArray(buf, values))
+	decoded, err = DecodeDeltaByteArray(buf)
+	require.NoError(t, err)
+	require.Equal(t, values, decoded)
+	require.Equal(t, 0, buf.Len())
+}
+
+func TestDeltaLengthByteArrayInvalidLength(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, EncodeDeltaInt32(buf, []int32{math.MaxInt32}))
+	buf.WriteString("abc")
+	data := buf.Bytes()
+
+	_, err := DecodeDeltaLengthByteArray(bytes.NewReader(data))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceed the remaining 3 bytes")
+
+	// the size of the remaining input of other readers isn't known.
+	_, err = DecodeDeltaLengthByteArray(io.MultiReader(bytes.NewReader(data)))
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
@@ -0,0 +1,28 @@
+// Package parquetencoding contains the low-level value encodings of the parquet
+// format, i.e. the RLE/bit-packing hybrid encoding, the delta encodings and the
+// plain encoding. The package has no dependencies on the file reader and writer
+// of the parquet-go package, so that other storage projects can use these
+// primitives to encode and decode parquet pages on their own.
+//
+// All encoders write to an io.Writer and all decoders read from an io.Reader.
+// Decoders never read more bytes from the reader than the encoded data contains,
+// so that several encoded sections can be read from the same reader one after
+// the other, like the levels and values of a data page. The PLAIN decoders return
+// the number of decoded values. If the input ends before all values are decoded,
+// they return io.EOF if it ends between two values and io.ErrUnexpectedEOF if it
+// ends within a value.
+//
+// The RLE/bit-packing hybrid encoding is used for repetition and definition
+// levels, dictionary indices and booleans:
+//
+//	buf := &bytes.Buffer{}
+//	if err := parquetencoding.EncodeHybrid(buf, 3, []int32{1, 2, 3, 4}); err != nil {
+//		// ...
+//	}
+//
+//	dec := parquetencoding.NewHybridDecoder(buf, 3)
+//	levels := make([]int32, 4)
+//	if _, err := dec.Decode(levels); err != nil {
+//		// ...
+//	}
+package parquetencoding
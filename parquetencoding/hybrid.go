@@ -0,0 +1,274 @@
+package parquetencoding
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// EncodeHybrid writes the values using the RLE/bit-packing hybrid encoding with the provided
+// bit width. Repeated values are written as RLE runs, all other values as bit-packed runs. As
+// bit-packed runs always contain a multiple of 8 values, the remaining values at the end are
+// written as RLE runs, so that the encoded data contains exactly the provided values. With a
+// bit width of zero all values are zero, and nothing is written.
+func EncodeHybrid(w io.Writer, bitWidth int, values []int32) error {
+	if bitWidth < 0 || bitWidth > 32 {
+		return errInvalidBitWidth
+	}
+
+	for _, v := range values {
+		if bits.Len32(uint32(v)) > bitWidth {
+			return fmt.Errorf("value %d doesn't fit into bit width %d", v, bitWidth)
+		}
+	}
+
+	if bitWidth == 0 {
+		return nil
+	}
+
+	for pos := 0; pos < len(values); {
+		// a RLE run is only used for at least 8 repeated values, which would otherwise
+		// take up a whole bit-packed group.
+		if run := repeatCount(values[pos:]); run >= 8 || len(values)-pos < 8 {
+			if err := writeRLERun(w, bitWidth, values[pos], run); err != nil {
+				return err
+			}
+			pos += run
+			continue
+		}
+
+		end := pos + 8
+		for end+8 <= len(values) && repeatCount(values[end:]) < 8 {
+			end += 8
+		}
+
+		if err := writeBitPackedRun(w, bitWidth, values[pos:end]); err != nil {
+			return err
+		}
+		pos = end
+	}
+
+	return nil
+}
+
+// EncodeHybridWithLength writes the values like EncodeHybrid, but prefixed with the length of the
+// encoded data as 4 byte little endian integer, like it is used for levels in data pages v1. Like
+// levels with a maximum level of zero, nothing at all is written for a bit width of zero.
+func EncodeHybridWithLength(w io.Writer, bitWidth int, values []int32) error {
+	buf := &sliceWriter{}
+	if err := EncodeHybrid(buf, bitWidth, values); err != nil {
+		return err
+	}
+	if bitWidth == 0 {
+		return nil
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(buf.data))); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.data)
+	return err
+}
+
+func repeatCount(values []int32) int {
+	n := 1
+	for n < len(values) && values[n] == values[0] {
+		n++
+	}
+	return n
+}
+
+func writeRLERun(w io.Writer, bitWidth int, value int32, count int) error {
+	if err := writeUvarint(w, uint64(count)<<1); err != nil {
+		return err
+	}
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(value))
+	_, err := w.Write(buf[:(bitWidth+7)/8])
+	return err
+}
+
+// writeBitPackedRun writes the values, whose number must be a multiple of 8, as a bit-packed run.
+func writeBitPackedRun(w io.Writer, bitWidth int, values []int32) error {
+	if err := writeUvarint(w, uint64(len(values)/8)<<1|1); err != nil {
+		return err
+	}
+
+	bw := &bitWriter{data: make([]byte, 0, packedSize(len(values), bitWidth))}
+	for _, v := range values {
+		bw.write(uint64(uint32(v)), bitWidth)
+	}
+	_, err := w.Write(bw.bytes())
+	return err
+}
+
+// HybridDecoder decodes values that are encoded using the RLE/bit-packing hybrid encoding.
+// Always use NewHybridDecoder or NewHybridDecoderWithLength to create such an object.
+type HybridDecoder struct {
+	r        *byteReader
+	bitWidth int
+	err      error
+
+	rleCount uint64
+	rleValue int32
+
+	bpGroups uint64
+	bpValues [8]int32
+	bpPos    int
+
+	buf [32]byte
+}
+
+// NewHybridDecoder creates a new decoder that reads the RLE/bit-packing hybrid encoded values of
+// the provided bit width from r.
+func NewHybridDecoder(r io.Reader, bitWidth int) *HybridDecoder {
+	d := &HybridDecoder{
+		r:        &byteReader{r: r},
+		bitWidth: bitWidth,
+		bpPos:    8,
+	}
+	if bitWidth < 0 || bitWidth > 32 {
+		d.err = errInvalidBitWidth
+	}
+	return d
+}
+
+// NewHybridDecoderWithLength creates a new decoder for values that were encoded using
+// EncodeHybridWithLength, i.e. that are prefixed with the length of the encoded data. For a
+// bit width of zero, nothing is read from r.
+func NewHybridDecoderWithLength(r io.Reader, bitWidth int) (*HybridDecoder, error) {
+	if bitWidth == 0 {
+		return NewHybridDecoder(r, bitWidth), nil
+	}
+
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+
+	return NewHybridDecoder(io.LimitReader(r, int64(size)), bitWidth), nil
+}
+
+// Next returns the next value. It returns io.EOF if there are no more values.
+func (d *HybridDecoder) Next() (int32, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	// with a bit width of zero, all values are zero and no data is stored.
+	if d.bitWidth == 0 {
+		return 0, nil
+	}
+
+	for d.rleCount == 0 && d.bpPos == 8 && d.bpGroups == 0 {
+		if err := d.readRunHeader(); err != nil {
+			d.err = err
+			return 0, err
+		}
+	}
+
+	if d.rleCount > 0 {
+		d.rleCount--
+		return d.rleValue, nil
+	}
+
+	if d.bpPos == 8 {
+		if err := d.readBitPackedGroup(); err != nil {
+			d.err = err
+			return 0, err
+		}
+	}
+
+	v := d.bpValues[d.bpPos]
+	d.bpPos++
+	return v, nil
+}
+
+// Decode fills dst with the next values and returns the number of decoded values. If there
+// are less values available than requested, it returns the number of values and io.EOF.
+func (d *HybridDecoder) Decode(dst []int32) (int, error) {
+	for i := range dst {
+		v, err := d.Next()
+		if err != nil {
+			return i, err
+		}
+		dst[i] = v
+	}
+	return len(dst), nil
+}
+
+func (d *HybridDecoder) readRunHeader() error {
+	h, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return err
+	}
+
+	if h&1 == 1 {
+		if d.bpGroups = h >> 1; d.bpGroups == 0 {
+			return errors.New("empty bit-packed run")
+		}
+		return nil
+	}
+
+	if d.rleCount = h >> 1; d.rleCount == 0 {
+		return errors.New("empty RLE run")
+	}
+
+	buf := d.buf[:4]
+	binary.LittleEndian.PutUint32(buf, 0)
+	if _, err := io.ReadFull(d.r.r, buf[:(d.bitWidth+7)/8]); err != nil {
+		return unexpectedEOF(err)
+	}
+	d.rleValue = int32(binary.LittleEndian.Uint32(buf))
+	if bits.Len32(uint32(d.rleValue)) > d.bitWidth {
+		return errors.New("RLE run value is too large")
+	}
+	return nil
+}
+
+func (d *HybridDecoder) readBitPackedGroup() error {
+	buf := d.buf[:d.bitWidth]
+	if _, err := io.ReadFull(d.r.r, buf); err != nil {
+		return unexpectedEOF(err)
+	}
+
+	// a group of 8 values takes exactly bitWidth bytes, which are shifted into an accumulator
+	// until it holds enough bits for the next value.
+	var (
+		acc uint64
+		n   uint
+		pos int
+	)
+	mask := uint64(1)<<uint(d.bitWidth) - 1
+	for i := range d.bpValues {
+		for n < uint(d.bitWidth) {
+			acc |= uint64(buf[pos]) << n
+			n += 8
+			pos++
+		}
+		d.bpValues[i] = int32(acc & mask)
+		acc >>= uint(d.bitWidth)
+		n -= uint(d.bitWidth)
+	}
+	d.bpGroups--
+	d.bpPos = 0
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+type sliceWriter struct {
+	data []byte
+}
+
+func (w *sliceWriter) Write(p []byte) (int, error) {
+	w.data = append(w.data, p...)
+	return len(p), nil
+}
@@ -0,0 +1,64 @@
+package parquetencoding
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHybrid(t *testing.T) {
+	for bw := 0; bw <= 32; bw++ {
+		values := make([]int32, 1005)
+		for i := range values {
+			switch {
+			case i >= 100 && i < 300:
+				values[i] = values[99]
+			case bw == 32:
+				values[i] = int32(rand.Uint32())
+			case bw > 0:
+				values[i] = int32(rand.Uint32() & (1<<uint(bw) - 1))
+			}
+		}
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, EncodeHybridWithLength(buf, bw, values))
+		buf.WriteString("trailing data")
+
+		dec, err := NewHybridDecoderWithLength(buf, bw)
+		require.NoError(t, err)
+		decoded := make([]int32, len(values))
+		n, err := dec.Decode(decoded)
+		require.NoError(t, err, "bit width %d", bw)
+		require.Equal(t, len(values), n)
+		require.Equal(t, values, decoded, "bit width %d", bw)
+		if bw > 0 {
+			// the encoded data contains exactly the provided values, without padding.
+			_, err = dec.Next()
+			require.Equal(t, io.EOF, err, "bit width %d", bw)
+		}
+
+		require.Equal(t, "trailing data", buf.String(), "bit width %d", bw)
+	}
+}
+
+func TestHybridErrors(t *testing.T) {
+	require.Error(t, EncodeHybrid(&bytes.Buffer{}, 33, nil))
+	require.Error(t, EncodeHybrid(&bytes.Buffer{}, 2, []int32{4}))
+
+	_, err := NewHybridDecoder(bytes.NewReader(nil), 33).Next()
+	require.Error(t, err)
+
+	_, err = NewHybridDecoder(bytes.NewReader(nil), 3).Next()
+	require.Equal(t, io.EOF, err)
+
+	// RLE run with value 8 for bit width 3.
+	_, err = NewHybridDecoder(bytes.NewReader([]byte{2, 8}), 3).Next()
+	require.Error(t, err)
+
+	// bit-packed run with missing data.
+	_, err = NewHybridDecoder(bytes.NewReader([]byte{3, 1}), 3).Next()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
@@ -0,0 +1,230 @@
+package parquetencoding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// EncodePlainBoolean writes the values using the PLAIN encoding, i.e. bit-packed with one bit per value.
+func EncodePlainBoolean(w io.Writer, values []bool) error {
+	bw := &bitWriter{data: make([]byte, 0, packedSize(len(values), 1))}
+	for _, v := range values {
+		var b uint64
+		if v {
+			b = 1
+		}
+		bw.write(b, 1)
+	}
+	_, err := w.Write(bw.bytes())
+	return err
+}
+
+// DecodePlainBoolean reads len(dst) PLAIN encoded values into dst and returns the number of
+// decoded values. If the input ends early, all values of the bytes that were read are decoded.
+func DecodePlainBoolean(r io.Reader, dst []bool) (int, error) {
+	buf, _, err := readFixed(r, packedSize(len(dst), 1), 1)
+
+	n := len(buf) * 8
+	if n > len(dst) {
+		n = len(dst)
+	}
+	br := &bitReader{data: buf}
+	for i := range dst[:n] {
+		dst[i] = br.read(1) == 1
+	}
+	return n, err
+}
+
+// EncodePlainInt32 writes the values using the PLAIN encoding.
+func EncodePlainInt32(w io.Writer, values []int32) error {
+	return binary.Write(w, binary.LittleEndian, values)
+}
+
+// DecodePlainInt32 reads len(dst) PLAIN encoded values into dst and returns the number of
+// decoded values.
+func DecodePlainInt32(r io.Reader, dst []int32) (int, error) {
+	buf, n, err := readFixed(r, len(dst), 4)
+	for i := range dst[:n] {
+		dst[i] = int32(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return n, err
+}
+
+// EncodePlainInt64 writes the values using the PLAIN encoding.
+func EncodePlainInt64(w io.Writer, values []int64) error {
+	return binary.Write(w, binary.LittleEndian, values)
+}
+
+// DecodePlainInt64 reads len(dst) PLAIN encoded values into dst and returns the number of
+// decoded values.
+func DecodePlainInt64(r io.Reader, dst []int64) (int, error) {
+	buf, n, err := readFixed(r, len(dst), 8)
+	for i := range dst[:n] {
+		dst[i] = int64(binary.LittleEndian.Uint64(buf[8*i:]))
+	}
+	return n, err
+}
+
+// EncodePlainInt96 writes the values using the PLAIN encoding.
+func EncodePlainInt96(w io.Writer, values [][12]byte) error {
+	for i := range values {
+		if _, err := w.Write(values[i][:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodePlainInt96 reads len(dst) PLAIN encoded values into dst and returns the number of
+// decoded values.
+func DecodePlainInt96(r io.Reader, dst [][12]byte) (int, error) {
+	buf, n, err := readFixed(r, len(dst), 12)
+	for i := range dst[:n] {
+		copy(dst[i][:], buf[12*i:])
+	}
+	return n, err
+}
+
+// EncodePlainFloat writes the values using the PLAIN encoding.
+func EncodePlainFloat(w io.Writer, values []float32) error {
+	return binary.Write(w, binary.LittleEndian, values)
+}
+
+// DecodePlainFloat reads len(dst) PLAIN encoded values into dst and returns the number of
+// decoded values.
+func DecodePlainFloat(r io.Reader, dst []float32) (int, error) {
+	buf, n, err := readFixed(r, len(dst), 4)
+	for i := range dst[:n] {
+		dst[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return n, err
+}
+
+// EncodePlainDouble writes the values using the PLAIN encoding.
+func EncodePlainDouble(w io.Writer, values []float64) error {
+	return binary.Write(w, binary.LittleEndian, values)
+}
+
+// DecodePlainDouble reads len(dst) PLAIN encoded values into dst and returns the number of
+// decoded values.
+func DecodePlainDouble(r io.Reader, dst []float64) (int, error) {
+	buf, n, err := readFixed(r, len(dst), 8)
+	for i := range dst[:n] {
+		dst[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[8*i:]))
+	}
+	return n, err
+}
+
+// EncodePlainByteArray writes the values using the PLAIN encoding, i.e. every value prefixed
+// with its length as 4 byte little endian integer.
+func EncodePlainByteArray(w io.Writer, values [][]byte) error {
+	for i := range values {
+		if len(values[i]) > math.MaxInt32 {
+			return fmt.Errorf("byte array of size %d is too large", len(values[i]))
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint32(len(values[i]))); err != nil {
+			return err
+		}
+		if _, err := w.Write(values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodePlainByteArray reads len(dst) PLAIN encoded values into dst and returns the number of
+// decoded values.
+func DecodePlainByteArray(r io.Reader, dst [][]byte) (int, error) {
+	for i := range dst {
+		var l uint32
+		if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
+			return i, err
+		}
+		if l > math.MaxInt32 {
+			return i, fmt.Errorf("byte array of size %d is too large", l)
+		}
+
+		v, err := readBytes(r, int(l))
+		if err != nil {
+			return i, err
+		}
+		dst[i] = v
+	}
+	return len(dst), nil
+}
+
+// EncodePlainFixedLenByteArray writes the values using the PLAIN encoding. All values must have
+// the provided length.
+func EncodePlainFixedLenByteArray(w io.Writer, length int, values [][]byte) error {
+	for i := range values {
+		if len(values[i]) != length {
+			return fmt.Errorf("value %d has length %d but %d was expected", i, len(values[i]), length)
+		}
+		if _, err := w.Write(values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodePlainFixedLenByteArray reads len(dst) PLAIN encoded values of the provided length into
+// dst and returns the number of decoded values.
+func DecodePlainFixedLenByteArray(r io.Reader, length int, dst [][]byte) (int, error) {
+	if length <= 0 {
+		return 0, fmt.Errorf("invalid length %d", length)
+	}
+
+	buf, n, err := readFixed(r, len(dst), length)
+	for i := range dst[:n] {
+		// the capacity is limited so that appending to a value can't overwrite the next value.
+		dst[i] = buf[i*length : (i+1)*length : (i+1)*length]
+	}
+	return n, err
+}
+
+// readFixed reads count values of size bytes and returns the data and the number of complete
+// values that were read. If the input ends early, it returns io.EOF if it ends between two values
+// and io.ErrUnexpectedEOF if it ends within a value.
+func readFixed(r io.Reader, count, size int) ([]byte, int, error) {
+	buf := make([]byte, count*size)
+	n, err := io.ReadFull(r, buf)
+	switch {
+	case err == nil:
+		return buf, count, nil
+	case (err == io.EOF || err == io.ErrUnexpectedEOF) && n%size == 0:
+		return buf[:n], n / size, io.EOF
+	}
+	return buf[:n], n / size, err
+}
+
+// maxTrustedLength is the largest length read from the data that is allocated at once if the size
+// of the remaining input is unknown.
+const maxTrustedLength = 64 * 1024
+
+// readBytes reads a value of length l. The length is taken from the data, so it's checked against
+// the remaining input if its size is known. Otherwise, large values grow while reading instead of
+// trusting the length blindly.
+func readBytes(r io.Reader, l int) ([]byte, error) {
+	lr, ok := r.(interface{ Len() int })
+	if ok && l > lr.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	if ok || l <= maxTrustedLength {
+		v := make([]byte, l)
+		if _, err := io.ReadFull(r, v); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return v, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := io.CopyN(buf, r, int64(l)); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	v := buf.Bytes()
+	return v[:len(v):len(v)], nil
+}
@@ -0,0 +1,98 @@
+package parquetencoding
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlain(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	bools := []bool{true, false, false, true, true, true, false, true, true}
+	require.NoError(t, EncodePlainBoolean(buf, bools))
+	int32s := []int32{1, -2, 3}
+	require.NoError(t, EncodePlainInt32(buf, int32s))
+	int64s := []int64{-1, 2, -3}
+	require.NoError(t, EncodePlainInt64(buf, int64s))
+	int96s := [][12]byte{{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}}
+	require.NoError(t, EncodePlainInt96(buf, int96s))
+	floats := []float32{1.5, -2.25}
+	require.NoError(t, EncodePlainFloat(buf, floats))
+	doubles := []float64{1.5, -2.25}
+	require.NoError(t, EncodePlainDouble(buf, doubles))
+	byteArrays := [][]byte{[]byte("foo"), {}, []byte("bar")}
+	require.NoError(t, EncodePlainByteArray(buf, byteArrays))
+	fixed := [][]byte{[]byte("ab"), []byte("cd")}
+	require.NoError(t, EncodePlainFixedLenByteArray(buf, 2, fixed))
+	require.Error(t, EncodePlainFixedLenByteArray(buf, 3, fixed))
+
+	decodedBools := make([]bool, len(bools))
+	_, err := DecodePlainBoolean(buf, decodedBools)
+	require.NoError(t, err)
+	require.Equal(t, bools, decodedBools)
+	decodedInt32s := make([]int32, len(int32s))
+	_, err = DecodePlainInt32(buf, decodedInt32s)
+	require.NoError(t, err)
+	require.Equal(t, int32s, decodedInt32s)
+	decodedInt64s := make([]int64, len(int64s))
+	_, err = DecodePlainInt64(buf, decodedInt64s)
+	require.NoError(t, err)
+	require.Equal(t, int64s, decodedInt64s)
+	decodedInt96s := make([][12]byte, len(int96s))
+	_, err = DecodePlainInt96(buf, decodedInt96s)
+	require.NoError(t, err)
+	require.Equal(t, int96s, decodedInt96s)
+	decodedFloats := make([]float32, len(floats))
+	_, err = DecodePlainFloat(buf, decodedFloats)
+	require.NoError(t, err)
+	require.Equal(t, floats, decodedFloats)
+	decodedDoubles := make([]float64, len(doubles))
+	_, err = DecodePlainDouble(buf, decodedDoubles)
+	require.NoError(t, err)
+	require.Equal(t, doubles, decodedDoubles)
+	decodedByteArrays := make([][]byte, len(byteArrays))
+	_, err = DecodePlainByteArray(buf, decodedByteArrays)
+	require.NoError(t, err)
+	require.Equal(t, byteArrays, decodedByteArrays)
+	decodedFixed := make([][]byte, len(fixed))
+	_, err = DecodePlainFixedLenByteArray(buf, 2, decodedFixed)
+	require.NoError(t, err)
+	require.Equal(t, fixed, decodedFixed)
+
+	require.Equal(t, 0, buf.Len())
+	_, err = DecodePlainInt32(buf, make([]int32, 1))
+	require.Equal(t, io.EOF, err)
+}
+
+func TestPlainShortInput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, EncodePlainInt64(buf, []int64{1, 2}))
+	data := buf.Bytes()
+
+	decoded := make([]int64, 3)
+	n, err := DecodePlainInt64(bytes.NewReader(data), decoded)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []int64{1, 2}, decoded[:n])
+
+	n, err = DecodePlainInt64(bytes.NewReader(data[:12]), decoded)
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	require.Equal(t, 1, n)
+
+	buf.Reset()
+	require.NoError(t, EncodePlainByteArray(buf, [][]byte{[]byte("foo"), []byte("bar")}))
+	byteArrays := make([][]byte, 2)
+	n, err = DecodePlainByteArray(bytes.NewReader(buf.Bytes()[:9]), byteArrays)
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []byte("foo"), byteArrays[0])
+
+	bools := make([]bool, 10)
+	n, err = DecodePlainBoolean(bytes.NewReader([]byte{0x81}), bools)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, 8, n)
+	require.Equal(t, []bool{true, false, false, false, false, false, false, true}, bools[:n])
+}
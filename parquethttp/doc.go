@@ -0,0 +1,36 @@
+/*
+Package parquethttp serves parquet files over HTTP. It works in conjunction with the goparquet
+package.
+
+The Handler streams the rows of a file as newline-delimited JSON, one object per row. The file is
+selected by the path of the request, and opened using the FileOpener that was provided to
+NewHandler:
+
+	h := parquethttp.NewHandler(func(name string) (parquethttp.File, error) {
+		return os.Open(filepath.Join(dataDir, filepath.FromSlash(name)))
+	})
+	http.Handle("/files/", http.StripPrefix("/files/", h))
+
+The rows can be limited using the following query parameters:
+
+	columns=a,b.c    only return the columns a and b.c (projection)
+	filter=a:gt:10   only return rows whose column a is greater than 10
+	limit=100        return at most 100 rows
+
+The filter parameter can be provided multiple times, all filters need to match. The supported
+operators are eq, ne, lt, le, gt and ge. Filters are supported on non-repeated columns of all
+types except INT96 and unsigned integers, and are also applied to the statistics of the row
+groups, so that row groups without matching rows aren't read at all. The values are parsed
+according to the logical type of the column: decimals like 10.5, dates like 2006-01-02,
+timestamps in RFC 3339 format, UUIDs in their canonical form and the values of other
+FIXED_LEN_BYTE_ARRAY columns as hexadecimal strings. Binary values are compared in the order set
+by goparquet.WithPruningBinaryComparator, which is unsigned byte-wise by default. Rows in which a
+filter column is null never match.
+
+The rows are encoded like goparquet.Row, e.g. binary columns with a STRING, ENUM or JSON
+annotation are returned as JSON strings and other binary columns as base64 encoded strings. If an
+error occurs after the first row was sent, a final object with the key "error" is written.
+
+Only JSON is supported as output format, other values of the format query parameter are rejected.
+*/
+package parquethttp
@@ -0,0 +1,185 @@
+package parquethttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/internal/rowfilter"
+)
+
+// File is a parquet file that is served by the Handler.
+type File interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// FileOpener opens the file with the provided name. The name is the path of the request without
+// leading slash. Paths that aren't valid as defined by fs.ValidPath, e.g. because they contain ".."
+// elements, are rejected with status 400 before the FileOpener is called. If the returned error
+// satisfies os.IsNotExist, the handler responds with status 404.
+type FileOpener func(name string) (File, error)
+
+// flushInterval is the number of rows after which the response is flushed.
+const flushInterval = 1000
+
+// Handler is an http.Handler that streams the rows of parquet files as newline-delimited JSON.
+type Handler struct {
+	open FileOpener
+	opts []goparquet.FileReaderOption
+}
+
+// NewHandler creates a new Handler that opens files using open. The provided options are used
+// for all file readers in addition to the options derived from the query parameters.
+func NewHandler(open FileOpener, opts ...goparquet.FileReaderOption) *Handler {
+	return &Handler{
+		open: open,
+		opts: opts,
+	}
+}
+
+// operators maps the operators of the filter query parameter to the filter operators.
+var operators = map[string]rowfilter.Op{
+	"eq": rowfilter.Eq,
+	"ne": rowfilter.Ne,
+	"lt": rowfilter.Lt,
+	"le": rowfilter.Le,
+	"gt": rowfilter.Gt,
+	"ge": rowfilter.Ge,
+}
+
+// query contains the parsed query parameters of a request.
+type query struct {
+	rowfilter.Query
+	limit int64
+}
+
+func parseQuery(r *http.Request) (*query, error) {
+	params := r.URL.Query()
+
+	if format := params.Get("format"); format != "" && format != "json" {
+		return nil, fmt.Errorf("format %q is not supported", format)
+	}
+
+	q := &query{limit: -1}
+
+	if columns := params.Get("columns"); columns != "" {
+		q.Columns = strings.Split(columns, ",")
+	}
+
+	for _, f := range params["filter"] {
+		parts := strings.SplitN(f, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid filter %q, expected <column>:<operator>:<value>", f)
+		}
+		op, ok := operators[parts[1]]
+		if !ok {
+			return nil, fmt.Errorf("invalid operator %q in filter %q", parts[1], f)
+		}
+		q.Filters = append(q.Filters, &rowfilter.Filter{Column: parts[0], Op: op, Value: parts[2]})
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid limit %q", limit)
+		}
+		q.limit = n
+	}
+
+	return q, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := parseQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if !validPath(name) {
+		http.Error(w, "invalid file name", http.StatusBadRequest)
+		return
+	}
+
+	file, err := h.open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "file not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, fmt.Sprintf("opening file failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	reader, err := goparquet.NewFileReaderWithOptions(file, h.opts...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading file failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err := q.Prepare(reader); err != nil {
+		if _, ok := err.(*rowfilter.QueryError); ok {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("reading file failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	q.stream(w, r, reader)
+}
+
+func (q *query) stream(w http.ResponseWriter, r *http.Request, reader *goparquet.FileReader) {
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	sd := reader.GetSchemaDefinition()
+
+	var written int64
+	for q.limit < 0 || written < q.limit {
+		if err := r.Context().Err(); err != nil {
+			return
+		}
+
+		row, err := reader.NextRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = enc.Encode(map[string]string{"error": err.Error()})
+			return
+		}
+
+		if !q.Match(row) {
+			continue
+		}
+		q.Project(row)
+
+		if err := enc.Encode(&goparquet.Row{Values: row, Schema: sd}); err != nil {
+			return
+		}
+
+		written++
+		if flusher != nil && written%flushInterval == 0 {
+			flusher.Flush()
+		}
+	}
+
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,141 @@
+package parquethttp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+type testFile struct {
+	*bytes.Reader
+	footerSeeks *int
+}
+
+func (f testFile) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekEnd {
+		*f.footerSeeks++
+	}
+	return f.Reader.Seek(offset, whence)
+}
+
+func (testFile) Close() error {
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		required group info {
+			required double score;
+			optional binary raw;
+		}
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := goparquet.NewFileWriter(buf, goparquet.WithSchemaDefinition(sd))
+	for i := 0; i < 30; i++ {
+		data := map[string]interface{}{
+			"id":   int64(i),
+			"info": map[string]interface{}{"score": float64(i) / 2, "raw": []byte{byte(i)}},
+		}
+		if i%2 == 0 {
+			data["name"] = []byte{byte('a' + i%26)}
+		}
+		require.NoError(t, w.AddData(data))
+		if i%10 == 9 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+
+	var idPages, footerSeeks int
+	h := NewHandler(func(name string) (File, error) {
+		if name != "test.parquet" {
+			return nil, os.ErrNotExist
+		}
+		return testFile{Reader: bytes.NewReader(buf.Bytes()), footerSeeks: &footerSeeks}, nil
+	}, goparquet.WithPageMiddleware(func(page *goparquet.PageData) error {
+		if page.Column == "id" {
+			idPages++
+		}
+		return nil
+	}))
+
+	get := func(query string) (int, []map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, query, nil))
+		if rec.Code != http.StatusOK {
+			return rec.Code, nil
+		}
+		require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+		var rows []map[string]interface{}
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			var row map[string]interface{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+			rows = append(rows, row)
+		}
+		return rec.Code, rows
+	}
+
+	code, rows := get("/test.parquet")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, rows, 30)
+
+	// the footer is only read once per request.
+	var singleSeeks int
+	_, err = goparquet.NewFileReader(testFile{Reader: bytes.NewReader(buf.Bytes()), footerSeeks: &singleSeeks})
+	require.NoError(t, err)
+	require.Equal(t, singleSeeks, footerSeeks)
+	require.Equal(t, map[string]interface{}{
+		"id":   float64(2),
+		"name": "c",
+		"info": map[string]interface{}{"score": float64(1), "raw": "Ag=="},
+	}, rows[2])
+
+	// only the second row group can contain matching rows.
+	idPages = 0
+	code, rows = get("/test.parquet?columns=name&filter=id:ge:12&filter=info.score:lt:8&limit=2")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []map[string]interface{}{{"name": "m"}, {}}, rows)
+	require.Equal(t, 1, idPages)
+
+	code, rows = get("/test.parquet?columns=info&filter=name:eq:e")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, []map[string]interface{}{{"info": map[string]interface{}{"score": float64(2), "raw": "BA=="}}}, rows)
+
+	code, rows = get("/test.parquet?filter=id:gt:100")
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, rows)
+
+	for query, status := range map[string]int{
+		"/unknown.parquet":                  http.StatusNotFound,
+		"/../test.parquet":                  http.StatusBadRequest,
+		"/sub/./test.parquet":               http.StatusBadRequest,
+		"/sub//test.parquet":                http.StatusBadRequest,
+		"/sub\\test.parquet":                http.StatusBadRequest,
+		"/test.parquet?format=arrow":        http.StatusBadRequest,
+		"/test.parquet?columns=unknown":     http.StatusBadRequest,
+		"/test.parquet?filter=id:gt":        http.StatusBadRequest,
+		"/test.parquet?filter=id:like:1":    http.StatusBadRequest,
+		"/test.parquet?filter=id:gt:foo":    http.StatusBadRequest,
+		"/test.parquet?filter=info:eq:1":    http.StatusBadRequest,
+		"/test.parquet?filter=unknown:eq:1": http.StatusBadRequest,
+		"/test.parquet?limit=-1":            http.StatusBadRequest,
+	} {
+		code, _ := get(query)
+		require.Equal(t, status, code, query)
+	}
+}
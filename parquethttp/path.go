@@ -0,0 +1,16 @@
+//go:build go1.16
+// +build go1.16
+
+package parquethttp
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// validPath returns true if name is a valid path as defined by fs.ValidPath, i.e. an unrooted,
+// slash-separated path without empty, "." or ".." elements. Backslashes are rejected as well, as
+// they are separators for FileOpeners that use the os package on Windows.
+func validPath(name string) bool {
+	return fs.ValidPath(name) && !strings.Contains(name, `\`)
+}
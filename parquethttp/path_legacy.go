@@ -0,0 +1,21 @@
+//go:build !go1.16
+// +build !go1.16
+
+package parquethttp
+
+import "strings"
+
+// validPath returns true if name is an unrooted, slash-separated path without empty, "." or ".."
+// elements, like fs.ValidPath, which isn't available before Go 1.16. Backslashes are rejected as
+// well, as they are separators for FileOpeners that use the os package on Windows.
+func validPath(name string) bool {
+	if name == "." {
+		return true
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			return false
+		}
+	}
+	return !strings.Contains(name, `\`)
+}
@@ -0,0 +1,247 @@
+package parquetschema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// SchemaDifferenceKind describes how a column differs between two schema definitions.
+type SchemaDifferenceKind int
+
+const (
+	// ColumnAdded means that the column only exists in the new schema definition.
+	ColumnAdded SchemaDifferenceKind = iota
+	// ColumnRemoved means that the column only exists in the old schema definition.
+	ColumnRemoved
+	// ColumnRetyped means that the physical type, the type length, the logical or converted
+	// type of the column or whether it's a group changed.
+	ColumnRetyped
+	// RepetitionChanged means that the repetition type of the column changed.
+	RepetitionChanged
+)
+
+func (k SchemaDifferenceKind) String() string {
+	switch k {
+	case ColumnAdded:
+		return "added"
+	case ColumnRemoved:
+		return "removed"
+	case ColumnRetyped:
+		return "retyped"
+	case RepetitionChanged:
+		return "repetition changed"
+	}
+	return fmt.Sprintf("SchemaDifferenceKind(%d)", int(k))
+}
+
+// SchemaDifference is a single difference between two schema definitions.
+type SchemaDifference struct {
+	// Kind describes how the column differs.
+	Kind SchemaDifferenceKind
+	// Column is the path of the column in dotted notation.
+	Column string
+	// Old describes the column in the old schema definition, e.g. "optional int32 (INT(16, true))".
+	// It is empty if the column was added.
+	Old string
+	// New describes the column in the new schema definition. It is empty if the column was
+	// removed.
+	New string
+	// Compatible is true if the difference doesn't prevent data that was written with the new
+	// schema definition from being read as the old schema definition.
+	Compatible bool
+}
+
+func (d SchemaDifference) String() string {
+	verdict := "incompatible"
+	if d.Compatible {
+		verdict = "compatible"
+	}
+	switch d.Kind {
+	case ColumnAdded:
+		return fmt.Sprintf("column %s %s as %s (%s)", d.Column, d.Kind, d.New, verdict)
+	case ColumnRemoved:
+		return fmt.Sprintf("column %s %s, was %s (%s)", d.Column, d.Kind, d.Old, verdict)
+	}
+	return fmt.Sprintf("column %s %s from %s to %s (%s)", d.Column, d.Kind, d.Old, d.New, verdict)
+}
+
+// SchemaComparison is the result of CompareSchemas.
+type SchemaComparison struct {
+	// Differences contains all differences between the schema definitions in the order of the
+	// columns of the old schema definition, followed by the added columns.
+	Differences []SchemaDifference
+}
+
+// Compatible returns true if none of the differences is incompatible.
+func (c *SchemaComparison) Compatible() bool {
+	for _, d := range c.Differences {
+		if !d.Compatible {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal returns true if the schema definitions have no differences.
+func (c *SchemaComparison) Equal() bool {
+	return len(c.Differences) == 0
+}
+
+// CompareSchemas compares the schema definition b to the schema definition a and returns the
+// differences of the columns, which are matched by their names. The names of the root columns are
+// ignored. The verdict of a difference tells whether data written with b can still be read by a
+// reader that expects a:
+//
+// Added columns are compatible, as readers ignore them. Removed columns are compatible if they
+// are optional or repeated in a, as readers return nulls resp. empty lists for them. Relaxing
+// a required column to optional is incompatible, while making an optional column required is
+// compatible. Any change from or to a repeated column is incompatible. Retyped columns are only
+// compatible if they can be widened from the type in b to the type in a, i.e. from int32 to int64
+// if both are signed integers, or from float to double if both aren't annotated. Converted types
+// are compared as the logical types they correspond to, so that a column annotated as UTF8 by an
+// older writer matches a column annotated as STRING.
+func CompareSchemas(a, b *SchemaDefinition) *SchemaComparison {
+	c := &SchemaComparison{}
+
+	var aCols, bCols []*ColumnDefinition
+	if a != nil && a.RootColumn != nil {
+		aCols = a.RootColumn.Children
+	}
+	if b != nil && b.RootColumn != nil {
+		bCols = b.RootColumn.Children
+	}
+
+	c.compareColumns(nil, aCols, bCols)
+	return c
+}
+
+func (c *SchemaComparison) compareColumns(path []string, aCols, bCols []*ColumnDefinition) {
+	for _, aCol := range aCols {
+		name := aCol.SchemaElement.GetName()
+		colPath := append(path[:len(path):len(path)], name)
+		bCol := findColumn(bCols, name)
+		if bCol == nil {
+			c.Differences = append(c.Differences, SchemaDifference{
+				Kind:       ColumnRemoved,
+				Column:     strings.Join(colPath, "."),
+				Old:        describeColumn(aCol.SchemaElement),
+				Compatible: aCol.SchemaElement.GetRepetitionType() != parquet.FieldRepetitionType_REQUIRED,
+			})
+			continue
+		}
+		c.compareColumn(colPath, aCol, bCol)
+	}
+
+	for _, bCol := range bCols {
+		name := bCol.SchemaElement.GetName()
+		if findColumn(aCols, name) != nil {
+			continue
+		}
+		c.Differences = append(c.Differences, SchemaDifference{
+			Kind:       ColumnAdded,
+			Column:     strings.Join(append(path[:len(path):len(path)], name), "."),
+			New:        describeColumn(bCol.SchemaElement),
+			Compatible: true,
+		})
+	}
+}
+
+func (c *SchemaComparison) compareColumn(path []string, aCol, bCol *ColumnDefinition) {
+	aElem, bElem := aCol.SchemaElement, bCol.SchemaElement
+	column := strings.Join(path, ".")
+
+	if aRep, bRep := aElem.GetRepetitionType(), bElem.GetRepetitionType(); aRep != bRep {
+		c.Differences = append(c.Differences, SchemaDifference{
+			Kind:       RepetitionChanged,
+			Column:     column,
+			Old:        describeColumn(aElem),
+			New:        describeColumn(bElem),
+			Compatible: aRep == parquet.FieldRepetitionType_OPTIONAL && bRep == parquet.FieldRepetitionType_REQUIRED,
+		})
+	}
+
+	if !EquivalentTypes(aElem, bElem) {
+		c.Differences = append(c.Differences, SchemaDifference{
+			Kind:       ColumnRetyped,
+			Column:     column,
+			Old:        describeColumn(aElem),
+			New:        describeColumn(bElem),
+			Compatible: IsWidening(aElem, bElem),
+		})
+	}
+
+	// the children of groups are compared even if the annotation of the group changed, but not
+	// if a group was replaced by a primitive column or vice versa.
+	if (aElem.Type == nil) == (bElem.Type == nil) {
+		c.compareColumns(path, aCol.Children, bCol.Children)
+	}
+}
+
+func findColumn(cols []*ColumnDefinition, name string) *ColumnDefinition {
+	for _, col := range cols {
+		if col.SchemaElement.GetName() == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// describeColumn returns the repetition type, the type and the annotation of the column.
+func describeColumn(elem *parquet.SchemaElement) string {
+	return strings.ToLower(elem.GetRepetitionType().String()) + " " + describeType(elem)
+}
+
+// describeType returns the type and the annotation of the column.
+func describeType(elem *parquet.SchemaElement) string {
+	return typeWithAnnotation(elem, getAnnotation(elem))
+}
+
+func typeWithAnnotation(elem *parquet.SchemaElement, annotation string) string {
+	typ := "group"
+	if elem.Type != nil {
+		typ = getSchemaType(elem)
+	}
+	if annotation != "" {
+		typ += " (" + annotation + ")"
+	}
+	return typ
+}
+
+// EquivalentTypes returns true if the columns described by a and b have the same physical type
+// and type length, or are both groups, and have equivalent annotations. A converted type is
+// equivalent to the logical type it corresponds to, e.g. UTF8 to STRING and INT_32 to
+// INT(32, true).
+func EquivalentTypes(a, b *parquet.SchemaElement) bool {
+	return normalizedType(a) == normalizedType(b)
+}
+
+// normalizedType returns the type and the normalized annotation of the column.
+func normalizedType(elem *parquet.SchemaElement) string {
+	return typeWithAnnotation(elem, normalizedAnnotation(elem))
+}
+
+// IsWidening returns true if values of the column described by from can be converted to values of
+// the column described by to without loss, i.e. from int32 to int64 if both are signed integers,
+// or from float to double if both aren't annotated. Converted types are treated like their
+// equivalent logical types.
+func IsWidening(to, from *parquet.SchemaElement) bool {
+	switch {
+	case from.GetType() == parquet.Type_INT32 && to.GetType() == parquet.Type_INT64:
+		return isSignedInteger(from) && isSignedInteger(to)
+	case from.GetType() == parquet.Type_FLOAT && to.GetType() == parquet.Type_DOUBLE:
+		return normalizedAnnotation(from) == "" && normalizedAnnotation(to) == ""
+	}
+	return false
+}
+
+// isSignedInteger returns true if the column is either not annotated or annotated as a signed
+// integer.
+func isSignedInteger(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType == nil && elem.ConvertedType == nil {
+		return true
+	}
+	lt := LogicalTypeOf(elem)
+	return lt != nil && lt.IsSetINTEGER() && lt.INTEGER.IsSigned
+}
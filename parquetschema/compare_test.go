@@ -0,0 +1,160 @@
+package parquetschema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	a, err := ParseSchemaDefinition(`message old {
+		required int64 id;
+		required int32 count (INT(16, true));
+		optional double score;
+		optional binary name (STRING);
+		required binary key;
+		optional group tags (LIST) {
+			repeated group list {
+				required binary element (STRING);
+			}
+		}
+		optional int32 removed;
+		required int32 removed_required;
+		required group info {
+			optional int64 age;
+			optional int32 size;
+		}
+		repeated int32 values;
+	}`)
+	require.NoError(t, err)
+
+	b, err := ParseSchemaDefinition(`message new {
+		required int64 id;
+		required int64 count;
+		optional float score;
+		required binary name (STRING);
+		optional binary key (ENUM);
+		optional group tags (LIST) {
+			repeated group list {
+				required int32 element;
+			}
+		}
+		required group info {
+			optional int32 age;
+			optional int32 size;
+			optional binary note;
+		}
+		optional int32 values;
+		required binary added;
+	}`)
+	require.NoError(t, err)
+
+	c := CompareSchemas(a, b)
+	require.False(t, c.Compatible())
+	require.False(t, c.Equal())
+
+	expected := []string{
+		"column count retyped from required int32 (INT(16, true)) to required int64 (incompatible)",
+		"column score retyped from optional double to optional float (compatible)",
+		"column name repetition changed from optional binary (STRING) to required binary (STRING) (compatible)",
+		"column key repetition changed from required binary to optional binary (ENUM) (incompatible)",
+		"column key retyped from required binary to optional binary (ENUM) (incompatible)",
+		"column tags.list.element retyped from required binary (STRING) to required int32 (incompatible)",
+		"column removed removed, was optional int32 (compatible)",
+		"column removed_required removed, was required int32 (incompatible)",
+		"column info.age retyped from optional int64 to optional int32 (compatible)",
+		"column info.note added as optional binary (compatible)",
+		"column values repetition changed from repeated int32 to optional int32 (incompatible)",
+		"column added added as required binary (compatible)",
+	}
+	var actual []string
+	for _, d := range c.Differences {
+		actual = append(actual, d.String())
+	}
+	require.Equal(t, expected, actual)
+
+	require.Equal(t, ColumnRetyped, c.Differences[0].Kind)
+	require.Equal(t, "count", c.Differences[0].Column)
+	require.Equal(t, "required int32 (INT(16, true))", c.Differences[0].Old)
+	require.Equal(t, "required int64", c.Differences[0].New)
+
+	c = CompareSchemas(a, a)
+	require.True(t, c.Equal())
+	require.True(t, c.Compatible())
+
+	c = CompareSchemas(b, a)
+	require.False(t, c.Compatible())
+}
+
+func TestCompareSchemasConvertedTypes(t *testing.T) {
+	tests := []struct {
+		typ       string
+		converted parquet.ConvertedType
+		logical   string
+	}{
+		{"binary", parquet.ConvertedType_UTF8, "STRING"},
+		{"binary", parquet.ConvertedType_ENUM, "ENUM"},
+		{"binary", parquet.ConvertedType_JSON, "JSON"},
+		{"binary", parquet.ConvertedType_BSON, "BSON"},
+		{"int32", parquet.ConvertedType_DATE, "DATE"},
+		{"int32", parquet.ConvertedType_DECIMAL, "DECIMAL(9, 2)"},
+		{"int32", parquet.ConvertedType_TIME_MILLIS, "TIME(MILLIS, true)"},
+		{"int64", parquet.ConvertedType_TIME_MICROS, "TIME(MICROS, true)"},
+		{"int64", parquet.ConvertedType_TIMESTAMP_MILLIS, "TIMESTAMP(MILLIS, true)"},
+		{"int64", parquet.ConvertedType_TIMESTAMP_MICROS, "TIMESTAMP(MICROS, true)"},
+		{"int32", parquet.ConvertedType_INT_8, "INT(8, true)"},
+		{"int32", parquet.ConvertedType_INT_16, "INT(16, true)"},
+		{"int32", parquet.ConvertedType_INT_32, "INT(32, true)"},
+		{"int64", parquet.ConvertedType_INT_64, "INT(64, true)"},
+		{"int32", parquet.ConvertedType_UINT_8, "INT(8, false)"},
+		{"int32", parquet.ConvertedType_UINT_16, "INT(16, false)"},
+		{"int32", parquet.ConvertedType_UINT_32, "INT(32, false)"},
+		{"int64", parquet.ConvertedType_UINT_64, "INT(64, false)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.converted.String(), func(t *testing.T) {
+			logical, err := ParseSchemaDefinition(fmt.Sprintf("message m { optional %s c (%s); }", tt.typ, tt.logical))
+			require.NoError(t, err)
+
+			legacy, err := ParseSchemaDefinition(fmt.Sprintf("message m { optional %s c; }", tt.typ))
+			require.NoError(t, err)
+			elem := legacy.RootColumn.Children[0].SchemaElement
+			elem.ConvertedType = parquet.ConvertedTypePtr(tt.converted)
+			if tt.converted == parquet.ConvertedType_DECIMAL {
+				precision, scale := int32(9), int32(2)
+				elem.Precision, elem.Scale = &precision, &scale
+			}
+
+			c := CompareSchemas(logical, legacy)
+			require.True(t, c.Equal(), "%v", c.Differences)
+
+			c = CompareSchemas(legacy, logical)
+			require.True(t, c.Equal(), "%v", c.Differences)
+		})
+	}
+
+	a, err := ParseSchemaDefinition(`message m { optional int64 c (INT(64, true)); optional group l (LIST) { repeated group list { optional binary element (STRING); } } }`)
+	require.NoError(t, err)
+	b, err := ParseSchemaDefinition(`message m { optional int32 c (INT_32); optional group l (LIST) { repeated group list { optional binary element (UTF8); } } }`)
+	require.NoError(t, err)
+	b.RootColumn.Children[1].SchemaElement.LogicalType = nil
+
+	c := CompareSchemas(a, b)
+	require.True(t, c.Compatible(), "%v", c.Differences)
+	require.Equal(t, []string{"column c retyped from optional int64 (INT(64, true)) to optional int32 (INT_32) (compatible)"}, describeDifferences(c))
+
+	c = CompareSchemas(b, a)
+	require.False(t, c.Compatible())
+}
+
+func describeDifferences(c *SchemaComparison) []string {
+	var res []string
+	for _, d := range c.Differences {
+		res = append(res, d.String())
+	}
+	return res
+}
@@ -0,0 +1,93 @@
+package parquetschema
+
+import (
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// LogicalTypeOf returns the logical type of elem. If elem only has a converted type, the
+// equivalent logical type as defined in the parquet documentation is returned, so that columns
+// written by older writers, e.g. annotated as UTF8 or INT_32, can be treated the same as columns
+// annotated as STRING or INT(32, true). nil is returned if elem has no annotation, or if its
+// converted type has no logical equivalent, like INTERVAL or MAP_KEY_VALUE.
+func LogicalTypeOf(elem *parquet.SchemaElement) *parquet.LogicalType {
+	if elem.LogicalType != nil {
+		return elem.LogicalType
+	}
+	if elem.ConvertedType == nil {
+		return nil
+	}
+
+	lt := parquet.NewLogicalType()
+	switch elem.GetConvertedType() {
+	case parquet.ConvertedType_UTF8:
+		lt.STRING = parquet.NewStringType()
+	case parquet.ConvertedType_ENUM:
+		lt.ENUM = parquet.NewEnumType()
+	case parquet.ConvertedType_JSON:
+		lt.JSON = parquet.NewJsonType()
+	case parquet.ConvertedType_BSON:
+		lt.BSON = parquet.NewBsonType()
+	case parquet.ConvertedType_LIST:
+		lt.LIST = parquet.NewListType()
+	case parquet.ConvertedType_MAP:
+		lt.MAP = parquet.NewMapType()
+	case parquet.ConvertedType_DATE:
+		lt.DATE = parquet.NewDateType()
+	case parquet.ConvertedType_DECIMAL:
+		if elem.Precision == nil || elem.Scale == nil {
+			return nil
+		}
+		lt.DECIMAL = &parquet.DecimalType{Precision: elem.GetPrecision(), Scale: elem.GetScale()}
+	case parquet.ConvertedType_TIME_MILLIS:
+		lt.TIME = &parquet.TimeType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{MILLIS: parquet.NewMilliSeconds()}}
+	case parquet.ConvertedType_TIME_MICROS:
+		lt.TIME = &parquet.TimeType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()}}
+	case parquet.ConvertedType_TIMESTAMP_MILLIS:
+		lt.TIMESTAMP = &parquet.TimestampType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{MILLIS: parquet.NewMilliSeconds()}}
+	case parquet.ConvertedType_TIMESTAMP_MICROS:
+		lt.TIMESTAMP = &parquet.TimestampType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()}}
+	case parquet.ConvertedType_INT_8:
+		lt.INTEGER = &parquet.IntType{BitWidth: 8, IsSigned: true}
+	case parquet.ConvertedType_INT_16:
+		lt.INTEGER = &parquet.IntType{BitWidth: 16, IsSigned: true}
+	case parquet.ConvertedType_INT_32:
+		lt.INTEGER = &parquet.IntType{BitWidth: 32, IsSigned: true}
+	case parquet.ConvertedType_INT_64:
+		lt.INTEGER = &parquet.IntType{BitWidth: 64, IsSigned: true}
+	case parquet.ConvertedType_UINT_8:
+		lt.INTEGER = &parquet.IntType{BitWidth: 8, IsSigned: false}
+	case parquet.ConvertedType_UINT_16:
+		lt.INTEGER = &parquet.IntType{BitWidth: 16, IsSigned: false}
+	case parquet.ConvertedType_UINT_32:
+		lt.INTEGER = &parquet.IntType{BitWidth: 32, IsSigned: false}
+	case parquet.ConvertedType_UINT_64:
+		lt.INTEGER = &parquet.IntType{BitWidth: 64, IsSigned: false}
+	default:
+		return nil
+	}
+	return lt
+}
+
+// normalizedAnnotation returns the annotation of elem like getAnnotation, but with converted
+// types replaced by their equivalent logical types, so that equivalent annotations of older and
+// newer writers are returned as the same string.
+func normalizedAnnotation(elem *parquet.SchemaElement) string {
+	switch lt := LogicalTypeOf(elem); {
+	case lt == nil:
+	case lt.IsSetLIST():
+		return "LIST"
+	case lt.IsSetMAP():
+		return "MAP"
+	default:
+		return getSchemaLogicalType(lt)
+	}
+	return getAnnotation(elem)
+}
+
+// IsUTF8String returns true if the values of the column elem are UTF-8 encoded strings, i.e. it is
+// annotated as STRING, as ENUM, whose values are the UTF-8 encoded names of the enum constants, or
+// as JSON, or with the equivalent converted types.
+func IsUTF8String(elem *parquet.SchemaElement) bool {
+	lt := LogicalTypeOf(elem)
+	return lt != nil && (lt.IsSetSTRING() || lt.IsSetENUM() || lt.IsSetJSON())
+}
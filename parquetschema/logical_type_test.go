@@ -0,0 +1,28 @@
+package parquetschema
+
+import (
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsUTF8String(t *testing.T) {
+	for _, tt := range []struct {
+		elem     *parquet.SchemaElement
+		expected bool
+	}{
+		{&parquet.SchemaElement{LogicalType: &parquet.LogicalType{STRING: parquet.NewStringType()}}, true},
+		{&parquet.SchemaElement{LogicalType: &parquet.LogicalType{ENUM: parquet.NewEnumType()}}, true},
+		{&parquet.SchemaElement{LogicalType: &parquet.LogicalType{JSON: parquet.NewJsonType()}}, true},
+		{&parquet.SchemaElement{LogicalType: &parquet.LogicalType{BSON: parquet.NewBsonType()}}, false},
+		{&parquet.SchemaElement{ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)}, true},
+		{&parquet.SchemaElement{ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM)}, true},
+		{&parquet.SchemaElement{ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_JSON)}, true},
+		{&parquet.SchemaElement{ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_BSON)}, false},
+		// UTF8 is the zero value of the converted type, so an unset converted type must not count.
+		{&parquet.SchemaElement{}, false},
+	} {
+		require.Equal(t, tt.expected, IsUTF8String(tt.elem), "%v", tt.elem)
+	}
+}
@@ -37,7 +37,7 @@ func SchemaDefinitionFromColumnDefinition(c *ColumnDefinition) *SchemaDefinition
 //	column-definition ::= <repetition-type> <column-type-definition>
 //	repetition-type ::= 'required' | 'repeated' | 'optional'
 //	column-type-definition ::= <group-definition> | <field-definition>
-//	group-definition ::= 'group' <identifier> <converted-type-annotation>? '{' <message-body> '}'
+//	group-definition ::= 'group' <identifier> <converted-type-annotation>? <field-id-definition>? '{' <message-body> '}'
 //	field-definition ::= <type> <identifier> <logical-type-annotation>? <field-id-definition>? ';'
 //	type ::= 'binary'
 //		| 'float'
@@ -79,7 +79,10 @@ func SchemaDefinitionFromColumnDefinition(c *ColumnDefinition) *SchemaDefinition
 //		| 'JSON'
 //		| 'BSON'
 //		| 'INT' '(' <bit-width> ',' <boolean> ')'
+//		| 'INTEGER' '(' <bit-width> ',' <boolean> ')'
 //		| 'DECIMAL' '(' <precision> ',' <scale> ')'
+//		| 'UNKNOWN'
+//		| 'NULL'
 //	field-id-definition ::= '=' <number>
 //	number ::= <digit>+
 //	digit ::= '0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9'
@@ -91,6 +94,9 @@ func SchemaDefinitionFromColumnDefinition(c *ColumnDefinition) *SchemaDefinition
 //	bit-width ::= '8' | '16' | '32' | '64'
 //	precision := <number>
 //	scale := <number>
+//
+// This is the format that parquet-mr and parquet-tools use to print schemas, so their output can be
+// parsed as well, e.g. to keep schemas in configuration files.
 // For examples of textual schema definitions, please take a look at schema-files/*.schema.
 func ParseSchemaDefinition(schemaText string) (*SchemaDefinition, error) {
 	p := newSchemaParser(schemaText)
@@ -169,8 +175,11 @@ func printCols(w io.Writer, cols []*ColumnDefinition, indent int) {
 
 		if elem.Type == nil {
 			fmt.Fprintf(w, "group %s", elem.GetName())
-			if elem.ConvertedType != nil {
-				fmt.Fprintf(w, " (%s)", elem.GetConvertedType().String())
+			if annotation := getAnnotation(elem); annotation != "" {
+				fmt.Fprintf(w, " (%s)", annotation)
+			}
+			if elem.FieldID != nil {
+				fmt.Fprintf(w, " = %d", elem.GetFieldID())
 			}
 			fmt.Fprintf(w, " {\n")
 			printCols(w, col.Children, indent+2)
@@ -180,10 +189,8 @@ func printCols(w io.Writer, cols []*ColumnDefinition, indent int) {
 		} else {
 			typ := getSchemaType(elem)
 			fmt.Fprintf(w, "%s %s", typ, elem.GetName())
-			if elem.LogicalType != nil {
-				fmt.Fprintf(w, " (%s)", getSchemaLogicalType(elem.GetLogicalType()))
-			} else if elem.ConvertedType != nil {
-				fmt.Fprintf(w, " (%s)", elem.GetConvertedType().String())
+			if annotation := getAnnotation(elem); annotation != "" {
+				fmt.Fprintf(w, " (%s)", annotation)
 			}
 			if elem.FieldID != nil {
 				fmt.Fprintf(w, " = %d", elem.GetFieldID())
@@ -193,6 +200,31 @@ func printCols(w io.Writer, cols []*ColumnDefinition, indent int) {
 	}
 }
 
+// getAnnotation returns the logical or converted type of elem as it is written in the schema
+// definition, or an empty string if it has none.
+func getAnnotation(elem *parquet.SchemaElement) string {
+	if elem.Type == nil {
+		if elem.ConvertedType != nil {
+			return elem.GetConvertedType().String()
+		} else if lt := elem.LogicalType; lt != nil && lt.IsSetLIST() {
+			return "LIST"
+		} else if lt != nil && lt.IsSetMAP() {
+			return "MAP"
+		}
+		return ""
+	}
+
+	if elem.LogicalType != nil {
+		return getSchemaLogicalType(elem.GetLogicalType())
+	} else if elem.GetConvertedType() == parquet.ConvertedType_DECIMAL && elem.Precision != nil && elem.Scale != nil {
+		// the precision and scale of legacy decimals are stored in the schema element.
+		return fmt.Sprintf("DECIMAL(%d, %d)", elem.GetPrecision(), elem.GetScale())
+	} else if elem.ConvertedType != nil {
+		return elem.GetConvertedType().String()
+	}
+	return ""
+}
+
 func printIndent(w io.Writer, indent int) {
 	for i := 0; i < indent; i++ {
 		fmt.Fprintf(w, " ")
@@ -273,6 +305,8 @@ func getSchemaLogicalType(t *parquet.LogicalType) string {
 		return fmt.Sprintf("DECIMAL(%d, %d)", t.DECIMAL.Precision, t.DECIMAL.Scale)
 	case t.IsSetINTEGER():
 		return fmt.Sprintf("INT(%d, %t)", t.INTEGER.BitWidth, t.INTEGER.IsSigned)
+	case t.IsSetUNKNOWN():
+		return "UNKNOWN"
 	default:
 		return "BUG(UNKNOWN)"
 	}
@@ -30,6 +30,51 @@ func TestSchemaDefinition(t *testing.T) {
 	}
 }
 
+func TestParseParquetMRSchema(t *testing.T) {
+	// the output of parquet-tools schema for a file written by Spark.
+	sd, err := ParseSchemaDefinition(`message spark_schema {
+  required int64 id = 1;
+  optional int32 small (INTEGER(8,true)) = 2;
+  optional int32 count (INTEGER(32,false));
+  optional int64 ts (TIMESTAMP(MICROS,true));
+  optional fixed_len_byte_array(5) amount (DECIMAL(10,2));
+  optional group tags (LIST) = 3 {
+    repeated group list {
+      optional binary element (STRING);
+    }
+  }
+  optional group attrs (MAP) {
+    repeated group key_value {
+      required binary key (UTF8);
+      optional binary value (UTF8);
+    }
+  }
+}
+`)
+	require.NoError(t, err)
+
+	require.Equal(t, `message spark_schema {
+  required int64 id = 1;
+  optional int32 small (INT(8, true)) = 2;
+  optional int32 count (INT(32, false));
+  optional int64 ts (TIMESTAMP(MICROS, true));
+  optional fixed_len_byte_array(5) amount (DECIMAL(10, 2));
+  optional group tags (LIST) = 3 {
+    repeated group list {
+      optional binary element (STRING);
+    }
+  }
+  optional group attrs (MAP) {
+    repeated group key_value {
+      required binary key (UTF8);
+      optional binary value (UTF8);
+    }
+  }
+}
+`, sd.String())
+	require.Equal(t, int32(3), sd.SubSchema("tags").SchemaElement().GetFieldID())
+}
+
 func TestNilSchemaDef(t *testing.T) {
 	var sd *SchemaDefinition
 
@@ -123,3 +168,64 @@ func TestSubSchema(t *testing.T) {
 
 	require.Nil(t, schemaDef.SubSchema("does-not-exist"))
 }
+
+func TestSchemaDefinitionStringAnnotations(t *testing.T) {
+	listType := parquet.NewLogicalType()
+	listType.LIST = parquet.NewListType()
+
+	var precision, scale int32 = 10, 2
+
+	sd := SchemaDefinitionFromColumnDefinition(&ColumnDefinition{
+		SchemaElement: &parquet.SchemaElement{Name: "foo"},
+		Children: []*ColumnDefinition{
+			{
+				SchemaElement: &parquet.SchemaElement{
+					Name:           "amount",
+					Type:           parquet.TypePtr(parquet.Type_INT64),
+					RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+					ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL),
+					Precision:      &precision,
+					Scale:          &scale,
+				},
+			},
+			{
+				SchemaElement: &parquet.SchemaElement{
+					Name:           "ids",
+					RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+					LogicalType:    listType,
+				},
+				Children: []*ColumnDefinition{
+					{
+						SchemaElement: &parquet.SchemaElement{
+							Name:           "list",
+							RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+						},
+						Children: []*ColumnDefinition{
+							{
+								SchemaElement: &parquet.SchemaElement{
+									Name:           "element",
+									Type:           parquet.TypePtr(parquet.Type_INT64),
+									RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	schemaText := `message foo {
+  required int64 amount (DECIMAL(10, 2));
+  optional group ids (LIST) {
+    repeated group list {
+      required int64 element;
+    }
+  }
+}
+`
+	require.Equal(t, schemaText, sd.String())
+
+	_, err := ParseSchemaDefinition(sd.String())
+	require.NoError(t, err)
+}
@@ -394,6 +394,11 @@ func (p *schemaParser) parseColumnDefinition() *ColumnDefinition {
 			p.next()
 		}
 
+		if p.token.typ == itemEqual {
+			col.SchemaElement.FieldID = p.parseFieldID()
+			p.next()
+		}
+
 		col.Children = p.parseMessageBody()
 
 		p.expect(itemRightBrace)
@@ -501,7 +506,7 @@ func (p *schemaParser) parseLogicalOrConvertedType() (*parquet.LogicalType, *par
 		ct = p.parseTimestampLogicalType(lt)
 	case "TIME":
 		ct = p.parseTimeLogicalType(lt)
-	case "INT":
+	case "INT", "INTEGER": // INTEGER is the name used by parquet-mr.
 		ct = p.parseIntLogicalType(lt)
 	case "UUID":
 		lt.UUID = parquet.NewUUIDType()
@@ -516,6 +521,8 @@ func (p *schemaParser) parseLogicalOrConvertedType() (*parquet.LogicalType, *par
 		ct = parquet.ConvertedTypePtr(parquet.ConvertedType_BSON)
 	case "DECIMAL":
 		p.parseDecimalLogicalType(lt)
+	case "UNKNOWN", "NULL":
+		lt.UNKNOWN = parquet.NewNullType()
 	default:
 		convertedType, err := parquet.ConvertedTypeFromString(strings.ToUpper(typStr))
 		if err != nil {
@@ -974,6 +981,10 @@ func (col *ColumnDefinition) validate(isRoot bool, strictMode bool) error {
 		if col.SchemaElement.GetType() != parquet.Type_FIXED_LEN_BYTE_ARRAY || col.SchemaElement.GetTypeLength() != 16 {
 			return fmt.Errorf("field %s is annotated as UUID but is not a fixed_len_byte_array(16)", col.SchemaElement.Name)
 		}
+	case col.SchemaElement.LogicalType != nil && col.SchemaElement.GetLogicalType().IsSetUNKNOWN():
+		if col.SchemaElement.GetRepetitionType() == parquet.FieldRepetitionType_REQUIRED {
+			return fmt.Errorf("field %s is annotated as UNKNOWN but is required", col.SchemaElement.Name)
+		}
 	case col.SchemaElement.LogicalType != nil && col.SchemaElement.GetLogicalType().IsSetENUM():
 		if col.SchemaElement.GetType() != parquet.Type_BYTE_ARRAY {
 			return fmt.Errorf("field %s is annotated as ENUM but is not a binary", col.SchemaElement.Name)
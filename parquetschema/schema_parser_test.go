@@ -486,6 +486,8 @@ message foo { }`, false, false}, // this is necessary because we once had a pars
 
 			}
 		}`, false, true}, // invalid ConvertedType
+		{`message foo { optional int32 bar (UNKNOWN); optional binary baz (NULL); }`, false, false},
+		{`message foo { required int32 bar (UNKNOWN); }`, true, false}, // always null column is required.
 	}
 
 	for idx, tt := range testData {
@@ -0,0 +1,326 @@
+package goparquet
+
+import (
+	"container/list"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PartitionFunc returns the partition of the record m. Records of the same partition are
+// written to the same file until the file is rolled over.
+type PartitionFunc func(m map[string]interface{}) (string, error)
+
+// FileCreator creates the file with the name name.
+type FileCreator func(name string) (io.WriteCloser, error)
+
+// PartitionedWriterOption describes an option function that is applied to a PartitionedWriter
+// when it is created.
+type PartitionedWriterOption func(pw *PartitionedWriter)
+
+// WithFileWriterOptions sets the options of the FileWriters that write the files of the
+// partitions. A limit callback set using WithLimitCallback is replaced by the
+// PartitionedWriter, which rolls over to a new file whenever a limit of a file is hit.
+func WithFileWriterOptions(opts ...FileWriterOption) PartitionedWriterOption {
+	return func(pw *PartitionedWriter) {
+		pw.fileOpts = append(pw.fileOpts, opts...)
+	}
+}
+
+// WithMaxOpenFiles sets the maximum number of files that are open at the same time. Before a
+// file for another partition is opened, the least recently used file is closed. Records that
+// are added to its partition later on are written to a new file. A max of 0, which is the
+// default, doesn't limit the number of open files.
+func WithMaxOpenFiles(max int) PartitionedWriterOption {
+	return func(pw *PartitionedWriter) {
+		pw.maxOpen = max
+	}
+}
+
+// DefaultFileNameTemplate is the template of the file names of a PartitionedWriter unless set
+// otherwise using WithFileNameTemplate. The files of the partition p are named
+// p/part-00000.parquet, p/part-00001.parquet and so on, or part-00000.parquet and so on for the
+// partition "".
+const DefaultFileNameTemplate = `{{with .Partition}}{{.}}/{{end}}part-{{printf "%05d" .Sequence}}.parquet`
+
+// The keys of the key-value meta data in which the PartitionedWriter stores the values of the
+// file name template of every file, so that the origin of a file can be traced without relying
+// on its name.
+const (
+	FilePartitionKey = "parquet-go.file.partition"
+	FileSequenceKey  = "parquet-go.file.sequence"
+	FileTimestampKey = "parquet-go.file.timestamp"
+	FileWriterIDKey  = "parquet-go.file.writer_id"
+)
+
+// FileNameValues contains the values that are passed to the file name template of a
+// PartitionedWriter.
+type FileNameValues struct {
+	// Partition is the partition of the file.
+	Partition string
+	// Sequence is the number of the file within its partition, starting at 0.
+	Sequence int
+	// Time is the time at which the file was opened. It's stored in the meta data in the
+	// RFC 3339 format with nanoseconds.
+	Time time.Time
+	// WriterID is the ID set using WithWriterID.
+	WriterID string
+}
+
+// WithFileNameTemplate sets the text/template that is executed with the FileNameValues of a file
+// to get its name, e.g. `{{.Partition}}/{{.WriterID}}-{{.Time.Unix}}-{{.Sequence}}.parquet`. The
+// template has to produce a different name for every file, so it usually contains the
+// partition and the sequence number.
+func WithFileNameTemplate(tmpl string) PartitionedWriterOption {
+	return func(pw *PartitionedWriter) {
+		pw.nameTemplate = tmpl
+	}
+}
+
+// WithWriterID sets the ID of the writer that is passed to the file name template, e.g. to
+// distinguish the files of multiple processes that write to the same partitions.
+func WithWriterID(id string) PartitionedWriterOption {
+	return func(pw *PartitionedWriter) {
+		pw.writerID = id
+	}
+}
+
+// PartitionedWriter writes records to one file per partition. A file is closed and the next
+// file of the partition is created whenever a hard limit of the file, e.g. set using
+// WithMaxFileSize or WithMaxRowGroups, is hit. Without a PartitionFunc, all records are
+// written to the same partition, so that the writer only rolls over files.
+//
+// The files are named using a template, see WithFileNameTemplate, and the values of the template
+// are stored in the key-value meta data of every file. A PartitionedWriter is not safe for
+// concurrent use.
+type PartitionedWriter struct {
+	create    FileCreator
+	partition PartitionFunc
+	fileOpts  []FileWriterOption
+	maxOpen   int
+
+	nameTemplate string
+	name         *template.Template
+	writerID     string
+	now          func() time.Time
+
+	open map[string]*list.Element
+	// lru contains the open files, the most recently used one first.
+	lru *list.List
+	// sequences contains the sequence number of the next file of every partition.
+	sequences map[string]int
+	// names contains the names of all files that were opened.
+	names map[string]bool
+	files []string
+}
+
+type partitionFile struct {
+	partition string
+	seq       int
+	name      string
+	fw        *FileWriter
+	w         *lazyFile
+}
+
+// errRollover is returned by the limit callback of the files of a PartitionedWriter.
+var errRollover = errors.New("roll over to the next file")
+
+// NewPartitionedWriter creates a new PartitionedWriter that creates its files using create and
+// assigns the records to partitions using partition.
+func NewPartitionedWriter(create FileCreator, partition PartitionFunc, opts ...PartitionedWriterOption) (*PartitionedWriter, error) {
+	if create == nil {
+		return nil, errors.New("no file creator")
+	}
+
+	pw := &PartitionedWriter{
+		create:    create,
+		partition: partition,
+		open:      make(map[string]*list.Element),
+		lru:       list.New(),
+		sequences: make(map[string]int),
+		names:     make(map[string]bool),
+
+		nameTemplate: DefaultFileNameTemplate,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(pw)
+	}
+
+	if pw.maxOpen < 0 {
+		return nil, errors.Errorf("invalid maximum number of open files %d", pw.maxOpen)
+	}
+
+	var err error
+	if pw.name, err = template.New("file name").Parse(pw.nameTemplate); err != nil {
+		return nil, errors.Wrap(err, "invalid file name template")
+	}
+
+	return pw, nil
+}
+
+// AddData adds the record m to the file of its partition. If the file hits one of its limits,
+// it's closed and m is added to the next file of the partition.
+func (pw *PartitionedWriter) AddData(m map[string]interface{}) error {
+	partition := ""
+	if pw.partition != nil {
+		var err error
+		if partition, err = pw.partition(m); err != nil {
+			return err
+		}
+	}
+
+	f, err := pw.file(partition)
+	if err != nil {
+		return err
+	}
+
+	err = f.fw.AddData(m)
+	if err == errRollover {
+		if err := pw.closeFile(partition); err != nil {
+			return err
+		}
+		if f, err = pw.file(partition); err != nil {
+			return err
+		}
+		err = f.fw.AddData(m)
+	}
+	if err == errRollover {
+		return errors.Errorf("partition %q: record exceeds the limits of an empty file", partition)
+	}
+	return err
+}
+
+// file returns the open file of the partition, and opens the next file of the partition if
+// there is none.
+func (pw *PartitionedWriter) file(partition string) (*partitionFile, error) {
+	if e, ok := pw.open[partition]; ok {
+		pw.lru.MoveToFront(e)
+		return e.Value.(*partitionFile), nil
+	}
+
+	if pw.maxOpen > 0 && pw.lru.Len() >= pw.maxOpen {
+		if err := pw.closeFile(pw.lru.Back().Value.(*partitionFile).partition); err != nil {
+			return nil, err
+		}
+	}
+
+	values := FileNameValues{
+		Partition: partition,
+		Sequence:  pw.sequences[partition],
+		Time:      pw.now(),
+		WriterID:  pw.writerID,
+	}
+	var sb strings.Builder
+	if err := pw.name.Execute(&sb, values); err != nil {
+		return nil, errors.Wrapf(err, "partition %q: executing the file name template failed", partition)
+	}
+	name := sb.String()
+	if name == "" {
+		return nil, errors.Errorf("partition %q: the file name template produced an empty name", partition)
+	}
+	if pw.names[name] {
+		return nil, errors.Errorf("partition %q: the file name template produced the name %s again", partition, name)
+	}
+
+	f := &partitionFile{
+		partition: partition,
+		seq:       values.Sequence,
+		name:      name,
+		w:         &lazyFile{name: name, create: pw.create},
+	}
+	opts := append(append([]FileWriterOption{}, pw.fileOpts...), WithLimitCallback(func(WriterLimit) error {
+		return errRollover
+	}))
+	f.fw = NewFileWriter(f.w, opts...)
+	f.fw.SetMetaData(FilePartitionKey, values.Partition)
+	f.fw.SetMetaData(FileSequenceKey, strconv.Itoa(values.Sequence))
+	f.fw.SetMetaData(FileTimestampKey, values.Time.Format(time.RFC3339Nano))
+	f.fw.SetMetaData(FileWriterIDKey, values.WriterID)
+
+	pw.names[name] = true
+	pw.sequences[partition] = values.Sequence + 1
+	pw.open[partition] = pw.lru.PushFront(f)
+	return f, nil
+}
+
+// closeFile closes the open file of the partition. A file without records is not created, and
+// its sequence number and name can be used for the next file of the partition.
+func (pw *PartitionedWriter) closeFile(partition string) error {
+	e, ok := pw.open[partition]
+	if !ok {
+		return nil
+	}
+	pw.lru.Remove(e)
+	delete(pw.open, partition)
+
+	f := e.Value.(*partitionFile)
+	if len(f.fw.rowGroups) == 0 && f.fw.rowGroupNumRecords() == 0 {
+		pw.sequences[partition] = f.seq
+		delete(pw.names, f.name)
+		return nil
+	}
+
+	if err := f.fw.Close(); err != nil {
+		_ = f.w.Close()
+		return errors.Wrapf(err, "closing %s failed", f.name)
+	}
+	if err := f.w.Close(); err != nil {
+		return errors.Wrapf(err, "closing %s failed", f.name)
+	}
+	pw.files = append(pw.files, f.name)
+	return nil
+}
+
+// OpenFiles returns the number of files that are currently open.
+func (pw *PartitionedWriter) OpenFiles() int {
+	return pw.lru.Len()
+}
+
+// Files returns the names of the files that were written and closed so far, in the order in
+// which they were closed.
+func (pw *PartitionedWriter) Files() []string {
+	return append([]string(nil), pw.files...)
+}
+
+// Close closes all open files. It returns the first error, but tries to close all files.
+func (pw *PartitionedWriter) Close() error {
+	var firstErr error
+	for pw.lru.Len() > 0 {
+		if err := pw.closeFile(pw.lru.Back().Value.(*partitionFile).partition); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// lazyFile creates the file on the first write, so that files that are never written to are
+// not created at all.
+type lazyFile struct {
+	name   string
+	create FileCreator
+	w      io.WriteCloser
+}
+
+func (f *lazyFile) Write(p []byte) (int, error) {
+	if f.w == nil {
+		w, err := f.create(f.name)
+		if err != nil {
+			return 0, errors.Wrapf(err, "creating %s failed", f.name)
+		}
+		f.w = w
+	}
+	return f.w.Write(p)
+}
+
+func (f *lazyFile) Close() error {
+	if f.w == nil {
+		return nil
+	}
+	return f.w.Close()
+}
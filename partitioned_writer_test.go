@@ -0,0 +1,166 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+type testFile struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (f *testFile) Close() error {
+	f.closed = true
+	return nil
+}
+
+type testFiles map[string]*testFile
+
+func (m testFiles) create(name string) (io.WriteCloser, error) {
+	f := &testFile{}
+	m[name] = f
+	return f, nil
+}
+
+func (m testFiles) readIDs(t *testing.T, name string) []int64 {
+	f, ok := m[name]
+	require.True(t, ok, name)
+	require.True(t, f.closed, name)
+
+	r, err := NewFileReader(bytes.NewReader(f.Bytes()))
+	require.NoError(t, err)
+
+	var ids []int64
+	for {
+		row, err := r.NextRow()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		ids = append(ids, row["id"].(int64))
+	}
+	return ids
+}
+
+func partitionBy(m map[string]interface{}) (string, error) {
+	return string(m["p"].([]byte)), nil
+}
+
+func TestPartitionedWriterMaxOpenFiles(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required binary p (STRING);
+	}`)
+	require.NoError(t, err)
+
+	files := testFiles{}
+	pw, err := NewPartitionedWriter(files.create, partitionBy,
+		WithMaxOpenFiles(2),
+		WithFileWriterOptions(WithSchemaDefinition(sd)),
+	)
+	require.NoError(t, err)
+
+	for i, p := range []string{"a", "b", "a", "c", "b", "a"} {
+		require.NoError(t, pw.AddData(map[string]interface{}{"id": int64(i), "p": []byte(p)}))
+		require.LessOrEqual(t, pw.OpenFiles(), 2)
+	}
+	// a record that fails doesn't create a file.
+	require.Error(t, pw.AddData(map[string]interface{}{"id": "x", "p": []byte("d")}))
+	require.NoError(t, pw.Close())
+	require.Zero(t, pw.OpenFiles())
+
+	// c evicted b, b evicted a.
+	require.Equal(t, []string{"b/part-00000.parquet", "a/part-00000.parquet", "c/part-00000.parquet", "b/part-00001.parquet", "a/part-00001.parquet"}, pw.Files())
+	require.Len(t, files, 5)
+	require.Equal(t, []int64{0, 2}, files.readIDs(t, "a/part-00000.parquet"))
+	require.Equal(t, []int64{5}, files.readIDs(t, "a/part-00001.parquet"))
+	require.Equal(t, []int64{1}, files.readIDs(t, "b/part-00000.parquet"))
+	require.Equal(t, []int64{4}, files.readIDs(t, "b/part-00001.parquet"))
+	require.Equal(t, []int64{3}, files.readIDs(t, "c/part-00000.parquet"))
+
+	_, err = NewPartitionedWriter(files.create, nil, WithMaxOpenFiles(-1))
+	require.Error(t, err)
+	_, err = NewPartitionedWriter(nil, nil)
+	require.Error(t, err)
+}
+
+func TestPartitionedWriterRollover(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	files := testFiles{}
+	pw, err := NewPartitionedWriter(files.create, nil, WithFileWriterOptions(
+		WithSchemaDefinition(sd),
+		WithMaxRowGroupSize(1),
+		WithMaxRowGroups(2),
+	))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, pw.AddData(map[string]interface{}{"id": int64(i)}))
+	}
+	require.NoError(t, pw.Close())
+
+	require.Equal(t, []string{"part-00000.parquet", "part-00001.parquet", "part-00002.parquet"}, pw.Files())
+	require.Equal(t, []int64{0, 1}, files.readIDs(t, "part-00000.parquet"))
+	require.Equal(t, []int64{2, 3}, files.readIDs(t, "part-00001.parquet"))
+	require.Equal(t, []int64{4}, files.readIDs(t, "part-00002.parquet"))
+}
+
+func TestPartitionedWriterFileNameTemplate(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required binary p (STRING);
+	}`)
+	require.NoError(t, err)
+
+	now := time.Date(2020, 9, 13, 14, 26, 40, 123000000, time.UTC)
+
+	files := testFiles{}
+	pw, err := NewPartitionedWriter(files.create, partitionBy,
+		WithFileNameTemplate(`{{.Partition}}/{{.WriterID}}-{{.Time.Unix}}-{{.Sequence}}.parquet`),
+		WithWriterID("w1"),
+		WithFileWriterOptions(WithSchemaDefinition(sd), WithMaxRowGroupSize(1), WithMaxRowGroups(1)),
+	)
+	require.NoError(t, err)
+	pw.now = func() time.Time { return now }
+
+	require.NoError(t, pw.AddData(map[string]interface{}{"id": int64(0), "p": []byte("a")}))
+	now = now.Add(time.Second)
+	require.NoError(t, pw.AddData(map[string]interface{}{"id": int64(1), "p": []byte("a")}))
+	require.NoError(t, pw.Close())
+
+	require.Equal(t, []string{"a/w1-1600007200-0.parquet", "a/w1-1600007201-1.parquet"}, pw.Files())
+	require.Equal(t, []int64{1}, files.readIDs(t, "a/w1-1600007201-1.parquet"))
+
+	r, err := NewFileReader(bytes.NewReader(files["a/w1-1600007201-1.parquet"].Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		FilePartitionKey: "a",
+		FileSequenceKey:  "1",
+		FileTimestampKey: "2020-09-13T14:26:41.123Z",
+		FileWriterIDKey:  "w1",
+	}, r.MetaData())
+
+	// the template has to produce a new name for every file.
+	pw, err = NewPartitionedWriter(testFiles{}.create, nil,
+		WithFileNameTemplate(`data.parquet`),
+		WithFileWriterOptions(WithSchemaDefinition(sd), WithMaxRowGroupSize(1), WithMaxRowGroups(1)),
+	)
+	require.NoError(t, err)
+	require.NoError(t, pw.AddData(map[string]interface{}{"id": int64(0), "p": []byte("a")}))
+	err = pw.AddData(map[string]interface{}{"id": int64(1), "p": []byte("a")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "produced the name data.parquet again")
+
+	_, err = NewPartitionedWriter(testFiles{}.create, nil, WithFileNameTemplate(`{{.Partition`))
+	require.Error(t, err)
+}
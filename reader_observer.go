@@ -0,0 +1,67 @@
+package goparquet
+
+import (
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// ReaderObserver is notified about the data that is read by a FileReader, e.g. to collect metrics
+// about the files that are read.
+type ReaderObserver interface {
+	// ChunkRead is called after the pages of a column chunk were read while reading the rows of
+	// a row group. It's not called for column chunks that aren't selected.
+	ChunkRead(chunk *ChunkEncodings)
+}
+
+// ChunkEncodings describes the encodings that were actually encountered in the data pages of a
+// column chunk, which can differ from the encodings that are listed in its meta data.
+type ChunkEncodings struct {
+	// RowGroup is the index of the row group of the column chunk.
+	RowGroup int
+	// Column is the flat name of the column.
+	Column string
+	// MetaData contains the encodings that are listed in the meta data of the column chunk.
+	MetaData []parquet.Encoding
+	// Pages contains the number of data pages per encoding of their values.
+	Pages map[parquet.Encoding]int
+	// Values contains the number of values, including null values, per encoding of the data
+	// pages that contain them.
+	Values map[parquet.Encoding]int64
+}
+
+// DictionaryFallback returns true if some but not all data pages of the column chunk are
+// dictionary encoded, which happens when the writer falls back to another encoding because the
+// dictionary grew too large.
+func (c *ChunkEncodings) DictionaryFallback() bool {
+	dict, total := 0, 0
+	for enc, n := range c.Pages {
+		if enc == parquet.Encoding_RLE_DICTIONARY || enc == parquet.Encoding_PLAIN_DICTIONARY {
+			dict += n
+		}
+		total += n
+	}
+	return dict > 0 && dict < total
+}
+
+// WithReaderObserver sets the observer that is notified about the data that is read.
+func WithReaderObserver(observer ReaderObserver) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.observer = observer
+	}
+}
+
+// chunkEncodings collects the encodings of the data pages of a column chunk.
+func chunkEncodings(rowGroup int, col *Column, meta *parquet.ColumnMetaData, pages []pageReader) *ChunkEncodings {
+	res := &ChunkEncodings{
+		RowGroup: rowGroup,
+		Column:   col.FlatName(),
+		MetaData: meta.Encodings,
+		Pages:    make(map[parquet.Encoding]int),
+		Values:   make(map[parquet.Encoding]int64),
+	}
+	for _, p := range pages {
+		enc := p.valuesEncoding()
+		res.Pages[enc]++
+		res.Values[enc] += int64(p.numValues())
+	}
+	return res
+}
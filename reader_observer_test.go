@@ -0,0 +1,79 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+type testObserver struct {
+	chunks []*ChunkEncodings
+}
+
+func (o *testObserver) ChunkRead(chunk *ChunkEncodings) {
+	o.chunks = append(o.chunks, chunk)
+}
+
+func TestReaderObserver(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		required binary name (STRING);
+	}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w := NewFileWriter(&buf, WithSchemaDefinition(sd),
+		WithPageValueLimits(1, 100),
+		WithMaxDictionarySize(100),
+		WithEncodingForColumn("id", parquet.Encoding_DELTA_BINARY_PACKED),
+	)
+	for i := 0; i < 1000; i++ {
+		// the name column falls back to PLAIN encoding once the distinct values start.
+		name := fmt.Sprintf("v%d", i%10)
+		if i >= 500 {
+			name = fmt.Sprintf("x%04d", i)
+		}
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i), "name": []byte(name)}))
+	}
+	require.NoError(t, w.Close())
+
+	observer := &testObserver{}
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithReaderObserver(observer))
+	require.NoError(t, err)
+	for {
+		if _, err := r.NextRow(); err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	require.Len(t, observer.chunks, 2)
+
+	id := observer.chunks[0]
+	require.Equal(t, 0, id.RowGroup)
+	require.Equal(t, "id", id.Column)
+	require.Equal(t, map[parquet.Encoding]int{parquet.Encoding_DELTA_BINARY_PACKED: 10}, id.Pages)
+	require.Equal(t, map[parquet.Encoding]int64{parquet.Encoding_DELTA_BINARY_PACKED: 1000}, id.Values)
+	require.False(t, id.DictionaryFallback())
+
+	name := observer.chunks[1]
+	require.Equal(t, "name", name.Column)
+	require.Equal(t, map[parquet.Encoding]int{parquet.Encoding_RLE_DICTIONARY: 5, parquet.Encoding_PLAIN: 5}, name.Pages)
+	require.Equal(t, map[parquet.Encoding]int64{parquet.Encoding_RLE_DICTIONARY: 500, parquet.Encoding_PLAIN: 500}, name.Values)
+	require.Contains(t, name.MetaData, parquet.Encoding_RLE_DICTIONARY)
+	require.True(t, name.DictionaryFallback())
+
+	// column chunks that aren't selected aren't reported.
+	observer = &testObserver{}
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithReaderObserver(observer), WithColumns("name"))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.NoError(t, err)
+	require.Len(t, observer.chunks, 1)
+	require.Equal(t, "name", observer.chunks[0].Column)
+}
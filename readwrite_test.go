@@ -2,9 +2,14 @@ package goparquet
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -460,6 +465,44 @@ func TestWriteThenReadFileNested3(t *testing.T) {
 	}
 }
 
+func TestWriteThenReadListWithNullElements(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional group ids (LIST) {
+			repeated group list {
+				optional int64 element;
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	list := func(elements ...map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"ids": map[string]interface{}{"list": elements}}
+	}
+	data := []map[string]interface{}{
+		list(map[string]interface{}{"element": int64(1)}, map[string]interface{}{}, map[string]interface{}{"element": int64(3)}),
+		list(map[string]interface{}{}, map[string]interface{}{}),
+		{},
+		list(map[string]interface{}{}, map[string]interface{}{"element": int64(4)}),
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, row := range data {
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for _, row := range data {
+		d, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, row, d)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
 func TestWriteEmptyDict(t *testing.T) {
 	_ = os.Mkdir("files", 0755)
 
@@ -866,3 +909,638 @@ func TestReadWriteColumeEncodings(t *testing.T) {
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestWriteEncodedRowGroup(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+		required int32 bar;
+	}`)
+	require.NoError(t, err)
+
+	plainPage := func(numValues int32, data []byte) *EncodedPage {
+		return &EncodedPage{
+			Header: &parquet.PageHeader{
+				Type:                 parquet.PageType_DATA_PAGE,
+				UncompressedPageSize: int32(len(data)),
+				CompressedPageSize:   int32(len(data)),
+				DataPageHeader: &parquet.DataPageHeader{
+					NumValues:               numValues,
+					Encoding:                parquet.Encoding_PLAIN,
+					DefinitionLevelEncoding: parquet.Encoding_RLE,
+					RepetitionLevelEncoding: parquet.Encoding_RLE,
+				},
+			},
+			Data: data,
+		}
+	}
+
+	foo1, foo2, bar := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	require.NoError(t, binary.Write(foo1, binary.LittleEndian, []int64{1, 2}))
+	require.NoError(t, binary.Write(foo2, binary.LittleEndian, []int64{3}))
+	require.NoError(t, binary.Write(bar, binary.LittleEndian, []int32{10, 20, 30}))
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+
+	require.Error(t, w.WriteEncodedRowGroup(3, []*EncodedColumnChunk{
+		{Column: "foo", Pages: []*EncodedPage{plainPage(3, bar.Bytes())}},
+	}), "missing column chunk should fail")
+
+	require.Error(t, w.WriteEncodedRowGroup(3, []*EncodedColumnChunk{
+		{Column: "foo", Pages: []*EncodedPage{plainPage(3, bar.Bytes())}}, nil,
+	}), "nil column chunk should fail")
+
+	// the chunk of foo is valid, but nothing is written because the chunk of bar isn't.
+	fooChunk := &EncodedColumnChunk{Column: "foo", Pages: []*EncodedPage{plainPage(2, foo1.Bytes()), plainPage(1, foo2.Bytes())}}
+	require.Error(t, w.WriteEncodedRowGroup(3, []*EncodedColumnChunk{
+		fooChunk, {Column: "bar", Pages: []*EncodedPage{plainPage(2, bar.Bytes()[:8])}},
+	}), "number of values that doesn't match the number of rows should fail")
+	require.Error(t, w.WriteEncodedRowGroup(3, []*EncodedColumnChunk{
+		fooChunk, {Column: "bar", Codec: parquet.CompressionCodec(42), Pages: []*EncodedPage{plainPage(3, bar.Bytes())}},
+	}), "invalid codec should fail")
+	require.Error(t, w.WriteEncodedRowGroup(3, []*EncodedColumnChunk{
+		fooChunk, {Column: "bar", Pages: []*EncodedPage{{Header: plainPage(3, bar.Bytes()).Header}}},
+	}), "page size that doesn't match the data should fail")
+	require.Zero(t, buf.Len())
+
+	require.NoError(t, w.WriteEncodedRowGroup(3, []*EncodedColumnChunk{
+		{Column: "bar", Pages: []*EncodedPage{plainPage(3, bar.Bytes())}},
+		{Column: "foo", Pages: []*EncodedPage{plainPage(2, foo1.Bytes()), plainPage(1, foo2.Bytes())}},
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), r.NumRows())
+
+	for i := 0; i < 3; i++ {
+		data, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"foo": int64(i + 1), "bar": int32(10 * (i + 1))}, data)
+	}
+
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestColumnOrders(t *testing.T) {
+	columnOrders := func(schema string, options ...FileWriterOption) []*parquet.ColumnOrder {
+		sd, err := parquetschema.ParseSchemaDefinition(schema)
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithSchemaDefinition(sd)}, options...)...)
+		require.NoError(t, w.AddData(map[string]interface{}{}))
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		return r.meta.ColumnOrders
+	}
+
+	typeDefined := &parquet.ColumnOrder{TYPE_ORDER: parquet.NewTypeDefinedOrder()}
+	require.Equal(t, []*parquet.ColumnOrder{typeDefined, typeDefined, typeDefined}, columnOrders(`message test {
+		optional int64 id;
+		optional binary name (STRING);
+		optional fixed_len_byte_array(4) amount (DECIMAL(8, 2));
+	}`))
+
+	// the order of INT96 is undefined.
+	require.Nil(t, columnOrders(`message test {
+		optional int64 id;
+		optional int96 ts;
+	}`))
+
+	// the statistics of binary columns aren't in the type defined order with other comparators.
+	schema := `message test {
+		optional int64 id;
+		optional binary name (STRING);
+		optional fixed_len_byte_array(4) amount (DECIMAL(8, 2));
+	}`
+	require.Nil(t, columnOrders(schema, WithStatisticsBinaryComparator(BinaryComparatorSigned)))
+	require.Nil(t, columnOrders(schema, WithColumnStatisticsBinaryComparator("name", BinaryComparatorSigned)))
+	require.Len(t, columnOrders(schema, WithColumnStatisticsBinaryComparator("amount", BinaryComparatorSigned)), 3)
+}
+
+func TestWriterLimits(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMaxRowGroups(2))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"foo": int64(i)}))
+		require.NoError(t, w.FlushRowGroup())
+	}
+	err = w.AddData(map[string]interface{}{"foo": int64(2)})
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrLimitExceeded))
+	require.Error(t, w.WriteColumnBatch("foo", []int64{2}, nil, nil))
+
+	// the file can be closed after the limit was hit.
+	require.NoError(t, w.Close())
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 2, r.RowGroupCount())
+	require.Equal(t, int64(2), r.NumRows())
+
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithMaxRowGroups(1))
+	require.NoError(t, w.AddData(map[string]interface{}{"foo": int64(0)}))
+	require.NoError(t, w.AddData(map[string]interface{}{"foo": int64(1)}))
+	require.NoError(t, w.Close())
+
+	var hits []WriterLimit
+	errRotate := errors.New("rotate")
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithMaxFileSize(50), WithLimitCallback(func(limit WriterLimit) error {
+		hits = append(hits, limit)
+		if len(hits) > 1 {
+			return errRotate
+		}
+		return nil
+	}))
+
+	numRecords := 0
+	for ; numRecords < 100; numRecords++ {
+		if err = w.AddData(map[string]interface{}{"foo": int64(numRecords)}); err != nil {
+			break
+		}
+	}
+	require.Equal(t, errRotate, err)
+	require.Equal(t, []WriterLimit{LimitMaxFileSize, LimitMaxFileSize}, hits)
+	require.True(t, numRecords > 1 && numRecords < 100, "unexpected number of records %d", numRecords)
+	require.NoError(t, w.Close())
+}
+
+func TestWriterAutoFlush(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional int64 foo;
+	}`)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		opts      []FileWriterOption
+		data      func(i int) map[string]interface{}
+		rowGroups []int64
+	}{
+		"rows": {
+			opts:      []FileWriterOption{WithMaxRowGroupRows(10)},
+			data:      func(i int) map[string]interface{} { return map[string]interface{}{"foo": int64(i)} },
+			rowGroups: []int64{10, 10, 5},
+		},
+		"size_and_rows": {
+			opts:      []FileWriterOption{WithMaxRowGroupSize(80), WithMaxRowGroupRows(8)},
+			data:      func(i int) map[string]interface{} { return map[string]interface{}{"foo": int64(i)} },
+			rowGroups: []int64{8, 8, 8, 1},
+		},
+		"size": {
+			opts:      []FileWriterOption{WithMaxRowGroupSize(80), WithMaxRowGroupRows(20)},
+			data:      func(i int) map[string]interface{} { return map[string]interface{}{"foo": int64(i)} },
+			rowGroups: []int64{10, 10, 5},
+		},
+		"levels": {
+			// only the definition levels of the null values take up space.
+			opts:      []FileWriterOption{WithMaxRowGroupSize(2)},
+			data:      func(i int) map[string]interface{} { return map[string]interface{}{} },
+			rowGroups: []int64{9, 9, 7},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewFileWriter(&buf, append([]FileWriterOption{WithSchemaDefinition(sd)}, tt.opts...)...)
+			for i := 0; i < 25; i++ {
+				require.NoError(t, w.AddData(tt.data(i)))
+			}
+			require.NoError(t, w.Close())
+
+			r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			var rowGroups []int64
+			for _, rg := range r.meta.RowGroups {
+				rowGroups = append(rowGroups, rg.NumRows)
+			}
+			require.Equal(t, tt.rowGroups, rowGroups)
+		})
+	}
+}
+
+func TestWriterRowGroupTargetSize(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary payload;
+	}`)
+	require.NoError(t, err)
+
+	const target, maxPadding = 16 * 1024, 4 * 1024
+
+	var buf bytes.Buffer
+	w := NewFileWriter(&buf, WithSchemaDefinition(sd),
+		WithCompressionCodec(parquet.CompressionCodec_GZIP),
+		WithRowGroupTargetSize(target, maxPadding),
+	)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		// distinct values that can be compressed to about half of their size.
+		payload := make([]byte, 1000)
+		copy(payload, fmt.Sprintf("%04d", i))
+		rnd.Read(payload[4:500])
+		require.NoError(t, w.AddData(map[string]interface{}{"payload": payload}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	rowGroups := r.meta.RowGroups
+	require.True(t, len(rowGroups) > 2)
+
+	// the first row group is flushed once its uncompressed size reaches the first multiple of
+	// the target, the following ones take the compression ratio into account.
+	require.Equal(t, int64(15), rowGroups[0].NumRows)
+	require.True(t, rowGroups[2].NumRows > rowGroups[0].NumRows*3/2, "%d rows", rowGroups[2].NumRows)
+
+	end := int64(len(magic))
+	padded := false
+	for i, rg := range rowGroups {
+		meta := rg.Columns[0].MetaData
+		start := meta.DataPageOffset
+		if meta.DictionaryPageOffset != nil {
+			start = *meta.DictionaryPageOffset
+		}
+		if start != end {
+			padded = true
+			require.Equal(t, int64(0), start%target, "row group %d", i)
+			require.True(t, start-end < maxPadding, "row group %d", i)
+			require.Equal(t, make([]byte, start-end), buf.Bytes()[end:start])
+		}
+		end = start + meta.TotalCompressedSize
+		require.Equal(t, start/target, (end-1)/target, "row group %d crosses a part boundary", i)
+	}
+	require.True(t, padded)
+
+	for i := 0; i < 1000; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("%04d", i)), row["payload"].([]byte)[:4])
+	}
+}
+
+func TestWriteDataPageVersionForColumn(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional group a {
+			repeated int64 b;
+		}
+		optional binary c (STRING);
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithDataPageV2(),
+		WithDataPageVersionForColumn("id", DataPageV1),
+		WithDataPageVersionForColumn("a.b", DataPageV2),
+	)
+	rows := []map[string]interface{}{
+		{"id": int64(1), "a": map[string]interface{}{"b": []int64{1, 2}}, "c": []byte("foo")},
+		{"id": int64(2)},
+		{"id": int64(3), "a": map[string]interface{}{"b": []int64{3}}},
+	}
+	for _, row := range rows {
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	for _, row := range rows {
+		actual, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, row, actual)
+	}
+
+	expected := map[string]parquet.PageType{
+		"id":  parquet.PageType_DATA_PAGE,
+		"a.b": parquet.PageType_DATA_PAGE_V2,
+		"c":   parquet.PageType_DATA_PAGE_V2,
+	}
+	for _, chunk := range r.meta.RowGroups[0].Columns {
+		meta := chunk.MetaData
+		ph := &parquet.PageHeader{}
+		require.NoError(t, readThrift(ph, bytes.NewReader(buf.Bytes()[meta.DataPageOffset:])))
+		col := strings.Join(meta.PathInSchema, ".")
+		require.Equal(t, expected[col], ph.Type, col)
+	}
+
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithDataPageVersionForColumn("unknown", DataPageV2))
+	require.NoError(t, w.AddData(rows[0]))
+	require.Error(t, w.FlushRowGroup())
+}
+
+func TestWriteColumnCodec(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional group a {
+			repeated int64 b;
+		}
+		optional binary c (STRING);
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd),
+		WithCompressionCodec(parquet.CompressionCodec_SNAPPY),
+		WithColumnCodec("c", parquet.CompressionCodec_GZIP),
+		WithColumnCodec("a.b", parquet.CompressionCodec_UNCOMPRESSED),
+	)
+	rows := []map[string]interface{}{
+		{"id": int64(1), "a": map[string]interface{}{"b": []int64{1, 2}}, "c": []byte("foo")},
+		{"id": int64(2)},
+		{"id": int64(3), "a": map[string]interface{}{"b": []int64{3}}},
+	}
+	for _, row := range rows {
+		require.NoError(t, w.AddData(row))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	for _, row := range rows {
+		actual, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, row, actual)
+	}
+
+	expected := map[string]parquet.CompressionCodec{
+		"id":  parquet.CompressionCodec_SNAPPY,
+		"a.b": parquet.CompressionCodec_UNCOMPRESSED,
+		"c":   parquet.CompressionCodec_GZIP,
+	}
+	for _, chunk := range r.meta.RowGroups[0].Columns {
+		col := strings.Join(chunk.MetaData.PathInSchema, ".")
+		require.Equal(t, expected[col], chunk.MetaData.Codec, col)
+	}
+
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithColumnCodec("unknown", parquet.CompressionCodec_GZIP))
+	require.NoError(t, w.AddData(rows[0]))
+	require.Error(t, w.FlushRowGroup())
+}
+
+func TestFlushRowGroupInvalidColumnOptions(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	for _, opt := range []FileWriterOption{
+		WithDataPageVersionForColumn("unknown", DataPageV2),
+		WithColumnCodec("unknown", parquet.CompressionCodec_GZIP),
+		WithColumnStatisticsBinaryComparator("unknown", BinaryComparatorSigned),
+		WithEncodingForColumn("unknown", parquet.Encoding_PLAIN),
+		WithBloomFilter("unknown", 0.01),
+	} {
+		w := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), opt)
+		require.NoError(t, w.WriteColumnBatch("id", []int64{1, 2}, nil, nil))
+
+		// the column batches of the row group are kept as they are.
+		require.Error(t, w.FlushRowGroup())
+		require.Equal(t, map[string]int64{"id": 2}, w.columnBatchRecords)
+		require.Error(t, w.FlushRowGroup())
+	}
+}
+
+func TestWriterTransactions(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		repeated int32 tags;
+	}`)
+	require.NoError(t, err)
+
+	row := func(id int64) map[string]interface{} {
+		data := map[string]interface{}{
+			"id":   id,
+			"tags": []int32{int32(id), int32(id % 3)},
+		}
+		if id%2 == 0 {
+			data["name"] = []byte(fmt.Sprintf("name%d", id))
+		}
+		return data
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+
+	require.Error(t, w.Commit())
+	require.Error(t, w.Rollback())
+
+	for i := int64(0); i < 10; i++ {
+		require.NoError(t, w.AddData(row(i)))
+	}
+
+	require.NoError(t, w.BeginRowGroup())
+	require.Error(t, w.BeginRowGroup())
+	for i := int64(1000); i < 1020; i++ {
+		require.NoError(t, w.AddData(row(i)))
+	}
+	// tags fails after id and name were already added.
+	require.Error(t, w.AddData(map[string]interface{}{"id": int64(-5), "name": []byte("zzz"), "tags": "invalid"}))
+	require.Error(t, w.FlushRowGroup())
+	require.Error(t, w.Close())
+	require.NoError(t, w.Rollback())
+
+	require.NoError(t, w.BeginRowGroup())
+	for i := int64(10); i < 20; i++ {
+		require.NoError(t, w.AddData(row(i)))
+	}
+	require.NoError(t, w.Commit())
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(20), r.NumRows())
+
+	for i := int64(0); i < 20; i++ {
+		data, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, row(i), data)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+
+	stats, err := r.RollupStatistics()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), int64(binary.LittleEndian.Uint64(stats["id"].MinValue)))
+	require.Equal(t, int64(19), int64(binary.LittleEndian.Uint64(stats["id"].MaxValue)))
+	require.Equal(t, int32(19), int32(binary.LittleEndian.Uint32(stats["tags"].MaxValue)))
+	require.Equal(t, int64(40), stats["tags"].NumValues)
+}
+
+func TestWriteUnsignedColumns(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 a (INT(32, false));
+		required int64 b (INT(64, false));
+	}`)
+	require.NoError(t, err)
+
+	for _, enc := range []parquet.Encoding{parquet.Encoding_PLAIN, parquet.Encoding_DELTA_BINARY_PACKED} {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd), WithEncodingForColumn("a", enc), WithEncodingForColumn("b", enc))
+		for i := 0; i < 3; i++ {
+			require.NoError(t, w.AddData(map[string]interface{}{"a": int32(i - 1), "b": int64(i - 2)}))
+		}
+		require.NoError(t, w.Close(), enc.String())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"a": uint32(math.MaxUint32), "b": uint64(math.MaxUint64 - 1)}, row, enc.String())
+	}
+}
+
+func TestWriteEncodingForColumn(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional int32 day (DATE);
+		required binary name (STRING);
+		required double value;
+	}`)
+	require.NoError(t, err)
+
+	write := func(opts ...FileWriterOption) ([]byte, error) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithSchemaDefinition(sd)}, opts...)...)
+		for i := 0; i < 1000; i++ {
+			data := map[string]interface{}{
+				"id":    int64(1e12 + i*3),
+				"name":  []byte(fmt.Sprintf("name%d", i)),
+				"value": float64(i) / 2,
+			}
+			if i%5 != 0 {
+				data["day"] = int32(18000 + i/10)
+			}
+			if err := w.AddData(data); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	plain, err := write(WithEncodingForColumn("id", parquet.Encoding_PLAIN))
+	require.NoError(t, err)
+
+	delta, err := write(
+		WithEncodingForColumn("id", parquet.Encoding_DELTA_BINARY_PACKED),
+		WithEncodingForColumn("day", parquet.Encoding_DELTA_BINARY_PACKED),
+		WithEncodingForColumn("name", parquet.Encoding_DELTA_BYTE_ARRAY),
+	)
+	require.NoError(t, err)
+
+	plainReader, err := NewFileReader(bytes.NewReader(plain))
+	require.NoError(t, err)
+	r, err := NewFileReader(bytes.NewReader(delta))
+	require.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		expected, err := plainReader.NextRow()
+		require.NoError(t, err)
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected, row)
+	}
+
+	plainID := plainReader.CurrentRowGroup().Columns[0].MetaData
+	deltaID := r.CurrentRowGroup().Columns[0].MetaData
+	require.Contains(t, deltaID.Encodings, parquet.Encoding_DELTA_BINARY_PACKED)
+	require.Less(t, deltaID.TotalCompressedSize*10, plainID.TotalCompressedSize)
+	require.Contains(t, r.CurrentRowGroup().Columns[1].MetaData.Encodings, parquet.Encoding_DELTA_BINARY_PACKED)
+	require.Contains(t, r.CurrentRowGroup().Columns[2].MetaData.Encodings, parquet.Encoding_DELTA_BYTE_ARRAY)
+
+	_, err = write(WithEncodingForColumn("value", parquet.Encoding_DELTA_BINARY_PACKED))
+	require.Error(t, err)
+	_, err = write(WithEncodingForColumn("unknown", parquet.Encoding_PLAIN))
+	require.Error(t, err)
+}
+
+func TestWriteNullColumn(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary padding (UNKNOWN);
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithPageIndex(), WithPageValueLimits(1, 100))
+
+	numRows := 1000
+	for i := 0; i < numRows; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i)}))
+	}
+	require.NoError(t, w.Close())
+
+	w2 := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+	require.Error(t, w2.AddData(map[string]interface{}{"id": int64(0), "padding": []byte("foo")}))
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Contains(t, r.String(), "optional binary padding (UNKNOWN);")
+
+	_, idOffsets, err := r.PageIndex(0, "id")
+	require.NoError(t, err)
+	require.Len(t, idOffsets.PageLocations, numRows/100)
+
+	// the null column is written as a single page without values.
+	_, offsets, err := r.PageIndex(0, "padding")
+	require.NoError(t, err)
+	require.Len(t, offsets.PageLocations, 1)
+	meta := r.meta.RowGroups[0].Columns[1].MetaData
+	require.Nil(t, meta.DictionaryPageOffset)
+	require.Equal(t, int64(numRows), meta.Statistics.GetNullCount())
+
+	for i := 0; i < numRows; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"id": int64(i)}, row)
+	}
+}
+
+func TestWriteMetaData(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	kv := map[string]string{"lineage": "job-1", "tmp": "x"}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMetaData(kv))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.NoError(t, w.FlushRowGroup())
+
+	w.SetMetaData("schema.registry.id", "42")
+	w.SetMetaData("lineage", "job-2")
+	w.SetMetaData("empty", "")
+	w.DeleteMetaData("tmp")
+	require.Equal(t, map[string]string{"lineage": "job-2", "schema.registry.id": "42", "empty": ""}, w.MetaData())
+	// the map passed to WithMetaData isn't shared with the writer.
+	require.Equal(t, map[string]string{"lineage": "job-1", "tmp": "x"}, kv)
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	// entries without a value are omitted by MetaData.
+	require.Equal(t, map[string]string{"lineage": "job-2", "schema.registry.id": "42"}, r.MetaData())
+
+	// the entries are written in the order of their keys.
+	var keys []string
+	for _, kv := range r.meta.KeyValueMetadata {
+		keys = append(keys, kv.Key)
+	}
+	require.Equal(t, []string{"empty", "lineage", "schema.registry.id"}, keys)
+	require.Nil(t, r.meta.KeyValueMetadata[0].Value)
+}
@@ -0,0 +1,65 @@
+package goparquet
+
+import (
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Rewrite decodes all rows of the parquet file src and encodes them again into a new file written
+// to dst, e.g. to migrate files to another compression codec, page size or encoding. The schema,
+// the key-value meta data of the file and of the column chunks, and the row group boundaries of
+// src are preserved. opts are applied after the schema and the meta data, so they can set
+// everything else, e.g. WithCompressionCodec, WithPageValueLimits or WithEncodingForColumn.
+// Options that limit the size of row groups can split the row groups of src.
+func Rewrite(dst io.Writer, src io.ReadSeeker, opts ...FileWriterOption) error {
+	r, err := NewFileReader(src)
+	if err != nil {
+		return errors.Wrap(err, "opening source file failed")
+	}
+
+	// the schema is set before the options are applied, so that an invalid schema, e.g. one with
+	// duplicate column names, is returned as an error.
+	w := NewFileWriter(dst)
+	if err := w.SetSchemaDefinition(r.GetSchemaDefinition()); err != nil {
+		return errors.Wrap(err, "setting schema definition failed")
+	}
+	for _, opt := range append([]FileWriterOption{WithMetaData(r.MetaData())}, opts...) {
+		opt(w)
+	}
+
+	for rg := 0; rg < r.RowGroupCount(); rg++ {
+		numRows, err := r.RowGroupNumRows()
+		if err != nil {
+			return errors.Wrapf(err, "reading row group %d failed", rg)
+		}
+
+		for i := int64(0); i < numRows; i++ {
+			row, err := r.NextRow()
+			if err != nil {
+				return errors.Wrapf(err, "reading row %d of row group %d failed", i, rg)
+			}
+			if err := w.AddData(row); err != nil {
+				return errors.Wrapf(err, "adding row %d of row group %d failed", i, rg)
+			}
+		}
+
+		if w.rowGroupNumRecords() == 0 {
+			continue
+		}
+
+		var flushOpts []FlushRowGroupOption
+		for _, chunk := range r.CurrentRowGroup().Columns {
+			if chunk.MetaData != nil && len(chunk.MetaData.KeyValueMetadata) > 0 {
+				name := strings.Join(chunk.MetaData.PathInSchema, ".")
+				flushOpts = append(flushOpts, WithRowGroupMetaDataForColumn(name, keyValueMetaDataToMap(chunk.MetaData.KeyValueMetadata)))
+			}
+		}
+		if err := w.FlushRowGroup(flushOpts...); err != nil {
+			return errors.Wrapf(err, "flushing row group %d failed", rg)
+		}
+	}
+
+	return w.Close()
+}
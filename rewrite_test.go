@@ -0,0 +1,93 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewrite(t *testing.T) {
+	data := writeValidateTestFile(t, WithCompressionCodec(parquet.CompressionCodec_GZIP), WithMetaData(map[string]string{"foo": "bar"}))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Rewrite(buf, bytes.NewReader(data), WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithPageValueLimits(1, 30)))
+
+	orig, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{"foo": "bar"}, r.MetaData())
+	require.Equal(t, 3, r.RowGroupCount())
+	for _, rg := range r.meta.RowGroups {
+		require.Equal(t, int64(100), rg.NumRows)
+		for _, chunk := range rg.Columns {
+			require.Equal(t, parquet.CompressionCodec_SNAPPY, chunk.MetaData.Codec)
+		}
+	}
+
+	for {
+		expected, err := orig.NextRow()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected, row)
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestRewriteColumnMetaData(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.NoError(t, w.FlushRowGroup(WithRowGroupMetaDataForColumn("id", map[string]string{"foo": "bar"})))
+	require.NoError(t, w.Close())
+
+	out := &bytes.Buffer{}
+	require.NoError(t, Rewrite(out, bytes.NewReader(buf.Bytes())))
+
+	r, err := NewFileReader(bytes.NewReader(out.Bytes()))
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	require.NoError(t, err)
+	kv, err := r.ColumnMetaData("id")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"foo": "bar"}, kv)
+
+	require.Error(t, Rewrite(&bytes.Buffer{}, bytes.NewReader([]byte("invalid"))))
+}
+
+func TestRewriteDuplicateColumnNames(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+		required int64 fop;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"foo": int64(1), "fop": int64(2)}))
+	require.NoError(t, w.Close())
+
+	// the source file can be read, but a file with duplicate column names can't be written.
+	data := bytes.Replace(buf.Bytes(), []byte("fop"), []byte("foo"), -1)
+	_, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	err = Rewrite(&bytes.Buffer{}, bytes.NewReader(data))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `duplicate column name "foo"`)
+}
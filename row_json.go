@@ -0,0 +1,224 @@
+package goparquet
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+)
+
+// Row is a row as returned by NextRow together with the schema definition of its columns, so
+// that it can be encoded as JSON according to the types of the columns.
+type Row struct {
+	// Values contains the values of the row like they are returned by NextRow.
+	Values map[string]interface{}
+	// Schema is the schema definition of the row.
+	Schema *parquetschema.SchemaDefinition
+}
+
+// NextRowWithSchema reads the next row like NextRow and returns it together with the schema
+// definition of the rows returned by NextRow.
+func (f *FileReader) NextRowWithSchema() (*Row, error) {
+	values, err := f.NextRow()
+	if err != nil {
+		return nil, err
+	}
+	return &Row{Values: values, Schema: f.RowSchemaDefinition()}, nil
+}
+
+// MarshalJSON encodes the row as a JSON object. Groups are encoded as objects and repeated
+// columns as arrays. Binary values annotated as STRING, ENUM or JSON are encoded as strings,
+// UUIDs in their canonical text form and other binary values as base64. Decimals are encoded as
+// strings with the exact decimal value, e.g. "-12.30". Timestamps are encoded in RFC 3339 format,
+// in UTC if they're adjusted to UTC and without time zone otherwise, dates as "2006-01-02" and
+// times as "15:04:05.999999999". NaN and infinite floating point values are encoded as the
+// strings "NaN", "+Inf" and "-Inf".
+func (r *Row) MarshalJSON() ([]byte, error) {
+	var children []*parquetschema.ColumnDefinition
+	if r.Schema != nil && r.Schema.RootColumn != nil {
+		children = r.Schema.RootColumn.Children
+	}
+	return json.Marshal(jsonGroup(children, r.Values))
+}
+
+// jsonGroup converts the values of a group to JSON values.
+func jsonGroup(cols []*parquetschema.ColumnDefinition, data map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(data))
+	for name, v := range data {
+		col := findColumnDefinition(cols, name)
+		if col == nil {
+			ret[name] = v
+			continue
+		}
+		ret[name] = jsonColumnValue(col, v)
+	}
+	return ret
+}
+
+// jsonColumnValue converts a value of the column col, which may be a slice of values if the
+// column is repeated, to a JSON value.
+func jsonColumnValue(col *parquetschema.ColumnDefinition, v interface{}) interface{} {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return jsonGroup(col.Children, x)
+	case []map[string]interface{}:
+		ret := make([]interface{}, len(x))
+		for i := range x {
+			ret[i] = jsonGroup(col.Children, x[i])
+		}
+		return ret
+	case []byte:
+		return jsonValue(col.SchemaElement, x)
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		ret := make([]interface{}, rv.Len())
+		for i := range ret {
+			ret[i] = jsonValue(col.SchemaElement, rv.Index(i).Interface())
+		}
+		return ret
+	}
+
+	return jsonValue(col.SchemaElement, v)
+}
+
+// jsonValue converts a single value of a column with the schema element elem to a JSON value.
+func jsonValue(elem *parquet.SchemaElement, v interface{}) interface{} {
+	lt, ct := elem.LogicalType, elem.ConvertedType
+	isConverted := func(types ...parquet.ConvertedType) bool {
+		if ct == nil {
+			return false
+		}
+		for _, typ := range types {
+			if *ct == typ {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch x := v.(type) {
+	case []byte:
+		switch {
+		case isDecimalElement(elem):
+			return formatDecimal(decimalFromBytes(x), elem.GetScale())
+		case parquetschema.IsUTF8String(elem):
+			return string(x)
+		case lt != nil && lt.IsSetUUID() && len(x) == 16:
+			return formatUUID(x)
+		}
+		return x
+
+	case [12]byte:
+		return Int96ToTime(x).UTC().Format(time.RFC3339Nano)
+
+	case int32:
+		switch {
+		case isDecimalElement(elem):
+			return formatDecimal(big.NewInt(int64(x)), elem.GetScale())
+		case lt != nil && lt.IsSetDATE(), isConverted(parquet.ConvertedType_DATE):
+			return time.Unix(int64(x)*24*60*60, 0).UTC().Format("2006-01-02")
+		case lt != nil && lt.IsSetTIME(), isConverted(parquet.ConvertedType_TIME_MILLIS):
+			return formatTime(int64(x) * int64(time.Millisecond))
+		}
+		return x
+
+	case int64:
+		switch {
+		case isDecimalElement(elem):
+			return formatDecimal(big.NewInt(x), elem.GetScale())
+		case lt != nil && lt.IsSetTIMESTAMP():
+			t := unitsToTime(x, timeUnitDuration(lt.TIMESTAMP.Unit))
+			if lt.TIMESTAMP.IsAdjustedToUTC {
+				return t.Format(time.RFC3339Nano)
+			}
+			return t.Format("2006-01-02T15:04:05.999999999")
+		case isConverted(parquet.ConvertedType_TIMESTAMP_MILLIS):
+			return unitsToTime(x, time.Millisecond).Format(time.RFC3339Nano)
+		case isConverted(parquet.ConvertedType_TIMESTAMP_MICROS):
+			return unitsToTime(x, time.Microsecond).Format(time.RFC3339Nano)
+		case lt != nil && lt.IsSetTIME():
+			return formatTime(x * int64(timeUnitDuration(lt.TIME.Unit)))
+		case isConverted(parquet.ConvertedType_TIME_MICROS):
+			return formatTime(x * int64(time.Microsecond))
+		}
+		return x
+
+	case float32:
+		if s, ok := formatNonFinite(float64(x)); ok {
+			return s
+		}
+		return x
+
+	case float64:
+		if s, ok := formatNonFinite(x); ok {
+			return s
+		}
+		return x
+	}
+
+	return v
+}
+
+// timeUnitDuration returns the duration of a unit of a TIMESTAMP or TIME logical type.
+func timeUnitDuration(unit *parquet.TimeUnit) time.Duration {
+	switch {
+	case unit == nil:
+		return time.Millisecond
+	case unit.IsSetNANOS():
+		return time.Nanosecond
+	case unit.IsSetMICROS():
+		return time.Microsecond
+	}
+	return time.Millisecond
+}
+
+// formatTime formats a time of day given in nanoseconds since midnight.
+func formatTime(nsec int64) string {
+	return time.Unix(0, nsec).UTC().Format("15:04:05.999999999")
+}
+
+func formatNonFinite(f float64) (string, bool) {
+	switch {
+	case math.IsNaN(f):
+		return "NaN", true
+	case math.IsInf(f, 1):
+		return "+Inf", true
+	case math.IsInf(f, -1):
+		return "-Inf", true
+	}
+	return "", false
+}
+
+// decimalFromBytes returns the unscaled value of a decimal stored as big-endian two's complement.
+func decimalFromBytes(b []byte) *big.Int {
+	v := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+	}
+	return v
+}
+
+// formatDecimal formats the unscaled value of a decimal with scale digits after the decimal point.
+func formatDecimal(unscaled *big.Int, scale int32) string {
+	if scale <= 0 {
+		return new(big.Int).Mul(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil)).String()
+	}
+
+	digits := new(big.Int).Abs(unscaled).String()
+	if len(digits) <= int(scale) {
+		digits = strings.Repeat("0", int(scale)-len(digits)+1) + digits
+	}
+	s := digits[:len(digits)-int(scale)] + "." + digits[len(digits)-int(scale):]
+	if unscaled.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
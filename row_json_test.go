@@ -0,0 +1,145 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowMarshalJSON(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary name (STRING);
+		optional binary blob;
+		optional fixed_len_byte_array(16) id (UUID);
+		required int32 price (DECIMAL(9, 2));
+		optional fixed_len_byte_array(16) big (DECIMAL(30, 4));
+		optional int64 ts (TIMESTAMP(MICROS, true));
+		optional int64 local (TIMESTAMP(NANOS, false));
+		optional int64 legacy (TIMESTAMP_MILLIS);
+		optional int32 day (DATE);
+		optional int32 tod (TIME(MILLIS, true));
+		optional int96 old;
+		optional double score;
+		optional group tags (LIST) {
+			repeated group list {
+				required binary element (STRING);
+			}
+		}
+		optional group nested {
+			repeated float values;
+		}
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"name":   []byte("hello"),
+		"blob":   []byte{0xff, 0x00},
+		"id":     []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00},
+		"price":  int32(-1230),
+		"big":    append(bytes.Repeat([]byte{0xff}, 15), 0xfb),
+		"ts":     int64(1600000000123456),
+		"local":  int64(1600000000000000001),
+		"legacy": int64(1600000000123),
+		"day":    int32(18518),
+		"tod":    int32(45296789),
+		"old":    TimeToInt96(time.Date(2020, 9, 13, 12, 26, 40, 500000000, time.UTC)),
+		"score":  math.NaN(),
+		"tags": map[string]interface{}{
+			"list": []map[string]interface{}{
+				{"element": []byte("a")},
+				{"element": []byte("b")},
+			},
+		},
+		"nested": map[string]interface{}{
+			"values": []float32{1.5, float32(math.Inf(-1))},
+		},
+	}))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"name":  []byte("empty"),
+		"price": int32(5),
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	row, err := r.NextRowWithSchema()
+	require.NoError(t, err)
+	data, err := json.Marshal(row)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"name": "hello",
+		"blob": "/wA=",
+		"id": "123e4567-e89b-12d3-a456-426614174000",
+		"price": "-12.30",
+		"big": "-0.0005",
+		"ts": "2020-09-13T12:26:40.123456Z",
+		"local": "2020-09-13T12:26:40.000000001",
+		"legacy": "2020-09-13T12:26:40.123Z",
+		"day": "2020-09-13",
+		"tod": "12:34:56.789",
+		"old": "2020-09-13T12:26:40.5Z",
+		"score": "NaN",
+		"tags": {"list": [{"element": "a"}, {"element": "b"}]},
+		"nested": {"values": [1.5, "-Inf"]}
+	}`, string(data))
+
+	row, err = r.NextRowWithSchema()
+	require.NoError(t, err)
+	data, err = json.Marshal(row)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "empty", "price": "0.05"}`, string(data))
+
+	_, err = r.NextRowWithSchema()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		unscaled int64
+		scale    int32
+		expected string
+	}{
+		{0, 0, "0"},
+		{0, 2, "0.00"},
+		{12345, 2, "123.45"},
+		{-12345, 5, "-0.12345"},
+		{-1, 3, "-0.001"},
+		{42, -2, "4200"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, formatDecimal(big.NewInt(tt.unscaled), tt.scale))
+	}
+
+	require.Equal(t, "-1", decimalFromBytes([]byte{0xff, 0xff}).String())
+	require.Equal(t, "255", decimalFromBytes([]byte{0x00, 0xff}).String())
+}
+
+func TestJSONValueTimestampRange(t *testing.T) {
+	sentinel := time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+	early := time.Date(1000, 1, 1, 0, 0, 0, 1000000, time.UTC)
+
+	micros := &parquet.SchemaElement{LogicalType: &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{
+		IsAdjustedToUTC: true,
+		Unit:            &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()},
+	}}}
+	require.Equal(t, "9999-12-31T23:59:59Z", jsonValue(micros, sentinel.Unix()*1000000))
+	require.Equal(t, "1000-01-01T00:00:00.001Z", jsonValue(micros, early.Unix()*1000000+1000))
+
+	millis := &parquet.SchemaElement{ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS)}
+	require.Equal(t, "9999-12-31T23:59:59Z", jsonValue(millis, sentinel.Unix()*1000))
+	require.Equal(t, "1000-01-01T00:00:00.001Z", jsonValue(millis, early.Unix()*1000+1))
+
+	legacyMicros := &parquet.SchemaElement{ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)}
+	require.Equal(t, "9999-12-31T23:59:59Z", jsonValue(legacyMicros, sentinel.Unix()*1000000))
+}
@@ -2,6 +2,7 @@ package goparquet
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/fraugster/parquet-go/parquet"
@@ -100,6 +101,11 @@ func (c *Column) Element() *parquet.SchemaElement {
 	return c.element
 }
 
+// FieldID returns the field ID of the column, or nil if the column has no field ID.
+func (c *Column) FieldID() *int32 {
+	return c.Element().FieldID
+}
+
 // Type returns the parquet type of the value. If the column is a group, then the
 // method will return nil.
 func (c *Column) Type() *parquet.Type {
@@ -160,12 +166,15 @@ func (c *Column) buildElement() *parquet.SchemaElement {
 	return elem
 }
 
+// getDataSize returns the estimated size of the values and the repetition and definition levels
+// that are buffered for the column.
 func (c *Column) getDataSize() int64 {
+	levelsSize := c.data.rLevels.size() + c.data.dLevels.size()
 	if _, ok := c.data.typedColumnStore.(*booleanStore); ok {
 		// Booleans are stored in one bit, so the result is the number of items / 8
-		return int64(c.data.values.numValues())/8 + 1
+		return int64(c.data.values.numValues())/8 + 1 + levelsSize
 	}
-	return c.data.values.size
+	return c.data.values.size + levelsSize
 }
 
 func (c *Column) getNextData() (map[string]interface{}, int32, error) {
@@ -232,6 +241,28 @@ func (c *Column) getFirstRDLevel() (int32, int32, bool) {
 	return -1, -1, false
 }
 
+// getNextRLevel returns the repetition level of the next value of the column. All data columns
+// of a group share the repetition levels of the group, so for groups it's the repetition level of
+// the next value of any data column that is read, even if the value is null, e.g. for a null
+// element of a list.
+func (c *Column) getNextRLevel() (int32, bool) {
+	if c.data != nil {
+		if c.data.skipped {
+			return 0, true
+		}
+		rl, _, last := c.data.getRDLevelAt(-1)
+		return rl, last
+	}
+
+	for i := range c.children {
+		if rl, last := c.children[i].getNextRLevel(); !last {
+			return rl, false
+		}
+	}
+
+	return 0, true
+}
+
 func (c *Column) getData() (interface{}, int32, error) {
 	if c.children != nil {
 		data, maxD, err := c.getNextData()
@@ -245,7 +276,7 @@ func (c *Column) getData() (interface{}, int32, error) {
 
 		ret := []map[string]interface{}{data}
 		for {
-			rl, _, last := c.getFirstRDLevel()
+			rl, last := c.getNextRLevel()
 			if last || rl < int32(c.maxR) || rl == 0 {
 				// end of this object
 				return ret, maxD, nil
@@ -271,6 +302,8 @@ type schema struct {
 
 	// selected columns in reading. if the size is zero, it means all the columns
 	selectedColumn []string
+	// evicted columns in reading. they are not read anymore, even if they are selected
+	evictedColumn []string
 }
 
 func (r *schema) ensureRoot() {
@@ -293,7 +326,17 @@ func (r *schema) setSelectedColumns(selected ...string) {
 	r.selectedColumn = selected
 }
 
+func (r *schema) evictColumns(columns ...string) {
+	r.evictedColumn = append(r.evictedColumn, columns...)
+}
+
 func (r *schema) isSelected(path string) bool {
+	for _, pattern := range r.evictedColumn {
+		if pattern == path || strings.HasPrefix(path, pattern+".") {
+			return false
+		}
+	}
+
 	if len(r.selectedColumn) == 0 {
 		return true
 	}
@@ -314,8 +357,11 @@ func (r *schema) isSelected(path string) bool {
 func (r *schema) getSchemaArray() []*parquet.SchemaElement {
 	r.ensureRoot()
 	elem := r.root.getSchemaArray()
-	// the root doesn't have repetition type
-	elem[0].RepetitionType = nil
+	// the root doesn't have repetition type. the element is copied as it may be shared
+	// with other schemas, see CompiledSchema.
+	root := *elem[0]
+	root.RepetitionType = nil
+	elem[0] = &root
 	return elem
 }
 
@@ -338,14 +384,59 @@ func (r *schema) Columns() []*Column {
 }
 
 func (r *schema) GetColumnByName(path string) *Column {
+	var col *Column
 	data := r.Columns()
 	for i := range data {
 		if data[i].flatName == path {
-			return data[i]
+			if col != nil {
+				// the name is ambiguous, see DuplicateColumnNamesAllow.
+				return nil
+			}
+			col = data[i]
 		}
 	}
 
-	return nil
+	return col
+}
+
+func (r *schema) GetColumnByFieldID(id int32) *Column {
+	if r.root == nil {
+		return nil
+	}
+
+	var (
+		col  *Column
+		walk func(cols []*Column) bool
+	)
+	walk = func(cols []*Column) bool {
+		for _, c := range cols {
+			if fieldID := c.FieldID(); fieldID != nil && *fieldID == id {
+				if col != nil {
+					return false
+				}
+				col = c
+			}
+			if !walk(c.children) {
+				return false
+			}
+		}
+		return true
+	}
+	if !walk(r.root.children) {
+		// the field ID is ambiguous.
+		return nil
+	}
+
+	return col
+}
+
+func (r *schema) GetColumnByIndex(index int) *Column {
+	data := r.Columns()
+	if index < 0 || index >= len(data) {
+		return nil
+	}
+
+	return data[index]
 }
 
 // resetData is useful for resetting data after writing a chunk, to collect data for the next chunk
@@ -396,9 +487,15 @@ func (r *schema) SetSchemaDefinition(sd *parquetschema.SchemaDefinition) error {
 	r.root = root
 
 	for _, c := range r.root.children {
-		recursiveFix(c, "", 0, 0)
+		if err := recursiveFix(c, "", 0, 0); err != nil {
+			return err
+		}
 	}
 
+	if err := r.resolveDuplicateNames(DuplicateColumnNamesError); err != nil {
+		return err
+	}
+	r.sortIndex()
 	return nil
 }
 
@@ -582,28 +679,69 @@ func (r *schema) AddColumn(path string, col *Column) error {
 	return r.addColumnOrGroup(path, col)
 }
 
-func recursiveFix(col *Column, path string, maxR, maxD uint16) {
-	if col.rep != parquet.FieldRepetitionType_REQUIRED {
+// MaxLevel is the highest definition and repetition level that is supported. Schemas that are
+// nested so deeply that the levels of a column would exceed it can't be read or written.
+const MaxLevel = math.MaxUint16
+
+// LevelLimitExceededError is returned if the definition or repetition levels of a column in a
+// schema exceed MaxLevel, or the lower limit that was set using WithMaxLevel.
+type LevelLimitExceededError struct {
+	// Column is the flat name of the column or group whose levels exceed the limit.
+	Column string
+	// Level is either "definition" or "repetition".
+	Level string
+	// Max is the limit that was exceeded.
+	Max uint16
+}
+
+func (e *LevelLimitExceededError) Error() string {
+	return fmt.Sprintf("the %s level of column %s exceeds the maximum of %d", e.Level, e.Column, e.Max)
+}
+
+// nextLevels returns the maximum definition and repetition levels of a column with the
+// repetition type rep whose parent has the maximum levels maxD and maxR.
+func nextLevels(flatName string, rep parquet.FieldRepetitionType, maxD, maxR, limit uint16) (uint16, uint16, error) {
+	if rep != parquet.FieldRepetitionType_REQUIRED {
+		if maxD >= limit {
+			return 0, 0, &LevelLimitExceededError{Column: flatName, Level: "definition", Max: limit}
+		}
 		maxD++
 	}
-	if col.rep == parquet.FieldRepetitionType_REPEATED {
+	if rep == parquet.FieldRepetitionType_REPEATED {
+		if maxR >= limit {
+			return 0, 0, &LevelLimitExceededError{Column: flatName, Level: "repetition", Max: limit}
+		}
 		maxR++
 	}
 
-	col.maxR = maxR
-	col.maxD = maxD
+	return maxD, maxR, nil
+}
+
+func recursiveFix(col *Column, path string, maxR, maxD uint16) error {
 	col.flatName = path + "." + col.name
 	if path == "" {
 		col.flatName = col.name
 	}
+
+	maxD, maxR, err := nextLevels(col.flatName, col.rep, maxD, maxR, MaxLevel)
+	if err != nil {
+		return err
+	}
+
+	col.maxR = maxR
+	col.maxD = maxD
 	if col.data != nil {
 		col.data.reset(col.rep, col.maxR, col.maxD)
-		return
+		return nil
 	}
 
 	for i := range col.children {
-		recursiveFix(col.children[i], col.flatName, maxR, maxD)
+		if err := recursiveFix(col.children[i], col.flatName, maxR, maxD); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
 // do not call this function externally
@@ -651,7 +789,15 @@ func (r *schema) addColumnOrGroup(path string, col *Column) error {
 		return errors.New("the children are nil")
 	}
 
-	recursiveFix(col, c.flatName, c.maxR, c.maxD)
+	for _, child := range c.children {
+		if child.name == name {
+			return errors.Errorf("column %s already exists", path)
+		}
+	}
+
+	if err := recursiveFix(col, c.flatName, c.maxR, c.maxD); err != nil {
+		return err
+	}
 
 	c.children = append(c.children, col)
 	r.sortIndex()
@@ -689,6 +835,31 @@ func (r *schema) findDataColumn(path string) (*Column, error) {
 	return ret, nil
 }
 
+// repeatedDefinitionLevels returns the definition levels of the repeated columns and groups on
+// the path to the column with the flat name path, i.e. the definition level at which the element
+// that is repeated at repetition level r is defined is at index r-1.
+func (r *schema) repeatedDefinitionLevels(path string) []uint16 {
+	r.ensureRoot()
+	var levels []uint16
+	c := r.root
+	for _, name := range strings.Split(path, ".") {
+		found := false
+		for _, child := range c.children {
+			if child.name == name {
+				c, found = child, true
+				break
+			}
+		}
+		if !found {
+			return levels
+		}
+		if c.rep == parquet.FieldRepetitionType_REPEATED {
+			levels = append(levels, c.maxD)
+		}
+	}
+	return levels
+}
+
 func (r *schema) AddData(m map[string]interface{}) error {
 	r.readOnly = 1
 	r.ensureRoot()
@@ -786,7 +957,7 @@ func recursiveAddColumnData(c []*Column, m interface{}, defLvl uint16, maxRepLvl
 	return nil
 }
 
-func (c *Column) readColumnSchema(schema []*parquet.SchemaElement, name string, idx int, dLevel, rLevel uint16) (int, error) {
+func (c *Column) readColumnSchema(schema []*parquet.SchemaElement, name string, idx int, dLevel, rLevel, maxLevel uint16) (int, error) {
 	s := schema[idx]
 
 	if s.Name == "" {
@@ -797,12 +968,14 @@ func (c *Column) readColumnSchema(schema []*parquet.SchemaElement, name string,
 		return 0, errors.Errorf("field RepetitionType is nil in index %d", idx)
 	}
 
-	if *s.RepetitionType != parquet.FieldRepetitionType_REQUIRED {
-		dLevel++
+	c.flatName = name + "." + s.Name
+	if name == "" {
+		c.flatName = s.Name
 	}
 
-	if *s.RepetitionType == parquet.FieldRepetitionType_REPEATED {
-		rLevel++
+	dLevel, rLevel, err := nextLevels(c.flatName, *s.RepetitionType, dLevel, rLevel, maxLevel)
+	if err != nil {
+		return 0, err
 	}
 
 	c.element = s
@@ -814,15 +987,11 @@ func (c *Column) readColumnSchema(schema []*parquet.SchemaElement, name string,
 	}
 	c.rep = *s.RepetitionType
 	c.data = data
-	c.flatName = name + "." + s.Name
 	c.name = s.Name
-	if name == "" {
-		c.flatName = s.Name
-	}
 	return idx + 1, nil
 }
 
-func (c *Column) readGroupSchema(schema []*parquet.SchemaElement, name string, idx int, dLevel, rLevel uint16) (int, error) {
+func (c *Column) readGroupSchema(schema []*parquet.SchemaElement, name string, idx int, dLevel, rLevel, maxLevel uint16) (int, error) {
 	if len(schema) <= idx {
 		return 0, errors.New("schema index out of bound")
 	}
@@ -844,29 +1013,26 @@ func (c *Column) readGroupSchema(schema []*parquet.SchemaElement, name string, i
 		return 0, errors.Errorf("not enough element in the schema list in index %d", idx)
 	}
 
-	if s.RepetitionType != nil && *s.RepetitionType != parquet.FieldRepetitionType_REQUIRED {
-		dLevel++
+	if name == "" {
+		name = s.Name
+	} else {
+		name += "." + s.Name
 	}
 
-	if s.RepetitionType != nil && *s.RepetitionType == parquet.FieldRepetitionType_REPEATED {
-		rLevel++
+	// a group without repetition type is treated as required.
+	dLevel, rLevel, err := nextLevels(name, s.GetRepetitionType(), dLevel, rLevel, maxLevel)
+	if err != nil {
+		return 0, err
 	}
 
 	c.maxD = dLevel
 	c.maxR = rLevel
-
-	if name == "" {
-		name = s.Name
-	} else {
-		name += "." + s.Name
-	}
 	c.flatName = name
 	c.name = s.Name
 	c.element = s
 	c.children = make([]*Column, 0, l)
 	c.rep = s.GetRepetitionType()
 
-	var err error
 	idx++ // move idx from this group to next
 	for i := 0; i < l; i++ {
 		if len(schema) <= idx {
@@ -875,14 +1041,14 @@ func (c *Column) readGroupSchema(schema []*parquet.SchemaElement, name string, i
 		if schema[idx].Type == nil {
 			// another group
 			child := &Column{}
-			idx, err = child.readGroupSchema(schema, name, idx, dLevel, rLevel)
+			idx, err = child.readGroupSchema(schema, name, idx, dLevel, rLevel, maxLevel)
 			if err != nil {
 				return 0, err
 			}
 			c.children = append(c.children, child)
 		} else {
 			child := &Column{}
-			idx, err = child.readColumnSchema(schema, name, idx, dLevel, rLevel)
+			idx, err = child.readColumnSchema(schema, name, idx, dLevel, rLevel, maxLevel)
 			if err != nil {
 				return 0, err
 			}
@@ -893,26 +1059,29 @@ func (c *Column) readGroupSchema(schema []*parquet.SchemaElement, name string, i
 	return idx, nil
 }
 
-func (r *schema) readSchema(schema []*parquet.SchemaElement) error {
+func (r *schema) readSchema(schema []*parquet.SchemaElement, maxLevel uint16, duplicateNames DuplicateColumnNames) error {
 	r.readOnly = 1
 	var err error
 	for idx := 0; idx < len(schema); {
 		if schema[idx].Type == nil {
 			c := &Column{}
-			idx, err = c.readGroupSchema(schema, "", idx, 0, 0)
+			idx, err = c.readGroupSchema(schema, "", idx, 0, 0, maxLevel)
 			if err != nil {
 				return err
 			}
 			r.root.children = append(r.root.children, c)
 		} else {
 			c := &Column{}
-			idx, err = c.readColumnSchema(schema, "", idx, 0, 0)
+			idx, err = c.readColumnSchema(schema, "", idx, 0, 0, maxLevel)
 			if err != nil {
 				return err
 			}
 			r.root.children = append(r.root.children, c)
 		}
 	}
+	if err := r.resolveDuplicateNames(duplicateNames); err != nil {
+		return err
+	}
 	r.sortIndex()
 	r.schemaDef = parquetschema.SchemaDefinitionFromColumnDefinition(createColumnDefinitionFromColumn(r.root))
 	return nil
@@ -939,6 +1108,15 @@ func (r *schema) GetSchemaDefinition() *parquetschema.SchemaDefinition {
 	return def
 }
 
+func (r *schema) String() string {
+	if r.root == nil {
+		return r.schemaDef.String()
+	}
+	// the schema is rendered from the columns, as columns added with AddColumn aren't part of
+	// the schema definition.
+	return parquetschema.SchemaDefinitionFromColumnDefinition(createColumnDefinitionFromColumn(r.root)).String()
+}
+
 // DataSize return the size of data stored in the schema right now
 func (r *schema) DataSize() int64 {
 	cols := r.Columns()
@@ -960,15 +1138,31 @@ func (r *schema) rowGroupNumRecords() int64 {
 type SchemaCommon interface {
 	// Columns return only data columns, not all columns
 	Columns() []*Column
-	// Return a column by its name
+	// Return a column by its name, or nil if no or more than one column has that name
 	GetColumnByName(path string) *Column
+	// Return a data column by its index
+	GetColumnByIndex(index int) *Column
+
+	// GetColumnByFieldID returns the column or group with the field ID id, or nil if no or more
+	// than one column has this field ID.
+	GetColumnByFieldID(id int32) *Column
 
 	// GetSchemaDefinition returns the schema definition.
 	GetSchemaDefinition() *parquetschema.SchemaDefinition
 	SetSchemaDefinition(*parquetschema.SchemaDefinition) error
 
+	// SchemaFingerprint returns a fingerprint of the schema for fast equality checks.
+	SchemaFingerprint() string
+
+	// String returns the schema in the textual format that is also used by parquet-tools,
+	// including logical types and field IDs. It can be parsed with
+	// parquetschema.ParseSchemaDefinition.
+	String() string
+
 	// Internal functions
 	rowGroupNumRecords() int64
+	repeatedDefinitionLevels(path string) []uint16
+	setNumRecords(int64)
 	resetData()
 	getSchemaArray() []*parquet.SchemaElement
 }
@@ -976,9 +1170,9 @@ type SchemaCommon interface {
 // SchemaReader is an interface with methods necessary in the FileReader.
 type SchemaReader interface {
 	SchemaCommon
-	setNumRecords(int64)
 	getData() (map[string]interface{}, error)
 	setSelectedColumns(selected ...string)
+	evictColumns(columns ...string)
 	isSelected(string) bool
 }
 
@@ -993,7 +1187,65 @@ type SchemaWriter interface {
 	DataSize() int64
 }
 
-func makeSchema(meta *parquet.FileMetaData) (SchemaReader, error) {
+// normalizeRepetitionTypes fixes the repetition types of schema elements of producers that omit or
+// misuse them, so that the schema can be read: the root element never has a repetition type, and
+// other elements without a repetition type are treated as required. In strict mode, a root
+// element that is optional or repeated and elements without a repetition type are rejected with a
+// *ValidationError instead.
+func normalizeRepetitionTypes(elems []*parquet.SchemaElement, strict bool) error {
+	if len(elems) == 0 {
+		return nil
+	}
+
+	root := elems[0]
+	if root.RepetitionType != nil {
+		if strict && root.GetRepetitionType() != parquet.FieldRepetitionType_REQUIRED {
+			return &ValidationError{Column: root.Name, Reason: fmt.Sprintf("root element has repetition type %s", root.GetRepetitionType())}
+		}
+		root.RepetitionType = nil
+	}
+
+	// like readSchema, all remaining elements are read as children of the root.
+	for idx := 1; idx < len(elems); {
+		var err error
+		if idx, err = normalizeElementRepetitionType(elems, idx, "", strict); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeElementRepetitionType normalizes the repetition type of the element at idx and its
+// children, and returns the index of the element after them.
+func normalizeElementRepetitionType(elems []*parquet.SchemaElement, idx int, parent string, strict bool) (int, error) {
+	elem := elems[idx]
+	name := elem.Name
+	if parent != "" {
+		name = parent + "." + elem.Name
+	}
+
+	if elem.RepetitionType == nil {
+		if strict {
+			return 0, &ValidationError{Column: name, Reason: "repetition type is missing"}
+		}
+		rep := parquet.FieldRepetitionType_REQUIRED
+		elem.RepetitionType = &rep
+	}
+
+	idx++
+	if elem.Type != nil {
+		return idx, nil
+	}
+	for i := int32(0); i < elem.GetNumChildren() && idx < len(elems); i++ {
+		var err error
+		if idx, err = normalizeElementRepetitionType(elems, idx, name, strict); err != nil {
+			return 0, err
+		}
+	}
+	return idx, nil
+}
+
+func makeSchema(meta *parquet.FileMetaData, maxLevel uint16, duplicateNames DuplicateColumnNames) (SchemaReader, error) {
 	if len(meta.Schema) < 1 {
 		return nil, errors.New("no schema element found")
 	}
@@ -1016,7 +1268,7 @@ func makeSchema(meta *parquet.FileMetaData) (SchemaReader, error) {
 			},
 		},
 	}
-	err := s.readSchema(meta.Schema[1:])
+	err := s.readSchema(meta.Schema[1:], maxLevel, duplicateNames)
 	if err != nil {
 		return nil, err
 	}
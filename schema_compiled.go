@@ -0,0 +1,84 @@
+package goparquet
+
+import (
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/pkg/errors"
+)
+
+// CompiledSchema is a schema definition that was compiled into the column structure used by
+// the FileWriter. It is immutable and can be shared by any number of FileWriters, even
+// concurrently, so that e.g. a sink that writes thousands of partitions with the same schema
+// only needs to build the column structure once. Always use CompileSchema to create such an
+// object.
+type CompiledSchema struct {
+	schemaDef *parquetschema.SchemaDefinition
+	root      *Column
+}
+
+// CompileSchema compiles the schema definition for use with WithCompiledSchema. The schema
+// definition must not be modified afterwards.
+func CompileSchema(sd *parquetschema.SchemaDefinition) (*CompiledSchema, error) {
+	if sd == nil || sd.RootColumn == nil {
+		return nil, errors.New("schema definition without root column")
+	}
+
+	s := &schema{}
+	if err := s.SetSchemaDefinition(sd); err != nil {
+		return nil, err
+	}
+
+	return &CompiledSchema{
+		schemaDef: sd,
+		root:      s.root,
+	}, nil
+}
+
+// SchemaDefinition returns the schema definition the schema was compiled from.
+func (cs *CompiledSchema) SchemaDefinition() *parquetschema.SchemaDefinition {
+	return cs.schemaDef
+}
+
+// WithCompiledSchema sets the schema of the parquet file from a compiled schema. Only the
+// column stores that hold the data of the writer are created, everything else is shared
+// with the compiled schema.
+func WithCompiledSchema(cs *CompiledSchema) FileWriterOption {
+	return func(fw *FileWriter) {
+		root, err := cs.root.cloneWithNewStore()
+		if err != nil {
+			panic(err)
+		}
+
+		fw.SchemaWriter = &schema{
+			schemaDef: cs.schemaDef,
+			root:      root,
+		}
+	}
+}
+
+// cloneWithNewStore clones the column and all its children. The schema elements and
+// column parameters are shared with the original column, but every data column of the
+// clone gets a new and empty column store.
+func (c *Column) cloneWithNewStore() (*Column, error) {
+	clone := *c
+
+	if c.data != nil {
+		store, err := getColumnStore(c.element, c.params)
+		if err != nil {
+			return nil, err
+		}
+		store.reset(c.rep, c.maxR, c.maxD)
+		clone.data = store
+		return &clone, nil
+	}
+
+	clone.children = make([]*Column, 0, len(c.children))
+	for _, child := range c.children {
+		childClone, err := child.cloneWithNewStore()
+		if err != nil {
+			return nil, err
+		}
+		clone.children = append(clone.children, childClone)
+	}
+
+	return &clone, nil
+}
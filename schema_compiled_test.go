@@ -0,0 +1,76 @@
+package goparquet
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompiledSchema(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional group tags (LIST) {
+			repeated group list {
+				required binary element (STRING);
+			}
+		}
+		required fixed_len_byte_array(4) code;
+	}`)
+	require.NoError(t, err)
+
+	cs, err := CompileSchema(sd)
+	require.NoError(t, err)
+	require.Equal(t, sd, cs.SchemaDefinition())
+
+	const numWriters = 4
+	bufs := make([]*bytes.Buffer, numWriters)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWriters; i++ {
+		bufs[i] = &bytes.Buffer{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := NewFileWriter(bufs[i], WithCompiledSchema(cs))
+			for j := 0; j < 10; j++ {
+				if err := w.AddData(map[string]interface{}{
+					"id":   int64(i*100 + j),
+					"tags": map[string]interface{}{"list": []map[string]interface{}{{"element": []byte(fmt.Sprint(i))}}},
+					"code": []byte("abcd"),
+				}); err != nil {
+					panic(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				panic(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	expected := &bytes.Buffer{}
+	w := NewFileWriter(expected, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(0), "code": []byte("abcd")}))
+	require.NoError(t, w.Close())
+
+	expectedReader, err := NewFileReader(bytes.NewReader(expected.Bytes()))
+	require.NoError(t, err)
+
+	for i := 0; i < numWriters; i++ {
+		r, err := NewFileReader(bytes.NewReader(bufs[i].Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, expectedReader.meta.Schema, r.meta.Schema)
+		require.Equal(t, int64(10), r.NumRows())
+
+		for j := 0; j < 10; j++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, int64(i*100+j), row["id"])
+			require.Equal(t, map[string]interface{}{"list": []map[string]interface{}{{"element": []byte(fmt.Sprint(i))}}}, row["tags"])
+		}
+	}
+}
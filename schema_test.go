@@ -2,6 +2,7 @@ package goparquet
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"math/rand"
 	"testing"
@@ -379,3 +380,134 @@ func readAllData(t *testing.T, data []byte) {
 		}
 	}
 }
+
+func TestSchemaLevelLimits(t *testing.T) {
+	nestedSchema := func(depth int, rep parquet.FieldRepetitionType) []*parquet.SchemaElement {
+		one := int32(1)
+		elems := []*parquet.SchemaElement{{Name: "msg", NumChildren: &one}}
+		for i := 0; i < depth; i++ {
+			elems = append(elems, &parquet.SchemaElement{
+				Name:           "g",
+				RepetitionType: parquet.FieldRepetitionTypePtr(rep),
+				NumChildren:    &one,
+			})
+		}
+		return append(elems, &parquet.SchemaElement{
+			Name:           "v",
+			Type:           parquet.TypePtr(parquet.Type_INT32),
+			RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+		})
+	}
+
+	requireLevelError := func(err error, level string, max uint16) {
+		var levelErr *LevelLimitExceededError
+		require.True(t, errors.As(err, &levelErr), "unexpected error %v", err)
+		require.Equal(t, level, levelErr.Level)
+		require.Equal(t, max, levelErr.Max)
+	}
+
+	s, err := makeSchema(&parquet.FileMetaData{Schema: nestedSchema(3, parquet.FieldRepetitionType_REPEATED)}, 3, DuplicateColumnNamesError)
+	require.NoError(t, err)
+	require.Equal(t, uint16(3), s.Columns()[0].MaxDefinitionLevel())
+	require.Equal(t, uint16(3), s.Columns()[0].MaxRepetitionLevel())
+
+	_, err = makeSchema(&parquet.FileMetaData{Schema: nestedSchema(4, parquet.FieldRepetitionType_REPEATED)}, 3, DuplicateColumnNamesError)
+	requireLevelError(err, "definition", 3)
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		repeated group a {
+			repeated group b {
+				optional int64 c;
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"a": []map[string]interface{}{{"b": []map[string]interface{}{{"c": int64(1)}}}}}))
+	require.NoError(t, w.Close())
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithMaxLevel(3))
+	require.NoError(t, err)
+	_, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithMaxLevel(2))
+	requireLevelError(err, "definition", 2)
+}
+
+func TestSchemaString(t *testing.T) {
+	schemaText := `message test {
+  required int64 id = 1;
+  optional binary name (STRING) = 2;
+  optional int64 ts (TIMESTAMP(MILLIS, true));
+  optional group tags (LIST) = 3 {
+    repeated group list {
+      required int32 element (INT(16, true));
+    }
+  }
+}
+`
+	sd, err := parquetschema.ParseSchemaDefinition(schemaText)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.Equal(t, schemaText, w.String())
+	require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, schemaText, r.String())
+
+	w = NewFileWriter(&bytes.Buffer{})
+	store, err := NewInt64Store(parquet.Encoding_PLAIN, true, &ColumnParameters{})
+	require.NoError(t, err)
+	require.NoError(t, w.AddColumn("foo", NewDataColumn(store, parquet.FieldRepetitionType_REQUIRED)))
+	require.Equal(t, "message msg {\n  required int64 foo;\n}\n", w.String())
+}
+
+func TestFieldIDs(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id = 1;
+		optional binary name (STRING) = 2;
+		optional group info = 3 {
+			optional int32 age = 4;
+			optional int32 size = 5;
+		}
+		optional int32 other;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":    int64(1),
+		"name":  []byte("foo"),
+		"info":  map[string]interface{}{"age": int32(2), "size": int32(3)},
+		"other": int32(4),
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithColumnsByFieldID(2, 3), WithColumns("id"))
+	require.NoError(t, err)
+
+	// the field IDs are written to the schema of the file.
+	require.Equal(t, sd.String(), r.GetSchemaDefinition().String())
+
+	require.Equal(t, int32(4), *r.GetColumnByName("info.age").FieldID())
+	require.Nil(t, r.GetColumnByName("other").FieldID())
+	require.Equal(t, "info.size", r.GetColumnByFieldID(5).FlatName())
+	require.Equal(t, "info", r.GetColumnByFieldID(3).FlatName())
+	require.Nil(t, r.GetColumnByFieldID(6))
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"id":   int64(1),
+		"name": []byte("foo"),
+		"info": map[string]interface{}{"age": int32(2), "size": int32(3)},
+	}, row)
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithColumnsByFieldID(6))
+	require.Error(t, err)
+}
@@ -0,0 +1,276 @@
+package goparquet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// SortingColumn describes a column by which the records of the row groups are sorted.
+type SortingColumn struct {
+	// Column is the name of the column in dotted notation. It needs to be a data column that is
+	// neither repeated nor part of a repeated group.
+	Column string
+	// Descending is true if the values are sorted in descending order.
+	Descending bool
+	// NullsFirst is true if null values come before all other values.
+	NullsFirst bool
+}
+
+// SortMode defines how the FileWriter ensures that the records of a row group are sorted by the
+// sorting columns.
+type SortMode int
+
+const (
+	// SortUnverified only declares the sorting columns in the meta data of the row groups, the
+	// caller is responsible for adding the records in order. This is the default.
+	SortUnverified SortMode = iota
+	// SortVerify makes AddData return an error for a record that isn't sorted after the previous
+	// record of the row group. The record isn't added in that case.
+	SortVerify
+	// SortBuffer keeps the records of the current row group and sorts them before the row group
+	// is flushed, so that they can be added in any order. The records must not be modified after
+	// they were added.
+	SortBuffer
+)
+
+// WithSortingColumns declares that the records of every row group are sorted by the columns, so
+// that query engines can exploit the ordering. The first column is the primary sort key. The
+// sorting columns are written to the meta data of the row groups flushed by the FileWriter, but
+// not of row groups written with WriteEncodedRowGroup or CopyChunk. Values are compared in the
+// same order as the min and max statistics, NaN values are greater than all other values. The
+// mode determines whether the order of the records is verified or established by the FileWriter.
+// Column batches can only be written with SortUnverified.
+func WithSortingColumns(mode SortMode, columns ...SortingColumn) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.sorting = &rowGroupSorting{
+			mode:    mode,
+			columns: columns,
+		}
+	}
+}
+
+// rowGroupSorting contains the sorting columns of a FileWriter and the state that is required to
+// verify or establish the order of the records of the current row group.
+type rowGroupSorting struct {
+	mode    SortMode
+	columns []SortingColumn
+
+	// cols contains the resolved sorting columns, it is nil until the first record was added.
+	cols []*Column
+	cmps []BinaryComparator
+
+	// last is the sort key of the last record of the current row group, which is only kept with
+	// SortVerify.
+	last []interface{}
+	// rows and keys contain the records of the current row group and their sort keys, which are
+	// only kept with SortBuffer.
+	rows []map[string]interface{}
+	keys [][]interface{}
+}
+
+// resolve looks up the sorting columns in the schema of fw.
+func (s *rowGroupSorting) resolve(fw *FileWriter) error {
+	if s.cols != nil {
+		return nil
+	}
+
+	cols := make([]*Column, 0, len(s.columns))
+	cmps := make([]BinaryComparator, 0, len(s.columns))
+	for _, sc := range s.columns {
+		col := fw.GetColumnByName(sc.Column)
+		if col == nil || !col.DataColumn() {
+			return fmt.Errorf("sorting column %q not found", sc.Column)
+		}
+		if col.MaxRepetitionLevel() > 0 {
+			return fmt.Errorf("sorting column %s is repeated", sc.Column)
+		}
+		if col.Element().GetType() == parquet.Type_INT96 {
+			return fmt.Errorf("sorting column %s has the unsupported type %s", sc.Column, parquet.Type_INT96)
+		}
+		cols = append(cols, col)
+		cmps = append(cmps, fw.statsOpts.comparator(col))
+	}
+
+	s.cols, s.cmps = cols, cmps
+	return nil
+}
+
+// metaData returns the sorting columns for the meta data of a row group.
+func (s *rowGroupSorting) metaData() []*parquet.SortingColumn {
+	ret := make([]*parquet.SortingColumn, len(s.cols))
+	for i, col := range s.cols {
+		ret[i] = &parquet.SortingColumn{
+			ColumnIdx:  int32(col.Index()),
+			Descending: s.columns[i].Descending,
+			NullsFirst: s.columns[i].NullsFirst,
+		}
+	}
+	return ret
+}
+
+// key returns the values of the sorting columns of the record m, with nil for null values.
+func (s *rowGroupSorting) key(m map[string]interface{}) []interface{} {
+	key := make([]interface{}, len(s.cols))
+	for i, col := range s.cols {
+		var v interface{} = m
+		for _, name := range col.pathArray() {
+			data, ok := v.(map[string]interface{})
+			if !ok {
+				v = nil
+				break
+			}
+			v = data[name]
+		}
+		// decimals and dates are compared in the physical type they are written as.
+		if converted, err := col.getColumnStore().convertValue(v); err == nil {
+			v = converted
+		}
+		if b, ok := v.([]byte); ok && b == nil {
+			v = nil
+		}
+		key[i] = v
+	}
+	return key
+}
+
+// compare compares the sort keys a and b and returns a negative number if a comes before b, a
+// positive number if a comes after b, and 0 if they are equal.
+func (s *rowGroupSorting) compare(a, b []interface{}) int {
+	for i, col := range s.cols {
+		sc := s.columns[i]
+		switch {
+		case a[i] == nil && b[i] == nil:
+			continue
+		case a[i] == nil || b[i] == nil:
+			if (a[i] == nil) == sc.NullsFirst {
+				return -1
+			}
+			return 1
+		}
+
+		c := compareSortValues(col.Element(), a[i], b[i], s.cmps[i])
+		if sc.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareSortValues compares two non-null values of a column like the statistics do, with NaN
+// values being greater than all other values.
+func compareSortValues(elem *parquet.SchemaElement, a, b interface{}, cmp BinaryComparator) int {
+	if aNaN, bNaN := isNaN(a), isNaN(b); aNaN || bNaN {
+		switch {
+		case aNaN && bNaN:
+			return 0
+		case aNaN:
+			return 1
+		}
+		return -1
+	}
+	return compareValues(elem, a, b, cmp)
+}
+
+func isNaN(v interface{}) bool {
+	switch typed := v.(type) {
+	case float32:
+		return math.IsNaN(float64(typed))
+	case float64:
+		return math.IsNaN(typed)
+	}
+	return false
+}
+
+// addData adds the record m to fw and verifies or records its position in the sort order.
+func (s *rowGroupSorting) addData(fw *FileWriter, m map[string]interface{}) error {
+	if err := s.resolve(fw); err != nil {
+		return err
+	}
+
+	key := s.key(m)
+	if s.mode == SortVerify && s.last != nil && s.compare(s.last, key) > 0 {
+		names := make([]string, len(s.columns))
+		for i := range s.columns {
+			names[i] = s.columns[i].Column
+		}
+		return fmt.Errorf("record with sort key %v is not sorted by %s after the previous record with sort key %v", key, strings.Join(names, ", "), s.last)
+	}
+
+	if err := fw.SchemaWriter.AddData(m); err != nil {
+		return err
+	}
+
+	switch s.mode {
+	case SortVerify:
+		s.last = key
+	case SortBuffer:
+		s.rows = append(s.rows, m)
+		s.keys = append(s.keys, key)
+	}
+	return nil
+}
+
+// sortRowGroup sorts the records of the current row group of fw if they were buffered and aren't
+// sorted already, by adding them again in the right order.
+func (s *rowGroupSorting) sortRowGroup(fw *FileWriter) error {
+	if s.mode != SortBuffer || len(s.rows) == 0 {
+		return nil
+	}
+
+	if int64(len(s.rows)) != fw.rowGroupNumRecords() {
+		return errors.New("can't sort row group that contains records that weren't buffered")
+	}
+
+	idx := make([]int, len(s.rows))
+	for i := range idx {
+		idx[i] = i
+	}
+	less := func(i, j int) bool {
+		return s.compare(s.keys[idx[i]], s.keys[idx[j]]) < 0
+	}
+	if sort.SliceIsSorted(idx, less) {
+		return nil
+	}
+	sort.SliceStable(idx, less)
+
+	fw.SchemaWriter.resetData()
+	for _, i := range idx {
+		if err := fw.SchemaWriter.AddData(s.rows[i]); err != nil {
+			return fmt.Errorf("adding sorted record failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// reset discards the state of the current row group.
+func (s *rowGroupSorting) reset() {
+	s.last = nil
+	s.rows = nil
+	s.keys = nil
+}
+
+// mark returns the state of the current row group so that it can be restored by truncate.
+func (s *rowGroupSorting) mark() sortingMark {
+	return sortingMark{last: s.last, rows: len(s.rows)}
+}
+
+// truncate restores the state of the current row group of the mark m.
+func (s *rowGroupSorting) truncate(m sortingMark) {
+	s.last = m.last
+	s.rows = s.rows[:m.rows]
+	s.keys = s.keys[:m.rows]
+}
+
+// sortingMark is the state of the current row group of a rowGroupSorting.
+type sortingMark struct {
+	last []interface{}
+	rows int
+}
@@ -0,0 +1,138 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortingColumns(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional binary name (STRING);
+		required int64 id;
+		repeated int32 tags;
+	}`)
+	require.NoError(t, err)
+
+	sorting := []SortingColumn{
+		{Column: "name", NullsFirst: true},
+		{Column: "id", Descending: true},
+	}
+
+	records := []map[string]interface{}{
+		{"name": []byte("b"), "id": int64(1)},
+		{"id": int64(2)},
+		{"name": []byte("a"), "id": int64(3)},
+		{"name": []byte("b"), "id": int64(4)},
+		{"id": int64(5)},
+	}
+
+	sorted := []map[string]interface{}{
+		{"id": int64(5)},
+		{"id": int64(2)},
+		{"name": []byte("a"), "id": int64(3)},
+		{"name": []byte("b"), "id": int64(4)},
+		{"name": []byte("b"), "id": int64(1)},
+	}
+
+	readRows := func(t *testing.T, data []byte) (*FileReader, []map[string]interface{}) {
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		var rows []map[string]interface{}
+		for {
+			row, err := r.NextRow()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			rows = append(rows, row)
+		}
+		return r, rows
+	}
+
+	t.Run("buffer", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd), WithSortingColumns(SortBuffer, sorting...))
+		for _, rec := range records {
+			require.NoError(t, w.AddData(rec))
+		}
+		require.NoError(t, w.BeginRowGroup())
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("0"), "id": int64(6)}))
+		require.NoError(t, w.Rollback())
+		require.NoError(t, w.Close())
+
+		r, rows := readRows(t, buf.Bytes())
+		require.Equal(t, sorted, rows)
+		require.Equal(t, []*parquet.SortingColumn{
+			{ColumnIdx: 0, NullsFirst: true},
+			{ColumnIdx: 1, Descending: true},
+		}, r.meta.RowGroups[0].SortingColumns)
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd), WithSortingColumns(SortVerify, sorting...), WithMaxRowGroupRows(3))
+		for _, rec := range sorted {
+			require.NoError(t, w.AddData(rec))
+		}
+		// the order is only verified within a row group.
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("c"), "id": int64(7)}))
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("a"), "id": int64(8)}))
+		require.Error(t, w.AddData(map[string]interface{}{"name": []byte("a"), "id": int64(9)}))
+		require.Error(t, w.AddData(map[string]interface{}{"id": int64(10)}))
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("a"), "id": int64(7)}))
+		require.NoError(t, w.Close())
+
+		r, rows := readRows(t, buf.Bytes())
+		require.Len(t, rows, 8)
+		require.Equal(t, 3, r.RowGroupCount())
+		for _, rg := range r.meta.RowGroups {
+			require.Len(t, rg.SortingColumns, 2)
+		}
+	})
+
+	t.Run("unverified", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd), WithSortingColumns(SortUnverified, SortingColumn{Column: "id"}))
+		for _, rec := range records {
+			require.NoError(t, w.AddData(rec))
+		}
+		require.NoError(t, w.Close())
+
+		r, rows := readRows(t, buf.Bytes())
+		require.Equal(t, records, rows)
+		require.Equal(t, []*parquet.SortingColumn{{ColumnIdx: 1}}, r.meta.RowGroups[0].SortingColumns)
+	})
+
+	t.Run("invalid columns", func(t *testing.T) {
+		for _, col := range []string{"unknown", "tags"} {
+			w := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithSortingColumns(SortVerify, SortingColumn{Column: col}))
+			require.Error(t, w.AddData(records[0]), col)
+		}
+
+		w := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithSortingColumns(SortBuffer, SortingColumn{Column: "id"}))
+		require.Error(t, w.WriteColumnBatch("id", []int64{1}, nil, nil))
+	})
+}
+
+func TestCompareSortValues(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int32 a;
+		required double b;
+		required binary c (DECIMAL(4, 2));
+	}`)
+	require.NoError(t, err)
+
+	elems := sd.RootColumn.Children
+	nan := float64(0)
+	nan = nan / nan
+
+	require.Equal(t, -1, compareSortValues(elems[0].SchemaElement, int32(-2), int32(1), BinaryComparatorUnsigned))
+	require.Equal(t, 1, compareSortValues(elems[1].SchemaElement, nan, 1.5, BinaryComparatorUnsigned))
+	require.Equal(t, 0, compareSortValues(elems[1].SchemaElement, nan, nan, BinaryComparatorUnsigned))
+	require.Equal(t, -1, compareSortValues(elems[2].SchemaElement, []byte{0xff}, []byte{0x01}, BinaryComparatorUnsigned))
+}
@@ -0,0 +1,447 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// StatisticsKey is the key of the pseudo-column that is added to every row returned by NextRow if
+// statistics columns were requested using WithStatisticsColumns.
+const StatisticsKey = "_stats"
+
+// ColumnStatistics is a summary of the statistics of a single column across
+// multiple row groups, possibly from multiple files.
+type ColumnStatistics struct {
+	// Column is the flat name of the column in dotted notation.
+	Column string
+	// Type is the physical type of the column.
+	Type parquet.Type
+	// NumValues is the total number of values including nulls.
+	NumValues int64
+	// RowGroups is the number of row groups that contributed to this summary.
+	RowGroups int
+
+	// MinValue and MaxValue are the smallest resp. largest value of all row groups, encoded
+	// like the min_value and max_value fields of the parquet statistics. Both are nil if at
+	// least one row group didn't provide min and max values, as the summary can't be trusted
+	// in that case.
+	MinValue []byte
+	MaxValue []byte
+	// NullCount is the total number of nulls, or nil if at least one row group didn't
+	// provide a null count.
+	NullCount *int64
+
+	minMaxMissing    bool
+	nullCountMissing bool
+}
+
+func (cs *ColumnStatistics) merge(elem *parquet.SchemaElement, numValues int64, stats *parquet.Statistics, cmp BinaryComparator) {
+	cs.RowGroups++
+	cs.NumValues += numValues
+
+	if stats == nil || stats.NullCount == nil {
+		cs.nullCountMissing = true
+		cs.NullCount = nil
+	} else if !cs.nullCountMissing {
+		n := stats.GetNullCount()
+		if cs.NullCount != nil {
+			n += *cs.NullCount
+		}
+		cs.NullCount = &n
+	}
+
+	min, max := statisticsMinMax(elem, stats, cmp)
+
+	// a row group that only contains nulls has no min and max value, but it doesn't
+	// invalidate the min and max values of the other row groups.
+	if min == nil || max == nil {
+		if stats != nil && stats.NullCount != nil && stats.GetNullCount() == numValues {
+			return
+		}
+		cs.minMaxMissing = true
+		cs.MinValue, cs.MaxValue = nil, nil
+		return
+	}
+
+	if cs.minMaxMissing {
+		return
+	}
+
+	if cs.MinValue == nil || compareStatValues(elem, min, cs.MinValue, cmp) < 0 {
+		cs.MinValue = min
+	}
+	if cs.MaxValue == nil || compareStatValues(elem, max, cs.MaxValue, cmp) > 0 {
+		cs.MaxValue = max
+	}
+}
+
+func isUnsignedElement(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil && elem.LogicalType.INTEGER != nil {
+		return !elem.LogicalType.INTEGER.IsSigned
+	}
+	switch elem.GetConvertedType() {
+	case parquet.ConvertedType_UINT_8, parquet.ConvertedType_UINT_16, parquet.ConvertedType_UINT_32, parquet.ConvertedType_UINT_64:
+		return true
+	}
+	return false
+}
+
+func isDecimalElement(elem *parquet.SchemaElement) bool {
+	if elem.LogicalType != nil && elem.LogicalType.DECIMAL != nil {
+		return true
+	}
+	return elem.ConvertedType != nil && *elem.ConvertedType == parquet.ConvertedType_DECIMAL
+}
+
+// compareStatValues compares two plain encoded statistics values of the column described by elem.
+// Binary decimals are compared as signed integers, other binary values are compared using cmp.
+// Values that can't be decoded are compared byte-wise.
+func compareStatValues(elem *parquet.SchemaElement, a, b []byte, cmp BinaryComparator) int {
+	switch elem.GetType() {
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		if isDecimalElement(elem) {
+			return compareDecimals(a, b)
+		}
+		return cmp.Compare(a, b)
+	case parquet.Type_INT32:
+		if len(a) != 4 || len(b) != 4 {
+			break
+		}
+		x, y := binary.LittleEndian.Uint32(a), binary.LittleEndian.Uint32(b)
+		if isUnsignedElement(elem) {
+			return compareUint64(uint64(x), uint64(y))
+		}
+		return compareInt64(int64(int32(x)), int64(int32(y)))
+	case parquet.Type_INT64:
+		if len(a) != 8 || len(b) != 8 {
+			break
+		}
+		x, y := binary.LittleEndian.Uint64(a), binary.LittleEndian.Uint64(b)
+		if isUnsignedElement(elem) {
+			return compareUint64(x, y)
+		}
+		return compareInt64(int64(x), int64(y))
+	case parquet.Type_FLOAT:
+		if len(a) != 4 || len(b) != 4 {
+			break
+		}
+		return compareFloat64(
+			float64(math.Float32frombits(binary.LittleEndian.Uint32(a))),
+			float64(math.Float32frombits(binary.LittleEndian.Uint32(b))),
+		)
+	case parquet.Type_DOUBLE:
+		if len(a) != 8 || len(b) != 8 {
+			break
+		}
+		return compareFloat64(
+			math.Float64frombits(binary.LittleEndian.Uint64(a)),
+			math.Float64frombits(binary.LittleEndian.Uint64(b)),
+		)
+	}
+
+	return bytes.Compare(a, b)
+}
+
+// compareValues compares two non-null and non-NaN values of the column described by elem like
+// compareStatValues does with their encoded form, without encoding them.
+func compareValues(elem *parquet.SchemaElement, a, b interface{}, cmp BinaryComparator) int {
+	switch x := a.(type) {
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case y:
+				return -1
+			}
+			return 1
+		}
+	case int32:
+		if y, ok := b.(int32); ok {
+			if isUnsignedElement(elem) {
+				return compareUint64(uint64(uint32(x)), uint64(uint32(y)))
+			}
+			return compareInt64(int64(x), int64(y))
+		}
+	case int64:
+		if y, ok := b.(int64); ok {
+			if isUnsignedElement(elem) {
+				return compareUint64(uint64(x), uint64(y))
+			}
+			return compareInt64(x, y)
+		}
+	case float32:
+		if y, ok := b.(float32); ok {
+			return compareFloat64(float64(x), float64(y))
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return compareFloat64(x, y)
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return compareStatValues(elem, x, y, cmp)
+		}
+	}
+	return compareStatValues(elem, encodeStatValue(a), encodeStatValue(b), cmp)
+}
+
+// compareDecimals compares two big-endian two's complement integers of arbitrary length.
+func compareDecimals(a, b []byte) int {
+	negA := len(a) > 0 && a[0]&0x80 != 0
+	negB := len(b) > 0 && b[0]&0x80 != 0
+	if negA != negB {
+		if negA {
+			return -1
+		}
+		return 1
+	}
+
+	var pad byte
+	if negA {
+		pad = 0xff
+	}
+
+	// both values have the same sign, so they are compared byte-wise after sign-extending the
+	// shorter one.
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		x, y := pad, pad
+		if j := i - (n - len(a)); j >= 0 {
+			x = a[j]
+		}
+		if j := i - (n - len(b)); j >= 0 {
+			y = b[j]
+		}
+		if x != y {
+			return compareUint64(uint64(x), uint64(y))
+		}
+	}
+	return 0
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// RollupStatistics merges the statistics of all row groups of the file into a single summary
+// per column. The result is keyed by the flat column name in dotted notation.
+func (f *FileReader) RollupStatistics() (map[string]*ColumnStatistics, error) {
+	return RollupStatistics(f)
+}
+
+// RollupStatistics merges the statistics of all row groups of all provided files into a single
+// summary per column, e.g. to publish the statistics of a whole dataset in a catalog. All files
+// need to agree on the physical type of columns with the same name. Columns that are only
+// present in some of the files are summarized over these files only.
+func RollupStatistics(readers ...*FileReader) (map[string]*ColumnStatistics, error) {
+	result := make(map[string]*ColumnStatistics)
+
+	for idx, r := range readers {
+		for rgIdx, rg := range r.meta.RowGroups {
+			for _, chunk := range rg.Columns {
+				if chunk.MetaData == nil {
+					return nil, errors.Errorf("file %d, row group %d: column chunk without meta data", idx, rgIdx)
+				}
+
+				name := strings.Join(chunk.MetaData.PathInSchema, ".")
+				col := r.GetColumnByName(name)
+				if col == nil {
+					return nil, errors.Errorf("file %d, row group %d: column %s not found in schema", idx, rgIdx, name)
+				}
+
+				cs, ok := result[name]
+				if !ok {
+					cs = &ColumnStatistics{Column: name, Type: chunk.MetaData.Type}
+					result[name] = cs
+				}
+
+				if cs.Type != chunk.MetaData.Type {
+					return nil, errors.Errorf("file %d: column %s has type %s but %s was expected", idx, name, chunk.MetaData.Type, cs.Type)
+				}
+
+				cs.merge(col.Element(), chunk.MetaData.NumValues, r.writerVersion.trustedStatistics(col.Element().GetType(), chunk.MetaData.Statistics), r.opts.comparator(col))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// decodeStatValue decodes a plain encoded statistics value of the column described by elem into
+// the same type the values of the column are read as.
+func decodeStatValue(elem *parquet.SchemaElement, value []byte) (interface{}, error) {
+	switch elem.GetType() {
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		// byte arrays are stored without length prefix in the statistics.
+		return append([]byte(nil), value...), nil
+	}
+
+	dec, err := getValuesDecoder(parquet.Encoding_PLAIN, elem, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dec.init(bytes.NewReader(value)); err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 1)
+	if n, err := dec.decodeValues(values); n != 1 {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return values[0], nil
+}
+
+// rowGroupStatistics returns the min and max values of the provided columns in the row group as
+// min_<column> and max_<column>. Columns without statistics are omitted. The statistics of binary
+// columns are only returned if they were written in the order of their comparator in opts, and
+// statistics that the writer is known to have written wrong are omitted.
+func rowGroupStatistics(schema SchemaReader, rg *parquet.RowGroup, columns []string, writer *WriterVersion, opts *fileReaderOptions) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, 2*len(columns))
+	for _, name := range columns {
+		col := schema.GetColumnByName(name)
+		if col == nil {
+			return nil, errors.Errorf("column %q not found", name)
+		}
+
+		if len(rg.Columns) <= col.Index() || rg.Columns[col.Index()].MetaData == nil {
+			continue
+		}
+
+		stats := writer.trustedStatistics(col.Element().GetType(), rg.Columns[col.Index()].MetaData.Statistics)
+		min, max := statisticsMinMax(col.Element(), stats, opts.comparator(col))
+		if min == nil || max == nil {
+			continue
+		}
+
+		minValue, err := decodeStatValue(col.Element(), min)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding min value of column %s failed", name)
+		}
+		maxValue, err := decodeStatValue(col.Element(), max)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding max value of column %s failed", name)
+		}
+
+		result["min_"+name] = minValue
+		result["max_"+name] = maxValue
+	}
+
+	return result, nil
+}
+
+// FindRowGroups returns the range [first, end) of the row groups whose column chunks of the
+// column colName may contain value, using a binary search over the min and max statistics of the
+// chunks. The row groups need to be sorted by the column in ascending order across row groups,
+// i.e. neither the min nor the max statistics of a chunk may be less than the ones of the
+// previous chunk, otherwise the result is undefined. Binary values are compared in the order of
+// the column's comparator, see WithColumnPruningBinaryComparator, so that columns with
+// domain-specific orders registered using RegisterBinaryComparator can be searched. value needs
+// to be of the Go type of the column's physical type, e.g. int64 or []byte; strings are accepted
+// for binary columns. An error is returned if a chunk that is visited by the search has no usable
+// statistics. first equals end if no row group can contain value.
+func (f *FileReader) FindRowGroups(colName string, value interface{}) (first, end int, err error) {
+	col := f.GetColumnByName(colName)
+	if col == nil || !col.DataColumn() {
+		return 0, 0, errors.Errorf("column %q not found", colName)
+	}
+	elem := col.Element()
+
+	if s, ok := value.(string); ok {
+		value = []byte(s)
+	}
+	v := encodeStatValue(value)
+	if v == nil || !isPhysicalValue(elem.GetType(), value) {
+		return 0, 0, errors.Errorf("unsupported value of type %T for column %s of type %s", value, colName, elem.GetType())
+	}
+
+	cmp := f.opts.comparator(col)
+	bounds := func(rowGroup int) (min, max []byte) {
+		if err != nil {
+			return nil, nil
+		}
+		rg := f.meta.RowGroups[rowGroup]
+		if len(rg.Columns) > col.Index() && rg.Columns[col.Index()].MetaData != nil {
+			stats := f.writerVersion.trustedStatistics(elem.GetType(), rg.Columns[col.Index()].MetaData.Statistics)
+			min, max = statisticsMinMax(elem, stats, cmp)
+		}
+		if min == nil || max == nil {
+			err = errors.Errorf("row group %d has no statistics for column %s", rowGroup, colName)
+		}
+		return min, max
+	}
+
+	n := len(f.meta.RowGroups)
+	first = sort.Search(n, func(i int) bool {
+		_, max := bounds(i)
+		return err != nil || compareStatValues(elem, max, v, cmp) >= 0
+	})
+	end = first + sort.Search(n-first, func(i int) bool {
+		min, _ := bounds(first + i)
+		return err != nil || compareStatValues(elem, min, v, cmp) > 0
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, end, nil
+}
+
+// isPhysicalValue returns true if v is of the Go type of the physical type typ.
+func isPhysicalValue(typ parquet.Type, v interface{}) bool {
+	switch v.(type) {
+	case bool:
+		return typ == parquet.Type_BOOLEAN
+	case int32:
+		return typ == parquet.Type_INT32
+	case int64:
+		return typ == parquet.Type_INT64
+	case float32:
+		return typ == parquet.Type_FLOAT
+	case float64:
+		return typ == parquet.Type_DOUBLE
+	case []byte:
+		return isBinaryType(typ)
+	}
+	return false
+}
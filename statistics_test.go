@@ -0,0 +1,296 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func int64Bytes(v int64) []byte {
+	ret := make([]byte, 8)
+	binary.LittleEndian.PutUint64(ret, uint64(v))
+	return ret
+}
+
+func TestRollupStatistics(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+		optional int64 bar;
+	}`)
+	require.NoError(t, err)
+
+	writeFile := func(rowGroups ...[]map[string]interface{}) *FileReader {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		for _, rg := range rowGroups {
+			for _, row := range rg {
+				require.NoError(t, w.AddData(row))
+			}
+			require.NoError(t, w.FlushRowGroup())
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		return r
+	}
+
+	r1 := writeFile(
+		[]map[string]interface{}{{"foo": int64(-5), "bar": int64(3)}, {"foo": int64(7)}},
+		[]map[string]interface{}{{"foo": int64(100)}},
+	)
+	r2 := writeFile(
+		[]map[string]interface{}{{"foo": int64(-20), "bar": int64(42)}},
+	)
+
+	stats, err := r1.RollupStatistics()
+	require.NoError(t, err)
+	require.Equal(t, int64Bytes(-5), stats["foo"].MinValue)
+	require.Equal(t, int64Bytes(100), stats["foo"].MaxValue)
+	require.Equal(t, int64(3), stats["foo"].NumValues)
+	require.Equal(t, 2, stats["foo"].RowGroups)
+	require.Equal(t, int64Bytes(3), stats["bar"].MinValue)
+	require.Equal(t, int64Bytes(3), stats["bar"].MaxValue)
+	require.Equal(t, int64(2), *stats["bar"].NullCount)
+
+	stats, err = RollupStatistics(r1, r2)
+	require.NoError(t, err)
+	require.Equal(t, int64Bytes(-20), stats["foo"].MinValue)
+	require.Equal(t, int64Bytes(100), stats["foo"].MaxValue)
+	require.Equal(t, int64Bytes(42), stats["bar"].MaxValue)
+	require.Equal(t, int64(2), *stats["bar"].NullCount)
+
+	// a row group without statistics invalidates min, max and null count.
+	r2.meta.RowGroups[0].Columns[0].MetaData.Statistics = nil
+	stats, err = RollupStatistics(r1, r2)
+	require.NoError(t, err)
+	require.Nil(t, stats["foo"].MinValue)
+	require.Nil(t, stats["foo"].MaxValue)
+	require.Nil(t, stats["foo"].NullCount)
+	require.Equal(t, int64(4), stats["foo"].NumValues)
+}
+
+func TestCompareStatValues(t *testing.T) {
+	signed := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32)}
+	unsigned := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT32), ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_32)}
+
+	minusOne := []byte{0xff, 0xff, 0xff, 0xff}
+	one := []byte{1, 0, 0, 0}
+
+	require.Equal(t, -1, compareStatValues(signed, minusOne, one, BinaryComparatorUnsigned))
+	require.Equal(t, 1, compareStatValues(unsigned, minusOne, one, BinaryComparatorUnsigned))
+	require.Equal(t, 0, compareStatValues(unsigned, one, one, BinaryComparatorUnsigned))
+
+	decimal := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_BYTE_ARRAY), ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL)}
+	require.Equal(t, -1, compareStatValues(decimal, []byte{0xff}, []byte{0x01}, BinaryComparatorUnsigned))
+	require.Equal(t, 1, compareStatValues(decimal, []byte{0x01, 0x00}, []byte{0x7f}, BinaryComparatorUnsigned))
+	require.Equal(t, -1, compareStatValues(decimal, []byte{0xfe, 0xff}, []byte{0xff}, BinaryComparatorUnsigned))
+	require.Equal(t, 0, compareStatValues(decimal, []byte{0xff, 0xff}, []byte{0xff}, BinaryComparatorUnsigned))
+	require.Equal(t, 0, compareStatValues(decimal, []byte{0x00, 0x05}, []byte{0x05}, BinaryComparatorUnsigned))
+}
+
+func TestValuesMinMax(t *testing.T) {
+	signed := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT64)}
+	unsigned := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_INT64), ConvertedType: parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_64)}
+	values := []interface{}{int64(3), int64(-1), nil, int64(7)}
+
+	min, max := valuesMinMax(signed, values, BinaryComparatorUnsigned)
+	require.Equal(t, int64Bytes(-1), min)
+	require.Equal(t, int64Bytes(7), max)
+
+	min, max = valuesMinMax(unsigned, values, BinaryComparatorUnsigned)
+	require.Equal(t, int64Bytes(3), min)
+	require.Equal(t, int64Bytes(-1), max)
+
+	double := &parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_DOUBLE)}
+	min, max = valuesMinMax(double, []interface{}{math.NaN(), 2.5, -1.5}, BinaryComparatorUnsigned)
+	require.Equal(t, -1.5, math.Float64frombits(binary.LittleEndian.Uint64(min)))
+	require.Equal(t, 2.5, math.Float64frombits(binary.LittleEndian.Uint64(max)))
+
+	min, max = valuesMinMax(double, []interface{}{math.NaN()}, BinaryComparatorUnsigned)
+	require.Nil(t, min)
+	require.Nil(t, max)
+
+	// only the results are encoded, the values are compared without allocations.
+	values = make([]interface{}, 1000)
+	for i := range values {
+		values[i] = int64(i * 7 % 1000)
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		valuesMinMax(signed, values, BinaryComparatorUnsigned)
+	})
+	require.LessOrEqual(t, allocs, float64(2))
+}
+
+func TestWriteStatistics(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional int32 i;
+		optional boolean b;
+		optional double d;
+		optional float f;
+		optional binary dec (DECIMAL(5, 0));
+		optional int96 ts;
+		optional binary s (STRING);
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"i": int32(-1), "b": true, "d": math.NaN(), "f": float32(0), "dec": []byte{0xff, 0x00}, "ts": [12]byte{1}, "s": []byte("b"),
+	}))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"i": int32(1), "b": true, "d": 2.5, "f": float32(1), "dec": []byte{0x01}, "s": []byte("a"),
+	}))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"d": -1.0, "f": float32(-1), "dec": []byte{0x80},
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	stats := func(col string) *parquet.Statistics {
+		return r.meta.RowGroups[0].Columns[r.GetColumnByName(col).Index()].MetaData.Statistics
+	}
+	float32Bytes := func(v float32) []byte {
+		ret := make([]byte, 4)
+		binary.LittleEndian.PutUint32(ret, math.Float32bits(v))
+		return ret
+	}
+	float64Bytes := func(v float64) []byte {
+		ret := make([]byte, 8)
+		binary.LittleEndian.PutUint64(ret, math.Float64bits(v))
+		return ret
+	}
+
+	require.Equal(t, []byte{0xff, 0xff, 0xff, 0xff}, stats("i").MinValue)
+	require.Equal(t, []byte{1, 0, 0, 0}, stats("i").MaxValue)
+	require.Equal(t, int64(1), stats("i").GetNullCount())
+
+	require.Equal(t, []byte{1}, stats("b").MinValue)
+	require.Equal(t, []byte{1}, stats("b").MaxValue)
+
+	require.Equal(t, float64Bytes(-1), stats("d").MinValue)
+	require.Equal(t, float64Bytes(2.5), stats("d").MaxValue)
+	require.Equal(t, int64(0), stats("d").GetNullCount())
+
+	require.Equal(t, float32Bytes(-1), stats("f").MinValue)
+	require.Equal(t, float32Bytes(1), stats("f").MaxValue)
+
+	require.Equal(t, []byte{0xff, 0x00}, stats("dec").MinValue)
+	require.Equal(t, []byte{0x01}, stats("dec").MaxValue)
+
+	require.Nil(t, stats("ts").MinValue)
+	require.Nil(t, stats("ts").MaxValue)
+	require.Equal(t, int64(2), stats("ts").GetNullCount())
+
+	require.Equal(t, []byte("a"), stats("s").MinValue)
+	require.Equal(t, []byte("b"), stats("s").MaxValue)
+	require.Equal(t, int64(1), stats("s").GetNullCount())
+
+	// the deprecated fields are only written for columns whose order older readers interpret
+	// correctly.
+	for _, col := range []string{"i", "b", "d", "f"} {
+		require.Equal(t, stats(col).MinValue, stats(col).Min, col)
+		require.Equal(t, stats(col).MaxValue, stats(col).Max, col)
+	}
+	for _, col := range []string{"dec", "ts", "s"} {
+		require.Nil(t, stats(col).Min, col)
+		require.Nil(t, stats(col).Max, col)
+	}
+
+	// a zero is written as -0 if it's the min value and as +0 if it's the max value.
+	buf.Reset()
+	w = NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"f": float32(0)}))
+	require.NoError(t, w.Close())
+	r, err = NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, float32Bytes(float32(math.Copysign(0, -1))), stats("f").MinValue)
+	require.Equal(t, float32Bytes(0), stats("f").MaxValue)
+}
+
+func TestLegacyStatistics(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 i;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, v := range []int64{-5, 7} {
+		require.NoError(t, w.AddData(map[string]interface{}{"i": v}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	stats := r.meta.RowGroups[0].Columns[0].MetaData.Statistics
+	require.Equal(t, int64Bytes(-5), stats.Min)
+	require.Equal(t, int64Bytes(7), stats.Max)
+
+	// min_value and max_value are preferred, the deprecated fields are only used if they are in
+	// the order of the column's type.
+	sd, err = parquetschema.ParseSchemaDefinition(`message test {
+		required int64 i;
+		required int32 u (INT(32, false));
+	}`)
+	require.NoError(t, err)
+	iElem := sd.SubSchema("i").SchemaElement()
+	uElem := sd.SubSchema("u").SchemaElement()
+
+	min, max := statisticsMinMax(iElem, &parquet.Statistics{Min: int64Bytes(1), Max: int64Bytes(2), MinValue: int64Bytes(3), MaxValue: int64Bytes(4)}, BinaryComparatorUnsigned)
+	require.Equal(t, int64Bytes(3), min)
+	require.Equal(t, int64Bytes(4), max)
+
+	min, max = statisticsMinMax(iElem, &parquet.Statistics{Min: int64Bytes(1), Max: int64Bytes(2)}, BinaryComparatorUnsigned)
+	require.Equal(t, int64Bytes(1), min)
+	require.Equal(t, int64Bytes(2), max)
+
+	min, max = statisticsMinMax(uElem, &parquet.Statistics{Min: []byte{7, 0, 0, 0}, Max: []byte{0xfb, 0xff, 0xff, 0xff}}, BinaryComparatorUnsigned)
+	require.Nil(t, min)
+	require.Nil(t, max)
+}
+
+func TestStatisticsColumns(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 ts;
+		optional double score;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"ts": int64(20), "score": 1.5}))
+	require.NoError(t, w.AddData(map[string]interface{}{"ts": int64(10), "score": -3.0}))
+	require.NoError(t, w.FlushRowGroup())
+	require.NoError(t, w.AddData(map[string]interface{}{"ts": int64(30)}))
+	require.NoError(t, w.Close())
+
+	_, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithStatisticsColumns("unknown"))
+	require.Error(t, err)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithStatisticsColumns("ts", "score"))
+	require.NoError(t, err)
+
+	firstRowGroup := map[string]interface{}{"min_ts": int64(10), "max_ts": int64(20), "min_score": -3.0, "max_score": 1.5}
+	expected := []map[string]interface{}{
+		{"ts": int64(20), "score": 1.5, StatisticsKey: firstRowGroup},
+		{"ts": int64(10), "score": -3.0, StatisticsKey: firstRowGroup},
+		// the second row group only contains nulls in the column score, so it has no min and max.
+		{"ts": int64(30), StatisticsKey: map[string]interface{}{"min_ts": int64(30), "max_ts": int64(30)}},
+	}
+
+	for _, row := range expected {
+		actual, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, row, actual)
+	}
+}
@@ -0,0 +1,170 @@
+package goparquet
+
+import (
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/pkg/errors"
+)
+
+// WithTargetSchema makes NextRow return the rows in the shape of the schema definition sd instead
+// of the schema of the file, so that files whose schema evolved over time can be read as one
+// table. Columns of the file that aren't part of sd are neither read nor returned. Optional and
+// repeated columns of sd that are missing in the file are returned as nulls, required columns
+// as the zero value of their type. Values of int32 columns of the file are widened to int64, and
+// values of float columns to double, if sd requires it, following the same rules as the
+// UnionReader. Columns annotated with converted types match columns of sd annotated with the
+// equivalent logical types. NewFileReaderWithOptions fails if the schema of the file isn't
+// compatible with sd as determined by parquetschema.CompareSchemas.
+func WithTargetSchema(sd *parquetschema.SchemaDefinition) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.targetSchema = sd
+	}
+}
+
+// RowSchemaDefinition returns the schema definition of the rows returned by NextRow, which is the
+// schema definition provided with WithTargetSchema if there is one, and the schema definition of
+// the file otherwise.
+func (f *FileReader) RowSchemaDefinition() *parquetschema.SchemaDefinition {
+	if f.opts.targetSchema != nil {
+		return f.opts.targetSchema
+	}
+	return f.GetSchemaDefinition()
+}
+
+// checkTargetSchema returns an error if the rows of a file with the schema definition file can't
+// be converted to the schema definition target.
+func checkTargetSchema(target, file *parquetschema.SchemaDefinition) error {
+	if target.RootColumn == nil {
+		return errors.New("target schema has no root column")
+	}
+
+	for _, d := range parquetschema.CompareSchemas(target, file).Differences {
+		// required columns that are missing in the file are filled with zero values.
+		if !d.Compatible && d.Kind != parquetschema.ColumnRemoved {
+			return errors.Errorf("incompatible target schema: %s", d)
+		}
+	}
+
+	return checkTargetDefaults(target.RootColumn.Children, file.RootColumn.Children)
+}
+
+// checkTargetDefaults checks that a zero value can be created for all required columns of target
+// that are missing in file.
+func checkTargetDefaults(target, file []*parquetschema.ColumnDefinition) error {
+	for _, t := range target {
+		f := findColumnDefinition(file, t.SchemaElement.GetName())
+		if f != nil {
+			if err := checkTargetDefaults(t.Children, f.Children); err != nil {
+				return err
+			}
+			continue
+		}
+		if t.SchemaElement.GetRepetitionType() != parquet.FieldRepetitionType_REQUIRED {
+			continue
+		}
+		if t.SchemaElement.Type == nil {
+			if err := checkTargetDefaults(t.Children, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := resolveValueType(t.SchemaElement); err != nil {
+			return errors.Wrapf(err, "no default value for required column %s of the target schema", t.SchemaElement.GetName())
+		}
+	}
+	return nil
+}
+
+// targetColumns returns the flat names of the columns of file that are part of target.
+func targetColumns(target *parquetschema.SchemaDefinition, file []*Column) []string {
+	var names []string
+	for _, col := range file {
+		cols := target.RootColumn.Children
+		var found *parquetschema.ColumnDefinition
+		for _, name := range col.pathArray() {
+			if found = findColumnDefinition(cols, name); found == nil {
+				break
+			}
+			cols = found.Children
+		}
+		if found != nil {
+			names = append(names, col.FlatName())
+		}
+	}
+	return names
+}
+
+// adaptGroup converts the data of a group of the file with the columns file to the columns target.
+func adaptGroup(target, file []*parquetschema.ColumnDefinition, data map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(target))
+	for _, t := range target {
+		name := t.SchemaElement.GetName()
+		f := findColumnDefinition(file, name)
+		if f == nil {
+			if t.SchemaElement.GetRepetitionType() == parquet.FieldRepetitionType_REQUIRED {
+				ret[name] = defaultValue(t)
+			}
+			continue
+		}
+		if v, ok := data[name]; ok {
+			ret[name] = adaptValue(t, f, v)
+		}
+	}
+	return ret
+}
+
+// adaptValue converts a value of the column file to the column target.
+func adaptValue(target, file *parquetschema.ColumnDefinition, v interface{}) interface{} {
+	if target.SchemaElement.Type == nil {
+		switch typed := v.(type) {
+		case map[string]interface{}:
+			return adaptGroup(target.Children, file.Children, typed)
+		case []map[string]interface{}:
+			ret := make([]map[string]interface{}, len(typed))
+			for i := range typed {
+				ret[i] = adaptGroup(target.Children, file.Children, typed[i])
+			}
+			return ret
+		}
+		return v
+	}
+
+	if target.SchemaElement.GetType() == file.SchemaElement.GetType() {
+		return v
+	}
+
+	return widenValue(v, nil)
+}
+
+// defaultValue returns the zero value of a required column, like it would be returned by NextRow.
+func defaultValue(col *parquetschema.ColumnDefinition) interface{} {
+	if col.SchemaElement.Type == nil {
+		return adaptGroup(col.Children, nil, nil)
+	}
+
+	// the type was already resolved by checkTargetDefaults.
+	vt, _ := resolveValueType(col.SchemaElement)
+	switch vt.typ {
+	case parquet.Type_BOOLEAN:
+		return false
+	case parquet.Type_INT32:
+		if vt.unsigned {
+			return uint32(0)
+		}
+		return int32(0)
+	case parquet.Type_INT64:
+		if vt.unsigned {
+			return uint64(0)
+		}
+		return int64(0)
+	case parquet.Type_INT96:
+		return [12]byte{}
+	case parquet.Type_FLOAT:
+		return float32(0)
+	case parquet.Type_DOUBLE:
+		return float64(0)
+	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		return make([]byte, col.SchemaElement.GetTypeLength())
+	}
+	return []byte{}
+}
@@ -0,0 +1,155 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetSchema(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message old {
+		required int32 id;
+		optional float score;
+		optional binary name (STRING);
+		optional int64 extra;
+		repeated int32 tags;
+		optional group info {
+			required int32 a;
+			optional int32 dropped;
+		}
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":    int32(1),
+		"score": float32(1.5),
+		"name":  []byte("foo"),
+		"extra": int64(42),
+		"tags":  []int32{1, 2},
+		"info":  map[string]interface{}{"a": int32(3), "dropped": int32(4)},
+	}))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id": int32(2),
+	}))
+	require.NoError(t, w.Close())
+
+	target, err := parquetschema.ParseSchemaDefinition(`message new {
+		required int64 id;
+		optional double score;
+		optional binary name (STRING);
+		repeated int64 tags;
+		optional int32 added;
+		required binary label (STRING);
+		optional group info {
+			required int32 a;
+			required boolean flag;
+		}
+		required group meta {
+			required int64 version;
+			optional binary source;
+		}
+	}`)
+	require.NoError(t, err)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithTargetSchema(target))
+	require.NoError(t, err)
+	require.Equal(t, target, r.RowSchemaDefinition())
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"id":    int64(1),
+		"score": float64(1.5),
+		"name":  []byte("foo"),
+		"tags":  []int64{1, 2},
+		"label": []byte{},
+		"info":  map[string]interface{}{"a": int32(3), "flag": false},
+		"meta":  map[string]interface{}{"version": int64(0)},
+	}, row)
+
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"id":    int64(2),
+		"label": []byte{},
+		"meta":  map[string]interface{}{"version": int64(0)},
+	}, row)
+
+	// columns that aren't part of the target schema aren't read.
+	require.Equal(t, []string{"id", "score", "name", "tags", "info.a"}, r.SchemaReader.(*schema).selectedColumn)
+
+	for _, incompatible := range []string{
+		`message new { required int32 id (INT(8, true)); }`,
+		`message new { required binary id; }`,
+		`message new { required float score; }`,
+		`message new { required int32 tags; }`,
+	} {
+		target, err := parquetschema.ParseSchemaDefinition(incompatible)
+		require.NoError(t, err)
+		_, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithTargetSchema(target))
+		require.Error(t, err, incompatible)
+	}
+}
+
+func TestTargetSchemaConvertedTypes(t *testing.T) {
+	// files of older writers only annotate the columns with converted types.
+	sd, err := parquetschema.ParseSchemaDefinition(`message old {
+		required binary name (UTF8);
+		required int32 count (INT_32);
+		optional int32 small (INT_16);
+		optional group tags (LIST) {
+			repeated group list {
+				required binary element (UTF8);
+			}
+		}
+	}`)
+	require.NoError(t, err)
+	sd.RootColumn.Children[3].SchemaElement.LogicalType = nil
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"name":  []byte("foo"),
+		"count": int32(42),
+		"small": int32(7),
+		"tags": map[string]interface{}{
+			"list": []map[string]interface{}{{"element": []byte("bar")}},
+		},
+	}))
+	require.NoError(t, w.Close())
+
+	target, err := parquetschema.ParseSchemaDefinition(`message new {
+		required binary name (STRING);
+		required int32 count (INT(32, true));
+		optional int64 small (INT(64, true));
+		optional group tags (LIST) {
+			repeated group list {
+				required binary element (STRING);
+			}
+		}
+	}`)
+	require.NoError(t, err)
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithTargetSchema(target))
+	require.NoError(t, err)
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"name":  []byte("foo"),
+		"count": int32(42),
+		"small": int64(7),
+		"tags": map[string]interface{}{
+			"list": []map[string]interface{}{{"element": []byte("bar")}},
+		},
+	}, row)
+
+	target, err = parquetschema.ParseSchemaDefinition(`message new { required int32 count (INT(32, false)); }`)
+	require.NoError(t, err)
+	_, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithTargetSchema(target))
+	require.Error(t, err)
+}
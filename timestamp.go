@@ -0,0 +1,289 @@
+package goparquet
+
+import (
+	"math"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// WithTimestampConversion makes NextRow return the values of columns annotated as TIMESTAMP as
+// time.Time values instead of the number of units since the Unix epoch as int64. Timestamps that
+// are adjusted to UTC are returned in UTC. Local timestamps, which aren't adjusted to UTC,
+// describe a wall clock time, which is returned in the location loc, or in UTC if loc is nil.
+// Columns with the deprecated converted types TIMESTAMP_MILLIS and TIMESTAMP_MICROS are adjusted
+// to UTC. Repeated TIMESTAMP columns are returned as []time.Time.
+func WithTimestampConversion(loc *time.Location) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.timestampConversion = true
+		opts.timestampLocation = loc
+	}
+}
+
+// WithLocalTimestampLocation sets the location whose wall clock time is stored when time.Time
+// values are written to TIMESTAMP columns that aren't adjusted to UTC. By default, the wall clock
+// time in the location of the time.Time value is stored. Values of TIMESTAMP columns that are
+// adjusted to UTC always store the instant of the time.Time value.
+func WithLocalTimestampLocation(loc *time.Location) FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.timestampOpts == nil {
+			fw.timestampOpts = &timestampOptions{}
+		}
+		fw.timestampOpts.location = loc
+	}
+}
+
+// WithStrictTimestampUnits makes AddData return an error for time.Time values that are more
+// precise than the unit of their TIMESTAMP column, e.g. a time with microseconds for a column
+// with the unit MILLIS. By default, the additional precision is truncated.
+func WithStrictTimestampUnits() FileWriterOption {
+	return func(fw *FileWriter) {
+		if fw.timestampOpts == nil {
+			fw.timestampOpts = &timestampOptions{}
+		}
+		fw.timestampOpts.strict = true
+	}
+}
+
+// timestampOptions contains the options of a FileWriter to convert time.Time values into
+// TIMESTAMP values.
+type timestampOptions struct {
+	location *time.Location
+	strict   bool
+}
+
+// WithInt96Timestamps makes the FileWriter store the columns that are annotated as TIMESTAMP in
+// the schema as INT96 columns instead, for older versions of Hive and Impala that only support
+// timestamps as INT96. time.Time values and the int64 values in the unit of the TIMESTAMP columns
+// are converted to INT96 when they're added. The schema is changed when the first record is
+// added or the first row group is begun, copied or written as encoded pages, so column chunks of
+// TIMESTAMP columns can't be copied with CopyChunk. Column batches can't be written with INT96
+// timestamps.
+func WithInt96Timestamps() FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.int96Timestamps = true
+	}
+}
+
+// int96Timestamp describes a TIMESTAMP column that is stored as INT96.
+type int96Timestamp struct {
+	unit time.Duration
+	utc  bool
+}
+
+// prepareColumns applies the options that affect the columns before the first record is added or
+// the first column chunk is written, after which no columns can be added to the schema anymore.
+func (fw *FileWriter) prepareColumns() {
+	if fw.columnsPrepared {
+		return
+	}
+
+	if fw.int96Timestamps {
+		fw.useInt96Timestamps()
+	}
+
+	if fw.timestampOpts != nil {
+		for _, col := range fw.Columns() {
+			if cs := col.getColumnStore(); cs != nil {
+				cs.timestamps = fw.timestampOpts
+			}
+		}
+	}
+
+	fw.columnsPrepared = true
+}
+
+// useInt96Timestamps changes the TIMESTAMP columns of the schema into INT96 columns. The column
+// stores of all other columns are kept.
+func (fw *FileWriter) useInt96Timestamps() {
+	for _, col := range fw.Columns() {
+		if unit, utc, ok := timestampUnit(col.Element()); ok {
+			col.useInt96(int96Timestamp{unit: unit, utc: utc})
+		}
+	}
+}
+
+// useInt96 changes the TIMESTAMP column into an INT96 column. The column store keeps its settings,
+// except for the encoding, as INT96 only supports PLAIN.
+func (c *Column) useInt96(ts int96Timestamp) {
+	// the parameters and the element may be shared with a CompiledSchema, so they're replaced
+	// instead of modified.
+	params := *c.params
+	params.LogicalType = nil
+	params.ConvertedType = nil
+	c.params = &params
+
+	store := &int96Store{}
+	store.ColumnParameters = &params
+	c.data.typedColumnStore = store
+	c.data.enc = parquet.Encoding_PLAIN
+	c.data.int96 = &ts
+	c.data.reset(c.rep, c.maxR, c.maxD)
+
+	if c.element != nil {
+		c.element = c.buildElement()
+	}
+}
+
+// timestampUnit returns the duration of the unit of a column annotated as TIMESTAMP and whether
+// the timestamps are adjusted to UTC. ok is false if the column isn't annotated as TIMESTAMP or
+// its unit isn't supported.
+func timestampUnit(elem *parquet.SchemaElement) (unit time.Duration, utc bool, ok bool) {
+	if elem.GetType() != parquet.Type_INT64 {
+		return 0, false, false
+	}
+
+	if lt := elem.LogicalType; lt != nil && lt.IsSetTIMESTAMP() {
+		switch u := lt.TIMESTAMP.Unit; {
+		case u == nil:
+			return 0, false, false
+		case u.IsSetMILLIS():
+			return time.Millisecond, lt.TIMESTAMP.IsAdjustedToUTC, true
+		case u.IsSetMICROS():
+			return time.Microsecond, lt.TIMESTAMP.IsAdjustedToUTC, true
+		case u.IsSetNANOS():
+			return time.Nanosecond, lt.TIMESTAMP.IsAdjustedToUTC, true
+		}
+		return 0, false, false
+	}
+
+	if elem.ConvertedType != nil {
+		switch elem.GetConvertedType() {
+		case parquet.ConvertedType_TIMESTAMP_MILLIS:
+			return time.Millisecond, true, true
+		case parquet.ConvertedType_TIMESTAMP_MICROS:
+			return time.Microsecond, true, true
+		}
+	}
+	return 0, false, false
+}
+
+// timestampToTime returns the time of a TIMESTAMP value with the unit unit.
+func (opts *fileReaderOptions) timestampToTime(v int64, unit time.Duration, utc bool) time.Time {
+	t := unitsToTime(v, unit)
+	if utc || opts.timestampLocation == nil {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), opts.timestampLocation)
+}
+
+// unitsToTime returns the time in UTC that is v units after the Unix epoch.
+func unitsToTime(v int64, unit time.Duration) time.Time {
+	perSecond := int64(time.Second / unit)
+	sec, frac := v/perSecond, v%perSecond
+	if frac < 0 {
+		sec, frac = sec-1, frac+perSecond
+	}
+	return time.Unix(sec, frac*int64(unit)).UTC()
+}
+
+// convertTimestamps converts time.Time values, which may be a single value or a slice of values,
+// into TIMESTAMP values if the column is annotated as TIMESTAMP, or into INT96 values if the
+// column is a TIMESTAMP column stored as INT96. Other values are returned unchanged.
+func (cs *ColumnStore) convertTimestamps(v interface{}) (interface{}, error) {
+	if cs.int96 != nil {
+		return cs.convertInt96Timestamps(v)
+	}
+
+	switch typed := v.(type) {
+	case time.Time:
+		if unit, utc, ok := cs.timestampUnit(); ok {
+			return cs.timestamps.timestampValue(typed, unit, utc)
+		}
+	case []time.Time:
+		if unit, utc, ok := cs.timestampUnit(); ok {
+			values := make([]int64, len(typed))
+			for i := range typed {
+				value, err := cs.timestamps.timestampValue(typed[i], unit, utc)
+				if err != nil {
+					return nil, err
+				}
+				values[i] = value
+			}
+			return values, nil
+		}
+	}
+	return v, nil
+}
+
+func (cs *ColumnStore) timestampUnit() (time.Duration, bool, bool) {
+	params := cs.params()
+	if params == nil {
+		return 0, false, false
+	}
+	typ := cs.parquetType()
+	return timestampUnit(&parquet.SchemaElement{Type: &typ, LogicalType: params.LogicalType, ConvertedType: params.ConvertedType})
+}
+
+// timestampValue returns t as the number of units since the Unix epoch. For local timestamps, the
+// wall clock time of t is stored.
+func (opts *timestampOptions) timestampValue(t time.Time, unit time.Duration, utc bool) (int64, error) {
+	if !utc {
+		if opts != nil && opts.location != nil {
+			t = t.In(opts.location)
+		}
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+
+	v, exact, overflow := TimeToTimestamp(t, unit)
+	if opts != nil && opts.strict && !exact {
+		return 0, errors.Errorf("time %s can't be stored with the unit %s without losing precision", t, unit)
+	}
+	if overflow != 0 {
+		return 0, errors.Errorf("time %s is out of range for the unit %s", t, unit)
+	}
+	return v, nil
+}
+
+// TimeToTimestamp returns t as a TIMESTAMP value with the unit unit, which is the number of units
+// since the Unix epoch. Additional precision of t is truncated, in which case exact is false. If
+// t is out of the range that can be represented with the unit, overflow is -1 or 1 if t is too
+// early or too late, and v is the value that results from wrapping around.
+func TimeToTimestamp(t time.Time, unit time.Duration) (v int64, exact bool, overflow int) {
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	perSecond := int64(time.Second / unit)
+
+	switch {
+	case sec > (math.MaxInt64-nsec/int64(unit))/perSecond:
+		overflow = 1
+	case sec < math.MinInt64/perSecond:
+		overflow = -1
+	}
+	return sec*perSecond + nsec/int64(unit), nsec%int64(unit) == 0, overflow
+}
+
+// convertInt96Timestamps converts time.Time values and int64 values in the unit of the original
+// TIMESTAMP column into INT96 values.
+func (cs *ColumnStore) convertInt96Timestamps(v interface{}) (interface{}, error) {
+	switch typed := v.(type) {
+	case time.Time:
+		return cs.int96Value(typed), nil
+	case int64:
+		return cs.int96Value(unitsToTime(typed, cs.int96.unit)), nil
+	case []time.Time:
+		values := make([][12]byte, len(typed))
+		for i := range typed {
+			values[i] = cs.int96Value(typed[i])
+		}
+		return values, nil
+	case []int64:
+		values := make([][12]byte, len(typed))
+		for i := range typed {
+			values[i] = cs.int96Value(unitsToTime(typed[i], cs.int96.unit))
+		}
+		return values, nil
+	}
+	return v, nil
+}
+
+// int96Value returns t as INT96 value. For local timestamps, the wall clock time of t is stored.
+func (cs *ColumnStore) int96Value(t time.Time) [12]byte {
+	if !cs.int96.utc {
+		if cs.timestamps != nil && cs.timestamps.location != nil {
+			t = t.In(cs.timestamps.location)
+		}
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	return TimeToInt96(t)
+}
@@ -0,0 +1,264 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampConversion(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 utc (TIMESTAMP(MILLIS, true));
+		optional int64 local (TIMESTAMP(MICROS, false));
+		repeated int64 legacy (TIMESTAMP_MICROS);
+		required int64 count;
+	}`)
+	require.NoError(t, err)
+
+	berlin := time.FixedZone("CEST", 2*60*60)
+	newYork := time.FixedZone("EDT", -4*60*60)
+	ts := time.Date(2020, 9, 13, 14, 26, 40, 123456789, berlin)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"utc":    ts,
+		"local":  ts,
+		"legacy": []time.Time{ts, time.Date(1969, 12, 31, 23, 59, 59, 999999000, time.UTC)},
+		"count":  int64(1),
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"utc":    int64(1600000000123),
+		"local":  int64(1600007200123456),
+		"legacy": []int64{1600000000123456, -1},
+		"count":  int64(1),
+	}, row)
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithTimestampConversion(berlin))
+	require.NoError(t, err)
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"utc":    time.Date(2020, 9, 13, 12, 26, 40, 123000000, time.UTC),
+		"local":  time.Date(2020, 9, 13, 14, 26, 40, 123456000, berlin),
+		"legacy": []time.Time{time.Date(2020, 9, 13, 12, 26, 40, 123456000, time.UTC), time.Date(1969, 12, 31, 23, 59, 59, 999999000, time.UTC)},
+		"count":  int64(1),
+	}, row)
+
+	// the wall clock time of local timestamps is taken in the location of the writer.
+	buf = &bytes.Buffer{}
+	w = NewFileWriter(buf, WithSchemaDefinition(sd), WithLocalTimestampLocation(newYork))
+	require.NoError(t, w.AddData(map[string]interface{}{"utc": ts, "local": ts, "count": int64(1)}))
+	require.NoError(t, w.Close())
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithTimestampConversion(nil))
+	require.NoError(t, err)
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2020, 9, 13, 8, 26, 40, 123456000, time.UTC), row["local"])
+
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithStrictTimestampUnits())
+	require.Error(t, w.AddData(map[string]interface{}{"utc": ts, "count": int64(1)}))
+	require.NoError(t, w.AddData(map[string]interface{}{"utc": ts.Truncate(time.Millisecond), "count": int64(1)}))
+
+	w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+	require.Error(t, w.AddData(map[string]interface{}{"utc": time.Date(300000000, 1, 1, 0, 0, 0, 0, time.UTC), "count": int64(1)}))
+	require.Error(t, w.AddData(map[string]interface{}{"utc": int64(1), "count": ts}))
+}
+
+func TestTimeToTimestamp(t *testing.T) {
+	v, exact, overflow := TimeToTimestamp(time.Date(1969, 12, 31, 23, 59, 59, 999500000, time.UTC), time.Millisecond)
+	require.Equal(t, int64(-1), v)
+	require.False(t, exact)
+	require.Equal(t, 0, overflow)
+
+	v, exact, overflow = TimeToTimestamp(time.Unix(1, 2000), time.Microsecond)
+	require.Equal(t, int64(1000002), v)
+	require.True(t, exact)
+	require.Equal(t, 0, overflow)
+
+	_, _, overflow = TimeToTimestamp(time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC), time.Nanosecond)
+	require.Equal(t, 1, overflow)
+	_, _, overflow = TimeToTimestamp(time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC), time.Nanosecond)
+	require.Equal(t, -1, overflow)
+}
+
+func TestNanosecondTimestamps(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 ts (TIMESTAMP(NANOS, true));
+		optional int64 local (TIMESTAMP(NANOS, false));
+	}`)
+	require.NoError(t, err)
+
+	before := time.Date(1969, 7, 20, 20, 17, 40, 1, time.UTC)
+	after := time.Date(2020, 9, 13, 12, 26, 40, 123456789, time.UTC)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"ts": after, "local": after}))
+	require.NoError(t, w.AddData(map[string]interface{}{"ts": before}))
+	require.NoError(t, w.AddData(map[string]interface{}{"ts": int64(0)}))
+	require.Error(t, NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd)).AddData(map[string]interface{}{"ts": time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC)}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithTimestampConversion(nil))
+	require.NoError(t, err)
+
+	// the statistics are ordered as signed integers, which readers can rely on because of the
+	// type defined column orders.
+	require.Len(t, r.meta.ColumnOrders, 2)
+	require.NotNil(t, r.meta.ColumnOrders[0].TYPE_ORDER)
+	stats, err := r.RollupStatistics()
+	require.NoError(t, err)
+	require.Equal(t, int64Bytes(before.UnixNano()), stats["ts"].MinValue)
+	require.Equal(t, int64Bytes(after.UnixNano()), stats["ts"].MaxValue)
+
+	for _, expected := range []map[string]interface{}{
+		{"ts": after, "local": after},
+		{"ts": before},
+		{"ts": time.Unix(0, 0).UTC()},
+	} {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected, row)
+	}
+}
+
+func TestInt96Timestamps(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 utc (TIMESTAMP(MILLIS, true));
+		optional int64 local (TIMESTAMP(MICROS, false));
+		repeated int64 legacy (TIMESTAMP_MICROS);
+		required int64 count;
+	}`)
+	require.NoError(t, err)
+
+	berlin := time.FixedZone("CEST", 2*60*60)
+	ts := time.Date(2020, 9, 13, 14, 26, 40, 123456789, berlin)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithInt96Timestamps())
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"utc":    ts,
+		"local":  int64(1600007200123456),
+		"legacy": []time.Time{ts},
+		"count":  int64(1),
+	}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, `message test {
+  required int96 utc;
+  optional int96 local;
+  repeated int96 legacy;
+  required int64 count;
+}
+`, r.GetSchemaDefinition().String())
+	require.Nil(t, r.meta.ColumnOrders)
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"utc":    TimeToInt96(ts),
+		"local":  TimeToInt96(time.Date(2020, 9, 13, 14, 26, 40, 123456000, time.UTC)),
+		"legacy": [][12]byte{TimeToInt96(ts)},
+		"count":  int64(1),
+	}, row)
+
+	err = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithInt96Timestamps()).WriteColumnBatch("count", []int64{1}, nil, nil)
+	require.Error(t, err)
+}
+
+func TestInt96TimestampsKeepColumnStores(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 ts (TIMESTAMP(MILLIS, true));
+	}`)
+	require.NoError(t, err)
+	compiled, err := CompileSchema(sd)
+	require.NoError(t, err)
+
+	write := func(options ...FileWriterOption) *FileReader {
+		deltaStore, err := NewInt64Store(parquet.Encoding_DELTA_BINARY_PACKED, false, &ColumnParameters{})
+		require.NoError(t, err)
+		plainStore, err := NewByteArrayStore(parquet.Encoding_PLAIN, false, &ColumnParameters{})
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, append([]FileWriterOption{WithCompiledSchema(compiled)}, options...)...)
+		require.NoError(t, w.AddColumn("delta", NewDataColumn(deltaStore, parquet.FieldRepetitionType_REQUIRED)))
+		require.NoError(t, w.AddColumn("name", NewDataColumn(plainStore, parquet.FieldRepetitionType_REQUIRED)))
+		for i := 0; i < 100; i++ {
+			require.NoError(t, w.AddData(map[string]interface{}{"ts": int64(i), "delta": int64(i), "name": []byte("same")}))
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		return r
+	}
+
+	for _, r := range []*FileReader{write(WithInt96Timestamps()), write()} {
+		columns := r.meta.RowGroups[0].Columns
+		require.Contains(t, columns[1].MetaData.Encodings, parquet.Encoding_DELTA_BINARY_PACKED)
+		require.NotContains(t, columns[2].MetaData.Encodings, parquet.Encoding_RLE_DICTIONARY)
+		require.NotContains(t, columns[2].MetaData.Encodings, parquet.Encoding_PLAIN_DICTIONARY)
+	}
+
+	// the compiled schema isn't changed by the writer with INT96 timestamps.
+	require.Equal(t, parquet.Type_INT96, write(WithInt96Timestamps()).meta.RowGroups[0].Columns[0].MetaData.Type)
+	require.Equal(t, parquet.Type_INT64, write().meta.RowGroups[0].Columns[0].MetaData.Type)
+}
+
+func TestInt96TimestampsCopyChunks(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 ts (TIMESTAMP(MILLIS, true));
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{"ts": int64(1600007200123)}))
+	require.NoError(t, w.Close())
+
+	src, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// the INT64 chunk can't be copied to a column that is written as INT96.
+	out := &bytes.Buffer{}
+	w = NewFileWriter(out, WithSchemaDefinition(sd), WithInt96Timestamps())
+	require.Error(t, w.CopyChunk(src, 0, "ts"))
+	require.NoError(t, w.AddData(map[string]interface{}{"ts": int64(1600007200123)}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(out.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r.NumRows())
+	_, err = r.NextRow()
+	require.NoError(t, err)
+
+	// raw chunks are not copied, the values are converted instead.
+	out = &bytes.Buffer{}
+	w = NewFileWriter(out, WithSchemaDefinition(sd), WithInt96Timestamps())
+	n, err := CopyRows(w, src, WithCopyRawChunks())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	require.NoError(t, w.Close())
+
+	r, err = NewFileReader(bytes.NewReader(out.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, parquet.Type_INT96, r.meta.RowGroups[0].Columns[0].MetaData.Type)
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, TimeToInt96(time.Unix(0, 1600007200123*int64(time.Millisecond))), row["ts"])
+}
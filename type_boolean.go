@@ -4,6 +4,7 @@ import (
 	"io"
 
 	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetencoding"
 	"github.com/pkg/errors"
 )
 
@@ -50,48 +51,43 @@ func (b *booleanPlainDecoder) decodeValues(dst []interface{}) (int, error) {
 		}
 	}
 
-	buf := make([]byte, 1)
-	for i := start; i < len(dst); i += 8 {
-		if _, err := io.ReadFull(b.r, buf); err != nil {
-			return i, err
-		}
-		d := unpack8int32_1(buf)
-		for j := 0; j < 8; j++ {
-			if i+j < len(dst) {
-				dst[i+j] = d[j] == 1
-			} else {
-				b.left = append(b.left, d[j] == 1)
-			}
-		}
+	// the values are decoded in multiples of 8, so that the remaining values of the last byte
+	// are kept for the next call.
+	need := len(dst) - start
+	values := make([]bool, (need+7)/8*8)
+	n, err := parquetencoding.DecodePlainBoolean(b.r, values)
+	if n > need {
+		b.left = values[need:n]
+		n = need
+	}
+	for i := range values[:n] {
+		dst[start+i] = values[i]
+	}
+	if n < need {
+		return start + n, err
 	}
 
 	return len(dst), nil
 }
 
 type booleanPlainEncoder struct {
-	w    io.Writer
-	data *packedArray
+	w      io.Writer
+	values []bool
 }
 
 func (b *booleanPlainEncoder) Close() error {
-	b.data.flush()
-	return writeFull(b.w, b.data.data)
+	return parquetencoding.EncodePlainBoolean(b.w, b.values)
 }
 
 func (b *booleanPlainEncoder) init(w io.Writer) error {
 	b.w = w
-	b.data = &packedArray{}
-	b.data.reset(1)
+	b.values = b.values[:0]
 	return nil
 }
 
 func (b *booleanPlainEncoder) encodeValues(values []interface{}) error {
 	for i := range values {
-		var v int32
-		if values[i].(bool) {
-			v = 1
-		}
-		b.data.appendSingle(v)
+		b.values = append(b.values, values[i].(bool))
 	}
 
 	return nil
@@ -175,14 +171,6 @@ func (b *booleanStore) reset(repetitionType parquet.FieldRepetitionType) {
 	b.repTyp = repetitionType
 }
 
-func (b *booleanStore) maxValue() []byte {
-	return nil
-}
-
-func (b *booleanStore) minValue() []byte {
-	return nil
-}
-
 func (b *booleanStore) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
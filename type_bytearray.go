@@ -3,17 +3,35 @@ package goparquet
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 
 	"github.com/fraugster/parquet-go/parquet"
-
+	"github.com/fraugster/parquet-go/parquetencoding"
 	"github.com/pkg/errors"
 )
 
+// ValueLengthError is returned when a page contains an INT96 or FIXED_LEN_BYTE_ARRAY value whose length
+// doesn't match the length expected from the column type, e.g. because the file is truncated or malformed.
+type ValueLengthError struct {
+	// Type is the physical type of the column.
+	Type parquet.Type
+	// Expected is the length of the values of the column.
+	Expected int
+	// Actual is the length of the value found in the page.
+	Actual int
+}
+
+func (e *ValueLengthError) Error() string {
+	return fmt.Sprintf("%s value has length %d but %d was expected", e.Type, e.Actual, e.Expected)
+}
+
 type byteArrayPlainDecoder struct {
 	r io.Reader
 	// if the length is set, then this is a fix size array decoder, unless it reads the len first
 	length int
+	// if zeroCopy is set, values reference the page data instead of being copied, if possible.
+	zeroCopy bool
 }
 
 func (b *byteArrayPlainDecoder) init(r io.Reader) error {
@@ -21,37 +39,61 @@ func (b *byteArrayPlainDecoder) init(r io.Reader) error {
 	return nil
 }
 
-func (b *byteArrayPlainDecoder) next() ([]byte, error) {
+// nextZeroCopy returns the next value as a slice of the page data.
+func (b *byteArrayPlainDecoder) nextZeroCopy(pageData *bytes.Buffer) ([]byte, error) {
 	var l = int32(b.length)
 	if l == 0 {
-		if err := binary.Read(b.r, binary.LittleEndian, &l); err != nil {
+		if err := binary.Read(pageData, binary.LittleEndian, &l); err != nil {
 			return nil, err
 		}
 
 		if l < 0 {
 			return nil, errors.New("bytearray/plain: len is negative")
 		}
-	} else if l < 0 {
-		return nil, errors.New("bytearray/plain: len is negative")
 	}
 
-	buf := make([]byte, l)
-	_, err := io.ReadFull(b.r, buf)
-	if err != nil {
-		return nil, err
+	if n := pageData.Len(); n < int(l) {
+		if n == 0 {
+			return nil, io.EOF
+		}
+		if b.length > 0 {
+			return nil, &ValueLengthError{Type: parquet.Type_FIXED_LEN_BYTE_ARRAY, Expected: b.length, Actual: n}
+		}
+		return nil, io.ErrUnexpectedEOF
 	}
-
-	return buf, nil
+	// the capacity is limited so that appending to the value can't overwrite the next value.
+	return pageData.Next(int(l))[:l:l], nil
 }
 
 func (b *byteArrayPlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	var err error
-	for i := range dst {
-		if dst[i], err = b.next(); err != nil {
-			return i, err
+	if pageData, ok := b.r.(*bytes.Buffer); ok && b.zeroCopy {
+		var err error
+		for i := range dst {
+			if dst[i], err = b.nextZeroCopy(pageData); err != nil {
+				return i, err
+			}
 		}
+		return len(dst), nil
 	}
-	return len(dst), nil
+
+	var (
+		values = make([][]byte, len(dst))
+		n      int
+		err    error
+	)
+	if b.length > 0 {
+		cr := &countingReader{r: b.r}
+		n, err = parquetencoding.DecodePlainFixedLenByteArray(cr, b.length, values)
+		if err == io.ErrUnexpectedEOF {
+			err = &ValueLengthError{Type: parquet.Type_FIXED_LEN_BYTE_ARRAY, Expected: b.length, Actual: cr.n - n*b.length}
+		}
+	} else {
+		n, err = parquetencoding.DecodePlainByteArray(b.r, values)
+	}
+	for i := range values[:n] {
+		dst[i] = values[i]
+	}
+	return n, err
 }
 
 type byteArrayPlainEncoder struct {
@@ -66,234 +108,135 @@ func (b *byteArrayPlainEncoder) init(w io.Writer) error {
 	return nil
 }
 
-func (b *byteArrayPlainEncoder) writeBytes(data []byte) error {
-	l := b.length
-	if l == 0 { // variable length
-		l = len(data)
-		l32 := int32(l)
-		if err := binary.Write(b.w, binary.LittleEndian, l32); err != nil {
-			return err
-		}
-	} else if len(data) != l {
-		return errors.Errorf("the byte array should be with length %d but is %d", l, len(data))
-	}
-
-	return writeFull(b.w, data)
-}
-
 func (b *byteArrayPlainEncoder) encodeValues(values []interface{}) error {
+	data := make([][]byte, len(values))
 	for i := range values {
-		if err := b.writeBytes(values[i].([]byte)); err != nil {
-			return err
-		}
+		data[i] = values[i].([]byte)
 	}
 
-	return nil
+	if b.length > 0 {
+		return parquetencoding.EncodePlainFixedLenByteArray(b.w, b.length, data)
+	}
+	return parquetencoding.EncodePlainByteArray(b.w, data)
 }
 
 func (*byteArrayPlainEncoder) Close() error {
 	return nil
 }
 
+// byteArrayDeltaLengthDecoder decodes the values of a page using the DELTA_LENGTH_BYTE_ARRAY
+// decoder of the parquetencoding package.
 type byteArrayDeltaLengthDecoder struct {
-	r        io.Reader
 	position int
-	lens     []int32
+	values   [][]byte
 }
 
 func (b *byteArrayDeltaLengthDecoder) init(r io.Reader) error {
-	b.r = r
-	b.position = 0
-	lensDecoder := int32DeltaBPDecoder{}
-	if err := lensDecoder.init(r); err != nil {
-		return err
-	}
-
-	b.lens = make([]int32, lensDecoder.valuesCount)
-	return decodeInt32(&lensDecoder, b.lens)
-}
-
-func (b *byteArrayDeltaLengthDecoder) next() ([]byte, error) {
-	if b.position >= len(b.lens) {
-		return nil, io.EOF
-	}
-	size := int(b.lens[b.position])
-	value := make([]byte, size)
-	if _, err := io.ReadFull(b.r, value); err != nil {
-		return nil, errors.Wrap(err, "there is no byte left")
+	values, err := parquetencoding.DecodeDeltaLengthByteArray(r)
+	if err != nil {
+		return errors.Wrap(err, "bytearray/delta")
 	}
-	b.position++
-
-	return value, nil
+	b.position = 0
+	b.values = values
+	return nil
 }
 
 func (b *byteArrayDeltaLengthDecoder) decodeValues(dst []interface{}) (int, error) {
-	total := len(dst)
-	for i := 0; i < total; i++ {
-		v, err := b.next()
-		if err != nil {
-			return i, err
+	for i := range dst {
+		if b.position >= len(b.values) {
+			return i, io.EOF
 		}
-		dst[i] = v
+		dst[i] = b.values[b.position]
+		b.position++
 	}
-	return total, nil
+	return len(dst), nil
 }
 
-// this type is used inside the byteArrayDeltaEncoder, the Close method should do the actual write, not before.
+// byteArrayDeltaLengthEncoder collects the values of a page and writes them using the
+// DELTA_LENGTH_BYTE_ARRAY encoder of the parquetencoding package when it's closed.
 type byteArrayDeltaLengthEncoder struct {
-	w    io.Writer
-	buf  *bytes.Buffer
-	lens []interface{}
+	w      io.Writer
+	values [][]byte
 }
 
 func (b *byteArrayDeltaLengthEncoder) init(w io.Writer) error {
 	b.w = w
-	b.buf = &bytes.Buffer{}
+	b.values = b.values[:0]
 	return nil
 }
 
-func (b *byteArrayDeltaLengthEncoder) writeOne(data []byte) error {
-	b.lens = append(b.lens, int32(len(data)))
-	return writeFull(b.buf, data)
-}
-
 func (b *byteArrayDeltaLengthEncoder) encodeValues(values []interface{}) error {
-	if b.lens == nil {
-		// this is just for the first time, maybe we need to copy and increase the cap in the next calls?
-		b.lens = make([]interface{}, 0, len(values))
-	}
 	for i := range values {
-		if err := b.writeOne(values[i].([]byte)); err != nil {
-			return err
-		}
+		b.values = append(b.values, values[i].([]byte))
 	}
-
 	return nil
 }
 
 func (b *byteArrayDeltaLengthEncoder) Close() error {
-	enc := &int32DeltaBPEncoder{
-		deltaBitPackEncoder32: deltaBitPackEncoder32{
-			blockSize:      128,
-			miniBlockCount: 4,
-		},
-	}
-
-	if err := encodeValue(b.w, enc, b.lens); err != nil {
-		return err
-	}
-
-	return writeFull(b.w, b.buf.Bytes())
+	return parquetencoding.EncodeDeltaLengthByteArray(b.w, b.values)
 }
 
+// byteArrayDeltaDecoder decodes the values of a page using the DELTA_BYTE_ARRAY decoder of the
+// parquetencoding package.
 type byteArrayDeltaDecoder struct {
-	suffixDecoder byteArrayDeltaLengthDecoder
-	prefixLens    []int32
-	previousValue []byte
+	// if the length is set, all values need to be of that length.
+	length int
+
+	position int
+	values   [][]byte
 }
 
 func (d *byteArrayDeltaDecoder) init(r io.Reader) error {
-	lensDecoder := deltaBitPackDecoder32{}
-	if err := lensDecoder.init(r); err != nil {
-		return err
-	}
-
-	d.prefixLens = make([]int32, lensDecoder.valuesCount)
-	if err := decodeInt32(&lensDecoder, d.prefixLens); err != nil {
-		return err
-	}
-	if err := d.suffixDecoder.init(r); err != nil {
-		return err
-	}
-
-	if len(d.prefixLens) != len(d.suffixDecoder.lens) {
-		return errors.New("bytearray/delta: different number of suffixes and prefixes")
+	values, err := parquetencoding.DecodeDeltaByteArray(r)
+	if err != nil {
+		return errors.Wrap(err, "bytearray/delta")
 	}
-	d.previousValue = make([]byte, 0)
-
+	d.position = 0
+	d.values = values
 	return nil
 }
 
 func (d *byteArrayDeltaDecoder) decodeValues(dst []interface{}) (int, error) {
-	total := len(dst)
-	for i := 0; i < total; i++ {
-		suffix, err := d.suffixDecoder.next()
-		if err != nil {
-			return i, err
-		}
-		// after this line no error is acceptable
-		prefixLen := int(d.prefixLens[d.suffixDecoder.position-1])
-		value := make([]byte, 0, prefixLen+len(suffix))
-		if len(d.previousValue) < prefixLen {
-			// prevent panic from invalid input
-			return 0, errors.Errorf("invalid prefix len in the stream, the value is %d byte but the it needs %d byte", len(d.previousValue), prefixLen)
+	for i := range dst {
+		if d.position >= len(d.values) {
+			return i, io.EOF
 		}
-		if prefixLen > 0 {
-			value = append(value, d.previousValue[:prefixLen]...)
+		value := d.values[d.position]
+		if d.length > 0 && len(value) != d.length {
+			return i, &ValueLengthError{Type: parquet.Type_FIXED_LEN_BYTE_ARRAY, Expected: d.length, Actual: len(value)}
 		}
-		value = append(value, suffix...)
-		d.previousValue = value
 		dst[i] = value
+		d.position++
 	}
-
-	return total, nil
+	return len(dst), nil
 }
 
+// byteArrayDeltaEncoder collects the values of a page and writes them using the DELTA_BYTE_ARRAY
+// encoder of the parquetencoding package when it's closed.
 type byteArrayDeltaEncoder struct {
-	w io.Writer
-
-	prefixLens    []interface{}
-	previousValue []byte
-
-	values *byteArrayDeltaLengthEncoder
+	w      io.Writer
+	values [][]byte
 }
 
 func (b *byteArrayDeltaEncoder) init(w io.Writer) error {
 	b.w = w
-	b.prefixLens = nil
-	b.previousValue = []byte{}
-	b.values = &byteArrayDeltaLengthEncoder{}
-	return b.values.init(w)
+	b.values = b.values[:0]
+	return nil
 }
 
 func (b *byteArrayDeltaEncoder) encodeValues(values []interface{}) error {
-	if b.prefixLens == nil {
-		b.prefixLens = make([]interface{}, 0, len(values))
-		b.values.lens = make([]interface{}, 0, len(values))
-	}
-
 	for i := range values {
-		data := values[i].([]byte)
-		pLen := prefix(b.previousValue, data)
-		b.prefixLens = append(b.prefixLens, int32(pLen))
-		if err := b.values.writeOne(data[pLen:]); err != nil {
-			return err
-		}
-		b.previousValue = data
+		b.values = append(b.values, values[i].([]byte))
 	}
-
 	return nil
 }
 
 func (b *byteArrayDeltaEncoder) Close() error {
-	// write the lens first
-	enc := &int32DeltaBPEncoder{
-		deltaBitPackEncoder32: deltaBitPackEncoder32{
-			blockSize:      128,
-			miniBlockCount: 4,
-		},
-	}
-
-	if err := encodeValue(b.w, enc, b.prefixLens); err != nil {
-		return err
-	}
-
-	return b.values.Close()
+	return parquetencoding.EncodeDeltaByteArray(b.w, b.values)
 }
 
 type byteArrayStore struct {
-	repTyp   parquet.FieldRepetitionType
-	min, max []byte
+	repTyp parquet.FieldRepetitionType
 
 	*ColumnParameters
 }
@@ -322,40 +265,6 @@ func (is *byteArrayStore) repetitionType() parquet.FieldRepetitionType {
 
 func (is *byteArrayStore) reset(repetitionType parquet.FieldRepetitionType) {
 	is.repTyp = repetitionType
-	is.min = nil
-	is.max = nil
-}
-
-func (is *byteArrayStore) maxValue() []byte {
-	return is.max
-}
-
-func (is *byteArrayStore) minValue() []byte {
-	return is.min
-}
-
-func (is *byteArrayStore) setMinMax(j []byte) error {
-	if is.TypeLength != nil && *is.TypeLength > 0 && int32(len(j)) != *is.TypeLength {
-		return errors.Errorf("the size of data should be %d but is %d", *is.TypeLength, len(j))
-	}
-	// For nil value there is no need to set the min/max
-	if j == nil {
-		return nil
-	}
-	if is.max == nil || is.min == nil {
-		is.min = j
-		is.max = j
-		return nil
-	}
-
-	if bytes.Compare(j, is.min) < 0 {
-		is.min = j
-	}
-	if bytes.Compare(j, is.max) > 0 {
-		is.max = j
-	}
-
-	return nil
 }
 
 func (is *byteArrayStore) getValues(v interface{}) ([]interface{}, error) {
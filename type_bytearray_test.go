@@ -1,6 +1,15 @@
 package goparquet
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
 
 func TestFuzzCrashByteArrayPlainDecoderNext(t *testing.T) {
 	data := []byte("PAR1\x15\x00\x15\xac\x02\x15\xac\x02,\x150\x15\x00\x15\x06\x15" +
@@ -25,3 +34,117 @@ func TestFuzzCrashByteArrayPlainDecoderNext(t *testing.T) {
 
 	readAllData(t, data)
 }
+
+func TestByteArrayPlainDecoderZeroCopy(t *testing.T) {
+	data := &bytes.Buffer{}
+	enc := &byteArrayPlainEncoder{}
+	require.NoError(t, enc.init(data))
+	require.NoError(t, enc.encodeValues([]interface{}{[]byte("foo"), []byte("barbaz")}))
+	encoded := data.Bytes()
+
+	for _, zeroCopy := range []bool{false, true} {
+		pageData := append([]byte(nil), encoded...)
+
+		dec := &byteArrayPlainDecoder{zeroCopy: zeroCopy}
+		require.NoError(t, dec.init(bytes.NewBuffer(pageData)))
+
+		values := make([]interface{}, 2)
+		n, err := dec.decodeValues(values)
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Equal(t, []interface{}{[]byte("foo"), []byte("barbaz")}, values)
+
+		_, err = dec.decodeValues(make([]interface{}, 1))
+		require.Equal(t, io.EOF, err)
+
+		// modifying the page data only changes the values if they reference it.
+		copy(pageData[4:], "FOO")
+		copy(pageData[11:], "BAR")
+		if zeroCopy {
+			require.Equal(t, []interface{}{[]byte("FOO"), []byte("BARbaz")}, values)
+		} else {
+			require.Equal(t, []interface{}{[]byte("foo"), []byte("barbaz")}, values)
+		}
+
+		// appending to a value can't overwrite the next value.
+		foo := values[0].([]byte)
+		require.Equal(t, 3, cap(foo))
+		_ = append(foo, 'x')
+		require.Equal(t, byte(6), pageData[7])
+	}
+
+	pageData := []byte("abcdef")
+	dec := &byteArrayPlainDecoder{length: 3, zeroCopy: true}
+	require.NoError(t, dec.init(bytes.NewBuffer(pageData)))
+	values := make([]interface{}, 2)
+	n, err := dec.decodeValues(values)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	copy(pageData, "ABCDEF")
+	require.Equal(t, []interface{}{[]byte("ABC"), []byte("DEF")}, values)
+}
+
+func TestReadZeroCopyByteArrays(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary name (STRING);
+		optional fixed_len_byte_array(2) code;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, name := range []string{"alice", "bob", "carol"} {
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte(name), "code": []byte(name[:2])}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithZeroCopyByteArrays(), WithColumns("name"))
+	require.NoError(t, err)
+
+	for _, name := range []string{"alice", "bob", "carol"} {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"name": []byte(name)}, row)
+	}
+
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestValueLengthErrors(t *testing.T) {
+	var lengthErr *ValueLengthError
+
+	int96Dec := &int96PlainDecoder{}
+	require.NoError(t, int96Dec.init(bytes.NewReader(make([]byte, 20))))
+	n, err := int96Dec.decodeValues(make([]interface{}, 2))
+	require.Equal(t, 1, n)
+	require.True(t, errors.As(err, &lengthErr), "unexpected error %v", err)
+	require.Equal(t, &ValueLengthError{Type: parquet.Type_INT96, Expected: 12, Actual: 8}, lengthErr)
+
+	for _, zeroCopy := range []bool{false, true} {
+		flbaDec := &byteArrayPlainDecoder{length: 4, zeroCopy: zeroCopy}
+		require.NoError(t, flbaDec.init(bytes.NewBuffer([]byte("abcdef"))))
+		n, err = flbaDec.decodeValues(make([]interface{}, 2))
+		require.Equal(t, 1, n)
+		require.True(t, errors.As(err, &lengthErr), "unexpected error %v", err)
+		require.Equal(t, &ValueLengthError{Type: parquet.Type_FIXED_LEN_BYTE_ARRAY, Expected: 4, Actual: 2}, lengthErr)
+	}
+
+	buf := &bytes.Buffer{}
+	deltaEnc := &byteArrayDeltaEncoder{}
+	require.NoError(t, deltaEnc.init(buf))
+	require.NoError(t, deltaEnc.encodeValues([]interface{}{[]byte("abcd"), []byte("abc")}))
+	require.NoError(t, deltaEnc.Close())
+	deltaDec, err := getFixedLenByteArrayValuesDecoder(parquet.Encoding_DELTA_BYTE_ARRAY, 4, nil)
+	require.NoError(t, err)
+	require.NoError(t, deltaDec.init(buf))
+	n, err = deltaDec.decodeValues(make([]interface{}, 2))
+	require.Equal(t, 1, n)
+	require.True(t, errors.As(err, &lengthErr), "unexpected error %v", err)
+
+	_, err = getFixedLenByteArrayValuesDecoder(parquet.Encoding_PLAIN, 0, nil)
+	require.Error(t, err)
+	typeLength := int32(-1)
+	_, err = getDictValuesDecoder(&parquet.SchemaElement{Type: parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY), TypeLength: &typeLength})
+	require.Error(t, err)
+}
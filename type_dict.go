@@ -102,6 +102,17 @@ func (d *dictStore) getIndex(in interface{}, size int) int32 {
 	return idx
 }
 
+// truncate removes all but the first numValues distinct values and the first numData entries.
+// Values are only appended to the dictionary, so the remaining entries never refer to a removed
+// value.
+func (d *dictStore) truncate(numValues, numData int) {
+	for _, v := range d.values[numValues:] {
+		delete(d.indices, mapKey(v))
+	}
+	d.values = d.values[:numValues]
+	d.data = d.data[:numData]
+}
+
 func (d *dictStore) addValue(v interface{}, size int) {
 	if v == nil {
 		d.nullCount++
@@ -159,21 +170,26 @@ type dictEncoder struct {
 }
 
 func (d *dictEncoder) Close() error {
-	v := len(d.values)
-	if v == 0 { // empty dictionary?
+	return encodeDictIndices(d.w, len(d.values), d.data)
+}
+
+// encodeDictIndices writes the indices into a dictionary with dictSize values, prefixed by their
+// bit width.
+func encodeDictIndices(w io.Writer, dictSize int, indices []int32) error {
+	if dictSize == 0 { // empty dictionary?
 		return errors.New("empty dictionary nothing to write")
 	}
 
-	w := bits.Len(uint(v))
+	bw := bits.Len(uint(dictSize))
 	// first write the bitLength in a byte
-	if err := writeFull(d.w, []byte{byte(w)}); err != nil {
+	if err := writeFull(w, []byte{byte(bw)}); err != nil {
 		return err
 	}
-	enc := newHybridEncoder(w)
-	if err := enc.init(d.w); err != nil {
+	enc := newHybridEncoder(bw)
+	if err := enc.init(w); err != nil {
 		return err
 	}
-	if err := enc.encode(d.data); err != nil {
+	if err := enc.encode(indices); err != nil {
 		return err
 	}
 
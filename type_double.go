@@ -1,11 +1,10 @@
 package goparquet
 
 import (
-	"encoding/binary"
 	"io"
-	"math"
 
 	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetencoding"
 	"github.com/pkg/errors"
 )
 
@@ -20,15 +19,13 @@ func (d *doublePlainDecoder) init(r io.Reader) error {
 }
 
 func (d *doublePlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	var data uint64
-	for i := range dst {
-		if err := binary.Read(d.r, binary.LittleEndian, &data); err != nil {
-			return i, err
-		}
-		dst[i] = math.Float64frombits(data)
+	data := make([]float64, len(dst))
+	n, err := parquetencoding.DecodePlainDouble(d.r, data)
+	for i := range data[:n] {
+		dst[i] = data[i]
 	}
 
-	return len(dst), nil
+	return n, err
 }
 
 type doublePlainEncoder struct {
@@ -46,17 +43,16 @@ func (d *doublePlainEncoder) init(w io.Writer) error {
 }
 
 func (d *doublePlainEncoder) encodeValues(values []interface{}) error {
-	data := make([]uint64, len(values))
+	data := make([]float64, len(values))
 	for i := range values {
-		data[i] = math.Float64bits(values[i].(float64))
+		data[i] = values[i].(float64)
 	}
 
-	return binary.Write(d.w, binary.LittleEndian, data)
+	return parquetencoding.EncodePlainDouble(d.w, data)
 }
 
 type doubleStore struct {
-	repTyp   parquet.FieldRepetitionType
-	min, max float64
+	repTyp parquet.FieldRepetitionType
 
 	*ColumnParameters
 }
@@ -82,42 +78,12 @@ func (f *doubleStore) repetitionType() parquet.FieldRepetitionType {
 
 func (f *doubleStore) reset(rep parquet.FieldRepetitionType) {
 	f.repTyp = rep
-	f.min = math.MaxFloat64
-	f.max = -math.MaxFloat64
-}
-
-func (f *doubleStore) maxValue() []byte {
-	if f.max == -math.MaxFloat64 {
-		return nil
-	}
-	ret := make([]byte, 8)
-	binary.LittleEndian.PutUint64(ret, math.Float64bits(f.max))
-	return ret
-}
-
-func (f *doubleStore) minValue() []byte {
-	if f.min == math.MaxFloat64 {
-		return nil
-	}
-	ret := make([]byte, 8)
-	binary.LittleEndian.PutUint64(ret, math.Float64bits(f.min))
-	return ret
-}
-
-func (f *doubleStore) setMinMax(j float64) {
-	if j < f.min {
-		f.min = j
-	}
-	if j > f.max {
-		f.max = j
-	}
 }
 
 func (f *doubleStore) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case float64:
-		f.setMinMax(typed)
 		vals = []interface{}{typed}
 	case []float64:
 		if f.repTyp != parquet.FieldRepetitionType_REPEATED {
@@ -125,7 +91,6 @@ func (f *doubleStore) getValues(v interface{}) ([]interface{}, error) {
 		}
 		vals = make([]interface{}, len(typed))
 		for j := range typed {
-			f.setMinMax(typed[j])
 			vals[j] = typed[j]
 		}
 	default:
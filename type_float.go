@@ -1,13 +1,12 @@
 package goparquet
 
 import (
-	"encoding/binary"
 	"io"
-	"math"
 
 	"github.com/pkg/errors"
 
 	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetencoding"
 )
 
 type floatPlainDecoder struct {
@@ -21,15 +20,13 @@ func (f *floatPlainDecoder) init(r io.Reader) error {
 }
 
 func (f *floatPlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	var data uint32
-	for i := range dst {
-		if err := binary.Read(f.r, binary.LittleEndian, &data); err != nil {
-			return i, err
-		}
-		dst[i] = math.Float32frombits(data)
+	data := make([]float32, len(dst))
+	n, err := parquetencoding.DecodePlainFloat(f.r, data)
+	for i := range data[:n] {
+		dst[i] = data[i]
 	}
 
-	return len(dst), nil
+	return n, err
 }
 
 type floatPlainEncoder struct {
@@ -47,17 +44,16 @@ func (d *floatPlainEncoder) init(w io.Writer) error {
 }
 
 func (d *floatPlainEncoder) encodeValues(values []interface{}) error {
-	data := make([]uint32, len(values))
+	data := make([]float32, len(values))
 	for i := range values {
-		data[i] = math.Float32bits(values[i].(float32))
+		data[i] = values[i].(float32)
 	}
 
-	return binary.Write(d.w, binary.LittleEndian, data)
+	return parquetencoding.EncodePlainFloat(d.w, data)
 }
 
 type floatStore struct {
-	repTyp   parquet.FieldRepetitionType
-	min, max float32
+	repTyp parquet.FieldRepetitionType
 
 	*ColumnParameters
 }
@@ -83,42 +79,12 @@ func (f *floatStore) repetitionType() parquet.FieldRepetitionType {
 
 func (f *floatStore) reset(rep parquet.FieldRepetitionType) {
 	f.repTyp = rep
-	f.min = math.MaxFloat32
-	f.max = -math.MaxFloat32
-}
-
-func (f *floatStore) maxValue() []byte {
-	if f.max == -math.MaxFloat32 {
-		return nil
-	}
-	ret := make([]byte, 4)
-	binary.LittleEndian.PutUint32(ret, math.Float32bits(f.max))
-	return ret
-}
-
-func (f *floatStore) minValue() []byte {
-	if f.min == math.MaxFloat32 {
-		return nil
-	}
-	ret := make([]byte, 4)
-	binary.LittleEndian.PutUint32(ret, math.Float32bits(f.min))
-	return ret
-}
-
-func (f *floatStore) setMinMax(j float32) {
-	if j < f.min {
-		f.min = j
-	}
-	if j > f.max {
-		f.max = j
-	}
 }
 
 func (f *floatStore) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case float32:
-		f.setMinMax(typed)
 		vals = []interface{}{typed}
 	case []float32:
 		if f.repTyp != parquet.FieldRepetitionType_REPEATED {
@@ -126,7 +92,6 @@ func (f *floatStore) getValues(v interface{}) ([]interface{}, error) {
 		}
 		vals = make([]interface{}, len(typed))
 		for j := range typed {
-			f.setMinMax(typed[j])
 			vals[j] = typed[j]
 		}
 	default:
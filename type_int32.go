@@ -1,11 +1,10 @@
 package goparquet
 
 import (
-	"encoding/binary"
 	"io"
-	"math"
 
 	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetencoding"
 	"github.com/pkg/errors"
 )
 
@@ -21,19 +20,17 @@ func (i *int32PlainDecoder) init(r io.Reader) error {
 }
 
 func (i *int32PlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	var d int32
-	for idx := range dst {
-		if err := binary.Read(i.r, binary.LittleEndian, &d); err != nil {
-			return idx, err
-		}
+	d := make([]int32, len(dst))
+	n, err := parquetencoding.DecodePlainInt32(i.r, d)
+	for idx := range d[:n] {
 		if i.unSigned {
-			dst[idx] = uint32(d)
+			dst[idx] = uint32(d[idx])
 		} else {
-			dst[idx] = d
+			dst[idx] = d[idx]
 		}
 	}
 
-	return len(dst), nil
+	return n, err
 }
 
 type int32PlainEncoder struct {
@@ -55,14 +52,14 @@ func (i *int32PlainEncoder) encodeValues(values []interface{}) error {
 	d := make([]int32, len(values))
 	if i.unSigned {
 		for i := range values {
-			d[i] = int32(values[i].(uint32))
+			d[i] = unsignedInt32(values[i])
 		}
 	} else {
 		for j := range values {
 			d[j] = values[j].(int32)
 		}
 	}
-	return binary.Write(i.w, binary.LittleEndian, d)
+	return parquetencoding.EncodePlainInt32(i.w, d)
 }
 
 type int32DeltaBPDecoder struct {
@@ -94,7 +91,7 @@ type int32DeltaBPEncoder struct {
 func (d *int32DeltaBPEncoder) encodeValues(values []interface{}) error {
 	if d.unSigned {
 		for i := range values {
-			if err := d.addInt32(int32(values[i].(uint32))); err != nil {
+			if err := d.addInt32(unsignedInt32(values[i])); err != nil {
 				return err
 			}
 		}
@@ -110,8 +107,7 @@ func (d *int32DeltaBPEncoder) encodeValues(values []interface{}) error {
 }
 
 type int32Store struct {
-	repTyp   parquet.FieldRepetitionType
-	min, max int32
+	repTyp parquet.FieldRepetitionType
 
 	*ColumnParameters
 }
@@ -137,42 +133,12 @@ func (is *int32Store) repetitionType() parquet.FieldRepetitionType {
 
 func (is *int32Store) reset(rep parquet.FieldRepetitionType) {
 	is.repTyp = rep
-	is.min = math.MaxInt32
-	is.max = math.MinInt32
-}
-
-func (is *int32Store) maxValue() []byte {
-	if is.max == math.MinInt32 {
-		return nil
-	}
-	ret := make([]byte, 4)
-	binary.LittleEndian.PutUint32(ret, uint32(is.max))
-	return ret
-}
-
-func (is *int32Store) minValue() []byte {
-	if is.min == math.MaxInt32 {
-		return nil
-	}
-	ret := make([]byte, 4)
-	binary.LittleEndian.PutUint32(ret, uint32(is.min))
-	return ret
-}
-
-func (is *int32Store) setMinMax(j int32) {
-	if j < is.min {
-		is.min = j
-	}
-	if j > is.max {
-		is.max = j
-	}
 }
 
 func (is *int32Store) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case int32:
-		is.setMinMax(typed)
 		vals = []interface{}{typed}
 	case []int32:
 		if is.repTyp != parquet.FieldRepetitionType_REPEATED {
@@ -180,7 +146,6 @@ func (is *int32Store) getValues(v interface{}) ([]interface{}, error) {
 		}
 		vals = make([]interface{}, len(typed))
 		for j := range typed {
-			is.setMinMax(typed[j])
 			vals[j] = typed[j]
 		}
 	default:
@@ -196,3 +161,12 @@ func (*int32Store) append(arrayIn interface{}, value interface{}) interface{} {
 	}
 	return append(arrayIn.([]int32), value.(int32))
 }
+
+// unsignedInt32 returns the physical value of a value of an unsigned INT32 column, which is a
+// uint32 if it was decoded, and an int32 if it was added to a column store.
+func unsignedInt32(v interface{}) int32 {
+	if u, ok := v.(uint32); ok {
+		return int32(u)
+	}
+	return v.(int32)
+}
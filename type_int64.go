@@ -1,11 +1,10 @@
 package goparquet
 
 import (
-	"encoding/binary"
 	"io"
-	"math"
 
 	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetencoding"
 	"github.com/pkg/errors"
 )
 
@@ -21,19 +20,16 @@ func (i *int64PlainDecoder) init(r io.Reader) error {
 }
 
 func (i *int64PlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	var d int64
-
-	for idx := range dst {
-		if err := binary.Read(i.r, binary.LittleEndian, &d); err != nil {
-			return idx, err
-		}
+	d := make([]int64, len(dst))
+	n, err := parquetencoding.DecodePlainInt64(i.r, d)
+	for idx := range d[:n] {
 		if i.unSigned {
-			dst[idx] = uint64(d)
+			dst[idx] = uint64(d[idx])
 		} else {
-			dst[idx] = d
+			dst[idx] = d[idx]
 		}
 	}
-	return len(dst), nil
+	return n, err
 }
 
 type int64PlainEncoder struct {
@@ -55,14 +51,14 @@ func (i *int64PlainEncoder) encodeValues(values []interface{}) error {
 	d := make([]int64, len(values))
 	if i.unSigned {
 		for i := range values {
-			d[i] = int64(values[i].(uint64))
+			d[i] = unsignedInt64(values[i])
 		}
 	} else {
 		for i := range values {
 			d[i] = values[i].(int64)
 		}
 	}
-	return binary.Write(i.w, binary.LittleEndian, d)
+	return parquetencoding.EncodePlainInt64(i.w, d)
 }
 
 type int64DeltaBPDecoder struct {
@@ -95,7 +91,7 @@ type int64DeltaBPEncoder struct {
 func (d *int64DeltaBPEncoder) encodeValues(values []interface{}) error {
 	if d.unSigned {
 		for i := range values {
-			if err := d.addInt64(int64(values[i].(uint64))); err != nil {
+			if err := d.addInt64(unsignedInt64(values[i])); err != nil {
 				return err
 			}
 		}
@@ -111,8 +107,7 @@ func (d *int64DeltaBPEncoder) encodeValues(values []interface{}) error {
 }
 
 type int64Store struct {
-	repTyp   parquet.FieldRepetitionType
-	min, max int64
+	repTyp parquet.FieldRepetitionType
 
 	*ColumnParameters
 }
@@ -138,42 +133,12 @@ func (is *int64Store) repetitionType() parquet.FieldRepetitionType {
 
 func (is *int64Store) reset(rep parquet.FieldRepetitionType) {
 	is.repTyp = rep
-	is.min = math.MaxInt64
-	is.max = math.MinInt64
-}
-
-func (is *int64Store) maxValue() []byte {
-	if is.max == math.MinInt64 {
-		return nil
-	}
-	ret := make([]byte, 8)
-	binary.LittleEndian.PutUint64(ret, uint64(is.max))
-	return ret
-}
-
-func (is *int64Store) minValue() []byte {
-	if is.min == math.MaxInt64 {
-		return nil
-	}
-	ret := make([]byte, 8)
-	binary.LittleEndian.PutUint64(ret, uint64(is.min))
-	return ret
-}
-
-func (is *int64Store) setMinMax(j int64) {
-	if j < is.min {
-		is.min = j
-	}
-	if j > is.max {
-		is.max = j
-	}
 }
 
 func (is *int64Store) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case int64:
-		is.setMinMax(typed)
 		vals = []interface{}{typed}
 	case []int64:
 		if is.repTyp != parquet.FieldRepetitionType_REPEATED {
@@ -181,7 +146,6 @@ func (is *int64Store) getValues(v interface{}) ([]interface{}, error) {
 		}
 		vals = make([]interface{}, len(typed))
 		for j := range typed {
-			is.setMinMax(typed[j])
 			vals[j] = typed[j]
 		}
 	default:
@@ -197,3 +161,12 @@ func (*int64Store) append(arrayIn interface{}, value interface{}) interface{} {
 	}
 	return append(arrayIn.([]int64), value.(int64))
 }
+
+// unsignedInt64 returns the physical value of a value of an unsigned INT64 column, which is a
+// uint64 if it was decoded, and an int64 if it was added to a column store.
+func unsignedInt64(v interface{}) int64 {
+	if u, ok := v.(uint64); ok {
+		return int64(u)
+	}
+	return v.(int64)
+}
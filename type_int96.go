@@ -4,6 +4,7 @@ import (
 	"io"
 
 	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetencoding"
 
 	"github.com/pkg/errors"
 )
@@ -19,26 +20,16 @@ func (i *int96PlainDecoder) init(r io.Reader) error {
 }
 
 func (i *int96PlainDecoder) decodeValues(dst []interface{}) (int, error) {
-	idx := 0
-	for range dst {
-		var data [12]byte
-		// this one is a little tricky do not use ReadFull here
-		n, err := i.r.Read(data[:])
-		// make sure we handle the read data first then handle the error
-		if n == 12 {
-			dst[idx] = data
-			idx++
-		}
-
-		if err != nil && (n == 0 || n == 12) {
-			return idx, err
-		}
-
-		if err != nil {
-			return idx, errors.Wrap(err, "not enough byte to read the Int96")
-		}
+	data := make([][12]byte, len(dst))
+	cr := &countingReader{r: i.r}
+	n, err := parquetencoding.DecodePlainInt96(cr, data)
+	for idx := range data[:n] {
+		dst[idx] = data[idx]
+	}
+	if err == io.ErrUnexpectedEOF {
+		return n, &ValueLengthError{Type: parquet.Type_INT96, Expected: 12, Actual: cr.n - 12*n}
 	}
-	return len(dst), nil
+	return n, err
 }
 
 type int96PlainEncoder struct {
@@ -56,13 +47,12 @@ func (i *int96PlainEncoder) init(w io.Writer) error {
 }
 
 func (i *int96PlainEncoder) encodeValues(values []interface{}) error {
-	data := make([]byte, len(values)*12)
+	data := make([][12]byte, len(values))
 	for j := range values {
-		i96 := values[j].([12]byte)
-		copy(data[j*12:], i96[:])
+		data[j] = values[j].([12]byte)
 	}
 
-	return writeFull(i.w, data)
+	return parquetencoding.EncodePlainInt96(i.w, data)
 }
 
 type int96Store struct {
@@ -85,9 +75,6 @@ func (is *int96Store) getValues(v interface{}) ([]interface{}, error) {
 	var vals []interface{}
 	switch typed := v.(type) {
 	case [12]byte:
-		if err := is.setMinMax(typed[:]); err != nil {
-			return nil, err
-		}
 		vals = []interface{}{typed}
 	case [][12]byte:
 		if is.repTyp != parquet.FieldRepetitionType_REPEATED {
@@ -95,9 +82,6 @@ func (is *int96Store) getValues(v interface{}) ([]interface{}, error) {
 		}
 		vals = make([]interface{}, len(typed))
 		for j := range typed {
-			if err := is.setMinMax(typed[j][:]); err != nil {
-				return nil, err
-			}
 			vals[j] = typed[j]
 		}
 	default:
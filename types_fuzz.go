@@ -68,12 +68,7 @@ func FuzzBooleanRLE(data []byte) int {
 
 func FuzzInt32DeltaBP(data []byte) int {
 	maxSize := len(data) / 4
-	d := int32DeltaBPDecoder{
-		deltaBitPackDecoder32: deltaBitPackDecoder32{
-			blockSize:      128,
-			miniBlockCount: 4,
-		},
-	}
+	d := int32DeltaBPDecoder{}
 	err := d.init(bytes.NewReader(data))
 	if err != nil {
 		return -1
@@ -84,12 +79,7 @@ func FuzzInt32DeltaBP(data []byte) int {
 		return 0
 	}
 
-	e := int32DeltaBPEncoder{
-		deltaBitPackEncoder32: deltaBitPackEncoder32{
-			blockSize:      128,
-			miniBlockCount: 4,
-		},
-	}
+	e := int32DeltaBPEncoder{}
 
 	if err := e.init(&bytes.Buffer{}); err != nil {
 		return -1
@@ -41,7 +41,7 @@ var (
 		},
 		{
 			name: "Int32Delta",
-			enc:  &int32DeltaBPEncoder{deltaBitPackEncoder32: deltaBitPackEncoder32{blockSize: 128, miniBlockCount: 4}},
+			enc:  &int32DeltaBPEncoder{},
 			dec:  &int32DeltaBPDecoder{},
 			rand: func() interface{} {
 				return int32(rand.Int())
@@ -57,7 +57,7 @@ var (
 		},
 		{
 			name: "Uint32Delta",
-			enc:  &int32DeltaBPEncoder{unSigned: true, deltaBitPackEncoder32: deltaBitPackEncoder32{blockSize: 128, miniBlockCount: 4}},
+			enc:  &int32DeltaBPEncoder{unSigned: true},
 			dec:  &int32DeltaBPDecoder{unSigned: true},
 			rand: func() interface{} {
 				return uint32(rand.Int())
@@ -73,7 +73,7 @@ var (
 		},
 		{
 			name: "Int64Delta",
-			enc:  &int64DeltaBPEncoder{deltaBitPackEncoder64: deltaBitPackEncoder64{blockSize: 128, miniBlockCount: 4}},
+			enc:  &int64DeltaBPEncoder{},
 			dec:  &int64DeltaBPDecoder{},
 			rand: func() interface{} {
 				return rand.Int63()
@@ -89,7 +89,7 @@ var (
 		},
 		{
 			name: "Uint64Delta",
-			enc:  &int64DeltaBPEncoder{unSigned: true, deltaBitPackEncoder64: deltaBitPackEncoder64{blockSize: 128, miniBlockCount: 4}},
+			enc:  &int64DeltaBPEncoder{unSigned: true},
 			dec:  &int64DeltaBPDecoder{unSigned: true},
 			rand: func() interface{} {
 				return uint64(rand.Int63())
@@ -0,0 +1,315 @@
+package goparquet
+
+import (
+	"io"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/pkg/errors"
+)
+
+// UnionReader reads the rows of multiple files one after another as if they had the same
+// schema. The schema of the UnionReader is the union of the schemas of all files: it contains
+// every column that is present in any of the files. Columns that are not present in all files
+// are optional, INT32 columns are widened to INT64 and FLOAT columns are widened to DOUBLE if
+// another file contains the column with the wider type. Columns that are annotated with a
+// converted type in one file and with the equivalent logical type in another, e.g. UTF8 and
+// STRING, are treated as the same type. The rows returned by NextRow adhere to
+// the union schema, so they can be written using a FileWriter that was created with the schema
+// definition returned by SchemaDefinition.
+type UnionReader struct {
+	readers []*FileReader
+	schema  *parquetschema.SchemaDefinition
+
+	// present contains the flat names of the columns and groups that are present in each file.
+	present []map[string]bool
+	// widen contains the paths of the columns whose values need to be widened for each file.
+	widen [][][]string
+
+	current int
+}
+
+// NewUnionReader creates a new UnionReader that reads the rows of the provided readers in
+// order. It returns an error if the schemas of the files contain the same column with
+// incompatible types, repetition types or annotations.
+func NewUnionReader(readers ...*FileReader) (*UnionReader, error) {
+	if len(readers) == 0 {
+		return nil, errors.New("no readers provided")
+	}
+
+	schemas := make([]*parquetschema.SchemaDefinition, len(readers))
+	for i, r := range readers {
+		schemas[i] = r.GetSchemaDefinition()
+	}
+
+	schema, err := UnionSchemaDefinition(schemas...)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &UnionReader{
+		readers: readers,
+		schema:  schema,
+		present: make([]map[string]bool, len(readers)),
+		widen:   make([][][]string, len(readers)),
+	}
+
+	for i, sd := range schemas {
+		u.present[i] = make(map[string]bool)
+		u.collectColumns(i, sd.RootColumn.Children, schema.RootColumn.Children, nil)
+	}
+
+	return u, nil
+}
+
+func (u *UnionReader) collectColumns(file int, cols, unionCols []*parquetschema.ColumnDefinition, path []string) {
+	for _, col := range cols {
+		colPath := append(append([]string(nil), path...), col.SchemaElement.Name)
+		u.present[file][strings.Join(colPath, ".")] = true
+
+		unionCol := findColumnDefinition(unionCols, col.SchemaElement.Name)
+		if col.SchemaElement.Type == nil {
+			u.collectColumns(file, col.Children, unionCol.Children, colPath)
+			continue
+		}
+
+		if *col.SchemaElement.Type != *unionCol.SchemaElement.Type {
+			u.widen[file] = append(u.widen[file], colPath)
+		}
+	}
+}
+
+// SchemaDefinition returns the union of the schemas of all files.
+func (u *UnionReader) SchemaDefinition() *parquetschema.SchemaDefinition {
+	return u.schema
+}
+
+// FileCount returns the number of files.
+func (u *UnionReader) FileCount() int {
+	return len(u.readers)
+}
+
+// CurrentFile returns the index of the file the last row returned by NextRow was read from.
+func (u *UnionReader) CurrentFile() int {
+	return u.current
+}
+
+// ColumnPresent returns true if the schema of the file with index file contains the column or
+// group colName. The column name has to be provided in its dotted notation. Rows of files
+// without the column never contain a value for it.
+func (u *UnionReader) ColumnPresent(file int, colName string) bool {
+	if file < 0 || file >= len(u.present) {
+		return false
+	}
+	return u.present[file][colName]
+}
+
+// NextRow reads the next row. It returns io.EOF after the last row of the last file.
+func (u *UnionReader) NextRow() (map[string]interface{}, error) {
+	for u.current < len(u.readers) {
+		row, err := u.readers[u.current].NextRow()
+		if err == io.EOF {
+			if u.current == len(u.readers)-1 {
+				return nil, io.EOF
+			}
+			u.current++
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading file %d failed", u.current)
+		}
+
+		for _, path := range u.widen[u.current] {
+			widenValue(row, path)
+		}
+
+		return row, nil
+	}
+
+	return nil, io.EOF
+}
+
+// widenValue converts the values of the column at path within v to the wider type.
+func widenValue(v interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		switch x := v.(type) {
+		case int32:
+			return int64(x)
+		case float32:
+			return float64(x)
+		case []int32:
+			res := make([]int64, len(x))
+			for i := range x {
+				res[i] = int64(x[i])
+			}
+			return res
+		case []float32:
+			res := make([]float64, len(x))
+			for i := range x {
+				res[i] = float64(x[i])
+			}
+			return res
+		}
+		return v
+	}
+
+	switch x := v.(type) {
+	case map[string]interface{}:
+		if child, ok := x[path[0]]; ok {
+			x[path[0]] = widenValue(child, path[1:])
+		}
+	case []map[string]interface{}:
+		for _, m := range x {
+			widenValue(m, path)
+		}
+	}
+
+	return v
+}
+
+// UnionSchemaDefinition returns a schema definition that contains every column of the provided
+// schema definitions. Columns and groups are in the order in which they first appear. A column
+// or group is required only if it is required in all schemas, otherwise it is optional or
+// repeated. INT32 and FLOAT columns are widened to INT64 and DOUBLE, respectively, if another
+// schema contains the column with the wider type, following the rules of
+// parquetschema.IsWidening. Converted types are compared as their equivalent logical types. An
+// error is returned if the schemas contain the same column with other incompatible types,
+// repetition types or annotations.
+func UnionSchemaDefinition(schemas ...*parquetschema.SchemaDefinition) (*parquetschema.SchemaDefinition, error) {
+	if len(schemas) == 0 {
+		return nil, errors.New("no schema definitions provided")
+	}
+
+	root := &parquetschema.ColumnDefinition{}
+	rootElem := *schemas[0].RootColumn.SchemaElement
+	root.SchemaElement = &rootElem
+
+	childLists := make([][]*parquetschema.ColumnDefinition, len(schemas))
+	for i, sd := range schemas {
+		if sd == nil || sd.RootColumn == nil {
+			return nil, errors.Errorf("schema definition %d is empty", i)
+		}
+		childLists[i] = sd.RootColumn.Children
+	}
+
+	children, err := unionColumns(childLists, "")
+	if err != nil {
+		return nil, err
+	}
+	root.Children = children
+	numChildren := int32(len(children))
+	root.SchemaElement.NumChildren = &numChildren
+
+	return parquetschema.SchemaDefinitionFromColumnDefinition(root), nil
+}
+
+// unionColumns merges the columns that have the same name in the lists of columns of all
+// schemas. A nil list means that the parent group is not present in the schema.
+func unionColumns(lists [][]*parquetschema.ColumnDefinition, prefix string) ([]*parquetschema.ColumnDefinition, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, cols := range lists {
+		for _, col := range cols {
+			if name := col.SchemaElement.Name; !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	var res []*parquetschema.ColumnDefinition
+	for _, name := range names {
+		var cols []*parquetschema.ColumnDefinition
+		for _, list := range lists {
+			if col := findColumnDefinition(list, name); col != nil {
+				cols = append(cols, col)
+			}
+		}
+
+		col, err := unionColumn(cols, len(cols) == len(lists), prefix+name)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, col)
+	}
+
+	return res, nil
+}
+
+// unionColumn merges the definitions of the same column in multiple schemas. present is true if
+// the column is present in all schemas.
+func unionColumn(cols []*parquetschema.ColumnDefinition, present bool, flatName string) (*parquetschema.ColumnDefinition, error) {
+	elem := *cols[0].SchemaElement
+	rep := elem.GetRepetitionType()
+
+	for _, col := range cols[1:] {
+		other := col.SchemaElement
+		if (elem.Type == nil) != (other.Type == nil) {
+			return nil, errors.Errorf("column %s is a group in one schema and a column in another one", flatName)
+		}
+
+		otherRep := other.GetRepetitionType()
+		if (rep == parquet.FieldRepetitionType_REPEATED) != (otherRep == parquet.FieldRepetitionType_REPEATED) {
+			return nil, errors.Errorf("column %s is repeated in one schema but not in another one", flatName)
+		}
+		if otherRep == parquet.FieldRepetitionType_OPTIONAL {
+			rep = otherRep
+		}
+
+		if elem.Type == nil {
+			if !parquetschema.EquivalentTypes(&elem, other) {
+				return nil, errors.Errorf("column %s has conflicting logical or converted types", flatName)
+			}
+			continue
+		}
+
+		switch {
+		case parquetschema.EquivalentTypes(&elem, other), parquetschema.IsWidening(&elem, other):
+		case parquetschema.IsWidening(other, &elem):
+			elem.Type = other.Type
+			elem.LogicalType = other.LogicalType
+			elem.ConvertedType = other.ConvertedType
+		case elem.GetType() == other.GetType() && elem.GetTypeLength() != other.GetTypeLength():
+			return nil, errors.Errorf("column %s has conflicting type lengths %d and %d", flatName, elem.GetTypeLength(), other.GetTypeLength())
+		case elem.GetType() == other.GetType():
+			return nil, errors.Errorf("column %s has conflicting logical or converted types", flatName)
+		default:
+			return nil, errors.Errorf("column %s has conflicting types %s and %s", flatName, *elem.Type, *other.Type)
+		}
+	}
+
+	if !present && rep == parquet.FieldRepetitionType_REQUIRED {
+		rep = parquet.FieldRepetitionType_OPTIONAL
+	}
+	elem.RepetitionType = &rep
+
+	res := &parquetschema.ColumnDefinition{SchemaElement: &elem}
+	if elem.Type != nil {
+		return res, nil
+	}
+
+	lists := make([][]*parquetschema.ColumnDefinition, len(cols))
+	for i, col := range cols {
+		lists[i] = col.Children
+	}
+
+	children, err := unionColumns(lists, flatName+".")
+	if err != nil {
+		return nil, err
+	}
+	res.Children = children
+	numChildren := int32(len(children))
+	elem.NumChildren = &numChildren
+
+	return res, nil
+}
+
+func findColumnDefinition(cols []*parquetschema.ColumnDefinition, name string) *parquetschema.ColumnDefinition {
+	for _, col := range cols {
+		if col.SchemaElement.Name == name {
+			return col
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package goparquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnionReader(t *testing.T) {
+	writeFile := func(schema string, rows ...map[string]interface{}) *FileReader {
+		sd, err := parquetschema.ParseSchemaDefinition(schema)
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		for _, row := range rows {
+			require.NoError(t, w.AddData(row))
+		}
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		return r
+	}
+
+	r1 := writeFile(`message v1 {
+		required int64 id;
+		required int32 n;
+		repeated float scores;
+		optional binary name (STRING);
+	}`,
+		map[string]interface{}{"id": int64(1), "n": int32(10), "scores": []float32{0.5}, "name": []byte("a")},
+		map[string]interface{}{"id": int64(2), "n": int32(20)},
+	)
+	r2 := writeFile(`message v2 {
+		required int64 id;
+		optional int64 n;
+		repeated double scores;
+		required group g {
+			required int32 x;
+		}
+	}`,
+		map[string]interface{}{"id": int64(3), "n": int64(30), "scores": []float64{1.5, 2.5}, "g": map[string]interface{}{"x": int32(7)}},
+	)
+
+	u, err := NewUnionReader(r1, r2)
+	require.NoError(t, err)
+	require.Equal(t, 2, u.FileCount())
+
+	expected, err := parquetschema.ParseSchemaDefinition(`message v1 {
+		required int64 id;
+		optional int64 n;
+		repeated double scores;
+		optional binary name (STRING);
+		optional group g {
+			required int32 x;
+		}
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, expected.String(), u.SchemaDefinition().String())
+
+	require.True(t, u.ColumnPresent(0, "name"))
+	require.False(t, u.ColumnPresent(0, "g"))
+	require.False(t, u.ColumnPresent(0, "g.x"))
+	require.False(t, u.ColumnPresent(1, "name"))
+	require.True(t, u.ColumnPresent(1, "g.x"))
+	require.False(t, u.ColumnPresent(2, "id"))
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(u.SchemaDefinition()))
+
+	var files []int
+	for {
+		row, err := u.NextRow()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		files = append(files, u.CurrentFile())
+		require.NoError(t, w.AddData(row))
+	}
+	require.Equal(t, []int{0, 0, 1}, files)
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	rows := []map[string]interface{}{
+		{"id": int64(1), "n": int64(10), "scores": []float64{0.5}, "name": []byte("a")},
+		{"id": int64(2), "n": int64(20)},
+		{"id": int64(3), "n": int64(30), "scores": []float64{1.5, 2.5}, "g": map[string]interface{}{"x": int32(7)}},
+	}
+	for _, expected := range rows {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, expected, row)
+	}
+}
+
+func TestUnionSchemaDefinitionConflicts(t *testing.T) {
+	tests := map[string][2]string{
+		"types":       {`required int64 a;`, `required binary a;`},
+		"annotations": {`required int32 a (DATE);`, `required int64 a;`},
+		"repeated":    {`repeated int64 a;`, `optional int64 a;`},
+		"group":       {`required int64 a;`, `required group a { required int64 b; }`},
+		"nested":      {`optional group a { required int64 b; }`, `optional group a { required double b; }`},
+	}
+
+	for name, columns := range tests {
+		t.Run(name, func(t *testing.T) {
+			var schemas []*parquetschema.SchemaDefinition
+			for _, c := range columns {
+				sd, err := parquetschema.ParseSchemaDefinition("message test { " + c + " }")
+				require.NoError(t, err)
+				schemas = append(schemas, sd)
+			}
+
+			_, err := UnionSchemaDefinition(schemas...)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestUnionSchemaDefinitionConvertedTypes(t *testing.T) {
+	a, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary name (UTF8);
+		required int32 count (INT_32);
+		optional int32 day (DATE);
+	}`)
+	require.NoError(t, err)
+	a.RootColumn.Children[2].SchemaElement.LogicalType = nil
+
+	b, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary name (STRING);
+		required int64 count (INT(64, true));
+		optional int32 day (DATE);
+	}`)
+	require.NoError(t, err)
+
+	sd, err := UnionSchemaDefinition(a, b)
+	require.NoError(t, err)
+	require.Equal(t, `message test {
+  required binary name (UTF8);
+  required int64 count (INT(64, true));
+  optional int32 day (DATE);
+}
+`, sd.String())
+
+	readers := make([]*FileReader, 2)
+	for i, sd := range []*parquetschema.SchemaDefinition{a, b} {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		count := interface{}(int32(i))
+		if i == 1 {
+			count = int64(i)
+		}
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("foo"), "count": count}))
+		require.NoError(t, w.Close())
+
+		readers[i], err = NewFileReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+	}
+
+	u, err := NewUnionReader(readers...)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		row, err := u.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"name": []byte("foo"), "count": int64(i)}, row)
+	}
+}
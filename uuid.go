@@ -0,0 +1,166 @@
+package goparquet
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/pkg/errors"
+)
+
+// UUIDFormat determines the Go type of the values of columns annotated as UUID returned by
+// NextRow.
+type UUIDFormat int
+
+const (
+	// UUIDBytes returns the values as []byte of length 16, like the values of other
+	// FIXED_LEN_BYTE_ARRAY columns. This is the default.
+	UUIDBytes UUIDFormat = iota
+	// UUIDArray returns the values as [16]byte.
+	UUIDArray
+	// UUIDString returns the values as strings in the canonical form
+	// xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx with lowercase hexadecimal digits.
+	UUIDString
+)
+
+func (f UUIDFormat) String() string {
+	switch f {
+	case UUIDBytes:
+		return "bytes"
+	case UUIDArray:
+		return "array"
+	case UUIDString:
+		return "string"
+	}
+	return fmt.Sprintf("UUIDFormat(%d)", int(f))
+}
+
+// WithUUIDFormat sets the Go type of the values of columns annotated as UUID returned by NextRow.
+// Repeated UUID columns are returned as slices of that type.
+func WithUUIDFormat(format UUIDFormat) FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.uuidFormat = format
+	}
+}
+
+// NewUUIDStore creates a new column store to store UUIDs, which is a FIXED_LEN_BYTE_ARRAY(16)
+// column annotated as UUID. Values can be added as [16]byte, as []byte of length 16 or as strings
+// in the canonical form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx. If allowDict is true, then using a
+// dictionary is considered by the column store depending on its heuristics.
+func NewUUIDStore(enc parquet.Encoding, allowDict bool) (*ColumnStore, error) {
+	length := int32(16)
+	return NewFixedByteArrayStore(enc, allowDict, &ColumnParameters{
+		TypeLength:  &length,
+		LogicalType: &parquet.LogicalType{UUID: parquet.NewUUIDType()},
+	})
+}
+
+// isUUIDElement returns true if the column is a FIXED_LEN_BYTE_ARRAY(16) column annotated as UUID.
+func isUUIDElement(elem *parquet.SchemaElement) bool {
+	return elem.LogicalType != nil && elem.LogicalType.IsSetUUID() &&
+		elem.GetType() == parquet.Type_FIXED_LEN_BYTE_ARRAY && elem.GetTypeLength() == 16
+}
+
+// formatUUID returns the canonical form of the UUID u.
+func formatUUID(u []byte) string {
+	h := hex.EncodeToString(u)
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// parseUUID parses a UUID in the canonical form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx. The
+// hexadecimal digits may be uppercase or lowercase.
+func parseUUID(s string) ([]byte, error) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return nil, errors.Errorf("invalid UUID %q", s)
+	}
+	u, err := hex.DecodeString(s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	if err != nil {
+		return nil, errors.Errorf("invalid UUID %q", s)
+	}
+	return u, nil
+}
+
+func (f UUIDFormat) value(u []byte) interface{} {
+	if len(u) != 16 {
+		return u
+	}
+	switch f {
+	case UUIDArray:
+		var arr [16]byte
+		copy(arr[:], u)
+		return arr
+	case UUIDString:
+		return formatUUID(u)
+	}
+	return u
+}
+
+func (f UUIDFormat) values(u [][]byte) interface{} {
+	switch f {
+	case UUIDArray:
+		values := make([][16]byte, len(u))
+		for i := range u {
+			copy(values[i][:], u[i])
+		}
+		return values
+	case UUIDString:
+		values := make([]string, len(u))
+		for i := range u {
+			values[i] = formatUUID(u[i])
+		}
+		return values
+	}
+	return u
+}
+
+// convertUUIDs converts [16]byte values and UUID strings, which may be a single value or a slice
+// of values, into byte slices if the column is annotated as UUID, and checks that byte slices have
+// a length of 16. Other values are returned unchanged.
+func (cs *ColumnStore) convertUUIDs(v interface{}) (interface{}, error) {
+	if !cs.isUUID() {
+		return v, nil
+	}
+
+	switch typed := v.(type) {
+	case [16]byte:
+		return typed[:], nil
+	case string:
+		return parseUUID(typed)
+	case []byte:
+		if len(typed) != 16 {
+			return nil, errors.Errorf("UUID has length %d instead of 16", len(typed))
+		}
+	case [][16]byte:
+		values := make([][]byte, len(typed))
+		for i := range typed {
+			values[i] = typed[i][:]
+		}
+		return values, nil
+	case []string:
+		values := make([][]byte, len(typed))
+		for i := range typed {
+			u, err := parseUUID(typed[i])
+			if err != nil {
+				return nil, err
+			}
+			values[i] = u
+		}
+		return values, nil
+	case [][]byte:
+		for i := range typed {
+			if len(typed[i]) != 16 {
+				return nil, errors.Errorf("UUID has length %d instead of 16", len(typed[i]))
+			}
+		}
+	}
+	return v, nil
+}
+
+func (cs *ColumnStore) isUUID() bool {
+	params := cs.params()
+	if params == nil || cs.parquetType() != parquet.Type_FIXED_LEN_BYTE_ARRAY {
+		return false
+	}
+	typ := parquet.Type_FIXED_LEN_BYTE_ARRAY
+	return isUUIDElement(&parquet.SchemaElement{Type: &typ, TypeLength: params.TypeLength, LogicalType: params.LogicalType})
+}
@@ -0,0 +1,85 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUUIDColumns(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required fixed_len_byte_array(16) id (UUID);
+		repeated fixed_len_byte_array(16) refs (UUID);
+		optional fixed_len_byte_array(16) raw;
+	}`)
+	require.NoError(t, err)
+
+	id := [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	other := [16]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":   "123E4567-E89B-12D3-A456-426614174000",
+		"refs": [][16]byte{id, other, other},
+		"raw":  id[:],
+	}))
+	require.NoError(t, w.AddData(map[string]interface{}{
+		"id":   id,
+		"refs": []string{"ffffffff-ffff-ffff-ffff-ffffffffffff"},
+	}))
+	require.NoError(t, w.Close())
+
+	tests := []struct {
+		format   UUIDFormat
+		expected []map[string]interface{}
+	}{
+		{UUIDBytes, []map[string]interface{}{
+			{"id": id[:], "refs": [][]byte{id[:], other[:], other[:]}, "raw": id[:]},
+			{"id": id[:], "refs": [][]byte{other[:]}},
+		}},
+		{UUIDArray, []map[string]interface{}{
+			{"id": id, "refs": [][16]byte{id, other, other}, "raw": id[:]},
+			{"id": id, "refs": [][16]byte{other}},
+		}},
+		{UUIDString, []map[string]interface{}{
+			{"id": "123e4567-e89b-12d3-a456-426614174000", "refs": []string{"123e4567-e89b-12d3-a456-426614174000", "ffffffff-ffff-ffff-ffff-ffffffffffff", "ffffffff-ffff-ffff-ffff-ffffffffffff"}, "raw": id[:]},
+			{"id": "123e4567-e89b-12d3-a456-426614174000", "refs": []string{"ffffffff-ffff-ffff-ffff-ffffffffffff"}},
+		}},
+	}
+	for _, tt := range tests {
+		r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithUUIDFormat(tt.format))
+		require.NoError(t, err)
+		for _, expected := range tt.expected {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, expected, row, tt.format.String())
+		}
+	}
+
+	for _, value := range []interface{}{"123e4567-e89b-12d3-a456", "123e4567+e89b-12d3-a456-426614174000", "123e4567-e89b-12d3-a456-42661417400g", id[:8]} {
+		w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd))
+		require.Error(t, w.AddData(map[string]interface{}{"id": value}), "%v", value)
+	}
+}
+
+func TestNewUUIDStore(t *testing.T) {
+	store, err := NewUUIDStore(parquet.Encoding_PLAIN, true)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf)
+	require.NoError(t, w.AddColumn("id", NewDataColumn(store, parquet.FieldRepetitionType_REQUIRED)))
+	require.NoError(t, w.AddData(map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174000"}))
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithUUIDFormat(UUIDString))
+	require.NoError(t, err)
+	require.Equal(t, "message msg {\n  required fixed_len_byte_array(16) id (UUID);\n}\n", r.GetSchemaDefinition().String())
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174000"}, row)
+}
@@ -0,0 +1,466 @@
+package goparquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"unicode/utf8"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/pkg/errors"
+)
+
+// ValidationError is returned if the data of a column doesn't pass the validation that is enabled
+// by WithStrictValidation.
+type ValidationError struct {
+	// Column is the flat name of the column that failed the validation.
+	Column string
+	// Reason describes why the validation failed.
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation of column %s failed: %s", e.Column, e.Reason)
+}
+
+// ChunkValidationReport is the result of validating a single column chunk.
+type ChunkValidationReport struct {
+	// RowGroup is the index of the validated row group.
+	RowGroup int
+	// Column is the flat name of the validated column.
+	Column string
+	// DataPages is the number of data pages found in the chunk.
+	DataPages int
+	// DictionaryPages is the number of dictionary pages found in the chunk.
+	DictionaryPages int
+	// NumValues is the sum of the value counts of all data pages.
+	NumValues int64
+	// Pages contains the sizes of the data pages in the order of the chunk. Pages whose levels
+	// can't be located are reported as problems and aren't included.
+	Pages []PageSizes
+	// ValuesChecked is true if the values of the chunk were decoded and checked against the
+	// statistics of the chunk by ValidateValues.
+	ValuesChecked bool
+	// Problems contains a description of every inconsistency that was found. The chunk
+	// is valid if it is empty.
+	Problems []string
+}
+
+// PageSizes contains the sizes of a data page. The page data consists of the encoded repetition
+// and definition levels, followed by the encoded values. In V1 data pages, the levels are
+// compressed together with the values, and each of them is prefixed with its length. In V2 data
+// pages, the levels are stored uncompressed in front of the values and their lengths are part of
+// the page header. In both cases, the sizes of the page header include the levels.
+type PageSizes struct {
+	// Offset is the position of the page header in the file.
+	Offset int64
+	// Type is the type of the page, either DATA_PAGE or DATA_PAGE_V2.
+	Type parquet.PageType
+	// CompressedSize is the size of the page data in the file as declared in the page header.
+	CompressedSize int32
+	// UncompressedSize is the size of the page data after decompression as declared in the page
+	// header.
+	UncompressedSize int32
+	// LevelsSize is the number of bytes of the repetition and definition levels, including their
+	// length prefixes in V1 data pages.
+	LevelsSize int32
+	// PayloadSize is the number of bytes of the uncompressed values, i.e. UncompressedSize
+	// without LevelsSize.
+	PayloadSize int32
+}
+
+// Valid returns true if no problems were found in the column chunk.
+func (r *ChunkValidationReport) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *ChunkValidationReport) addProblem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// Validate checks the chunk of the column colName in the row group with index rowGroup without
+// decoding its values. It verifies that the page headers form a consistent chain within the
+// chunk, that the value counts of the pages add up to the number of values in the chunk meta
+// data, that the page checksums match if present, and that all pages can be decompressed to
+// their declared size. The sizes of the levels and values of every data page are checked and
+// recorded in the report. The page middleware of the reader is applied to the page data before
+// it is decompressed. The column name has to be provided in its dotted notation.
+// Inconsistencies are reported in the returned report, an error is only returned if the
+// validation itself failed. It doesn't change the position of the reader for NextRow.
+func (f *FileReader) Validate(rowGroup int, colName string) (*ChunkValidationReport, error) {
+	if rowGroup < 0 || rowGroup >= len(f.meta.RowGroups) {
+		return nil, errors.Errorf("row group index %d is out of bounds", rowGroup)
+	}
+
+	col := f.GetColumnByName(colName)
+	if col == nil {
+		return nil, errors.Errorf("column %q not found", colName)
+	}
+
+	rg := f.meta.RowGroups[rowGroup]
+	if len(rg.Columns) <= col.Index() {
+		return nil, errors.Errorf("column index %d is out of bounds", col.Index())
+	}
+
+	chunk := rg.Columns[col.Index()]
+	r := f.reader
+	if chunk.FilePath != nil {
+		file, closeFile, err := openChunkFile(chunk, &f.opts)
+		if err != nil {
+			return nil, err
+		}
+		defer closeFile()
+		r = file
+	}
+
+	dec, err := f.opts.decryptor.chunk(col, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ChunkValidationReport{RowGroup: rowGroup, Column: col.FlatName()}
+
+	meta := chunk.MetaData
+	if meta == nil {
+		report.addProblem("missing meta data")
+		return report, nil
+	}
+
+	if typ := col.Element().GetType(); meta.Type != typ {
+		report.addProblem("wrong type in meta data, expected %s was %s", typ, meta.Type)
+	}
+
+	offset := meta.DataPageOffset
+	if meta.DictionaryPageOffset != nil {
+		offset = *meta.DictionaryPageOffset
+	}
+
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	pages := &offsetReader{
+		inner:  r,
+		offset: offset,
+	}
+
+	var pd *pageDecryptor
+	if dec != nil {
+		pd = dec.pages(meta)
+	}
+
+	if complete := validatePages(pages, col, chunk.MetaData, pd, f.opts.codec(col, meta.Codec), f.opts.middleware, report); complete {
+		if report.NumValues != meta.NumValues {
+			report.addProblem("pages contain %d values but the meta data declares %d values", report.NumValues, meta.NumValues)
+		}
+	}
+
+	if meta.DictionaryPageOffset != nil && report.DictionaryPages == 0 {
+		report.addProblem("meta data has a dictionary page offset but the chunk has no dictionary page")
+	}
+
+	return report, nil
+}
+
+// ValidateValues performs the checks of Validate and, if they don't find any problems, decodes
+// all values of the chunk and checks them against the statistics of the column chunk meta data.
+// The null count has to match the number of null values, and the min and max values have to be
+// lower resp. upper bounds of the values. Statistics that are missing aren't checked. It doesn't
+// change the position of the reader for NextRow.
+func (f *FileReader) ValidateValues(rowGroup int, colName string) (*ChunkValidationReport, error) {
+	report, err := f.Validate(rowGroup, colName)
+	if err != nil || !report.Valid() {
+		return report, err
+	}
+
+	col := f.GetColumnByName(colName)
+	chunk := f.meta.RowGroups[rowGroup].Columns[col.Index()]
+
+	pages, err := readChunk(f.reader, col, chunk, &f.opts, nil, nil)
+	if err != nil {
+		report.addProblem("decoding the pages failed: %v", err)
+		return report, nil
+	}
+
+	var (
+		values    []interface{}
+		numValues int64
+	)
+	for i := range pages {
+		data := make([]interface{}, pages[i].numValues())
+		n, notNull, _, _, err := pages[i].readValues(data)
+		if err != nil {
+			report.addProblem("data page %d: decoding the values failed: %v", i, err)
+			return report, nil
+		}
+		if n != len(data) {
+			report.addProblem("data page %d: decoded %d values but the page header declares %d values", i, n, len(data))
+			return report, nil
+		}
+		numValues += int64(n)
+		values = append(values, data[:notNull]...)
+	}
+
+	report.ValuesChecked = true
+	validateStatistics(col, chunk.MetaData.Statistics, values, numValues-int64(len(values)), f.opts.comparator(col), report)
+
+	return report, nil
+}
+
+// validateStatistics checks the statistics of a column chunk against the values that were decoded
+// from it and records the problems it finds in report.
+func validateStatistics(col *Column, stats *parquet.Statistics, values []interface{}, nullCount int64, cmp BinaryComparator, report *ChunkValidationReport) {
+	if stats == nil {
+		return
+	}
+
+	if stats.NullCount != nil && stats.GetNullCount() != nullCount {
+		report.addProblem("statistics declare %d null values but the chunk contains %d null values", stats.GetNullCount(), nullCount)
+	}
+
+	elem := col.Element()
+	storedMin, storedMax := statisticsMinMax(elem, stats, cmp)
+
+	// unsigned integers are decoded as unsigned Go types but their statistics are encoded like
+	// their signed counterparts.
+	signed := make([]interface{}, len(values))
+	for i, v := range values {
+		switch typed := v.(type) {
+		case uint32:
+			signed[i] = int32(typed)
+		case uint64:
+			signed[i] = int64(typed)
+		default:
+			signed[i] = v
+		}
+	}
+	min, max := valuesMinMax(elem, signed, cmp)
+
+	if storedMin != nil && min != nil && compareStatValues(elem, storedMin, min, cmp) > 0 {
+		report.addProblem("statistics declare a min value of %x but the chunk contains the smaller value %x", storedMin, min)
+	}
+	if storedMax != nil && max != nil && compareStatValues(elem, storedMax, max, cmp) < 0 {
+		report.addProblem("statistics declare a max value of %x but the chunk contains the larger value %x", storedMax, max)
+	}
+}
+
+// validatePages walks through all pages of the chunk and records the problems it finds in
+// report. It returns false if the chain of page headers is broken and the validation had to
+// stop before the end of the chunk.
+func validatePages(r *offsetReader, col *Column, meta *parquet.ColumnMetaData, pd *pageDecryptor, codec parquet.CompressionCodec, middleware []PageMiddleware, report *ChunkValidationReport) bool {
+	for page := 0; r.Count() < meta.TotalCompressedSize; page++ {
+		start := r.offset
+
+		ph := &parquet.PageHeader{}
+		var err error
+		if pd != nil {
+			ph, err = pd.readPageHeader(r, meta.TotalCompressedSize-r.Count())
+		} else {
+			err = readThrift(ph, r)
+		}
+		if err != nil {
+			report.addProblem("page %d at offset %d: reading page header failed: %v", page, start, err)
+			return false
+		}
+
+		if ph.CompressedPageSize < 0 || ph.UncompressedPageSize < 0 {
+			report.addProblem("page %d at offset %d: invalid page data size", page, start)
+			return false
+		}
+
+		if r.Count()+int64(ph.CompressedPageSize) > meta.TotalCompressedSize {
+			report.addProblem("page %d at offset %d: page data exceeds the chunk size of %d bytes", page, start, meta.TotalCompressedSize)
+			return false
+		}
+
+		switch ph.Type {
+		case parquet.PageType_DICTIONARY_PAGE:
+			if report.DictionaryPages > 0 {
+				report.addProblem("page %d at offset %d: more than one dictionary page", page, start)
+			} else if page > 0 {
+				report.addProblem("page %d at offset %d: dictionary page is not the first page", page, start)
+			}
+			if ph.DictionaryPageHeader == nil {
+				report.addProblem("page %d at offset %d: dictionary page without dictionary page header", page, start)
+			}
+			report.DictionaryPages++
+		case parquet.PageType_DATA_PAGE, parquet.PageType_DATA_PAGE_V2:
+			if ph.Type == parquet.PageType_DATA_PAGE && ph.DataPageHeader == nil {
+				report.addProblem("page %d at offset %d: data page without data page header", page, start)
+			}
+			if ph.Type == parquet.PageType_DATA_PAGE_V2 && ph.DataPageHeaderV2 == nil {
+				report.addProblem("page %d at offset %d: data page without data page V2 header", page, start)
+			}
+			if report.DataPages == 0 && start != meta.DataPageOffset {
+				report.addProblem("page %d at offset %d: first data page doesn't start at the data page offset %d", page, start, meta.DataPageOffset)
+			}
+			numValues := pageNumValues(ph)
+			if numValues < 0 {
+				report.addProblem("page %d at offset %d: negative number of values %d", page, start, numValues)
+			} else {
+				report.NumValues += int64(numValues)
+			}
+			report.DataPages++
+		case parquet.PageType_INDEX_PAGE:
+		default:
+			report.addProblem("page %d at offset %d: unknown page type %s", page, start, ph.Type)
+		}
+
+		data := make([]byte, ph.CompressedPageSize)
+		if _, err := io.ReadFull(r, data); err != nil {
+			report.addProblem("page %d at offset %d: reading page data failed: %v", page, start, err)
+			return false
+		}
+
+		if ph.Crc != nil {
+			if expected, actual := uint32(*ph.Crc), crc32.ChecksumIEEE(data); expected != actual {
+				report.addProblem("page %d at offset %d: CRC32 checksum mismatch, expected %08x, got %08x", page, start, expected, actual)
+			}
+		}
+
+		problem := func(format string, args ...interface{}) {
+			report.addProblem("page %d at offset %d: %s", page, start, fmt.Sprintf(format, args...))
+		}
+
+		processed, pageCodec := ph, codec
+		if pd != nil {
+			plain, header, err := pd.decryptPage(data, ph)
+			if err != nil {
+				problem("%v", err)
+				continue
+			}
+			data, processed = plain, header
+		}
+		if len(middleware) > 0 {
+			p, err := runPageMiddleware(data, processed, codec, col, middleware)
+			if err != nil {
+				problem("%v", err)
+				continue
+			}
+			data, processed, pageCodec = p.Data, p.Header, p.Codec
+		}
+
+		levelsSize, ok := validatePageData(data, processed, col, pageCodec, problem)
+		if ok && (ph.Type == parquet.PageType_DATA_PAGE || ph.Type == parquet.PageType_DATA_PAGE_V2) {
+			report.Pages = append(report.Pages, PageSizes{
+				Offset:           start,
+				Type:             ph.Type,
+				CompressedSize:   ph.CompressedPageSize,
+				UncompressedSize: processed.UncompressedPageSize,
+				LevelsSize:       levelsSize,
+				PayloadSize:      processed.UncompressedPageSize - levelsSize,
+			})
+		}
+	}
+
+	return true
+}
+
+// validatePageData checks that the page data can be decompressed to the uncompressed size of the
+// page header and returns the size of the levels of data pages. It returns false if the page data
+// is invalid.
+func validatePageData(data []byte, ph *parquet.PageHeader, col *Column, codec parquet.CompressionCodec, problem func(format string, args ...interface{})) (int32, bool) {
+	uncompressedSize := int(ph.UncompressedPageSize)
+	var levelsSize int32
+	if h := ph.DataPageHeaderV2; h != nil {
+		if err := validatePageV2Sizes(ph, col); err != nil {
+			problem("%v", err)
+			return 0, false
+		}
+		levelsSize = h.RepetitionLevelsByteLength + h.DefinitionLevelsByteLength
+		data = data[levelsSize:]
+		uncompressedSize -= int(levelsSize)
+		if !h.GetIsCompressed() {
+			codec = parquet.CompressionCodec_UNCOMPRESSED
+		}
+	}
+
+	res, err := decompressBlock(data, codec)
+	if err != nil {
+		problem("decompression failed: %v", err)
+		return 0, false
+	}
+
+	if len(res) != uncompressedSize {
+		problem("decompressed data must be %d byte but its %d byte", uncompressedSize, len(res))
+		return 0, false
+	}
+
+	if ph.Type == parquet.PageType_DATA_PAGE {
+		// the levels of V1 data pages are prefixed with their length, unless the maximum level
+		// is 0 and the levels are omitted.
+		for _, max := range []uint16{col.MaxRepetitionLevel(), col.MaxDefinitionLevel()} {
+			if max == 0 {
+				continue
+			}
+			if len(res)-int(levelsSize) < 4 {
+				problem("page data of %d bytes is too short for the levels", len(res))
+				return 0, false
+			}
+			size := int64(binary.LittleEndian.Uint32(res[levelsSize:])) + 4
+			if size > int64(len(res))-int64(levelsSize) {
+				problem("levels of %d bytes exceed the page data of %d bytes", size, len(res))
+				return 0, false
+			}
+			levelsSize += int32(size)
+		}
+	}
+
+	return levelsSize, true
+}
+
+// validatePageV2Sizes checks the level sizes of the data page V2 header against the sizes of the
+// page and the maximum levels of col.
+func validatePageV2Sizes(ph *parquet.PageHeader, col *Column) error {
+	h := ph.DataPageHeaderV2
+	if h.RepetitionLevelsByteLength < 0 || h.DefinitionLevelsByteLength < 0 {
+		return errors.New("invalid level sizes in data page V2 header")
+	}
+	if col.MaxRepetitionLevel() == 0 && h.RepetitionLevelsByteLength != 0 {
+		return errors.Errorf("repetition levels of %d bytes in a column without repetition levels", h.RepetitionLevelsByteLength)
+	}
+	if col.MaxDefinitionLevel() == 0 && h.DefinitionLevelsByteLength != 0 {
+		return errors.Errorf("definition levels of %d bytes in a column without definition levels", h.DefinitionLevelsByteLength)
+	}
+
+	levelsSize := int64(h.RepetitionLevelsByteLength) + int64(h.DefinitionLevelsByteLength)
+	if levelsSize > int64(ph.CompressedPageSize) || levelsSize > int64(ph.UncompressedPageSize) {
+		return errors.Errorf("level sizes of %d bytes exceed the page size", levelsSize)
+	}
+	if !h.GetIsCompressed() && ph.CompressedPageSize != ph.UncompressedPageSize {
+		return errors.Errorf("uncompressed page has a compressed size of %d bytes and an uncompressed size of %d bytes", ph.CompressedPageSize, ph.UncompressedPageSize)
+	}
+
+	return nil
+}
+
+// validateColumnData checks that the number of values that were read for the column in the
+// current row group matches the value count of the column chunk and the number of rows of the
+// row group, and that the values of STRING, ENUM and JSON columns are valid UTF-8.
+func validateColumnData(col *Column, metaNumValues, numRows int64) error {
+	s := col.getColumnStore()
+
+	numValues := int64(s.dLevels.count)
+	if numValues != metaNumValues {
+		return &ValidationError{Column: col.FlatName(), Reason: fmt.Sprintf("read %d values but the column chunk contains %d values", numValues, metaNumValues)}
+	}
+
+	rows := columnRows(col)
+	if rows != numRows {
+		return &ValidationError{Column: col.FlatName(), Reason: fmt.Sprintf("read %d rows but the row group contains %d rows", rows, numRows)}
+	}
+
+	if !parquetschema.IsUTF8String(col.Element()) {
+		return nil
+	}
+
+	for _, v := range s.values.values {
+		if b, ok := v.([]byte); ok && !utf8.Valid(b) {
+			return &ValidationError{Column: col.FlatName(), Reason: fmt.Sprintf("invalid UTF-8 string %q", b)}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,373 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func writeValidateTestFile(t *testing.T, opts ...FileWriterOption) []byte {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+		repeated int32 tags;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, append([]FileWriterOption{WithSchemaDefinition(sd)}, opts...)...)
+	for i := 0; i < 300; i++ {
+		data := map[string]interface{}{
+			"id":   int64(i),
+			"tags": []int32{int32(i), int32(i % 3)},
+		}
+		if i%4 != 0 {
+			data["name"] = []byte{byte('a' + i%5)}
+		}
+		require.NoError(t, w.AddData(data))
+		if i%100 == 99 {
+			require.NoError(t, w.FlushRowGroup())
+		}
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string][]FileWriterOption{
+		"v1":      nil,
+		"v1_gzip": {WithCompressionCodec(parquet.CompressionCodec_GZIP)},
+		"v2":      {WithDataPageV2()},
+		"v2_snappy": {
+			WithDataPageV2(),
+			WithCompressionCodec(parquet.CompressionCodec_SNAPPY),
+		},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := writeValidateTestFile(t, opts...)
+			r, err := NewFileReader(bytes.NewReader(data))
+			require.NoError(t, err)
+
+			for rg := 0; rg < r.RowGroupCount(); rg++ {
+				for col, numValues := range map[string]int64{"id": 100, "name": 100, "tags": 200} {
+					report, err := r.Validate(rg, col)
+					require.NoError(t, err)
+					require.True(t, report.Valid(), "%s/%d: %v", col, rg, report.Problems)
+					require.Equal(t, 1, report.DataPages)
+					require.Equal(t, numValues, report.NumValues)
+
+					require.Len(t, report.Pages, 1)
+					page := report.Pages[0]
+					require.Equal(t, page.UncompressedSize, page.LevelsSize+page.PayloadSize)
+					if col == "id" {
+						require.Equal(t, int32(0), page.LevelsSize)
+					} else {
+						require.True(t, page.LevelsSize > 0, "%s/%d: %+v", col, rg, page)
+					}
+				}
+			}
+
+			// validation must not change the position for NextRow.
+			for i := 0; i < 300; i++ {
+				row, err := r.NextRow()
+				require.NoError(t, err)
+				require.Equal(t, int64(i), row["id"])
+			}
+		})
+	}
+}
+
+func TestValidateProblems(t *testing.T) {
+	data := writeValidateTestFile(t, WithCompressionCodec(parquet.CompressionCodec_GZIP))
+
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	_, err = r.Validate(3, "id")
+	require.Error(t, err)
+	_, err = r.Validate(0, "unknown")
+	require.Error(t, err)
+
+	meta := r.meta.RowGroups[0].Columns[0].MetaData
+	meta.NumValues++
+	report, err := r.Validate(0, "id")
+	require.NoError(t, err)
+	require.False(t, report.Valid())
+	require.Len(t, report.Problems, 1)
+	require.Contains(t, report.Problems[0], "meta data declares 101 values")
+	meta.NumValues--
+
+	meta.TotalCompressedSize -= 10
+	report, err = r.Validate(0, "id")
+	require.NoError(t, err)
+	require.False(t, report.Valid())
+	require.Contains(t, report.Problems[0], "exceeds the chunk size")
+	meta.TotalCompressedSize += 10
+
+	// corrupt the compressed data of the last page of the first column.
+	offset := meta.DataPageOffset + meta.TotalCompressedSize - 8
+	corrupted := append([]byte{}, data...)
+	for i := offset; i < offset+8; i++ {
+		corrupted[i] ^= 0xff
+	}
+
+	r, err = NewFileReader(bytes.NewReader(corrupted))
+	require.NoError(t, err)
+
+	report, err = r.Validate(0, "id")
+	require.NoError(t, err)
+	require.False(t, report.Valid())
+	require.Contains(t, report.Problems[0], "decompression failed")
+
+	report, err = r.Validate(0, "tags")
+	require.NoError(t, err)
+	require.True(t, report.Valid(), "%v", report.Problems)
+}
+
+func TestValidateValues(t *testing.T) {
+	for _, opts := range [][]FileWriterOption{nil, {WithDataPageV2(), WithCompressionCodec(parquet.CompressionCodec_SNAPPY)}} {
+		data := writeValidateTestFile(t, opts...)
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+
+		for rg := 0; rg < r.RowGroupCount(); rg++ {
+			for _, col := range []string{"id", "name", "tags"} {
+				report, err := r.ValidateValues(rg, col)
+				require.NoError(t, err)
+				require.True(t, report.Valid(), "%s/%d: %v", col, rg, report.Problems)
+				require.True(t, report.ValuesChecked)
+			}
+		}
+
+		// validation must not change the position for NextRow.
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(0), row["id"])
+	}
+
+	data := writeValidateTestFile(t)
+	r, err := NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	stats := r.meta.RowGroups[0].Columns[1].MetaData.Statistics
+	*stats.NullCount = 24
+	stats.MaxValue = []byte("c")
+	report, err := r.ValidateValues(0, "name")
+	require.NoError(t, err)
+	require.False(t, report.Valid())
+	require.Len(t, report.Problems, 2)
+	require.Contains(t, report.Problems[0], "declare 24 null values but the chunk contains 25 null values")
+	require.Contains(t, report.Problems[1], "max value of 63")
+
+	stats = r.meta.RowGroups[1].Columns[0].MetaData.Statistics
+	stats.MinValue = encodeStatValue(int64(101))
+	report, err = r.ValidateValues(1, "id")
+	require.NoError(t, err)
+	require.False(t, report.Valid())
+	require.Len(t, report.Problems, 1)
+	require.Contains(t, report.Problems[0], "min value")
+
+	// the values aren't decoded if the pages are invalid.
+	r.meta.RowGroups[2].Columns[0].MetaData.NumValues++
+	report, err = r.ValidateValues(2, "id")
+	require.NoError(t, err)
+	require.False(t, report.Valid())
+	require.False(t, report.ValuesChecked)
+}
+
+func TestStrictValidation(t *testing.T) {
+	readAll := func(data []byte, opts ...FileReaderOption) error {
+		r, err := NewFileReaderWithOptions(bytes.NewReader(data), opts...)
+		require.NoError(t, err)
+		for {
+			if _, err := r.NextRow(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+
+	requireValidationError := func(data []byte, column string) {
+		err := readAll(data, WithStrictValidation())
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr), "unexpected error %v", err)
+		require.Equal(t, column, validationErr.Column)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, readAll(writeValidateTestFile(t), WithStrictValidation()))
+		require.NoError(t, readAll(writeValidateTestFile(t, WithDataPageV2()), WithStrictValidation()))
+	})
+
+	t.Run("utf8", func(t *testing.T) {
+		sd, err := parquetschema.ParseSchemaDefinition(`message test {
+			required binary name (STRING);
+			required binary data;
+		}`)
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("foo"), "data": []byte{0xff}}))
+		require.NoError(t, w.Close())
+		require.NoError(t, readAll(buf.Bytes(), WithStrictValidation()))
+
+		buf.Reset()
+		w = NewFileWriter(buf, WithSchemaDefinition(sd))
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte{0xff}, "data": []byte("foo")}))
+		require.NoError(t, w.Close())
+		require.NoError(t, readAll(buf.Bytes()))
+		requireValidationError(buf.Bytes(), "name")
+	})
+
+	t.Run("enum", func(t *testing.T) {
+		sd, err := parquetschema.ParseSchemaDefinition(`message test {
+			required binary state (ENUM);
+		}`)
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		require.NoError(t, w.AddData(map[string]interface{}{"state": []byte{0xff}}))
+		require.NoError(t, w.Close())
+		require.NoError(t, readAll(buf.Bytes()))
+		requireValidationError(buf.Bytes(), "state")
+	})
+
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		optional group a {
+			optional int64 b;
+		}
+	}`)
+	require.NoError(t, err)
+
+	writeEncoded := func(numRows int64, page *EncodedPage) []byte {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		require.NoError(t, w.WriteEncodedRowGroup(numRows, []*EncodedColumnChunk{{Column: "a.b", Pages: []*EncodedPage{page}}}))
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	encodePage := func(dLevels []int32, values ...int64) *EncodedPage {
+		levels := &packedArray{}
+		levels.reset(2)
+		for _, l := range dLevels {
+			levels.appendSingle(l)
+		}
+		levels.flush()
+
+		data := &bytes.Buffer{}
+		require.NoError(t, encodeLevelsV1(data, 3, levels))
+		require.NoError(t, binary.Write(data, binary.LittleEndian, values))
+
+		return &EncodedPage{
+			Header: &parquet.PageHeader{
+				Type:                 parquet.PageType_DATA_PAGE,
+				UncompressedPageSize: int32(data.Len()),
+				CompressedPageSize:   int32(data.Len()),
+				DataPageHeader: &parquet.DataPageHeader{
+					NumValues:               int32(len(dLevels)),
+					Encoding:                parquet.Encoding_PLAIN,
+					DefinitionLevelEncoding: parquet.Encoding_RLE,
+					RepetitionLevelEncoding: parquet.Encoding_RLE,
+				},
+			},
+			Data: data.Bytes(),
+		}
+	}
+
+	t.Run("levels", func(t *testing.T) {
+		require.NoError(t, readAll(writeEncoded(3, encodePage([]int32{2, 0, 2}, 1, 2)), WithStrictValidation()))
+		// the maximum definition level of a.b is 2.
+		requireValidationError(writeEncoded(3, encodePage([]int32{2, 3, 2}, 1, 2)), "a.b")
+	})
+
+	t.Run("num rows", func(t *testing.T) {
+		// the writer rejects chunks whose number of values doesn't match the number of rows, so
+		// the number of rows is changed after the row group was written.
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd))
+		require.NoError(t, w.WriteEncodedRowGroup(3, []*EncodedColumnChunk{{Column: "a.b", Pages: []*EncodedPage{encodePage([]int32{2, 0, 2}, 1, 2)}}}))
+		w.rowGroups[0].NumRows = 2
+		w.totalNumRecords = 2
+		require.NoError(t, w.Close())
+
+		require.NoError(t, readAll(buf.Bytes()))
+		requireValidationError(buf.Bytes(), "a.b")
+	})
+
+	t.Run("v2 level sizes", func(t *testing.T) {
+		encodePageV2 := func(fn func(ph *parquet.PageHeader)) *EncodedPage {
+			levels := &packedArray{}
+			levels.reset(2)
+			for _, l := range []int32{2, 0, 2} {
+				levels.appendSingle(l)
+			}
+			levels.flush()
+
+			data := &bytes.Buffer{}
+			require.NoError(t, encodeLevelsV2(data, 2, levels))
+			levelsSize := data.Len()
+			require.NoError(t, binary.Write(data, binary.LittleEndian, []int64{1, 2}))
+
+			ph := &parquet.PageHeader{
+				Type:                 parquet.PageType_DATA_PAGE_V2,
+				UncompressedPageSize: int32(data.Len()),
+				CompressedPageSize:   int32(data.Len()),
+				DataPageHeaderV2: &parquet.DataPageHeaderV2{
+					NumValues:                  3,
+					NumNulls:                   1,
+					NumRows:                    3,
+					Encoding:                   parquet.Encoding_PLAIN,
+					DefinitionLevelsByteLength: int32(levelsSize),
+				},
+			}
+			fn(ph)
+			return &EncodedPage{Header: ph, Data: data.Bytes()}
+		}
+
+		require.NoError(t, readAll(writeEncoded(3, encodePageV2(func(ph *parquet.PageHeader) {})), WithStrictValidation()))
+		// the uncompressed size has to include the levels.
+		requireValidationError(writeEncoded(3, encodePageV2(func(ph *parquet.PageHeader) {
+			ph.UncompressedPageSize -= ph.DataPageHeaderV2.DefinitionLevelsByteLength
+		})), "a.b")
+		// a.b isn't repeated.
+		requireValidationError(writeEncoded(3, encodePageV2(func(ph *parquet.PageHeader) {
+			ph.DataPageHeaderV2.RepetitionLevelsByteLength = 1
+			ph.DataPageHeaderV2.DefinitionLevelsByteLength--
+		})), "a.b")
+
+		r, err := NewFileReader(bytes.NewReader(writeEncoded(3, encodePageV2(func(ph *parquet.PageHeader) {
+			ph.DataPageHeaderV2.DefinitionLevelsByteLength = ph.CompressedPageSize + 1
+		}))))
+		require.NoError(t, err)
+		report, err := r.Validate(0, "a.b")
+		require.NoError(t, err)
+		require.False(t, report.Valid())
+		require.Contains(t, report.Problems[0], "exceed the page size")
+		require.Empty(t, report.Pages)
+	})
+
+	t.Run("page size", func(t *testing.T) {
+		data := writeEncoded(3, encodePage([]int32{2, 0, 2}, 1, 2))
+		r, err := NewFileReaderWithOptions(bytes.NewReader(data), WithStrictValidation())
+		require.NoError(t, err)
+
+		r.meta.RowGroups[0].Columns[0].MetaData.TotalCompressedSize--
+		_, err = r.NextRow()
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr), "unexpected error %v", err)
+	})
+}
@@ -0,0 +1,82 @@
+package goparquet
+
+import (
+	"fmt"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// WithLenientValueCounts makes the FileReader trust the page headers if the number of values in
+// the pages of a column chunk doesn't match the value count of the column chunk meta data, or
+// the number of rows in the pages doesn't match the number of rows of the row group, as written
+// by some streaming writers. Instead of failing or reading beyond the end of the column data,
+// the rows of the row group are read as far as all selected columns contain them, and the
+// discrepancies are reported by ValueCountMismatches. With WithStrictValidation, these
+// discrepancies aren't considered validation errors anymore.
+func WithLenientValueCounts() FileReaderOption {
+	return func(opts *fileReaderOptions) {
+		opts.lenientValueCounts = true
+	}
+}
+
+// ValueCountMismatch describes a column chunk whose pages contain a different number of values or
+// rows than declared by the meta data.
+type ValueCountMismatch struct {
+	// RowGroup is the index of the row group of the column chunk.
+	RowGroup int
+	// Column is the flat name of the column.
+	Column string
+	// MetaDataValues is the number of values declared by the column chunk meta data.
+	MetaDataValues int64
+	// PageValues is the number of values in the pages of the column chunk.
+	PageValues int64
+	// MetaDataRows is the number of rows declared by the row group meta data.
+	MetaDataRows int64
+	// PageRows is the number of rows in the pages of the column chunk.
+	PageRows int64
+}
+
+func (m ValueCountMismatch) String() string {
+	return fmt.Sprintf("column %s in row group %d: pages contain %d values in %d rows but the meta data declares %d values in %d rows",
+		m.Column, m.RowGroup, m.PageValues, m.PageRows, m.MetaDataValues, m.MetaDataRows)
+}
+
+// ValueCountMismatches returns the column chunks read so far whose value counts didn't match
+// their meta data. It's only populated with WithLenientValueCounts.
+func (f *FileReader) ValueCountMismatches() []ValueCountMismatch {
+	return f.valueCountMismatches
+}
+
+// columnRows returns the number of rows of the data that was read for the column in the current
+// row group.
+func columnRows(col *Column) int64 {
+	s := col.getColumnStore()
+	if col.MaxRepetitionLevel() == 0 {
+		return int64(s.dLevels.count)
+	}
+
+	var rows int64
+	for i := 0; i < s.rLevels.count; i++ {
+		if rl, _ := s.rLevels.at(i); rl == 0 {
+			rows++
+		}
+	}
+	return rows
+}
+
+// checkValueCounts returns the mismatch of the data that was read for the column in the current
+// row group, or nil if it matches the meta data.
+func checkValueCounts(rowGroup int, col *Column, meta *parquet.ColumnMetaData, numRows int64) *ValueCountMismatch {
+	values, rows := int64(col.getColumnStore().dLevels.count), columnRows(col)
+	if values == meta.NumValues && rows == numRows {
+		return nil
+	}
+	return &ValueCountMismatch{
+		RowGroup:       rowGroup,
+		Column:         col.FlatName(),
+		MetaDataValues: meta.NumValues,
+		PageValues:     values,
+		MetaDataRows:   numRows,
+		PageRows:       rows,
+	}
+}
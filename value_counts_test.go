@@ -0,0 +1,77 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLenientValueCounts(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 foo;
+		repeated int32 bar;
+	}`)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, w.AddData(map[string]interface{}{"foo": int64(i), "bar": []int32{int32(i), int32(i)}}))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// write a new footer that declares more values and rows than the pages contain.
+	r.meta.RowGroups[0].NumRows = 12
+	r.meta.RowGroups[0].Columns[1].MetaData.NumValues = 24
+	footerLen := binary.LittleEndian.Uint32(buf.Bytes()[buf.Len()-8:])
+	out := &writePosStruct{w: &bytes.Buffer{}}
+	_, err = out.Write(buf.Bytes()[:buf.Len()-8-int(footerLen)])
+	require.NoError(t, err)
+	require.NoError(t, writeFileMetaData(out, r.meta))
+	data := out.w.(*bytes.Buffer).Bytes()
+
+	r, err = NewFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := r.NextRow()
+		require.NoError(t, err)
+	}
+	_, err = r.NextRow()
+	require.Error(t, err)
+	require.NotEqual(t, io.EOF, err)
+
+	for _, opts := range [][]FileReaderOption{
+		{WithLenientValueCounts()},
+		{WithLenientValueCounts(), WithStrictValidation()},
+	} {
+		r, err = NewFileReaderWithOptions(bytes.NewReader(data), opts...)
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			row, err := r.NextRow()
+			require.NoError(t, err)
+			require.Equal(t, map[string]interface{}{"foo": int64(i), "bar": []int32{int32(i), int32(i)}}, row)
+		}
+		_, err = r.NextRow()
+		require.Equal(t, io.EOF, err)
+
+		require.Equal(t, []ValueCountMismatch{
+			{RowGroup: 0, Column: "foo", MetaDataValues: 10, PageValues: 10, MetaDataRows: 12, PageRows: 10},
+			{RowGroup: 0, Column: "bar", MetaDataValues: 24, PageValues: 20, MetaDataRows: 12, PageRows: 10},
+		}, r.ValueCountMismatches())
+		require.Equal(t, "column bar in row group 0: pages contain 20 values in 10 rows but the meta data declares 24 values in 12 rows", r.ValueCountMismatches()[1].String())
+	}
+
+	r, err = NewFileReaderWithOptions(bytes.NewReader(data), WithStrictValidation())
+	require.NoError(t, err)
+	_, err = r.NextRow()
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr), "unexpected error %v", err)
+}
@@ -0,0 +1,247 @@
+package goparquet
+
+import (
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// TempFile is the temporary storage of a part that was written through a WriteCoordinator.
+type TempFile interface {
+	io.Writer
+	io.ReadSeeker
+	io.Closer
+}
+
+// TempStorage creates a new TempFile. The WriteCoordinator closes the files once they were
+// assembled or discarded, so implementations that are backed by the file system should remove
+// the file in Close.
+type TempStorage func() (TempFile, error)
+
+// WriteCoordinator lets multiple goroutines write row groups independently and assembles them
+// into a single file with one footer. Each part is written to its own TempFile using a
+// FileWriter and is identified by a sequence number that determines the position of its row
+// groups in the assembled file, regardless of the order in which the parts were completed.
+type WriteCoordinator struct {
+	temp TempStorage
+	opts []FileWriterOption
+
+	mu        sync.Mutex
+	parts     map[int]TempFile // nil for parts without data
+	pending   map[int]bool
+	assembled bool
+}
+
+// NewWriteCoordinator creates a new WriteCoordinator that stores the parts in files created by
+// temp, or in memory if temp is nil. The options are used for the writers of all parts and for
+// the assembled file, so they need to contain the schema definition.
+func NewWriteCoordinator(temp TempStorage, opts ...FileWriterOption) *WriteCoordinator {
+	if temp == nil {
+		temp = func() (TempFile, error) {
+			return &memoryFile{}, nil
+		}
+	}
+
+	return &WriteCoordinator{
+		temp:    temp,
+		opts:    opts,
+		parts:   make(map[int]TempFile),
+		pending: make(map[int]bool),
+	}
+}
+
+// WritePart calls fn with a new FileWriter for the part with the sequence number seq, and closes
+// the writer after fn returns. The part is only added to the coordinator if both fn and closing
+// the writer succeed. A part to which no data was written doesn't add any row groups. It is safe
+// to call WritePart from multiple goroutines, but every sequence number can only be used once.
+func (c *WriteCoordinator) WritePart(seq int, fn func(w *FileWriter) error) error {
+	c.mu.Lock()
+	switch {
+	case c.assembled:
+		c.mu.Unlock()
+		return errors.New("the parts were already assembled")
+	case c.pending[seq]:
+		c.mu.Unlock()
+		return errors.Errorf("part %d was already written", seq)
+	}
+	if _, ok := c.parts[seq]; ok {
+		c.mu.Unlock()
+		return errors.Errorf("part %d was already written", seq)
+	}
+	c.pending[seq] = true
+	c.mu.Unlock()
+
+	var file TempFile
+	done := false
+	defer func() {
+		// the part is released even if fn panics, so that it doesn't block Assemble.
+		if !done && file != nil {
+			_ = file.Close()
+		}
+		c.mu.Lock()
+		delete(c.pending, seq)
+		c.mu.Unlock()
+	}()
+
+	file, err := c.temp()
+	if err != nil {
+		return errors.Wrapf(err, "writing part %d failed", seq)
+	}
+
+	w := NewFileWriter(file, c.opts...)
+	if err := fn(w); err != nil {
+		return errors.Wrapf(err, "writing part %d failed", seq)
+	}
+
+	if len(w.rowGroups) == 0 && w.rowGroupNumRecords() == 0 {
+		done = true
+		err = file.Close()
+		file = nil
+	} else if err = w.Close(); err == nil {
+		done = true
+	}
+	if err != nil {
+		return errors.Wrapf(err, "writing part %d failed", seq)
+	}
+
+	c.mu.Lock()
+	c.parts[seq] = file
+	c.mu.Unlock()
+
+	return nil
+}
+
+// snapshot marks the coordinator as assembled and returns the sequence numbers and files of the
+// parts with data, ordered by their sequence numbers.
+func (c *WriteCoordinator) snapshot() ([]int, []TempFile, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.assembled {
+		return nil, nil, errors.New("the parts were already assembled")
+	}
+	for seq := range c.pending {
+		return nil, nil, errors.Errorf("part %d is still being written", seq)
+	}
+	c.assembled = true
+
+	seqs := make([]int, 0, len(c.parts))
+	for seq, file := range c.parts {
+		if file != nil {
+			seqs = append(seqs, seq)
+		}
+	}
+	sort.Ints(seqs)
+
+	files := make([]TempFile, len(seqs))
+	for i, seq := range seqs {
+		files[i] = c.parts[seq]
+	}
+
+	return seqs, files, nil
+}
+
+// Assemble writes the row groups of all parts to w, ordered by the sequence numbers of the parts,
+// followed by the footer. Afterwards, all parts are closed and no further parts can be written.
+// It returns an error if a part is still being written.
+func (c *WriteCoordinator) Assemble(w io.Writer) error {
+	seqs, files, err := c.snapshot()
+	if err != nil {
+		return err
+	}
+	defer closeTempFiles(files)
+
+	fw := NewFileWriter(w, c.opts...)
+	for i, file := range files {
+		if err := assemblePart(fw, file); err != nil {
+			return errors.Wrapf(err, "assembling part %d failed", seqs[i])
+		}
+	}
+
+	return fw.Close()
+}
+
+// Discard closes all parts without assembling them. No further parts can be written afterwards.
+func (c *WriteCoordinator) Discard() error {
+	_, files, err := c.snapshot()
+	if err != nil {
+		return err
+	}
+	return closeTempFiles(files)
+}
+
+func closeTempFiles(files []TempFile) error {
+	var firstErr error
+	for _, file := range files {
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// assemblePart copies the column chunks of all row groups of the part to fw as they are.
+func assemblePart(fw *FileWriter, file TempFile) error {
+	r, err := NewFileReader(file)
+	if err != nil {
+		return err
+	}
+
+	for rgIdx := range r.meta.RowGroups {
+		for _, col := range r.Columns() {
+			if err := fw.CopyChunk(r, rgIdx, col.FlatName()); err != nil {
+				return errors.Wrapf(err, "row group %d", rgIdx)
+			}
+		}
+	}
+
+	return nil
+}
+
+// memoryFile is a TempFile that keeps its data in memory.
+type memoryFile struct {
+	data []byte
+	pos  int64
+}
+
+func (f *memoryFile) Write(p []byte) (int, error) {
+	if end := f.pos + int64(len(p)); end > int64(len(f.data)) {
+		f.data = append(f.data, make([]byte, end-int64(len(f.data)))...)
+	}
+	n := copy(f.data[f.pos:], p)
+	f.pos += int64(n)
+	return n, nil
+}
+
+func (f *memoryFile) Read(p []byte) (int, error) {
+	if f.pos >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[f.pos:])
+	f.pos += int64(n)
+	return n, nil
+}
+
+func (f *memoryFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += int64(len(f.data))
+	default:
+		return 0, errors.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+func (f *memoryFile) Close() error {
+	f.data = nil
+	return nil
+}
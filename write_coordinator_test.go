@@ -0,0 +1,154 @@
+package goparquet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCoordinator(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		optional binary name (STRING);
+	}`)
+	require.NoError(t, err)
+
+	c := NewWriteCoordinator(nil, WithSchemaDefinition(sd), WithCompressionCodec(parquet.CompressionCodec_SNAPPY), WithMetaData(map[string]string{"foo": "bar"}))
+
+	// every part contains two row groups of 10 rows with the ids seq*20 to seq*20+19.
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for _, seq := range []int{3, 1, 0, 2} {
+		wg.Add(1)
+		go func(seq int) {
+			defer wg.Done()
+			errs[seq] = c.WritePart(seq, func(w *FileWriter) error {
+				for i := 0; i < 20; i++ {
+					id := int64(seq*20 + i)
+					if err := w.AddData(map[string]interface{}{"id": id, "name": []byte(fmt.Sprint(id))}); err != nil {
+						return err
+					}
+					if i == 9 {
+						if err := w.FlushRowGroup(WithRowGroupMetaDataForColumn("id", map[string]string{"part": fmt.Sprint(seq)})); err != nil {
+							return err
+						}
+					}
+				}
+				return nil
+			})
+		}(seq)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.Error(t, c.WritePart(1, func(*FileWriter) error { return nil }))
+	require.Error(t, c.WritePart(4, func(*FileWriter) error { return errors.New("failed") }))
+
+	// an empty part doesn't add a row group.
+	require.NoError(t, c.WritePart(5, func(*FileWriter) error { return nil }))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, c.Assemble(buf))
+	require.Error(t, c.Assemble(&bytes.Buffer{}))
+	require.Error(t, c.WritePart(6, func(*FileWriter) error { return nil }))
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 8, r.RowGroupCount())
+	require.Equal(t, int64(80), r.NumRows())
+	require.Equal(t, "bar", r.MetaData()["foo"])
+
+	for i := 0; i < 80; i++ {
+		row, err := r.NextRow()
+		require.NoError(t, err)
+		require.Equal(t, int64(i), row["id"])
+		require.Equal(t, []byte(fmt.Sprint(i)), row["name"])
+
+		if i%20 == 0 {
+			kv, err := r.ColumnMetaData("id")
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprint(i/20), kv["part"])
+			require.Equal(t, parquet.CompressionCodec_SNAPPY, r.CurrentRowGroup().Columns[0].MetaData.Codec)
+		}
+	}
+	_, err = r.NextRow()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestWriteCoordinatorPending(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	c := NewWriteCoordinator(nil, WithSchemaDefinition(sd))
+
+	started, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		_ = c.WritePart(0, func(w *FileWriter) error {
+			close(started)
+			<-done
+			return w.AddData(map[string]interface{}{"id": int64(1)})
+		})
+	}()
+
+	<-started
+	require.Error(t, c.Assemble(&bytes.Buffer{}))
+	close(done)
+}
+
+func TestWriteCoordinatorPanic(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+	}`)
+	require.NoError(t, err)
+
+	var files []*closeTrackingFile
+	c := NewWriteCoordinator(func() (TempFile, error) {
+		f := &closeTrackingFile{}
+		files = append(files, f)
+		return f, nil
+	}, WithSchemaDefinition(sd))
+
+	require.Panics(t, func() {
+		_ = c.WritePart(0, func(w *FileWriter) error {
+			if err := w.AddData(map[string]interface{}{"id": int64(1)}); err != nil {
+				return err
+			}
+			panic("failed")
+		})
+	})
+	require.Len(t, files, 1)
+	require.True(t, files[0].closed)
+
+	// the part that panicked is neither pending nor assembled.
+	require.NoError(t, c.WritePart(1, func(w *FileWriter) error {
+		return w.AddData(map[string]interface{}{"id": int64(2)})
+	}))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, c.Assemble(buf))
+
+	r, err := NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), r.NumRows())
+}
+
+type closeTrackingFile struct {
+	memoryFile
+	closed bool
+}
+
+func (f *closeTrackingFile) Close() error {
+	f.closed = true
+	return f.memoryFile.Close()
+}
@@ -0,0 +1,125 @@
+package goparquet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// WriterPlugin observes the values that are written by a FileWriter and can write sidecar files
+// next to the parquet file when the FileWriter is closed, e.g. secondary indexes like min/max
+// sketches or inverted indexes.
+type WriterPlugin interface {
+	// ObserveChunk is called for every column chunk of the row groups that are flushed by the
+	// FileWriter, before the chunk is written. Returning an error aborts the flush. Row groups
+	// written with WriteEncodedRowGroup or CopyChunk aren't observed.
+	ObserveChunk(chunk *ObservedChunk) error
+	// WriteSidecars is called when the FileWriter is closed, after the meta data footer of the
+	// parquet file was written. create creates the sidecar files, which are closed by the
+	// FileWriter after WriteSidecars returns.
+	WriteSidecars(meta *parquet.FileMetaData, create SidecarStorage) error
+}
+
+// ObservedChunk contains the values of a column chunk that is about to be written.
+type ObservedChunk struct {
+	// RowGroup is the index of the row group of the column chunk.
+	RowGroup int
+	// Column is the column of the column chunk.
+	Column *Column
+	// Values contains all values of the column chunk including nulls, which are nil. The values
+	// must not be modified.
+	Values []interface{}
+	// Rows contains the index of the row within the file for every value, so that values of
+	// repeated columns can be assigned to their rows.
+	Rows []int64
+}
+
+// SidecarStorage creates the sidecar file with the name name.
+type SidecarStorage func(name string) (io.WriteCloser, error)
+
+// WithWriterPlugin adds a plugin that observes the values written by the FileWriter. The
+// plugins are called in the order in which they were added.
+func WithWriterPlugin(plugin WriterPlugin) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.plugins = append(fw.plugins, plugin)
+	}
+}
+
+// WithSidecarStorage sets the storage in which the sidecar files of the plugins are created, e.g.
+// files next to the parquet file. Without a storage, plugins can't create sidecar files.
+func WithSidecarStorage(storage SidecarStorage) FileWriterOption {
+	return func(fw *FileWriter) {
+		fw.sidecarStorage = storage
+	}
+}
+
+// observeRowGroup passes the column chunks of the current row group to the plugins.
+func (fw *FileWriter) observeRowGroup() error {
+	if len(fw.plugins) == 0 {
+		return nil
+	}
+
+	for _, col := range fw.Columns() {
+		cs := col.getColumnStore()
+		numValues := cs.dLevels.count
+		chunk := &ObservedChunk{
+			RowGroup: len(fw.rowGroups),
+			Column:   col,
+			Values:   make([]interface{}, numValues),
+			Rows:     make([]int64, numValues),
+		}
+		// the column store only contains the defined values, so the nulls are inserted
+		// according to the definition levels.
+		defined := cs.values.assemble()
+		row := fw.totalNumRecords - 1
+		for i := 0; i < numValues; i++ {
+			if rl, _ := cs.rLevels.at(i); rl == 0 {
+				row++
+			}
+			chunk.Rows[i] = row
+			if dl, _ := cs.dLevels.at(i); uint16(dl) == col.MaxDefinitionLevel() && len(defined) > 0 {
+				chunk.Values[i], defined = defined[0], defined[1:]
+			}
+		}
+
+		for _, p := range fw.plugins {
+			if err := p.ObserveChunk(chunk); err != nil {
+				return fmt.Errorf("observing column %s failed: %w", col.FlatName(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeSidecars lets the plugins write their sidecar files and closes them afterwards.
+func (fw *FileWriter) writeSidecars(meta *parquet.FileMetaData) error {
+	for _, p := range fw.plugins {
+		var files []io.Closer
+		create := func(name string) (io.WriteCloser, error) {
+			if fw.sidecarStorage == nil {
+				return nil, errors.New("no sidecar storage configured")
+			}
+			f, err := fw.sidecarStorage(name)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+			return f, nil
+		}
+
+		err := p.WriteSidecars(meta, create)
+		for _, f := range files {
+			if cerr := f.Close(); err == nil && cerr != nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("writing sidecar files failed: %w", err)
+		}
+	}
+
+	return nil
+}
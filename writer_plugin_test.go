@@ -0,0 +1,101 @@
+package goparquet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+// invertedIndexPlugin builds an inverted index of the values of a binary column.
+type invertedIndexPlugin struct {
+	column string
+	index  map[string][]int64
+}
+
+func (p *invertedIndexPlugin) ObserveChunk(chunk *ObservedChunk) error {
+	if chunk.Column.FlatName() != p.column {
+		return nil
+	}
+	for i, v := range chunk.Values {
+		if v == nil {
+			continue
+		}
+		p.index[string(v.([]byte))] = append(p.index[string(v.([]byte))], chunk.Rows[i])
+	}
+	return nil
+}
+
+func (p *invertedIndexPlugin) WriteSidecars(meta *parquet.FileMetaData, create SidecarStorage) error {
+	f, err := create(p.column + ".idx")
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(f).Encode(map[string]interface{}{"rows": meta.NumRows, "index": p.index})
+}
+
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestWriterPlugin(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required int64 id;
+		repeated binary tags (STRING);
+	}`)
+	require.NoError(t, err)
+
+	plugin := &invertedIndexPlugin{column: "tags", index: make(map[string][]int64)}
+	sidecars := make(map[string]*closeBuffer)
+
+	buf := &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd), WithMaxRowGroupRows(2), WithWriterPlugin(plugin),
+		WithSidecarStorage(func(name string) (io.WriteCloser, error) {
+			sidecars[name] = &closeBuffer{}
+			return sidecars[name], nil
+		}))
+	for i, tags := range [][]string{{"a", "b"}, nil, {"b"}, {"c", "a", "a"}, {"b"}} {
+		var values [][]byte
+		for _, tag := range tags {
+			values = append(values, []byte(tag))
+		}
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(i), "tags": values}))
+	}
+	require.NoError(t, w.Close())
+
+	require.Len(t, sidecars, 1)
+	require.True(t, sidecars["tags.idx"].closed)
+	require.JSONEq(t, `{"rows": 5, "index": {"a": [0, 3, 3], "b": [0, 2, 4], "c": [3]}}`, sidecars["tags.idx"].String())
+
+	t.Run("errors", func(t *testing.T) {
+		w := NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithWriterPlugin(&invertedIndexPlugin{column: "tags", index: make(map[string][]int64)}))
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+		require.Error(t, w.Close())
+
+		w = NewFileWriter(&bytes.Buffer{}, WithSchemaDefinition(sd), WithWriterPlugin(failingPlugin{}))
+		require.NoError(t, w.AddData(map[string]interface{}{"id": int64(1)}))
+		require.Error(t, w.FlushRowGroup())
+	})
+}
+
+type failingPlugin struct{}
+
+func (failingPlugin) ObserveChunk(chunk *ObservedChunk) error {
+	return errors.New("failed")
+}
+
+func (failingPlugin) WriteSidecars(meta *parquet.FileMetaData, create SidecarStorage) error {
+	return fmt.Errorf("failed")
+}
@@ -0,0 +1,132 @@
+package goparquet
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fraugster/parquet-go/parquet"
+)
+
+// WriterVersion is the application that wrote a file, as described by the created_by field of the
+// file meta data, e.g. "parquet-mr version 1.8.0 (build 0fda28af84b9746396014ad6a415b90592a98b3b)".
+type WriterVersion struct {
+	// Application is the name of the application, e.g. "parquet-mr" or "parquet-cpp-arrow".
+	Application string
+	// Version is the version of the application, e.g. "1.8.0" or "1.5.1-SNAPSHOT". It is empty
+	// if the created_by field doesn't contain a version.
+	Version string
+	// Build is the build of the application, which is usually a commit hash. It is empty if the
+	// created_by field doesn't contain a build.
+	Build string
+}
+
+var createdByRegexp = regexp.MustCompile(`^(.+?)\s+version\s+(\S+)(?:\s+\(build\s*([^)]*)\))?`)
+
+// ParseCreatedBy parses the created_by field of the file meta data. If it doesn't follow the
+// format "<application> version <version> (build <build>)", the whole field is returned as the
+// application. It returns nil if createdBy is empty.
+func ParseCreatedBy(createdBy string) *WriterVersion {
+	createdBy = strings.TrimSpace(createdBy)
+	if createdBy == "" {
+		return nil
+	}
+
+	m := createdByRegexp.FindStringSubmatch(createdBy)
+	if m == nil {
+		return &WriterVersion{Application: createdBy}
+	}
+	return &WriterVersion{
+		Application: m[1],
+		Version:     m[2],
+		Build:       strings.TrimSpace(m[3]),
+	}
+}
+
+func (v *WriterVersion) String() string {
+	s := v.Application
+	if v.Version != "" {
+		s += " version " + v.Version
+	}
+	if v.Build != "" {
+		s += " (build " + v.Build + ")"
+	}
+	return s
+}
+
+// VersionNumbers returns the major, minor and patch number of the version. Missing numbers are 0,
+// suffixes like "-SNAPSHOT" are ignored. ok is false if the version doesn't start with a number.
+func (v *WriterVersion) VersionNumbers() (major, minor, patch int, ok bool) {
+	version := v.Version
+	if idx := strings.IndexAny(version, "-+ "); idx >= 0 {
+		version = version[:idx]
+	}
+
+	var numbers [3]int
+	for i, part := range strings.SplitN(version, ".", 3) {
+		// ignore everything after the patch number, e.g. in 1.2.3.4.
+		if i == 2 {
+			part = strings.SplitN(part, ".", 2)[0]
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			if i == 0 {
+				return 0, 0, 0, false
+			}
+			break
+		}
+		numbers[i] = n
+	}
+	return numbers[0], numbers[1], numbers[2], true
+}
+
+// Before returns true if the file was written by the application with a version before
+// major.minor.patch. It returns false for other applications and unknown versions.
+func (v *WriterVersion) Before(application string, major, minor, patch int) bool {
+	if v == nil || v.Application != application {
+		return false
+	}
+	vMajor, vMinor, vPatch, ok := v.VersionNumbers()
+	if !ok {
+		return false
+	}
+	if vMajor != major {
+		return vMajor < major
+	}
+	if vMinor != minor {
+		return vMinor < minor
+	}
+	return vPatch < patch
+}
+
+// statisticsMinMaxCorrect returns false if the writer is known to have written wrong min and max
+// statistics for columns of the physical type typ, so that they must not be used for pruning.
+func (v *WriterVersion) statisticsMinMaxCorrect(typ parquet.Type) bool {
+	// PARQUET-251: parquet-mr before 1.8.0 reused the buffers of binary values, so that the min
+	// and max statistics of binary columns could contain other values of the column chunk.
+	if isBinaryType(typ) && v.Before("parquet-mr", 1, 8, 0) {
+		return false
+	}
+	return true
+}
+
+// trustedStatistics returns stats without the min and max values if the writer is known to have
+// written them wrong for columns of the physical type typ.
+func (v *WriterVersion) trustedStatistics(typ parquet.Type, stats *parquet.Statistics) *parquet.Statistics {
+	if stats == nil || v.statisticsMinMaxCorrect(typ) {
+		return stats
+	}
+	return &parquet.Statistics{
+		NullCount:     stats.NullCount,
+		DistinctCount: stats.DistinctCount,
+	}
+}
+
+// WriterVersion returns the application that wrote the file, parsed from the created_by field of
+// the file meta data, or nil if the field isn't set. Statistics that the application is known to
+// have written wrong, like the binary statistics of parquet-mr before 1.8.0 (PARQUET-251), are
+// ignored by WithRowGroupFilter, WithStatisticsColumns and RollupStatistics, and are not copied by
+// MergeFiles, CopyChunk and CopyRows.
+func (f *FileReader) WriterVersion() *WriterVersion {
+	return f.writerVersion
+}
@@ -0,0 +1,129 @@
+package goparquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCreatedBy(t *testing.T) {
+	tests := []struct {
+		createdBy string
+		expected  *WriterVersion
+	}{
+		{"", nil},
+		{"parquet-go", &WriterVersion{Application: "parquet-go"}},
+		{"parquet-mr version 1.8.0 (build 0fda28af84b9746396014ad6a415b90592a98b3b)", &WriterVersion{Application: "parquet-mr", Version: "1.8.0", Build: "0fda28af84b9746396014ad6a415b90592a98b3b"}},
+		{"parquet-cpp-arrow version 14.0.2", &WriterVersion{Application: "parquet-cpp-arrow", Version: "14.0.2"}},
+		{"impala version 1.0-SNAPSHOT (build 9d1ab6cd2b2f3c2e1a2c3a7c6b1c1c5b1e3f0a9e)", &WriterVersion{Application: "impala", Version: "1.0-SNAPSHOT", Build: "9d1ab6cd2b2f3c2e1a2c3a7c6b1c1c5b1e3f0a9e"}},
+	}
+	for _, tt := range tests {
+		v := ParseCreatedBy(tt.createdBy)
+		require.Equal(t, tt.expected, v, tt.createdBy)
+		if v != nil {
+			require.Equal(t, tt.createdBy, v.String())
+		}
+	}
+
+	v := ParseCreatedBy("parquet-mr version 1.7.1-SNAPSHOT")
+	major, minor, patch, ok := v.VersionNumbers()
+	require.True(t, ok)
+	require.Equal(t, []int{1, 7, 1}, []int{major, minor, patch})
+	require.True(t, v.Before("parquet-mr", 1, 8, 0))
+	require.False(t, v.Before("parquet-mr", 1, 7, 1))
+	require.False(t, v.Before("parquet-cpp", 2, 0, 0))
+
+	_, _, _, ok = ParseCreatedBy("parquet-go").VersionNumbers()
+	require.False(t, ok)
+	require.False(t, ParseCreatedBy("parquet-go").Before("parquet-go", 1, 0, 0))
+
+	var unknown *WriterVersion
+	require.False(t, unknown.Before("parquet-mr", 1, 8, 0))
+}
+
+func TestWriterVersionStatistics(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary name (STRING);
+		required int64 count;
+	}`)
+	require.NoError(t, err)
+
+	readFile := func(createdBy string) *FileReader {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd), WithCreator(createdBy))
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("a"), "count": int64(1)}))
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("b"), "count": int64(2)}))
+		require.NoError(t, w.Close())
+
+		r, err := NewFileReaderWithOptions(bytes.NewReader(buf.Bytes()), WithStatisticsColumns("name", "count"))
+		require.NoError(t, err)
+		return r
+	}
+
+	r := readFile("parquet-mr version 1.7.0 (build abc)")
+	require.Equal(t, &WriterVersion{Application: "parquet-mr", Version: "1.7.0", Build: "abc"}, r.WriterVersion())
+
+	stats, err := r.RollupStatistics()
+	require.NoError(t, err)
+	require.Nil(t, stats["name"].MinValue)
+	require.Equal(t, int64(0), *stats["name"].NullCount)
+	require.Equal(t, int64Bytes(1), stats["count"].MinValue)
+
+	row, err := r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"min_count": int64(1), "max_count": int64(2)}, row[StatisticsKey])
+
+	r = readFile("parquet-mr version 1.8.0 (build abc)")
+	stats, err = r.RollupStatistics()
+	require.NoError(t, err)
+	require.Equal(t, []byte("a"), stats["name"].MinValue)
+
+	row, err = r.NextRow()
+	require.NoError(t, err)
+	require.Equal(t, []byte("b"), row[StatisticsKey].(map[string]interface{})["max_name"])
+}
+
+func TestWriterVersionStatisticsCopied(t *testing.T) {
+	sd, err := parquetschema.ParseSchemaDefinition(`message test {
+		required binary name (STRING);
+		required int64 count;
+	}`)
+	require.NoError(t, err)
+
+	writeFile := func(createdBy string) []byte {
+		buf := &bytes.Buffer{}
+		w := NewFileWriter(buf, WithSchemaDefinition(sd), WithCreator(createdBy))
+		require.NoError(t, w.AddData(map[string]interface{}{"name": []byte("a"), "count": int64(1)}))
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	old, current := writeFile("parquet-mr version 1.7.0 (build abc)"), writeFile("parquet-go")
+
+	checkStatistics := func(data []byte) {
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		for rg, expected := range [][]byte{nil, []byte("a")} {
+			stats := r.meta.RowGroups[rg].Columns[0].MetaData.Statistics
+			require.Equal(t, expected, stats.MinValue, "row group %d", rg)
+			require.Equal(t, int64(0), stats.GetNullCount())
+			require.Equal(t, int64Bytes(1), r.meta.RowGroups[rg].Columns[1].MetaData.Statistics.MinValue)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, MergeFiles(buf, bytes.NewReader(old), bytes.NewReader(current)))
+	checkStatistics(buf.Bytes())
+
+	buf = &bytes.Buffer{}
+	w := NewFileWriter(buf, WithSchemaDefinition(sd))
+	for _, data := range [][]byte{old, current} {
+		r, err := NewFileReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		_, err = CopyRows(w, r, WithCopyRawChunks())
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	checkStatistics(buf.Bytes())
+}